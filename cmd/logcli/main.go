@@ -650,6 +650,7 @@ func newQuery(instant bool, cmd *kingpin.CmdClause) *query.Query {
 	cmd.Flag("remote-schema", "Execute the current query using a remote schema retrieved from the configured -schema-store.").Default("false").BoolVar(&q.FetchSchemaFromStorage)
 	cmd.Flag("schema-store", "Store used for retrieving remote schema.").Default("").StringVar(&q.SchemaStore)
 	cmd.Flag("colored-output", "Show output with colored labels").Default("false").BoolVar(&q.ColoredOutput)
+	cmd.Flag("explain", "Execute the query with EXPLAIN ANALYZE and print the query plan annotated with runtime statistics of each node to stderr. Only supported by the new query engine.").Default("false").BoolVar(&q.Explain)
 
 	return q
 }
@@ -508,6 +508,14 @@ func (c *Compactor) RegisterIndexCompactor(indexType string, indexCompactor Inde
 	c.indexCompactors[indexType] = indexCompactor
 }
 
+// SetObjectDeleter sets the function which applies delete requests to storage
+// other than chunks. It is a no-op if retention is disabled.
+func (c *Compactor) SetObjectDeleter(objectDeleter deletion.ObjectDeleterFunc) {
+	if c.deleteRequestsManager != nil {
+		c.deleteRequestsManager.SetObjectDeleter(objectDeleter)
+	}
+}
+
 func (c *Compactor) TablesManager() TablesManager {
 	return c.tablesManager
 }
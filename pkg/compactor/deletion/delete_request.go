@@ -107,6 +107,11 @@ func allMatch(matchers []*labels.Matcher, labels labels.Labels) bool {
 	return true
 }
 
+// Interval returns the time range of the logs selected for deletion.
+func (d *DeleteRequest) Interval() model.Interval {
+	return model.Interval{Start: d.StartTime, End: d.EndTime}
+}
+
 // IsDeleted checks if the given chunk entry would have data requested for deletion.
 func (d *DeleteRequest) IsDeleted(userID []byte, lbls labels.Labels, chunk retention.Chunk) bool {
 	if d.UserID != unsafeGetString(userID) {
@@ -42,6 +42,10 @@ type userDeleteRequests struct {
 	requestsInterval model.Interval
 }
 
+// ObjectDeleterFunc deletes the logs of a user selected by delete requests
+// from storage other than chunks, such as data objects.
+type ObjectDeleterFunc func(ctx context.Context, userID string, requests []*DeleteRequest) error
+
 type Table interface {
 	GetUserIndex(userID string) (retention.SeriesIterator, error)
 }
@@ -62,6 +66,7 @@ type DeleteRequestsManager struct {
 	deletionManifestStoreClient client.ObjectClient
 	jobBuilder                  *JobBuilder
 	tablesManager               TablesManager
+	objectDeleter               ObjectDeleterFunc
 
 	metrics            *deleteRequestsManagerMetrics
 	wg                 sync.WaitGroup
@@ -105,11 +110,7 @@ func (d *DeleteRequestsManager) Init(tablesManager TablesManager, registerer pro
 	d.tablesManager = tablesManager
 
 	if d.HSModeEnabled {
-		d.jobBuilder = NewJobBuilder(d.deletionManifestStoreClient, tablesManager.ApplyStorageUpdates, func(requests []DeleteRequest) {
-			for _, req := range requests {
-				d.markRequestAsProcessed(req)
-			}
-		}, registerer)
+		d.jobBuilder = NewJobBuilder(d.deletionManifestStoreClient, tablesManager.ApplyStorageUpdates, d.markRequestsAsProcessed, registerer)
 	}
 
 	var err error
@@ -125,6 +126,13 @@ func (d *DeleteRequestsManager) Init(tablesManager TablesManager, registerer pro
 	return nil
 }
 
+// SetObjectDeleter sets the function which applies delete requests to storage
+// other than chunks. The delete requests of a user are only marked as
+// processed once it succeeded for them. It must be called before Start.
+func (d *DeleteRequestsManager) SetObjectDeleter(objectDeleter ObjectDeleterFunc) {
+	d.objectDeleter = objectDeleter
+}
+
 // Start starts the DeleteRequestsManager's background operations. It is a blocking call.
 // To stop the background operations, cancel the passed context.
 func (d *DeleteRequestsManager) Start(ctx context.Context) {
@@ -523,22 +531,78 @@ func (d *DeleteRequestsManager) markRequestAsProcessed(deleteRequest DeleteReque
 	}
 }
 
+// markRequestsAsProcessed marks the requests processed by deletion jobs as
+// processed once they are applied to storage other than chunks.
+func (d *DeleteRequestsManager) markRequestsAsProcessed(requests []DeleteRequest) {
+	requestsByUser := map[string][]*DeleteRequest{}
+	for i := range requests {
+		requestsByUser[requests[i].UserID] = append(requestsByUser[requests[i].UserID], &requests[i])
+	}
+
+	for userID, userRequests := range requestsByUser {
+		// The requests are left unprocessed to be retried in the next run.
+		if err := d.deleteFromObjects(userID, userRequests); err != nil {
+			continue
+		}
+
+		for _, req := range userRequests {
+			d.markRequestAsProcessed(*req)
+		}
+	}
+}
+
+// deleteFromObjects applies the delete requests of a user to storage other
+// than chunks, if an object deleter is set.
+func (d *DeleteRequestsManager) deleteFromObjects(userID string, requests []*DeleteRequest) error {
+	if d.objectDeleter == nil || len(requests) == 0 {
+		return nil
+	}
+
+	for _, req := range requests {
+		// Requests read from deletion manifests don't have their query parsed yet.
+		if req.logSelectorExpr == nil {
+			if err := req.SetQuery(req.Query); err != nil {
+				level.Error(util_log.Logger).Log("msg", "failed to parse delete request query", "delete_request_id", req.RequestID, "user", userID, "err", err)
+				return err
+			}
+		}
+		req.TotalLinesDeletedMetric = d.metrics.deletedLinesTotal
+	}
+
+	if err := d.objectDeleter(context.Background(), userID, requests); err != nil {
+		level.Error(util_log.Logger).Log("msg", "failed to apply delete requests to objects", "user", userID, "err", err)
+		d.metrics.deletionFailures.WithLabelValues("object_deletion").Inc()
+		return err
+	}
+	return nil
+}
+
 func (d *DeleteRequestsManager) MarkPhaseFinished() {
 	if d.currentBatch.requestCount() == 0 {
 		return
 	}
 
-	for _, userDeleteRequests := range d.currentBatch.deleteRequestsToProcess {
+	failedUsers := map[string]struct{}{}
+	for userID, userDeleteRequests := range d.currentBatch.deleteRequestsToProcess {
 		if userDeleteRequests == nil {
 			continue
 		}
 
+		// The requests are left unprocessed to be retried in the next run.
+		if err := d.deleteFromObjects(userID, userDeleteRequests.requests); err != nil {
+			failedUsers[userID] = struct{}{}
+			continue
+		}
+
 		for _, deleteRequest := range userDeleteRequests.requests {
 			d.markRequestAsProcessed(*deleteRequest)
 		}
 	}
 
 	for _, req := range d.currentBatch.duplicateRequests {
+		if _, ok := failedUsers[req.UserID]; ok {
+			continue
+		}
 		level.Info(util_log.Logger).Log("msg", "marking duplicate delete request as processed",
 			"delete_request_id", req.RequestID,
 			"sequence_num", req.SequenceNum,
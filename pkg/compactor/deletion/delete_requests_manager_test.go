@@ -2,6 +2,7 @@ package deletion
 
 import (
 	"context"
+	"errors"
 	"path/filepath"
 	"slices"
 	"strings"
@@ -1370,6 +1371,53 @@ func TestDeleteRequestsManager_SeriesProgressWithTimeout(t *testing.T) {
 	require.NoFileExists(t, filepath.Join(workingDir, seriesProgressFilename))
 }
 
+func TestDeleteRequestsManager_ObjectDeleter(t *testing.T) {
+	lblFooBar := mustParseLabel(`{foo="bar"}`)
+	deleteRequestsStore := &mockDeleteRequestsStore{deleteRequests: []DeleteRequest{
+		{RequestID: "1", Query: lblFooBar.String(), UserID: "user1", StartTime: 0, EndTime: 100, Status: StatusReceived},
+		{RequestID: "2", Query: lblFooBar.String(), UserID: "user2", StartTime: 0, EndTime: 100, Status: StatusReceived},
+	}}
+
+	mgr, err := NewDeleteRequestsManager(t.TempDir(), deleteRequestsStore, time.Hour, 70, &fakeLimits{defaultLimit: limit{deletionMode: deletionmode.FilterAndDelete.String()}}, false, nil, nil)
+	require.NoError(t, err)
+	require.NoError(t, mgr.Init(nil, nil))
+
+	var objectDeleterErr error
+	deletedRequests := map[string][]string{}
+	mgr.SetObjectDeleter(func(_ context.Context, userID string, requests []*DeleteRequest) error {
+		for _, req := range requests {
+			require.NotNil(t, req.logSelectorExpr)
+			require.NotNil(t, req.TotalLinesDeletedMetric)
+			deletedRequests[userID] = append(deletedRequests[userID], req.RequestID)
+		}
+		if userID == "user2" {
+			return objectDeleterErr
+		}
+		return nil
+	})
+
+	// requests of users failing to be deleted from objects must not be marked as processed
+	objectDeleterErr = errors.New("failed to rewrite objects")
+	mgr.MarkPhaseStarted()
+	mgr.MarkPhaseFinished()
+	require.Equal(t, map[string][]string{"user1": {"1"}, "user2": {"2"}}, deletedRequests)
+
+	processedRequests, err := deleteRequestsStore.getDeleteRequestsByStatus(StatusProcessed)
+	require.NoError(t, err)
+	require.Len(t, processedRequests, 1)
+	require.Equal(t, "1", processedRequests[0].RequestID)
+
+	// the failed requests are retried in the next run
+	objectDeleterErr = nil
+	mgr.MarkPhaseStarted()
+	mgr.MarkPhaseFinished()
+	require.Equal(t, map[string][]string{"user1": {"1"}, "user2": {"2", "2"}}, deletedRequests)
+
+	processedRequests, err = deleteRequestsStore.getDeleteRequestsByStatus(StatusProcessed)
+	require.NoError(t, err)
+	require.Len(t, processedRequests, 2)
+}
+
 type storeAddReqDetails struct {
 	userID, query      string
 	startTime, endTime model.Time
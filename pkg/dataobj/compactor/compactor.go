@@ -0,0 +1,646 @@
+// Package compactor merges small data objects into larger, stream-sorted data
+// objects.
+package compactor
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/user"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/thanos-io/objstore"
+
+	"github.com/grafana/loki/pkg/push"
+
+	"github.com/grafana/loki/v3/pkg/dataobj"
+	"github.com/grafana/loki/v3/pkg/dataobj/consumer/logsobj"
+	"github.com/grafana/loki/v3/pkg/dataobj/index"
+	"github.com/grafana/loki/v3/pkg/dataobj/index/indexobj"
+	"github.com/grafana/loki/v3/pkg/dataobj/metastore"
+	"github.com/grafana/loki/v3/pkg/dataobj/sections/logs"
+	"github.com/grafana/loki/v3/pkg/dataobj/sections/pointers"
+	"github.com/grafana/loki/v3/pkg/dataobj/sections/streams"
+	"github.com/grafana/loki/v3/pkg/dataobj/uploader"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/util/filter"
+)
+
+// A Compactor merges the logs and streams sections of several data objects of
+// a tenant into new data objects of up to the target object size, replaces
+// the original objects in the metastore and deletes them after a grace
+// period.
+//
+// Methods on Compactor are not goroutine-safe; callers are responsible for
+// synchronization.
+type Compactor struct {
+	cfg      Config
+	bucket   objstore.Bucket
+	tenantID string
+	logger   log.Logger
+
+	builder  *logsobj.Builder
+	uploader *uploader.Uploader
+	buf      *bytes.Buffer
+
+	// Updaters for metastore objects that reference data objects (v1) and
+	// index objects (v2).
+	format       metastore.StorageFormatType
+	dataUpdater  *metastore.Updater
+	indexUpdater *metastore.Updater
+	indexBucket  objstore.Bucket
+}
+
+// New creates a new [Compactor] for the given tenant.
+//
+// New returns an error if the provided config is invalid.
+func New(cfg Config, mCfg metastore.Config, bucket objstore.Bucket, tenantID string, logger log.Logger) (*Compactor, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	builder, err := logsobj.NewBuilder(cfg.BuilderConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create builder: %w", err)
+	}
+
+	indexBucket := objstore.NewPrefixedBucket(bucket, cfg.IndexStoragePrefix)
+
+	return &Compactor{
+		cfg:      cfg,
+		bucket:   bucket,
+		tenantID: tenantID,
+		logger:   logger,
+
+		builder:  builder,
+		uploader: uploader.New(cfg.UploaderConfig, bucket, tenantID, logger),
+		buf:      bytes.NewBuffer(make([]byte, 0, int(cfg.TargetObjectSize))),
+
+		format:       mCfg.Updater.StorageFormat,
+		dataUpdater:  metastore.NewUpdater(mCfg.Updater, bucket, tenantID, logger),
+		indexUpdater: metastore.NewUpdater(mCfg.Updater, indexBucket, tenantID, logger),
+		indexBucket:  indexBucket,
+	}, nil
+}
+
+// CompactObjects merges the data objects at the given paths, for metastores
+// that reference data objects directly (storage format v1). The original
+// objects are replaced by the new objects in the metastore and marked for
+// deletion.
+func (c *Compactor) CompactObjects(ctx context.Context, paths []string) ([]metastore.Entry, error) {
+	if len(paths) < 2 {
+		return nil, nil
+	}
+
+	removed, added, err := c.merge(ctx, paths, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.dataUpdater.Replace(ctx, removed, added); err != nil {
+		return nil, fmt.Errorf("failed to update metastore: %w", err)
+	}
+	if err := c.markForDeletion(ctx, paths); err != nil {
+		return nil, err
+	}
+
+	level.Info(c.logger).Log("msg", "compacted data objects", "tenant", c.tenantID, "objects", len(paths), "compacted_objects", len(added))
+	return added, nil
+}
+
+// CompactIndex merges all data objects referenced by the index object at
+// indexPath, for metastores that reference index objects (storage format v2).
+// A new index object is built for the merged data objects and replaces the
+// original index object in the metastore. The original data objects and the
+// original index object are marked for deletion.
+func (c *Compactor) CompactIndex(ctx context.Context, indexPath string) (metastore.Entry, error) {
+	paths, err := c.indexedObjects(ctx, indexPath)
+	if err != nil {
+		return metastore.Entry{}, err
+	}
+	if len(paths) < 2 {
+		return metastore.Entry{}, nil
+	}
+
+	newIndex, err := c.rewriteIndex(ctx, indexPath, paths, nil)
+	if err != nil {
+		return metastore.Entry{}, err
+	}
+
+	level.Info(c.logger).Log("msg", "compacted index", "tenant", c.tenantID, "index", indexPath, "objects", len(paths))
+	return newIndex, nil
+}
+
+// Compact merges the small objects of the metastore windows which ended
+// between start and end. Objects smaller than half of the target object size
+// are small.
+//
+// For storage format v1, small data objects of a window are merged in
+// batches of up to the target object size. For storage format v2, the data
+// objects referenced by an index object are merged if at least two of them
+// are small.
+func (c *Compactor) Compact(ctx context.Context, start, end time.Time) error {
+	windows, err := metastore.Windows(ctx, c.metastoreBucket(), c.tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to list metastore windows: %w", err)
+	}
+	windows = slices.DeleteFunc(windows, func(window time.Time) bool {
+		windowEnd := window.Add(metastore.WindowSize)
+		return !windowEnd.After(start) || windowEnd.After(end)
+	})
+	if len(windows) == 0 {
+		return nil
+	}
+
+	if c.format == metastore.StorageFormatTypeV2 {
+		return c.compactIndexes(ctx, windows[0], windows[len(windows)-1].Add(metastore.WindowSize))
+	}
+	for _, window := range windows {
+		if err := c.compactWindow(ctx, window); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// compactWindow merges the small data objects of the metastore window
+// starting at window (storage format v1).
+func (c *Compactor) compactWindow(ctx context.Context, window time.Time) error {
+	paths, err := c.listObjects(ctx, window, window.Add(metastore.WindowSize-1))
+	if err != nil {
+		return err
+	}
+
+	var (
+		batch     []string
+		batchSize int64
+	)
+	flush := func() error {
+		defer func() { batch, batchSize = nil, 0 }()
+		if len(batch) < 2 {
+			return nil
+		}
+		_, err := c.CompactObjects(ctx, batch)
+		return err
+	}
+
+	for _, objectPath := range paths {
+		size, err := c.objectSize(ctx, objectPath)
+		if err != nil {
+			return err
+		} else if !c.isSmall(size) {
+			continue
+		}
+
+		batch = append(batch, objectPath)
+		batchSize += size
+		if batchSize >= int64(c.cfg.TargetObjectSize) {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	return flush()
+}
+
+// compactIndexes merges the data objects of the index objects whose logs are
+// between start and end, if at least two of them are small (storage format
+// v2).
+func (c *Compactor) compactIndexes(ctx context.Context, start, end time.Time) error {
+	ms := metastore.NewObjectMetastore(c.indexBucket, c.logger, nil)
+	indexPaths, err := ms.DataObjects(user.InjectOrgID(ctx, c.tenantID), start, end)
+	if err != nil {
+		return fmt.Errorf("failed to list objects: %w", err)
+	}
+
+	for _, indexPath := range indexPaths {
+		paths, err := c.indexedObjects(ctx, indexPath)
+		if err != nil {
+			return err
+		}
+
+		var small int
+		for _, objectPath := range paths {
+			size, err := c.objectSize(ctx, objectPath)
+			if err != nil {
+				return err
+			} else if c.isSmall(size) {
+				small++
+			}
+		}
+		if small < 2 {
+			continue
+		}
+
+		if _, err := c.CompactIndex(ctx, indexPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// objectSize returns the size of the data object at objectPath.
+func (c *Compactor) objectSize(ctx context.Context, objectPath string) (int64, error) {
+	attrs, err := c.bucket.Attributes(ctx, objectPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get attributes of object %s: %w", objectPath, err)
+	}
+	return attrs.Size, nil
+}
+
+// isSmall returns true if a data object of the given size should be merged
+// with other data objects.
+func (c *Compactor) isSmall(size int64) bool {
+	return size < int64(c.cfg.TargetObjectSize)/2
+}
+
+// metastoreBucket returns the bucket of the metastore objects: the data
+// bucket for storage format v1 and the index bucket for storage format v2.
+func (c *Compactor) metastoreBucket() objstore.Bucket {
+	if c.format == metastore.StorageFormatTypeV2 {
+		return c.indexBucket
+	}
+	return c.bucket
+}
+
+// listObjects returns the paths of the objects referenced by the metastore
+// which may contain logs between start and end: data objects for storage
+// format v1 and index objects for storage format v2.
+func (c *Compactor) listObjects(ctx context.Context, start, end time.Time) ([]string, error) {
+	bucket := c.metastoreBucket()
+
+	windows, err := metastore.Windows(ctx, bucket, c.tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list metastore windows: %w", err)
+	}
+	if len(windows) == 0 || !windows[0].Before(end) {
+		return nil, nil
+	}
+	if start.Before(windows[0]) {
+		start = windows[0]
+	}
+
+	if c.format == metastore.StorageFormatTypeV2 {
+		// Index pointers are only listed if their whole time range is within
+		// the listed range, so all of them are listed. Callers skip indexes
+		// without matching logs when they inspect them.
+		start, end = windows[0], windows[len(windows)-1].Add(metastore.WindowSize)
+	}
+
+	ms := metastore.NewObjectMetastore(bucket, c.logger, nil)
+	paths, err := ms.DataObjects(user.InjectOrgID(ctx, c.tenantID), start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list objects: %w", err)
+	}
+	return paths, nil
+}
+
+// indexedObjects returns the paths of the data objects referenced by the
+// index object at indexPath.
+func (c *Compactor) indexedObjects(ctx context.Context, indexPath string) ([]string, error) {
+	indexObj, err := dataobj.FromBucket(ctx, c.indexBucket, indexPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open index object: %w", err)
+	}
+	return referencedObjects(ctx, indexObj)
+}
+
+// rewriteIndex merges the data objects at paths, which are referenced by the
+// index object at indexPath, and builds a new index object for them. The new
+// index object replaces the original one in the metastore, and the original
+// objects are marked for deletion. Logs matched by drop are dropped while
+// merging; if no logs are left, the index object is removed from the
+// metastore and the zero entry is returned.
+func (c *Compactor) rewriteIndex(ctx context.Context, indexPath string, paths []string, drop dropFunc) (metastore.Entry, error) {
+	indexBuilder, err := indexobj.NewBuilder(c.cfg.IndexBuilderConfig)
+	if err != nil {
+		return metastore.Entry{}, fmt.Errorf("failed to create index builder: %w", err)
+	}
+	calculator := index.NewCalculator(indexBuilder)
+
+	removed, added, err := c.merge(ctx, paths, drop, func(obj *dataobj.Object, objectPath string) error {
+		return calculator.Calculate(ctx, c.logger, obj, objectPath)
+	})
+	if err != nil {
+		return metastore.Entry{}, err
+	}
+
+	oldIndex := timeRange(removed)
+	oldIndex.Path = indexPath
+
+	// If all logs were dropped, the index object is removed without a
+	// replacement.
+	if len(added) == 0 {
+		if err := c.indexUpdater.Replace(ctx, []metastore.Entry{oldIndex}, nil); err != nil {
+			return metastore.Entry{}, fmt.Errorf("failed to update metastore: %w", err)
+		}
+		return metastore.Entry{}, c.markForDeletion(ctx, append(paths, c.indexKey(indexPath)))
+	}
+
+	var indexBuf bytes.Buffer
+	stats, err := calculator.Flush(&indexBuf)
+	if err != nil {
+		return metastore.Entry{}, fmt.Errorf("failed to flush index: %w", err)
+	}
+	key := index.ObjectKey(c.tenantID, &indexBuf)
+	if err := c.indexBucket.Upload(ctx, key, &indexBuf); err != nil {
+		return metastore.Entry{}, fmt.Errorf("failed to upload index: %w", err)
+	}
+
+	newIndex := metastore.Entry{Path: key, MinTimestamp: stats.MinTimestamp, MaxTimestamp: stats.MaxTimestamp}
+
+	if err := c.indexUpdater.Replace(ctx, []metastore.Entry{oldIndex}, []metastore.Entry{newIndex}); err != nil {
+		return metastore.Entry{}, fmt.Errorf("failed to update metastore: %w", err)
+	}
+	if err := c.markForDeletion(ctx, append(paths, c.indexKey(indexPath))); err != nil {
+		return metastore.Entry{}, err
+	}
+	return newIndex, nil
+}
+
+// indexKey returns the key of an index object in the bucket of the compactor.
+func (c *Compactor) indexKey(indexPath string) string {
+	return c.cfg.IndexStoragePrefix + indexPath
+}
+
+// referencedObjects returns the distinct paths of the data objects that are
+// referenced by the pointers sections of an index object.
+func referencedObjects(ctx context.Context, obj *dataobj.Object) ([]string, error) {
+	var (
+		paths []string
+		seen  = make(map[string]struct{})
+	)
+	for result := range pointers.Iter(ctx, obj) {
+		pointer, err := result.Value()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read pointers: %w", err)
+		}
+		if _, ok := seen[pointer.Path]; ok {
+			continue
+		}
+		objectPath := strings.Clone(pointer.Path)
+		seen[objectPath] = struct{}{}
+		paths = append(paths, objectPath)
+	}
+	return paths, nil
+}
+
+// A cutoffFunc returns the time before which the logs of a stream with the
+// given labels are dropped. The zero time keeps all logs of the stream.
+type cutoffFunc func(lbs labels.Labels) time.Time
+
+// drop returns a dropFunc which drops the logs before the cutoff of their
+// stream.
+func (f cutoffFunc) drop() dropFunc {
+	return func(lbs labels.Labels) (filter.Func, error) {
+		cutoff := f(lbs)
+		if cutoff.IsZero() {
+			return nil, nil
+		}
+		return func(ts time.Time, _ string, _ labels.Labels) bool {
+			return ts.Before(cutoff)
+		}, nil
+	}
+}
+
+// A dropFunc returns a filter for the logs of a stream with the given labels,
+// which returns true for the logs to drop. A nil filter keeps all logs of the
+// stream.
+type dropFunc func(lbs labels.Labels) (filter.Func, error)
+
+// merge reads the data objects at paths and writes their logs into new data
+// objects. It returns the entries of the original objects and the entries of
+// the new objects. If drop is not nil, the logs it matches are dropped. If onFlush is not nil, it is called for each new object
+// after it has been uploaded.
+func (c *Compactor) merge(ctx context.Context, paths []string, drop dropFunc, onFlush func(obj *dataobj.Object, path string) error) (removed, added []metastore.Entry, err error) {
+	c.builder.Reset()
+	defer c.builder.Reset()
+
+	flush := func() error {
+		c.buf.Reset()
+		stats, err := c.builder.Flush(c.buf)
+		if errors.Is(err, logsobj.ErrBuilderEmpty) {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("failed to flush object: %w", err)
+		}
+
+		objectPath, err := c.uploader.Upload(ctx, c.buf)
+		if err != nil {
+			return err
+		}
+		added = append(added, metastore.Entry{Path: objectPath, MinTimestamp: stats.MinTimestamp, MaxTimestamp: stats.MaxTimestamp})
+
+		if onFlush == nil {
+			return nil
+		}
+		obj, err := dataobj.FromReaderAt(bytes.NewReader(c.buf.Bytes()), int64(c.buf.Len()))
+		if err != nil {
+			return fmt.Errorf("failed to read compacted object: %w", err)
+		}
+		return onFlush(obj, objectPath)
+	}
+
+	for _, objectPath := range paths {
+		obj, err := dataobj.FromBucket(ctx, c.bucket, objectPath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open object %s: %w", objectPath, err)
+		}
+
+		entry, err := c.appendObject(ctx, obj, drop, flush)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to merge object %s: %w", objectPath, err)
+		}
+		entry.Path = objectPath
+		removed = append(removed, entry)
+	}
+
+	if err := flush(); err != nil {
+		return nil, nil, err
+	}
+	return removed, added, nil
+}
+
+// appendObject appends the logs of obj to the builder, calling flush whenever
+// the builder is full. It returns an entry with the time range of obj.
+func (c *Compactor) appendObject(ctx context.Context, obj *dataobj.Object, drop dropFunc, flush func() error) (metastore.Entry, error) {
+	var (
+		entry         metastore.Entry
+		streamLabels  = make(map[int64]string)
+		streamFilters = make(map[int64]filter.Func)
+	)
+	for result := range streams.Iter(ctx, obj) {
+		stream, err := result.Value()
+		if err != nil {
+			return entry, fmt.Errorf("failed to read streams: %w", err)
+		}
+		streamLabels[stream.ID] = stream.Labels.String()
+		if drop != nil {
+			f, err := drop(stream.Labels)
+			if err != nil {
+				return entry, fmt.Errorf("failed to create filter for stream %s: %w", stream.Labels, err)
+			} else if f != nil {
+				streamFilters[stream.ID] = f
+			}
+		}
+
+		if entry.MinTimestamp.IsZero() || stream.MinTimestamp.Before(entry.MinTimestamp) {
+			entry.MinTimestamp = stream.MinTimestamp
+		}
+		if stream.MaxTimestamp.After(entry.MaxTimestamp) {
+			entry.MaxTimestamp = stream.MaxTimestamp
+		}
+	}
+
+	for result := range logs.Iter(ctx, obj) {
+		record, err := result.Value()
+		if err != nil {
+			return entry, fmt.Errorf("failed to read logs: %w", err)
+		}
+		lbs, ok := streamLabels[record.StreamID]
+		if !ok {
+			return entry, fmt.Errorf("unknown stream ID %d", record.StreamID)
+		}
+
+		// Records are reused by the iterator, so all their values are copied
+		// before they are appended.
+		line := string(record.Line)
+		if f, ok := streamFilters[record.StreamID]; ok && f(record.Timestamp, line, record.Metadata) {
+			continue
+		}
+
+		stream := logproto.Stream{
+			Labels: lbs,
+			Entries: []logproto.Entry{{
+				Timestamp:          record.Timestamp,
+				Line:               line,
+				StructuredMetadata: convertMetadata(record.Metadata),
+			}},
+		}
+
+		err = c.builder.Append(stream)
+		if errors.Is(err, logsobj.ErrBuilderFull) {
+			if err := flush(); err != nil {
+				return entry, err
+			}
+			err = c.builder.Append(stream)
+		}
+		if err != nil {
+			return entry, fmt.Errorf("failed to append logs: %w", err)
+		}
+	}
+
+	return entry, nil
+}
+
+func convertMetadata(md labels.Labels) push.LabelsAdapter {
+	result := make(push.LabelsAdapter, 0, md.Len())
+	md.Range(func(l labels.Label) {
+		result = append(result, push.LabelAdapter{Name: strings.Clone(l.Name), Value: strings.Clone(l.Value)})
+	})
+	return result
+}
+
+// timeRange returns an entry spanning the time range of all entries.
+func timeRange(entries []metastore.Entry) metastore.Entry {
+	var result metastore.Entry
+	for _, entry := range entries {
+		if result.MinTimestamp.IsZero() || entry.MinTimestamp.Before(result.MinTimestamp) {
+			result.MinTimestamp = entry.MinTimestamp
+		}
+		if entry.MaxTimestamp.After(result.MaxTimestamp) {
+			result.MaxTimestamp = entry.MaxTimestamp
+		}
+	}
+	return result
+}
+
+// deletionsPrefix returns the prefix of the objects which list the objects
+// that are due for deletion.
+func deletionsPrefix(tenantID string) string {
+	return fmt.Sprintf("tenant-%s/compaction/deletions/", tenantID)
+}
+
+// markForDeletion writes a marker object that lists the object keys that can
+// be deleted once the grace period has passed. The name of the marker starts
+// with the time it was written at.
+func (c *Compactor) markForDeletion(ctx context.Context, keys []string) error {
+	content := strings.Join(keys, "\n")
+
+	sum := sha256.Sum224([]byte(content))
+	name := fmt.Sprintf("%s%d-%s", deletionsPrefix(c.tenantID), time.Now().UnixNano(), hex.EncodeToString(sum[:8]))
+
+	if err := c.bucket.Upload(ctx, name, strings.NewReader(content)); err != nil {
+		return fmt.Errorf("failed to mark objects for deletion: %w", err)
+	}
+	return nil
+}
+
+// Cleanup deletes the objects that were replaced by compaction at least the
+// grace period before now.
+func (c *Compactor) Cleanup(ctx context.Context, now time.Time) error {
+	var markers []string
+	err := c.bucket.Iter(ctx, deletionsPrefix(c.tenantID), func(name string) error {
+		timestamp, _, _ := strings.Cut(path.Base(name), "-")
+		nanos, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			level.Warn(c.logger).Log("msg", "ignoring invalid deletion marker", "marker", name, "err", err)
+			return nil
+		}
+		if now.Sub(time.Unix(0, nanos)) >= c.cfg.GracePeriod {
+			markers = append(markers, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list deletion markers: %w", err)
+	}
+
+	for _, marker := range markers {
+		if err := c.deleteMarked(ctx, marker); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteMarked deletes the objects listed in marker, followed by the marker
+// itself. Objects which no longer exist are ignored, so an interrupted
+// cleanup can be retried.
+func (c *Compactor) deleteMarked(ctx context.Context, marker string) error {
+	reader, err := c.bucket.Get(ctx, marker)
+	if err != nil {
+		return fmt.Errorf("failed to read deletion marker %s: %w", marker, err)
+	}
+	content, err := io.ReadAll(reader)
+	_ = reader.Close()
+	if err != nil {
+		return fmt.Errorf("failed to read deletion marker %s: %w", marker, err)
+	}
+
+	for _, key := range strings.Split(string(content), "\n") {
+		if key == "" {
+			continue
+		}
+		if err := c.bucket.Delete(ctx, key); err != nil && !c.bucket.IsObjNotFoundErr(err) {
+			return fmt.Errorf("failed to delete object %s: %w", key, err)
+		}
+	}
+
+	if err := c.bucket.Delete(ctx, marker); err != nil && !c.bucket.IsObjNotFoundErr(err) {
+		return fmt.Errorf("failed to delete deletion marker %s: %w", marker, err)
+	}
+	level.Info(c.logger).Log("msg", "deleted compacted objects", "tenant", c.tenantID, "marker", marker)
+	return nil
+}
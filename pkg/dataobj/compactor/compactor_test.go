@@ -0,0 +1,250 @@
+package compactor
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/user"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+
+	"github.com/grafana/loki/v3/pkg/dataobj"
+	"github.com/grafana/loki/v3/pkg/dataobj/consumer/logsobj"
+	"github.com/grafana/loki/v3/pkg/dataobj/index"
+	"github.com/grafana/loki/v3/pkg/dataobj/index/indexobj"
+	"github.com/grafana/loki/v3/pkg/dataobj/metastore"
+	"github.com/grafana/loki/v3/pkg/dataobj/sections/logs"
+	"github.com/grafana/loki/v3/pkg/dataobj/uploader"
+	"github.com/grafana/loki/v3/pkg/logproto"
+)
+
+const tenantID = "test"
+
+var (
+	testBuilderConfig = logsobj.BuilderConfig{
+		TargetPageSize:    128 * 1024,
+		TargetObjectSize:  4 * 1024 * 1024,
+		TargetSectionSize: 2 * 1024 * 1024,
+
+		BufferSize:              4 * 1024 * 1024,
+		SectionStripeMergeLimit: 2,
+	}
+
+	testConfig = Config{
+		BuilderConfig:  testBuilderConfig,
+		UploaderConfig: uploader.Config{SHAPrefixSize: 2},
+		IndexBuilderConfig: indexobj.BuilderConfig{
+			TargetPageSize:    128 * 1024,
+			TargetObjectSize:  4 * 1024 * 1024,
+			TargetSectionSize: 2 * 1024 * 1024,
+
+			BufferSize:              4 * 1024 * 1024,
+			SectionStripeMergeLimit: 2,
+		},
+		IndexStoragePrefix: "index/",
+		GracePeriod:        time.Hour,
+		CompactionInterval: time.Minute,
+		CompactionLookback: 24 * time.Hour,
+	}
+
+	now = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// testEntry is a log line of a stream with an app label, written at offset
+// from now.
+type testEntry struct {
+	app    string
+	offset time.Duration
+}
+
+// buildObject builds a data object with the given entries and uploads it to
+// bucket.
+func buildObject(t *testing.T, bucket objstore.Bucket, path string, entries ...testEntry) {
+	t.Helper()
+
+	builder, err := logsobj.NewBuilder(testBuilderConfig)
+	require.NoError(t, err)
+
+	for _, entry := range entries {
+		err := builder.Append(logproto.Stream{
+			Labels: fmt.Sprintf(`{app=%q}`, entry.app),
+			Entries: []logproto.Entry{{
+				Timestamp: now.Add(entry.offset),
+				Line:      fmt.Sprintf("line from %s", entry.app),
+			}},
+		})
+		require.NoError(t, err)
+	}
+
+	var buf bytes.Buffer
+	_, err = builder.Flush(&buf)
+	require.NoError(t, err)
+	require.NoError(t, bucket.Upload(context.Background(), path, &buf))
+}
+
+// buildIndex builds an index object for the data objects at paths and adds it
+// to the metastore, like the index builder does. It returns the path of the
+// index object.
+func buildIndex(t *testing.T, bucket objstore.Bucket, paths ...string) string {
+	t.Helper()
+
+	ctx := context.Background()
+	indexBucket := objstore.NewPrefixedBucket(bucket, testConfig.IndexStoragePrefix)
+
+	indexBuilder, err := indexobj.NewBuilder(testConfig.IndexBuilderConfig)
+	require.NoError(t, err)
+	calculator := index.NewCalculator(indexBuilder)
+	for _, path := range paths {
+		obj, err := dataobj.FromBucket(ctx, bucket, path)
+		require.NoError(t, err)
+		require.NoError(t, calculator.Calculate(ctx, log.NewNopLogger(), obj, path))
+	}
+
+	var buf bytes.Buffer
+	stats, err := calculator.Flush(&buf)
+	require.NoError(t, err)
+	indexPath := index.ObjectKey(tenantID, &buf)
+	require.NoError(t, indexBucket.Upload(ctx, indexPath, &buf))
+
+	updater := metastore.NewUpdater(metastore.UpdaterConfig{StorageFormat: metastore.StorageFormatTypeV2}, indexBucket, tenantID, log.NewNopLogger())
+	require.NoError(t, updater.Update(ctx, indexPath, stats.MinTimestamp, stats.MaxTimestamp))
+	return indexPath
+}
+
+// readLines returns the log lines of all objects, grouped by the ID of
+// their stream.
+func readLines(t *testing.T, bucket objstore.Bucket, paths ...string) map[string][]string {
+	t.Helper()
+
+	result := make(map[string][]string)
+	for _, path := range paths {
+		obj, err := dataobj.FromBucket(context.Background(), bucket, path)
+		require.NoError(t, err)
+
+		for res := range logs.Iter(context.Background(), obj) {
+			record, err := res.Value()
+			require.NoError(t, err)
+			result[fmt.Sprint(record.StreamID)] = append(result[fmt.Sprint(record.StreamID)], string(record.Line))
+		}
+	}
+	return result
+}
+
+func countLines(lines map[string][]string) int {
+	var n int
+	for _, l := range lines {
+		n += len(l)
+	}
+	return n
+}
+
+func TestCompactor_CompactObjects(t *testing.T) {
+	ctx := user.InjectOrgID(context.Background(), tenantID)
+	bucket := objstore.NewInMemBucket()
+
+	mCfg := metastore.Config{Updater: metastore.UpdaterConfig{StorageFormat: metastore.StorageFormatTypeV1}}
+	updater := metastore.NewUpdater(mCfg.Updater, bucket, tenantID, log.NewNopLogger())
+
+	paths := []string{"objects/a", "objects/b", "objects/c"}
+	buildObject(t, bucket, paths[0], testEntry{"api", 0}, testEntry{"db", time.Second})
+	buildObject(t, bucket, paths[1], testEntry{"api", time.Minute})
+	buildObject(t, bucket, paths[2], testEntry{"db", 2 * time.Minute}, testEntry{"web", 2*time.Minute + time.Second})
+	for i, path := range paths {
+		start := now.Add(time.Duration(i) * time.Minute)
+		require.NoError(t, updater.Update(ctx, path, start, start.Add(time.Second)))
+	}
+
+	c, err := New(testConfig, mCfg, bucket, tenantID, log.NewNopLogger())
+	require.NoError(t, err)
+
+	added, err := c.CompactObjects(ctx, paths)
+	require.NoError(t, err)
+	require.Len(t, added, 1)
+	require.Equal(t, now, added[0].MinTimestamp)
+	require.Equal(t, now.Add(2*time.Minute+time.Second), added[0].MaxTimestamp)
+
+	// All logs are kept, and streams of the different objects are merged.
+	lines := readLines(t, bucket, added[0].Path)
+	require.Len(t, lines, 3)
+	require.Equal(t, 5, countLines(lines))
+
+	// The metastore only references the compacted object.
+	ms := metastore.NewObjectMetastore(bucket, log.NewNopLogger(), nil)
+	objects, err := ms.DataObjects(ctx, now.Add(-time.Hour), now.Add(time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, []string{added[0].Path}, objects)
+
+	// The original objects are only deleted after the grace period.
+	require.NoError(t, c.Cleanup(ctx, time.Now()))
+	for _, path := range paths {
+		exists, err := bucket.Exists(ctx, path)
+		require.NoError(t, err)
+		require.True(t, exists)
+	}
+
+	require.NoError(t, c.Cleanup(ctx, time.Now().Add(testConfig.GracePeriod)))
+	for _, path := range paths {
+		exists, err := bucket.Exists(ctx, path)
+		require.NoError(t, err)
+		require.False(t, exists)
+	}
+	exists, err := bucket.Exists(ctx, added[0].Path)
+	require.NoError(t, err)
+	require.True(t, exists)
+
+	// Markers are removed once their objects are deleted.
+	var markers []string
+	require.NoError(t, bucket.Iter(ctx, deletionsPrefix(tenantID), func(name string) error {
+		markers = append(markers, name)
+		return nil
+	}))
+	require.Empty(t, markers)
+}
+
+func TestCompactor_CompactIndex(t *testing.T) {
+	ctx := user.InjectOrgID(context.Background(), tenantID)
+	bucket := objstore.NewInMemBucket()
+	indexBucket := objstore.NewPrefixedBucket(bucket, testConfig.IndexStoragePrefix)
+
+	mCfg := metastore.Config{Updater: metastore.UpdaterConfig{StorageFormat: metastore.StorageFormatTypeV2}}
+
+	paths := []string{"objects/a", "objects/b"}
+	buildObject(t, bucket, paths[0], testEntry{"api", 0}, testEntry{"db", time.Second})
+	buildObject(t, bucket, paths[1], testEntry{"api", time.Minute})
+
+	indexPath := buildIndex(t, bucket, paths...)
+
+	c, err := New(testConfig, mCfg, bucket, tenantID, log.NewNopLogger())
+	require.NoError(t, err)
+
+	entry, err := c.CompactIndex(ctx, indexPath)
+	require.NoError(t, err)
+	require.NotEqual(t, indexPath, entry.Path)
+
+	// The metastore only references the new index object.
+	ms := metastore.NewObjectMetastore(indexBucket, log.NewNopLogger(), nil)
+	objects, err := ms.DataObjects(ctx, now.Add(-time.Hour), now.Add(time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, []string{entry.Path}, objects)
+
+	// The new index references a single compacted data object.
+	indexObj, err := dataobj.FromBucket(ctx, indexBucket, entry.Path)
+	require.NoError(t, err)
+	compacted, err := referencedObjects(ctx, indexObj)
+	require.NoError(t, err)
+	require.Len(t, compacted, 1)
+	require.Equal(t, 3, countLines(readLines(t, bucket, compacted...)))
+
+	// The original data objects and index object are deleted after the grace
+	// period.
+	require.NoError(t, c.Cleanup(ctx, time.Now().Add(testConfig.GracePeriod)))
+	for _, path := range append(paths, testConfig.IndexStoragePrefix+indexPath) {
+		exists, err := bucket.Exists(ctx, path)
+		require.NoError(t, err)
+		require.False(t, exists, path)
+	}
+}
@@ -0,0 +1,87 @@
+package compactor
+
+import (
+	"errors"
+	"flag"
+	"time"
+
+	"github.com/grafana/loki/v3/pkg/dataobj/consumer/logsobj"
+	"github.com/grafana/loki/v3/pkg/dataobj/index/indexobj"
+	"github.com/grafana/loki/v3/pkg/dataobj/uploader"
+)
+
+// Config configures a [Compactor].
+type Config struct {
+	logsobj.BuilderConfig `yaml:",inline"`
+	UploaderConfig        uploader.Config `yaml:"uploader"`
+
+	// IndexBuilderConfig configures the builder for index objects, which are
+	// rebuilt for compacted data objects when the metastore uses the v2
+	// storage format.
+	IndexBuilderConfig indexobj.BuilderConfig `yaml:"index_builder" experimental:"true"`
+
+	// IndexStoragePrefix is the prefix of index objects and the metastore
+	// objects that reference them. It must match the prefix used by the index
+	// builder.
+	IndexStoragePrefix string `yaml:"index_storage_prefix" experimental:"true"`
+
+	// GracePeriod is the time to wait after compaction before the original
+	// objects are deleted, so that queries which already resolved them from
+	// the metastore can finish.
+	GracePeriod time.Duration `yaml:"grace_period" experimental:"true"`
+
+	// CompactionInterval is the interval at which the compactor service looks
+	// for small objects to compact and deletes compacted objects.
+	CompactionInterval time.Duration `yaml:"compaction_interval" experimental:"true"`
+
+	// CompactionLookback limits compaction to metastore windows which ended
+	// within the lookback, so older objects are not inspected on every run.
+	CompactionLookback time.Duration `yaml:"compaction_lookback" experimental:"true"`
+
+	// RetentionEnabled enables the compactor service to delete logs which
+	// are older than the retention period of their tenant and stream.
+	RetentionEnabled bool `yaml:"retention_enabled" experimental:"true"`
+}
+
+// RegisterFlags registers flags for the compactor.
+func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
+	cfg.RegisterFlagsWithPrefix("dataobj-compactor.", f)
+}
+
+// RegisterFlagsWithPrefix registers flags with the given prefix.
+func (cfg *Config) RegisterFlagsWithPrefix(prefix string, f *flag.FlagSet) {
+	cfg.BuilderConfig.RegisterFlagsWithPrefix(prefix, f)
+	cfg.UploaderConfig.RegisterFlagsWithPrefix(prefix, f)
+	cfg.IndexBuilderConfig.RegisterFlagsWithPrefix(prefix+"index-builder.", f)
+	f.StringVar(&cfg.IndexStoragePrefix, prefix+"index-storage-prefix", "index/v0/", "Experimental: The prefix of index objects in object storage. Must match the prefix used by the index builder.")
+	f.DurationVar(&cfg.GracePeriod, prefix+"grace-period", 2*time.Hour, "Experimental: The time to wait after compaction before deleting the original data objects.")
+	f.DurationVar(&cfg.CompactionInterval, prefix+"compaction-interval", 10*time.Minute, "Experimental: The interval at which to compact small data objects and delete compacted data objects.")
+	f.DurationVar(&cfg.CompactionLookback, prefix+"compaction-lookback", 24*time.Hour, "Experimental: Only data objects of metastore windows which ended within the lookback are compacted.")
+	f.BoolVar(&cfg.RetentionEnabled, prefix+"retention-enabled", false, "Experimental: Delete logs of data objects which are older than the retention period configured in the limits, including per-stream retention.")
+}
+
+// Validate validates the Config.
+func (cfg *Config) Validate() error {
+	var errs []error
+
+	if err := cfg.BuilderConfig.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := cfg.UploaderConfig.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := cfg.IndexBuilderConfig.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if cfg.GracePeriod < 0 {
+		errs = append(errs, errors.New("GracePeriod must not be negative"))
+	}
+	if cfg.CompactionInterval <= 0 {
+		errs = append(errs, errors.New("CompactionInterval must be greater than 0"))
+	}
+	if cfg.CompactionLookback <= 0 {
+		errs = append(errs, errors.New("CompactionLookback must be greater than 0"))
+	}
+
+	return errors.Join(errs...)
+}
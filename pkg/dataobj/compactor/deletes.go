@@ -0,0 +1,192 @@
+package compactor
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/loki/v3/pkg/compactor/retention"
+	"github.com/grafana/loki/v3/pkg/dataobj"
+	"github.com/grafana/loki/v3/pkg/dataobj/metastore"
+	"github.com/grafana/loki/v3/pkg/dataobj/sections/streams"
+	"github.com/grafana/loki/v3/pkg/util/filter"
+)
+
+// A DeleteRequest selects logs of a tenant to delete. It is implemented by
+// the delete requests of pkg/compactor/deletion, which the delete requests
+// manager passes to [Service.ApplyDeletes] with their query and metric set.
+type DeleteRequest interface {
+	// Interval returns the time range of the logs to delete.
+	Interval() model.Interval
+
+	// IsDeleted returns true if the request selects logs of the stream with
+	// the given labels within the time range of chunk.
+	IsDeleted(userID []byte, lbls labels.Labels, chunk retention.Chunk) bool
+
+	// FilterFunction returns a filter which returns true for the logs of the
+	// stream with the given labels to delete.
+	FilterFunction(lbls labels.Labels) (filter.Func, error)
+}
+
+// ApplyDeletes deletes the logs of the tenant which match any of the delete
+// requests.
+//
+// Data objects with matching logs are rewritten without them; with the v2
+// storage format, the index objects which reference them are rebuilt as
+// well. The original objects are deleted after the grace period, like
+// compacted objects.
+func (c *Compactor) ApplyDeletes(ctx context.Context, reqs []DeleteRequest) error {
+	if len(reqs) == 0 {
+		return nil
+	}
+
+	var start, end model.Time
+	for i, req := range reqs {
+		interval := req.Interval()
+		if i == 0 || interval.Start < start {
+			start = interval.Start
+		}
+		if interval.End > end {
+			end = interval.End
+		}
+	}
+
+	// Logs are deleted if any of the requests matches them.
+	drop := func(lbs labels.Labels) (filter.Func, error) {
+		var filters []filter.Func
+		for _, req := range reqs {
+			f, err := req.FilterFunction(lbs)
+			if err != nil {
+				return nil, err
+			}
+			filters = append(filters, f)
+		}
+		return func(ts time.Time, line string, structuredMetadata labels.Labels) bool {
+			for _, f := range filters {
+				if f(ts, line, structuredMetadata) {
+					return true
+				}
+			}
+			return false
+		}, nil
+	}
+
+	paths, err := c.listObjects(ctx, start.Time(), end.Time().Add(time.Millisecond))
+	if err != nil {
+		return err
+	}
+
+	if c.format == metastore.StorageFormatTypeV2 {
+		for _, indexPath := range paths {
+			if err := c.applyIndexDeletes(ctx, indexPath, reqs, drop); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return c.applyObjectDeletes(ctx, paths, reqs, drop)
+}
+
+// applyObjectDeletes rewrites the data objects which are referenced by the
+// metastore directly (storage format v1) and contain logs matched by reqs.
+func (c *Compactor) applyObjectDeletes(ctx context.Context, paths []string, reqs []DeleteRequest, drop dropFunc) error {
+	var affected []string
+	for _, objectPath := range paths {
+		ok, err := c.matchesDeletes(ctx, objectPath, reqs)
+		if err != nil {
+			return err
+		} else if ok {
+			affected = append(affected, objectPath)
+		}
+	}
+	if len(affected) == 0 {
+		return nil
+	}
+
+	// Each object is rewritten separately to keep the time ranges in the
+	// metastore as narrow as they were.
+	var removed, added []metastore.Entry
+	for _, objectPath := range affected {
+		r, a, err := c.merge(ctx, []string{objectPath}, drop, nil)
+		if err != nil {
+			return err
+		}
+		removed = append(removed, r...)
+		added = append(added, a...)
+	}
+
+	if err := c.dataUpdater.Replace(ctx, removed, added); err != nil {
+		return fmt.Errorf("failed to update metastore: %w", err)
+	}
+	if err := c.markForDeletion(ctx, affected); err != nil {
+		return err
+	}
+
+	level.Info(c.logger).Log("msg", "applied deletes to data objects", "tenant", c.tenantID, "objects", len(affected), "rewritten_objects", len(added))
+	return nil
+}
+
+// applyIndexDeletes rewrites the data objects referenced by the index object
+// at indexPath (storage format v2) and the index object itself if any of the
+// data objects contain logs matched by reqs.
+func (c *Compactor) applyIndexDeletes(ctx context.Context, indexPath string, reqs []DeleteRequest, drop dropFunc) error {
+	paths, err := c.indexedObjects(ctx, indexPath)
+	if err != nil {
+		return err
+	}
+
+	var matches bool
+	for _, objectPath := range paths {
+		ok, err := c.matchesDeletes(ctx, objectPath, reqs)
+		if err != nil {
+			return err
+		} else if ok {
+			matches = true
+			break
+		}
+	}
+	if !matches {
+		return nil
+	}
+
+	if _, err := c.rewriteIndex(ctx, indexPath, paths, drop); err != nil {
+		return err
+	}
+
+	level.Info(c.logger).Log("msg", "applied deletes to index", "tenant", c.tenantID, "index", indexPath, "objects", len(paths))
+	return nil
+}
+
+// matchesDeletes returns true if the data object at objectPath has a stream
+// with logs in the time range of one of the requests whose labels match the
+// request's selector. Line filters of the requests are only evaluated when
+// the object is rewritten.
+func (c *Compactor) matchesDeletes(ctx context.Context, objectPath string, reqs []DeleteRequest) (bool, error) {
+	obj, err := dataobj.FromBucket(ctx, c.bucket, objectPath)
+	if err != nil {
+		return false, fmt.Errorf("failed to open object %s: %w", objectPath, err)
+	}
+
+	userID := []byte(c.tenantID)
+	for result := range streams.Iter(ctx, obj) {
+		stream, err := result.Value()
+		if err != nil {
+			return false, fmt.Errorf("failed to read streams of %s: %w", objectPath, err)
+		}
+
+		chunk := retention.Chunk{
+			From:    model.TimeFromUnixNano(stream.MinTimestamp.UnixNano()),
+			Through: model.TimeFromUnixNano(stream.MaxTimestamp.UnixNano()),
+		}
+		for _, req := range reqs {
+			if req.IsDeleted(userID, stream.Labels, chunk) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,144 @@
+package compactor
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/user"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+
+	"github.com/grafana/loki/v3/pkg/compactor/retention"
+	"github.com/grafana/loki/v3/pkg/dataobj/metastore"
+	"github.com/grafana/loki/v3/pkg/util/filter"
+)
+
+// testDeleteRequest deletes the logs of streams with an app label, optionally
+// only those lines that contain a substring.
+type testDeleteRequest struct {
+	app        string
+	contains   string
+	start, end time.Time
+}
+
+func (r testDeleteRequest) Interval() model.Interval {
+	return model.Interval{Start: model.TimeFromUnixNano(r.start.UnixNano()), End: model.TimeFromUnixNano(r.end.UnixNano())}
+}
+
+func (r testDeleteRequest) IsDeleted(userID []byte, lbls labels.Labels, chunk retention.Chunk) bool {
+	interval := r.Interval()
+	return string(userID) == tenantID && lbls.Get("app") == r.app && chunk.From <= interval.End && chunk.Through >= interval.Start
+}
+
+func (r testDeleteRequest) FilterFunction(lbls labels.Labels) (filter.Func, error) {
+	return func(ts time.Time, line string, _ labels.Labels) bool {
+		return lbls.Get("app") == r.app && !ts.Before(r.start) && !ts.After(r.end) && strings.Contains(line, r.contains)
+	}, nil
+}
+
+func TestCompactor_ApplyDeletes(t *testing.T) {
+	ctx := user.InjectOrgID(context.Background(), tenantID)
+	bucket := objstore.NewInMemBucket()
+
+	mCfg := metastore.Config{Updater: metastore.UpdaterConfig{StorageFormat: metastore.StorageFormatTypeV1}}
+	updater := metastore.NewUpdater(mCfg.Updater, bucket, tenantID, log.NewNopLogger())
+
+	buildObject(t, bucket, "objects/deleted", testEntry{"api", 0})
+	buildObject(t, bucket, "objects/partial", testEntry{"api", time.Minute}, testEntry{"db", time.Minute})
+	buildObject(t, bucket, "objects/other", testEntry{"db", 0})
+	buildObject(t, bucket, "objects/later", testEntry{"api", time.Hour})
+	require.NoError(t, updater.Update(ctx, "objects/deleted", now, now))
+	require.NoError(t, updater.Update(ctx, "objects/partial", now.Add(time.Minute), now.Add(time.Minute)))
+	require.NoError(t, updater.Update(ctx, "objects/other", now, now))
+	require.NoError(t, updater.Update(ctx, "objects/later", now.Add(time.Hour), now.Add(time.Hour)))
+
+	c, err := New(testConfig, mCfg, bucket, tenantID, log.NewNopLogger())
+	require.NoError(t, err)
+	require.NoError(t, c.ApplyDeletes(ctx, []DeleteRequest{
+		testDeleteRequest{app: "api", start: now, end: now.Add(time.Minute)},
+	}))
+
+	ms := metastore.NewObjectMetastore(bucket, log.NewNopLogger(), nil)
+	objects, err := ms.DataObjects(ctx, now.Add(-time.Hour), now.Add(2*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, objects, 3)
+	require.Contains(t, objects, "objects/other")
+	require.Contains(t, objects, "objects/later")
+	require.NotContains(t, objects, "objects/deleted")
+	require.NotContains(t, objects, "objects/partial")
+
+	// The partially deleted object is rewritten with the logs of the other
+	// stream.
+	for _, path := range objects {
+		if path == "objects/other" || path == "objects/later" {
+			continue
+		}
+		lines := readLines(t, bucket, path)
+		require.Equal(t, 1, countLines(lines))
+		for _, l := range lines {
+			require.Equal(t, []string{"line from db"}, l)
+		}
+	}
+
+	require.NoError(t, c.Cleanup(ctx, time.Now().Add(testConfig.GracePeriod)))
+	for path, expected := range map[string]bool{
+		"objects/deleted": false,
+		"objects/partial": false,
+		"objects/other":   true,
+		"objects/later":   true,
+	} {
+		exists, err := bucket.Exists(ctx, path)
+		require.NoError(t, err)
+		require.Equal(t, expected, exists, path)
+	}
+}
+
+func TestCompactor_ApplyDeletes_Index(t *testing.T) {
+	ctx := user.InjectOrgID(context.Background(), tenantID)
+	bucket := objstore.NewInMemBucket()
+	indexBucket := objstore.NewPrefixedBucket(bucket, testConfig.IndexStoragePrefix)
+
+	mCfg := metastore.Config{Updater: metastore.UpdaterConfig{StorageFormat: metastore.StorageFormatTypeV2}}
+
+	buildObject(t, bucket, "objects/deleted", testEntry{"api", 0})
+	buildObject(t, bucket, "objects/partial", testEntry{"api", 0}, testEntry{"db", time.Minute})
+	buildObject(t, bucket, "objects/other", testEntry{"db", 0})
+	deletedIndex := buildIndex(t, bucket, "objects/deleted")
+	partialIndex := buildIndex(t, bucket, "objects/partial")
+	otherIndex := buildIndex(t, bucket, "objects/other")
+
+	c, err := New(testConfig, mCfg, bucket, tenantID, log.NewNopLogger())
+	require.NoError(t, err)
+	require.NoError(t, c.ApplyDeletes(ctx, []DeleteRequest{
+		testDeleteRequest{app: "api", contains: "api", start: now, end: now},
+	}))
+
+	// The index without remaining logs is removed and the partially deleted
+	// index is replaced.
+	ms := metastore.NewObjectMetastore(indexBucket, log.NewNopLogger(), nil)
+	indexes, err := ms.DataObjects(ctx, now.Add(-time.Hour), now.Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, indexes, 2)
+	require.Contains(t, indexes, otherIndex)
+	require.NotContains(t, indexes, deletedIndex)
+	require.NotContains(t, indexes, partialIndex)
+
+	for _, indexPath := range indexes {
+		if indexPath == otherIndex {
+			continue
+		}
+		paths, err := c.indexedObjects(ctx, indexPath)
+		require.NoError(t, err)
+		require.Len(t, paths, 1)
+		lines := readLines(t, bucket, paths...)
+		require.Equal(t, 1, countLines(lines))
+		for _, l := range lines {
+			require.Equal(t, []string{"line from db"}, l)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package compactor
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	statusFailure = "failure"
+	statusSuccess = "success"
+)
+
+type metrics struct {
+	runsTotal          *prometheus.CounterVec
+	runDurationSeconds prometheus.Histogram
+	lastSuccessfulRun  prometheus.Gauge
+}
+
+func newMetrics(r prometheus.Registerer) *metrics {
+	return &metrics{
+		runsTotal: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "loki_dataobj_compactor",
+			Name:      "runs_total",
+			Help:      "Total number of compaction runs by status.",
+		}, []string{"status"}),
+		runDurationSeconds: promauto.With(r).NewHistogram(prometheus.HistogramOpts{
+			Namespace: "loki_dataobj_compactor",
+			Name:      "run_duration_seconds",
+			Help:      "Time spent in compaction runs.",
+			Buckets:   prometheus.ExponentialBuckets(1, 4, 8),
+		}),
+		lastSuccessfulRun: promauto.With(r).NewGauge(prometheus.GaugeOpts{
+			Namespace: "loki_dataobj_compactor",
+			Name:      "last_successful_run_timestamp_seconds",
+			Help:      "Unix timestamp of the last successful compaction run.",
+		}),
+	}
+}
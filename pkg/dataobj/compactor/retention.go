@@ -0,0 +1,213 @@
+package compactor
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/loki/v3/pkg/compactor/retention"
+	"github.com/grafana/loki/v3/pkg/dataobj"
+	"github.com/grafana/loki/v3/pkg/dataobj/metastore"
+	"github.com/grafana/loki/v3/pkg/dataobj/sections/streams"
+)
+
+// expiration describes how many of the logs of a data object have expired.
+type expiration int
+
+const (
+	expirationNone    expiration = iota // No logs have expired.
+	expirationPartial                   // Some logs have expired.
+	expirationFull                      // All logs have expired.
+)
+
+// ApplyRetention deletes the logs of the tenant which are older than the
+// retention period configured in limits, including per-stream retention.
+//
+// Data objects in which all logs have expired are removed from the metastore,
+// and data objects in which only some logs have expired are rewritten without
+// the expired logs. The original objects are deleted after the grace period,
+// like compacted objects.
+func (c *Compactor) ApplyRetention(ctx context.Context, limits retention.Limits, now time.Time) error {
+	minPeriod := smallestRetentionPeriod(limits, c.tenantID)
+	if minPeriod <= 0 {
+		return nil
+	}
+
+	snapshot := retention.NewTenantRetentionSnapshot(limits, c.tenantID)
+	cutoff := cutoffFunc(func(lbs labels.Labels) time.Time {
+		period := snapshot.RetentionPeriodFor(lbs)
+		// The 0 value disables retention.
+		if period <= 0 {
+			return time.Time{}
+		}
+		return now.Add(-period)
+	})
+
+	// Only objects with logs older than the smallest retention period can
+	// contain expired logs.
+	paths, err := c.listObjects(ctx, time.Time{}, now.Add(-minPeriod))
+	if err != nil {
+		return err
+	}
+
+	if c.format == metastore.StorageFormatTypeV2 {
+		for _, indexPath := range paths {
+			if err := c.applyIndexRetention(ctx, indexPath, cutoff); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return c.applyObjectRetention(ctx, paths, cutoff)
+}
+
+// smallestRetentionPeriod returns the smallest enabled retention period of the
+// tenant, or 0 if retention is disabled.
+func smallestRetentionPeriod(limits retention.Limits, tenantID string) time.Duration {
+	smallest := limits.RetentionPeriod(tenantID)
+	for _, streamRetention := range limits.StreamRetention(tenantID) {
+		period := time.Duration(streamRetention.Period)
+		if period > 0 && (smallest <= 0 || period < smallest) {
+			smallest = period
+		}
+	}
+	return smallest
+}
+
+// applyObjectRetention applies retention to data objects which are
+// referenced by the metastore directly (storage format v1).
+func (c *Compactor) applyObjectRetention(ctx context.Context, paths []string, cutoff cutoffFunc) error {
+	var (
+		removed, added []metastore.Entry
+		deleted        []string
+	)
+
+	for _, objectPath := range paths {
+		entry, exp, err := c.inspectObject(ctx, objectPath, cutoff)
+		if err != nil {
+			return err
+		}
+
+		switch exp {
+		case expirationNone:
+			continue
+		case expirationFull:
+			removed = append(removed, entry)
+		case expirationPartial:
+			r, a, err := c.merge(ctx, []string{objectPath}, cutoff.drop(), nil)
+			if err != nil {
+				return err
+			}
+			removed = append(removed, r...)
+			added = append(added, a...)
+		}
+		deleted = append(deleted, objectPath)
+	}
+
+	if len(deleted) == 0 {
+		return nil
+	}
+	if err := c.dataUpdater.Replace(ctx, removed, added); err != nil {
+		return fmt.Errorf("failed to update metastore: %w", err)
+	}
+	if err := c.markForDeletion(ctx, deleted); err != nil {
+		return err
+	}
+
+	level.Info(c.logger).Log("msg", "applied retention to data objects", "tenant", c.tenantID, "objects", len(deleted), "rewritten_objects", len(added))
+	return nil
+}
+
+// applyIndexRetention applies retention to the data objects referenced by an
+// index object (storage format v2). If all logs of the data objects have
+// expired, the index object is removed from the metastore. Otherwise, the
+// data objects and the index object are rewritten if any of their logs have
+// expired.
+func (c *Compactor) applyIndexRetention(ctx context.Context, indexPath string, cutoff cutoffFunc) error {
+	paths, err := c.indexedObjects(ctx, indexPath)
+	if err != nil {
+		return err
+	}
+
+	var (
+		entries []metastore.Entry
+		full    = true
+		none    = true
+	)
+	for _, objectPath := range paths {
+		entry, exp, err := c.inspectObject(ctx, objectPath, cutoff)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, entry)
+		full = full && exp == expirationFull
+		none = none && exp == expirationNone
+	}
+
+	switch {
+	case none:
+		return nil
+
+	case full:
+		indexEntry := timeRange(entries)
+		indexEntry.Path = indexPath
+		if err := c.indexUpdater.Replace(ctx, []metastore.Entry{indexEntry}, nil); err != nil {
+			return fmt.Errorf("failed to update metastore: %w", err)
+		}
+		if err := c.markForDeletion(ctx, append(paths, c.indexKey(indexPath))); err != nil {
+			return err
+		}
+
+	default:
+		if _, err := c.rewriteIndex(ctx, indexPath, paths, cutoff.drop()); err != nil {
+			return err
+		}
+	}
+
+	level.Info(c.logger).Log("msg", "applied retention to index", "tenant", c.tenantID, "index", indexPath, "objects", len(paths), "expired", full)
+	return nil
+}
+
+// inspectObject returns the entry of the data object at objectPath and how
+// many of its logs are older than the cutoff of their stream.
+func (c *Compactor) inspectObject(ctx context.Context, objectPath string, cutoff cutoffFunc) (metastore.Entry, expiration, error) {
+	obj, err := dataobj.FromBucket(ctx, c.bucket, objectPath)
+	if err != nil {
+		return metastore.Entry{}, expirationNone, fmt.Errorf("failed to open object %s: %w", objectPath, err)
+	}
+
+	var (
+		entry = metastore.Entry{Path: objectPath}
+		full  = true
+		none  = true
+	)
+	for result := range streams.Iter(ctx, obj) {
+		stream, err := result.Value()
+		if err != nil {
+			return metastore.Entry{}, expirationNone, fmt.Errorf("failed to read streams of %s: %w", objectPath, err)
+		}
+
+		if entry.MinTimestamp.IsZero() || stream.MinTimestamp.Before(entry.MinTimestamp) {
+			entry.MinTimestamp = stream.MinTimestamp
+		}
+		if stream.MaxTimestamp.After(entry.MaxTimestamp) {
+			entry.MaxTimestamp = stream.MaxTimestamp
+		}
+
+		streamCutoff := cutoff(stream.Labels)
+		full = full && stream.MaxTimestamp.Before(streamCutoff)
+		none = none && !stream.MinTimestamp.Before(streamCutoff)
+	}
+
+	switch {
+	case none:
+		return entry, expirationNone, nil
+	case full:
+		return entry, expirationFull, nil
+	default:
+		return entry, expirationPartial, nil
+	}
+}
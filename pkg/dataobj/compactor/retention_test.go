@@ -0,0 +1,154 @@
+package compactor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/flagext"
+	"github.com/grafana/dskit/user"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+
+	"github.com/grafana/loki/v3/pkg/dataobj/metastore"
+	"github.com/grafana/loki/v3/pkg/validation"
+)
+
+func newRetentionLimits(t *testing.T) *validation.Overrides {
+	t.Helper()
+
+	var limits validation.Limits
+	flagext.DefaultValues(&limits)
+	limits.RetentionPeriod = model.Duration(24 * time.Hour)
+	limits.StreamRetention = []validation.StreamRetention{{
+		Period:   model.Duration(60 * time.Hour),
+		Priority: 1,
+		Selector: `{app="web"}`,
+		Matchers: []*labels.Matcher{labels.MustNewMatcher(labels.MatchEqual, "app", "web")},
+	}}
+
+	overrides, err := validation.NewOverrides(limits, nil)
+	require.NoError(t, err)
+	return overrides
+}
+
+func TestCompactor_ApplyRetention(t *testing.T) {
+	ctx := user.InjectOrgID(context.Background(), tenantID)
+	bucket := objstore.NewInMemBucket()
+
+	mCfg := metastore.Config{Updater: metastore.UpdaterConfig{StorageFormat: metastore.StorageFormatTypeV1}}
+	updater := metastore.NewUpdater(mCfg.Updater, bucket, tenantID, log.NewNopLogger())
+
+	// Retention is applied 50h after now, so only logs of web streams written
+	// at now are retained.
+	buildObject(t, bucket, "objects/expired", testEntry{"api", 0}, testEntry{"db", time.Second})
+	buildObject(t, bucket, "objects/partial", testEntry{"api", 0}, testEntry{"db", 40 * time.Hour})
+	buildObject(t, bucket, "objects/web", testEntry{"web", 0})
+	buildObject(t, bucket, "objects/recent", testEntry{"api", 40 * time.Hour})
+	require.NoError(t, updater.Update(ctx, "objects/expired", now, now.Add(time.Second)))
+	require.NoError(t, updater.Update(ctx, "objects/partial", now, now.Add(40*time.Hour)))
+	require.NoError(t, updater.Update(ctx, "objects/web", now, now))
+	require.NoError(t, updater.Update(ctx, "objects/recent", now.Add(40*time.Hour), now.Add(40*time.Hour)))
+
+	c, err := New(testConfig, mCfg, bucket, tenantID, log.NewNopLogger())
+	require.NoError(t, err)
+	require.NoError(t, c.ApplyRetention(ctx, newRetentionLimits(t), now.Add(50*time.Hour)))
+
+	ms := metastore.NewObjectMetastore(bucket, log.NewNopLogger(), nil)
+	objects, err := ms.DataObjects(ctx, now.Add(-time.Hour), now.Add(50*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, objects, 3)
+	require.Contains(t, objects, "objects/web")
+	require.Contains(t, objects, "objects/recent")
+	require.NotContains(t, objects, "objects/expired")
+	require.NotContains(t, objects, "objects/partial")
+
+	// The partially expired object is rewritten without the expired logs.
+	for _, path := range objects {
+		if path == "objects/web" || path == "objects/recent" {
+			continue
+		}
+		lines := readLines(t, bucket, path)
+		require.Equal(t, 1, countLines(lines))
+		for _, l := range lines {
+			require.Equal(t, []string{"line from db"}, l)
+		}
+	}
+
+	require.NoError(t, c.Cleanup(ctx, time.Now().Add(testConfig.GracePeriod)))
+	for path, expected := range map[string]bool{
+		"objects/expired": false,
+		"objects/partial": false,
+		"objects/web":     true,
+		"objects/recent":  true,
+	} {
+		exists, err := bucket.Exists(ctx, path)
+		require.NoError(t, err)
+		require.Equal(t, expected, exists, path)
+	}
+}
+
+func TestCompactor_ApplyRetention_Index(t *testing.T) {
+	ctx := user.InjectOrgID(context.Background(), tenantID)
+	bucket := objstore.NewInMemBucket()
+	indexBucket := objstore.NewPrefixedBucket(bucket, testConfig.IndexStoragePrefix)
+
+	mCfg := metastore.Config{Updater: metastore.UpdaterConfig{StorageFormat: metastore.StorageFormatTypeV2}}
+
+	buildObject(t, bucket, "objects/expired", testEntry{"api", 0})
+	buildObject(t, bucket, "objects/partial", testEntry{"api", 0}, testEntry{"db", 40 * time.Hour})
+	buildObject(t, bucket, "objects/web", testEntry{"web", 0})
+	expiredIndex := buildIndex(t, bucket, "objects/expired")
+	partialIndex := buildIndex(t, bucket, "objects/partial", "objects/web")
+
+	c, err := New(testConfig, mCfg, bucket, tenantID, log.NewNopLogger())
+	require.NoError(t, err)
+	require.NoError(t, c.ApplyRetention(ctx, newRetentionLimits(t), now.Add(50*time.Hour)))
+
+	// The expired index is removed and the partially expired index is replaced.
+	ms := metastore.NewObjectMetastore(indexBucket, log.NewNopLogger(), nil)
+	indexes, err := ms.DataObjects(ctx, now.Add(-time.Hour), now.Add(50*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, indexes, 1)
+	require.NotEqual(t, expiredIndex, indexes[0])
+	require.NotEqual(t, partialIndex, indexes[0])
+
+	paths, err := c.indexedObjects(ctx, indexes[0])
+	require.NoError(t, err)
+	require.Len(t, paths, 1)
+	require.Equal(t, 2, countLines(readLines(t, bucket, paths...)))
+
+	require.NoError(t, c.Cleanup(ctx, time.Now().Add(testConfig.GracePeriod)))
+	for _, path := range []string{"objects/expired", "objects/partial", "objects/web", c.indexKey(expiredIndex), c.indexKey(partialIndex)} {
+		exists, err := bucket.Exists(ctx, path)
+		require.NoError(t, err)
+		require.False(t, exists, path)
+	}
+}
+
+func TestCompactor_ApplyRetention_Disabled(t *testing.T) {
+	ctx := user.InjectOrgID(context.Background(), tenantID)
+	bucket := objstore.NewInMemBucket()
+
+	mCfg := metastore.Config{Updater: metastore.UpdaterConfig{StorageFormat: metastore.StorageFormatTypeV1}}
+	updater := metastore.NewUpdater(mCfg.Updater, bucket, tenantID, log.NewNopLogger())
+	buildObject(t, bucket, "objects/old", testEntry{"api", 0})
+	require.NoError(t, updater.Update(ctx, "objects/old", now, now))
+
+	var limits validation.Limits
+	flagext.DefaultValues(&limits)
+	overrides, err := validation.NewOverrides(limits, nil)
+	require.NoError(t, err)
+
+	c, err := New(testConfig, mCfg, bucket, tenantID, log.NewNopLogger())
+	require.NoError(t, err)
+	require.NoError(t, c.ApplyRetention(ctx, overrides, now.Add(365*24*time.Hour)))
+
+	ms := metastore.NewObjectMetastore(bucket, log.NewNopLogger(), nil)
+	objects, err := ms.DataObjects(ctx, now.Add(-time.Hour), now.Add(time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, []string{"objects/old"}, objects)
+}
@@ -0,0 +1,147 @@
+package compactor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/services"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/thanos-io/objstore"
+
+	"github.com/grafana/loki/v3/pkg/compactor/retention"
+	"github.com/grafana/loki/v3/pkg/dataobj/metastore"
+)
+
+// Service periodically applies retention to the data objects of all tenants
+// if enabled, compacts their small data objects, and deletes the objects
+// replaced by compaction once their grace period has passed.
+//
+// When running in the same process as the compactor, the compactor's delete
+// requests manager applies delete requests through [Service.ApplyDeletes].
+//
+// Only a single instance of Service may run against a bucket at a time.
+type Service struct {
+	services.Service
+
+	// mtx serializes compaction runs and delete requests, which both
+	// replace data objects of the same tenants.
+	mtx sync.Mutex
+
+	cfg     Config
+	mCfg    metastore.Config
+	bucket  objstore.Bucket
+	limits  retention.Limits
+	logger  log.Logger
+	metrics *metrics
+}
+
+// NewService creates a new compactor [Service].
+//
+// NewService returns an error if the provided config is invalid.
+func NewService(cfg Config, mCfg metastore.Config, bucket objstore.Bucket, limits retention.Limits, logger log.Logger, reg prometheus.Registerer) (*Service, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	s := &Service{
+		cfg:     cfg,
+		mCfg:    mCfg,
+		bucket:  bucket,
+		limits:  limits,
+		logger:  log.With(logger, "component", "dataobj-compactor"),
+		metrics: newMetrics(reg),
+	}
+	s.Service = services.NewTimerService(cfg.CompactionInterval, nil, s.iteration, nil)
+	return s, nil
+}
+
+func (s *Service) iteration(ctx context.Context) error {
+	// Errors are logged and retried on the next iteration, so they don't stop
+	// the service.
+	_ = s.RunOnce(ctx, time.Now())
+	return nil
+}
+
+// RunOnce applies retention to the data objects of all tenants if enabled,
+// compacts their small data objects and deletes the objects replaced by
+// compaction at least the grace period before now.
+// Tenants are processed independently, so a failing tenant doesn't block
+// the others.
+func (s *Service) RunOnce(ctx context.Context, now time.Time) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	start := time.Now()
+	defer func() { s.metrics.runDurationSeconds.Observe(time.Since(start).Seconds()) }()
+
+	metastoreBucket := s.bucket
+	if s.mCfg.Updater.StorageFormat == metastore.StorageFormatTypeV2 {
+		metastoreBucket = objstore.NewPrefixedBucket(s.bucket, s.cfg.IndexStoragePrefix)
+	}
+
+	tenants, err := metastore.Tenants(ctx, metastoreBucket)
+	if err != nil {
+		level.Error(s.logger).Log("msg", "failed to list tenants", "err", err)
+		s.metrics.runsTotal.WithLabelValues(statusFailure).Inc()
+		return fmt.Errorf("failed to list tenants: %w", err)
+	}
+
+	var errs []error
+	for _, tenantID := range tenants {
+		if err := s.runTenant(ctx, tenantID, now); err != nil {
+			level.Error(s.logger).Log("msg", "failed to compact tenant", "tenant", tenantID, "err", err)
+			errs = append(errs, fmt.Errorf("tenant %s: %w", tenantID, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		s.metrics.runsTotal.WithLabelValues(statusFailure).Inc()
+		return errors.Join(errs...)
+	}
+	s.metrics.runsTotal.WithLabelValues(statusSuccess).Inc()
+	s.metrics.lastSuccessfulRun.SetToCurrentTime()
+	return nil
+}
+
+func (s *Service) runTenant(ctx context.Context, tenantID string, now time.Time) error {
+	c, err := New(s.cfg, s.mCfg, s.bucket, tenantID, s.logger)
+	if err != nil {
+		return err
+	}
+
+	// Retention is applied first, so that expired logs aren't compacted.
+	if s.cfg.RetentionEnabled {
+		if err := c.ApplyRetention(ctx, s.limits, now); err != nil {
+			return fmt.Errorf("failed to apply retention: %w", err)
+		}
+	}
+	if err := c.Compact(ctx, now.Add(-s.cfg.CompactionLookback), now); err != nil {
+		return fmt.Errorf("failed to compact objects: %w", err)
+	}
+	if err := c.Cleanup(ctx, now); err != nil {
+		return fmt.Errorf("failed to delete compacted objects: %w", err)
+	}
+	return nil
+}
+
+// ApplyDeletes deletes the logs of the tenant which match any of the delete
+// requests from its data objects. It returns once the affected data objects
+// are rewritten, so that the requests can be marked as processed.
+func (s *Service) ApplyDeletes(ctx context.Context, tenantID string, reqs []DeleteRequest) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	c, err := New(s.cfg, s.mCfg, s.bucket, tenantID, s.logger)
+	if err != nil {
+		return err
+	}
+	if err := c.ApplyDeletes(ctx, reqs); err != nil {
+		return fmt.Errorf("failed to apply delete requests: %w", err)
+	}
+	return nil
+}
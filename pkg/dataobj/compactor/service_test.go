@@ -0,0 +1,141 @@
+package compactor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/user"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+
+	"github.com/grafana/loki/v3/pkg/dataobj"
+	"github.com/grafana/loki/v3/pkg/dataobj/metastore"
+)
+
+func TestService_RunOnce(t *testing.T) {
+	ctx := user.InjectOrgID(context.Background(), tenantID)
+	bucket := objstore.NewInMemBucket()
+
+	mCfg := metastore.Config{Updater: metastore.UpdaterConfig{StorageFormat: metastore.StorageFormatTypeV1}}
+	updater := metastore.NewUpdater(mCfg.Updater, bucket, tenantID, log.NewNopLogger())
+
+	// Objects a and b are in the first metastore window, while object c is in
+	// the next window, which hasn't ended at the time of the run.
+	buildObject(t, bucket, "objects/a", testEntry{"api", 0}, testEntry{"db", time.Second})
+	buildObject(t, bucket, "objects/b", testEntry{"api", time.Minute})
+	buildObject(t, bucket, "objects/c", testEntry{"api", metastore.WindowSize + time.Minute})
+	require.NoError(t, updater.Update(ctx, "objects/a", now, now.Add(time.Second)))
+	require.NoError(t, updater.Update(ctx, "objects/b", now.Add(time.Minute), now.Add(time.Minute)))
+	require.NoError(t, updater.Update(ctx, "objects/c", now.Add(metastore.WindowSize+time.Minute), now.Add(metastore.WindowSize+time.Minute)))
+
+	s, err := NewService(testConfig, mCfg, bucket, nil, log.NewNopLogger(), prometheus.NewRegistry())
+	require.NoError(t, err)
+	require.NoError(t, s.RunOnce(context.Background(), now.Add(metastore.WindowSize+time.Hour)))
+
+	ms := metastore.NewObjectMetastore(bucket, log.NewNopLogger(), nil)
+	objects, err := ms.DataObjects(ctx, now, now.Add(2*metastore.WindowSize))
+	require.NoError(t, err)
+	require.Len(t, objects, 2)
+	require.Contains(t, objects, "objects/c")
+	require.NotContains(t, objects, "objects/a")
+	require.NotContains(t, objects, "objects/b")
+
+	// A window with a single small object is left alone by later runs.
+	require.NoError(t, s.RunOnce(context.Background(), now.Add(metastore.WindowSize+time.Hour)))
+
+	compacted, err := ms.DataObjects(ctx, now, now.Add(2*metastore.WindowSize))
+	require.NoError(t, err)
+	require.ElementsMatch(t, objects, compacted)
+}
+
+func TestService_RunOnce_Index(t *testing.T) {
+	ctx := user.InjectOrgID(context.Background(), tenantID)
+	bucket := objstore.NewInMemBucket()
+	indexBucket := objstore.NewPrefixedBucket(bucket, testConfig.IndexStoragePrefix)
+
+	mCfg := metastore.Config{Updater: metastore.UpdaterConfig{StorageFormat: metastore.StorageFormatTypeV2}}
+
+	buildObject(t, bucket, "objects/a", testEntry{"api", 0}, testEntry{"db", time.Second})
+	buildObject(t, bucket, "objects/b", testEntry{"api", time.Minute})
+	indexPath := buildIndex(t, bucket, "objects/a", "objects/b")
+
+	s, err := NewService(testConfig, mCfg, bucket, nil, log.NewNopLogger(), prometheus.NewRegistry())
+	require.NoError(t, err)
+
+	// The window of the index hasn't ended yet, so it isn't compacted.
+	require.NoError(t, s.RunOnce(context.Background(), now.Add(time.Hour)))
+	ms := metastore.NewObjectMetastore(indexBucket, log.NewNopLogger(), nil)
+	objects, err := ms.DataObjects(ctx, now.Add(-time.Hour), now.Add(time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, []string{indexPath}, objects)
+
+	require.NoError(t, s.RunOnce(context.Background(), now.Add(metastore.WindowSize+time.Hour)))
+	objects, err = ms.DataObjects(ctx, now.Add(-time.Hour), now.Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, objects, 1)
+	require.NotEqual(t, indexPath, objects[0])
+
+	indexObj, err := dataobj.FromBucket(ctx, indexBucket, objects[0])
+	require.NoError(t, err)
+	paths, err := referencedObjects(ctx, indexObj)
+	require.NoError(t, err)
+	require.Len(t, paths, 1)
+}
+
+func TestService_RunOnce_Retention(t *testing.T) {
+	ctx := user.InjectOrgID(context.Background(), tenantID)
+	bucket := objstore.NewInMemBucket()
+
+	mCfg := metastore.Config{Updater: metastore.UpdaterConfig{StorageFormat: metastore.StorageFormatTypeV1}}
+	updater := metastore.NewUpdater(mCfg.Updater, bucket, tenantID, log.NewNopLogger())
+
+	buildObject(t, bucket, "objects/expired", testEntry{"api", 0})
+	require.NoError(t, updater.Update(ctx, "objects/expired", now, now))
+
+	ms := metastore.NewObjectMetastore(bucket, log.NewNopLogger(), nil)
+	limits := newRetentionLimits(t)
+
+	// Retention is only applied if enabled.
+	s, err := NewService(testConfig, mCfg, bucket, limits, log.NewNopLogger(), prometheus.NewRegistry())
+	require.NoError(t, err)
+	require.NoError(t, s.RunOnce(context.Background(), now.Add(50*time.Hour)))
+	objects, err := ms.DataObjects(ctx, now.Add(-time.Hour), now.Add(time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, []string{"objects/expired"}, objects)
+
+	cfg := testConfig
+	cfg.RetentionEnabled = true
+	s, err = NewService(cfg, mCfg, bucket, limits, log.NewNopLogger(), prometheus.NewRegistry())
+	require.NoError(t, err)
+	require.NoError(t, s.RunOnce(context.Background(), now.Add(50*time.Hour)))
+	objects, err = ms.DataObjects(ctx, now.Add(-time.Hour), now.Add(time.Hour))
+	require.NoError(t, err)
+	require.Empty(t, objects)
+}
+
+func TestService_ApplyDeletes(t *testing.T) {
+	ctx := user.InjectOrgID(context.Background(), tenantID)
+	bucket := objstore.NewInMemBucket()
+
+	mCfg := metastore.Config{Updater: metastore.UpdaterConfig{StorageFormat: metastore.StorageFormatTypeV1}}
+	updater := metastore.NewUpdater(mCfg.Updater, bucket, tenantID, log.NewNopLogger())
+
+	buildObject(t, bucket, "objects/deleted", testEntry{"api", 0})
+	buildObject(t, bucket, "objects/other", testEntry{"db", 0})
+	require.NoError(t, updater.Update(ctx, "objects/deleted", now, now))
+	require.NoError(t, updater.Update(ctx, "objects/other", now, now))
+
+	s, err := NewService(testConfig, mCfg, bucket, nil, log.NewNopLogger(), prometheus.NewRegistry())
+	require.NoError(t, err)
+	require.NoError(t, s.ApplyDeletes(context.Background(), tenantID, []DeleteRequest{
+		testDeleteRequest{app: "api", start: now, end: now},
+	}))
+
+	ms := metastore.NewObjectMetastore(bucket, log.NewNopLogger(), nil)
+	objects, err := ms.DataObjects(ctx, now.Add(-time.Hour), now.Add(time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, []string{"objects/other"}, objects)
+}
@@ -0,0 +1,10 @@
+package consumer
+
+import (
+	"github.com/grafana/loki/v3/pkg/dataobj/consumer/logsobj"
+)
+
+// Limits is an interface for the per-tenant limits of the dataobj consumer.
+type Limits interface {
+	DataObjCompression(userID string) logsobj.CompressionOverrides
+}
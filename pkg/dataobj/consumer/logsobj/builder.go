@@ -61,6 +61,15 @@ type BuilderConfig struct {
 	// values of MergeSize trade off lower memory overhead for higher time spent
 	// merging.
 	SectionStripeMergeLimit int `yaml:"section_stripe_merge_limit"`
+
+	// Compression configures the compression of each column type of logs
+	// sections.
+	Compression CompressionConfig `yaml:"compression"`
+
+	// DictionaryEncoding enables dictionary encoding of structured metadata
+	// columns. It must only be enabled once all readers of data objects
+	// support dictionary encoding.
+	DictionaryEncoding bool `yaml:"dictionary_encoding"`
 }
 
 // RegisterFlagsWithPrefix registers flags with the given prefix.
@@ -75,6 +84,8 @@ func (cfg *BuilderConfig) RegisterFlagsWithPrefix(prefix string, f *flag.FlagSet
 	f.Var(&cfg.TargetSectionSize, prefix+"target-section-size", "Configures a maximum size for sections, for sections that support it.")
 	f.Var(&cfg.BufferSize, prefix+"buffer-size", "The size of the buffer to use for sorting logs.")
 	f.IntVar(&cfg.SectionStripeMergeLimit, prefix+"section-stripe-merge-limit", 2, "The maximum number of stripes to merge into a section at once. Must be greater than 1.")
+	cfg.Compression.RegisterFlagsWithPrefix(prefix+"compression.", f)
+	f.BoolVar(&cfg.DictionaryEncoding, prefix+"dictionary-encoding", false, "Experimental: Enables dictionary encoding of structured metadata columns. Only enable this once all components reading data objects support dictionary encoding.")
 }
 
 // Validate validates the BuilderConfig.
@@ -103,6 +114,10 @@ func (cfg *BuilderConfig) Validate() error {
 		errs = append(errs, errors.New("LogsMergeStripesMax must be greater than 1"))
 	}
 
+	if err := cfg.Compression.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("invalid compression: %w", err))
+	}
+
 	return errors.Join(errs...)
 }
 
@@ -162,9 +177,11 @@ func NewBuilder(cfg BuilderConfig) (*Builder, error) {
 		builder: dataobj.NewBuilder(),
 		streams: streams.NewBuilder(metrics.streams, int(cfg.TargetPageSize)),
 		logs: logs.NewBuilder(metrics.logs, logs.BuilderOptions{
-			PageSizeHint:     int(cfg.TargetPageSize),
-			BufferSize:       int(cfg.BufferSize),
-			StripeMergeLimit: cfg.SectionStripeMergeLimit,
+			PageSizeHint:       int(cfg.TargetPageSize),
+			BufferSize:         int(cfg.BufferSize),
+			StripeMergeLimit:   cfg.SectionStripeMergeLimit,
+			Compression:        cfg.Compression.options(),
+			DictionaryEncoding: cfg.DictionaryEncoding,
 		}),
 	}, nil
 }
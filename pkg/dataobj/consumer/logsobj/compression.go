@@ -0,0 +1,102 @@
+package logsobj
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/grafana/loki/v3/pkg/dataobj/sections/logs"
+)
+
+// CompressionConfig configures the compression of each column type of logs
+// sections.
+type CompressionConfig struct {
+	StreamID  ColumnCompressionConfig `yaml:"stream_id"`
+	Timestamp ColumnCompressionConfig `yaml:"timestamp"`
+	Metadata  ColumnCompressionConfig `yaml:"metadata"`
+	Message   ColumnCompressionConfig `yaml:"message"`
+}
+
+// RegisterFlagsWithPrefix registers flags with the given prefix.
+func (cfg *CompressionConfig) RegisterFlagsWithPrefix(prefix string, f *flag.FlagSet) {
+	defaults := logs.DefaultCompressionOptions()
+
+	cfg.StreamID.registerFlagsWithPrefix(prefix+"stream-id.", "stream IDs", defaults.StreamID, f)
+	cfg.Timestamp.registerFlagsWithPrefix(prefix+"timestamp.", "timestamps", defaults.Timestamp, f)
+	cfg.Metadata.registerFlagsWithPrefix(prefix+"metadata.", "structured metadata", defaults.Metadata, f)
+	cfg.Message.registerFlagsWithPrefix(prefix+"message.", "log lines", defaults.Message, f)
+}
+
+// Validate validates the CompressionConfig.
+func (cfg *CompressionConfig) Validate() error {
+	return cfg.options().Validate()
+}
+
+func (cfg *CompressionConfig) options() logs.CompressionOptions {
+	return logs.CompressionOptions{
+		StreamID:  cfg.StreamID.options(),
+		Timestamp: cfg.Timestamp.options(),
+		Metadata:  cfg.Metadata.options(),
+		Message:   cfg.Message.options(),
+	}
+}
+
+// ColumnCompressionConfig configures the compression of a column type.
+type ColumnCompressionConfig struct {
+	Codec string `yaml:"codec"`
+	Level int    `yaml:"level"`
+}
+
+func (cfg *ColumnCompressionConfig) registerFlagsWithPrefix(prefix, name string, defaults logs.ColumnCompression, f *flag.FlagSet) {
+	f.StringVar(&cfg.Codec, prefix+"codec", string(defaults.Codec), fmt.Sprintf("The compression codec to use for %s. Supported values: none, snappy, lz4, zstd.", name))
+	f.IntVar(&cfg.Level, prefix+"level", defaults.Level, fmt.Sprintf("The compression level to use for %s. Only supported by zstd (1-22) and lz4 (1-9). 0 uses the default level of the codec.", name))
+}
+
+func (cfg *ColumnCompressionConfig) options() logs.ColumnCompression {
+	return logs.ColumnCompression{Codec: logs.Codec(cfg.Codec), Level: cfg.Level}
+}
+
+// Override returns cfg with the compression of each column set in overrides
+// replacing the configured one.
+func (cfg CompressionConfig) Override(overrides CompressionOverrides) CompressionConfig {
+	cfg.StreamID = cfg.StreamID.override(overrides.StreamID)
+	cfg.Timestamp = cfg.Timestamp.override(overrides.Timestamp)
+	cfg.Metadata = cfg.Metadata.override(overrides.Metadata)
+	cfg.Message = cfg.Message.override(overrides.Message)
+	return cfg
+}
+
+func (cfg ColumnCompressionConfig) override(o ColumnCompressionOverride) ColumnCompressionConfig {
+	if o.Codec == "" {
+		return cfg
+	}
+	return ColumnCompressionConfig(o)
+}
+
+// CompressionOverrides overrides the compression of each column type of logs
+// sections for a single tenant. Columns without a codec keep the configured
+// compression.
+type CompressionOverrides struct {
+	StreamID  ColumnCompressionOverride `yaml:"stream_id" json:"stream_id"`
+	Timestamp ColumnCompressionOverride `yaml:"timestamp" json:"timestamp"`
+	Metadata  ColumnCompressionOverride `yaml:"metadata" json:"metadata"`
+	Message   ColumnCompressionOverride `yaml:"message" json:"message"`
+}
+
+// Validate validates the CompressionOverrides.
+func (o *CompressionOverrides) Validate() error {
+	defaults := logs.DefaultCompressionOptions()
+	cfg := CompressionConfig{
+		StreamID:  ColumnCompressionConfig{Codec: string(defaults.StreamID.Codec), Level: defaults.StreamID.Level},
+		Timestamp: ColumnCompressionConfig{Codec: string(defaults.Timestamp.Codec), Level: defaults.Timestamp.Level},
+		Metadata:  ColumnCompressionConfig{Codec: string(defaults.Metadata.Codec), Level: defaults.Metadata.Level},
+		Message:   ColumnCompressionConfig{Codec: string(defaults.Message.Codec), Level: defaults.Message.Level},
+	}
+	cfg = cfg.Override(*o)
+	return cfg.Validate()
+}
+
+// ColumnCompressionOverride overrides the compression of a column type.
+type ColumnCompressionOverride struct {
+	Codec string `yaml:"codec" json:"codec" doc:"description=The compression codec to use for the column. Supported values: none, snappy, lz4, zstd. If empty, the compression configured for the dataobj consumer is used."`
+	Level int    `yaml:"level" json:"level" doc:"description=The compression level to use for the column. Only supported by zstd (1-22) and lz4 (1-9). 0 uses the default level of the codec."`
+}
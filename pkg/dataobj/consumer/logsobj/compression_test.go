@@ -0,0 +1,35 @@
+package logsobj
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompressionConfig_Override(t *testing.T) {
+	cfg := CompressionConfig{
+		StreamID:  ColumnCompressionConfig{Codec: "none"},
+		Timestamp: ColumnCompressionConfig{Codec: "none"},
+		Metadata:  ColumnCompressionConfig{Codec: "zstd", Level: 1},
+		Message:   ColumnCompressionConfig{Codec: "zstd", Level: 1},
+	}
+
+	actual := cfg.Override(CompressionOverrides{
+		Message: ColumnCompressionOverride{Codec: "lz4", Level: 4},
+		// Levels without a codec are ignored.
+		Metadata: ColumnCompressionOverride{Level: 9},
+	})
+
+	require.Equal(t, CompressionConfig{
+		StreamID:  ColumnCompressionConfig{Codec: "none"},
+		Timestamp: ColumnCompressionConfig{Codec: "none"},
+		Metadata:  ColumnCompressionConfig{Codec: "zstd", Level: 1},
+		Message:   ColumnCompressionConfig{Codec: "lz4", Level: 4},
+	}, actual)
+}
+
+func TestCompressionOverrides_Validate(t *testing.T) {
+	require.NoError(t, (&CompressionOverrides{}).Validate())
+	require.NoError(t, (&CompressionOverrides{Message: ColumnCompressionOverride{Codec: "zstd", Level: 3}}).Validate())
+	require.Error(t, (&CompressionOverrides{Message: ColumnCompressionOverride{Codec: "brotli"}}).Validate())
+}
@@ -41,6 +41,7 @@ type Service struct {
 	mCfg   metastore.Config
 	bucket objstore.Bucket
 	codec  distributor.TenantPrefixCodec
+	limits Limits
 
 	// Partition management
 	partitionMtx      sync.RWMutex
@@ -49,13 +50,14 @@ type Service struct {
 	bufPool *sync.Pool
 }
 
-func New(kafkaCfg kafka.Config, cfg Config, mCfg metastore.Config, topicPrefix string, bucket objstore.Bucket, instanceID string, partitionRing ring.PartitionRingReader, reg prometheus.Registerer, logger log.Logger) *Service {
+func New(kafkaCfg kafka.Config, cfg Config, mCfg metastore.Config, topicPrefix string, bucket objstore.Bucket, limits Limits, instanceID string, partitionRing ring.PartitionRingReader, reg prometheus.Registerer, logger log.Logger) *Service {
 	s := &Service{
 		logger:            log.With(logger, "component", groupName),
 		cfg:               cfg,
 		mCfg:              mCfg,
 		bucket:            bucket,
 		codec:             distributor.TenantPrefixCodec(topicPrefix),
+		limits:            limits,
 		partitionHandlers: make(map[string]map[int32]*partitionProcessor),
 		reg:               reg,
 		bufPool: &sync.Pool{
@@ -116,8 +118,11 @@ func (s *Service) handlePartitionsAssigned(ctx context.Context, client *kgo.Clie
 			s.partitionHandlers[topic] = make(map[int32]*partitionProcessor)
 		}
 
+		builderCfg := s.cfg.BuilderConfig
+		builderCfg.Compression = builderCfg.Compression.Override(s.limits.DataObjCompression(tenant))
+
 		for _, partition := range parts {
-			processor := newPartitionProcessor(ctx, client, s.cfg.BuilderConfig, s.cfg.UploaderConfig, s.mCfg, s.bucket, tenant, virtualShard, topic, partition, s.logger, s.reg, s.bufPool, s.cfg.IdleFlushTimeout, s.eventsProducerClient)
+			processor := newPartitionProcessor(ctx, client, builderCfg, s.cfg.UploaderConfig, s.mCfg, s.bucket, tenant, virtualShard, topic, partition, s.logger, s.reg, s.bufPool, s.cfg.IdleFlushTimeout, s.eventsProducerClient)
 			s.partitionHandlers[topic][partition] = processor
 			processor.start()
 		}
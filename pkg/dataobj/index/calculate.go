@@ -124,15 +124,16 @@ func (c *Calculator) processLogsSection(ctx context.Context, sectionLogger log.L
 	}
 
 	columnBloomBuilders := make(map[string]*bloom.BloomFilter)
-	columnIndexes := make(map[string]int64)
 	for _, column := range stats.Columns {
 		if !logs.IsMetadataColumn(column.Type) {
 			continue
 		}
 		columnBloomBuilders[column.Name] = bloom.NewWithEstimates(uint(column.Cardinality), 1.0/128.0)
-		columnIndexes[column.Name] = column.ColumnIndex
 	}
 
+	// tokenIndexer is nil if the token index is disabled.
+	tokenIndexer := c.indexobjBuilder.NewTokenIndexer()
+
 	// Read the whole logs section to extract all the column values.
 	cnt := 0
 	// TODO(benclive): Switch to a columnar reader instead of row based
@@ -149,6 +150,11 @@ func (c *Calculator) processLogsSection(ctx context.Context, sectionLogger log.L
 		}
 
 		for i, log := range logsBuf[:n] {
+			// Rows are read in order without predicates, so cnt is the row of
+			// the log line in the section.
+			if tokenIndexer != nil {
+				tokenIndexer.Add(int64(cnt), log.Line)
+			}
 			cnt++
 			log.Metadata.Range(func(md labels.Label) {
 				columnBloomBuilders[md.Name].Add([]byte(md.Value))
@@ -179,13 +185,22 @@ func (c *Calculator) processLogsSection(ctx context.Context, sectionLogger log.L
 			return fmt.Errorf("failed to marshal bloom filter: %w", err)
 		}
 		c.builderMtx.Lock()
-		err = c.indexobjBuilder.AppendColumnIndex(objectPath, sectionIdx, columnName, columnIndexes[columnName], bloomBytes)
+		err = c.indexobjBuilder.AppendColumnIndex(objectPath, sectionIdx, columnName, bloomBytes)
 		c.builderMtx.Unlock()
 		if err != nil {
 			return fmt.Errorf("failed to append column index: %w", err)
 		}
 	}
 
+	if tokenIndexer != nil {
+		c.builderMtx.Lock()
+		err = c.indexobjBuilder.AppendTokenIndex(objectPath, sectionIdx, tokenIndexer)
+		c.builderMtx.Unlock()
+		if err != nil {
+			return fmt.Errorf("failed to append token index: %w", err)
+		}
+	}
+
 	level.Info(sectionLogger).Log("msg", "finished processing logs section", "rowsProcessed", cnt)
 	return nil
 }
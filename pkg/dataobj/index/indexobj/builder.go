@@ -15,9 +15,11 @@ import (
 	"github.com/prometheus/prometheus/model/labels"
 
 	"github.com/grafana/loki/v3/pkg/dataobj"
+	"github.com/grafana/loki/v3/pkg/dataobj/sections/blooms"
 	"github.com/grafana/loki/v3/pkg/dataobj/sections/indexpointers"
 	"github.com/grafana/loki/v3/pkg/dataobj/sections/pointers"
 	"github.com/grafana/loki/v3/pkg/dataobj/sections/streams"
+	"github.com/grafana/loki/v3/pkg/dataobj/sections/tokens"
 )
 
 // ErrBuilderFull is returned by [Builder.Append] when the buffer is
@@ -58,6 +60,33 @@ type BuilderConfig struct {
 	// values of MergeSize trade off lower memory overhead for higher time spent
 	// merging.
 	SectionStripeMergeLimit int `yaml:"section_stripe_merge_limit"`
+
+	// TokenIndex configures the token index of log lines, which allows
+	// queries with line filters to skip rows of logs sections.
+	TokenIndex TokenIndexConfig `yaml:"token_index"`
+
+	// RunLengthEncoding enables run-length encoding of the column names of
+	// blooms sections. It must only be enabled once all readers of index
+	// objects support run-length encoding.
+	RunLengthEncoding bool `yaml:"run_length_encoding"`
+}
+
+// TokenIndexConfig configures the token index of log lines.
+type TokenIndexConfig struct {
+	// Enabled enables building a token index of the log lines of indexed
+	// logs sections.
+	Enabled bool `yaml:"enabled"`
+
+	// RowsPerBlock configures the number of rows of a logs section which are
+	// grouped together in the token index. Larger values reduce the size of
+	// the index at the cost of reading more rows for each match.
+	RowsPerBlock int `yaml:"rows_per_block"`
+}
+
+// RegisterFlagsWithPrefix registers flags with the given prefix.
+func (cfg *TokenIndexConfig) RegisterFlagsWithPrefix(prefix string, f *flag.FlagSet) {
+	f.BoolVar(&cfg.Enabled, prefix+"enabled", false, "Experimental: Enables building a token index of log lines, which allows queries with line filters to skip rows of logs sections.")
+	f.IntVar(&cfg.RowsPerBlock, prefix+"rows-per-block", 512, "Experimental: The number of rows of a logs section grouped together in the token index. Larger values reduce the size of the index at the cost of reading more rows for each match.")
 }
 
 // RegisterFlagsWithPrefix registers flags with the given prefix.
@@ -72,6 +101,8 @@ func (cfg *BuilderConfig) RegisterFlagsWithPrefix(prefix string, f *flag.FlagSet
 	f.Var(&cfg.TargetSectionSize, prefix+"target-section-size", "Configures a maximum size for sections, for sections that support it.")
 	f.Var(&cfg.BufferSize, prefix+"buffer-size", "The size of the buffer to use for sorting logs.")
 	f.IntVar(&cfg.SectionStripeMergeLimit, prefix+"section-stripe-merge-limit", 2, "The maximum number of stripes to merge into a section at once. Must be greater than 1.")
+	cfg.TokenIndex.RegisterFlagsWithPrefix(prefix+"token-index.", f)
+	f.BoolVar(&cfg.RunLengthEncoding, prefix+"run-length-encoding", false, "Experimental: Enables run-length encoding of the column names of blooms sections. Only enable this once all components reading index objects support run-length encoding.")
 }
 
 // Validate validates the BuilderConfig.
@@ -100,6 +131,10 @@ func (cfg *BuilderConfig) Validate() error {
 		errs = append(errs, errors.New("LogsMergeStripesMax must be greater than 1"))
 	}
 
+	if cfg.TokenIndex.Enabled && cfg.TokenIndex.RowsPerBlock <= 0 {
+		errs = append(errs, errors.New("TokenIndex.RowsPerBlock must be greater than 0"))
+	}
+
 	return errors.Join(errs...)
 }
 
@@ -120,6 +155,8 @@ type Builder struct {
 	builder       *dataobj.Builder // Inner builder for accumulating sections.
 	streams       *streams.Builder
 	pointers      *pointers.Builder
+	blooms        *blooms.Builder
+	tokens        *tokens.Builder
 	indexPointers *indexpointers.Builder
 
 	state builderState
@@ -160,6 +197,8 @@ func NewBuilder(cfg BuilderConfig) (*Builder, error) {
 		builder:       dataobj.NewBuilder(),
 		streams:       streams.NewBuilder(metrics.streams, int(cfg.TargetPageSize)),
 		pointers:      pointers.NewBuilder(metrics.pointers, int(cfg.TargetPageSize)),
+		blooms:        blooms.NewBuilder(metrics.blooms, int(cfg.TargetPageSize), cfg.RunLengthEncoding),
+		tokens:        tokens.NewBuilder(metrics.tokens, int(cfg.TargetPageSize)),
 		indexPointers: indexpointers.NewBuilder(metrics.indexPointers, int(cfg.TargetPageSize)),
 	}, nil
 }
@@ -286,21 +325,56 @@ func (b *Builder) ObserveLogLine(path string, section int64, streamIDInObject in
 	return nil
 }
 
-// Append buffers a stream to be written to a data object. Append returns an
-// error if the stream labels cannot be parsed or [ErrBuilderFull] if the
-// builder is full.
+// AppendColumnIndex buffers a bloom filter of the values of the column named
+// columnName in the section of the data object at path. The bloom filter is
+// written to the blooms section of the index object. AppendColumnIndex
+// returns [ErrBuilderFull] if the builder is full.
 //
 // Once a Builder is full, call [Builder.Flush] to flush the buffered data,
-// then call Append again with the same entry.
-func (b *Builder) AppendColumnIndex(path string, section int64, columnName string, columnIndex int64, valuesBloom []byte) error {
-	// Check whether the buffer is full before a stream can be appended; this is
-	// tends to overestimate, but we may still go over our target size.
-	//
-	// Since this check only happens after the first call to Append,
-	// b.currentSizeEstimate will always be updated to reflect the size following
-	// the previous append.
+// then call AppendColumnIndex again with the same entry.
+func (b *Builder) AppendColumnIndex(path string, section int64, columnName string, valuesBloom []byte) error {
+	newEntrySize := len(path) + 1 + len(columnName) + len(valuesBloom)
+
+	if b.state != builderStateEmpty && b.currentSizeEstimate+newEntrySize > int(b.cfg.TargetObjectSize) {
+		return ErrBuilderFull
+	}
+
+	timer := prometheus.NewTimer(b.metrics.appendTime)
+	defer timer.ObserveDuration()
 
-	newEntrySize := len(columnName) + 1 + 1 + len(valuesBloom) + 1
+	b.blooms.Append(path, section, columnName, valuesBloom)
+
+	// If our blooms section has gotten big enough, we want to flush it to the
+	// encoder and start a new section.
+	if b.blooms.EstimatedSize() > int(b.cfg.TargetSectionSize) {
+		if err := b.builder.Append(b.blooms); err != nil {
+			return err
+		}
+	}
+
+	b.currentSizeEstimate = b.estimatedSize()
+	b.state = builderStateDirty
+	return nil
+}
+
+// NewTokenIndexer returns a new [tokens.Indexer] for indexing the log lines of
+// a logs section, or nil if the token index is disabled.
+func (b *Builder) NewTokenIndexer() *tokens.Indexer {
+	if !b.cfg.TokenIndex.Enabled {
+		return nil
+	}
+	return tokens.NewIndexer(b.cfg.TokenIndex.RowsPerBlock)
+}
+
+// AppendTokenIndex buffers the tokens of the log lines of the section of the
+// data object at path, accumulated by ix. The tokens are written to the
+// tokens section of the index object. AppendTokenIndex returns
+// [ErrBuilderFull] if the builder is full.
+//
+// Once a Builder is full, call [Builder.Flush] to flush the buffered data,
+// then call AppendTokenIndex again with the same entry.
+func (b *Builder) AppendTokenIndex(path string, section int64, ix *tokens.Indexer) error {
+	newEntrySize := ix.EstimatedSize() + ix.Len()*(len(path)+1)
 
 	if b.state != builderStateEmpty && b.currentSizeEstimate+newEntrySize > int(b.cfg.TargetObjectSize) {
 		return ErrBuilderFull
@@ -309,12 +383,12 @@ func (b *Builder) AppendColumnIndex(path string, section int64, columnName strin
 	timer := prometheus.NewTimer(b.metrics.appendTime)
 	defer timer.ObserveDuration()
 
-	b.pointers.RecordColumnIndex(path, section, columnName, columnIndex, valuesBloom)
+	b.tokens.AppendIndexer(path, section, ix)
 
-	// If our logs section has gotten big enough, we want to flush it to the
+	// If our tokens section has gotten big enough, we want to flush it to the
 	// encoder and start a new section.
-	if b.pointers.EstimatedSize() > int(b.cfg.TargetSectionSize) {
-		if err := b.builder.Append(b.pointers); err != nil {
+	if b.tokens.EstimatedSize() > int(b.cfg.TargetSectionSize) {
+		if err := b.builder.Append(b.tokens); err != nil {
 			return err
 		}
 	}
@@ -328,6 +402,8 @@ func (b *Builder) estimatedSize() int {
 	var size int
 	size += b.streams.EstimatedSize()
 	size += b.pointers.EstimatedSize()
+	size += b.blooms.EstimatedSize()
+	size += b.tokens.EstimatedSize()
 	size += b.indexPointers.EstimatedSize()
 	size += b.builder.Bytes()
 	b.metrics.sizeEstimate.Set(float64(size))
@@ -361,6 +437,8 @@ func (b *Builder) Flush(output *bytes.Buffer) (FlushStats, error) {
 
 	flushErrors = append(flushErrors, b.builder.Append(b.streams))
 	flushErrors = append(flushErrors, b.builder.Append(b.pointers))
+	flushErrors = append(flushErrors, b.builder.Append(b.blooms))
+	flushErrors = append(flushErrors, b.builder.Append(b.tokens))
 	flushErrors = append(flushErrors, b.builder.Append(b.indexPointers))
 
 	if err := errors.Join(flushErrors...); err != nil {
@@ -416,6 +494,20 @@ func (b *Builder) observeObject(ctx context.Context, obj *dataobj.Object) error
 				continue
 			}
 			errs = append(errs, b.metrics.pointers.Observe(ctx, pointerSection))
+		case blooms.CheckSection(sec):
+			bloomsSection, err := blooms.Open(ctx, sec)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			errs = append(errs, b.metrics.blooms.Observe(ctx, bloomsSection))
+		case tokens.CheckSection(sec):
+			tokensSection, err := tokens.Open(ctx, sec)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			errs = append(errs, b.metrics.tokens.Observe(ctx, tokensSection))
 		case streams.CheckSection(sec):
 			streamSection, err := streams.Open(context.Background(), sec)
 			if err != nil {
@@ -434,6 +526,8 @@ func (b *Builder) Reset() {
 	b.builder.Reset()
 	b.streams.Reset()
 	b.pointers.Reset()
+	b.blooms.Reset()
+	b.tokens.Reset()
 	b.indexPointers.Reset()
 
 	//b.metrics.sizeEstimate.Set(0)
@@ -6,14 +6,18 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 
 	"github.com/grafana/loki/v3/pkg/dataobj"
+	"github.com/grafana/loki/v3/pkg/dataobj/sections/blooms"
 	"github.com/grafana/loki/v3/pkg/dataobj/sections/indexpointers"
 	"github.com/grafana/loki/v3/pkg/dataobj/sections/pointers"
 	"github.com/grafana/loki/v3/pkg/dataobj/sections/streams"
+	"github.com/grafana/loki/v3/pkg/dataobj/sections/tokens"
 )
 
 // builderMetrics provides instrumnetation for a [Builder].
 type builderMetrics struct {
 	pointers      *pointers.Metrics
+	blooms        *blooms.Metrics
+	tokens        *tokens.Metrics
 	indexPointers *indexpointers.Metrics
 	streams       *streams.Metrics
 	dataobj       *dataobj.Metrics
@@ -39,6 +43,8 @@ func newBuilderMetrics() *builderMetrics {
 	return &builderMetrics{
 		indexPointers: indexpointers.NewMetrics(),
 		pointers:      pointers.NewMetrics(),
+		blooms:        blooms.NewMetrics(),
+		tokens:        tokens.NewMetrics(),
 		streams:       streams.NewMetrics(),
 		dataobj:       dataobj.NewMetrics(),
 		targetPageSize: prometheus.NewGauge(prometheus.GaugeOpts{
@@ -127,6 +133,8 @@ func (m *builderMetrics) Register(reg prometheus.Registerer) error {
 
 	errs = append(errs, m.indexPointers.Register(reg))
 	errs = append(errs, m.pointers.Register(reg))
+	errs = append(errs, m.blooms.Register(reg))
+	errs = append(errs, m.tokens.Register(reg))
 	errs = append(errs, m.streams.Register(reg))
 	errs = append(errs, m.dataobj.Register(reg))
 
@@ -151,6 +159,8 @@ func (m *builderMetrics) Register(reg prometheus.Registerer) error {
 func (m *builderMetrics) Unregister(reg prometheus.Registerer) {
 	m.indexPointers.Unregister(reg)
 	m.pointers.Unregister(reg)
+	m.blooms.Unregister(reg)
+	m.tokens.Unregister(reg)
 	m.streams.Unregister(reg)
 	m.dataobj.Unregister(reg)
 
@@ -12,9 +12,11 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/loki/v3/pkg/dataobj"
+	"github.com/grafana/loki/v3/pkg/dataobj/sections/blooms"
 	"github.com/grafana/loki/v3/pkg/dataobj/sections/logs"
 	"github.com/grafana/loki/v3/pkg/dataobj/sections/pointers"
 	"github.com/grafana/loki/v3/pkg/dataobj/sections/streams"
+	"github.com/grafana/loki/v3/pkg/dataobj/sections/tokens"
 )
 
 var testBuilderConfig = BuilderConfig{
@@ -56,13 +58,12 @@ func TestBuilder(t *testing.T) {
 		},
 	}
 
-	testPointers := []pointers.SectionPointer{
+	testBlooms := []blooms.Bloom{
 		{
-			Path:              "test/path",
-			Section:           1,
-			ColumnName:        "foo",
-			ColumnIndex:       1,
-			ValuesBloomFilter: []byte{1, 2, 3},
+			Path:       "test/path",
+			Section:    1,
+			ColumnName: "foo",
+			Filter:     []byte{1, 2, 3},
 		},
 	}
 
@@ -74,8 +75,8 @@ func TestBuilder(t *testing.T) {
 			_, err := builder.AppendStream(stream)
 			require.NoError(t, err)
 		}
-		for _, pointer := range testPointers {
-			err := builder.AppendColumnIndex(pointer.Path, pointer.Section, pointer.ColumnName, pointer.ColumnIndex, pointer.ValuesBloomFilter)
+		for _, bloom := range testBlooms {
+			err := builder.AppendColumnIndex(bloom.Path, bloom.Section, bloom.ColumnName, bloom.Filter)
 			require.NoError(t, err)
 		}
 		_, err = builder.Flush(buf)
@@ -86,7 +87,8 @@ func TestBuilder(t *testing.T) {
 		obj, err := dataobj.FromReaderAt(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
 		require.NoError(t, err)
 		require.Equal(t, 1, obj.Sections().Count(streams.CheckSection))
-		require.Equal(t, 1, obj.Sections().Count(pointers.CheckSection))
+		require.Equal(t, 0, obj.Sections().Count(pointers.CheckSection))
+		require.Equal(t, 1, obj.Sections().Count(blooms.CheckSection))
 		require.Equal(t, 0, obj.Sections().Count(logs.CheckSection))
 	})
 
@@ -98,8 +100,8 @@ func TestBuilder(t *testing.T) {
 			_, err := builder.AppendStream(stream)
 			require.NoError(t, err)
 		}
-		for _, pointer := range testPointers {
-			err := builder.AppendColumnIndex(pointer.Path, pointer.Section, pointer.ColumnName, pointer.ColumnIndex, pointer.ValuesBloomFilter)
+		for _, bloom := range testBlooms {
+			err := builder.AppendColumnIndex(bloom.Path, bloom.Section, bloom.ColumnName, bloom.Filter)
 			require.NoError(t, err)
 		}
 
@@ -113,7 +115,8 @@ func TestBuilder(t *testing.T) {
 		obj, err := dataobj.FromReaderAt(bytes.NewReader(dirtyBuf.Bytes()[5:]), int64(dirtyBuf.Len()-5))
 		require.NoError(t, err)
 		require.Equal(t, 1, obj.Sections().Count(streams.CheckSection))
-		require.Equal(t, 1, obj.Sections().Count(pointers.CheckSection))
+		require.Equal(t, 0, obj.Sections().Count(pointers.CheckSection))
+		require.Equal(t, 1, obj.Sections().Count(blooms.CheckSection))
 		require.Equal(t, 0, obj.Sections().Count(logs.CheckSection))
 	})
 }
@@ -169,3 +172,35 @@ func TestBuilder_AppendIndexPointer(t *testing.T) {
 		i++
 	}
 }
+
+func TestBuilder_AppendTokenIndex(t *testing.T) {
+	cfg := testBuilderConfig
+	cfg.TokenIndex = TokenIndexConfig{Enabled: true, RowsPerBlock: 1}
+
+	builder, err := NewBuilder(cfg)
+	require.NoError(t, err)
+
+	ix := builder.NewTokenIndexer()
+	require.NotNil(t, ix)
+	for i, line := range []string{"GET /api/v1/push", "POST /api/v1/query", "GET /ready"} {
+		ix.Add(int64(i), []byte(line))
+	}
+	require.NoError(t, builder.AppendTokenIndex("test/path", 0, ix))
+
+	var buf bytes.Buffer
+	_, err = builder.Flush(&buf)
+	require.NoError(t, err)
+
+	obj, err := dataobj.FromReaderAt(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	require.Equal(t, 1, obj.Sections().Count(tokens.CheckSection))
+
+	rows, ok, err := tokens.MatchSubstrings(context.Background(), obj, "test/path", 0, []string{"GET"})
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, []logs.RowRange{{Start: 0, End: 0}, {Start: 2, End: 2}}, rows)
+
+	disabled, err := NewBuilder(testBuilderConfig)
+	require.NoError(t, err)
+	require.Nil(t, disabled.NewTokenIndexer())
+}
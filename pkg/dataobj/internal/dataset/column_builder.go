@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4/v4"
 
 	"github.com/grafana/loki/v3/pkg/dataobj/internal/metadata/datasetmd"
 )
@@ -47,6 +48,10 @@ type CompressionOptions struct {
 	// Zstd holds encoding options for Zstd compression. Only used for
 	// [datasetmd.COMPRESSION_TYPE_ZSTD].
 	Zstd []zstd.EOption
+
+	// LZ4 holds encoding options for LZ4 compression. Only used for
+	// [datasetmd.COMPRESSION_TYPE_LZ4].
+	LZ4 []lz4.Option
 }
 
 // A ColumnBuilder builds a sequence of [Value] entries of a common type into a
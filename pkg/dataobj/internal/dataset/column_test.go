@@ -148,6 +148,62 @@ func TestColumnBuilder_MinMax(t *testing.T) {
 	require.Equal(t, fString, string(page1Max.ByteArray()))
 }
 
+func TestColumnBuilder_Dictionary(t *testing.T) {
+	in := []string{"info", "", "debug", "info", "error", "", "info", "debug"}
+
+	opts := BuilderOptions{
+		PageSizeHint: 1024,
+		Value:        datasetmd.VALUE_TYPE_BYTE_ARRAY,
+		Compression:  datasetmd.COMPRESSION_TYPE_ZSTD,
+		Encoding:     datasetmd.ENCODING_TYPE_DICTIONARY,
+	}
+	b, err := NewColumnBuilder("", opts)
+	require.NoError(t, err)
+
+	for i, s := range in {
+		require.NoError(t, b.Append(i, ByteArrayValue([]byte(s))))
+	}
+
+	col, err := b.Flush()
+	require.NoError(t, err)
+	require.Len(t, col.Pages, 1)
+	require.Equal(t, len(in)-2, col.Pages[0].Info.ValuesCount) // -2 for the empty strings
+
+	// The page dictionary is stored sorted in the page statistics, even
+	// without range statistics.
+	require.NotNil(t, col.Pages[0].Info.Stats)
+	var dictionary []string
+	for _, entry := range col.Pages[0].Info.Stats.Dictionary {
+		var value Value
+		require.NoError(t, value.UnmarshalBinary(entry))
+		dictionary = append(dictionary, string(value.ByteArray()))
+	}
+	require.Equal(t, []string{"debug", "error", "info"}, dictionary)
+
+	var actual []string
+
+	r := newColumnReader(col)
+	for {
+		var values [1]Value
+		n, err := r.Read(context.Background(), values[:])
+		if err != nil && !errors.Is(err, io.EOF) {
+			require.NoError(t, err)
+		} else if n == 0 && errors.Is(err, io.EOF) {
+			break
+		} else if n == 0 {
+			continue
+		}
+
+		if values[0].IsNil() {
+			actual = append(actual, "")
+		} else {
+			actual = append(actual, string(values[0].ByteArray()))
+		}
+	}
+
+	require.Equal(t, in, actual)
+}
+
 func TestColumnBuilder_Cardinality(t *testing.T) {
 	var (
 		// We include the null string in the test to ensure that it's never
@@ -12,6 +12,7 @@ import (
 
 	"github.com/golang/snappy"
 	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4/v4"
 
 	"github.com/grafana/loki/v3/pkg/dataobj/internal/metadata/datasetmd"
 	"github.com/grafana/loki/v3/pkg/dataobj/internal/util/bufpool"
@@ -146,6 +147,15 @@ func (p *MemPage) reader(compression datasetmd.CompressionType) (presence io.Rea
 			return nil
 		}}, nil
 
+	case datasetmd.COMPRESSION_TYPE_LZ4:
+		lr := lz4Pool.Get().(*lz4.Reader)
+		lr.Reset(compressedValuesReader)
+		return bitmapReader, &closerFunc{Reader: lr, onClose: func() error {
+			lr.Reset(nil) // Allow releasing the buffer.
+			lz4Pool.Put(lr)
+			return nil
+		}}, nil
+
 	default:
 		// We do *not* want to panic here, as we may be trying to read a page from
 		// a newer format.
@@ -159,6 +169,12 @@ var snappyPool = sync.Pool{
 	},
 }
 
+var lz4Pool = sync.Pool{
+	New: func() any {
+		return lz4.NewReader(nil)
+	},
+}
+
 type closerFunc struct {
 	io.Reader
 	onClose func() error
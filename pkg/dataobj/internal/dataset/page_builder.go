@@ -5,6 +5,7 @@ import (
 	"encoding/binary"
 	"fmt"
 	"hash/crc32"
+	"slices"
 
 	"github.com/grafana/loki/v3/pkg/dataobj/internal/metadata/datasetmd"
 	"github.com/grafana/loki/v3/pkg/dataobj/internal/streamio"
@@ -204,10 +205,17 @@ func valueSize(v Value) int {
 // EstimatedSize returns the estimated uncompressed size of the builder in
 // bytes.
 func (b *pageBuilder) EstimatedSize() int {
-	// This estimate doesn't account for any values in encoders which haven't
+	// This estimate doesn't account for most values in encoders which haven't
 	// been flushed yet. However, encoder buffers are usually small enough that
 	// we wouldn't massively overshoot our estimate.
-	return b.presenceBuffer.Len() + b.valuesWriter.BytesWritten()
+	//
+	// Encoders which buffer an entire page before writing report their
+	// buffered size so pages are still cut at the expected size.
+	size := b.presenceBuffer.Len() + b.valuesWriter.BytesWritten()
+	if enc, ok := b.valuesEnc.(bufferedValueEncoder); ok {
+		size += enc.BufferedSize()
+	}
+	return size
 }
 
 // Rows returns the number of rows appended to the pageBuilder.
@@ -225,6 +233,10 @@ func (b *pageBuilder) Flush() (*MemPage, error) {
 		return nil, fmt.Errorf("no data to flush")
 	}
 
+	// Statistics must be built before flushing the values encoder, which
+	// discards the dictionary of dictionary-encoded pages.
+	stats := b.buildStats()
+
 	// Before we can build the page we need to finish flushing our encoders and
 	// writers.
 	//
@@ -271,7 +283,7 @@ func (b *pageBuilder) Flush() (*MemPage, error) {
 			ValuesCount:      b.values,
 
 			Encoding: b.opts.Encoding,
-			Stats:    b.buildStats(),
+			Stats:    stats,
 		},
 
 		Data: finalData.Bytes(),
@@ -282,14 +294,61 @@ func (b *pageBuilder) Flush() (*MemPage, error) {
 }
 
 func (b *pageBuilder) buildStats() *datasetmd.Statistics {
-	var stats datasetmd.Statistics
+	var (
+		stats    datasetmd.Statistics
+		hasStats bool
+	)
 	if b.opts.Statistics.StoreRangeStats {
 		b.buildRangeStats(&stats)
+		hasStats = true
+	}
+	if enc, ok := b.valuesEnc.(*dictionaryEncoder); ok {
+		hasStats = b.buildDictionaryStats(enc, &stats) || hasStats
+	}
+
+	if hasStats {
 		return &stats
 	}
 	return nil
 }
 
+// maxDictionaryStatsSize is the maximum size of a page dictionary to store in
+// page statistics. Larger dictionaries are omitted to avoid bloating the
+// metadata of columns with high cardinality.
+const maxDictionaryStatsSize = 4096
+
+// buildDictionaryStats stores the sorted dictionary of enc into dst.
+// buildDictionaryStats returns false if the dictionary was too large to be
+// stored.
+func (b *pageBuilder) buildDictionaryStats(enc *dictionaryEncoder, dst *datasetmd.Statistics) bool {
+	var (
+		entries = enc.Dictionary()
+		size    int
+	)
+	for _, entry := range entries {
+		size += len(entry)
+	}
+	if len(entries) == 0 || size > maxDictionaryStatsSize {
+		return false
+	}
+
+	values := make([]Value, 0, len(entries))
+	for _, entry := range entries {
+		values = append(values, ByteArrayValue(entry))
+	}
+	slices.SortFunc(values, func(a, b Value) int { return CompareValues(&a, &b) })
+
+	dst.Dictionary = make([][]byte, 0, len(values))
+	for _, value := range values {
+		valueBytes, err := value.MarshalBinary()
+		if err != nil {
+			panic(fmt.Sprintf("pageBuilder.buildStats: failed to marshal dictionary value: %s", err))
+		}
+		dst.Dictionary = append(dst.Dictionary, valueBytes)
+	}
+	return true
+}
+
 func (b *pageBuilder) buildRangeStats(dst *datasetmd.Statistics) {
 	minValueBytes, err := b.minValue.MarshalBinary()
 	if err != nil {
@@ -139,50 +139,61 @@ func Test_pageBuilder_WriteRead(t *testing.T) {
 		"goodbye",
 	}
 
-	opts := BuilderOptions{
-		PageSizeHint: 1024,
-		Value:        datasetmd.VALUE_TYPE_BYTE_ARRAY,
-		Compression:  datasetmd.COMPRESSION_TYPE_SNAPPY,
-		Encoding:     datasetmd.ENCODING_TYPE_PLAIN,
-	}
-	b, err := newPageBuilder(opts)
-	require.NoError(t, err)
-
-	for _, s := range in {
-		require.True(t, b.Append(ByteArrayValue([]byte(s))))
+	compressions := []datasetmd.CompressionType{
+		datasetmd.COMPRESSION_TYPE_NONE,
+		datasetmd.COMPRESSION_TYPE_SNAPPY,
+		datasetmd.COMPRESSION_TYPE_ZSTD,
+		datasetmd.COMPRESSION_TYPE_LZ4,
 	}
 
-	page, err := b.Flush()
-	require.NoError(t, err)
-	require.Equal(t, len(in), page.Info.RowCount)
-	require.Equal(t, len(in)-2, page.Info.ValuesCount) // -2 for the empty strings
-
-	t.Log("Uncompressed size: ", page.Info.UncompressedSize)
-	t.Log("Compressed size: ", page.Info.CompressedSize)
+	for _, compression := range compressions {
+		t.Run(compression.String(), func(t *testing.T) {
+			opts := BuilderOptions{
+				PageSizeHint: 1024,
+				Value:        datasetmd.VALUE_TYPE_BYTE_ARRAY,
+				Compression:  compression,
+				Encoding:     datasetmd.ENCODING_TYPE_PLAIN,
+			}
+			b, err := newPageBuilder(opts)
+			require.NoError(t, err)
 
-	var actual []string
+			for _, s := range in {
+				require.True(t, b.Append(ByteArrayValue([]byte(s))))
+			}
 
-	r := newPageReader(page, opts.Value, opts.Compression)
-	for {
-		var values [1]Value
-		n, err := r.Read(context.Background(), values[:])
-		if err != nil && !errors.Is(err, io.EOF) {
+			page, err := b.Flush()
 			require.NoError(t, err)
-		} else if n == 0 && errors.Is(err, io.EOF) {
-			break
-		} else if n == 0 {
-			continue
-		}
+			require.Equal(t, len(in), page.Info.RowCount)
+			require.Equal(t, len(in)-2, page.Info.ValuesCount) // -2 for the empty strings
+
+			t.Log("Uncompressed size: ", page.Info.UncompressedSize)
+			t.Log("Compressed size: ", page.Info.CompressedSize)
+
+			var actual []string
+
+			r := newPageReader(page, opts.Value, opts.Compression)
+			for {
+				var values [1]Value
+				n, err := r.Read(context.Background(), values[:])
+				if err != nil && !errors.Is(err, io.EOF) {
+					require.NoError(t, err)
+				} else if n == 0 && errors.Is(err, io.EOF) {
+					break
+				} else if n == 0 {
+					continue
+				}
 
-		val := values[0]
-		if val.IsNil() || val.IsZero() {
-			actual = append(actual, "")
-		} else {
-			require.Equal(t, datasetmd.VALUE_TYPE_BYTE_ARRAY, val.Type())
-			actual = append(actual, string(val.ByteArray()))
-		}
+				val := values[0]
+				if val.IsNil() || val.IsZero() {
+					actual = append(actual, "")
+				} else {
+					require.Equal(t, datasetmd.VALUE_TYPE_BYTE_ARRAY, val.Type())
+					actual = append(actual, string(val.ByteArray()))
+				}
+			}
+			require.Equal(t, in, actual)
+		})
 	}
-	require.Equal(t, in, actual)
 }
 
 func Test_pageBuilder_Fill(t *testing.T) {
@@ -57,8 +57,12 @@ type (
 	// FuncPredicate is a [Predicate] which asserts that a row may only be
 	// included if the Value of the Column passes the Keep function.
 	//
-	// Instances of FuncPredicate are ineligible for page filtering and should
-	// only be used when there isn't a more explicit Predicate implementation.
+	// Instances of FuncPredicate can only be used for page filtering on pages
+	// which store their dictionary in statistics, and should only be used when
+	// there isn't a more explicit Predicate implementation.
+	//
+	// Keep may be invoked for values which are not present in any row, and
+	// must not have side effects.
 	FuncPredicate struct {
 		Column Column // Column to check.
 
@@ -31,6 +31,14 @@ type ReaderOptions struct {
 	// Holds a list of predicates that can be sequentially applied to the dataset.
 	Predicates []Predicate
 
+	// RowRanges optionally restricts the rows returned by a Reader to rows
+	// within the given ranges, in addition to the filtering of Predicates. If
+	// RowRanges is nil, all rows may be returned; if RowRanges is empty but
+	// non-nil, no rows are returned.
+	//
+	// Pages which don't overlap with any of the RowRanges are never read.
+	RowRanges []RowRange
+
 	// TargetCacheSize configures the amount of memory to target for caching
 	// pages in memory. The cache may exceed this size if the combined size of
 	// all pages required for a single call to [Reader.Reead] exceeds this value.
@@ -544,6 +552,14 @@ func (r *Reader) initDownloader(ctx context.Context) error {
 		}
 	}
 
+	if r.opts.RowRanges != nil {
+		var valid rowRanges
+		for _, rr := range r.opts.RowRanges {
+			valid.Add(rowRange(rr))
+		}
+		ranges = intersectRanges(nil, ranges, valid)
+	}
+
 	r.dl.SetDatasetRanges(ranges)
 	r.ranges = ranges
 
@@ -663,9 +679,14 @@ func (r *Reader) buildPredicateRanges(ctx context.Context, p Predicate) (rowRang
 	case LessThanPredicate:
 		return r.buildColumnPredicateRanges(ctx, p.Column, p)
 
-	case FuncPredicate, nil:
-		// These predicates (and nil) don't support any filtering, so it maps to
-		// the full range being valid.
+	case FuncPredicate:
+		// FuncPredicates can only filter pages which store a dictionary in
+		// their statistics.
+		return r.buildColumnPredicateRanges(ctx, p.Column, p)
+
+	case nil:
+		// A nil predicate doesn't support any filtering, so it maps to the full
+		// range being valid.
 		//
 		// We use r.dl.AllColumns instead of r.opts.Columns because the downloader
 		// will cache metadata.
@@ -746,8 +767,12 @@ func simplifyNotPredicate(p NotPredicate) (Predicate, error) {
 }
 
 // buildColumnPredicateRanges returns a set of rowRanges that are valid based
-// on whether EqualPredicate, InPredicate, GreaterThanPredicate, or LessThanPredicate may be
-// true for each page in a column.
+// on whether EqualPredicate, InPredicate, GreaterThanPredicate, LessThanPredicate, or
+// FuncPredicate may be true for each page in a column.
+//
+// Pages which store their dictionary in statistics are filtered by checking
+// the predicate against every value in the dictionary. Otherwise, pages are
+// filtered by the range of values in the page, if available.
 func (r *Reader) buildColumnPredicateRanges(ctx context.Context, c Column, p Predicate) (rowRanges, error) {
 	// Get the wrapped column so that the result of c.ListPages can be cached.
 	if idx, ok := r.origColumnLookup[c]; ok {
@@ -778,6 +803,22 @@ func (r *Reader) buildColumnPredicateRanges(ctx context.Context, c Column, p Pre
 			End:   uint64(pageStart + pageInfo.RowCount - 1),
 		}
 
+		if dictionary := pageInfo.Stats.GetDictionary(); pageInfo.Encoding == datasetmd.ENCODING_TYPE_DICTIONARY && len(dictionary) > 0 {
+			hasNulls := pageInfo.ValuesCount < pageInfo.RowCount
+
+			include, err := dictionaryIncludes(p, dictionary, hasNulls)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read page dictionary: %w", err)
+			} else if include {
+				ranges.Add(pageRange)
+			}
+			continue
+		} else if _, ok := p.(FuncPredicate); ok {
+			// FuncPredicates can't be checked against a range of values.
+			ranges.Add(pageRange)
+			continue
+		}
+
 		minValue, maxValue, err := readMinMax(pageInfo.Stats)
 		if err != nil {
 			return nil, fmt.Errorf("failed to read page stats: %w", err)
@@ -816,6 +857,44 @@ func (r *Reader) buildColumnPredicateRanges(ctx context.Context, c Column, p Pre
 	return ranges, nil
 }
 
+// dictionaryIncludes reports whether p may be true for any row of a page with
+// the provided dictionary. If hasNulls is true, the page also contains NULL
+// values.
+func dictionaryIncludes(p Predicate, dictionary [][]byte, hasNulls bool) (bool, error) {
+	if p, ok := p.(FuncPredicate); ok && hasNulls && p.Keep(p.Column, Value{}) {
+		return true, nil
+	}
+
+	var value Value
+	for _, valueBytes := range dictionary {
+		if err := value.UnmarshalBinary(valueBytes); err != nil {
+			return false, fmt.Errorf("failed to unmarshal dictionary value: %w", err)
+		}
+
+		var include bool
+		switch p := p.(type) {
+		case EqualPredicate:
+			include = CompareValues(&value, &p.Value) == 0
+		case GreaterThanPredicate:
+			include = CompareValues(&value, &p.Value) > 0
+		case LessThanPredicate:
+			include = CompareValues(&value, &p.Value) < 0
+		case InPredicate:
+			include = p.Values.Contains(value)
+		case FuncPredicate:
+			include = p.Keep(p.Column, value)
+		default:
+			panic(fmt.Sprintf("unsupported predicate type %T", p))
+		}
+
+		if include {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
 // readMinMax reads the minimum and maximum values from the provided
 // statistics. If either minValue or maxValue is NULL, the value is not present
 // in the statistics.
@@ -9,6 +9,7 @@ import (
 	"math/rand"
 	"slices"
 	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/dustin/go-humanize"
@@ -128,6 +129,40 @@ func Test_Reader_ReadWithPredicate_NoSecondary(t *testing.T) {
 	require.Equal(t, expected, actual)
 }
 
+func Test_Reader_ReadWithRowRanges(t *testing.T) {
+	dset, columns := buildTestDataset(t)
+
+	r := NewReader(ReaderOptions{
+		Dataset: dset,
+		Columns: columns,
+		Predicates: []Predicate{
+			GreaterThanPredicate{
+				Column: columns[3], // birth_year column
+				Value:  Int64Value(1985),
+			},
+		},
+		RowRanges: []RowRange{{Start: 0, End: 2}, {Start: 6, End: 100}},
+	})
+	defer r.Close()
+
+	actualRows, err := readDataset(r, 3)
+	require.NoError(t, err)
+
+	var expected []testPerson
+	for i, p := range basicReaderTestData {
+		if (i <= 2 || i >= 6) && p.birthYear > 1985 {
+			expected = append(expected, p)
+		}
+	}
+	require.Equal(t, expected, convertToTestPersons(actualRows))
+
+	// Empty row ranges don't permit any rows.
+	r.Reset(ReaderOptions{Dataset: dset, Columns: columns, RowRanges: []RowRange{}})
+	actualRows, err = readDataset(r, 3)
+	require.NoError(t, err)
+	require.Empty(t, actualRows)
+}
+
 func Test_Reader_Reset(t *testing.T) {
 	dset, columns := buildTestDataset(t)
 	r := NewReader(ReaderOptions{Dataset: dset, Columns: columns})
@@ -389,6 +424,152 @@ func Test_BuildPredicateRanges(t *testing.T) {
 	}
 }
 
+func Test_BuildPredicateRanges_Dictionary(t *testing.T) {
+	ds, cols := buildMemDatasetWithDictionary(t)
+	tt := []struct {
+		name      string
+		predicate Predicate
+		want      rowRanges
+	}{
+		{
+			name:      "equal predicate in dictionary",
+			predicate: EqualPredicate{Column: cols[0], Value: ByteArrayValue([]byte("warn"))},
+			want:      rowRanges{{Start: 100, End: 199}},
+		},
+		{
+			name:      "equal predicate within range but not in dictionary",
+			predicate: EqualPredicate{Column: cols[0], Value: ByteArrayValue([]byte("fatal"))},
+			want:      nil,
+		},
+		{
+			name:      "greater than predicate",
+			predicate: GreaterThanPredicate{Column: cols[0], Value: ByteArrayValue([]byte("info"))},
+			want:      rowRanges{{Start: 100, End: 199}},
+		},
+		{
+			name: "in predicate",
+			predicate: InPredicate{
+				Column: cols[0],
+				Values: NewByteArrayValueSet([]Value{
+					ByteArrayValue([]byte("fatal")),
+					ByteArrayValue([]byte("debug")),
+				}),
+			},
+			want: rowRanges{{Start: 0, End: 99}, {Start: 200, End: 299}},
+		},
+		{
+			name: "not equal predicate",
+			predicate: NotPredicate{
+				Inner: EqualPredicate{Column: cols[0], Value: ByteArrayValue([]byte("debug"))},
+			},
+			want: rowRanges{{Start: 0, End: 99}, {Start: 100, End: 199}},
+		},
+		{
+			name: "func predicate",
+			predicate: FuncPredicate{
+				Column: cols[0],
+				Keep: func(_ Column, value Value) bool {
+					return !value.IsNil() && strings.HasPrefix(string(value.ByteArray()), "warn")
+				},
+			},
+			want: rowRanges{{Start: 100, End: 199}},
+		},
+		{
+			name: "func predicate matching NULL",
+			predicate: FuncPredicate{
+				Column: cols[0],
+				Keep: func(_ Column, value Value) bool {
+					return value.IsNil()
+				},
+			},
+			want: rowRanges{{Start: 200, End: 299}},
+		},
+	}
+
+	ctx := context.Background()
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			r := NewReader(ReaderOptions{
+				Dataset:    ds,
+				Columns:    cols,
+				Predicates: []Predicate{tc.predicate},
+			})
+			defer r.Close()
+
+			require.NoError(t, r.initDownloader(ctx))
+
+			got, err := r.buildPredicateRanges(ctx, tc.predicate)
+			require.NoError(t, err)
+			require.Equal(t, tc.want, got, "row ranges should match expected ranges")
+		})
+	}
+}
+
+// buildMemDatasetWithDictionary creates a test dataset with a single
+// dictionary-encoded column with dictionaries in its page stats.
+func buildMemDatasetWithDictionary(t *testing.T) (Dataset, []Column) {
+	t.Helper()
+
+	dset := FromMemory([]*MemColumn{
+		{
+			Info: ColumnInfo{
+				Name:      "level",
+				Type:      datasetmd.VALUE_TYPE_BYTE_ARRAY,
+				RowsCount: 300,
+			},
+			Pages: []*MemPage{
+				{
+					Info: PageInfo{
+						RowCount:    100, // 0 - 99
+						ValuesCount: 100,
+						Encoding:    datasetmd.ENCODING_TYPE_DICTIONARY,
+						Stats: &datasetmd.Statistics{
+							Dictionary: encodeByteArrayValues(t, "debug", "info"),
+						},
+					},
+				},
+				{
+					Info: PageInfo{
+						RowCount:    100, // 100 - 199
+						ValuesCount: 100,
+						Encoding:    datasetmd.ENCODING_TYPE_DICTIONARY,
+						Stats: &datasetmd.Statistics{
+							Dictionary: encodeByteArrayValues(t, "error", "warn"),
+						},
+					},
+				},
+				{
+					Info: PageInfo{
+						RowCount:    100, // 200 - 299
+						ValuesCount: 50,  // Page contains NULLs.
+						Encoding:    datasetmd.ENCODING_TYPE_DICTIONARY,
+						Stats: &datasetmd.Statistics{
+							Dictionary: encodeByteArrayValues(t, "debug"),
+						},
+					},
+				},
+			},
+		},
+	})
+
+	cols, err := result.Collect(dset.ListColumns(context.Background()))
+	require.NoError(t, err)
+
+	return dset, cols
+}
+
+func encodeByteArrayValues(t *testing.T, values ...string) [][]byte {
+	t.Helper()
+
+	var out [][]byte
+	for _, v := range values {
+		data, err := ByteArrayValue([]byte(v)).MarshalBinary()
+		require.NoError(t, err)
+		out = append(out, data)
+	}
+	return out
+}
+
 // buildMemDatasetWithStats creates a test dataset with only column and page stats.
 func buildMemDatasetWithStats(t *testing.T) (Dataset, []Column) {
 	t.Helper()
@@ -9,6 +9,11 @@ type rowRange struct {
 	Start, End uint64
 }
 
+// RowRange denotes an inclusive range of rows [Start, End] in a [Dataset].
+type RowRange struct {
+	Start, End uint64
+}
+
 // String prints out the row range in a human-readable format
 // "[rr.Start,rr.End]".
 func (rr rowRange) String() string {
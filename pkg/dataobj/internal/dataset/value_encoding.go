@@ -30,6 +30,16 @@ type valueEncoder interface {
 	Reset(w streamio.Writer)
 }
 
+// A bufferedValueEncoder is a [valueEncoder] which buffers encoded values in
+// memory until it is flushed.
+type bufferedValueEncoder interface {
+	valueEncoder
+
+	// BufferedSize returns the estimated size of values which have been
+	// encoded but not yet written to the underlying [streamio.Writer].
+	BufferedSize() int
+}
+
 // A valueDecoder decodes sequences of [Value] from an underlying
 // [streamio.Reader]. Implementations of encoding types must call
 // registerValueEncoding to register themselves.
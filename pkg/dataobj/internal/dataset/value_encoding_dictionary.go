@@ -0,0 +1,306 @@
+package dataset
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/metadata/datasetmd"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/streamio"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/util/slicegrow"
+)
+
+func init() {
+	// Register the encoding so instances of it can be dynamically created.
+	registerValueEncoding(
+		datasetmd.VALUE_TYPE_BYTE_ARRAY,
+		datasetmd.ENCODING_TYPE_DICTIONARY,
+		func(w streamio.Writer) valueEncoder { return newDictionaryEncoder(w) },
+		func(r streamio.Reader) valueDecoder { return newDictionaryDecoder(r) },
+	)
+}
+
+// dictionaryEncoder encodes byte array values using a dictionary. Each
+// distinct value is stored once in the dictionary, and values are stored as
+// indexes into the dictionary. Indexes are encoded with [bitmapEncoder], so
+// long runs of the same value are run-length encoded.
+//
+// Values are buffered in memory until [dictionaryEncoder.Flush] is called.
+//
+// # Format
+//
+// The EBNF grammar is as follows:
+//
+//	dictionary_data  = block*;
+//	block            = dictionary indexes;
+//	dictionary       = uvarint(dictionary_len) entry*;
+//	entry            = uvarint(entry_len) entry_bytes;
+//	indexes          = uvarint(indexes_len) indexes_bytes;
+//	indexes_bytes    = (* bitmap-encoded indexes into dictionary *)
+//
+// A block is written for every call to Flush, so that every block is
+// self-contained.
+type dictionaryEncoder struct {
+	w streamio.Writer
+
+	lookup     map[string]uint64 // Index of values in dictionary.
+	dictionary [][]byte          // Distinct values in order of first appearance.
+	dictSize   int               // Encoded size of dictionary.
+
+	indexes    *bytes.Buffer
+	indexesEnc *bitmapEncoder
+}
+
+var _ valueEncoder = (*dictionaryEncoder)(nil)
+
+// newDictionaryEncoder creates a dictionaryEncoder that writes encoded values
+// to w.
+func newDictionaryEncoder(w streamio.Writer) *dictionaryEncoder {
+	indexes := bytes.NewBuffer(nil)
+
+	return &dictionaryEncoder{
+		w: w,
+
+		lookup: make(map[string]uint64),
+
+		indexes:    indexes,
+		indexesEnc: newBitmapEncoder(indexes),
+	}
+}
+
+// ValueType returns [datasetmd.VALUE_TYPE_BYTE_ARRAY].
+func (enc *dictionaryEncoder) ValueType() datasetmd.ValueType {
+	return datasetmd.VALUE_TYPE_BYTE_ARRAY
+}
+
+// EncodingType returns [datasetmd.ENCODING_TYPE_DICTIONARY].
+func (enc *dictionaryEncoder) EncodingType() datasetmd.EncodingType {
+	return datasetmd.ENCODING_TYPE_DICTIONARY
+}
+
+// Encode encodes an individual byte array value.
+func (enc *dictionaryEncoder) Encode(v Value) error {
+	if v.Type() != datasetmd.VALUE_TYPE_BYTE_ARRAY {
+		return fmt.Errorf("dictionary: invalid value type %v", v.Type())
+	}
+	bv := v.ByteArray()
+
+	index, ok := enc.lookup[string(bv)]
+	if !ok {
+		index = uint64(len(enc.dictionary))
+
+		// Values are copied, as callers may reuse the memory of v.
+		entry := bytes.Clone(bv)
+		enc.lookup[string(entry)] = index
+		enc.dictionary = append(enc.dictionary, entry)
+		enc.dictSize += streamio.UvarintSize(uint64(len(entry))) + len(entry)
+	}
+
+	return enc.indexesEnc.Encode(Uint64Value(index))
+}
+
+// Dictionary returns the distinct values encoded since the last call to
+// Flush or Reset.
+func (enc *dictionaryEncoder) Dictionary() [][]byte {
+	return enc.dictionary
+}
+
+// BufferedSize returns the estimated size of values which have been encoded
+// but not yet written.
+func (enc *dictionaryEncoder) BufferedSize() int {
+	if len(enc.dictionary) == 0 {
+		return 0
+	}
+	return streamio.UvarintSize(uint64(len(enc.dictionary))) + enc.dictSize + enc.indexes.Len()
+}
+
+// Flush writes the dictionary and the indexes of all values encoded since
+// the last call to Flush to the underlying [streamio.Writer].
+func (enc *dictionaryEncoder) Flush() error {
+	if len(enc.dictionary) == 0 {
+		return nil
+	}
+
+	if err := enc.indexesEnc.Flush(); err != nil {
+		return fmt.Errorf("flushing indexes: %w", err)
+	}
+
+	if err := streamio.WriteUvarint(enc.w, uint64(len(enc.dictionary))); err != nil {
+		return err
+	}
+	for _, entry := range enc.dictionary {
+		if err := streamio.WriteUvarint(enc.w, uint64(len(entry))); err != nil {
+			return err
+		} else if n, err := enc.w.Write(entry); err != nil {
+			return err
+		} else if n != len(entry) {
+			return fmt.Errorf("short write; expected %d bytes, wrote %d", len(entry), n)
+		}
+	}
+
+	if err := streamio.WriteUvarint(enc.w, uint64(enc.indexes.Len())); err != nil {
+		return err
+	} else if _, err := enc.indexes.WriteTo(enc.w); err != nil {
+		return err
+	}
+
+	enc.reset()
+	return nil
+}
+
+// Reset implements [valueEncoder]. It discards any buffered values and resets
+// the encoder to write to w.
+func (enc *dictionaryEncoder) Reset(w streamio.Writer) {
+	enc.w = w
+	enc.reset()
+}
+
+func (enc *dictionaryEncoder) reset() {
+	clear(enc.lookup)
+	enc.dictionary = nil
+	enc.dictSize = 0
+
+	enc.indexes.Reset()
+	enc.indexesEnc.Reset(enc.indexes)
+}
+
+// dictionaryDecoder decodes byte array values encoded by [dictionaryEncoder].
+type dictionaryDecoder struct {
+	r streamio.Reader
+
+	dictionary [][]byte
+
+	indexesData []byte
+	indexes     *bytes.Reader
+	indexesDec  *bitmapDecoder
+	indexesBuf  []Value
+
+	inBlock bool // True if a block has been read and not yet exhausted.
+}
+
+var _ valueDecoder = (*dictionaryDecoder)(nil)
+
+// newDictionaryDecoder creates a dictionaryDecoder that reads encoded values
+// from r.
+func newDictionaryDecoder(r streamio.Reader) *dictionaryDecoder {
+	indexes := bytes.NewReader(nil)
+
+	return &dictionaryDecoder{
+		r: r,
+
+		indexes:    indexes,
+		indexesDec: newBitmapDecoder(indexes),
+	}
+}
+
+// ValueType returns [datasetmd.VALUE_TYPE_BYTE_ARRAY].
+func (dec *dictionaryDecoder) ValueType() datasetmd.ValueType {
+	return datasetmd.VALUE_TYPE_BYTE_ARRAY
+}
+
+// EncodingType returns [datasetmd.ENCODING_TYPE_DICTIONARY].
+func (dec *dictionaryDecoder) EncodingType() datasetmd.EncodingType {
+	return datasetmd.ENCODING_TYPE_DICTIONARY
+}
+
+// Decode decodes up to len(s) values, storing the results into s. The
+// number of decoded values is returned, followed by an error (if any).
+// At the end of the stream, Decode returns 0, [io.EOF].
+func (dec *dictionaryDecoder) Decode(s []Value) (int, error) {
+	if len(s) == 0 {
+		return 0, nil
+	}
+
+	for {
+		if !dec.inBlock {
+			if err := dec.readBlock(); err != nil {
+				return 0, err
+			}
+		}
+
+		dec.indexesBuf = slicegrow.GrowToCap(dec.indexesBuf, len(s))
+		dec.indexesBuf = dec.indexesBuf[:len(s)]
+
+		n, err := dec.indexesDec.Decode(dec.indexesBuf)
+		if errors.Is(err, io.EOF) && n == 0 {
+			// Move on to the next block.
+			dec.inBlock = false
+			continue
+		} else if err != nil && !errors.Is(err, io.EOF) {
+			return 0, fmt.Errorf("decoding indexes: %w", err)
+		}
+
+		for i, index := range dec.indexesBuf[:n] {
+			if index.Uint64() >= uint64(len(dec.dictionary)) {
+				return i, fmt.Errorf("dictionary index %d out of range", index.Uint64())
+			}
+			entry := dec.dictionary[index.Uint64()]
+
+			dst := slicegrow.GrowToCap(s[i].Buffer(), len(entry))
+			dst = dst[:len(entry)]
+			copy(dst, entry)
+			s[i] = ByteArrayValue(dst)
+		}
+		return n, nil
+	}
+}
+
+// readBlock reads the next block from the underlying reader. readBlock
+// returns [io.EOF] if there are no more blocks.
+func (dec *dictionaryDecoder) readBlock() error {
+	dictionaryLen, err := binary.ReadUvarint(dec.r)
+	if err != nil {
+		return err
+	}
+
+	dec.dictionary = slicegrow.GrowToCap(dec.dictionary, int(dictionaryLen))
+	dec.dictionary = dec.dictionary[:dictionaryLen]
+	for i := range dec.dictionary {
+		entryLen, err := binary.ReadUvarint(dec.r)
+		if err != nil {
+			return fmt.Errorf("reading dictionary entry size: %w", unexpectedEOF(err))
+		}
+
+		entry := slicegrow.GrowToCap(dec.dictionary[i], int(entryLen))
+		entry = entry[:entryLen]
+		if _, err := io.ReadFull(dec.r, entry); err != nil {
+			return fmt.Errorf("reading dictionary entry: %w", unexpectedEOF(err))
+		}
+		dec.dictionary[i] = entry
+	}
+
+	indexesLen, err := binary.ReadUvarint(dec.r)
+	if err != nil {
+		return fmt.Errorf("reading indexes size: %w", unexpectedEOF(err))
+	}
+	dec.indexesData = slicegrow.GrowToCap(dec.indexesData, int(indexesLen))
+	dec.indexesData = dec.indexesData[:indexesLen]
+	if _, err := io.ReadFull(dec.r, dec.indexesData); err != nil {
+		return fmt.Errorf("reading indexes: %w", unexpectedEOF(err))
+	}
+
+	dec.indexes.Reset(dec.indexesData)
+	dec.indexesDec.Reset(dec.indexes)
+	dec.inBlock = true
+	return nil
+}
+
+// unexpectedEOF converts [io.EOF] into [io.ErrUnexpectedEOF], for reads which
+// must not hit the end of the stream.
+func unexpectedEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// Reset implements [valueDecoder]. It resets the decoder to read from r.
+func (dec *dictionaryDecoder) Reset(r streamio.Reader) {
+	dec.r = r
+	dec.dictionary = dec.dictionary[:0]
+	dec.indexes.Reset(nil)
+	dec.indexesDec.Reset(dec.indexes)
+	dec.inBlock = false
+}
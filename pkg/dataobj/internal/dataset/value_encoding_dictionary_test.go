@@ -0,0 +1,137 @@
+package dataset
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/streamio"
+)
+
+var testDictionaryStrings = []string{
+	"info",
+	"info",
+	"debug",
+	"info",
+	"error",
+	"error",
+	"error",
+	"debug",
+	"warn",
+	"info",
+}
+
+func Test_dictionaryEncoder(t *testing.T) {
+	var buf bytes.Buffer
+
+	var (
+		enc    = newDictionaryEncoder(&buf)
+		dec    = newDictionaryDecoder(&buf)
+		decBuf = make([]Value, batchSize)
+	)
+
+	for _, v := range testDictionaryStrings {
+		require.NoError(t, enc.Encode(ByteArrayValue([]byte(v))))
+	}
+	require.Len(t, enc.Dictionary(), 4)
+	require.NoError(t, enc.Flush())
+	require.Zero(t, enc.BufferedSize())
+
+	require.Equal(t, testDictionaryStrings, decodeDictionaryStrings(t, dec, decBuf))
+}
+
+func Test_dictionaryEncoder_multipleBlocks(t *testing.T) {
+	var buf bytes.Buffer
+
+	var (
+		enc    = newDictionaryEncoder(&buf)
+		dec    = newDictionaryDecoder(&buf)
+		decBuf = make([]Value, 3)
+	)
+
+	// Each flush writes a new, independent block.
+	for _, v := range testDictionaryStrings {
+		require.NoError(t, enc.Encode(ByteArrayValue([]byte(v))))
+	}
+	require.NoError(t, enc.Flush())
+	for _, v := range testStrings {
+		require.NoError(t, enc.Encode(ByteArrayValue([]byte(v))))
+	}
+	require.NoError(t, enc.Flush())
+
+	expect := append(append([]string{}, testDictionaryStrings...), testStrings...)
+	require.Equal(t, expect, decodeDictionaryStrings(t, dec, decBuf))
+}
+
+func Test_dictionaryEncoder_partialRead(t *testing.T) {
+	var buf bytes.Buffer
+
+	var (
+		enc    = newDictionaryEncoder(&buf)
+		dec    = newDictionaryDecoder(&oneByteReader{&buf})
+		decBuf = make([]Value, batchSize)
+	)
+
+	for _, v := range testDictionaryStrings {
+		require.NoError(t, enc.Encode(ByteArrayValue([]byte(v))))
+	}
+	require.NoError(t, enc.Flush())
+
+	require.Equal(t, testDictionaryStrings, decodeDictionaryStrings(t, dec, decBuf))
+}
+
+func Test_dictionaryEncoder_reusingValues(t *testing.T) {
+	var buf bytes.Buffer
+
+	var (
+		enc    = newDictionaryEncoder(&buf)
+		dec    = newDictionaryDecoder(&buf)
+		decBuf = make([]Value, batchSize)
+	)
+
+	// Reuse the memory of the encoded value to ensure the encoder copies it.
+	value := make([]byte, 0, 64)
+	for _, v := range testDictionaryStrings {
+		value = append(value[:0], v...)
+		require.NoError(t, enc.Encode(ByteArrayValue(value)))
+	}
+	require.NoError(t, enc.Flush())
+
+	for i := range decBuf {
+		decBuf[i] = ByteArrayValue(make([]byte, 64))
+	}
+
+	require.Equal(t, testDictionaryStrings, decodeDictionaryStrings(t, dec, decBuf))
+}
+
+func decodeDictionaryStrings(t *testing.T, dec *dictionaryDecoder, decBuf []Value) []string {
+	t.Helper()
+
+	var out []string
+	for {
+		n, err := dec.Decode(decBuf)
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		for _, v := range decBuf[:n] {
+			out = append(out, string(v.ByteArray()))
+		}
+	}
+	return out
+}
+
+func Benchmark_dictionaryEncoder_Append(b *testing.B) {
+	enc := newDictionaryEncoder(streamio.Discard)
+
+	for i := 0; i < b.N; i++ {
+		for _, v := range testDictionaryStrings {
+			_ = enc.Encode(ByteArrayValue([]byte(v)))
+		}
+		_ = enc.Flush()
+	}
+}
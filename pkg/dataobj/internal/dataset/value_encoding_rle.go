@@ -0,0 +1,266 @@
+package dataset
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/metadata/datasetmd"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/streamio"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/util/slicegrow"
+)
+
+func init() {
+	// Register the encodings so instances of them can be dynamically created.
+	registerValueEncoding(
+		datasetmd.VALUE_TYPE_INT64,
+		datasetmd.ENCODING_TYPE_RLE,
+		func(w streamio.Writer) valueEncoder { return newRLEEncoder(w, datasetmd.VALUE_TYPE_INT64) },
+		func(r streamio.Reader) valueDecoder { return newRLEDecoder(r, datasetmd.VALUE_TYPE_INT64) },
+	)
+	registerValueEncoding(
+		datasetmd.VALUE_TYPE_BYTE_ARRAY,
+		datasetmd.ENCODING_TYPE_RLE,
+		func(w streamio.Writer) valueEncoder { return newRLEEncoder(w, datasetmd.VALUE_TYPE_BYTE_ARRAY) },
+		func(r streamio.Reader) valueDecoder { return newRLEDecoder(r, datasetmd.VALUE_TYPE_BYTE_ARRAY) },
+	)
+}
+
+// rleEncoder encodes sequences of repeated int64 or byte array values. Each
+// sequence is stored as the number of repetitions followed by the value.
+//
+// rleEncoder is best suited for columns with long runs of the same value; for
+// columns where values rarely repeat consecutively, rleEncoder uses more space
+// than the plain encoding.
+//
+// # Format
+//
+// The EBNF grammar is as follows:
+//
+//	rle_data = run*;
+//	run      = uvarint(run_length) value;
+//	value    = varint(int64) | uvarint(len) bytes;
+type rleEncoder struct {
+	w         streamio.Writer
+	valueType datasetmd.ValueType
+
+	runLength uint64
+	runInt    int64
+	runBytes  []byte
+}
+
+var _ valueEncoder = (*rleEncoder)(nil)
+
+// newRLEEncoder creates an rleEncoder that writes encoded values of the given
+// valueType to w.
+func newRLEEncoder(w streamio.Writer, valueType datasetmd.ValueType) *rleEncoder {
+	return &rleEncoder{w: w, valueType: valueType}
+}
+
+// ValueType returns the type of values encoded by enc.
+func (enc *rleEncoder) ValueType() datasetmd.ValueType {
+	return enc.valueType
+}
+
+// EncodingType returns [datasetmd.ENCODING_TYPE_RLE].
+func (enc *rleEncoder) EncodingType() datasetmd.EncodingType {
+	return datasetmd.ENCODING_TYPE_RLE
+}
+
+// Encode encodes an individual value. The current run is written once a
+// different value is encoded or Flush is called.
+func (enc *rleEncoder) Encode(v Value) error {
+	if v.Type() != enc.valueType {
+		return fmt.Errorf("rle: invalid value type %v", v.Type())
+	}
+
+	if enc.runLength > 0 && enc.continuesRun(v) {
+		enc.runLength++
+		return nil
+	}
+
+	if err := enc.Flush(); err != nil {
+		return err
+	}
+
+	switch enc.valueType {
+	case datasetmd.VALUE_TYPE_INT64:
+		enc.runInt = v.Int64()
+	case datasetmd.VALUE_TYPE_BYTE_ARRAY:
+		// Values are copied, as callers may reuse the memory of v.
+		enc.runBytes = append(enc.runBytes[:0], v.ByteArray()...)
+	}
+	enc.runLength = 1
+	return nil
+}
+
+func (enc *rleEncoder) continuesRun(v Value) bool {
+	switch enc.valueType {
+	case datasetmd.VALUE_TYPE_INT64:
+		return v.Int64() == enc.runInt
+	case datasetmd.VALUE_TYPE_BYTE_ARRAY:
+		return bytes.Equal(v.ByteArray(), enc.runBytes)
+	}
+	return false
+}
+
+// BufferedSize returns the size of the current run, which has been encoded
+// but not yet written.
+func (enc *rleEncoder) BufferedSize() int {
+	if enc.runLength == 0 {
+		return 0
+	}
+
+	size := streamio.UvarintSize(enc.runLength)
+	switch enc.valueType {
+	case datasetmd.VALUE_TYPE_INT64:
+		size += streamio.VarintSize(enc.runInt)
+	case datasetmd.VALUE_TYPE_BYTE_ARRAY:
+		size += streamio.UvarintSize(uint64(len(enc.runBytes))) + len(enc.runBytes)
+	}
+	return size
+}
+
+// Flush writes the current run to the underlying [streamio.Writer].
+func (enc *rleEncoder) Flush() error {
+	if enc.runLength == 0 {
+		return nil
+	}
+
+	if err := streamio.WriteUvarint(enc.w, enc.runLength); err != nil {
+		return err
+	}
+
+	switch enc.valueType {
+	case datasetmd.VALUE_TYPE_INT64:
+		if err := streamio.WriteVarint(enc.w, enc.runInt); err != nil {
+			return err
+		}
+	case datasetmd.VALUE_TYPE_BYTE_ARRAY:
+		if err := streamio.WriteUvarint(enc.w, uint64(len(enc.runBytes))); err != nil {
+			return err
+		} else if n, err := enc.w.Write(enc.runBytes); err != nil {
+			return err
+		} else if n != len(enc.runBytes) {
+			return fmt.Errorf("short write; expected %d bytes, wrote %d", len(enc.runBytes), n)
+		}
+	}
+
+	enc.runLength = 0
+	return nil
+}
+
+// Reset implements [valueEncoder]. It discards the current run and resets the
+// encoder to write to w.
+func (enc *rleEncoder) Reset(w streamio.Writer) {
+	enc.w = w
+	enc.runLength = 0
+	enc.runInt = 0
+	enc.runBytes = enc.runBytes[:0]
+}
+
+// rleDecoder decodes values encoded by [rleEncoder].
+type rleDecoder struct {
+	r         streamio.Reader
+	valueType datasetmd.ValueType
+
+	remaining uint64 // Number of values remaining in the current run.
+	runInt    int64
+	runBytes  []byte
+}
+
+var _ valueDecoder = (*rleDecoder)(nil)
+
+// newRLEDecoder creates an rleDecoder that reads encoded values of the given
+// valueType from r.
+func newRLEDecoder(r streamio.Reader, valueType datasetmd.ValueType) *rleDecoder {
+	return &rleDecoder{r: r, valueType: valueType}
+}
+
+// ValueType returns the type of values decoded by dec.
+func (dec *rleDecoder) ValueType() datasetmd.ValueType {
+	return dec.valueType
+}
+
+// EncodingType returns [datasetmd.ENCODING_TYPE_RLE].
+func (dec *rleDecoder) EncodingType() datasetmd.EncodingType {
+	return datasetmd.ENCODING_TYPE_RLE
+}
+
+// Decode decodes up to len(s) values, storing the results into s. The
+// number of decoded values is returned, followed by an error (if any).
+// At the end of the stream, Decode returns 0, [io.EOF].
+func (dec *rleDecoder) Decode(s []Value) (int, error) {
+	if len(s) == 0 {
+		return 0, nil
+	}
+
+	for i := range s {
+		if dec.remaining == 0 {
+			err := dec.readRun()
+			if errors.Is(err, io.EOF) {
+				if i == 0 {
+					return 0, io.EOF
+				}
+				return i, nil
+			} else if err != nil {
+				return i, err
+			}
+		}
+
+		switch dec.valueType {
+		case datasetmd.VALUE_TYPE_INT64:
+			s[i] = Int64Value(dec.runInt)
+		case datasetmd.VALUE_TYPE_BYTE_ARRAY:
+			dst := slicegrow.GrowToCap(s[i].Buffer(), len(dec.runBytes))
+			dst = dst[:len(dec.runBytes)]
+			copy(dst, dec.runBytes)
+			s[i] = ByteArrayValue(dst)
+		}
+		dec.remaining--
+	}
+	return len(s), nil
+}
+
+// readRun reads the next run from the stream.
+func (dec *rleDecoder) readRun() error {
+	runLength, err := binary.ReadUvarint(dec.r)
+	if err != nil {
+		return err
+	} else if runLength == 0 {
+		return fmt.Errorf("rle: invalid run length 0")
+	}
+
+	switch dec.valueType {
+	case datasetmd.VALUE_TYPE_INT64:
+		dec.runInt, err = binary.ReadVarint(dec.r)
+		if err != nil {
+			return fmt.Errorf("rle: reading value: %w", unexpectedEOF(err))
+		}
+	case datasetmd.VALUE_TYPE_BYTE_ARRAY:
+		sz, err := binary.ReadUvarint(dec.r)
+		if err != nil {
+			return fmt.Errorf("rle: reading value size: %w", unexpectedEOF(err))
+		}
+		dec.runBytes = slicegrow.GrowToCap(dec.runBytes, int(sz))
+		dec.runBytes = dec.runBytes[:sz]
+		if _, err := io.ReadFull(dec.r, dec.runBytes); err != nil {
+			return fmt.Errorf("rle: reading value: %w", unexpectedEOF(err))
+		}
+	default:
+		return fmt.Errorf("rle: unsupported value type %v", dec.valueType)
+	}
+
+	dec.remaining = runLength
+	return nil
+}
+
+// Reset implements [valueDecoder]. It resets the decoder to read from r.
+func (dec *rleDecoder) Reset(r streamio.Reader) {
+	dec.r = r
+	dec.remaining = 0
+	dec.runInt = 0
+	dec.runBytes = dec.runBytes[:0]
+}
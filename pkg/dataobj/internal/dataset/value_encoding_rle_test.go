@@ -0,0 +1,82 @@
+package dataset
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/metadata/datasetmd"
+)
+
+func Test_rleEncoder_Int64(t *testing.T) {
+	var buf bytes.Buffer
+
+	var (
+		enc    = newRLEEncoder(&buf, datasetmd.VALUE_TYPE_INT64)
+		dec    = newRLEDecoder(&buf, datasetmd.VALUE_TYPE_INT64)
+		decBuf = make([]Value, 3)
+	)
+
+	in := []int64{5, 5, 5, 5, -10, 7, 7, 5, 5, 5}
+	for _, v := range in {
+		require.NoError(t, enc.Encode(Int64Value(v)))
+	}
+	require.NoError(t, enc.Flush())
+	require.Zero(t, enc.BufferedSize())
+
+	var out []int64
+	for {
+		n, err := dec.Decode(decBuf)
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		for _, v := range decBuf[:n] {
+			out = append(out, v.Int64())
+		}
+	}
+
+	require.Equal(t, in, out)
+}
+
+func Test_rleEncoder_ByteArray(t *testing.T) {
+	var buf bytes.Buffer
+
+	var (
+		enc    = newRLEEncoder(&buf, datasetmd.VALUE_TYPE_BYTE_ARRAY)
+		dec    = newRLEDecoder(&oneByteReader{&buf}, datasetmd.VALUE_TYPE_BYTE_ARRAY)
+		decBuf = make([]Value, batchSize)
+	)
+
+	// Reuse the memory of the encoded value to ensure the encoder copies it.
+	value := make([]byte, 0, 64)
+	for _, v := range testDictionaryStrings {
+		value = append(value[:0], v...)
+		require.NoError(t, enc.Encode(ByteArrayValue(value)))
+	}
+	require.NoError(t, enc.Flush())
+
+	var out []string
+	for {
+		n, err := dec.Decode(decBuf)
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		for _, v := range decBuf[:n] {
+			out = append(out, string(v.ByteArray()))
+		}
+	}
+
+	require.Equal(t, testDictionaryStrings, out)
+}
+
+func Test_rleEncoder_invalidType(t *testing.T) {
+	enc := newRLEEncoder(&bytes.Buffer{}, datasetmd.VALUE_TYPE_INT64)
+	require.Error(t, enc.Encode(ByteArrayValue([]byte("foo"))))
+}
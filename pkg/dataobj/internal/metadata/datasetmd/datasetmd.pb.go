@@ -71,6 +71,8 @@ const (
 	COMPRESSION_TYPE_SNAPPY CompressionType = 2
 	// Zstd compression.
 	COMPRESSION_TYPE_ZSTD CompressionType = 3
+	// LZ4 compression.
+	COMPRESSION_TYPE_LZ4 CompressionType = 4
 )
 
 var CompressionType_name = map[int32]string{
@@ -78,6 +80,7 @@ var CompressionType_name = map[int32]string{
 	1: "COMPRESSION_TYPE_NONE",
 	2: "COMPRESSION_TYPE_SNAPPY",
 	3: "COMPRESSION_TYPE_ZSTD",
+	4: "COMPRESSION_TYPE_LZ4",
 }
 
 var CompressionType_value = map[string]int32{
@@ -85,6 +88,7 @@ var CompressionType_value = map[string]int32{
 	"COMPRESSION_TYPE_NONE":        1,
 	"COMPRESSION_TYPE_SNAPPY":      2,
 	"COMPRESSION_TYPE_ZSTD":        3,
+	"COMPRESSION_TYPE_LZ4":         4,
 }
 
 func (CompressionType) EnumDescriptor() ([]byte, []int) {
@@ -106,6 +110,13 @@ const (
 	// Bitmap encoding. Bitmaps effiently store repeating sequences of unsigned
 	// integers using a combination of run-length encoding and bitpacking.
 	ENCODING_TYPE_BITMAP EncodingType = 3
+	// Dictionary encoding. Each distinct value within the page is stored once
+	// in a dictionary, followed by the indexes of values into the dictionary
+	// stored using bitmap encoding.
+	ENCODING_TYPE_DICTIONARY EncodingType = 4
+	// Run-length encoding. Sequences of repeated values are stored as the
+	// length of the sequence followed by the value.
+	ENCODING_TYPE_RLE EncodingType = 5
 )
 
 var EncodingType_name = map[int32]string{
@@ -113,6 +124,8 @@ var EncodingType_name = map[int32]string{
 	1: "ENCODING_TYPE_PLAIN",
 	2: "ENCODING_TYPE_DELTA",
 	3: "ENCODING_TYPE_BITMAP",
+	4: "ENCODING_TYPE_DICTIONARY",
+	5: "ENCODING_TYPE_RLE",
 }
 
 var EncodingType_value = map[string]int32{
@@ -120,6 +133,8 @@ var EncodingType_value = map[string]int32{
 	"ENCODING_TYPE_PLAIN":       1,
 	"ENCODING_TYPE_DELTA":       2,
 	"ENCODING_TYPE_BITMAP":      3,
+	"ENCODING_TYPE_DICTIONARY":  4,
+	"ENCODING_TYPE_RLE":         5,
 }
 
 func (EncodingType) EnumDescriptor() ([]byte, []int) {
@@ -277,6 +292,12 @@ type Statistics struct {
 	// Applications must not assume that an unset cardinality_count means that
 	// the column has no distinct values; check for values_count == 0 instead.
 	CardinalityCount uint64 `protobuf:"varint,3,opt,name=cardinality_count,json=cardinalityCount,proto3" json:"cardinality_count,omitempty"`
+	// Distinct values of a page, each encoded like min_value. Only set for
+	// pages using ENCODING_TYPE_DICTIONARY.
+	//
+	// Applications may skip pages for which no value of the dictionary passes
+	// a predicate without reading the page.
+	Dictionary [][]byte `protobuf:"bytes,4,rep,name=dictionary,proto3" json:"dictionary,omitempty"`
 }
 
 func (m *Statistics) Reset()      { *m = Statistics{} }
@@ -332,6 +353,13 @@ func (m *Statistics) GetCardinalityCount() uint64 {
 	return 0
 }
 
+func (m *Statistics) GetDictionary() [][]byte {
+	if m != nil {
+		return m.Dictionary
+	}
+	return nil
+}
+
 // Page describes an individual page within a column.
 type PageInfo struct {
 	// Uncompressed size of the page within the data object.
@@ -468,53 +496,56 @@ func init() {
 }
 
 var fileDescriptor_7ab9d5b21b743868 = []byte{
-	// 728 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x55, 0x4f, 0x53, 0xda, 0x5e,
-	0x14, 0xe5, 0x01, 0xfa, 0x23, 0x17, 0xd4, 0xf8, 0x7e, 0x5a, 0x63, 0xa9, 0x29, 0xb5, 0x33, 0x95,
-	0x6a, 0x07, 0xa6, 0xd8, 0x69, 0xd7, 0xfc, 0x49, 0x9d, 0xcc, 0x68, 0xc8, 0x04, 0x74, 0x06, 0x37,
-	0x99, 0x67, 0x08, 0x34, 0x95, 0x24, 0x0c, 0x09, 0x54, 0x5c, 0x75, 0xe5, 0xba, 0x1f, 0xa3, 0x1f,
-	0xa5, 0x4b, 0x97, 0x2e, 0x2b, 0xce, 0x74, 0xba, 0xf4, 0x23, 0x74, 0x78, 0xe1, 0x4f, 0x04, 0xca,
-	0xb8, 0xe8, 0xee, 0x71, 0xce, 0xb9, 0xef, 0x5e, 0xee, 0x39, 0x0f, 0xe0, 0x43, 0xf3, 0xbc, 0x9e,
-	0xae, 0x12, 0x97, 0xd8, 0x67, 0x9f, 0xd3, 0x86, 0xe5, 0xea, 0x2d, 0x8b, 0x34, 0xd2, 0xa6, 0xee,
-	0x92, 0x3e, 0x48, 0x19, 0x47, 0x77, 0xcd, 0xea, 0xf8, 0x94, 0x6a, 0xb6, 0x6c, 0xd7, 0xc6, 0xf1,
-	0x41, 0x51, 0x6a, 0xa8, 0x4d, 0x0d, 0x14, 0xa9, 0xce, 0xdb, 0xed, 0x5f, 0x21, 0x80, 0xbc, 0xdd,
-	0x68, 0x9b, 0x96, 0x68, 0xd5, 0x6c, 0x8c, 0x21, 0x6c, 0x11, 0x53, 0xe7, 0x50, 0x02, 0x25, 0x19,
-	0x85, 0x9e, 0xb1, 0x00, 0xd0, 0x21, 0x8d, 0xb6, 0xae, 0xba, 0xdd, 0xa6, 0xce, 0x05, 0x13, 0x28,
-	0xb9, 0x9c, 0x79, 0x95, 0x9a, 0x73, 0x69, 0xea, 0xa4, 0x2f, 0x2f, 0x77, 0x9b, 0xba, 0xc2, 0x74,
-	0x86, 0x47, 0xbc, 0x05, 0xd0, 0xb2, 0xbf, 0x38, 0xaa, 0x66, 0xb7, 0x2d, 0x97, 0x0b, 0x25, 0x50,
-	0x32, 0xac, 0x30, 0x7d, 0x24, 0xdf, 0x07, 0xb0, 0x04, 0x51, 0xcd, 0x36, 0x9b, 0x2d, 0xdd, 0x71,
-	0x0c, 0xdb, 0xe2, 0xc2, 0xb4, 0xcd, 0x9b, 0xb9, 0x6d, 0xf2, 0x63, 0x3d, 0x6d, 0xe6, 0xbf, 0x00,
-	0xef, 0xc1, 0x6a, 0xdb, 0x1a, 0x02, 0x7a, 0x55, 0x75, 0x8c, 0x4b, 0x9d, 0x5b, 0xa0, 0x5d, 0x59,
-	0x3f, 0x51, 0x32, 0x2e, 0x75, 0xbc, 0x03, 0x2b, 0x93, 0xd2, 0x45, 0x2a, 0x5d, 0x9e, 0x16, 0x0e,
-	0x27, 0x51, 0xed, 0x5a, 0xcd, 0xd1, 0x5d, 0xee, 0x3f, 0x4f, 0x38, 0x84, 0x8b, 0x14, 0xc5, 0x2f,
-	0x61, 0x69, 0x24, 0xa4, 0xf7, 0x45, 0xa8, 0x2c, 0x36, 0x04, 0xe9, 0x6d, 0x07, 0x00, 0x8e, 0x4b,
-	0x5c, 0xc3, 0x71, 0x0d, 0xcd, 0xe1, 0x98, 0x04, 0x4a, 0x46, 0x33, 0x3b, 0x73, 0xbf, 0x72, 0x69,
-	0x24, 0x57, 0x7c, 0xa5, 0xf8, 0x05, 0xc4, 0xe8, 0xa2, 0x87, 0xdb, 0x05, 0xda, 0x2c, 0xea, 0x61,
-	0x74, 0xbf, 0xdb, 0x0e, 0xc0, 0xb8, 0x18, 0xc7, 0x81, 0x31, 0x0d, 0x4b, 0xa5, 0x02, 0x6a, 0x76,
-	0x4c, 0x89, 0x98, 0x86, 0x45, 0x8d, 0xa3, 0x24, 0xb9, 0x18, 0x90, 0xc1, 0x01, 0x49, 0x2e, 0x3c,
-	0x72, 0x0f, 0x56, 0x35, 0xd2, 0xaa, 0x1a, 0x16, 0x69, 0x18, 0x6e, 0xf7, 0x81, 0x9b, 0xac, 0x8f,
-	0xf0, 0x9a, 0x5e, 0x85, 0x20, 0x22, 0x93, 0xba, 0x4e, 0xb3, 0x35, 0xd3, 0x11, 0xf4, 0x78, 0x47,
-	0x82, 0x33, 0x1d, 0x59, 0x83, 0x05, 0xad, 0xa5, 0xed, 0x67, 0xe8, 0x0c, 0x4b, 0x8a, 0xf7, 0x61,
-	0x22, 0x6c, 0xe1, 0xc9, 0xb0, 0x09, 0x10, 0xd1, 0x2d, 0xcd, 0xae, 0x1a, 0x56, 0x9d, 0x66, 0x62,
-	0x39, 0xf3, 0x7a, 0xee, 0xda, 0x85, 0x81, 0x98, 0xc6, 0x6c, 0x54, 0x8a, 0x9f, 0x43, 0xd4, 0x9f,
-	0x04, 0x2f, 0x32, 0xe0, 0x4b, 0x41, 0x1c, 0x98, 0x71, 0x02, 0xbc, 0xa0, 0x44, 0xfe, 0xe2, 0x7e,
-	0xe4, 0xdf, 0xb9, 0xcf, 0x4c, 0xb9, 0xbf, 0xdb, 0x06, 0x66, 0xf4, 0x28, 0xf1, 0x53, 0x78, 0x72,
-	0x92, 0x3d, 0x3c, 0x16, 0xd4, 0x72, 0x45, 0x16, 0xd4, 0x63, 0xa9, 0x24, 0x0b, 0x79, 0xf1, 0xa3,
-	0x28, 0x14, 0xd8, 0x00, 0x5e, 0x03, 0xd6, 0xc7, 0x89, 0x52, 0xf9, 0xfd, 0x3b, 0x16, 0xe1, 0x75,
-	0x58, 0xf5, 0x57, 0x78, 0x70, 0x10, 0x6f, 0xc2, 0xba, 0x0f, 0xce, 0x55, 0xca, 0x82, 0x9a, 0x55,
-	0x94, 0x6c, 0x85, 0x0d, 0x6f, 0x87, 0x23, 0x21, 0x36, 0xb4, 0x7b, 0x85, 0x60, 0x65, 0xe2, 0x95,
-	0xe2, 0x04, 0x3c, 0xcb, 0x17, 0x8f, 0x64, 0x45, 0x28, 0x95, 0xc4, 0xa2, 0x34, 0x6b, 0x86, 0x4d,
-	0x58, 0x9f, 0x52, 0x48, 0x45, 0x49, 0x60, 0x11, 0x8e, 0xc3, 0xc6, 0x14, 0x55, 0x92, 0xb2, 0xb2,
-	0x5c, 0xf1, 0xc6, 0x99, 0x22, 0x4f, 0x4b, 0xe5, 0x02, 0x1b, 0xda, 0xed, 0x42, 0xcc, 0xef, 0x21,
-	0xde, 0x82, 0x4d, 0x41, 0xca, 0x17, 0x0b, 0xa2, 0x74, 0x30, 0x6b, 0x82, 0x0d, 0xf8, 0xff, 0x21,
-	0x2d, 0x1f, 0x66, 0x45, 0x89, 0x45, 0xd3, 0x44, 0x41, 0x38, 0x2c, 0x67, 0xd9, 0x20, 0xe6, 0x60,
-	0xed, 0x21, 0x91, 0x13, 0xcb, 0x47, 0x59, 0x99, 0x0d, 0xe5, 0x2e, 0xae, 0x6f, 0xf9, 0xc0, 0xcd,
-	0x2d, 0x1f, 0xb8, 0xbf, 0xe5, 0xd1, 0xd7, 0x1e, 0x8f, 0xbe, 0xf7, 0x78, 0xf4, 0xa3, 0xc7, 0xa3,
-	0xeb, 0x1e, 0x8f, 0x7e, 0xf6, 0x78, 0xf4, 0xbb, 0xc7, 0x07, 0xee, 0x7b, 0x3c, 0xfa, 0x76, 0xc7,
-	0x07, 0xae, 0xef, 0xf8, 0xc0, 0xcd, 0x1d, 0x1f, 0x38, 0xcd, 0xd5, 0x0d, 0xf7, 0x53, 0xfb, 0x2c,
-	0xa5, 0xd9, 0x66, 0xba, 0xde, 0x22, 0x35, 0x62, 0x91, 0x74, 0xc3, 0x3e, 0x37, 0xd2, 0x9d, 0xfd,
-	0xf4, 0x23, 0xff, 0x08, 0xce, 0x16, 0xe9, 0xef, 0xff, 0xfe, 0x9f, 0x00, 0x00, 0x00, 0xff, 0xff,
-	0xaa, 0x6b, 0x9a, 0xcf, 0x3a, 0x06, 0x00, 0x00,
+	// 783 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x55, 0x4d, 0x8f, 0xda, 0x56,
+	0x14, 0xe5, 0x61, 0x4f, 0x8a, 0x2f, 0x64, 0xe2, 0x79, 0x9d, 0x69, 0x3c, 0x25, 0x71, 0x29, 0x95,
+	0x1a, 0x3a, 0xa9, 0x40, 0x65, 0xa2, 0x76, 0x6d, 0xc0, 0x8d, 0x2c, 0x11, 0x63, 0x19, 0x27, 0x12,
+	0xb3, 0xb1, 0xde, 0x18, 0x43, 0xdd, 0x60, 0x1b, 0xd9, 0x86, 0x0e, 0x59, 0x75, 0xd5, 0x75, 0xa5,
+	0xfe, 0x81, 0x2e, 0xbb, 0xea, 0xef, 0xe8, 0x72, 0x96, 0x59, 0x76, 0x18, 0xa9, 0xea, 0x72, 0x7e,
+	0x42, 0xc5, 0x33, 0x1f, 0xe6, 0xa3, 0x68, 0x16, 0xd9, 0x3d, 0xce, 0x39, 0xf7, 0xdd, 0xcb, 0x3d,
+	0xc7, 0x7a, 0xf0, 0xdd, 0xf0, 0x6d, 0xbf, 0xd2, 0x25, 0x11, 0xf1, 0x2f, 0x7f, 0xac, 0x38, 0x5e,
+	0x64, 0x07, 0x1e, 0x19, 0x54, 0x5c, 0x3b, 0x22, 0x33, 0x90, 0x32, 0xa1, 0x1d, 0xb9, 0xdd, 0xd5,
+	0xa9, 0x3c, 0x0c, 0xfc, 0xc8, 0xc7, 0xf9, 0x79, 0x51, 0x79, 0xa1, 0x2d, 0xcf, 0x15, 0xe5, 0xf1,
+	0x37, 0xc5, 0x7f, 0x18, 0x80, 0xba, 0x3f, 0x18, 0xb9, 0x9e, 0xe2, 0xf5, 0x7c, 0x8c, 0x81, 0xf5,
+	0x88, 0x6b, 0x0b, 0xa8, 0x80, 0x4a, 0x9c, 0x4e, 0xcf, 0x58, 0x06, 0x18, 0x93, 0xc1, 0xc8, 0x36,
+	0xa3, 0xc9, 0xd0, 0x16, 0xd2, 0x05, 0x54, 0x3a, 0xac, 0x7e, 0x59, 0xde, 0x73, 0x69, 0xf9, 0xcd,
+	0x4c, 0x6e, 0x4c, 0x86, 0xb6, 0xce, 0x8d, 0x17, 0x47, 0xfc, 0x14, 0x20, 0xf0, 0x7f, 0x0a, 0x4d,
+	0xcb, 0x1f, 0x79, 0x91, 0xc0, 0x14, 0x50, 0x89, 0xd5, 0xb9, 0x19, 0x52, 0x9f, 0x01, 0x58, 0x85,
+	0xac, 0xe5, 0xbb, 0xc3, 0xc0, 0x0e, 0x43, 0xc7, 0xf7, 0x04, 0x96, 0xb6, 0xf9, 0x7a, 0x6f, 0x9b,
+	0xfa, 0x4a, 0x4f, 0x9b, 0x25, 0x2f, 0xc0, 0xcf, 0xe1, 0x68, 0xe4, 0x2d, 0x00, 0xbb, 0x6b, 0x86,
+	0xce, 0x3b, 0x5b, 0x38, 0xa0, 0x5d, 0xf9, 0x24, 0xd1, 0x76, 0xde, 0xd9, 0xf8, 0x19, 0x3c, 0xda,
+	0x94, 0x3e, 0xa0, 0xd2, 0xc3, 0x6d, 0xe1, 0x62, 0x12, 0xd3, 0xef, 0xf5, 0x42, 0x3b, 0x12, 0x3e,
+	0x8a, 0x85, 0x0b, 0xb8, 0x45, 0x51, 0xfc, 0x05, 0x3c, 0x5c, 0x0a, 0xe9, 0x7d, 0x19, 0x2a, 0xcb,
+	0x2d, 0x40, 0x7a, 0xdb, 0x4b, 0x80, 0x30, 0x22, 0x91, 0x13, 0x46, 0x8e, 0x15, 0x0a, 0x5c, 0x01,
+	0x95, 0xb2, 0xd5, 0x67, 0x7b, 0xff, 0x72, 0x7b, 0x29, 0xd7, 0x13, 0xa5, 0xf8, 0x73, 0xc8, 0xd1,
+	0x45, 0x2f, 0xb6, 0x0b, 0xb4, 0x59, 0x36, 0xc6, 0xe8, 0x7e, 0x8b, 0xbf, 0x21, 0x80, 0x55, 0x35,
+	0xce, 0x03, 0xe7, 0x3a, 0x9e, 0x49, 0x15, 0xd4, 0xed, 0x9c, 0x9e, 0x71, 0x1d, 0x8f, 0x3a, 0x47,
+	0x49, 0x72, 0x35, 0x27, 0xd3, 0x73, 0x92, 0x5c, 0xc5, 0xe4, 0x73, 0x38, 0xb2, 0x48, 0xd0, 0x75,
+	0x3c, 0x32, 0x70, 0xa2, 0xc9, 0x9a, 0x9d, 0x7c, 0x82, 0x88, 0x5d, 0x15, 0x01, 0xba, 0x8e, 0x15,
+	0x39, 0xbe, 0x47, 0x82, 0x89, 0xc0, 0x16, 0x98, 0x52, 0x4e, 0x4f, 0x20, 0xc5, 0x5f, 0x18, 0xc8,
+	0x68, 0xa4, 0x6f, 0xd3, 0xf0, 0xed, 0xb4, 0x0c, 0xdd, 0xdf, 0xb2, 0xf4, 0x4e, 0xcb, 0x8e, 0xe1,
+	0xc0, 0x0a, 0xac, 0xf3, 0x2a, 0x9d, 0xf1, 0xa1, 0x1e, 0xff, 0xd8, 0x48, 0x23, 0xbb, 0x99, 0x46,
+	0x19, 0x32, 0xb6, 0x67, 0xf9, 0x5d, 0xc7, 0xeb, 0xd3, 0xd0, 0x1c, 0x56, 0xbf, 0xda, 0xeb, 0x8b,
+	0x3c, 0x17, 0xd3, 0x1c, 0x2e, 0x4b, 0xf1, 0x67, 0x90, 0x4d, 0x46, 0x25, 0xce, 0x14, 0x24, 0x62,
+	0x92, 0x07, 0x6e, 0x15, 0x91, 0x38, 0x49, 0x99, 0xff, 0x89, 0x47, 0xe6, 0xc3, 0xc5, 0x83, 0xdb,
+	0x8a, 0xc7, 0xd9, 0x08, 0xb8, 0xe5, 0x57, 0x8b, 0x3f, 0x85, 0x4f, 0xde, 0x48, 0xcd, 0xd7, 0xb2,
+	0x69, 0x74, 0x34, 0xd9, 0x7c, 0xad, 0xb6, 0x35, 0xb9, 0xae, 0x7c, 0xaf, 0xc8, 0x0d, 0x3e, 0x85,
+	0x8f, 0x81, 0x4f, 0x70, 0x8a, 0x6a, 0x7c, 0xfb, 0x82, 0x47, 0xf8, 0x04, 0x8e, 0x92, 0x15, 0x31,
+	0x9c, 0xc6, 0xa7, 0x70, 0x92, 0x80, 0x6b, 0x1d, 0x43, 0x36, 0x25, 0x5d, 0x97, 0x3a, 0x3c, 0x5b,
+	0x64, 0x33, 0x0c, 0xcf, 0x9c, 0xfd, 0x8e, 0xe0, 0xd1, 0xc6, 0x67, 0x8c, 0x0b, 0xf0, 0xa4, 0xde,
+	0x7a, 0xa5, 0xe9, 0x72, 0xbb, 0xad, 0xb4, 0xd4, 0x5d, 0x33, 0x9c, 0xc2, 0xc9, 0x96, 0x42, 0x6d,
+	0xa9, 0x32, 0x8f, 0x70, 0x1e, 0x1e, 0x6f, 0x51, 0x6d, 0x55, 0xd2, 0xb4, 0x4e, 0x3c, 0xce, 0x16,
+	0x79, 0xd1, 0x36, 0x1a, 0x3c, 0x83, 0x05, 0x38, 0xde, 0xa2, 0x9a, 0x17, 0x2f, 0x78, 0xf6, 0xec,
+	0x4f, 0x04, 0xb9, 0xa4, 0xbd, 0xf8, 0x29, 0x9c, 0xca, 0x6a, 0xbd, 0xd5, 0x50, 0xd4, 0x97, 0xbb,
+	0x86, 0x7b, 0x0c, 0x1f, 0xaf, 0xd3, 0x5a, 0x53, 0x52, 0x54, 0x1e, 0x6d, 0x13, 0x0d, 0xb9, 0x69,
+	0x48, 0x7c, 0x7a, 0xd6, 0x7b, 0x9d, 0xa8, 0x29, 0xc6, 0x2b, 0x49, 0xe3, 0x19, 0xfc, 0x04, 0x84,
+	0x8d, 0x12, 0xa5, 0x6e, 0x28, 0x2d, 0x55, 0xd2, 0x3b, 0x3c, 0x3b, 0x5b, 0xfa, 0x3a, 0xab, 0x37,
+	0x65, 0xfe, 0xa0, 0x76, 0x75, 0x7d, 0x23, 0xa6, 0xde, 0xdf, 0x88, 0xa9, 0xbb, 0x1b, 0x11, 0xfd,
+	0x3c, 0x15, 0xd1, 0x1f, 0x53, 0x11, 0xfd, 0x35, 0x15, 0xd1, 0xf5, 0x54, 0x44, 0x7f, 0x4f, 0x45,
+	0xf4, 0xef, 0x54, 0x4c, 0xdd, 0x4d, 0x45, 0xf4, 0xeb, 0xad, 0x98, 0xba, 0xbe, 0x15, 0x53, 0xef,
+	0x6f, 0xc5, 0xd4, 0x45, 0xad, 0xef, 0x44, 0x3f, 0x8c, 0x2e, 0xcb, 0x96, 0xef, 0x56, 0xfa, 0x01,
+	0xe9, 0x11, 0x8f, 0x54, 0x06, 0xfe, 0x5b, 0xa7, 0x32, 0x3e, 0xaf, 0xdc, 0xf3, 0xe5, 0xb9, 0x7c,
+	0x40, 0x1f, 0x9c, 0xf3, 0xff, 0x06, 0x00, 0xa5, 0x72, 0x0b, 0xed, 0xab, 0x06, 0x00, 0x00,
 }
 
 func (x ValueType) String() string {
@@ -617,6 +648,14 @@ func (this *Statistics) Equal(that interface{}) bool {
 	if this.CardinalityCount != that1.CardinalityCount {
 		return false
 	}
+	if len(this.Dictionary) != len(that1.Dictionary) {
+		return false
+	}
+	for i := range this.Dictionary {
+		if !bytes.Equal(this.Dictionary[i], that1.Dictionary[i]) {
+			return false
+		}
+	}
 	return true
 }
 func (this *PageInfo) Equal(that interface{}) bool {
@@ -692,11 +731,12 @@ func (this *Statistics) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 7)
+	s := make([]string, 0, 8)
 	s = append(s, "&datasetmd.Statistics{")
 	s = append(s, "MinValue: "+fmt.Sprintf("%#v", this.MinValue)+",\n")
 	s = append(s, "MaxValue: "+fmt.Sprintf("%#v", this.MaxValue)+",\n")
 	s = append(s, "CardinalityCount: "+fmt.Sprintf("%#v", this.CardinalityCount)+",\n")
+	s = append(s, "Dictionary: "+fmt.Sprintf("%#v", this.Dictionary)+",\n")
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -830,6 +870,15 @@ func (m *Statistics) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if len(m.Dictionary) > 0 {
+		for iNdEx := len(m.Dictionary) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.Dictionary[iNdEx])
+			copy(dAtA[i:], m.Dictionary[iNdEx])
+			i = encodeVarintDatasetmd(dAtA, i, uint64(len(m.Dictionary[iNdEx])))
+			i--
+			dAtA[i] = 0x22
+		}
+	}
 	if m.CardinalityCount != 0 {
 		i = encodeVarintDatasetmd(dAtA, i, uint64(m.CardinalityCount))
 		i--
@@ -996,6 +1045,12 @@ func (m *Statistics) Size() (n int) {
 	if m.CardinalityCount != 0 {
 		n += 1 + sovDatasetmd(uint64(m.CardinalityCount))
 	}
+	if len(m.Dictionary) > 0 {
+		for _, b := range m.Dictionary {
+			l = len(b)
+			n += 1 + l + sovDatasetmd(uint64(l))
+		}
+	}
 	return n
 }
 
@@ -1069,6 +1124,7 @@ func (this *Statistics) String() string {
 		`MinValue:` + fmt.Sprintf("%v", this.MinValue) + `,`,
 		`MaxValue:` + fmt.Sprintf("%v", this.MaxValue) + `,`,
 		`CardinalityCount:` + fmt.Sprintf("%v", this.CardinalityCount) + `,`,
+		`Dictionary:` + fmt.Sprintf("%v", this.Dictionary) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -1488,6 +1544,38 @@ func (m *Statistics) Unmarshal(dAtA []byte) error {
 					break
 				}
 			}
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Dictionary", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDatasetmd
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthDatasetmd
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthDatasetmd
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Dictionary = append(m.Dictionary, make([]byte, postIndex-iNdEx))
+			copy(m.Dictionary[len(m.Dictionary)-1], dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipDatasetmd(dAtA[iNdEx:])
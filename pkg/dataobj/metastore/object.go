@@ -15,6 +15,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/bits-and-blooms/bloom/v3"
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
 	"github.com/grafana/dskit/tenant"
@@ -24,6 +25,7 @@ import (
 	"golang.org/x/sync/errgroup"
 
 	"github.com/grafana/loki/v3/pkg/dataobj"
+	"github.com/grafana/loki/v3/pkg/dataobj/sections/blooms"
 	"github.com/grafana/loki/v3/pkg/dataobj/sections/indexpointers"
 	"github.com/grafana/loki/v3/pkg/dataobj/sections/logs"
 	"github.com/grafana/loki/v3/pkg/dataobj/sections/pointers"
@@ -32,6 +34,9 @@ import (
 
 const (
 	metastoreWindowSize = 12 * time.Hour
+
+	// WindowSize is the time range covered by a single metastore object.
+	WindowSize = metastoreWindowSize
 )
 
 type ObjectMetastore struct {
@@ -54,6 +59,10 @@ type DataobjSectionDescriptor struct {
 	Size      int64
 	Start     time.Time
 	End       time.Time
+
+	// IndexPath is the path of the index object which the section was found
+	// in. The index object may hold a token index of the section.
+	IndexPath string
 }
 
 func NewSectionDescriptor(pointer pointers.SectionPointer) *DataobjSectionDescriptor {
@@ -99,6 +108,46 @@ func iterStorePaths(tenantID string, start, end time.Time) iter.Seq[string] {
 	}
 }
 
+// Windows returns the start times of all metastore windows of a tenant that
+// exist in bucket, in chronological order.
+func Windows(ctx context.Context, bucket objstore.BucketReader, tenantID string) ([]time.Time, error) {
+	var (
+		windows []time.Time
+		prefix  = fmt.Sprintf("tenant-%s/metastore/", tenantID)
+	)
+	err := bucket.Iter(ctx, prefix, func(name string) error {
+		name = strings.TrimSuffix(strings.TrimPrefix(name, prefix), ".store")
+		window, err := time.Parse(time.RFC3339, name)
+		if err != nil {
+			return fmt.Errorf("invalid metastore window %s: %w", name, err)
+		}
+		windows = append(windows, window.UTC())
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(windows, time.Time.Compare)
+	return windows, nil
+}
+
+// Tenants returns the IDs of all tenants with objects in bucket. Tenants
+// without metastore objects have no [Windows].
+func Tenants(ctx context.Context, bucket objstore.BucketReader) ([]string, error) {
+	var tenants []string
+	err := bucket.Iter(ctx, "", func(name string) error {
+		tenantID, ok := strings.CutPrefix(strings.TrimSuffix(name, "/"), "tenant-")
+		if ok && tenantID != "" {
+			tenants = append(tenants, tenantID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tenants, nil
+}
+
 func NewObjectMetastore(bucket objstore.Bucket, logger log.Logger, reg prometheus.Registerer) *ObjectMetastore {
 	store := &ObjectMetastore{
 		bucket:      bucket,
@@ -231,8 +280,7 @@ func (m *ObjectMetastore) Sections(ctx context.Context, start, end time.Time, ma
 
 	// Search the section AMQs to estimate sections that might match the predicates
 	// AMQs may return false positives so this is an over-estimate.
-	pointerMatchers := pointerPredicateFromMatchers(predicates...)
-	sectionMembershipEstimates, err := m.estimateSectionsForPredicates(ctx, paths, pointerMatchers)
+	sectionMembershipEstimates, err := m.estimateSectionsForPredicates(ctx, paths, predicates)
 	if err != nil {
 		return nil, err
 	}
@@ -410,6 +458,9 @@ func pointerPredicateFromMatchers(matchers ...*labels.Matcher) pointers.RowPredi
 			})
 		}
 	}
+	if len(predicates) == 0 {
+		return nil
+	}
 
 	current := predicates[0]
 
@@ -549,7 +600,9 @@ func (m *ObjectMetastore) getSectionsForStreams(ctx context.Context, paths []str
 
 				sectionDescriptor, ok := objectSectionDescriptors[key]
 				if !ok {
-					objectSectionDescriptors[key] = NewSectionDescriptor(pointer)
+					sectionDescriptor = NewSectionDescriptor(pointer)
+					sectionDescriptor.IndexPath = path
+					objectSectionDescriptors[key] = sectionDescriptor
 					return
 				}
 				sectionDescriptor.Merge(pointer)
@@ -579,12 +632,36 @@ func (m *ObjectMetastore) getSectionsForStreams(ctx context.Context, paths []str
 	return sectionDescriptors, nil
 }
 
-// estimateSectionsForPredicates checks the predicates against the section AMQs to determine approximate section membership.
-// This is an inexact lookup and only returns probable sections: there may be false positives, but no true negatives. There is no additional metadata returned beyond the section info.
-func (m *ObjectMetastore) estimateSectionsForPredicates(ctx context.Context, paths []string, predicate pointers.RowPredicate) ([]*DataobjSectionDescriptor, error) {
+// bloomMatchersFromMatchers returns the matchers which can be checked against
+// the bloom filters of the blooms section. Only equality matchers with a
+// non-empty value are supported, as an empty value also matches sections
+// without the column.
+func bloomMatchersFromMatchers(matchers ...*labels.Matcher) []*labels.Matcher {
+	var bloomMatchers []*labels.Matcher
+	for _, matcher := range matchers {
+		if matcher.Type == labels.MatchEqual && matcher.Value != "" {
+			bloomMatchers = append(bloomMatchers, matcher)
+		}
+	}
+	return bloomMatchers
+}
+
+// estimateSectionsForPredicates returns the sections referenced by the index
+// objects at paths which might contain logs matching all predicates. This is
+// an inexact lookup: there may be false positives, but no false negatives.
+//
+// Index objects with a blooms section are checked against the bloom filters of
+// the blooms section. Older index objects fall back to the column indexes of
+// the pointers section.
+func (m *ObjectMetastore) estimateSectionsForPredicates(ctx context.Context, paths []string, predicates []*labels.Matcher) ([]*DataobjSectionDescriptor, error) {
 	timer := prometheus.NewTimer(m.metrics.estimateSectionsTotalDuration)
 	defer timer.ObserveDuration()
 
+	var (
+		pointerPredicate = pointerPredicateFromMatchers(predicates...)
+		bloomMatchers    = bloomMatchersFromMatchers(predicates...)
+	)
+
 	g, ctx := errgroup.WithContext(ctx)
 	g.SetLimit(m.parallelism)
 
@@ -599,16 +676,26 @@ func (m *ObjectMetastore) estimateSectionsForPredicates(ctx context.Context, pat
 
 			pointerReadTimer := prometheus.NewTimer(m.metrics.estimateSectionsPointerReadDuration)
 			var objectSectionDescriptors []*DataobjSectionDescriptor
-			err = forEachObjPointer(ctx, idxObject, predicate, nil, func(pointer pointers.SectionPointer) {
-				objectSectionDescriptors = append(objectSectionDescriptors, &DataobjSectionDescriptor{
-					SectionKey: SectionKey{
-						ObjectPath: pointer.Path,
-						SectionIdx: pointer.Section,
-					},
+			if len(bloomMatchers) > 0 && idxObject.Sections().Count(blooms.CheckSection) > 0 {
+				keys, err := matchBloomSections(ctx, idxObject, bloomMatchers)
+				if err != nil {
+					return fmt.Errorf("reading blooms from index: %w", err)
+				}
+				for _, key := range keys {
+					objectSectionDescriptors = append(objectSectionDescriptors, &DataobjSectionDescriptor{SectionKey: key})
+				}
+			} else {
+				err = forEachObjPointer(ctx, idxObject, pointerPredicate, nil, func(pointer pointers.SectionPointer) {
+					objectSectionDescriptors = append(objectSectionDescriptors, &DataobjSectionDescriptor{
+						SectionKey: SectionKey{
+							ObjectPath: pointer.Path,
+							SectionIdx: pointer.Section,
+						},
+					})
 				})
-			})
-			if err != nil {
-				return fmt.Errorf("reading object from bucket: %w", err)
+				if err != nil {
+					return fmt.Errorf("reading object from bucket: %w", err)
+				}
 			}
 			pointerReadTimer.ObserveDuration()
 
@@ -629,6 +716,79 @@ func (m *ObjectMetastore) estimateSectionsForPredicates(ctx context.Context, pat
 	return sectionDescriptors, nil
 }
 
+// matchBloomSections returns the keys of the sections for which the bloom
+// filters in the blooms sections of object pass all matchers. Sections without
+// a bloom filter for the column of a matcher don't have the column, and
+// therefore never pass.
+func matchBloomSections(ctx context.Context, object *dataobj.Object, matchers []*labels.Matcher) ([]SectionKey, error) {
+	names := make([]string, 0, len(matchers))
+	for _, matcher := range matchers {
+		if !slices.Contains(names, matcher.Name) {
+			names = append(names, matcher.Name)
+		}
+	}
+
+	var (
+		reader blooms.RowReader
+		buf    = make([]blooms.Bloom, 128)
+		filter = bloom.New(1, 1) // Dummy values, overwritten by ReadFrom.
+
+		// passed counts the matchers which passed for each section.
+		passed = make(map[SectionKey]int)
+	)
+	defer reader.Close()
+
+	for _, section := range object.Sections().Filter(blooms.CheckSection) {
+		sec, err := blooms.Open(ctx, section)
+		if err != nil {
+			return nil, fmt.Errorf("opening section: %w", err)
+		}
+
+		reader.Reset(sec)
+		if err := reader.SetPredicate(blooms.ColumnNameRowPredicate{Names: names}); err != nil {
+			return nil, err
+		}
+
+		for {
+			n, err := reader.Read(ctx, buf)
+			if err != nil && !errors.Is(err, io.EOF) {
+				return nil, err
+			}
+			if n == 0 && errors.Is(err, io.EOF) {
+				break
+			}
+
+			for _, b := range buf[:n] {
+				key := SectionKey{ObjectPath: b.Path, SectionIdx: b.Section}
+				if _, err := filter.ReadFrom(bytes.NewReader(b.Filter)); err != nil {
+					// If the bloom filter is invalid, we assume all matchers
+					// of the column pass.
+					for _, matcher := range matchers {
+						if matcher.Name == b.ColumnName {
+							passed[key]++
+						}
+					}
+					continue
+				}
+
+				for _, matcher := range matchers {
+					if matcher.Name == b.ColumnName && filter.TestString(matcher.Value) {
+						passed[key]++
+					}
+				}
+			}
+		}
+	}
+
+	keys := make([]SectionKey, 0, len(passed))
+	for key, count := range passed {
+		if count == len(matchers) {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
 func fetchObject(ctx context.Context, bucket objstore.Bucket, path string) (*dataobj.Object, error) {
 	return dataobj.FromBucket(ctx, bucket, path)
 }
@@ -662,6 +822,11 @@ func (m *ObjectMetastore) listObjects(ctx context.Context, path string, start, e
 	if err != nil {
 		return nil, fmt.Errorf("reading metastore object: %w", err)
 	}
+	if n == 0 {
+		// All entries of the metastore object have been removed; see
+		// [Updater.Replace].
+		return nil, nil
+	}
 	object, err := dataobj.FromReaderAt(bytes.NewReader(buf.Bytes()), n)
 	if err != nil {
 		return nil, fmt.Errorf("getting object from reader: %w", err)
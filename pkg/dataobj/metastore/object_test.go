@@ -8,6 +8,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/bits-and-blooms/bloom/v3"
 	"github.com/go-kit/log"
 	"github.com/grafana/dskit/user"
 	"github.com/prometheus/client_golang/prometheus"
@@ -16,6 +17,7 @@ import (
 	"github.com/thanos-io/objstore"
 
 	"github.com/grafana/loki/v3/pkg/dataobj/consumer/logsobj"
+	"github.com/grafana/loki/v3/pkg/dataobj/index/indexobj"
 	"github.com/grafana/loki/v3/pkg/dataobj/uploader"
 	"github.com/grafana/loki/v3/pkg/logproto"
 )
@@ -293,3 +295,91 @@ func newTestDataBuilder(t *testing.T, tenantID string) *testDataBuilder {
 		uploader: uploader,
 	}
 }
+
+func TestEstimateSectionsForPredicates(t *testing.T) {
+	ctx := context.Background()
+	bucket := objstore.NewInMemBucket()
+
+	builder, err := indexobj.NewBuilder(indexobj.BuilderConfig{
+		TargetPageSize:          1024 * 1024,
+		TargetObjectSize:        10 * 1024 * 1024,
+		TargetSectionSize:       1024 * 1024,
+		BufferSize:              1024 * 1024,
+		SectionStripeMergeLimit: 2,
+	})
+	require.NoError(t, err)
+
+	// Section 0 holds traces 1 and 2 and section 1 holds trace 3. Only section
+	// 0 has a request_id column.
+	appendBloom := func(section int64, columnName string, values ...string) {
+		filter := bloom.NewWithEstimates(uint(len(values)), 1.0/128.0)
+		for _, value := range values {
+			filter.AddString(value)
+		}
+		filterBytes, err := filter.MarshalBinary()
+		require.NoError(t, err)
+		require.NoError(t, builder.AppendColumnIndex("objects/0", section, columnName, filterBytes))
+	}
+	appendBloom(0, "trace_id", "trace-1", "trace-2")
+	appendBloom(0, "request_id", "request-1")
+	appendBloom(1, "trace_id", "trace-3")
+
+	var buf bytes.Buffer
+	_, err = builder.Flush(&buf)
+	require.NoError(t, err)
+	require.NoError(t, bucket.Upload(ctx, "indexes/0", &buf))
+
+	mstore := NewObjectMetastore(bucket, log.NewNopLogger(), nil)
+
+	tt := []struct {
+		name       string
+		predicates []*labels.Matcher
+		expect     []int64
+	}{
+		{
+			name:       "single match",
+			predicates: []*labels.Matcher{labels.MustNewMatcher(labels.MatchEqual, "trace_id", "trace-3")},
+			expect:     []int64{1},
+		},
+		{
+			name:       "no match",
+			predicates: []*labels.Matcher{labels.MustNewMatcher(labels.MatchEqual, "trace_id", "trace-4")},
+			expect:     nil,
+		},
+		{
+			name:       "missing column",
+			predicates: []*labels.Matcher{labels.MustNewMatcher(labels.MatchEqual, "user_id", "user-1")},
+			expect:     nil,
+		},
+		{
+			name: "all predicates must match",
+			predicates: []*labels.Matcher{
+				labels.MustNewMatcher(labels.MatchEqual, "trace_id", "trace-1"),
+				labels.MustNewMatcher(labels.MatchEqual, "request_id", "request-1"),
+			},
+			expect: []int64{0},
+		},
+		{
+			name: "one predicate does not match",
+			predicates: []*labels.Matcher{
+				labels.MustNewMatcher(labels.MatchEqual, "trace_id", "trace-3"),
+				labels.MustNewMatcher(labels.MatchEqual, "request_id", "request-1"),
+			},
+			expect: nil,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			sections, err := mstore.estimateSectionsForPredicates(ctx, []string{"indexes/0"}, tc.predicates)
+			require.NoError(t, err)
+
+			var actual []int64
+			for _, section := range sections {
+				require.Equal(t, "objects/0", section.ObjectPath)
+				actual = append(actual, section.SectionIdx)
+			}
+			require.ElementsMatch(t, tc.expect, actual)
+		})
+	}
+}
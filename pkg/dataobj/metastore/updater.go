@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"io"
+	"slices"
 	"strconv"
 	"sync"
 	"time"
@@ -116,8 +117,25 @@ func (m *Updater) initBuilder() error {
 	return initErr
 }
 
+// Entry is an object referenced by the metastore together with the time range
+// of the logs it contains.
+type Entry struct {
+	Path         string
+	MinTimestamp time.Time
+	MaxTimestamp time.Time
+}
+
 // Update adds provided dataobj path to the metastore. Flush stats are used to determine the stored metadata about this dataobj.
 func (m *Updater) Update(ctx context.Context, dataobjPath string, minTimestamp, maxTimestamp time.Time) error {
+	return m.Replace(ctx, nil, []Entry{{Path: dataobjPath, MinTimestamp: minTimestamp, MaxTimestamp: maxTimestamp}})
+}
+
+// Replace removes the removed entries from the metastore and adds the added
+// entries in their place. Each metastore object is updated in a single write,
+// so readers never observe a metastore object that contains both or neither
+// of the removed and added entries. Metastore objects without any remaining
+// entries are left empty.
+func (m *Updater) Replace(ctx context.Context, removed, added []Entry) error {
 	var err error
 	processingTime := prometheus.NewTimer(m.metrics.metastoreProcessingTime)
 	defer processingTime.ObserveDuration()
@@ -127,9 +145,35 @@ func (m *Updater) Update(ctx context.Context, dataobjPath string, minTimestamp,
 		return err
 	}
 
+	var (
+		removedPaths = make(map[string]struct{}, len(removed))
+		addedByStore = make(map[string][]Entry)
+		storePaths   []string
+	)
+	for _, entry := range removed {
+		removedPaths[entry.Path] = struct{}{}
+		for metastorePath := range iterStorePaths(m.tenantID, entry.MinTimestamp, entry.MaxTimestamp) {
+			if _, ok := addedByStore[metastorePath]; !ok {
+				addedByStore[metastorePath] = nil
+				storePaths = append(storePaths, metastorePath)
+			}
+		}
+	}
+	for _, entry := range added {
+		for metastorePath := range iterStorePaths(m.tenantID, entry.MinTimestamp, entry.MaxTimestamp) {
+			if _, ok := addedByStore[metastorePath]; !ok {
+				storePaths = append(storePaths, metastorePath)
+			}
+			addedByStore[metastorePath] = append(addedByStore[metastorePath], entry)
+		}
+	}
+	// Store paths contain the RFC3339 formatted window, so sorting them sorts
+	// the windows chronologically.
+	slices.Sort(storePaths)
+
 	// Work our way through the metastore objects window by window, updating & creating them as needed.
 	// Each one handles its own retries in order to keep making progress in the event of a failure.
-	for metastorePath := range iterStorePaths(m.tenantID, minTimestamp, maxTimestamp) {
+	for _, metastorePath := range storePaths {
 		m.backoff.Reset()
 		for m.backoff.Ongoing() {
 			err = m.bucket.GetAndReplace(ctx, metastorePath, func(existing io.Reader) (io.Reader, error) {
@@ -154,7 +198,7 @@ func (m *Updater) Update(ctx context.Context, dataobjPath string, minTimestamp,
 					if err != nil {
 						return nil, errors.Wrap(err, "creating object from buffer")
 					}
-					ty, err = m.readFromExisting(ctx, object)
+					ty, err = m.readFromExisting(ctx, object, removedPaths)
 					if err != nil {
 						return nil, errors.Wrap(err, "reading existing metastore version")
 					}
@@ -162,21 +206,36 @@ func (m *Updater) Update(ctx context.Context, dataobjPath string, minTimestamp,
 				}
 
 				encodingDuration := prometheus.NewTimer(m.metrics.metastoreEncodingTime)
-				err = m.append(ty, dataobjPath, minTimestamp, maxTimestamp)
-				if err != nil {
-					return nil, errors.Wrap(err, "appending to metastore builder")
+				for _, entry := range addedByStore[metastorePath] {
+					err = m.append(ty, entry.Path, entry.MinTimestamp, entry.MaxTimestamp)
+					if err != nil {
+						return nil, errors.Wrap(err, "appending to metastore builder")
+					}
 				}
 
 				m.buf.Reset()
 
+				// Data objects can't be empty, so a metastore object without any
+				// entries is written as an empty object instead. Emptying it in
+				// the same conditional write, rather than deleting it afterwards,
+				// ensures entries added concurrently are never lost. This only
+				// happens when entries are removed from old windows.
 				switch ty {
 				case StorageFormatTypeV1:
 					_, err = m.metastoreBuilder.Flush(m.buf)
+					if errors.Is(err, logsobj.ErrBuilderEmpty) {
+						m.buf.Reset()
+						return m.buf, nil
+					}
 					if err != nil {
 						return nil, errors.Wrap(err, "flushing metastore builder")
 					}
 				case StorageFormatTypeV2:
 					_, err = m.builder.Flush(m.buf)
+					if errors.Is(err, indexobj.ErrBuilderEmpty) {
+						m.buf.Reset()
+						return m.buf, nil
+					}
 					if err != nil {
 						return nil, errors.Wrap(err, "flushing metastore builder")
 					}
@@ -233,7 +292,8 @@ func (m *Updater) append(ty StorageFormatType, dataobjPath string, minTimestamp,
 }
 
 // readFromExisting reads the provided metastore object and appends the streams to the builder so it can be later modified.
-func (m *Updater) readFromExisting(ctx context.Context, object *dataobj.Object) (StorageFormatType, error) {
+// Entries for the paths in removedPaths are not appended.
+func (m *Updater) readFromExisting(ctx context.Context, object *dataobj.Object, removedPaths map[string]struct{}) (StorageFormatType, error) {
 	var streamsReader streams.RowReader
 	defer streamsReader.Close()
 
@@ -263,6 +323,9 @@ func (m *Updater) readFromExisting(ctx context.Context, object *dataobj.Object)
 					return StorageFormatTypeV1, errors.Wrap(err, "reading streams")
 				}
 				for _, stream := range buf[:n] {
+					if _, ok := removedPaths[stream.Labels.Get(labelNamePath)]; ok {
+						continue
+					}
 					err = m.metastoreBuilder.Append(logproto.Stream{
 						Labels:  stream.Labels.String(),
 						Entries: []logproto.Entry{{Line: ""}},
@@ -286,6 +349,9 @@ func (m *Updater) readFromExisting(ctx context.Context, object *dataobj.Object)
 					return StorageFormatTypeV2, errors.Wrap(err, "reading index pointers")
 				}
 				for _, indexPointer := range pbuf[:n] {
+					if _, ok := removedPaths[indexPointer.Path]; ok {
+						continue
+					}
 					err = m.builder.AppendIndexPointer(indexPointer.Path, indexPointer.StartTs, indexPointer.EndTs)
 					if err != nil {
 						return StorageFormatTypeV2, errors.Wrap(err, "appending index pointers")
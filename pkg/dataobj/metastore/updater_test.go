@@ -3,6 +3,7 @@ package metastore
 import (
 	"bytes"
 	"context"
+	"fmt"
 	io "io"
 	"strconv"
 	"sync"
@@ -11,6 +12,7 @@ import (
 
 	"github.com/go-kit/log"
 	"github.com/grafana/dskit/backoff"
+	"github.com/grafana/dskit/user"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/stretchr/testify/require"
@@ -104,12 +106,66 @@ func TestUpdater(t *testing.T) {
 		dobj, err := dataobj.FromReaderAt(bytes.NewReader(object), int64(len(object)))
 		require.NoError(t, err)
 
-		ty, err := updater.readFromExisting(context.Background(), dobj)
+		ty, err := updater.readFromExisting(context.Background(), dobj, nil)
 		require.NoError(t, err)
 		require.Equal(t, StorageFormatTypeV1, ty)
 	})
 }
 
+func TestUpdater_Replace(t *testing.T) {
+	for _, format := range []StorageFormatType{StorageFormatTypeV1, StorageFormatTypeV2} {
+		t.Run(fmt.Sprintf("format v%d", format+1), func(t *testing.T) {
+			tenantID := "test"
+			ctx := user.InjectOrgID(context.Background(), tenantID)
+			bucket := objstore.NewInMemBucket()
+
+			updater := NewUpdater(UpdaterConfig{StorageFormat: format}, bucket, tenantID, log.NewNopLogger())
+			require.NoError(t, updater.Update(ctx, "objects/a", unixTime(10), unixTime(20)))
+			require.NoError(t, updater.Update(ctx, "objects/b", unixTime(20), unixTime(30)))
+			require.NoError(t, updater.Update(ctx, "objects/c", unixTime(30), unixTime(40)))
+
+			err := updater.Replace(ctx,
+				[]Entry{
+					{Path: "objects/a", MinTimestamp: unixTime(10), MaxTimestamp: unixTime(20)},
+					{Path: "objects/b", MinTimestamp: unixTime(20), MaxTimestamp: unixTime(30)},
+				},
+				[]Entry{{Path: "objects/ab", MinTimestamp: unixTime(10), MaxTimestamp: unixTime(30)}},
+			)
+			require.NoError(t, err)
+
+			ms := NewObjectMetastore(bucket, log.NewNopLogger(), nil)
+			objects, err := ms.DataObjects(ctx, unixTime(0), unixTime(50))
+			require.NoError(t, err)
+			require.ElementsMatch(t, []string{"objects/ab", "objects/c"}, objects)
+
+			// Removing all entries of a window empties its metastore object
+			// instead of deleting it, and later updates fill it again.
+			err = updater.Replace(ctx, []Entry{{Path: "objects/c", MinTimestamp: unixTime(30), MaxTimestamp: unixTime(40)}}, nil)
+			require.NoError(t, err)
+			objects, err = ms.DataObjects(ctx, unixTime(0), unixTime(50))
+			require.NoError(t, err)
+			require.Equal(t, []string{"objects/ab"}, objects)
+
+			err = updater.Replace(ctx, []Entry{{Path: "objects/ab", MinTimestamp: unixTime(10), MaxTimestamp: unixTime(30)}}, nil)
+			require.NoError(t, err)
+			objects, err = ms.DataObjects(ctx, unixTime(0), unixTime(50))
+			require.NoError(t, err)
+			require.Empty(t, objects)
+
+			reader, err := bucket.Get(ctx, metastorePath(tenantID, unixTime(0)))
+			require.NoError(t, err)
+			object, err := io.ReadAll(reader)
+			require.NoError(t, err)
+			require.Empty(t, object)
+
+			require.NoError(t, updater.Update(ctx, "objects/d", unixTime(10), unixTime(20)))
+			objects, err = ms.DataObjects(ctx, unixTime(0), unixTime(50))
+			require.NoError(t, err)
+			require.Equal(t, []string{"objects/d"}, objects)
+		})
+	}
+}
+
 func newUpdater(t *testing.T, tenantID string, bucket objstore.Bucket, v1 *logsobj.Builder, v2 *indexobj.Builder) *Updater {
 	t.Helper()
 
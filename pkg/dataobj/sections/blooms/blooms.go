@@ -0,0 +1,132 @@
+// Package blooms defines types used for the data object blooms section. The
+// blooms section holds bloom filters of the values of columns in sections of
+// other data objects, such as structured metadata columns of logs sections.
+// Bloom filters allow skipping sections which can't contain a value before
+// any of their pages are read.
+package blooms
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grafana/loki/v3/pkg/dataobj"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/metadata/bloomsmd"
+)
+
+var sectionType = dataobj.SectionType{
+	Namespace: "github.com/grafana/loki",
+	Kind:      "blooms",
+}
+
+// CheckSection returns true if section is a blooms section.
+func CheckSection(section *dataobj.Section) bool { return section.Type == sectionType }
+
+// Section represents an opened blooms section.
+type Section struct {
+	reader  dataobj.SectionReader
+	columns []*Column
+}
+
+// Open opens a Section from an underlying [dataobj.Section]. Open returns an
+// error if the section metadata could not be read or if the provided ctx is
+// canceled.
+func Open(ctx context.Context, section *dataobj.Section) (*Section, error) {
+	if !CheckSection(section) {
+		return nil, fmt.Errorf("section type mismatch: got=%s want=%s", section.Type, sectionType)
+	}
+
+	sec := &Section{reader: section.Reader}
+	if err := sec.init(ctx); err != nil {
+		return nil, fmt.Errorf("intializing section: %w", err)
+	}
+	return sec, nil
+}
+
+func (s *Section) init(ctx context.Context) error {
+	dec := newDecoder(s.reader)
+	cols, err := dec.Columns(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to decode columns: %w", err)
+	}
+
+	for _, col := range cols {
+		colType, ok := convertColumnType(col.Type)
+		if !ok {
+			// Skip over unrecognized columns.
+			continue
+		}
+
+		s.columns = append(s.columns, &Column{
+			Section: s,
+			Name:    col.Info.Name,
+			Type:    colType,
+
+			desc: col,
+		})
+	}
+
+	return nil
+}
+
+// Columns returns the set of Columns in the section. The slice of returned
+// sections must not be mutated.
+//
+// Unrecognized columns (e.g., when running older code against newer blooms
+// sections) are skipped.
+func (s *Section) Columns() []*Column { return s.columns }
+
+// ColumnType represents the kind of information stored in a [Column].
+type ColumnType int
+
+const (
+	ColumnTypeInvalid     ColumnType = iota // ColumnTypeInvalid is an invalid column.
+	ColumnTypePath                          // ColumnTypePath is a column containing the path to the referenced data object.
+	ColumnTypeSection                       // ColumnTypeSection is a column containing the index of the section in the referenced data object.
+	ColumnTypeColumnName                    // ColumnTypeColumnName is a column containing the name of the column in the referenced section.
+	ColumnTypeBloomFilter                   // ColumnTypeBloomFilter is a column containing a bloom filter of the values of the column in the referenced section.
+)
+
+var columnTypeNames = map[ColumnType]string{
+	ColumnTypeInvalid:     "invalid",
+	ColumnTypePath:        "path",
+	ColumnTypeSection:     "section",
+	ColumnTypeColumnName:  "column_name",
+	ColumnTypeBloomFilter: "bloom_filter",
+}
+
+// String returns the human-readable name of ct.
+func (ct ColumnType) String() string {
+	text, ok := columnTypeNames[ct]
+	if !ok {
+		return fmt.Sprintf("ColumnType(%d)", ct)
+	}
+	return text
+}
+
+// A Column represents one of the columns in the blooms section. Valid columns
+// can only be retrieved by calling [Section.Columns].
+//
+// Data in columns can be read by using a [RowReader].
+type Column struct {
+	Section *Section   // Section that contains the column.
+	Name    string     // Optional name of the column.
+	Type    ColumnType // Type of data in the column.
+
+	desc *bloomsmd.ColumnDesc // Column description used for further decoding and reading.
+}
+
+func convertColumnType(protoType bloomsmd.ColumnType) (ColumnType, bool) {
+	switch protoType {
+	case bloomsmd.COLUMN_TYPE_UNSPECIFIED:
+		return ColumnTypeInvalid, true
+	case bloomsmd.COLUMN_TYPE_PATH:
+		return ColumnTypePath, true
+	case bloomsmd.COLUMN_TYPE_SECTION:
+		return ColumnTypeSection, true
+	case bloomsmd.COLUMN_TYPE_COLUMN_NAME:
+		return ColumnTypeColumnName, true
+	case bloomsmd.COLUMN_TYPE_BLOOM_FILTER:
+		return ColumnTypeBloomFilter, true
+	}
+	return ColumnTypeInvalid, false
+}
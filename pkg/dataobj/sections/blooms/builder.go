@@ -0,0 +1,238 @@
+package blooms
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/loki/v3/pkg/dataobj"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/dataset"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/metadata/bloomsmd"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/metadata/datasetmd"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/util/sliceclear"
+)
+
+// A Bloom is a bloom filter of the values of a column in a section of another
+// data object.
+type Bloom struct {
+	Path       string // Path of the referenced data object.
+	Section    int64  // Index of the section in the referenced data object.
+	ColumnName string // Name of the column in the referenced section.
+
+	// Filter is a bloom filter of the values of the column, as marshaled by
+	// [github.com/bits-and-blooms/bloom/v3.BloomFilter.MarshalBinary].
+	Filter []byte
+}
+
+// Reset resets the bloom to its zero value, retaining the capacity of Filter.
+func (b *Bloom) Reset() {
+	b.Path = ""
+	b.Section = 0
+	b.ColumnName = ""
+	b.Filter = b.Filter[:0]
+}
+
+// Builder builds a blooms section.
+type Builder struct {
+	metrics     *Metrics
+	pageSize    int
+	rleEncoding bool
+
+	blooms []*Bloom
+}
+
+// NewBuilder creates a new blooms section builder. The pageSize argument
+// specifies how large pages should be. If rleEncoding is true, column names
+// are run-length encoded, which requires readers that support run-length
+// encoding.
+func NewBuilder(metrics *Metrics, pageSize int, rleEncoding bool) *Builder {
+	if metrics == nil {
+		metrics = NewMetrics()
+	}
+	return &Builder{
+		metrics:     metrics,
+		pageSize:    pageSize,
+		rleEncoding: rleEncoding,
+		blooms:      make([]*Bloom, 0, 1024),
+	}
+}
+
+// Type returns the [dataobj.SectionType] of the blooms builder.
+func (b *Builder) Type() dataobj.SectionType { return sectionType }
+
+// Append adds a bloom filter of the values of the column named columnName in
+// the section of the data object at path.
+func (b *Builder) Append(path string, section int64, columnName string, filter []byte) {
+	b.blooms = append(b.blooms, &Bloom{
+		Path:       path,
+		Section:    section,
+		ColumnName: columnName,
+		Filter:     filter,
+	})
+	b.metrics.recordsTotal.Inc()
+}
+
+// EstimatedSize returns the estimated size of the blooms section in bytes.
+func (b *Builder) EstimatedSize() int {
+	// Bloom filters are stored uncompressed and make up almost all of the
+	// section, so their size is a good estimate. Paths and column names are
+	// assumed to compress to 10 bytes per row.
+	var sizeEstimate int
+	for _, bloom := range b.blooms {
+		sizeEstimate += len(bloom.Filter) + 10
+	}
+	return sizeEstimate
+}
+
+// Flush flushes the blooms section to the provided writer.
+//
+// After successful encoding, b is reset to a fresh state and can be reused.
+func (b *Builder) Flush(w dataobj.SectionWriter) (n int64, err error) {
+	timer := prometheus.NewTimer(b.metrics.encodeSeconds)
+	defer timer.ObserveDuration()
+
+	b.sortBlooms()
+
+	var enc encoder
+	defer enc.Reset()
+	if err := b.encodeTo(&enc); err != nil {
+		return 0, fmt.Errorf("building encoder: %w", err)
+	}
+
+	n, err = enc.Flush(w)
+	if err == nil {
+		b.Reset()
+	}
+	return n, err
+}
+
+// sortBlooms sorts the blooms by column name so that reading the blooms of a
+// single column can skip most pages, and then by their section.
+func (b *Builder) sortBlooms() {
+	sort.Slice(b.blooms, func(i, j int) bool {
+		switch {
+		case b.blooms[i].ColumnName != b.blooms[j].ColumnName:
+			return b.blooms[i].ColumnName < b.blooms[j].ColumnName
+		case b.blooms[i].Path != b.blooms[j].Path:
+			return b.blooms[i].Path < b.blooms[j].Path
+		default:
+			return b.blooms[i].Section < b.blooms[j].Section
+		}
+	})
+}
+
+// Reset resets all state, allowing the Builder to be reused.
+func (b *Builder) Reset() {
+	b.blooms = sliceclear.Clear(b.blooms)
+}
+
+func (b *Builder) encodeTo(enc *encoder) error {
+	pathBuilder, err := dataset.NewColumnBuilder("path", dataset.BuilderOptions{
+		PageSizeHint: b.pageSize,
+		Value:        datasetmd.VALUE_TYPE_BYTE_ARRAY,
+		Encoding:     datasetmd.ENCODING_TYPE_PLAIN,
+		Compression:  datasetmd.COMPRESSION_TYPE_ZSTD,
+		Statistics: dataset.StatisticsOptions{
+			StoreRangeStats: true,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("creating path column: %w", err)
+	}
+
+	sectionBuilder, err := dataset.NewColumnBuilder("section", dataset.BuilderOptions{
+		PageSizeHint: b.pageSize,
+		Value:        datasetmd.VALUE_TYPE_INT64,
+		Encoding:     datasetmd.ENCODING_TYPE_DELTA,
+		Compression:  datasetmd.COMPRESSION_TYPE_NONE,
+		Statistics: dataset.StatisticsOptions{
+			StoreRangeStats: true,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("creating section column: %w", err)
+	}
+
+	// Blooms are sorted by column name, so names repeat in long runs.
+	columnNameEncoding := datasetmd.ENCODING_TYPE_PLAIN
+	if b.rleEncoding {
+		columnNameEncoding = datasetmd.ENCODING_TYPE_RLE
+	}
+
+	columnNameBuilder, err := dataset.NewColumnBuilder("column_name", dataset.BuilderOptions{
+		PageSizeHint: b.pageSize,
+		Value:        datasetmd.VALUE_TYPE_BYTE_ARRAY,
+		Encoding:     columnNameEncoding,
+		Compression:  datasetmd.COMPRESSION_TYPE_ZSTD,
+		Statistics: dataset.StatisticsOptions{
+			StoreRangeStats: true,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("creating column name column: %w", err)
+	}
+
+	bloomFilterBuilder, err := dataset.NewColumnBuilder("bloom_filter", dataset.BuilderOptions{
+		PageSizeHint: b.pageSize,
+		Value:        datasetmd.VALUE_TYPE_BYTE_ARRAY,
+		Encoding:     datasetmd.ENCODING_TYPE_PLAIN,
+		// Bloom filters are close to random data and don't compress well.
+		Compression: datasetmd.COMPRESSION_TYPE_NONE,
+	})
+	if err != nil {
+		return fmt.Errorf("creating bloom filter column: %w", err)
+	}
+
+	for i, bloom := range b.blooms {
+		// Append only fails if the rows are out-of-order, which can't happen here.
+		_ = pathBuilder.Append(i, dataset.ByteArrayValue([]byte(bloom.Path)))
+		_ = sectionBuilder.Append(i, dataset.Int64Value(bloom.Section))
+		_ = columnNameBuilder.Append(i, dataset.ByteArrayValue([]byte(bloom.ColumnName)))
+		_ = bloomFilterBuilder.Append(i, dataset.ByteArrayValue(bloom.Filter))
+	}
+
+	// Encode our builders to sections. We ignore errors after enc.OpenStreams
+	// (which may fail due to a caller) since we guarantee correct usage of the
+	// encoding API.
+	{
+		var errs []error
+		errs = append(errs, encodeColumn(enc, bloomsmd.COLUMN_TYPE_PATH, pathBuilder))
+		errs = append(errs, encodeColumn(enc, bloomsmd.COLUMN_TYPE_SECTION, sectionBuilder))
+		errs = append(errs, encodeColumn(enc, bloomsmd.COLUMN_TYPE_COLUMN_NAME, columnNameBuilder))
+		errs = append(errs, encodeColumn(enc, bloomsmd.COLUMN_TYPE_BLOOM_FILTER, bloomFilterBuilder))
+
+		if err := errors.Join(errs...); err != nil {
+			return fmt.Errorf("encoding columns: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func encodeColumn(enc *encoder, columnType bloomsmd.ColumnType, builder *dataset.ColumnBuilder) error {
+	column, err := builder.Flush()
+	if err != nil {
+		return fmt.Errorf("flushing %s column: %w", columnType, err)
+	}
+
+	columnEnc, err := enc.OpenColumn(columnType, &column.Info)
+	if err != nil {
+		return fmt.Errorf("opening %s column encoder: %w", columnType, err)
+	}
+	defer func() {
+		// Discard on defer for safety. This will return an error if we
+		// successfully committed.
+		_ = columnEnc.Discard()
+	}()
+
+	for _, page := range column.Pages {
+		err := columnEnc.AppendPage(page)
+		if err != nil {
+			return fmt.Errorf("appending %s page: %w", columnType, err)
+		}
+	}
+
+	return columnEnc.Commit()
+}
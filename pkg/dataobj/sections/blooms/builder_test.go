@@ -0,0 +1,42 @@
+package blooms
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/dataobj"
+)
+
+func TestBuilder(t *testing.T) {
+	bb := NewBuilder(nil, 1024, false)
+	bb.Append("foo", 1, "trace_id", []byte{1, 2, 3})
+	bb.Append("foo", 0, "trace_id", []byte{4, 5, 6})
+	bb.Append("bar", 0, "request_id", []byte{7, 8, 9})
+
+	var buf bytes.Buffer
+	b := dataobj.NewBuilder()
+	require.NoError(t, b.Append(bb))
+	_, err := b.Flush(&buf)
+	require.NoError(t, err)
+
+	obj, err := dataobj.FromReaderAt(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+
+	var actual []Bloom
+	for result := range Iter(context.Background(), obj) {
+		bloom, err := result.Value()
+		require.NoError(t, err)
+		actual = append(actual, bloom)
+	}
+
+	// Blooms are sorted by column name, path and section.
+	expect := []Bloom{
+		{Path: "bar", Section: 0, ColumnName: "request_id", Filter: []byte{7, 8, 9}},
+		{Path: "foo", Section: 0, ColumnName: "trace_id", Filter: []byte{4, 5, 6}},
+		{Path: "foo", Section: 1, ColumnName: "trace_id", Filter: []byte{1, 2, 3}},
+	}
+	require.Equal(t, expect, actual)
+}
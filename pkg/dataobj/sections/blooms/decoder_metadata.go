@@ -0,0 +1,34 @@
+package blooms
+
+import (
+	"fmt"
+
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/metadata/bloomsmd"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/streamio"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/util/protocodec"
+)
+
+// decodeBloomsMetadata decodes blooms section metadata from r.
+func decodeBloomsMetadata(r streamio.Reader) (*bloomsmd.Metadata, error) {
+	gotVersion, err := streamio.ReadUvarint(r)
+	if err != nil {
+		return nil, fmt.Errorf("read blooms section format version: %w", err)
+	} else if gotVersion != bloomsFormatVersion {
+		return nil, fmt.Errorf("unexpected blooms section format version: got=%d want=%d", gotVersion, bloomsFormatVersion)
+	}
+
+	var md bloomsmd.Metadata
+	if err := protocodec.Decode(r, &md); err != nil {
+		return nil, fmt.Errorf("blooms section metadata: %w", err)
+	}
+	return &md, nil
+}
+
+// decodeBloomsColumnMetadata decodes blooms column metadata from r.
+func decodeBloomsColumnMetadata(r streamio.Reader) (*bloomsmd.ColumnMetadata, error) {
+	var metadata bloomsmd.ColumnMetadata
+	if err := protocodec.Decode(r, &metadata); err != nil {
+		return nil, fmt.Errorf("blooms column metadata: %w", err)
+	}
+	return &metadata, nil
+}
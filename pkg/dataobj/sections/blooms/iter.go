@@ -0,0 +1,130 @@
+package blooms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"unsafe"
+
+	"github.com/grafana/loki/v3/pkg/dataobj"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/dataset"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/metadata/bloomsmd"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/metadata/datasetmd"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/result"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/util/slicegrow"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/util/symbolizer"
+)
+
+// Iter iterates over blooms in the provided decoder. All blooms sections are
+// iterated over in order.
+func Iter(ctx context.Context, obj *dataobj.Object) result.Seq[Bloom] {
+	return result.Iter(func(yield func(Bloom) bool) error {
+		for i, section := range obj.Sections().Filter(CheckSection) {
+			bloomsSection, err := Open(ctx, section)
+			if err != nil {
+				return fmt.Errorf("opening section %d: %w", i, err)
+			}
+
+			for result := range IterSection(ctx, bloomsSection) {
+				if result.Err() != nil || !yield(result.MustValue()) {
+					return result.Err()
+				}
+			}
+		}
+
+		return nil
+	})
+}
+
+// IterSection iterates over the blooms in the provided section.
+func IterSection(ctx context.Context, section *Section) result.Seq[Bloom] {
+	return result.Iter(func(yield func(Bloom) bool) error {
+		r := NewRowReader(section)
+		defer r.Close()
+
+		var rows [1]Bloom
+		for {
+			// Filters are reused by Read, so rows are cleared to give each
+			// yielded bloom its own filter.
+			rows[0] = Bloom{}
+
+			n, err := r.Read(ctx, rows[:])
+			if err != nil && !errors.Is(err, io.EOF) {
+				return err
+			} else if n == 0 && errors.Is(err, io.EOF) {
+				return nil
+			}
+
+			for _, row := range rows[:n] {
+				if !yield(row) {
+					return nil
+				}
+			}
+		}
+	})
+}
+
+// decodeRow decodes a bloom from a [dataset.Row], using the provided columns
+// to determine the column type. The list of columns must match the columns
+// used to create the row.
+//
+// The sym argument is used for reusing paths and column names between calls
+// to decodeRow. If sym is nil, strings are always allocated. The filter of
+// bloom is reused between calls.
+func decodeRow(columns []*bloomsmd.ColumnDesc, row dataset.Row, bloom *Bloom, sym *symbolizer.Symbolizer) error {
+	bloom.Reset()
+
+	for columnIndex, columnValue := range row.Values {
+		if columnValue.IsNil() || columnValue.IsZero() {
+			continue
+		}
+
+		column := columns[columnIndex]
+		switch column.Type {
+		case bloomsmd.COLUMN_TYPE_PATH:
+			if ty := columnValue.Type(); ty != datasetmd.VALUE_TYPE_BYTE_ARRAY {
+				return fmt.Errorf("invalid type %s for %s", ty, column.Type)
+			}
+			if sym != nil {
+				bloom.Path = sym.Get(unsafeString(columnValue.ByteArray()))
+			} else {
+				bloom.Path = string(columnValue.ByteArray())
+			}
+
+		case bloomsmd.COLUMN_TYPE_SECTION:
+			if ty := columnValue.Type(); ty != datasetmd.VALUE_TYPE_INT64 {
+				return fmt.Errorf("invalid type %s for %s", ty, column.Type)
+			}
+			bloom.Section = columnValue.Int64()
+
+		case bloomsmd.COLUMN_TYPE_COLUMN_NAME:
+			if ty := columnValue.Type(); ty != datasetmd.VALUE_TYPE_BYTE_ARRAY {
+				return fmt.Errorf("invalid type %s for %s", ty, column.Type)
+			}
+			if sym != nil {
+				bloom.ColumnName = sym.Get(unsafeString(columnValue.ByteArray()))
+			} else {
+				bloom.ColumnName = string(columnValue.ByteArray())
+			}
+
+		case bloomsmd.COLUMN_TYPE_BLOOM_FILTER:
+			if ty := columnValue.Type(); ty != datasetmd.VALUE_TYPE_BYTE_ARRAY {
+				return fmt.Errorf("invalid type %s for %s", ty, column.Type)
+			}
+			filterBytes := columnValue.ByteArray()
+			bloom.Filter = slicegrow.GrowToCap(bloom.Filter, len(filterBytes))
+			bloom.Filter = bloom.Filter[:len(filterBytes)]
+			copy(bloom.Filter, filterBytes)
+
+		default:
+			// Unknown columns are skipped for forward compatibility.
+		}
+	}
+
+	return nil
+}
+
+func unsafeString(data []byte) string {
+	return unsafe.String(unsafe.SliceData(data), len(data))
+}
@@ -0,0 +1,15 @@
+package blooms
+
+type (
+	// RowPredicate is an expression used to filter rows in a data object.
+	RowPredicate interface{ isRowPredicate() }
+)
+
+// Supported predicates.
+type (
+	// A ColumnNameRowPredicate is a RowPredicate which requires the name of
+	// the column of a bloom filter to be one of Names.
+	ColumnNameRowPredicate struct{ Names []string }
+)
+
+func (ColumnNameRowPredicate) isRowPredicate() {}
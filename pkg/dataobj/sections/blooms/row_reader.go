@@ -0,0 +1,199 @@
+package blooms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/dataset"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/metadata/bloomsmd"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/util/slicegrow"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/util/symbolizer"
+)
+
+// RowReader is a reader for blooms in a data object.
+type RowReader struct {
+	sec   *Section
+	ready bool
+
+	predicate RowPredicate
+
+	buf []dataset.Row
+
+	reader     *dataset.Reader
+	columns    []dataset.Column
+	columnDesc []*bloomsmd.ColumnDesc
+
+	symbols *symbolizer.Symbolizer
+}
+
+// NewRowReader creates a new RowReader for the given section.
+func NewRowReader(sec *Section) *RowReader {
+	var r RowReader
+	r.Reset(sec)
+	return &r
+}
+
+// SetPredicate sets the predicate to use for filtering blooms. [RowReader.Read]
+// will only return blooms for which the predicate passes.
+//
+// A predicate may only be set before reading begins or after a call to
+// [RowReader.Reset].
+func (r *RowReader) SetPredicate(p RowPredicate) error {
+	if r.ready {
+		return fmt.Errorf("cannot change predicate after reading has started")
+	}
+
+	r.predicate = p
+	return nil
+}
+
+// Read reads up to the next len(s) blooms from the reader and stores them into
+// s. It returns the number of blooms read and any error encountered. At the
+// end of the blooms section, Read returns 0, io.EOF.
+//
+// The filters of the blooms in s are reused to hold the filters read.
+func (r *RowReader) Read(ctx context.Context, s []Bloom) (int, error) {
+	if r.sec == nil {
+		return 0, io.EOF
+	}
+
+	if !r.ready {
+		err := r.initReader(ctx)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	r.buf = slicegrow.GrowToCap(r.buf, len(s))
+	r.buf = r.buf[:len(s)]
+	n, err := r.reader.Read(ctx, r.buf)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return 0, fmt.Errorf("reading rows: %w", err)
+	} else if n == 0 && errors.Is(err, io.EOF) {
+		return 0, io.EOF
+	}
+
+	for i := range r.buf[:n] {
+		if err := decodeRow(r.columnDesc, r.buf[i], &s[i], r.symbols); err != nil {
+			return i, fmt.Errorf("decoding bloom: %w", err)
+		}
+	}
+
+	return n, nil
+}
+
+func (r *RowReader) initReader(ctx context.Context) error {
+	dec := newDecoder(r.sec.reader)
+
+	columnDescs, err := dec.Columns(ctx)
+	if err != nil {
+		return fmt.Errorf("reading columns: %w", err)
+	}
+
+	dset, err := newColumnsDataset(r.sec.Columns())
+	if err != nil {
+		return fmt.Errorf("creating section dataset: %w", err)
+	}
+	columns := dset.Columns()
+
+	var predicates []dataset.Predicate
+	if p := translateBloomsPredicate(r.predicate, columns, columnDescs); p != nil {
+		predicates = append(predicates, p)
+	}
+
+	readerOpts := dataset.ReaderOptions{
+		Dataset:    dset,
+		Columns:    columns,
+		Predicates: predicates,
+
+		TargetCacheSize: 16_000_000, // Permit up to 16MB of cache pages.
+	}
+
+	if r.reader == nil {
+		r.reader = dataset.NewReader(readerOpts)
+	} else {
+		r.reader.Reset(readerOpts)
+	}
+
+	if r.symbols == nil {
+		r.symbols = symbolizer.New(128, 100_000)
+	} else {
+		r.symbols.Reset()
+	}
+
+	r.columnDesc = columnDescs
+	r.columns = columns
+	r.ready = true
+	return nil
+}
+
+// Reset resets the RowReader with a new section to read from. Reset allows
+// reusing a RowReader without allocating a new one.
+//
+// Any set predicate is cleared when Reset is called.
+//
+// Reset may be called with a nil section to clear the RowReader without
+// needing a new section.
+func (r *RowReader) Reset(sec *Section) {
+	r.sec = sec
+	r.predicate = nil
+	r.ready = false
+	r.columns = nil
+	r.columnDesc = nil
+
+	if r.symbols != nil {
+		r.symbols.Reset()
+	}
+}
+
+// Close closes the RowReader and releases any resources it holds. Closed
+// RowReaders can be reused by calling [RowReader.Reset].
+func (r *RowReader) Close() error {
+	if r.reader != nil {
+		return r.reader.Close()
+	}
+	return nil
+}
+
+func translateBloomsPredicate(p RowPredicate, columns []dataset.Column, columnDescs []*bloomsmd.ColumnDesc) dataset.Predicate {
+	if p == nil {
+		return nil
+	}
+
+	var nameColumn dataset.Column
+	for i, desc := range columnDescs {
+		if desc.Type == bloomsmd.COLUMN_TYPE_COLUMN_NAME {
+			nameColumn = columns[i]
+		}
+	}
+
+	switch p := p.(type) {
+	case ColumnNameRowPredicate:
+		return convertColumnNamePredicate(p, nameColumn)
+
+	default:
+		panic(fmt.Sprintf("unsupported predicate type %T", p))
+	}
+}
+
+func convertColumnNamePredicate(p ColumnNameRowPredicate, nameColumn dataset.Column) dataset.Predicate {
+	if nameColumn == nil || len(p.Names) == 0 {
+		return dataset.FalsePredicate{}
+	}
+
+	var result dataset.Predicate
+	for _, name := range p.Names {
+		equal := dataset.EqualPredicate{
+			Column: nameColumn,
+			Value:  dataset.ByteArrayValue([]byte(name)),
+		}
+		if result == nil {
+			result = equal
+		} else {
+			result = dataset.OrPredicate{Left: result, Right: equal}
+		}
+	}
+	return result
+}
@@ -0,0 +1,87 @@
+package blooms
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/dataobj"
+)
+
+var bloomTestData = []Bloom{
+	{Path: "/path/to/object/1", Section: 0, ColumnName: "request_id", Filter: []byte("request-1-0")},
+	{Path: "/path/to/object/1", Section: 0, ColumnName: "trace_id", Filter: []byte("trace-1-0")},
+	{Path: "/path/to/object/1", Section: 1, ColumnName: "trace_id", Filter: []byte("trace-1-1")},
+	{Path: "/path/to/object/2", Section: 0, ColumnName: "user_id", Filter: []byte("user-2-0")},
+}
+
+func TestRowReader(t *testing.T) {
+	sec := buildBloomsSection(t, 10) // Many pages
+	r := NewRowReader(sec)
+	actual, err := readAllBlooms(context.Background(), r)
+	require.NoError(t, err)
+	require.Equal(t, bloomTestData, actual)
+}
+
+func TestRowReader_ColumnNamePredicate(t *testing.T) {
+	sec := buildBloomsSection(t, 10)
+
+	r := NewRowReader(sec)
+	require.NoError(t, r.SetPredicate(ColumnNameRowPredicate{Names: []string{"trace_id", "user_id"}}))
+	actual, err := readAllBlooms(context.Background(), r)
+	require.NoError(t, err)
+	require.Equal(t, []Bloom{bloomTestData[1], bloomTestData[2], bloomTestData[3]}, actual)
+
+	r.Reset(sec)
+	require.NoError(t, r.SetPredicate(ColumnNameRowPredicate{Names: []string{"span_id"}}))
+	actual, err = readAllBlooms(context.Background(), r)
+	require.NoError(t, err)
+	require.Empty(t, actual)
+}
+
+func buildBloomsSection(t *testing.T, pageSize int) *Section {
+	t.Helper()
+
+	s := NewBuilder(nil, pageSize, false)
+	for _, d := range bloomTestData {
+		s.Append(d.Path, d.Section, d.ColumnName, d.Filter)
+	}
+
+	var buf bytes.Buffer
+
+	builder := dataobj.NewBuilder()
+	require.NoError(t, builder.Append(s))
+
+	_, err := builder.Flush(&buf)
+	require.NoError(t, err)
+
+	obj, err := dataobj.FromReaderAt(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+
+	sec, err := Open(t.Context(), obj.Sections()[0])
+	require.NoError(t, err)
+	return sec
+}
+
+func readAllBlooms(ctx context.Context, r *RowReader) ([]Bloom, error) {
+	var res []Bloom
+
+	for {
+		// Filters are reused between reads, so a fresh buffer is used for each
+		// read.
+		buf := make([]Bloom, 2)
+		n, err := r.Read(ctx, buf)
+		if n > 0 {
+			res = append(res, buf[:n]...)
+		}
+		if errors.Is(err, io.EOF) {
+			return res, nil
+		} else if err != nil {
+			return res, err
+		}
+	}
+}
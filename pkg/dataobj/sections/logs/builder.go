@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/klauspost/compress/zstd"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/prometheus/model/labels"
 
@@ -39,6 +38,15 @@ type BuilderOptions struct {
 	// increase time spent merging. Higher values of StripeMergeLimit increase
 	// memory overhead but reduce time spent merging.
 	StripeMergeLimit int
+
+	// Compression configures the compression of each column type of the
+	// section. The zero value uses [DefaultCompressionOptions].
+	Compression CompressionOptions
+
+	// DictionaryEncoding enables dictionary encoding of structured metadata
+	// columns. Data objects written with DictionaryEncoding can only be read
+	// by readers that support dictionary encoding.
+	DictionaryEncoding bool
 }
 
 // Builder accumulate a set of [Record]s within a data object.
@@ -82,6 +90,9 @@ func NewBuilder(metrics *Metrics, opts BuilderOptions) *Builder {
 	return &Builder{
 		metrics: metrics,
 		opts:    opts,
+
+		stripeBuffer:  tableBuffer{dictionaryEncoding: opts.DictionaryEncoding},
+		sectionBuffer: tableBuffer{dictionaryEncoding: opts.DictionaryEncoding},
 	}
 }
 
@@ -123,11 +134,9 @@ func (b *Builder) flushRecords() {
 	// Our stripes are intermediate tables that don't need to have the best
 	// compression. To maintain high throughput on appends, we use the fastest
 	// compression for a stripe. Better compression is then used for sections.
-	compressionOpts := dataset.CompressionOptions{
-		Zstd: []zstd.EOption{zstd.WithEncoderLevel(zstd.SpeedFastest)},
-	}
+	compression := b.opts.Compression.resolve(true)
 
-	stripe := buildTable(&b.stripeBuffer, b.opts.PageSizeHint, compressionOpts, b.records)
+	stripe := buildTable(&b.stripeBuffer, b.opts.PageSizeHint, compression, b.records)
 	b.stripes = append(b.stripes, stripe)
 	b.stripesUncompressedSize += stripe.UncompressedSize()
 	b.stripesCompressedSize += stripe.CompressedSize()
@@ -141,11 +150,9 @@ func (b *Builder) flushSection() *table {
 		return nil
 	}
 
-	compressionOpts := dataset.CompressionOptions{
-		Zstd: []zstd.EOption{zstd.WithEncoderLevel(zstd.SpeedDefault)},
-	}
+	compression := b.opts.Compression.resolve(false)
 
-	section, err := mergeTablesIncremental(&b.sectionBuffer, b.opts.PageSizeHint, compressionOpts, b.stripes, b.opts.StripeMergeLimit)
+	section, err := mergeTablesIncremental(&b.sectionBuffer, b.opts.PageSizeHint, compression, b.stripes, b.opts.StripeMergeLimit)
 	if err != nil {
 		// We control the input to mergeTables, so this should never happen.
 		panic(fmt.Sprintf("merging tables: %v", err))
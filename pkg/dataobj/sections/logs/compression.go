@@ -0,0 +1,169 @@
+package logs
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4/v4"
+
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/dataset"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/metadata/datasetmd"
+)
+
+// Codec is a compression codec used for columns of the logs section.
+type Codec string
+
+// Supported codecs.
+const (
+	CodecNone   Codec = "none"
+	CodecSnappy Codec = "snappy"
+	CodecLZ4    Codec = "lz4"
+	CodecZstd   Codec = "zstd"
+)
+
+// SupportedCodecs lists all codecs which can be used for columns of the logs
+// section.
+var SupportedCodecs = []Codec{CodecNone, CodecSnappy, CodecLZ4, CodecZstd}
+
+// ColumnCompression configures how a column of the logs section is
+// compressed.
+type ColumnCompression struct {
+	// Codec is the codec to compress the column with. If empty, the default
+	// codec for the column type is used.
+	Codec Codec
+
+	// Level is the compression level of the codec. Zstd supports levels 1 to
+	// 22, following the levels of the reference implementation, and LZ4
+	// supports levels 1 to 9. Other codecs don't support levels.
+	//
+	// If 0, the default level of the codec is used.
+	Level int
+}
+
+// Validate returns an error if c is invalid.
+func (c ColumnCompression) Validate() error {
+	switch c.Codec {
+	case "", CodecNone, CodecSnappy:
+		if c.Level != 0 {
+			return fmt.Errorf("codec %q does not support compression levels", c.Codec)
+		}
+	case CodecLZ4:
+		if c.Level < 0 || c.Level > 9 {
+			return fmt.Errorf("invalid lz4 compression level %d: must be between 1 and 9", c.Level)
+		}
+	case CodecZstd:
+		if c.Level < 0 || c.Level > 22 {
+			return fmt.Errorf("invalid zstd compression level %d: must be between 1 and 22", c.Level)
+		}
+	default:
+		return fmt.Errorf("unsupported codec %q: must be one of %v", c.Codec, SupportedCodecs)
+	}
+	return nil
+}
+
+// CompressionOptions configures the compression of each column type of the
+// logs section. The zero value uses the default compression for each column
+// type.
+type CompressionOptions struct {
+	StreamID  ColumnCompression // Compression of the stream ID column.
+	Timestamp ColumnCompression // Compression of the timestamp column.
+	Metadata  ColumnCompression // Compression of structured metadata columns.
+	Message   ColumnCompression // Compression of the log line column.
+}
+
+// DefaultCompressionOptions returns the default compression of each column
+// type of the logs section.
+//
+// Stream IDs and timestamps are delta-encoded, which leaves little for
+// compression to do, so they are left uncompressed.
+func DefaultCompressionOptions() CompressionOptions {
+	return CompressionOptions{
+		StreamID:  ColumnCompression{Codec: CodecNone},
+		Timestamp: ColumnCompression{Codec: CodecNone},
+		Metadata:  ColumnCompression{Codec: CodecZstd},
+		Message:   ColumnCompression{Codec: CodecZstd},
+	}
+}
+
+// Validate returns an error if any column compression of opts is invalid.
+func (opts CompressionOptions) Validate() error {
+	var errs []error
+	if err := opts.StreamID.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("stream ID: %w", err))
+	}
+	if err := opts.Timestamp.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("timestamp: %w", err))
+	}
+	if err := opts.Metadata.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("metadata: %w", err))
+	}
+	if err := opts.Message.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("message: %w", err))
+	}
+	return errors.Join(errs...)
+}
+
+// tableCompression holds the resolved compression of each column of a table.
+type tableCompression struct {
+	StreamID  columnCompression
+	Timestamp columnCompression
+	Metadata  columnCompression
+	Message   columnCompression
+}
+
+// columnCompression holds the resolved compression of a column.
+type columnCompression struct {
+	Type    datasetmd.CompressionType
+	Options dataset.CompressionOptions
+}
+
+// resolve resolves opts into the compression to use for tables. If fastest is
+// true, codecs are configured for their fastest level regardless of the
+// configured level.
+func (opts CompressionOptions) resolve(fastest bool) tableCompression {
+	defaults := DefaultCompressionOptions()
+
+	return tableCompression{
+		StreamID:  opts.StreamID.resolve(defaults.StreamID, fastest),
+		Timestamp: opts.Timestamp.resolve(defaults.Timestamp, fastest),
+		Metadata:  opts.Metadata.resolve(defaults.Metadata, fastest),
+		Message:   opts.Message.resolve(defaults.Message, fastest),
+	}
+}
+
+func (c ColumnCompression) resolve(defaults ColumnCompression, fastest bool) columnCompression {
+	if c.Codec == "" {
+		c = defaults
+	}
+
+	switch c.Codec {
+	case CodecSnappy:
+		return columnCompression{Type: datasetmd.COMPRESSION_TYPE_SNAPPY}
+
+	case CodecLZ4:
+		level := lz4.Fast
+		if c.Level > 0 && !fastest {
+			level = lz4.CompressionLevel(1 << (8 + c.Level))
+		}
+		return columnCompression{
+			Type:    datasetmd.COMPRESSION_TYPE_LZ4,
+			Options: dataset.CompressionOptions{LZ4: []lz4.Option{lz4.CompressionLevelOption(level)}},
+		}
+
+	case CodecZstd:
+		level := zstd.SpeedDefault
+		if fastest {
+			level = zstd.SpeedFastest
+		} else if c.Level > 0 {
+			level = zstd.EncoderLevelFromZstd(c.Level)
+		}
+		return columnCompression{
+			Type:    datasetmd.COMPRESSION_TYPE_ZSTD,
+			Options: dataset.CompressionOptions{Zstd: []zstd.EOption{zstd.WithEncoderLevel(level)}},
+		}
+
+	default:
+		return columnCompression{Type: datasetmd.COMPRESSION_TYPE_NONE}
+	}
+}
@@ -0,0 +1,128 @@
+package logs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/dataset"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/metadata/datasetmd"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/result"
+)
+
+func TestCompressionOptions_Validate(t *testing.T) {
+	tt := []struct {
+		name   string
+		opts   CompressionOptions
+		expect string
+	}{
+		{name: "zero value", opts: CompressionOptions{}},
+		{name: "defaults", opts: DefaultCompressionOptions()},
+		{
+			name: "valid levels",
+			opts: CompressionOptions{
+				Metadata: ColumnCompression{Codec: CodecLZ4, Level: 9},
+				Message:  ColumnCompression{Codec: CodecZstd, Level: 19},
+			},
+		},
+		{
+			name:   "unknown codec",
+			opts:   CompressionOptions{Message: ColumnCompression{Codec: "gzip"}},
+			expect: `message: unsupported codec "gzip"`,
+		},
+		{
+			name:   "level not supported",
+			opts:   CompressionOptions{Timestamp: ColumnCompression{Codec: CodecSnappy, Level: 1}},
+			expect: `timestamp: codec "snappy" does not support compression levels`,
+		},
+		{
+			name:   "invalid zstd level",
+			opts:   CompressionOptions{Message: ColumnCompression{Codec: CodecZstd, Level: 23}},
+			expect: "message: invalid zstd compression level 23",
+		},
+		{
+			name:   "invalid lz4 level",
+			opts:   CompressionOptions{StreamID: ColumnCompression{Codec: CodecLZ4, Level: 10}},
+			expect: "stream ID: invalid lz4 compression level 10",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.opts.Validate()
+			if tc.expect == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, tc.expect)
+		})
+	}
+}
+
+func TestCompressionOptions_resolve(t *testing.T) {
+	// Unset codecs use the default codec of the column type.
+	compression := CompressionOptions{
+		Message: ColumnCompression{Codec: CodecLZ4, Level: 9},
+	}.resolve(false)
+
+	require.Equal(t, datasetmd.COMPRESSION_TYPE_NONE, compression.StreamID.Type)
+	require.Equal(t, datasetmd.COMPRESSION_TYPE_NONE, compression.Timestamp.Type)
+	require.Equal(t, datasetmd.COMPRESSION_TYPE_ZSTD, compression.Metadata.Type)
+	require.Equal(t, datasetmd.COMPRESSION_TYPE_LZ4, compression.Message.Type)
+	require.Len(t, compression.Message.Options.LZ4, 1)
+}
+
+func Test_buildTable_compression(t *testing.T) {
+	var buf tableBuffer
+
+	compression := CompressionOptions{
+		StreamID:  ColumnCompression{Codec: CodecSnappy},
+		Timestamp: ColumnCompression{Codec: CodecLZ4},
+		Metadata:  ColumnCompression{Codec: CodecZstd, Level: 19},
+		Message:   ColumnCompression{Codec: CodecLZ4, Level: 9},
+	}.resolve(false)
+
+	table := buildTable(&buf, 1024, compression, []Record{
+		{StreamID: 1, Timestamp: time.Unix(2, 0), Metadata: labels.FromStrings("level", "info"), Line: []byte("hello")},
+		{StreamID: 2, Timestamp: time.Unix(1, 0), Metadata: labels.FromStrings("level", "warn"), Line: []byte("world")},
+	})
+
+	require.Equal(t, datasetmd.COMPRESSION_TYPE_SNAPPY, table.StreamID.Info.Compression)
+	require.Equal(t, datasetmd.COMPRESSION_TYPE_LZ4, table.Timestamp.Info.Compression)
+	require.Len(t, table.Metadatas, 1)
+	require.Equal(t, datasetmd.COMPRESSION_TYPE_ZSTD, table.Metadatas[0].Info.Compression)
+	require.Equal(t, datasetmd.COMPRESSION_TYPE_LZ4, table.Message.Info.Compression)
+
+	columns, err := result.Collect(table.ListColumns(context.Background()))
+	require.NoError(t, err)
+
+	r := dataset.NewReader(dataset.ReaderOptions{
+		Dataset: table,
+		Columns: columns,
+	})
+	defer r.Close()
+
+	var actual []string
+
+	rows := make([]dataset.Row, 1024)
+	for {
+		n, err := r.Read(context.Background(), rows)
+		if err != nil && !errors.Is(err, io.EOF) {
+			require.NoError(t, err)
+		} else if n == 0 && errors.Is(err, io.EOF) {
+			break
+		}
+
+		for _, row := range rows[:n] {
+			require.Len(t, row.Values, 4)
+			actual = append(actual, string(row.Values[2].ByteArray())+" "+string(row.Values[3].ByteArray()))
+		}
+	}
+
+	require.Equal(t, []string{"info hello", "warn world"}, actual)
+}
@@ -41,6 +41,13 @@ type (
 	// key named Key to exist with a value of Value.
 	MetadataMatcherRowPredicate struct{ Key, Value string }
 
+	// A MetadataInRowPredicate is a RowPredicate that requires a metadata key
+	// named Key to exist with a value equal to one of Values.
+	MetadataInRowPredicate struct {
+		Key    string
+		Values []string
+	}
+
 	// A MetadataFilterRowPredicate is a RowPredicate that requires that metadata
 	// with the provided key pass a Keep function.
 	//
@@ -61,5 +68,6 @@ func (OrRowPredicate) isRowPredicate()               {}
 func (NotRowPredicate) isRowPredicate()              {}
 func (TimeRangeRowPredicate) isRowPredicate()        {}
 func (MetadataMatcherRowPredicate) isRowPredicate()  {}
+func (MetadataInRowPredicate) isRowPredicate()       {}
 func (MetadataFilterRowPredicate) isRowPredicate()   {}
 func (LogMessageFilterRowPredicate) isRowPredicate() {}
@@ -23,6 +23,7 @@ type RowReader struct {
 	ready bool
 
 	matchIDs   map[int64]struct{}
+	matchRows  []RowRange
 	predicates []RowPredicate
 
 	buf []dataset.Row
@@ -62,6 +63,30 @@ func (r *RowReader) MatchStreams(ids iter.Seq[int64]) error {
 	return nil
 }
 
+// A RowRange is an inclusive range of rows in a logs section.
+type RowRange struct {
+	Start, End int64
+}
+
+// MatchRows restricts [RowReader.Read] to logs within the provided ranges of
+// rows. Ranges of rows are typically retrieved from an index, so that pages
+// which can't contain matching logs are never read.
+//
+// If MatchRows is called with an empty slice of ranges, no logs are returned.
+//
+// MatchRows may only be called before reading begins or after a call to
+// [RowReader.Reset].
+func (r *RowReader) MatchRows(ranges []RowRange) error {
+	if r.ready {
+		return fmt.Errorf("cannot change matched rows after reading has started")
+	}
+
+	// ranges is copied into a non-nil slice so that an empty set of ranges
+	// still restricts reading.
+	r.matchRows = append(make([]RowRange, 0, len(ranges)), ranges...)
+	return nil
+}
+
 // SetPredicate sets the predicates to use for filtering logs. [RowReader.Read]
 // will only return logs for which the predicate passes.
 //
@@ -153,6 +178,7 @@ func (r *RowReader) initReader(ctx context.Context) error {
 		Dataset:    dset,
 		Columns:    columns,
 		Predicates: orderPredicates(predicates),
+		RowRanges:  datasetRowRanges(r.matchRows),
 
 		TargetCacheSize: 16_000_000, // Permit up to 16MB of cache pages.
 	}
@@ -187,6 +213,7 @@ func (r *RowReader) Reset(sec *Section) {
 	r.ready = false
 
 	clear(r.matchIDs)
+	r.matchRows = nil
 	r.predicates = nil
 
 	r.columns = nil
@@ -209,6 +236,23 @@ func (r *RowReader) Close() error {
 	return nil
 }
 
+// datasetRowRanges converts ranges into [dataset.RowRange]s. If ranges is nil,
+// datasetRowRanges returns nil so that all rows are read.
+func datasetRowRanges(ranges []RowRange) []dataset.RowRange {
+	if ranges == nil {
+		return nil
+	}
+
+	res := make([]dataset.RowRange, 0, len(ranges))
+	for _, rr := range ranges {
+		if rr.End < 0 || rr.End < rr.Start {
+			continue
+		}
+		res = append(res, dataset.RowRange{Start: uint64(max(rr.Start, 0)), End: uint64(rr.End)})
+	}
+	return res
+}
+
 func streamIDPredicate(ids iter.Seq[int64], columns []dataset.Column, columnDesc []*logsmd.ColumnDesc) dataset.Predicate {
 	streamIDColumn := findColumnFromDesc(columns, columnDesc, func(desc *logsmd.ColumnDesc) bool {
 		return desc.Type == logsmd.COLUMN_TYPE_STREAM_ID
@@ -296,6 +340,22 @@ func translateLogsPredicate(p RowPredicate, columns []dataset.Column, columnDesc
 			Value:  dataset.ByteArrayValue(unsafeSlice(p.Value, 0)),
 		}
 
+	case MetadataInRowPredicate:
+		metadataColumn := findColumnFromDesc(columns, columnDesc, func(desc *logsmd.ColumnDesc) bool {
+			return desc.Type == logsmd.COLUMN_TYPE_METADATA && desc.Info.Name == p.Key
+		})
+		if metadataColumn == nil || len(p.Values) == 0 {
+			return dataset.FalsePredicate{}
+		}
+		values := make([]dataset.Value, 0, len(p.Values))
+		for _, value := range p.Values {
+			values = append(values, dataset.ByteArrayValue(unsafeSlice(value, 0)))
+		}
+		return dataset.InPredicate{
+			Column: metadataColumn,
+			Values: dataset.NewByteArrayValueSet(values),
+		}
+
 	case MetadataFilterRowPredicate:
 		metadataColumn := findColumnFromDesc(columns, columnDesc, func(desc *logsmd.ColumnDesc) bool {
 			return desc.Type == logsmd.COLUMN_TYPE_METADATA && desc.Info.Name == p.Key
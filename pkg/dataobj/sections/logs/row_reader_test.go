@@ -3,10 +3,13 @@ package logs
 import (
 	"bytes"
 	"context"
+	"errors"
+	"io"
 	"slices"
 	"testing"
 	"time"
 
+	"github.com/prometheus/prometheus/model/labels"
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/loki/v3/pkg/dataobj"
@@ -35,6 +38,94 @@ func TestRowReader_StreamIDPredicate(t *testing.T) {
 	require.Equal(t, 1, n)
 }
 
+func TestRowReader_MatchRows(t *testing.T) {
+	logsSection := buildSection(t)
+
+	allRecords := make([]Record, 3)
+	n, err := NewRowReader(logsSection).Read(context.Background(), allRecords)
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+
+	readBuf := make([]Record, 3)
+	rowReader := NewRowReader(logsSection)
+	require.NoError(t, rowReader.MatchRows([]RowRange{{Start: 1, End: 1}}))
+	n, err = rowReader.Read(context.Background(), readBuf)
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	require.Equal(t, allRecords[1].Line, readBuf[0].Line)
+
+	rowReader.Reset(logsSection)
+	require.NoError(t, rowReader.MatchRows([]RowRange{}))
+	n, err = rowReader.Read(context.Background(), readBuf)
+	require.ErrorIs(t, err, io.EOF)
+	require.Equal(t, 0, n)
+}
+
+func TestRowReader_MetadataPredicates(t *testing.T) {
+	tests := []struct {
+		name      string
+		predicate RowPredicate
+		expected  []string
+	}{
+		{
+			name:      "equal",
+			predicate: MetadataMatcherRowPredicate{Key: "level", Value: "error"},
+			expected:  []string{"test"},
+		},
+		{
+			name:      "not equal",
+			predicate: NotRowPredicate{Inner: MetadataMatcherRowPredicate{Key: "level", Value: "error"}},
+			expected:  []string{"test2"},
+		},
+		{
+			name:      "in",
+			predicate: MetadataInRowPredicate{Key: "level", Values: []string{"warn", "info"}},
+			expected:  []string{"test2"},
+		},
+		{
+			name:      "in without matches",
+			predicate: MetadataInRowPredicate{Key: "level", Values: []string{"warn", "debug"}},
+			expected:  nil,
+		},
+		{
+			name:      "in on missing key",
+			predicate: MetadataInRowPredicate{Key: "missing", Values: []string{"info"}},
+			expected:  nil,
+		},
+		{
+			name: "or",
+			predicate: OrRowPredicate{
+				Left:  MetadataMatcherRowPredicate{Key: "level", Value: "error"},
+				Right: MetadataInRowPredicate{Key: "level", Values: []string{"info"}},
+			},
+			expected: []string{"test", "test2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logsSection := buildSection(t)
+
+			readBuf := make([]Record, 3)
+			rowReader := NewRowReader(logsSection)
+			require.NoError(t, rowReader.SetPredicates([]RowPredicate{tt.predicate}))
+
+			var lines []string
+			for {
+				n, err := rowReader.Read(context.Background(), readBuf)
+				for _, record := range readBuf[:n] {
+					lines = append(lines, string(record.Line))
+				}
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				require.NoError(t, err)
+			}
+			require.ElementsMatch(t, tt.expected, lines)
+		})
+	}
+}
+
 func buildSection(t *testing.T) *Section {
 	logsBuilder := NewBuilder(nil, BuilderOptions{
 		StripeMergeLimit: 2,
@@ -42,11 +133,13 @@ func buildSection(t *testing.T) *Section {
 	logsBuilder.Append(Record{
 		StreamID:  1,
 		Timestamp: time.Now(),
+		Metadata:  labels.FromStrings("level", "error"),
 		Line:      []byte("test"),
 	})
 	logsBuilder.Append(Record{
 		StreamID:  2,
 		Timestamp: time.Now(),
+		Metadata:  labels.FromStrings("level", "info"),
 		Line:      []byte("test2"),
 	})
 
@@ -121,19 +121,23 @@ type tableBuffer struct {
 	usedMetadatas  map[*dataset.ColumnBuilder]string // metadata with its name.
 
 	message *dataset.ColumnBuilder
+
+	// dictionaryEncoding enables dictionary encoding of metadata columns.
+	dictionaryEncoding bool
 }
 
 // StreamID gets or creates a stream ID column for the buffer.
-func (b *tableBuffer) StreamID(pageSize int) *dataset.ColumnBuilder {
+func (b *tableBuffer) StreamID(pageSize int, compression columnCompression) *dataset.ColumnBuilder {
 	if b.streamID != nil {
 		return b.streamID
 	}
 
 	col, err := dataset.NewColumnBuilder("", dataset.BuilderOptions{
-		PageSizeHint: pageSize,
-		Value:        datasetmd.VALUE_TYPE_INT64,
-		Encoding:     datasetmd.ENCODING_TYPE_DELTA,
-		Compression:  datasetmd.COMPRESSION_TYPE_NONE,
+		PageSizeHint:       pageSize,
+		Value:              datasetmd.VALUE_TYPE_INT64,
+		Encoding:           datasetmd.ENCODING_TYPE_DELTA,
+		Compression:        compression.Type,
+		CompressionOptions: compression.Options,
 		Statistics: dataset.StatisticsOptions{
 			StoreRangeStats:       true,
 			StoreCardinalityStats: true,
@@ -151,16 +155,17 @@ func (b *tableBuffer) StreamID(pageSize int) *dataset.ColumnBuilder {
 }
 
 // Timestamp gets or creates a timestamp column for the buffer.
-func (b *tableBuffer) Timestamp(pageSize int) *dataset.ColumnBuilder {
+func (b *tableBuffer) Timestamp(pageSize int, compression columnCompression) *dataset.ColumnBuilder {
 	if b.timestamp != nil {
 		return b.timestamp
 	}
 
 	col, err := dataset.NewColumnBuilder("", dataset.BuilderOptions{
-		PageSizeHint: pageSize,
-		Value:        datasetmd.VALUE_TYPE_INT64,
-		Encoding:     datasetmd.ENCODING_TYPE_DELTA,
-		Compression:  datasetmd.COMPRESSION_TYPE_NONE,
+		PageSizeHint:       pageSize,
+		Value:              datasetmd.VALUE_TYPE_INT64,
+		Encoding:           datasetmd.ENCODING_TYPE_DELTA,
+		Compression:        compression.Type,
+		CompressionOptions: compression.Options,
 		Statistics: dataset.StatisticsOptions{
 			StoreRangeStats: true,
 		},
@@ -178,7 +183,7 @@ func (b *tableBuffer) Timestamp(pageSize int) *dataset.ColumnBuilder {
 
 // Metadata gets or creates a metadata column for the buffer. To remove created
 // metadata columns, call [tableBuffer.CleanupMetadatas].
-func (b *tableBuffer) Metadata(key string, pageSize int, compressionOpts dataset.CompressionOptions) *dataset.ColumnBuilder {
+func (b *tableBuffer) Metadata(key string, pageSize int, compression columnCompression) *dataset.ColumnBuilder {
 	if b.usedMetadatas == nil {
 		b.usedMetadatas = make(map[*dataset.ColumnBuilder]string)
 	}
@@ -190,12 +195,20 @@ func (b *tableBuffer) Metadata(key string, pageSize int, compressionOpts dataset
 		return builder
 	}
 
+	// Metadata columns tend to have a low cardinality, so dictionary encoding
+	// makes them smaller and permits skipping pages by their dictionary. It is
+	// opt-in, as older readers can't decode dictionary encoded pages.
+	encoding := datasetmd.ENCODING_TYPE_PLAIN
+	if b.dictionaryEncoding {
+		encoding = datasetmd.ENCODING_TYPE_DICTIONARY
+	}
+
 	col, err := dataset.NewColumnBuilder(key, dataset.BuilderOptions{
 		PageSizeHint:       pageSize,
 		Value:              datasetmd.VALUE_TYPE_BYTE_ARRAY,
-		Encoding:           datasetmd.ENCODING_TYPE_PLAIN,
-		Compression:        datasetmd.COMPRESSION_TYPE_ZSTD,
-		CompressionOptions: compressionOpts,
+		Encoding:           encoding,
+		Compression:        compression.Type,
+		CompressionOptions: compression.Options,
 		Statistics: dataset.StatisticsOptions{
 			StoreRangeStats:       true,
 			StoreCardinalityStats: true,
@@ -219,7 +232,7 @@ func (b *tableBuffer) Metadata(key string, pageSize int, compressionOpts dataset
 }
 
 // Message gets or creates a message column for the buffer.
-func (b *tableBuffer) Message(pageSize int, compressionOpts dataset.CompressionOptions) *dataset.ColumnBuilder {
+func (b *tableBuffer) Message(pageSize int, compression columnCompression) *dataset.ColumnBuilder {
 	if b.message != nil {
 		return b.message
 	}
@@ -228,8 +241,8 @@ func (b *tableBuffer) Message(pageSize int, compressionOpts dataset.CompressionO
 		PageSizeHint:       pageSize,
 		Value:              datasetmd.VALUE_TYPE_BYTE_ARRAY,
 		Encoding:           datasetmd.ENCODING_TYPE_PLAIN,
-		Compression:        datasetmd.COMPRESSION_TYPE_ZSTD,
-		CompressionOptions: compressionOpts,
+		Compression:        compression.Type,
+		CompressionOptions: compression.Options,
 
 		// We explicitly don't have range stats for the message column:
 		//
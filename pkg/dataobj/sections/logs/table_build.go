@@ -11,15 +11,15 @@ import (
 
 // buildTable builds a table from the set of provided records. The records are
 // sorted with [sortRecords] prior to building the table.
-func buildTable(buf *tableBuffer, pageSize int, compressionOpts dataset.CompressionOptions, records []Record) *table {
+func buildTable(buf *tableBuffer, pageSize int, compression tableCompression, records []Record) *table {
 	sortRecords(records)
 
 	buf.Reset()
 
 	var (
-		streamIDBuilder  = buf.StreamID(pageSize)
-		timestampBuilder = buf.Timestamp(pageSize)
-		messageBuilder   = buf.Message(pageSize, compressionOpts)
+		streamIDBuilder  = buf.StreamID(pageSize, compression.StreamID)
+		timestampBuilder = buf.Timestamp(pageSize, compression.Timestamp)
+		messageBuilder   = buf.Message(pageSize, compression.Message)
 	)
 
 	for i, record := range records {
@@ -34,7 +34,7 @@ func buildTable(buf *tableBuffer, pageSize int, compressionOpts dataset.Compress
 		record.Metadata.Range(func(md labels.Label) {
 			// Passing around md.Value as an unsafe slice is safe here: appending
 			// values is always read-only and the byte slice will never be mutated.
-			metadataBuilder := buf.Metadata(md.Name, pageSize, compressionOpts)
+			metadataBuilder := buf.Metadata(md.Name, pageSize, compression.Metadata)
 			_ = metadataBuilder.Append(i, dataset.ByteArrayValue(unsafeSlice(md.Value, 0)))
 		})
 	}
@@ -19,15 +19,15 @@ import (
 // tables are open at a time.
 //
 // mergeTablesIncremental panics if maxMergeSize is less than 2.
-func mergeTablesIncremental(buf *tableBuffer, pageSize int, compressionOpts dataset.CompressionOptions, tables []*table, maxMergeSize int) (*table, error) {
+func mergeTablesIncremental(buf *tableBuffer, pageSize int, compression tableCompression, tables []*table, maxMergeSize int) (*table, error) {
 	if maxMergeSize < 2 {
 		panic("mergeTablesIncremental: merge size must be at least 2, got " + fmt.Sprint(maxMergeSize))
 	}
 
 	// Even if there's only one table, we still pass to mergeTables to ensure
-	// it's compressed with compressionOpts.
+	// it's compressed with compression.
 	if len(tables) == 1 {
-		return mergeTables(buf, pageSize, compressionOpts, tables)
+		return mergeTables(buf, pageSize, compression, tables)
 	}
 
 	in := tables
@@ -37,7 +37,7 @@ func mergeTablesIncremental(buf *tableBuffer, pageSize int, compressionOpts data
 
 		for i := 0; i < len(in); i += maxMergeSize {
 			set := in[i:min(i+maxMergeSize, len(in))]
-			merged, err := mergeTables(buf, pageSize, compressionOpts, set)
+			merged, err := mergeTables(buf, pageSize, compression, set)
 			if err != nil {
 				return nil, err
 			}
@@ -52,13 +52,13 @@ func mergeTablesIncremental(buf *tableBuffer, pageSize int, compressionOpts data
 
 // mergeTables merges the provided sorted tables into a new single sorted table
 // using k-way merge.
-func mergeTables(buf *tableBuffer, pageSize int, compressionOpts dataset.CompressionOptions, tables []*table) (*table, error) {
+func mergeTables(buf *tableBuffer, pageSize int, compression tableCompression, tables []*table) (*table, error) {
 	buf.Reset()
 
 	var (
-		streamIDBuilder  = buf.StreamID(pageSize)
-		timestampBuilder = buf.Timestamp(pageSize)
-		messageBuilder   = buf.Message(pageSize, compressionOpts)
+		streamIDBuilder  = buf.StreamID(pageSize, compression.StreamID)
+		timestampBuilder = buf.Timestamp(pageSize, compression.Timestamp)
+		messageBuilder   = buf.Message(pageSize, compression.Message)
 	)
 
 	var (
@@ -118,7 +118,7 @@ func mergeTables(buf *tableBuffer, pageSize int, compressionOpts dataset.Compres
 			case logsmd.COLUMN_TYPE_TIMESTAMP:
 				_ = timestampBuilder.Append(rows, value)
 			case logsmd.COLUMN_TYPE_METADATA:
-				columnBuilder := buf.Metadata(column.Info.Name, pageSize, compressionOpts)
+				columnBuilder := buf.Metadata(column.Info.Name, pageSize, compression.Metadata)
 				_ = columnBuilder.Append(rows, value)
 			case logsmd.COLUMN_TYPE_MESSAGE:
 				_ = messageBuilder.Append(rows, value)
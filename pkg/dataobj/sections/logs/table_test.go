@@ -8,6 +8,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/prometheus/prometheus/model/labels"
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/loki/v3/pkg/dataobj/internal/dataset"
@@ -19,15 +20,15 @@ func Test_table_metadataCleanup(t *testing.T) {
 	var buf tableBuffer
 	initBuffer(&buf)
 
-	_ = buf.Metadata("foo", 1024, dataset.CompressionOptions{})
-	_ = buf.Metadata("bar", 1024, dataset.CompressionOptions{})
+	_ = buf.Metadata("foo", 1024, columnCompression{})
+	_ = buf.Metadata("bar", 1024, columnCompression{})
 
 	table, err := buf.Flush()
 	require.NoError(t, err)
 	require.Equal(t, 2, len(table.Metadatas))
 
 	initBuffer(&buf)
-	_ = buf.Metadata("bar", 1024, dataset.CompressionOptions{})
+	_ = buf.Metadata("bar", 1024, columnCompression{})
 
 	table, err = buf.Flush()
 	require.NoError(t, err)
@@ -35,10 +36,36 @@ func Test_table_metadataCleanup(t *testing.T) {
 	require.Equal(t, "bar", table.Metadatas[0].Info.Name)
 }
 
+func Test_table_metadataEncoding(t *testing.T) {
+	tt := []struct {
+		name               string
+		dictionaryEncoding bool
+		expect             datasetmd.EncodingType
+	}{
+		{name: "default", expect: datasetmd.ENCODING_TYPE_PLAIN},
+		{name: "dictionary", dictionaryEncoding: true, expect: datasetmd.ENCODING_TYPE_DICTIONARY},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			buf := tableBuffer{dictionaryEncoding: tc.dictionaryEncoding}
+
+			table := buildTable(&buf, 1024, tableCompression{}, []Record{
+				{StreamID: 1, Timestamp: time.Unix(1, 0), Metadata: labels.FromStrings("level", "info"), Line: []byte("hello")},
+			})
+			require.Len(t, table.Metadatas, 1)
+
+			for _, page := range table.Metadatas[0].Pages {
+				require.Equal(t, tc.expect, page.Info.Encoding)
+			}
+		})
+	}
+}
+
 func initBuffer(buf *tableBuffer) {
-	buf.StreamID(1024)
-	buf.Timestamp(1024)
-	buf.Message(1024, dataset.CompressionOptions{})
+	buf.StreamID(1024, columnCompression{})
+	buf.Timestamp(1024, columnCompression{})
+	buf.Message(1024, columnCompression{})
 }
 
 func Test_mergeTables(t *testing.T) {
@@ -46,24 +73,24 @@ func Test_mergeTables(t *testing.T) {
 
 	// tables need to be sorted by Timestamp DESC and StreamID ASC
 	var (
-		tableA = buildTable(&buf, 1024, dataset.CompressionOptions{}, []Record{
+		tableA = buildTable(&buf, 1024, tableCompression{}, []Record{
 			{StreamID: 3, Timestamp: time.Unix(3, 0), Line: []byte("hello")},
 			{StreamID: 2, Timestamp: time.Unix(2, 0), Line: []byte("how")},
 			{StreamID: 1, Timestamp: time.Unix(1, 0), Line: []byte("you")},
 		})
 
-		tableB = buildTable(&buf, 1024, dataset.CompressionOptions{}, []Record{
+		tableB = buildTable(&buf, 1024, tableCompression{}, []Record{
 			{StreamID: 1, Timestamp: time.Unix(2, 0), Line: []byte("world")},
 			{StreamID: 3, Timestamp: time.Unix(1, 0), Line: []byte("goodbye")},
 		})
 
-		tableC = buildTable(&buf, 1024, dataset.CompressionOptions{}, []Record{
+		tableC = buildTable(&buf, 1024, tableCompression{}, []Record{
 			{StreamID: 3, Timestamp: time.Unix(2, 0), Line: []byte("are")},
 			{StreamID: 2, Timestamp: time.Unix(1, 0), Line: []byte("doing?")},
 		})
 	)
 
-	mergedTable, err := mergeTables(&buf, 1024, dataset.CompressionOptions{}, []*table{tableA, tableB, tableC})
+	mergedTable, err := mergeTables(&buf, 1024, tableCompression{}, []*table{tableA, tableB, tableC})
 	require.NoError(t, err)
 
 	mergedColumns, err := result.Collect(mergedTable.ListColumns(context.Background()))
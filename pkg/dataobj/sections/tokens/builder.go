@@ -0,0 +1,246 @@
+package tokens
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/loki/v3/pkg/dataobj"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/dataset"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/metadata/datasetmd"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/metadata/tokensmd"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/util/sliceclear"
+	"github.com/grafana/loki/v3/pkg/dataobj/sections/logs"
+)
+
+// A Token maps a token of log lines to the rows of a logs section in another
+// data object whose log lines contain the token.
+type Token struct {
+	Path    string // Path of the referenced data object.
+	Section int64  // Index of the logs section in the referenced data object.
+	Token   string // Token of log lines in the referenced section.
+
+	// Rows holds the sorted ranges of rows in the referenced section whose log
+	// lines contain Token.
+	Rows []logs.RowRange
+}
+
+// Reset resets the token to its zero value, retaining the capacity of Rows.
+func (t *Token) Reset() {
+	t.Path = ""
+	t.Section = 0
+	t.Token = ""
+	t.Rows = t.Rows[:0]
+}
+
+// entry is a buffered token, holding encoded row ranges to reduce memory
+// usage.
+type entry struct {
+	path    string
+	section int64
+	token   string
+	rows    []byte
+}
+
+// Builder builds a tokens section.
+type Builder struct {
+	metrics  *Metrics
+	pageSize int
+
+	entries []*entry
+}
+
+// NewBuilder creates a new tokens section builder. The pageSize argument
+// specifies how large pages should be.
+func NewBuilder(metrics *Metrics, pageSize int) *Builder {
+	if metrics == nil {
+		metrics = NewMetrics()
+	}
+	return &Builder{
+		metrics:  metrics,
+		pageSize: pageSize,
+		entries:  make([]*entry, 0, 1024),
+	}
+}
+
+// Type returns the [dataobj.SectionType] of the tokens builder.
+func (b *Builder) Type() dataobj.SectionType { return sectionType }
+
+// Append adds a token of the log lines in the section of the data object at
+// path, along with the sorted ranges of rows which contain it.
+func (b *Builder) Append(path string, section int64, token string, rows []logs.RowRange) {
+	b.entries = append(b.entries, &entry{
+		path:    path,
+		section: section,
+		token:   token,
+		rows:    appendRowRanges(nil, rows),
+	})
+	b.metrics.recordsTotal.Inc()
+}
+
+// AppendIndexer adds all tokens accumulated by ix for the section of the data
+// object at path.
+func (b *Builder) AppendIndexer(path string, section int64, ix *Indexer) {
+	ix.Each(func(token string, rows []logs.RowRange) {
+		b.Append(path, section, token, rows)
+	})
+}
+
+// EstimatedSize returns the estimated size of the tokens section in bytes.
+func (b *Builder) EstimatedSize() int {
+	// Paths and sections repeat heavily and are assumed to compress to 10
+	// bytes per row.
+	var sizeEstimate int
+	for _, e := range b.entries {
+		sizeEstimate += len(e.token) + len(e.rows) + 10
+	}
+	return sizeEstimate
+}
+
+// Flush flushes the tokens section to the provided writer.
+//
+// After successful encoding, b is reset to a fresh state and can be reused.
+func (b *Builder) Flush(w dataobj.SectionWriter) (n int64, err error) {
+	timer := prometheus.NewTimer(b.metrics.encodeSeconds)
+	defer timer.ObserveDuration()
+
+	b.sortEntries()
+
+	var enc encoder
+	defer enc.Reset()
+	if err := b.encodeTo(&enc); err != nil {
+		return 0, fmt.Errorf("building encoder: %w", err)
+	}
+
+	n, err = enc.Flush(w)
+	if err == nil {
+		b.Reset()
+	}
+	return n, err
+}
+
+// sortEntries sorts the entries by token so that looking up a set of tokens
+// can skip most pages, and then by their section.
+func (b *Builder) sortEntries() {
+	sort.Slice(b.entries, func(i, j int) bool {
+		switch {
+		case b.entries[i].token != b.entries[j].token:
+			return b.entries[i].token < b.entries[j].token
+		case b.entries[i].path != b.entries[j].path:
+			return b.entries[i].path < b.entries[j].path
+		default:
+			return b.entries[i].section < b.entries[j].section
+		}
+	})
+}
+
+// Reset resets all state, allowing the Builder to be reused.
+func (b *Builder) Reset() {
+	b.entries = sliceclear.Clear(b.entries)
+}
+
+func (b *Builder) encodeTo(enc *encoder) error {
+	pathBuilder, err := dataset.NewColumnBuilder("path", dataset.BuilderOptions{
+		PageSizeHint: b.pageSize,
+		Value:        datasetmd.VALUE_TYPE_BYTE_ARRAY,
+		Encoding:     datasetmd.ENCODING_TYPE_PLAIN,
+		Compression:  datasetmd.COMPRESSION_TYPE_ZSTD,
+		Statistics: dataset.StatisticsOptions{
+			StoreRangeStats: true,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("creating path column: %w", err)
+	}
+
+	sectionBuilder, err := dataset.NewColumnBuilder("section", dataset.BuilderOptions{
+		PageSizeHint: b.pageSize,
+		Value:        datasetmd.VALUE_TYPE_INT64,
+		Encoding:     datasetmd.ENCODING_TYPE_DELTA,
+		Compression:  datasetmd.COMPRESSION_TYPE_NONE,
+		Statistics: dataset.StatisticsOptions{
+			StoreRangeStats: true,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("creating section column: %w", err)
+	}
+
+	tokenBuilder, err := dataset.NewColumnBuilder("token", dataset.BuilderOptions{
+		PageSizeHint: b.pageSize,
+		Value:        datasetmd.VALUE_TYPE_BYTE_ARRAY,
+		Encoding:     datasetmd.ENCODING_TYPE_PLAIN,
+		Compression:  datasetmd.COMPRESSION_TYPE_ZSTD,
+		Statistics: dataset.StatisticsOptions{
+			// Entries are sorted by token, so range stats allow skipping pages
+			// which can't contain the tokens being looked up.
+			StoreRangeStats: true,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("creating token column: %w", err)
+	}
+
+	rowRangesBuilder, err := dataset.NewColumnBuilder("row_ranges", dataset.BuilderOptions{
+		PageSizeHint: b.pageSize,
+		Value:        datasetmd.VALUE_TYPE_BYTE_ARRAY,
+		Encoding:     datasetmd.ENCODING_TYPE_PLAIN,
+		Compression:  datasetmd.COMPRESSION_TYPE_ZSTD,
+	})
+	if err != nil {
+		return fmt.Errorf("creating row ranges column: %w", err)
+	}
+
+	for i, e := range b.entries {
+		// Append only fails if the rows are out-of-order, which can't happen here.
+		_ = pathBuilder.Append(i, dataset.ByteArrayValue([]byte(e.path)))
+		_ = sectionBuilder.Append(i, dataset.Int64Value(e.section))
+		_ = tokenBuilder.Append(i, dataset.ByteArrayValue([]byte(e.token)))
+		_ = rowRangesBuilder.Append(i, dataset.ByteArrayValue(e.rows))
+	}
+
+	// Encode our builders to sections. We ignore errors after enc.OpenStreams
+	// (which may fail due to a caller) since we guarantee correct usage of the
+	// encoding API.
+	{
+		var errs []error
+		errs = append(errs, encodeColumn(enc, tokensmd.COLUMN_TYPE_PATH, pathBuilder))
+		errs = append(errs, encodeColumn(enc, tokensmd.COLUMN_TYPE_SECTION, sectionBuilder))
+		errs = append(errs, encodeColumn(enc, tokensmd.COLUMN_TYPE_TOKEN, tokenBuilder))
+		errs = append(errs, encodeColumn(enc, tokensmd.COLUMN_TYPE_ROW_RANGES, rowRangesBuilder))
+
+		if err := errors.Join(errs...); err != nil {
+			return fmt.Errorf("encoding columns: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func encodeColumn(enc *encoder, columnType tokensmd.ColumnType, builder *dataset.ColumnBuilder) error {
+	column, err := builder.Flush()
+	if err != nil {
+		return fmt.Errorf("flushing %s column: %w", columnType, err)
+	}
+
+	columnEnc, err := enc.OpenColumn(columnType, &column.Info)
+	if err != nil {
+		return fmt.Errorf("opening %s column encoder: %w", columnType, err)
+	}
+	defer func() {
+		// Discard on defer for safety. This will return an error if we
+		// successfully committed.
+		_ = columnEnc.Discard()
+	}()
+
+	for _, page := range column.Pages {
+		err := columnEnc.AppendPage(page)
+		if err != nil {
+			return fmt.Errorf("appending %s page: %w", columnType, err)
+		}
+	}
+
+	return columnEnc.Commit()
+}
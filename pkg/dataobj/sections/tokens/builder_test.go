@@ -0,0 +1,43 @@
+package tokens
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/dataobj"
+	"github.com/grafana/loki/v3/pkg/dataobj/sections/logs"
+)
+
+func TestBuilder(t *testing.T) {
+	tb := NewBuilder(nil, 1024)
+	tb.Append("foo", 1, "abc", []logs.RowRange{{Start: 0, End: 9}})
+	tb.Append("foo", 0, "abc", []logs.RowRange{{Start: 5, End: 5}, {Start: 100, End: 199}})
+	tb.Append("bar", 0, "xyz", []logs.RowRange{{Start: 1024, End: 2047}})
+
+	var buf bytes.Buffer
+	b := dataobj.NewBuilder()
+	require.NoError(t, b.Append(tb))
+	_, err := b.Flush(&buf)
+	require.NoError(t, err)
+
+	obj, err := dataobj.FromReaderAt(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+
+	var actual []Token
+	for result := range Iter(context.Background(), obj) {
+		token, err := result.Value()
+		require.NoError(t, err)
+		actual = append(actual, token)
+	}
+
+	// Tokens are sorted by token, path and section.
+	expect := []Token{
+		{Path: "foo", Section: 0, Token: "abc", Rows: []logs.RowRange{{Start: 5, End: 5}, {Start: 100, End: 199}}},
+		{Path: "foo", Section: 1, Token: "abc", Rows: []logs.RowRange{{Start: 0, End: 9}}},
+		{Path: "bar", Section: 0, Token: "xyz", Rows: []logs.RowRange{{Start: 1024, End: 2047}}},
+	}
+	require.Equal(t, expect, actual)
+}
@@ -0,0 +1,144 @@
+package tokens
+
+import (
+	"unicode/utf8"
+
+	"github.com/grafana/loki/v3/pkg/dataobj/sections/logs"
+)
+
+// NGramLength is the number of runes in each token of a log line.
+const NGramLength = 3
+
+// indexedToken is the token recorded for every indexed logs section, covering
+// all of its rows. It allows telling apart sections where a token doesn't
+// appear from sections which haven't been indexed. Tokens of log lines are
+// always NGramLength runes long, so indexedToken never collides with them.
+const indexedToken = "*"
+
+// Tokenize calls f for every n-gram of [NGramLength] runes in line, in order.
+// The same token may be passed to f more than once. The token passed to f is
+// only valid for the duration of the call.
+//
+// A log line can only contain a substring if it contains all tokens of the
+// substring.
+func Tokenize(line []byte, f func(token []byte)) {
+	// offsets is a ring buffer of the byte offsets of the last NGramLength
+	// runes.
+	var offsets [NGramLength]int
+
+	var runes int
+	for i := 0; i < len(line); {
+		_, size := utf8.DecodeRune(line[i:])
+		offsets[runes%NGramLength] = i
+		runes++
+		i += size
+
+		if runes >= NGramLength {
+			// After incrementing runes, the oldest offset in the ring buffer is at
+			// runes%NGramLength.
+			f(line[offsets[runes%NGramLength]:i])
+		}
+	}
+}
+
+// An Indexer accumulates the tokens of the log lines of a single logs section.
+// Rows are grouped into blocks, and the Indexer tracks which blocks contain
+// each token. Larger blocks reduce the size of the index at the cost of less
+// precise row ranges.
+type Indexer struct {
+	rowsPerBlock int64
+	rows         int64 // Number of rows in the section.
+
+	tokens map[string]*blockRanges
+}
+
+// NewIndexer creates a new Indexer which groups rows into blocks of
+// rowsPerBlock rows. If rowsPerBlock is less than 1, every row is its own
+// block.
+func NewIndexer(rowsPerBlock int) *Indexer {
+	return &Indexer{
+		rowsPerBlock: int64(max(rowsPerBlock, 1)),
+		tokens:       make(map[string]*blockRanges),
+	}
+}
+
+// Add adds the tokens of the log line at the given row of the logs section.
+// Rows must be added in increasing order.
+func (ix *Indexer) Add(row int64, line []byte) {
+	block := row / ix.rowsPerBlock
+	ix.rows = max(ix.rows, row+1)
+
+	Tokenize(line, func(token []byte) {
+		// Lookups with string(token) don't allocate; the key is only allocated
+		// when a new token is found.
+		ranges, ok := ix.tokens[string(token)]
+		if !ok {
+			ranges = &blockRanges{}
+			ix.tokens[string(token)] = ranges
+		}
+		ranges.add(block)
+	})
+}
+
+// Len returns the number of distinct tokens in ix.
+func (ix *Indexer) Len() int { return len(ix.tokens) }
+
+// EstimatedSize returns the estimated size of the tokens of ix once encoded
+// into a tokens section, excluding paths.
+func (ix *Indexer) EstimatedSize() int {
+	var size int
+	for token, ranges := range ix.tokens {
+		// Each range is assumed to be encoded in 4 bytes.
+		size += len(token) + 4*len(ranges.ranges)
+	}
+	return size
+}
+
+// Each calls f for every token in ix with the ranges of rows which contain
+// the token. The order of tokens is undefined.
+//
+// Each always calls f for the special token which marks the section as
+// indexed.
+func (ix *Indexer) Each(f func(token string, ranges []logs.RowRange)) {
+	if ix.rows == 0 {
+		return
+	}
+	f(indexedToken, []logs.RowRange{{Start: 0, End: ix.rows - 1}})
+
+	var rows []logs.RowRange
+	for token, blocks := range ix.tokens {
+		rows = rows[:0]
+		for _, r := range blocks.ranges {
+			rows = append(rows, logs.RowRange{
+				Start: r.Start * ix.rowsPerBlock,
+				End:   min((r.End+1)*ix.rowsPerBlock, ix.rows) - 1,
+			})
+		}
+		f(token, rows)
+	}
+}
+
+// Reset discards all tokens, allowing ix to be reused for another section.
+func (ix *Indexer) Reset() {
+	ix.rows = 0
+	clear(ix.tokens)
+}
+
+// blockRanges holds sorted, non-overlapping ranges of blocks.
+type blockRanges struct {
+	ranges []logs.RowRange
+}
+
+// add adds block to br. Blocks must be added in increasing order.
+func (br *blockRanges) add(block int64) {
+	if n := len(br.ranges); n > 0 {
+		last := &br.ranges[n-1]
+		if block <= last.End {
+			return
+		} else if block == last.End+1 {
+			last.End = block
+			return
+		}
+	}
+	br.ranges = append(br.ranges, logs.RowRange{Start: block, End: block})
+}
@@ -0,0 +1,71 @@
+package tokens
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/dataobj/sections/logs"
+)
+
+func TestTokenize(t *testing.T) {
+	tt := []struct {
+		line   string
+		expect []string
+	}{
+		{line: "", expect: nil},
+		{line: "ab", expect: nil},
+		{line: "abc", expect: []string{"abc"}},
+		{line: "abcd", expect: []string{"abc", "bcd"}},
+		{line: "aaaa", expect: []string{"aaa", "aaa"}},
+		{line: "a€bc", expect: []string{"a€b", "€bc"}},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.line, func(t *testing.T) {
+			var actual []string
+			Tokenize([]byte(tc.line), func(token []byte) {
+				actual = append(actual, string(token))
+			})
+			require.Equal(t, tc.expect, actual)
+		})
+	}
+}
+
+func TestIndexer(t *testing.T) {
+	ix := NewIndexer(2)
+	ix.Add(0, []byte("foo"))
+	ix.Add(1, []byte("bar"))
+	ix.Add(2, []byte("baz"))
+	ix.Add(3, []byte("qux"))
+	ix.Add(4, []byte("foo bar"))
+
+	actual := map[string][]logs.RowRange{}
+	ix.Each(func(token string, ranges []logs.RowRange) {
+		actual[token] = append([]logs.RowRange(nil), ranges...)
+	})
+
+	require.Equal(t, []logs.RowRange{{Start: 0, End: 4}}, actual[indexedToken])
+	require.Equal(t, []logs.RowRange{{Start: 0, End: 1}, {Start: 4, End: 4}}, actual["foo"])
+	require.Equal(t, []logs.RowRange{{Start: 0, End: 1}, {Start: 4, End: 4}}, actual["bar"])
+	require.Equal(t, []logs.RowRange{{Start: 2, End: 3}}, actual["baz"])
+	require.Equal(t, []logs.RowRange{{Start: 2, End: 3}}, actual["qux"])
+	require.Equal(t, []logs.RowRange{{Start: 4, End: 4}}, actual["o b"])
+	require.NotContains(t, actual, "fooba")
+
+	ix.Reset()
+	require.Zero(t, ix.Len())
+}
+
+func TestRowRanges(t *testing.T) {
+	ranges := []logs.RowRange{{Start: 0, End: 0}, {Start: 3, End: 10}, {Start: 1000, End: 5000}}
+
+	actual, err := decodeRowRanges(nil, appendRowRanges(nil, ranges))
+	require.NoError(t, err)
+	require.Equal(t, ranges, actual)
+
+	other := []logs.RowRange{{Start: 5, End: 1200}, {Start: 4000, End: 4000}}
+	expect := []logs.RowRange{{Start: 5, End: 10}, {Start: 1000, End: 1200}, {Start: 4000, End: 4000}}
+	require.Equal(t, expect, intersectRowRanges(ranges, other))
+	require.Empty(t, intersectRowRanges(ranges, nil))
+}
@@ -0,0 +1,130 @@
+package tokens
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"unsafe"
+
+	"github.com/grafana/loki/v3/pkg/dataobj"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/dataset"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/metadata/datasetmd"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/metadata/tokensmd"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/result"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/util/symbolizer"
+)
+
+// Iter iterates over tokens in the provided decoder. All tokens sections are
+// iterated over in order.
+func Iter(ctx context.Context, obj *dataobj.Object) result.Seq[Token] {
+	return result.Iter(func(yield func(Token) bool) error {
+		for i, section := range obj.Sections().Filter(CheckSection) {
+			tokensSection, err := Open(ctx, section)
+			if err != nil {
+				return fmt.Errorf("opening section %d: %w", i, err)
+			}
+
+			for result := range IterSection(ctx, tokensSection) {
+				if result.Err() != nil || !yield(result.MustValue()) {
+					return result.Err()
+				}
+			}
+		}
+
+		return nil
+	})
+}
+
+// IterSection iterates over the tokens in the provided section.
+func IterSection(ctx context.Context, section *Section) result.Seq[Token] {
+	return result.Iter(func(yield func(Token) bool) error {
+		r := NewRowReader(section)
+		defer r.Close()
+
+		var rows [1]Token
+		for {
+			// Row ranges are reused by Read, so rows are cleared to give each
+			// yielded token its own row ranges.
+			rows[0] = Token{}
+
+			n, err := r.Read(ctx, rows[:])
+			if err != nil && !errors.Is(err, io.EOF) {
+				return err
+			} else if n == 0 && errors.Is(err, io.EOF) {
+				return nil
+			}
+
+			for _, row := range rows[:n] {
+				if !yield(row) {
+					return nil
+				}
+			}
+		}
+	})
+}
+
+// decodeRow decodes a token from a [dataset.Row], using the provided columns
+// to determine the column type. The list of columns must match the columns
+// used to create the row.
+//
+// The sym argument is used for reusing paths and tokens between calls to
+// decodeRow. If sym is nil, strings are always allocated. The row ranges of
+// token are reused between calls.
+func decodeRow(columns []*tokensmd.ColumnDesc, row dataset.Row, token *Token, sym *symbolizer.Symbolizer) error {
+	token.Reset()
+
+	for columnIndex, columnValue := range row.Values {
+		if columnValue.IsNil() || columnValue.IsZero() {
+			continue
+		}
+
+		column := columns[columnIndex]
+		switch column.Type {
+		case tokensmd.COLUMN_TYPE_PATH:
+			if ty := columnValue.Type(); ty != datasetmd.VALUE_TYPE_BYTE_ARRAY {
+				return fmt.Errorf("invalid type %s for %s", ty, column.Type)
+			}
+			if sym != nil {
+				token.Path = sym.Get(unsafeString(columnValue.ByteArray()))
+			} else {
+				token.Path = string(columnValue.ByteArray())
+			}
+
+		case tokensmd.COLUMN_TYPE_SECTION:
+			if ty := columnValue.Type(); ty != datasetmd.VALUE_TYPE_INT64 {
+				return fmt.Errorf("invalid type %s for %s", ty, column.Type)
+			}
+			token.Section = columnValue.Int64()
+
+		case tokensmd.COLUMN_TYPE_TOKEN:
+			if ty := columnValue.Type(); ty != datasetmd.VALUE_TYPE_BYTE_ARRAY {
+				return fmt.Errorf("invalid type %s for %s", ty, column.Type)
+			}
+			if sym != nil {
+				token.Token = sym.Get(unsafeString(columnValue.ByteArray()))
+			} else {
+				token.Token = string(columnValue.ByteArray())
+			}
+
+		case tokensmd.COLUMN_TYPE_ROW_RANGES:
+			if ty := columnValue.Type(); ty != datasetmd.VALUE_TYPE_BYTE_ARRAY {
+				return fmt.Errorf("invalid type %s for %s", ty, column.Type)
+			}
+			rows, err := decodeRowRanges(token.Rows, columnValue.ByteArray())
+			if err != nil {
+				return fmt.Errorf("decoding row ranges: %w", err)
+			}
+			token.Rows = rows
+
+		default:
+			// Unknown columns are skipped for forward compatibility.
+		}
+	}
+
+	return nil
+}
+
+func unsafeString(data []byte) string {
+	return unsafe.String(unsafe.SliceData(data), len(data))
+}
@@ -0,0 +1,97 @@
+package tokens
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"unicode/utf8"
+
+	"github.com/grafana/loki/v3/pkg/dataobj"
+	"github.com/grafana/loki/v3/pkg/dataobj/sections/logs"
+)
+
+// MatchSubstrings returns the ranges of rows in the logs section section of
+// the data object at path whose log lines may contain all of substrings. The
+// rows are looked up in the tokens sections of the index object obj.
+//
+// Rows are grouped into blocks when indexing, so the returned ranges may
+// include rows which don't contain the substrings; callers must still filter
+// log lines.
+//
+// MatchSubstrings returns false if the rows can't be narrowed down by obj,
+// such as when none of the substrings is long enough to have a token or when
+// the section isn't indexed by obj.
+func MatchSubstrings(ctx context.Context, obj *dataobj.Object, path string, section int64, substrings []string) ([]logs.RowRange, bool, error) {
+	var lookup []string
+	for _, substring := range substrings {
+		if !utf8.ValidString(substring) {
+			// Tokens of invalid UTF-8 may not align with the tokens of log lines
+			// containing them.
+			continue
+		}
+		Tokenize([]byte(substring), func(token []byte) {
+			if !slices.Contains(lookup, string(token)) {
+				lookup = append(lookup, string(token))
+			}
+		})
+	}
+	if len(lookup) == 0 {
+		return nil, false, nil
+	}
+	lookup = append(lookup, indexedToken)
+
+	found := make(map[string][]logs.RowRange, len(lookup))
+
+	var r RowReader
+	defer r.Close()
+
+	buf := make([]Token, 128)
+	for i, sec := range obj.Sections().Filter(CheckSection) {
+		tokensSection, err := Open(ctx, sec)
+		if err != nil {
+			return nil, false, fmt.Errorf("opening section %d: %w", i, err)
+		}
+
+		r.Reset(tokensSection)
+		// Sections aren't part of the predicate, since the first section is
+		// stored as a NULL value; they're checked after reading instead.
+		if err := r.SetPredicate(TokenRowPredicate{Path: path, Tokens: lookup}); err != nil {
+			return nil, false, err
+		}
+
+		for {
+			// Row ranges are reused by Read, so the buffer is cleared to keep the
+			// row ranges of previously found tokens intact.
+			clear(buf)
+
+			n, err := r.Read(ctx, buf)
+			if err != nil && !errors.Is(err, io.EOF) {
+				return nil, false, fmt.Errorf("reading tokens: %w", err)
+			}
+			for _, token := range buf[:n] {
+				if token.Section != section {
+					continue
+				}
+				found[token.Token] = token.Rows
+			}
+			if n == 0 && errors.Is(err, io.EOF) {
+				break
+			}
+		}
+	}
+
+	rows, ok := found[indexedToken]
+	if !ok {
+		return nil, false, nil
+	}
+	for _, token := range lookup {
+		rows = intersectRowRanges(rows, found[token])
+		if len(rows) == 0 {
+			// Return a non-nil slice to denote that no rows match.
+			return []logs.RowRange{}, true, nil
+		}
+	}
+	return rows, true, nil
+}
@@ -0,0 +1,70 @@
+package tokens
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/dataobj"
+	"github.com/grafana/loki/v3/pkg/dataobj/sections/logs"
+)
+
+func TestMatchSubstrings(t *testing.T) {
+	lines := []string{
+		"level=info msg=starting",
+		"level=info msg=ready",
+		"level=warn msg=slow request",
+		"level=error msg=request failed",
+		"level=info msg=request done",
+		"level=info msg=stopping",
+	}
+
+	ix := NewIndexer(1)
+	for row, line := range lines {
+		ix.Add(int64(row), []byte(line))
+	}
+
+	tb := NewBuilder(nil, 64) // Small pages to span multiple pages.
+	tb.AppendIndexer("logs/1", 0, ix)
+	tb.AppendIndexer("logs/2", 0, ix)
+
+	var buf bytes.Buffer
+	b := dataobj.NewBuilder()
+	require.NoError(t, b.Append(tb))
+	_, err := b.Flush(&buf)
+	require.NoError(t, err)
+
+	obj, err := dataobj.FromReaderAt(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+
+	tt := []struct {
+		substrings []string
+		expect     []logs.RowRange
+		expectOK   bool
+	}{
+		{substrings: []string{"request"}, expect: []logs.RowRange{{Start: 2, End: 4}}, expectOK: true},
+		{substrings: []string{"request", "level=info"}, expect: []logs.RowRange{{Start: 4, End: 4}}, expectOK: true},
+		{substrings: []string{"ready"}, expect: []logs.RowRange{{Start: 1, End: 1}}, expectOK: true},
+		{substrings: []string{"panic"}, expect: []logs.RowRange{}, expectOK: true},
+		{substrings: []string{"ms"}, expectOK: false},
+		{substrings: nil, expectOK: false},
+	}
+
+	for _, tc := range tt {
+		t.Run(fmt.Sprint(tc.substrings), func(t *testing.T) {
+			actual, ok, err := MatchSubstrings(context.Background(), obj, "logs/1", 0, tc.substrings)
+			require.NoError(t, err)
+			require.Equal(t, tc.expectOK, ok)
+			require.Equal(t, tc.expect, actual)
+		})
+	}
+
+	t.Run("unindexed section", func(t *testing.T) {
+		_, ok, err := MatchSubstrings(context.Background(), obj, "logs/1", 1, []string{"request"})
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+}
@@ -0,0 +1,18 @@
+package tokens
+
+type (
+	// RowPredicate is an expression used to filter rows in a data object.
+	RowPredicate interface{ isRowPredicate() }
+)
+
+// Supported predicates.
+type (
+	// A TokenRowPredicate is a RowPredicate which requires a token to be for
+	// a section of the data object at Path, and to be one of Tokens.
+	TokenRowPredicate struct {
+		Path   string
+		Tokens []string
+	}
+)
+
+func (TokenRowPredicate) isRowPredicate() {}
@@ -0,0 +1,72 @@
+package tokens
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/grafana/loki/v3/pkg/dataobj/sections/logs"
+)
+
+// appendRowRanges appends the encoded form of ranges to dst and returns the
+// extended slice. ranges must be sorted and must not overlap.
+//
+// Each range is encoded as the distance from the end of the previous range to
+// its start, followed by its length minus one, both as uvarints.
+func appendRowRanges(dst []byte, ranges []logs.RowRange) []byte {
+	var prevEnd int64
+	for _, rr := range ranges {
+		dst = binary.AppendUvarint(dst, uint64(rr.Start-prevEnd))
+		dst = binary.AppendUvarint(dst, uint64(rr.End-rr.Start))
+		prevEnd = rr.End
+	}
+	return dst
+}
+
+// decodeRowRanges decodes ranges encoded by [appendRowRanges] from data,
+// appending them to dst.
+func decodeRowRanges(dst []logs.RowRange, data []byte) ([]logs.RowRange, error) {
+	var prevEnd int64
+	for len(data) > 0 {
+		delta, n := binary.Uvarint(data)
+		if n <= 0 {
+			return dst, errors.New("invalid row range start")
+		}
+		data = data[n:]
+
+		length, n := binary.Uvarint(data)
+		if n <= 0 {
+			return dst, errors.New("invalid row range length")
+		}
+		data = data[n:]
+
+		start := prevEnd + int64(delta)
+		rr := logs.RowRange{Start: start, End: start + int64(length)}
+		dst = append(dst, rr)
+		prevEnd = rr.End
+	}
+	return dst, nil
+}
+
+// intersectRowRanges returns the ranges of rows that are in both a and b.
+// Both a and b must be sorted and must not overlap.
+func intersectRowRanges(a, b []logs.RowRange) []logs.RowRange {
+	var res []logs.RowRange
+
+	var i, j int
+	for i < len(a) && j < len(b) {
+		start := max(a[i].Start, b[j].Start)
+		end := min(a[i].End, b[j].End)
+		if start <= end {
+			res = append(res, logs.RowRange{Start: start, End: end})
+		}
+
+		// Advance whichever range ends first; the other may still overlap with
+		// the next range.
+		if a[i].End < b[j].End {
+			i++
+		} else {
+			j++
+		}
+	}
+	return res
+}
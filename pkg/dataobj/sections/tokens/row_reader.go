@@ -0,0 +1,208 @@
+package tokens
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/dataset"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/metadata/tokensmd"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/util/slicegrow"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/util/symbolizer"
+)
+
+// RowReader is a reader for tokens in a data object.
+type RowReader struct {
+	sec   *Section
+	ready bool
+
+	predicate RowPredicate
+
+	buf []dataset.Row
+
+	reader     *dataset.Reader
+	columns    []dataset.Column
+	columnDesc []*tokensmd.ColumnDesc
+
+	symbols *symbolizer.Symbolizer
+}
+
+// NewRowReader creates a new RowReader for the given section.
+func NewRowReader(sec *Section) *RowReader {
+	var r RowReader
+	r.Reset(sec)
+	return &r
+}
+
+// SetPredicate sets the predicate to use for filtering tokens. [RowReader.Read]
+// will only return tokens for which the predicate passes.
+//
+// A predicate may only be set before reading begins or after a call to
+// [RowReader.Reset].
+func (r *RowReader) SetPredicate(p RowPredicate) error {
+	if r.ready {
+		return fmt.Errorf("cannot change predicate after reading has started")
+	}
+
+	r.predicate = p
+	return nil
+}
+
+// Read reads up to the next len(s) tokens from the reader and stores them into
+// s. It returns the number of tokens read and any error encountered. At the
+// end of the tokens section, Read returns 0, io.EOF.
+//
+// The row ranges of the tokens in s are reused to hold the row ranges read.
+func (r *RowReader) Read(ctx context.Context, s []Token) (int, error) {
+	if r.sec == nil {
+		return 0, io.EOF
+	}
+
+	if !r.ready {
+		err := r.initReader(ctx)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	r.buf = slicegrow.GrowToCap(r.buf, len(s))
+	r.buf = r.buf[:len(s)]
+	n, err := r.reader.Read(ctx, r.buf)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return 0, fmt.Errorf("reading rows: %w", err)
+	} else if n == 0 && errors.Is(err, io.EOF) {
+		return 0, io.EOF
+	}
+
+	for i := range r.buf[:n] {
+		if err := decodeRow(r.columnDesc, r.buf[i], &s[i], r.symbols); err != nil {
+			return i, fmt.Errorf("decoding token: %w", err)
+		}
+	}
+
+	return n, nil
+}
+
+func (r *RowReader) initReader(ctx context.Context) error {
+	dec := newDecoder(r.sec.reader)
+
+	columnDescs, err := dec.Columns(ctx)
+	if err != nil {
+		return fmt.Errorf("reading columns: %w", err)
+	}
+
+	dset, err := newColumnsDataset(r.sec.Columns())
+	if err != nil {
+		return fmt.Errorf("creating section dataset: %w", err)
+	}
+	columns := dset.Columns()
+
+	var predicates []dataset.Predicate
+	if p := translateTokensPredicate(r.predicate, columns, columnDescs); p != nil {
+		predicates = append(predicates, p)
+	}
+
+	readerOpts := dataset.ReaderOptions{
+		Dataset:    dset,
+		Columns:    columns,
+		Predicates: predicates,
+
+		TargetCacheSize: 16_000_000, // Permit up to 16MB of cache pages.
+	}
+
+	if r.reader == nil {
+		r.reader = dataset.NewReader(readerOpts)
+	} else {
+		r.reader.Reset(readerOpts)
+	}
+
+	if r.symbols == nil {
+		r.symbols = symbolizer.New(128, 100_000)
+	} else {
+		r.symbols.Reset()
+	}
+
+	r.columnDesc = columnDescs
+	r.columns = columns
+	r.ready = true
+	return nil
+}
+
+// Reset resets the RowReader with a new section to read from. Reset allows
+// reusing a RowReader without allocating a new one.
+//
+// Any set predicate is cleared when Reset is called.
+//
+// Reset may be called with a nil section to clear the RowReader without
+// needing a new section.
+func (r *RowReader) Reset(sec *Section) {
+	r.sec = sec
+	r.predicate = nil
+	r.ready = false
+	r.columns = nil
+	r.columnDesc = nil
+
+	if r.symbols != nil {
+		r.symbols.Reset()
+	}
+}
+
+// Close closes the RowReader and releases any resources it holds. Closed
+// RowReaders can be reused by calling [RowReader.Reset].
+func (r *RowReader) Close() error {
+	if r.reader != nil {
+		return r.reader.Close()
+	}
+	return nil
+}
+
+func translateTokensPredicate(p RowPredicate, columns []dataset.Column, columnDescs []*tokensmd.ColumnDesc) dataset.Predicate {
+	if p == nil {
+		return nil
+	}
+
+	var pathColumn, tokenColumn dataset.Column
+	for i, desc := range columnDescs {
+		switch desc.Type {
+		case tokensmd.COLUMN_TYPE_PATH:
+			pathColumn = columns[i]
+		case tokensmd.COLUMN_TYPE_TOKEN:
+			tokenColumn = columns[i]
+		}
+	}
+
+	switch p := p.(type) {
+	case TokenRowPredicate:
+		return convertTokenPredicate(p, pathColumn, tokenColumn)
+
+	default:
+		panic(fmt.Sprintf("unsupported predicate type %T", p))
+	}
+}
+
+func convertTokenPredicate(p TokenRowPredicate, pathColumn, tokenColumn dataset.Column) dataset.Predicate {
+	if pathColumn == nil || tokenColumn == nil || len(p.Tokens) == 0 {
+		return dataset.FalsePredicate{}
+	}
+
+	var tokens dataset.Predicate
+	for _, token := range p.Tokens {
+		equal := dataset.EqualPredicate{
+			Column: tokenColumn,
+			Value:  dataset.ByteArrayValue([]byte(token)),
+		}
+		if tokens == nil {
+			tokens = equal
+		} else {
+			tokens = dataset.OrPredicate{Left: tokens, Right: equal}
+		}
+	}
+
+	// The token is checked first, as tokens are sorted and allow skipping the
+	// most pages.
+	return dataset.AndPredicate{
+		Left:  tokens,
+		Right: dataset.EqualPredicate{Column: pathColumn, Value: dataset.ByteArrayValue([]byte(p.Path))},
+	}
+}
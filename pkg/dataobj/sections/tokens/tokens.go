@@ -0,0 +1,132 @@
+// Package tokens defines types used for the data object tokens section. The
+// tokens section maps n-grams of log lines to the ranges of rows in logs
+// sections of other data objects which contain them. Row ranges allow line
+// filters to skip rows which can't contain a substring before any of their
+// log lines are decoded.
+package tokens
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grafana/loki/v3/pkg/dataobj"
+	"github.com/grafana/loki/v3/pkg/dataobj/internal/metadata/tokensmd"
+)
+
+var sectionType = dataobj.SectionType{
+	Namespace: "github.com/grafana/loki",
+	Kind:      "tokens",
+}
+
+// CheckSection returns true if section is a tokens section.
+func CheckSection(section *dataobj.Section) bool { return section.Type == sectionType }
+
+// Section represents an opened tokens section.
+type Section struct {
+	reader  dataobj.SectionReader
+	columns []*Column
+}
+
+// Open opens a Section from an underlying [dataobj.Section]. Open returns an
+// error if the section metadata could not be read or if the provided ctx is
+// canceled.
+func Open(ctx context.Context, section *dataobj.Section) (*Section, error) {
+	if !CheckSection(section) {
+		return nil, fmt.Errorf("section type mismatch: got=%s want=%s", section.Type, sectionType)
+	}
+
+	sec := &Section{reader: section.Reader}
+	if err := sec.init(ctx); err != nil {
+		return nil, fmt.Errorf("intializing section: %w", err)
+	}
+	return sec, nil
+}
+
+func (s *Section) init(ctx context.Context) error {
+	dec := newDecoder(s.reader)
+	cols, err := dec.Columns(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to decode columns: %w", err)
+	}
+
+	for _, col := range cols {
+		colType, ok := convertColumnType(col.Type)
+		if !ok {
+			// Skip over unrecognized columns.
+			continue
+		}
+
+		s.columns = append(s.columns, &Column{
+			Section: s,
+			Name:    col.Info.Name,
+			Type:    colType,
+
+			desc: col,
+		})
+	}
+
+	return nil
+}
+
+// Columns returns the set of Columns in the section. The slice of returned
+// sections must not be mutated.
+//
+// Unrecognized columns (e.g., when running older code against newer tokens
+// sections) are skipped.
+func (s *Section) Columns() []*Column { return s.columns }
+
+// ColumnType represents the kind of information stored in a [Column].
+type ColumnType int
+
+const (
+	ColumnTypeInvalid   ColumnType = iota // ColumnTypeInvalid is an invalid column.
+	ColumnTypePath                        // ColumnTypePath is a column containing the path to the referenced data object.
+	ColumnTypeSection                     // ColumnTypeSection is a column containing the index of the section in the referenced data object.
+	ColumnTypeToken                       // ColumnTypeToken is a column containing a token of the log lines in the referenced section.
+	ColumnTypeRowRanges                   // ColumnTypeRowRanges is a column containing the ranges of rows in the referenced section which contain the token.
+)
+
+var columnTypeNames = map[ColumnType]string{
+	ColumnTypeInvalid:   "invalid",
+	ColumnTypePath:      "path",
+	ColumnTypeSection:   "section",
+	ColumnTypeToken:     "token",
+	ColumnTypeRowRanges: "row_ranges",
+}
+
+// String returns the human-readable name of ct.
+func (ct ColumnType) String() string {
+	text, ok := columnTypeNames[ct]
+	if !ok {
+		return fmt.Sprintf("ColumnType(%d)", ct)
+	}
+	return text
+}
+
+// A Column represents one of the columns in the tokens section. Valid columns
+// can only be retrieved by calling [Section.Columns].
+//
+// Data in columns can be read by using a [RowReader].
+type Column struct {
+	Section *Section   // Section that contains the column.
+	Name    string     // Optional name of the column.
+	Type    ColumnType // Type of data in the column.
+
+	desc *tokensmd.ColumnDesc // Column description used for further decoding and reading.
+}
+
+func convertColumnType(protoType tokensmd.ColumnType) (ColumnType, bool) {
+	switch protoType {
+	case tokensmd.COLUMN_TYPE_UNSPECIFIED:
+		return ColumnTypeInvalid, true
+	case tokensmd.COLUMN_TYPE_PATH:
+		return ColumnTypePath, true
+	case tokensmd.COLUMN_TYPE_SECTION:
+		return ColumnTypeSection, true
+	case tokensmd.COLUMN_TYPE_TOKEN:
+		return ColumnTypeToken, true
+	case tokensmd.COLUMN_TYPE_ROW_RANGES:
+		return ColumnTypeRowRanges, true
+	}
+	return ColumnTypeInvalid, false
+}
@@ -27,6 +27,7 @@ type ResultBuilder interface {
 
 var _ ResultBuilder = &streamsResultBuilder{}
 var _ ResultBuilder = &vectorResultBuilder{}
+var _ ResultBuilder = &matrixResultBuilder{}
 
 func newStreamsResultBuilder() *streamsResultBuilder {
 	return &streamsResultBuilder{
@@ -67,6 +68,7 @@ func (b *streamsResultBuilder) collectRow(rec arrow.Record, i int) (labels.Label
 	var entry logproto.Entry
 	lbs := labels.NewBuilder(labels.EmptyLabels())
 	metadata := labels.NewBuilder(labels.EmptyLabels())
+	parsed := labels.NewBuilder(labels.EmptyLabels())
 
 	for colIdx := range int(rec.NumCols()) {
 		col := rec.Column(colIdx)
@@ -112,10 +114,24 @@ func (b *streamsResultBuilder) collectRow(rec arrow.Record, i int) (labels.Label
 			}
 			continue
 		}
+
+		// Extract parsed
+		if colType == types.ColumnTypeParsed.String() {
+			switch arr := col.(type) {
+			case *array.String:
+				parsed.Set(colName, arr.Value(i))
+				// include parsed labels in stream labels
+				lbs.Set(colName, arr.Value(i))
+			}
+			continue
+		}
 	}
 	entry.StructuredMetadata = logproto.FromLabelsToLabelAdapters(metadata.Labels())
-	// set to a non-nil value to match with existing engine.
-	entry.Parsed = logproto.FromLabelsToLabelAdapters(labels.Labels{})
+	entry.Parsed = logproto.FromLabelsToLabelAdapters(parsed.Labels())
+	if entry.Parsed == nil {
+		// set to a non-nil value to match with existing engine.
+		entry.Parsed = logproto.FromLabelsToLabelAdapters(labels.Labels{})
+	}
 
 	return lbs.Labels(), entry
 }
@@ -140,6 +156,10 @@ type vectorResultBuilder struct {
 	data        promql.Vector
 	lblsBuilder *labels.Builder
 	stats       stats.Result
+
+	// sortByValue retains the order of the collected samples instead of
+	// sorting them by their labels, as required by sort and sort_desc.
+	sortByValue bool
 }
 
 func newVectorResultBuilder() *vectorResultBuilder {
@@ -161,8 +181,14 @@ func (b *vectorResultBuilder) CollectRecord(rec arrow.Record) {
 }
 
 func (b *vectorResultBuilder) collectRow(rec arrow.Record, i int) (promql.Sample, bool) {
+	return collectSample(rec, i, b.lblsBuilder)
+}
+
+// collectSample converts the row at index i of the record into a [promql.Sample].
+// The labels builder lbs is reset and reused to build the labels of the sample.
+func collectSample(rec arrow.Record, i int, lbs *labels.Builder) (promql.Sample, bool) {
 	var sample promql.Sample
-	b.lblsBuilder.Reset(labels.EmptyLabels())
+	lbs.Reset(labels.EmptyLabels())
 
 	// TODO: we add a lot of overhead by reading row by row. Switch to vectorized conversion.
 	for colIdx := range int(rec.NumCols()) {
@@ -188,27 +214,32 @@ func (b *vectorResultBuilder) collectRow(rec arrow.Record, i int) (promql.Sample
 				return promql.Sample{}, false
 			}
 
-			col, ok := col.(*array.Int64)
-			if !ok {
+			switch col := col.(type) {
+			case *array.Float64:
+				sample.F = col.Value(i)
+			case *array.Int64:
+				sample.F = float64(col.Value(i))
+			default:
 				return promql.Sample{}, false
 			}
-			sample.F = float64(col.Value(i))
 		default:
 			// allow any string columns
 			if colDataType == datatype.Loki.String.String() {
-				b.lblsBuilder.Set(colName, col.(*array.String).Value(i))
+				lbs.Set(colName, col.(*array.String).Value(i))
 			}
 		}
 	}
 
-	sample.Metric = b.lblsBuilder.Labels()
+	sample.Metric = lbs.Labels()
 	return sample, true
 }
 
 func (b *vectorResultBuilder) Build() logqlmodel.Result {
-	sort.Slice(b.data, func(i, j int) bool {
-		return labels.Compare(b.data[i].Metric, b.data[j].Metric) < 0
-	})
+	if !b.sortByValue {
+		sort.Slice(b.data, func(i, j int) bool {
+			return labels.Compare(b.data[i].Metric, b.data[j].Metric) < 0
+		})
+	}
 	return logqlmodel.Result{
 		Data:       b.data,
 		Statistics: b.stats,
@@ -222,3 +253,68 @@ func (b *vectorResultBuilder) SetStats(s stats.Result) {
 func (b *vectorResultBuilder) Len() int {
 	return len(b.data)
 }
+
+// matrixResultBuilder collects the samples of a range query into a [promql.Matrix].
+// Each record is expected to hold samples of one or more steps.
+type matrixResultBuilder struct {
+	seriesIndex map[uint64]promql.Series
+	lblsBuilder *labels.Builder
+	stats       stats.Result
+	count       int
+}
+
+func newMatrixResultBuilder() *matrixResultBuilder {
+	return &matrixResultBuilder{
+		seriesIndex: make(map[uint64]promql.Series),
+		lblsBuilder: labels.NewBuilder(labels.EmptyLabels()),
+	}
+}
+
+func (b *matrixResultBuilder) CollectRecord(rec arrow.Record) {
+	for row := range int(rec.NumRows()) {
+		sample, ok := collectSample(rec, row, b.lblsBuilder)
+		if !ok {
+			continue
+		}
+
+		hash := sample.Metric.Hash()
+		series, ok := b.seriesIndex[hash]
+		if !ok {
+			series = promql.Series{
+				Metric: sample.Metric,
+				Floats: make([]promql.FPoint, 0, 1),
+			}
+		}
+
+		series.Floats = append(series.Floats, promql.FPoint{T: sample.T, F: sample.F})
+		b.seriesIndex[hash] = series
+		b.count++
+	}
+}
+
+func (b *matrixResultBuilder) Build() logqlmodel.Result {
+	series := make([]promql.Series, 0, len(b.seriesIndex))
+	for _, s := range b.seriesIndex {
+		// steps are not guaranteed to arrive in order
+		sort.Slice(s.Floats, func(i, j int) bool {
+			return s.Floats[i].T < s.Floats[j].T
+		})
+		series = append(series, s)
+	}
+
+	result := promql.Matrix(series)
+	sort.Sort(result)
+
+	return logqlmodel.Result{
+		Data:       result,
+		Statistics: b.stats,
+	}
+}
+
+func (b *matrixResultBuilder) SetStats(s stats.Result) {
+	b.stats = s
+}
+
+func (b *matrixResultBuilder) Len() int {
+	return b.count
+}
@@ -187,6 +187,52 @@ func TestStreamsResultBuilder(t *testing.T) {
 		}
 		require.Equal(t, expected, result.Data.(logqlmodel.Streams))
 	})
+
+	t.Run("parsed labels are added to stream labels and entry", func(t *testing.T) {
+		mdTypeParsed := datatype.ColumnMetadata(types.ColumnTypeParsed, datatype.Loki.String)
+		schema := arrow.NewSchema(
+			[]arrow.Field{
+				{Name: types.ColumnNameBuiltinTimestamp, Type: arrow.FixedWidthTypes.Timestamp_ns, Metadata: datatype.ColumnMetadataBuiltinTimestamp},
+				{Name: types.ColumnNameBuiltinMessage, Type: arrow.BinaryTypes.String, Metadata: datatype.ColumnMetadataBuiltinMessage},
+				{Name: "env", Type: arrow.BinaryTypes.String, Metadata: mdTypeLabel},
+				{Name: "level", Type: arrow.BinaryTypes.String, Metadata: mdTypeParsed},
+			},
+			nil,
+		)
+
+		data := [][]interface{}{
+			{arrow.Timestamp(1620000000000000001), "level=error", "prod", "error"},
+			{arrow.Timestamp(1620000000000000002), "no level", "prod", nil},
+		}
+
+		record := createRecord(t, schema, data)
+		defer record.Release()
+
+		pipeline := executor.NewBufferedPipeline(record)
+		defer pipeline.Close()
+
+		builder := newStreamsResultBuilder()
+		err := collectResult(context.Background(), pipeline, builder)
+
+		require.NoError(t, err)
+		require.Equal(t, 2, builder.Len())
+
+		expected := logqlmodel.Streams{
+			push.Stream{
+				Labels: labels.FromStrings("env", "prod", "level", "error").String(),
+				Entries: []logproto.Entry{
+					{Line: "level=error", Timestamp: time.Unix(0, 1620000000000000001), Parsed: logproto.FromLabelsToLabelAdapters(labels.FromStrings("level", "error"))},
+				},
+			},
+			push.Stream{
+				Labels: labels.FromStrings("env", "prod").String(),
+				Entries: []logproto.Entry{
+					{Line: "no level", Timestamp: time.Unix(0, 1620000000000000002), Parsed: logproto.FromLabelsToLabelAdapters(labels.Labels{})},
+				},
+			},
+		}
+		require.Equal(t, expected, builder.Build().Data.(logqlmodel.Streams))
+	})
 }
 
 func TestVectorResultBuilder(t *testing.T) {
@@ -270,3 +316,56 @@ func TestVectorResultBuilder(t *testing.T) {
 		require.Equal(t, 0, builder.Len(), "expected no samples to be collected")
 	})
 }
+
+func TestMatrixResultBuilder(t *testing.T) {
+	mdTypeString := datatype.ColumnMetadata(types.ColumnTypeAmbiguous, datatype.Loki.String)
+
+	t.Run("successful conversion of matrix data", func(t *testing.T) {
+		schema := arrow.NewSchema(
+			[]arrow.Field{
+				{Name: types.ColumnNameBuiltinTimestamp, Type: arrow.FixedWidthTypes.Timestamp_ns, Metadata: datatype.ColumnMetadataBuiltinTimestamp},
+				{Name: types.ColumnNameGeneratedValue, Type: arrow.PrimitiveTypes.Int64, Metadata: datatype.ColumnMetadata(types.ColumnTypeGenerated, datatype.Loki.Integer)},
+				{Name: "instance", Type: arrow.BinaryTypes.String, Metadata: mdTypeString},
+				{Name: "job", Type: arrow.BinaryTypes.String, Metadata: mdTypeString},
+			},
+			nil,
+		)
+
+		// one record per step, second step is emitted first
+		record1 := createRecord(t, schema, [][]any{
+			{arrow.Timestamp(1620000060000000000), int64(10), "localhost:9090", "prometheus"},
+			{arrow.Timestamp(1620000060000000000), int64(20), "localhost:9100", "node-exporter"},
+		})
+		defer record1.Release()
+
+		record2 := createRecord(t, schema, [][]any{
+			{arrow.Timestamp(1620000000000000000), int64(42), "localhost:9090", "prometheus"},
+			{arrow.Timestamp(1620000000000000000), int64(23), "localhost:9100", "node-exporter"},
+		})
+		defer record2.Release()
+
+		pipeline := executor.NewBufferedPipeline(record1, record2)
+		defer pipeline.Close()
+
+		builder := newMatrixResultBuilder()
+		err := collectResult(context.Background(), pipeline, builder)
+
+		require.NoError(t, err)
+		require.Equal(t, 4, builder.Len())
+
+		result := builder.Build()
+		matrix := result.Data.(promql.Matrix)
+
+		expected := promql.Matrix{
+			{
+				Metric: labels.FromStrings("instance", "localhost:9090", "job", "prometheus"),
+				Floats: []promql.FPoint{{T: 1620000000000, F: 42}, {T: 1620000060000, F: 10}},
+			},
+			{
+				Metric: labels.FromStrings("instance", "localhost:9100", "job", "node-exporter"),
+				Floats: []promql.FPoint{{T: 1620000000000, F: 23}, {T: 1620000060000, F: 20}},
+			},
+		}
+		require.Equal(t, expected, matrix)
+	})
+}
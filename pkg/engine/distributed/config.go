@@ -0,0 +1,42 @@
+package distributed
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/grafana/dskit/grpcclient"
+)
+
+// Config configures the distributed execution of the plans of the next
+// generation query engine.
+type Config struct {
+	// Address of the queriers that execute the fragments of a plan. The plans
+	// are executed locally if empty.
+	Address string `yaml:"address"`
+	// MaxConcurrentFragments is the maximum number of fragments of a single
+	// query which are executed at the same time.
+	MaxConcurrentFragments int               `yaml:"max_concurrent_fragments"`
+	GRPCClientConfig       grpcclient.Config `yaml:"grpc_client_config" doc:"description=Configures the gRPC client used to execute plan fragments on other queriers."`
+}
+
+// RegisterFlagsWithPrefix registers flags for the distributed execution.
+func (cfg *Config) RegisterFlagsWithPrefix(prefix string, f *flag.FlagSet) {
+	f.StringVar(&cfg.Address, prefix+"address", "", "Experimental: Address of the queriers that execute the fragments of the plans of the next generation query engine, for example dns:///querier-headless:9095. Plans are executed entirely by the querier that received the query if empty.")
+	f.IntVar(&cfg.MaxConcurrentFragments, prefix+"max-concurrent-fragments", 16, "Experimental: Maximum number of fragments of a single query which are executed on other queriers at the same time.")
+	cfg.GRPCClientConfig.RegisterFlagsWithPrefix(prefix+"grpc-client", f)
+}
+
+// Validate validates the config.
+func (cfg *Config) Validate() error {
+	if cfg.Address == "" {
+		return nil
+	}
+	if cfg.MaxConcurrentFragments <= 0 {
+		return errors.New("max concurrent fragments must be greater than 0")
+	}
+	if err := cfg.GRPCClientConfig.Validate(); err != nil {
+		return fmt.Errorf("invalid gRPC client config: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,279 @@
+package distributed
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/stretchr/testify/require"
+	"github.com/thanos-io/objstore"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/grafana/loki/v3/pkg/dataobj/consumer/logsobj"
+	"github.com/grafana/loki/v3/pkg/engine/distributed/distributedpb"
+	"github.com/grafana/loki/v3/pkg/engine/executor"
+	"github.com/grafana/loki/v3/pkg/engine/planner/logical"
+	"github.com/grafana/loki/v3/pkg/engine/planner/physical"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql"
+)
+
+func TestScheduler(t *testing.T) {
+	bucket := objstore.NewInMemBucket()
+	catalog := make(testCatalog)
+	for i := range 3 {
+		location := fmt.Sprintf("objects/%d", i)
+		uploadDataobj(t, bucket, location, []logproto.Stream{
+			{
+				Labels: fmt.Sprintf(`{env="prod", pod="pod-%d"}`, i),
+				Entries: []logproto.Entry{
+					{Timestamp: time.Unix(int64(10+i), 0), Line: "level=info msg=hello"},
+					{Timestamp: time.Unix(int64(70+i), 0), Line: "level=error msg=goodbye"},
+				},
+			},
+			{
+				Labels: fmt.Sprintf(`{env="dev", pod="pod-%d"}`, i),
+				Entries: []logproto.Entry{
+					{Timestamp: time.Unix(int64(20+i), 0), Line: "level=info msg=hello"},
+				},
+			},
+		})
+		catalog[physical.DataObjLocation(location)] = []int64{1, 2}
+	}
+
+	cfg := executor.Config{BatchSize: 100, Bucket: bucket}
+
+	for _, tc := range []struct {
+		name  string
+		query string
+		root  physical.NodeType // type of the root nodes of the fragments
+	}{
+		{name: "log query", query: `{env="prod"} |= "msg"`, root: physical.NodeTypeSortMerge},
+		{name: "metric query", query: `sum by (env) (count_over_time({env=~".+"} | logfmt | level="info" [1m]))`, root: physical.NodeTypeRangeAggreation},
+	} {
+		// A single concurrent fragment must not block the merge of the
+		// fragments.
+		for _, maxConcurrent := range []int{1, 16} {
+			t.Run(fmt.Sprintf("%s/max concurrent %d", tc.name, maxConcurrent), func(t *testing.T) {
+				scheduler, handler := newTestScheduler(t, cfg, maxConcurrent)
+
+				ctx := context.Background()
+				params, err := logql.NewLiteralParams(tc.query, time.Unix(0, 0), time.Unix(120, 0), time.Minute, 0, logproto.BACKWARD, 100, nil, nil)
+				require.NoError(t, err)
+
+				expect := collect(t, executor.Run(ctx, cfg, buildPlan(t, params, catalog, false)))
+				require.NotEmpty(t, expect)
+
+				runner := &recordingRunner{FragmentRunner: scheduler.ForQuery()}
+				distributedCfg := cfg
+				distributedCfg.Fragments = runner
+				actual := collect(t, executor.Run(ctx, distributedCfg, buildPlan(t, params, catalog, true)))
+
+				require.Len(t, runner.roots, len(catalog), "every data object should be read by a fragment")
+				for _, root := range runner.roots {
+					require.Equal(t, tc.root, root)
+				}
+				require.Equal(t, expect, actual)
+				require.LessOrEqual(t, handler.maxRunning.Load(), int64(maxConcurrent))
+			})
+		}
+	}
+}
+
+func TestScheduler_Error(t *testing.T) {
+	scheduler, _ := newTestScheduler(t, executor.Config{BatchSize: 100, Bucket: objstore.NewInMemBucket()}, 16)
+
+	catalog := testCatalog{"missing": {1}}
+	params, err := logql.NewLiteralParams(`{env="prod"}`, time.Unix(0, 0), time.Unix(120, 0), 0, 0, logproto.BACKWARD, 100, nil, nil)
+	require.NoError(t, err)
+
+	cfg := executor.Config{BatchSize: 100, Fragments: scheduler.ForQuery()}
+	pipeline := executor.Run(context.Background(), cfg, buildPlan(t, params, catalog, true))
+	defer pipeline.Close()
+
+	err = pipeline.Read(context.Background())
+	require.Error(t, err)
+	require.False(t, errors.Is(err, executor.EOF))
+}
+
+// newTestScheduler returns a [Scheduler] that executes fragments with a
+// [Handler] behind an in-memory gRPC connection.
+func newTestScheduler(t *testing.T, cfg executor.Config, maxConcurrent int) (*Scheduler, *countingHandler) {
+	t.Helper()
+
+	handler := &countingHandler{Handler: NewHandler(cfg, log.NewNopLogger())}
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	distributedpb.RegisterFragmentExecutorServer(srv, handler)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+
+	scheduler := &Scheduler{
+		conn:          conn,
+		client:        distributedpb.NewFragmentExecutorClient(conn),
+		maxConcurrent: maxConcurrent,
+	}
+	t.Cleanup(func() { _ = scheduler.Close() })
+	return scheduler, handler
+}
+
+// countingHandler records the maximum number of fragments executed by the
+// wrapped handler at the same time.
+type countingHandler struct {
+	*Handler
+
+	running    atomic.Int64
+	maxRunning atomic.Int64
+}
+
+func (h *countingHandler) ExecuteFragment(req *distributedpb.ExecuteFragmentRequest, stream distributedpb.FragmentExecutor_ExecuteFragmentServer) error {
+	running := h.running.Add(1)
+	defer h.running.Add(-1)
+	for {
+		maxRunning := h.maxRunning.Load()
+		if running <= maxRunning || h.maxRunning.CompareAndSwap(maxRunning, running) {
+			break
+		}
+	}
+	return h.Handler.ExecuteFragment(req, stream)
+}
+
+func buildPlan(t *testing.T, params logql.Params, catalog physical.Catalog, fragments bool) *physical.Plan {
+	t.Helper()
+
+	logicalPlan, err := logical.BuildPlan(params)
+	require.NoError(t, err)
+
+	planner := physical.NewPlanner(physical.NewContext(params.Start(), params.End()), catalog)
+	if fragments {
+		planner.EnableFragments()
+	}
+	plan, err := planner.Build(logicalPlan)
+	require.NoError(t, err)
+	plan, err = planner.Optimize(plan)
+	require.NoError(t, err)
+	return plan
+}
+
+// collect returns the rows of all records of the pipeline, formatted as
+// strings. Rows of metric queries are sorted as their order is undefined.
+func collect(t *testing.T, p executor.Pipeline) []string {
+	t.Helper()
+	defer p.Close()
+
+	var (
+		rows   []string
+		sorted = true
+	)
+	for {
+		err := p.Read(context.Background())
+		if errors.Is(err, executor.EOF) {
+			break
+		}
+		require.NoError(t, err)
+
+		rec, err := p.Value()
+		require.NoError(t, err)
+
+		for i := range int(rec.NumRows()) {
+			fields := make([]string, 0, rec.NumCols())
+			for j, col := range rec.Columns() {
+				name := rec.Schema().Field(j).Name
+				if name == "value" {
+					sorted = false
+				}
+				if col.IsNull(i) {
+					continue
+				}
+				fields = append(fields, name+"="+col.ValueStr(i))
+			}
+			sort.Strings(fields)
+			rows = append(rows, strings.Join(fields, " "))
+		}
+		rec.Release()
+	}
+	if !sorted {
+		sort.Strings(rows)
+	}
+	return rows
+}
+
+func uploadDataobj(t *testing.T, bucket objstore.Bucket, location string, streams []logproto.Stream) {
+	t.Helper()
+
+	builder, err := logsobj.NewBuilder(logsobj.BuilderConfig{
+		TargetPageSize:          8_000,
+		TargetObjectSize:        math.MaxInt,
+		TargetSectionSize:       32_000,
+		BufferSize:              8_000,
+		SectionStripeMergeLimit: 2,
+	})
+	require.NoError(t, err)
+	for _, stream := range streams {
+		require.NoError(t, builder.Append(stream))
+	}
+
+	var buf bytes.Buffer
+	_, err = builder.Flush(&buf)
+	require.NoError(t, err)
+	require.NoError(t, bucket.Upload(context.Background(), location, &buf))
+}
+
+// testCatalog is a [physical.Catalog] that resolves every expression to all
+// of its data objects and their stream IDs, each having a single section.
+type testCatalog map[physical.DataObjLocation][]int64
+
+func (c testCatalog) ResolveDataObj(_ physical.Expression, _, _ time.Time) ([]physical.DataObjLocation, [][]int64, [][]int, []physical.DataObjLocation, error) {
+	var (
+		locations []physical.DataObjLocation
+		streams   [][]int64
+		sections  [][]int
+	)
+	for location := range c {
+		locations = append(locations, location)
+	}
+	sort.Slice(locations, func(i, j int) bool { return locations[i] < locations[j] })
+	for _, location := range locations {
+		streams = append(streams, c[location])
+		sections = append(sections, []int{0})
+	}
+	return locations, streams, sections, nil, nil
+}
+
+func (c testCatalog) ResolveDataObjWithShard(e physical.Expression, _ []physical.Expression, _ physical.ShardInfo, from, through time.Time) ([]physical.DataObjLocation, [][]int64, [][]int, []physical.DataObjLocation, error) {
+	return c.ResolveDataObj(e, from, through)
+}
+
+// recordingRunner records the types of the root nodes of the fragments
+// executed by the wrapped runner.
+type recordingRunner struct {
+	executor.FragmentRunner
+
+	mtx   sync.Mutex
+	roots []physical.NodeType
+}
+
+func (r *recordingRunner) RunFragment(ctx context.Context, fragment physical.Fragment) executor.Pipeline {
+	r.mtx.Lock()
+	r.roots = append(r.roots, fragment.Root.Type())
+	r.mtx.Unlock()
+	return r.FragmentRunner.RunFragment(ctx, fragment)
+}
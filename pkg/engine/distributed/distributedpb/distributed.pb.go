@@ -0,0 +1,720 @@
+// Code generated by protoc-gen-gogo. DO NOT EDIT.
+// source: pkg/engine/distributed/distributedpb/distributed.proto
+
+package distributedpb
+
+import (
+	bytes "bytes"
+	context "context"
+	fmt "fmt"
+	proto "github.com/gogo/protobuf/proto"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	io "io"
+	math "math"
+	math_bits "math/bits"
+	reflect "reflect"
+	strings "strings"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.GoGoProtoPackageIsVersion3 // please upgrade the proto package
+
+// ExecuteFragmentRequest holds a plan fragment to execute.
+type ExecuteFragmentRequest struct {
+	// The fragment, encoded by physical.MarshalPlan.
+	Plan []byte `protobuf:"bytes,1,opt,name=plan,proto3" json:"plan,omitempty"`
+}
+
+func (m *ExecuteFragmentRequest) Reset()      { *m = ExecuteFragmentRequest{} }
+func (*ExecuteFragmentRequest) ProtoMessage() {}
+func (*ExecuteFragmentRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_6a6ff427fa701822, []int{0}
+}
+func (m *ExecuteFragmentRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *ExecuteFragmentRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_ExecuteFragmentRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *ExecuteFragmentRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ExecuteFragmentRequest.Merge(m, src)
+}
+func (m *ExecuteFragmentRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *ExecuteFragmentRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ExecuteFragmentRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ExecuteFragmentRequest proto.InternalMessageInfo
+
+func (m *ExecuteFragmentRequest) GetPlan() []byte {
+	if m != nil {
+		return m.Plan
+	}
+	return nil
+}
+
+// ExecuteFragmentResponse holds the next chunk of the results of a fragment,
+// which are encoded in the Arrow IPC streaming format.
+type ExecuteFragmentResponse struct {
+	Data []byte `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+}
+
+func (m *ExecuteFragmentResponse) Reset()      { *m = ExecuteFragmentResponse{} }
+func (*ExecuteFragmentResponse) ProtoMessage() {}
+func (*ExecuteFragmentResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_6a6ff427fa701822, []int{1}
+}
+func (m *ExecuteFragmentResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *ExecuteFragmentResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_ExecuteFragmentResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *ExecuteFragmentResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ExecuteFragmentResponse.Merge(m, src)
+}
+func (m *ExecuteFragmentResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *ExecuteFragmentResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ExecuteFragmentResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ExecuteFragmentResponse proto.InternalMessageInfo
+
+func (m *ExecuteFragmentResponse) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*ExecuteFragmentRequest)(nil), "engine.distributed.ExecuteFragmentRequest")
+	proto.RegisterType((*ExecuteFragmentResponse)(nil), "engine.distributed.ExecuteFragmentResponse")
+}
+
+func init() {
+	proto.RegisterFile("pkg/engine/distributed/distributedpb/distributed.proto", fileDescriptor_6a6ff427fa701822)
+}
+
+var fileDescriptor_6a6ff427fa701822 = []byte{
+	// 259 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0x32, 0x2b, 0xc8, 0x4e, 0xd7,
+	0x4f, 0xcd, 0x4b, 0xcf, 0xcc, 0x4b, 0xd5, 0x4f, 0xc9, 0x2c, 0x2e, 0x29, 0xca, 0x4c, 0x2a, 0x2d,
+	0x49, 0x4d, 0x41, 0x66, 0x17, 0x24, 0x21, 0xf3, 0xf4, 0x0a, 0x8a, 0xf2, 0x4b, 0xf2, 0x85, 0x84,
+	0x20, 0x7a, 0xf4, 0x90, 0x64, 0x94, 0x74, 0xb8, 0xc4, 0x5c, 0x2b, 0x52, 0x93, 0x4b, 0x4b, 0x52,
+	0xdd, 0x8a, 0x12, 0xd3, 0x73, 0x53, 0xf3, 0x4a, 0x82, 0x52, 0x0b, 0x4b, 0x53, 0x8b, 0x4b, 0x84,
+	0x84, 0xb8, 0x58, 0x0a, 0x72, 0x12, 0xf3, 0x24, 0x18, 0x15, 0x18, 0x35, 0x78, 0x82, 0xc0, 0x6c,
+	0x25, 0x5d, 0x2e, 0x71, 0x0c, 0xd5, 0xc5, 0x05, 0xf9, 0x79, 0xc5, 0xa9, 0x20, 0xe5, 0x29, 0x89,
+	0x25, 0x89, 0x30, 0xe5, 0x20, 0xb6, 0x51, 0x13, 0x23, 0x97, 0x00, 0x4c, 0x21, 0x44, 0x5f, 0x7e,
+	0x91, 0x50, 0x1e, 0x17, 0x3f, 0x9a, 0x19, 0x42, 0x5a, 0x7a, 0x98, 0x2e, 0xd3, 0xc3, 0xee, 0x2c,
+	0x29, 0x6d, 0xa2, 0xd4, 0x42, 0x1c, 0xa5, 0xc4, 0x60, 0xc0, 0xe8, 0x54, 0x7a, 0xe1, 0xa1, 0x1c,
+	0xc3, 0x8d, 0x87, 0x72, 0x0c, 0x1f, 0x1e, 0xca, 0x31, 0x36, 0x3c, 0x92, 0x63, 0x5c, 0xf1, 0x48,
+	0x8e, 0xf1, 0xc4, 0x23, 0x39, 0xc6, 0x0b, 0x8f, 0xe4, 0x18, 0x1f, 0x3c, 0x92, 0x63, 0x7c, 0xf1,
+	0x48, 0x8e, 0xe1, 0xc3, 0x23, 0x39, 0xc6, 0x09, 0x8f, 0xe5, 0x18, 0x2e, 0x3c, 0x96, 0x63, 0xb8,
+	0xf1, 0x58, 0x8e, 0x21, 0xca, 0x3e, 0x3d, 0xb3, 0x24, 0xa3, 0x34, 0x49, 0x2f, 0x39, 0x3f, 0x57,
+	0x3f, 0xbd, 0x28, 0x31, 0x2d, 0x31, 0x2f, 0x51, 0x3f, 0x27, 0x3f, 0x3b, 0x53, 0xbf, 0xcc, 0x58,
+	0x9f, 0x98, 0xe0, 0x4f, 0x62, 0x03, 0x87, 0xb9, 0x31, 0x60, 0x00, 0x70, 0xd7, 0xc0, 0x69, 0xad,
+	0x01, 0x00, 0x00,
+}
+
+func (this *ExecuteFragmentRequest) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*ExecuteFragmentRequest)
+	if !ok {
+		that2, ok := that.(ExecuteFragmentRequest)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if !bytes.Equal(this.Plan, that1.Plan) {
+		return false
+	}
+	return true
+}
+func (this *ExecuteFragmentResponse) Equal(that interface{}) bool {
+	if that == nil {
+		return this == nil
+	}
+
+	that1, ok := that.(*ExecuteFragmentResponse)
+	if !ok {
+		that2, ok := that.(ExecuteFragmentResponse)
+		if ok {
+			that1 = &that2
+		} else {
+			return false
+		}
+	}
+	if that1 == nil {
+		return this == nil
+	} else if this == nil {
+		return false
+	}
+	if !bytes.Equal(this.Data, that1.Data) {
+		return false
+	}
+	return true
+}
+func (this *ExecuteFragmentRequest) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 5)
+	s = append(s, "&distributedpb.ExecuteFragmentRequest{")
+	s = append(s, "Plan: "+fmt.Sprintf("%#v", this.Plan)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func (this *ExecuteFragmentResponse) GoString() string {
+	if this == nil {
+		return "nil"
+	}
+	s := make([]string, 0, 5)
+	s = append(s, "&distributedpb.ExecuteFragmentResponse{")
+	s = append(s, "Data: "+fmt.Sprintf("%#v", this.Data)+",\n")
+	s = append(s, "}")
+	return strings.Join(s, "")
+}
+func valueToGoStringDistributed(v interface{}, typ string) string {
+	rv := reflect.ValueOf(v)
+	if rv.IsNil() {
+		return "nil"
+	}
+	pv := reflect.Indirect(rv).Interface()
+	return fmt.Sprintf("func(v %v) *%v { return &v } ( %#v )", typ, typ, pv)
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// FragmentExecutorClient is the client API for FragmentExecutor service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type FragmentExecutorClient interface {
+	// ExecuteFragment executes a plan fragment and streams its results back.
+	ExecuteFragment(ctx context.Context, in *ExecuteFragmentRequest, opts ...grpc.CallOption) (FragmentExecutor_ExecuteFragmentClient, error)
+}
+
+type fragmentExecutorClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewFragmentExecutorClient(cc *grpc.ClientConn) FragmentExecutorClient {
+	return &fragmentExecutorClient{cc}
+}
+
+func (c *fragmentExecutorClient) ExecuteFragment(ctx context.Context, in *ExecuteFragmentRequest, opts ...grpc.CallOption) (FragmentExecutor_ExecuteFragmentClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_FragmentExecutor_serviceDesc.Streams[0], "/engine.distributed.FragmentExecutor/ExecuteFragment", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &fragmentExecutorExecuteFragmentClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type FragmentExecutor_ExecuteFragmentClient interface {
+	Recv() (*ExecuteFragmentResponse, error)
+	grpc.ClientStream
+}
+
+type fragmentExecutorExecuteFragmentClient struct {
+	grpc.ClientStream
+}
+
+func (x *fragmentExecutorExecuteFragmentClient) Recv() (*ExecuteFragmentResponse, error) {
+	m := new(ExecuteFragmentResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// FragmentExecutorServer is the server API for FragmentExecutor service.
+type FragmentExecutorServer interface {
+	// ExecuteFragment executes a plan fragment and streams its results back.
+	ExecuteFragment(*ExecuteFragmentRequest, FragmentExecutor_ExecuteFragmentServer) error
+}
+
+// UnimplementedFragmentExecutorServer can be embedded to have forward compatible implementations.
+type UnimplementedFragmentExecutorServer struct {
+}
+
+func (*UnimplementedFragmentExecutorServer) ExecuteFragment(req *ExecuteFragmentRequest, srv FragmentExecutor_ExecuteFragmentServer) error {
+	return status.Errorf(codes.Unimplemented, "method ExecuteFragment not implemented")
+}
+
+func RegisterFragmentExecutorServer(s *grpc.Server, srv FragmentExecutorServer) {
+	s.RegisterService(&_FragmentExecutor_serviceDesc, srv)
+}
+
+func _FragmentExecutor_ExecuteFragment_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExecuteFragmentRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(FragmentExecutorServer).ExecuteFragment(m, &fragmentExecutorExecuteFragmentServer{stream})
+}
+
+type FragmentExecutor_ExecuteFragmentServer interface {
+	Send(*ExecuteFragmentResponse) error
+	grpc.ServerStream
+}
+
+type fragmentExecutorExecuteFragmentServer struct {
+	grpc.ServerStream
+}
+
+func (x *fragmentExecutorExecuteFragmentServer) Send(m *ExecuteFragmentResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _FragmentExecutor_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "engine.distributed.FragmentExecutor",
+	HandlerType: (*FragmentExecutorServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ExecuteFragment",
+			Handler:       _FragmentExecutor_ExecuteFragment_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "pkg/engine/distributed/distributedpb/distributed.proto",
+}
+
+func (m *ExecuteFragmentRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ExecuteFragmentRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *ExecuteFragmentRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.Plan) > 0 {
+		i -= len(m.Plan)
+		copy(dAtA[i:], m.Plan)
+		i = encodeVarintDistributed(dAtA, i, uint64(len(m.Plan)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *ExecuteFragmentResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ExecuteFragmentResponse) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *ExecuteFragmentResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.Data) > 0 {
+		i -= len(m.Data)
+		copy(dAtA[i:], m.Data)
+		i = encodeVarintDistributed(dAtA, i, uint64(len(m.Data)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func encodeVarintDistributed(dAtA []byte, offset int, v uint64) int {
+	offset -= sovDistributed(v)
+	base := offset
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return base
+}
+func (m *ExecuteFragmentRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Plan)
+	if l > 0 {
+		n += 1 + l + sovDistributed(uint64(l))
+	}
+	return n
+}
+
+func (m *ExecuteFragmentResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Data)
+	if l > 0 {
+		n += 1 + l + sovDistributed(uint64(l))
+	}
+	return n
+}
+
+func sovDistributed(x uint64) (n int) {
+	return (math_bits.Len64(x|1) + 6) / 7
+}
+func sozDistributed(x uint64) (n int) {
+	return sovDistributed(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+}
+func (this *ExecuteFragmentRequest) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&ExecuteFragmentRequest{`,
+		`Plan:` + fmt.Sprintf("%v", this.Plan) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *ExecuteFragmentResponse) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&ExecuteFragmentResponse{`,
+		`Data:` + fmt.Sprintf("%v", this.Data) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func valueToStringDistributed(v interface{}) string {
+	rv := reflect.ValueOf(v)
+	if rv.IsNil() {
+		return "nil"
+	}
+	pv := reflect.Indirect(rv).Interface()
+	return fmt.Sprintf("*%v", pv)
+}
+func (m *ExecuteFragmentRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowDistributed
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ExecuteFragmentRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ExecuteFragmentRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Plan", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDistributed
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthDistributed
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthDistributed
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Plan = append(m.Plan[:0], dAtA[iNdEx:postIndex]...)
+			if m.Plan == nil {
+				m.Plan = []byte{}
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipDistributed(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthDistributed
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ExecuteFragmentResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowDistributed
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ExecuteFragmentResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ExecuteFragmentResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Data", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDistributed
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthDistributed
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthDistributed
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Data = append(m.Data[:0], dAtA[iNdEx:postIndex]...)
+			if m.Data == nil {
+				m.Data = []byte{}
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipDistributed(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthDistributed
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func skipDistributed(dAtA []byte) (n int, err error) {
+	l := len(dAtA)
+	iNdEx := 0
+	depth := 0
+	for iNdEx < l {
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return 0, ErrIntOverflowDistributed
+			}
+			if iNdEx >= l {
+				return 0, io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		wireType := int(wire & 0x7)
+		switch wireType {
+		case 0:
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowDistributed
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				iNdEx++
+				if dAtA[iNdEx-1] < 0x80 {
+					break
+				}
+			}
+		case 1:
+			iNdEx += 8
+		case 2:
+			var length int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowDistributed
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				length |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if length < 0 {
+				return 0, ErrInvalidLengthDistributed
+			}
+			iNdEx += length
+		case 3:
+			depth++
+		case 4:
+			if depth == 0 {
+				return 0, ErrUnexpectedEndOfGroupDistributed
+			}
+			depth--
+		case 5:
+			iNdEx += 4
+		default:
+			return 0, fmt.Errorf("proto: illegal wireType %d", wireType)
+		}
+		if iNdEx < 0 {
+			return 0, ErrInvalidLengthDistributed
+		}
+		if depth == 0 {
+			return iNdEx, nil
+		}
+	}
+	return 0, io.ErrUnexpectedEOF
+}
+
+var (
+	ErrInvalidLengthDistributed        = fmt.Errorf("proto: negative length found during unmarshaling")
+	ErrIntOverflowDistributed          = fmt.Errorf("proto: integer overflow")
+	ErrUnexpectedEndOfGroupDistributed = fmt.Errorf("proto: unexpected end of group")
+)
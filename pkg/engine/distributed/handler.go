@@ -0,0 +1,57 @@
+package distributed
+
+import (
+	"bufio"
+	"bytes"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+
+	"github.com/grafana/loki/v3/pkg/engine/distributed/distributedpb"
+	"github.com/grafana/loki/v3/pkg/engine/executor"
+	utillog "github.com/grafana/loki/v3/pkg/util/log"
+)
+
+// chunkSize is the size of the chunks the results of a fragment are sent in.
+const chunkSize = 1 << 20
+
+// Handler executes the fragments sent by the [Scheduler] of other queriers.
+type Handler struct {
+	cfg    executor.Config
+	logger log.Logger
+}
+
+var _ distributedpb.FragmentExecutorServer = (*Handler)(nil)
+
+// NewHandler returns a new Handler that executes fragments with cfg.
+func NewHandler(cfg executor.Config, logger log.Logger) *Handler {
+	return &Handler{cfg: cfg, logger: logger}
+}
+
+// ExecuteFragment implements [distributedpb.FragmentExecutorServer].
+func (h *Handler) ExecuteFragment(req *distributedpb.ExecuteFragmentRequest, stream distributedpb.FragmentExecutor_ExecuteFragmentServer) error {
+	ctx := stream.Context()
+
+	w := bufio.NewWriterSize(&streamWriter{stream: stream}, chunkSize)
+	if err := executor.ExecuteFragment(ctx, h.cfg, req.Plan, w); err != nil {
+		level.Warn(utillog.WithContext(ctx, h.logger)).Log("msg", "failed to execute plan fragment", "err", err)
+		return err
+	}
+	return w.Flush()
+}
+
+// streamWriter is an [io.Writer] that sends every write as a response on the
+// stream.
+type streamWriter struct {
+	stream distributedpb.FragmentExecutor_ExecuteFragmentServer
+}
+
+// Write implements [io.Writer].
+func (w *streamWriter) Write(p []byte) (int, error) {
+	// The message may be used after Send returns, so p is copied as the
+	// buffered writer reuses it.
+	if err := w.stream.Send(&distributedpb.ExecuteFragmentResponse{Data: bytes.Clone(p)}); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
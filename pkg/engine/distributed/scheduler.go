@@ -0,0 +1,221 @@
+package distributed
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/grafana/dskit/instrument"
+	"github.com/grafana/dskit/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"golang.org/x/sync/semaphore"
+	"google.golang.org/grpc"
+
+	"github.com/grafana/loki/v3/pkg/engine/distributed/distributedpb"
+	"github.com/grafana/loki/v3/pkg/engine/executor"
+	"github.com/grafana/loki/v3/pkg/engine/planner/physical"
+	"github.com/grafana/loki/v3/pkg/util/constants"
+)
+
+// serviceConfig spreads the fragments of a plan across all queriers the
+// address resolves to.
+const serviceConfig = `{"loadBalancingPolicy": "round_robin"}`
+
+// Scheduler executes the fragments of a plan on the queriers behind the
+// configured address and merges their results into the plan executed by the
+// local querier.
+type Scheduler struct {
+	conn          *grpc.ClientConn
+	client        distributedpb.FragmentExecutorClient
+	maxConcurrent int
+}
+
+// NewScheduler returns a new Scheduler that connects to the queriers behind
+// cfg.Address.
+func NewScheduler(cfg Config, reg prometheus.Registerer) (*Scheduler, error) {
+	requestDuration := promauto.With(reg).NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: constants.Loki,
+		Subsystem: "engine_fragments",
+		Name:      "request_duration_seconds",
+		Help:      "Time (in seconds) spent executing plan fragments on other queriers.",
+		Buckets:   instrument.DefBuckets,
+	}, []string{"operation", "status_code"})
+
+	dialOpts, err := cfg.GRPCClientConfig.DialOption(
+		nil,
+		[]grpc.StreamClientInterceptor{
+			middleware.StreamClientUserHeaderInterceptor,
+			middleware.StreamClientInstrumentInterceptor(requestDuration),
+		},
+		middleware.NoOpInvalidClusterValidationReporter,
+	)
+	if err != nil {
+		return nil, err
+	}
+	dialOpts = append(dialOpts,
+		grpc.WithDefaultServiceConfig(serviceConfig),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+	)
+
+	conn, err := grpc.NewClient(cfg.Address, dialOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("creating gRPC client: %w", err)
+	}
+	return &Scheduler{
+		conn:          conn,
+		client:        distributedpb.NewFragmentExecutorClient(conn),
+		maxConcurrent: cfg.MaxConcurrentFragments,
+	}, nil
+}
+
+// ForQuery returns a runner for the fragments of a single query, which
+// executes at most the configured number of fragments at the same time.
+func (s *Scheduler) ForQuery() executor.FragmentRunner {
+	return &queryRunner{
+		client: s.client,
+		sem:    semaphore.NewWeighted(int64(s.maxConcurrent)),
+	}
+}
+
+// Close closes the connections to the queriers.
+func (s *Scheduler) Close() error {
+	return s.conn.Close()
+}
+
+// queryRunner executes the fragments of a single query.
+type queryRunner struct {
+	client distributedpb.FragmentExecutorClient
+	sem    *semaphore.Weighted
+}
+
+var _ executor.FragmentRunner = (*queryRunner)(nil)
+
+// RunFragment implements [executor.FragmentRunner]. The fragment is sent to
+// one of the queriers once its results are first read.
+func (r *queryRunner) RunFragment(ctx context.Context, fragment physical.Fragment) executor.Pipeline {
+	ctx, cancel := context.WithCancel(ctx)
+	return executor.NewIPCPipeline(&fragmentReader{
+		ctx:    ctx,
+		cancel: cancel,
+		client: r.client,
+		sem:    r.sem,
+		plan:   fragment.Plan,
+		notify: make(chan struct{}, 1),
+	})
+}
+
+// fragmentReader is an [io.ReadCloser] of the results of a fragment that is
+// executed by another querier.
+//
+// The results are received in the background and buffered until they are
+// read. The merge of the fragments of a query needs to read from all of them
+// before any of them is consumed, so a fragment must give up its slot once
+// it was executed, and not only once its results were read. Otherwise the
+// merge would wait for fragments which wait for a slot.
+type fragmentReader struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	client distributedpb.FragmentExecutorClient
+	sem    *semaphore.Weighted
+	plan   *physical.Plan
+
+	start  sync.Once
+	notify chan struct{} // signalled when chunks or err changed
+
+	mtx    sync.Mutex
+	chunks [][]byte // received chunks which weren't read yet
+	err    error    // set once the stream ended, io.EOF if all results were received
+
+	buf []byte // the chunk which is being read
+}
+
+// Read implements [io.Reader].
+func (r *fragmentReader) Read(p []byte) (int, error) {
+	r.start.Do(func() { go r.receive() })
+
+	for len(r.buf) == 0 {
+		r.mtx.Lock()
+		if len(r.chunks) > 0 {
+			r.buf = r.chunks[0]
+			r.chunks[0] = nil
+			r.chunks = r.chunks[1:]
+			r.mtx.Unlock()
+			continue
+		}
+		err := r.err
+		r.mtx.Unlock()
+		if err != nil {
+			return 0, err
+		}
+
+		select {
+		case <-r.notify:
+		case <-r.ctx.Done():
+			return 0, r.ctx.Err()
+		}
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// receive executes the fragment and buffers its results until the stream
+// ended.
+func (r *fragmentReader) receive() {
+	err := r.execute()
+
+	r.mtx.Lock()
+	r.err = err
+	r.mtx.Unlock()
+	r.signal()
+}
+
+// execute executes the fragment once a slot is available and receives all of
+// its results. It returns [io.EOF] once all results were received.
+func (r *fragmentReader) execute() error {
+	if err := r.sem.Acquire(r.ctx, 1); err != nil {
+		return err
+	}
+	defer r.sem.Release(1)
+
+	data, err := physical.MarshalPlan(r.plan)
+	if err != nil {
+		return fmt.Errorf("encoding fragment: %w", err)
+	}
+	stream, err := r.client.ExecuteFragment(r.ctx, &distributedpb.ExecuteFragmentRequest{Plan: data})
+	if err != nil {
+		return fmt.Errorf("executing fragment: %w", err)
+	}
+
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			return io.EOF
+		} else if err != nil {
+			return fmt.Errorf("receiving fragment results: %w", err)
+		}
+
+		r.mtx.Lock()
+		r.chunks = append(r.chunks, resp.Data)
+		r.mtx.Unlock()
+		r.signal()
+	}
+}
+
+// signal wakes up a pending Read.
+func (r *fragmentReader) signal() {
+	select {
+	case r.notify <- struct{}{}:
+	default:
+	}
+}
+
+// Close implements [io.Closer]. It cancels the execution of the fragment if
+// it is still running.
+func (r *fragmentReader) Close() error {
+	r.cancel()
+	return nil
+}
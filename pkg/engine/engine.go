@@ -20,13 +20,23 @@ import (
 	"github.com/grafana/loki/v3/pkg/logql/syntax"
 	"github.com/grafana/loki/v3/pkg/logqlmodel"
 	"github.com/grafana/loki/v3/pkg/logqlmodel/stats"
+	"github.com/grafana/loki/v3/pkg/util/httpreq"
 	utillog "github.com/grafana/loki/v3/pkg/util/log"
 )
 
 var ErrNotSupported = errors.New("feature not supported in new query engine")
 
+// FragmentScheduler creates the runners which execute the fragments of the
+// plans of queries, for example on other queriers.
+type FragmentScheduler interface {
+	// ForQuery returns the runner for the fragments of the plan of a single
+	// query.
+	ForQuery() executor.FragmentRunner
+}
+
 // New creates a new instance of the query engine that implements the [logql.Engine] interface.
-func New(opts logql.EngineOpts, bucket objstore.Bucket, limits logql.Limits, reg prometheus.Registerer, logger log.Logger) *QueryEngine {
+// If fragments is non-nil, the plans are split into fragments which are executed by it.
+func New(opts logql.EngineOpts, bucket objstore.Bucket, fragments FragmentScheduler, limits logql.Limits, reg prometheus.Registerer, logger log.Logger) *QueryEngine {
 	var ms metastore.Metastore
 	if bucket != nil {
 		metastoreBucket := objstore.NewPrefixedBucket(bucket, opts.CataloguePath)
@@ -43,6 +53,7 @@ func New(opts logql.EngineOpts, bucket objstore.Bucket, limits logql.Limits, reg
 		limits:    limits,
 		metastore: ms,
 		bucket:    bucket,
+		fragments: fragments,
 		opts:      opts,
 	}
 }
@@ -54,6 +65,7 @@ type QueryEngine struct {
 	limits    logql.Limits
 	metastore metastore.Metastore
 	bucket    objstore.Bucket
+	fragments FragmentScheduler
 	opts      logql.EngineOpts
 }
 
@@ -100,6 +112,9 @@ func (e *QueryEngine) Execute(ctx context.Context, params logql.Params) (logqlmo
 	}
 	catalog := physical.NewMetastoreCatalog(ctx, e.metastore, catalogueType)
 	planner := physical.NewPlanner(physical.NewContext(params.Start(), params.End()), catalog)
+	if e.fragments != nil {
+		planner.EnableFragments()
+	}
 	plan, err := planner.Build(logicalPlan)
 	if err != nil {
 		level.Warn(logger).Log("msg", "failed to create physical plan", "err", err)
@@ -125,10 +140,23 @@ func (e *QueryEngine) Execute(ctx context.Context, params logql.Params) (logqlmo
 		"msg", "start executing query with new engine",
 	)
 
+	// Collect runtime statistics of each node if the query requested its
+	// analyzed plan.
+	var analysis *executor.Analysis
+	if httpreq.IsExplainAnalyze(ctx) {
+		analysis = executor.NewAnalysis()
+	}
+
 	t = time.Now() // start stopwatch for execution
 	cfg := executor.Config{
-		BatchSize: int64(e.opts.BatchSize),
-		Bucket:    e.bucket,
+		BatchSize:   int64(e.opts.BatchSize),
+		Bucket:      e.bucket,
+		MemoryLimit: int64(e.opts.MemoryLimit),
+		SpillDir:    e.opts.SpillDirectory,
+		Analysis:    analysis,
+	}
+	if e.fragments != nil {
+		cfg.Fragments = e.fragments.ForQuery()
 	}
 	pipeline := executor.Run(ctx, cfg, plan)
 	defer pipeline.Close()
@@ -138,8 +166,13 @@ func (e *QueryEngine) Execute(ctx context.Context, params logql.Params) (logqlmo
 	case syntax.LogSelectorExpr:
 		builder = newStreamsResultBuilder()
 	case syntax.SampleExpr:
-		// assume instant query since logical planning would fail for range queries.
-		builder = newVectorResultBuilder()
+		if logql.GetRangeType(params) == logql.InstantType {
+			vectorBuilder := newVectorResultBuilder()
+			vectorBuilder.sortByValue, _ = logql.Sortable(params)
+			builder = vectorBuilder
+		} else {
+			builder = newMatrixResultBuilder()
+		}
 	default:
 		// should never happen as we already check the expression type in the logical planner
 		panic(fmt.Sprintf("failed to execute. Invalid exprression type (%T)", params.GetExpression()))
@@ -150,6 +183,9 @@ func (e *QueryEngine) Execute(ctx context.Context, params logql.Params) (logqlmo
 		return logqlmodel.Result{}, err
 	}
 
+	if analysis != nil {
+		statsCtx.AddPlan(physical.PrintAsTreeWithStats(plan, analysis.Stats))
+	}
 	builder.SetStats(statsCtx.Result(time.Since(start), 0, builder.Len()))
 
 	e.metrics.subqueries.WithLabelValues(statusSuccess).Inc()
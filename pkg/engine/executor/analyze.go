@@ -0,0 +1,164 @@
+package executor
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/dustin/go-humanize"
+
+	"github.com/grafana/loki/v3/pkg/engine/planner/physical"
+	"github.com/grafana/loki/v3/pkg/logqlmodel/stats"
+)
+
+// Analysis collects runtime statistics of the pipelines that execute the
+// nodes of a physical plan, such as for EXPLAIN ANALYZE.
+type Analysis struct {
+	mu    sync.Mutex
+	nodes map[physical.Node]*nodeStats
+}
+
+// NewAnalysis creates a new, empty Analysis.
+func NewAnalysis() *Analysis {
+	return &Analysis{nodes: make(map[physical.Node]*nodeStats)}
+}
+
+// nodeStats holds the runtime statistics of the pipeline of a single node.
+type nodeStats struct {
+	batches  int64         // number of records produced
+	rows     int64         // number of rows produced
+	bytes    int64         // size of the buffers of the records produced
+	duration time.Duration // wall time of reading, including the time spent reading inputs
+
+	// Statistics of reading data objects, only tracked for DataObjScan nodes.
+	scan *stats.Dataobj
+}
+
+// Stats returns the runtime statistics of node, formatted for printing with
+// [physical.PrintAsTreeWithStats]. It returns nil if node wasn't executed.
+func (a *Analysis) Stats(node physical.Node) []physical.Stat {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	s, ok := a.nodes[node]
+	if !ok {
+		return nil
+	}
+
+	result := []physical.Stat{
+		{Name: "rows", Value: s.rows},
+		{Name: "batches", Value: s.batches},
+		{Name: "bytes", Value: humanize.Bytes(uint64(s.bytes))},
+		{Name: "duration", Value: s.duration.Round(time.Microsecond)},
+	}
+	if s.scan != nil {
+		result = append(result,
+			physical.Stat{Name: "rows_scanned", Value: s.scan.PrePredicateDecompressedRows},
+			physical.Stat{Name: "rows_matched", Value: s.scan.PostPredicateRows},
+			physical.Stat{Name: "pages_scanned", Value: s.scan.PagesScanned},
+			physical.Stat{Name: "pages_downloaded", Value: s.scan.PagesDownloaded},
+			physical.Stat{Name: "pages_downloaded_bytes", Value: humanize.Bytes(uint64(s.scan.PagesDownloadedBytes))},
+		)
+	}
+	return result
+}
+
+// observe wraps the pipeline executing node to collect its statistics.
+func (a *Analysis) observe(node physical.Node, pipeline Pipeline) Pipeline {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	s := &nodeStats{}
+	if _, ok := node.(*physical.DataObjScan); ok {
+		s.scan = &stats.Dataobj{}
+	}
+	a.nodes[node] = s
+
+	return &observedPipeline{analysis: a, stats: s, inner: pipeline}
+}
+
+// observedPipeline is a [Pipeline] which records the statistics of reading
+// from the wrapped pipeline.
+type observedPipeline struct {
+	analysis *Analysis
+	stats    *nodeStats
+	inner    Pipeline
+}
+
+var _ Pipeline = (*observedPipeline)(nil)
+
+// Read implements [Pipeline].
+func (p *observedPipeline) Read(ctx context.Context) error {
+	// The executor reads pipelines from a single goroutine, so the data
+	// object statistics collected while reading from a scan belong to it.
+	var before stats.Dataobj
+	if p.stats.scan != nil {
+		before = stats.FromContext(ctx).Store().Dataobj
+	}
+
+	start := time.Now()
+	err := p.inner.Read(ctx)
+	duration := time.Since(start)
+
+	var rec arrow.Record
+	if err == nil {
+		rec, _ = p.inner.Value()
+	}
+
+	p.analysis.mu.Lock()
+	defer p.analysis.mu.Unlock()
+
+	p.stats.duration += duration
+	if rec != nil {
+		p.stats.batches++
+		p.stats.rows += rec.NumRows()
+		p.stats.bytes += recordSize(rec)
+	}
+	if p.stats.scan != nil {
+		after := stats.FromContext(ctx).Store().Dataobj
+		p.stats.scan.PrePredicateDecompressedRows += after.PrePredicateDecompressedRows - before.PrePredicateDecompressedRows
+		p.stats.scan.PostPredicateRows += after.PostPredicateRows - before.PostPredicateRows
+		p.stats.scan.PagesScanned += after.PagesScanned - before.PagesScanned
+		p.stats.scan.PagesDownloaded += after.PagesDownloaded - before.PagesDownloaded
+		p.stats.scan.PagesDownloadedBytes += after.PagesDownloadedBytes - before.PagesDownloadedBytes
+	}
+	return err
+}
+
+// Value implements [Pipeline].
+func (p *observedPipeline) Value() (arrow.Record, error) { return p.inner.Value() }
+
+// Close implements [Pipeline].
+func (p *observedPipeline) Close() { p.inner.Close() }
+
+// Inputs implements [Pipeline].
+func (p *observedPipeline) Inputs() []Pipeline { return p.inner.Inputs() }
+
+// Transport implements [Pipeline].
+func (p *observedPipeline) Transport() Transport { return p.inner.Transport() }
+
+// recordSize returns the total size of the buffers of rec in bytes.
+func recordSize(rec arrow.Record) int64 {
+	var size int64
+	for _, col := range rec.Columns() {
+		size += arrayDataSize(col.Data())
+	}
+	return size
+}
+
+func arrayDataSize(data arrow.ArrayData) int64 {
+	var size int64
+	for _, buf := range data.Buffers() {
+		if buf != nil {
+			size += int64(buf.Len())
+		}
+	}
+	for _, child := range data.Children() {
+		size += arrayDataSize(child)
+	}
+	if data.DataType().ID() == arrow.DICTIONARY {
+		size += arrayDataSize(data.Dictionary())
+	}
+	return size
+}
@@ -0,0 +1,90 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/engine/internal/datatype"
+	"github.com/grafana/loki/v3/pkg/engine/internal/types"
+	"github.com/grafana/loki/v3/pkg/engine/planner/physical"
+	"github.com/grafana/loki/v3/pkg/logqlmodel/stats"
+)
+
+func TestAnalysis(t *testing.T) {
+	fields := []arrow.Field{
+		{Name: "env", Type: datatype.Arrow.String, Metadata: datatype.ColumnMetadata(types.ColumnTypeLabel, datatype.Loki.String)},
+	}
+	rec1, err := CSVToArrow(fields, "prod\ndev")
+	require.NoError(t, err)
+	defer rec1.Release()
+	rec2, err := CSVToArrow(fields, "prod")
+	require.NoError(t, err)
+	defer rec2.Release()
+
+	readAll := func(t *testing.T, ctx context.Context, p Pipeline) {
+		for {
+			err := p.Read(ctx)
+			if errors.Is(err, EOF) {
+				return
+			}
+			require.NoError(t, err)
+		}
+	}
+	stat := func(stats []physical.Stat, name string) any {
+		for _, s := range stats {
+			if s.Name == name {
+				return s.Value
+			}
+		}
+		return nil
+	}
+
+	t.Run("rows and batches", func(t *testing.T) {
+		analysis := NewAnalysis()
+		node := &physical.Limit{}
+
+		pipeline := analysis.observe(node, NewBufferedPipeline(rec1, rec2))
+		defer pipeline.Close()
+		readAll(t, t.Context(), pipeline)
+
+		s := analysis.Stats(node)
+		require.Equal(t, int64(3), stat(s, "rows"))
+		require.Equal(t, int64(2), stat(s, "batches"))
+		require.NotEqual(t, "0 B", stat(s, "bytes"))
+		require.Nil(t, stat(s, "pages_scanned"), "only scans report pages")
+
+		require.Nil(t, analysis.Stats(&physical.Limit{}), "nodes which weren't executed have no stats")
+	})
+
+	t.Run("data object statistics of scans", func(t *testing.T) {
+		analysis := NewAnalysis()
+		node := &physical.DataObjScan{}
+
+		// The scan reports its statistics to the statistics context of the
+		// query.
+		scan := NewBufferedPipeline(rec1)
+		pipeline := analysis.observe(node, newGenericPipeline(Local, func(ctx context.Context, _ []Pipeline) state {
+			stats.FromContext(ctx).AddPagesScanned(2)
+			stats.FromContext(ctx).AddPostPredicateRows(5)
+			if err := scan.Read(ctx); err != nil {
+				return failureState(err)
+			}
+			return scan.state
+		}))
+		defer pipeline.Close()
+
+		statsCtx, ctx := stats.NewContext(t.Context())
+		statsCtx.AddPagesScanned(10) // scanned by other nodes
+		readAll(t, ctx, pipeline)
+
+		s := analysis.Stats(node)
+		require.Equal(t, int64(2), stat(s, "rows"))
+		require.Equal(t, int64(4), stat(s, "pages_scanned")) // read twice until EOF
+		require.Equal(t, int64(10), stat(s, "rows_matched"))
+		require.Equal(t, int64(14), statsCtx.Store().Dataobj.PagesScanned, "statistics of the query are retained")
+	})
+}
@@ -0,0 +1,588 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/loki/v3/pkg/engine/internal/datatype"
+	"github.com/grafana/loki/v3/pkg/engine/internal/types"
+	"github.com/grafana/loki/v3/pkg/engine/planner/physical"
+)
+
+// vectorSample is a single sample of a series of a vector.
+type vectorSample struct {
+	labels labels.Labels
+	value  float64
+}
+
+// binaryOperation holds the state of a binary operation between two vectors,
+// or between a vector and a scalar.
+type binaryOperation struct {
+	node *physical.BinaryOperation
+
+	scalar float64 // value of the scalar operand, if any
+
+	tsEval    evalFunc // used to evaluate the timestamp column
+	valueEval evalFunc // used to evaluate the value column
+}
+
+// NewBinaryOperationPipeline returns a pipeline that performs the binary
+// operation of node on the samples of the left and right inputs. One of the
+// inputs is nil if the respective operand of node is a scalar.
+//
+// Samples of two vectors are matched on their labels at each timestamp,
+// following the semantics of the vector matching of LogQL. The whole input is
+// read before the first record is returned.
+func NewBinaryOperationPipeline(node *physical.BinaryOperation, left, right Pipeline, evaluator expressionEvaluator) (Pipeline, error) {
+	b := &binaryOperation{
+		node: node,
+		tsEval: evaluator.newFunc(&physical.ColumnExpr{
+			Ref: types.ColumnRef{
+				Column: types.ColumnNameBuiltinTimestamp,
+				Type:   types.ColumnTypeBuiltin,
+			},
+		}),
+		valueEval: evaluator.newFunc(&physical.ColumnExpr{
+			Ref: types.ColumnRef{
+				Column: types.ColumnNameGeneratedValue,
+				Type:   types.ColumnTypeGenerated,
+			},
+		}),
+	}
+
+	switch {
+	case left != nil && right != nil:
+		if node.VectorMatching == nil {
+			return nil, fmt.Errorf("binary operation %s between vectors requires vector matching", node.Op)
+		}
+	case left != nil || right != nil:
+		if node.Op.IsSetOperation() {
+			return nil, fmt.Errorf("set operation %s is not allowed between a vector and a scalar", node.Op)
+		}
+		value, err := scalarValue(node.Scalar)
+		if err != nil {
+			return nil, err
+		}
+		b.scalar = value
+	default:
+		return nil, fmt.Errorf("binary operation %s requires at least one vector operand", node.Op)
+	}
+
+	if !node.Op.IsSetOperation() {
+		if _, err := evalSampleOp(node.Op, 0, 0); err != nil {
+			return nil, err
+		}
+	}
+
+	var done bool
+	return newGenericPipeline(Local, func(ctx context.Context, _ []Pipeline) state {
+		if done {
+			return Exhausted
+		}
+		done = true
+
+		record, err := b.run(ctx, left, right)
+		if err != nil {
+			return failureState(err)
+		}
+		return successState(record)
+	}, slices.DeleteFunc([]Pipeline{left, right}, func(p Pipeline) bool { return p == nil })...), nil
+}
+
+// scalarValue returns the value of a numeric literal.
+func scalarValue(expr physical.LiteralExpression) (float64, error) {
+	literal, ok := expr.(*physical.LiteralExpr)
+	if !ok {
+		return 0, fmt.Errorf("invalid scalar expression %v", expr)
+	}
+
+	switch literal := literal.Literal.(type) {
+	case datatype.FloatLiteral:
+		return literal.Value(), nil
+	case datatype.IntegerLiteral:
+		return float64(literal.Value()), nil
+	default:
+		return 0, fmt.Errorf("unsupported scalar of type %s", literal.Type())
+	}
+}
+
+// run reads all samples of the inputs and returns the record with the result
+// of the operation for all timestamps.
+func (b *binaryOperation) run(ctx context.Context, left, right Pipeline) (arrow.Record, error) {
+	var lhs, rhs map[time.Time][]vectorSample
+	if left != nil {
+		points, err := b.readAll(ctx, left)
+		if err != nil {
+			return nil, err
+		}
+		lhs = points
+	}
+	if right != nil {
+		points, err := b.readAll(ctx, right)
+		if err != nil {
+			return nil, err
+		}
+		rhs = points
+	}
+
+	results := make(map[time.Time][]vectorSample)
+	for ts := range joinKeys(lhs, rhs) {
+		var (
+			vec []vectorSample
+			err error
+		)
+		switch {
+		case left == nil:
+			vec, err = b.scalarOp(rhs[ts], true)
+		case right == nil:
+			vec, err = b.scalarOp(lhs[ts], false)
+		default:
+			vec, err = b.vectorOp(lhs[ts], rhs[ts])
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(vec) > 0 {
+			results[ts] = vec
+		}
+	}
+
+	if len(results) == 0 {
+		return nil, EOF
+	}
+	return buildVectorRecord(results), nil
+}
+
+// joinKeys returns the union of the timestamps of both sides.
+func joinKeys(lhs, rhs map[time.Time][]vectorSample) map[time.Time]struct{} {
+	keys := make(map[time.Time]struct{}, max(len(lhs), len(rhs)))
+	for ts := range lhs {
+		keys[ts] = struct{}{}
+	}
+	for ts := range rhs {
+		keys[ts] = struct{}{}
+	}
+	return keys
+}
+
+// readAll reads all records of input and returns their samples grouped by
+// timestamp. All string columns except the builtin and generated ones are
+// treated as labels of the samples.
+func (b *binaryOperation) readAll(ctx context.Context, input Pipeline) (map[time.Time][]vectorSample, error) {
+	points := make(map[time.Time][]vectorSample)
+	for {
+		if err := input.Read(ctx); err != nil {
+			if errors.Is(err, EOF) {
+				return points, nil
+			}
+			return nil, err
+		}
+		record, _ := input.Value()
+
+		tsVec, err := b.tsEval(record)
+		if err != nil {
+			return nil, err
+		}
+		tsCol := tsVec.ToArray().(*array.Timestamp)
+
+		valueVec, err := b.valueEval(record)
+		if err != nil {
+			return nil, err
+		}
+		valueArr, err := float64Values(valueVec)
+		if err != nil {
+			return nil, err
+		}
+
+		var (
+			names  []string
+			arrays []*array.String
+		)
+		for i, field := range record.Schema().Fields() {
+			ct, _ := field.Metadata.GetValue(types.MetadataKeyColumnType)
+			dt, _ := field.Metadata.GetValue(types.MetadataKeyColumnDataType)
+			if dt != datatype.Loki.String.String() || ct == types.ColumnTypeBuiltin.String() || ct == types.ColumnTypeGenerated.String() {
+				continue
+			}
+			names = append(names, field.Name)
+			arrays = append(arrays, record.Column(i).(*array.String))
+		}
+
+		lb := labels.NewScratchBuilder(len(names))
+		for row := range int(record.NumRows()) {
+			lb.Reset()
+			for i, arr := range arrays {
+				if arr.IsNull(row) || arr.Value(row) == "" {
+					continue
+				}
+				// Values are backed by the arrow array data buffer.
+				lb.Add(names[i], strings.Clone(arr.Value(row)))
+			}
+			lb.Sort()
+
+			ts := tsCol.Value(row).ToTime(arrow.Nanosecond)
+			points[ts] = append(points[ts], vectorSample{labels: lb.Labels(), value: valueArr(row)})
+		}
+	}
+}
+
+// scalarOp applies the operation between each sample of vec and the scalar.
+// scalarLeft is true if the scalar is the left operand.
+func (b *binaryOperation) scalarOp(vec []vectorSample, scalarLeft bool) ([]vectorSample, error) {
+	results := make([]vectorSample, 0, len(vec))
+	for _, sample := range vec {
+		left, right := sample.value, b.scalar
+		if scalarLeft {
+			left, right = right, left
+		}
+
+		value, err := evalSampleOp(b.node.Op, left, right)
+		if err != nil {
+			return nil, err
+		}
+		if b.node.Op.IsComparison() && !b.node.ReturnBool {
+			// Comparisons without bool modifier filter out the samples that
+			// do not satisfy the comparison and keep the others as is.
+			if value == 0 {
+				continue
+			}
+			value = sample.value
+		}
+		results = append(results, vectorSample{labels: sample.labels, value: value})
+	}
+	return results, nil
+}
+
+// vectorOp applies the operation between the samples of lhs and rhs that
+// match according to the vector matching of the operation.
+func (b *binaryOperation) vectorOp(lhs, rhs []vectorSample) ([]vectorSample, error) {
+	matching := b.node.VectorMatching
+
+	lsigs := make([]uint64, len(lhs))
+	for i, sample := range lhs {
+		lsigs[i] = matchingSignature(sample.labels, matching)
+	}
+	rsigs := make([]uint64, len(rhs))
+	for i, sample := range rhs {
+		rsigs[i] = matchingSignature(sample.labels, matching)
+	}
+
+	switch b.node.Op {
+	case types.BinaryOpAnd:
+		return vectorAnd(lhs, rhs, lsigs, rsigs), nil
+	case types.BinaryOpOr:
+		return vectorOr(lhs, rhs, lsigs, rsigs), nil
+	case types.BinaryOpUnless:
+		return vectorUnless(lhs, rhs, lsigs, rsigs), nil
+	default:
+		return b.vectorBinop(lhs, rhs, lsigs, rsigs)
+	}
+}
+
+// matchingSignature returns the signature of the labels used for matching
+// series between vectors.
+func matchingSignature(lbs labels.Labels, matching *physical.VectorMatching) uint64 {
+	names := columnNames(matching.MatchingLabels)
+	if matching.On {
+		return labels.StableHash(labels.NewBuilder(lbs).Keep(names...).Labels())
+	}
+	return labels.StableHash(labels.NewBuilder(lbs).Del(names...).Labels())
+}
+
+func columnNames(columns []physical.ColumnExpression) []string {
+	names := make([]string, 0, len(columns))
+	for _, column := range columns {
+		if column, ok := column.(*physical.ColumnExpr); ok {
+			names = append(names, column.Ref.Column)
+		}
+	}
+	return names
+}
+
+// vectorBinop applies an arithmetic or comparison operation between the
+// matching samples of lhs and rhs.
+func (b *binaryOperation) vectorBinop(lhs, rhs []vectorSample, lsigs, rsigs []uint64) ([]vectorSample, error) {
+	matching := b.node.VectorMatching
+
+	// One-to-many matching is handled as many-to-one matching with swapped
+	// sides.
+	if matching.Card == types.VectorMatchOneToMany {
+		lhs, rhs = rhs, lhs
+		lsigs, rsigs = rsigs, lsigs
+	}
+
+	// Add all samples of the "one" side to a map, so that matches can be
+	// found later.
+	rightSigs := make(map[uint64]vectorSample, len(rhs))
+	for i, sample := range rhs {
+		if _, ok := rightSigs[rsigs[i]]; ok {
+			side := "right"
+			if matching.Card == types.VectorMatchOneToMany {
+				side = "left"
+			}
+			return nil, fmt.Errorf("found duplicate series on the %s hand-side"+
+				";many-to-many matching not allowed: matching labels must be unique on one side", side)
+		}
+		rightSigs[rsigs[i]] = sample
+	}
+
+	matchedSigs := make(map[uint64]map[uint64]struct{})
+	results := make([]vectorSample, 0, len(lhs))
+	for i, ls := range lhs {
+		sig := lsigs[i]
+		rs, found := rightSigs[sig]
+		if !found {
+			continue
+		}
+
+		metric := resultMetric(ls.labels, rs.labels, matching)
+		insertedSigs, exists := matchedSigs[sig]
+		if matching.Card == types.VectorMatchOneToOne {
+			if exists {
+				return nil, errors.New("multiple matches for labels: many-to-one matching must be explicit (group_left/group_right)")
+			}
+			matchedSigs[sig] = nil
+		} else {
+			insertSig := labels.StableHash(metric)
+			if !exists {
+				insertedSigs = map[uint64]struct{}{}
+				matchedSigs[sig] = insertedSigs
+			} else if _, duplicate := insertedSigs[insertSig]; duplicate {
+				return nil, errors.New("multiple matches for labels: grouping labels must ensure unique matches")
+			}
+			insertedSigs[insertSig] = struct{}{}
+		}
+
+		// Swap back before applying the operation.
+		left, right := ls.value, rs.value
+		if matching.Card == types.VectorMatchOneToMany {
+			left, right = right, left
+		}
+
+		value, err := evalSampleOp(b.node.Op, left, right)
+		if err != nil {
+			return nil, err
+		}
+		if b.node.Op.IsComparison() && !b.node.ReturnBool {
+			if value == 0 {
+				continue
+			}
+			value = left
+		}
+		results = append(results, vectorSample{labels: metric, value: value})
+	}
+	return results, nil
+}
+
+// resultMetric returns the labels of the result of an operation between two
+// matching samples.
+func resultMetric(lhs, rhs labels.Labels, matching *physical.VectorMatching) labels.Labels {
+	lb := labels.NewBuilder(lhs)
+
+	names := columnNames(matching.MatchingLabels)
+	if matching.Card == types.VectorMatchOneToOne {
+		if matching.On {
+			lhs.Range(func(l labels.Label) {
+				if !slices.Contains(names, l.Name) {
+					lb.Del(l.Name)
+				}
+			})
+		} else {
+			lb.Del(names...)
+		}
+	}
+
+	// Labels included by group_left or group_right are taken from the "one"
+	// side.
+	for _, name := range columnNames(matching.Include) {
+		if v := rhs.Get(name); v != "" {
+			lb.Set(name, v)
+		} else {
+			lb.Del(name)
+		}
+	}
+	return lb.Labels()
+}
+
+func vectorAnd(lhs, rhs []vectorSample, lsigs, rsigs []uint64) []vectorSample {
+	if len(lhs) == 0 || len(rhs) == 0 {
+		return nil // AND with nothing is nothing.
+	}
+
+	rightSigs := make(map[uint64]struct{}, len(rsigs))
+	for _, sig := range rsigs {
+		rightSigs[sig] = struct{}{}
+	}
+
+	results := make([]vectorSample, 0, len(lhs))
+	for i, ls := range lhs {
+		if _, ok := rightSigs[lsigs[i]]; ok {
+			results = append(results, ls)
+		}
+	}
+	return results
+}
+
+func vectorOr(lhs, rhs []vectorSample, lsigs, rsigs []uint64) []vectorSample {
+	if len(lhs) == 0 {
+		return rhs
+	} else if len(rhs) == 0 {
+		return lhs
+	}
+
+	leftSigs := make(map[uint64]struct{}, len(lsigs))
+	results := make([]vectorSample, 0, len(lhs)+len(rhs))
+	for i, ls := range lhs {
+		leftSigs[lsigs[i]] = struct{}{}
+		results = append(results, ls)
+	}
+	for i, rs := range rhs {
+		if _, ok := leftSigs[rsigs[i]]; !ok {
+			results = append(results, rs)
+		}
+	}
+	return results
+}
+
+func vectorUnless(lhs, rhs []vectorSample, lsigs, rsigs []uint64) []vectorSample {
+	if len(lhs) == 0 || len(rhs) == 0 {
+		return lhs
+	}
+
+	rightSigs := make(map[uint64]struct{}, len(rsigs))
+	for _, sig := range rsigs {
+		rightSigs[sig] = struct{}{}
+	}
+
+	results := make([]vectorSample, 0, len(lhs))
+	for i, ls := range lhs {
+		if _, ok := rightSigs[lsigs[i]]; !ok {
+			results = append(results, ls)
+		}
+	}
+	return results
+}
+
+// evalSampleOp applies an arithmetic or comparison operation on two sample
+// values. Comparisons return 1 if the comparison is satisfied and 0
+// otherwise.
+func evalSampleOp(op types.BinaryOp, left, right float64) (float64, error) {
+	switch op {
+	case types.BinaryOpAdd:
+		return left + right, nil
+	case types.BinaryOpSub:
+		return left - right, nil
+	case types.BinaryOpMul:
+		return left * right, nil
+	case types.BinaryOpDiv:
+		// guard against divide by zero
+		if right == 0 {
+			return math.NaN(), nil
+		}
+		return left / right, nil
+	case types.BinaryOpMod:
+		// guard against divide by zero
+		if right == 0 {
+			return math.NaN(), nil
+		}
+		return math.Mod(left, right), nil
+	case types.BinaryOpPow:
+		return math.Pow(left, right), nil
+	case types.BinaryOpEq:
+		return boolToFloat(left == right), nil
+	case types.BinaryOpNeq:
+		return boolToFloat(left != right), nil
+	case types.BinaryOpGt:
+		return boolToFloat(left > right), nil
+	case types.BinaryOpGte:
+		return boolToFloat(left >= right), nil
+	case types.BinaryOpLt:
+		return boolToFloat(left < right), nil
+	case types.BinaryOpLte:
+		return boolToFloat(left <= right), nil
+	default:
+		return 0, fmt.Errorf("unsupported binary operation %s", op)
+	}
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// buildVectorRecord builds a record of the samples of all timestamps in
+// sorted order of timestamp. Each label of the samples becomes a column.
+func buildVectorRecord(points map[time.Time][]vectorSample) arrow.Record {
+	names := make(map[string]struct{})
+	for _, vec := range points {
+		for _, sample := range vec {
+			sample.labels.Range(func(l labels.Label) {
+				names[l.Name] = struct{}{}
+			})
+		}
+	}
+	columns := slices.Sorted(maps.Keys(names))
+
+	fields := make([]arrow.Field, 0, len(columns)+2)
+	fields = append(fields,
+		arrow.Field{
+			Name:     types.ColumnNameBuiltinTimestamp,
+			Type:     datatype.Arrow.Timestamp,
+			Nullable: false,
+			Metadata: datatype.ColumnMetadataBuiltinTimestamp,
+		},
+		arrow.Field{
+			Name:     types.ColumnNameGeneratedValue,
+			Type:     datatype.Arrow.Float,
+			Nullable: false,
+			Metadata: datatype.ColumnMetadata(types.ColumnTypeGenerated, datatype.Loki.Float),
+		},
+	)
+	for _, name := range columns {
+		fields = append(fields, arrow.Field{
+			Name:     name,
+			Type:     datatype.Arrow.String,
+			Nullable: true,
+			Metadata: datatype.ColumnMetadata(types.ColumnTypeAmbiguous, datatype.Loki.String),
+		})
+	}
+
+	schema := arrow.NewSchema(fields, nil)
+	rb := array.NewRecordBuilder(memory.NewGoAllocator(), schema)
+	defer rb.Release()
+
+	timestamps := slices.SortedFunc(maps.Keys(points), func(a, b time.Time) int {
+		return a.Compare(b)
+	})
+	for _, ts := range timestamps {
+		tsValue, _ := arrow.TimestampFromTime(ts, arrow.Nanosecond)
+		for _, sample := range points[ts] {
+			rb.Field(0).(*array.TimestampBuilder).Append(tsValue)
+			rb.Field(1).(*array.Float64Builder).Append(sample.value)
+
+			for col, name := range columns {
+				builder := rb.Field(col + 2).(*array.StringBuilder) // offset by 2 as the first 2 fields are timestamp and value
+				if v := sample.labels.Get(name); v != "" {
+					builder.Append(v)
+				} else {
+					builder.AppendNull()
+				}
+			}
+		}
+	}
+
+	return rb.NewRecord()
+}
@@ -0,0 +1,321 @@
+package executor
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/engine/internal/datatype"
+	"github.com/grafana/loki/v3/pkg/engine/internal/types"
+	"github.com/grafana/loki/v3/pkg/engine/planner/physical"
+)
+
+func TestBinaryOperationPipeline(t *testing.T) {
+	fields := []arrow.Field{
+		{Name: types.ColumnNameBuiltinTimestamp, Type: datatype.Arrow.Timestamp, Metadata: datatype.ColumnMetadataBuiltinTimestamp},
+		{Name: types.ColumnNameGeneratedValue, Type: datatype.Arrow.Float, Metadata: datatype.ColumnMetadata(types.ColumnTypeGenerated, datatype.Loki.Float)},
+		{Name: "env", Type: datatype.Arrow.String, Metadata: datatype.ColumnMetadata(types.ColumnTypeAmbiguous, datatype.Loki.String), Nullable: true},
+		{Name: "service", Type: datatype.Arrow.String, Metadata: datatype.ColumnMetadata(types.ColumnTypeAmbiguous, datatype.Loki.String), Nullable: true},
+	}
+
+	lhsCSV := `1970-01-01 00:00:01,10,prod,app1
+1970-01-01 00:00:01,20,prod,app2
+1970-01-01 00:00:01,30,dev,app1
+1970-01-01 00:00:02,40,prod,app1`
+
+	rhsCSV := `1970-01-01 00:00:01,5,prod,app1
+1970-01-01 00:00:01,0,prod,app2
+1970-01-01 00:00:01,60,staging,app1
+1970-01-01 00:00:02,80,prod,app1`
+
+	columns := func(names ...string) []physical.ColumnExpression {
+		exprs := make([]physical.ColumnExpression, len(names))
+		for i, name := range names {
+			exprs[i] = &physical.ColumnExpr{Ref: types.ColumnRef{Column: name, Type: types.ColumnTypeAmbiguous}}
+		}
+		return exprs
+	}
+
+	// matchAll matches the series on all labels.
+	matchAll := &physical.VectorMatching{Card: types.VectorMatchOneToOne}
+
+	for _, tt := range []struct {
+		name       string
+		op         types.BinaryOp
+		returnBool bool
+		matching   *physical.VectorMatching
+		scalar     physical.LiteralExpression
+		scalarLeft bool
+		lhsCSV     string
+		rhsCSV     string
+		expected   []string
+	}{
+		{
+			name:     "division",
+			op:       types.BinaryOpDiv,
+			matching: matchAll,
+			expected: []string{
+				`1 {env="prod", service="app1"} 2`,
+				`1 {env="prod", service="app2"} NaN`,
+				`2 {env="prod", service="app1"} 0.5`,
+			},
+		},
+		{
+			name:     "comparison filters samples",
+			op:       types.BinaryOpGt,
+			matching: matchAll,
+			expected: []string{
+				`1 {env="prod", service="app1"} 10`,
+				`1 {env="prod", service="app2"} 20`,
+			},
+		},
+		{
+			name:       "comparison with bool modifier",
+			op:         types.BinaryOpGt,
+			returnBool: true,
+			matching:   matchAll,
+			expected: []string{
+				`1 {env="prod", service="app1"} 1`,
+				`1 {env="prod", service="app2"} 1`,
+				`2 {env="prod", service="app1"} 0`,
+			},
+		},
+		{
+			name:     "on labels",
+			op:       types.BinaryOpAdd,
+			matching: &physical.VectorMatching{On: true, MatchingLabels: columns("env"), Card: types.VectorMatchOneToOne},
+			lhsCSV: `1970-01-01 00:00:01,10,prod,app1
+1970-01-01 00:00:01,30,dev,app1`,
+			rhsCSV: `1970-01-01 00:00:01,1,prod,NULL`,
+			expected: []string{
+				`1 {env="prod"} 11`,
+			},
+		},
+		{
+			name:     "ignoring labels",
+			op:       types.BinaryOpSub,
+			matching: &physical.VectorMatching{MatchingLabels: columns("service"), Card: types.VectorMatchOneToOne},
+			lhsCSV: `1970-01-01 00:00:01,10,prod,app1
+1970-01-01 00:00:01,30,dev,app1`,
+			rhsCSV: `1970-01-01 00:00:01,1,prod,app2`,
+			expected: []string{
+				`1 {env="prod"} 9`,
+			},
+		},
+		{
+			name:     "group_left",
+			op:       types.BinaryOpMul,
+			matching: &physical.VectorMatching{On: true, MatchingLabels: columns("env"), Card: types.VectorMatchManyToOne, Include: columns("service")},
+			lhsCSV: `1970-01-01 00:00:01,10,prod,NULL
+1970-01-01 00:00:01,30,dev,NULL`,
+			rhsCSV: `1970-01-01 00:00:01,2,prod,app1`,
+			expected: []string{
+				`1 {env="prod", service="app1"} 20`,
+			},
+		},
+		{
+			name:     "group_right",
+			op:       types.BinaryOpSub,
+			matching: &physical.VectorMatching{On: true, MatchingLabels: columns("env"), Card: types.VectorMatchOneToMany},
+			lhsCSV:   `1970-01-01 00:00:01,10,prod,NULL`,
+			rhsCSV: `1970-01-01 00:00:01,2,prod,app1
+1970-01-01 00:00:01,3,prod,app2`,
+			expected: []string{
+				`1 {env="prod", service="app1"} 8`,
+				`1 {env="prod", service="app2"} 7`,
+			},
+		},
+		{
+			name:     "and",
+			op:       types.BinaryOpAnd,
+			matching: &physical.VectorMatching{Card: types.VectorMatchManyToMany},
+			expected: []string{
+				`1 {env="prod", service="app1"} 10`,
+				`1 {env="prod", service="app2"} 20`,
+				`2 {env="prod", service="app1"} 40`,
+			},
+		},
+		{
+			name:     "or",
+			op:       types.BinaryOpOr,
+			matching: &physical.VectorMatching{Card: types.VectorMatchManyToMany},
+			expected: []string{
+				`1 {env="prod", service="app1"} 10`,
+				`1 {env="prod", service="app2"} 20`,
+				`1 {env="dev", service="app1"} 30`,
+				`1 {env="staging", service="app1"} 60`,
+				`2 {env="prod", service="app1"} 40`,
+			},
+		},
+		{
+			name:     "unless on labels",
+			op:       types.BinaryOpUnless,
+			matching: &physical.VectorMatching{On: true, MatchingLabels: columns("env"), Card: types.VectorMatchManyToMany},
+			expected: []string{
+				`1 {env="dev", service="app1"} 30`,
+			},
+		},
+		{
+			name:     "scalar on the right",
+			op:       types.BinaryOpDiv,
+			scalar:   physical.NewLiteral(float64(10)),
+			rhsCSV:   "-",
+			expected: []string{`1 {env="prod", service="app1"} 1`, `1 {env="prod", service="app2"} 2`, `1 {env="dev", service="app1"} 3`, `2 {env="prod", service="app1"} 4`},
+		},
+		{
+			name:       "scalar on the left",
+			op:         types.BinaryOpSub,
+			scalar:     physical.NewLiteral(float64(100)),
+			scalarLeft: true,
+			rhsCSV:     "-",
+			expected:   []string{`1 {env="prod", service="app1"} 90`, `1 {env="prod", service="app2"} 80`, `1 {env="dev", service="app1"} 70`, `2 {env="prod", service="app1"} 60`},
+		},
+		{
+			name:   "comparison with scalar filters samples",
+			op:     types.BinaryOpGte,
+			scalar: physical.NewLiteral(float64(30)),
+			rhsCSV: "-",
+			expected: []string{
+				`1 {env="dev", service="app1"} 30`,
+				`2 {env="prod", service="app1"} 40`,
+			},
+		},
+		{
+			name:       "comparison with scalar on the left",
+			op:         types.BinaryOpLt,
+			returnBool: true,
+			scalar:     physical.NewLiteral(float64(30)),
+			scalarLeft: true,
+			rhsCSV:     "-",
+			expected:   []string{`1 {env="prod", service="app1"} 0`, `1 {env="prod", service="app2"} 0`, `1 {env="dev", service="app1"} 0`, `2 {env="prod", service="app1"} 1`},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			input := func(csv, fallback string) Pipeline {
+				if csv == "" {
+					csv = fallback
+				}
+				if csv == "-" {
+					return nil
+				}
+				record, err := CSVToArrow(fields, csv)
+				require.NoError(t, err)
+				return NewBufferedPipeline(record)
+			}
+
+			left, right := input(tt.lhsCSV, lhsCSV), input(tt.rhsCSV, rhsCSV)
+			if tt.scalarLeft {
+				left, right = right, left
+			}
+
+			pipeline, err := NewBinaryOperationPipeline(&physical.BinaryOperation{
+				Op:             tt.op,
+				ReturnBool:     tt.returnBool,
+				VectorMatching: tt.matching,
+				Scalar:         tt.scalar,
+			}, left, right, expressionEvaluator{})
+			require.NoError(t, err)
+			defer pipeline.Close()
+
+			require.Equal(t, tt.expected, collectSamples(t, pipeline))
+		})
+	}
+}
+
+func TestBinaryOperationPipeline_Errors(t *testing.T) {
+	fields := []arrow.Field{
+		{Name: types.ColumnNameBuiltinTimestamp, Type: datatype.Arrow.Timestamp, Metadata: datatype.ColumnMetadataBuiltinTimestamp},
+		{Name: types.ColumnNameGeneratedValue, Type: datatype.Arrow.Float, Metadata: datatype.ColumnMetadata(types.ColumnTypeGenerated, datatype.Loki.Float)},
+		{Name: "env", Type: datatype.Arrow.String, Metadata: datatype.ColumnMetadata(types.ColumnTypeAmbiguous, datatype.Loki.String), Nullable: true},
+	}
+
+	many := `1970-01-01 00:00:01,1,prod
+1970-01-01 00:00:01,2,dev`
+	one := `1970-01-01 00:00:01,1,prod`
+
+	for _, tt := range []struct {
+		name     string
+		lhsCSV   string
+		rhsCSV   string
+		expected string
+	}{
+		{
+			name:     "duplicate series on the right hand-side",
+			lhsCSV:   one,
+			rhsCSV:   many,
+			expected: "found duplicate series on the right hand-side;many-to-many matching not allowed: matching labels must be unique on one side",
+		},
+		{
+			name:     "many-to-one matching without group_left",
+			lhsCSV:   many,
+			rhsCSV:   one,
+			expected: "multiple matches for labels: many-to-one matching must be explicit (group_left/group_right)",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			lhs, err := CSVToArrow(fields, tt.lhsCSV)
+			require.NoError(t, err)
+			rhs, err := CSVToArrow(fields, tt.rhsCSV)
+			require.NoError(t, err)
+
+			// Matching on no labels at all makes every series match.
+			pipeline, err := NewBinaryOperationPipeline(&physical.BinaryOperation{
+				Op:             types.BinaryOpAdd,
+				VectorMatching: &physical.VectorMatching{On: true, Card: types.VectorMatchOneToOne},
+			}, NewBufferedPipeline(lhs), NewBufferedPipeline(rhs), expressionEvaluator{})
+			require.NoError(t, err)
+			defer pipeline.Close()
+
+			err = pipeline.Read(t.Context())
+			require.EqualError(t, err, tt.expected)
+		})
+	}
+
+	t.Run("set operation with scalar", func(t *testing.T) {
+		record, err := CSVToArrow(fields, one)
+		require.NoError(t, err)
+
+		_, err = NewBinaryOperationPipeline(&physical.BinaryOperation{
+			Op:     types.BinaryOpAnd,
+			Scalar: physical.NewLiteral(float64(1)),
+		}, NewBufferedPipeline(record), nil, expressionEvaluator{})
+		require.Error(t, err)
+	})
+}
+
+// collectSamples reads all records of the pipeline and returns each row as
+// "<seconds> <labels> <value>".
+func collectSamples(t *testing.T, pipeline Pipeline) []string {
+	t.Helper()
+
+	var samples []string
+	for {
+		err := pipeline.Read(t.Context())
+		if errors.Is(err, EOF) {
+			return samples
+		}
+		require.NoError(t, err)
+
+		record, _ := pipeline.Value()
+		tsCol := record.Column(0).(*array.Timestamp)
+		valueCol := record.Column(1).(*array.Float64)
+		for row := range int(record.NumRows()) {
+			var lbs []string
+			for col := 2; col < int(record.NumCols()); col++ {
+				arr := record.Column(col).(*array.String)
+				if arr.IsNull(row) {
+					continue
+				}
+				lbs = append(lbs, fmt.Sprintf("%s=%q", record.ColumnName(col), arr.Value(row)))
+			}
+
+			ts := tsCol.Value(row).ToTime(arrow.Nanosecond).Unix()
+			samples = append(samples, fmt.Sprintf("%d {%s} %v", ts, strings.Join(lbs, ", "), valueCol.Value(row)))
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"slices"
 
 	"github.com/apache/arrow-go/v18/arrow"
@@ -29,6 +30,10 @@ type dataobjScan struct {
 	streams     map[int64]labels.Labels
 	records     []logs.Record
 
+	rows     int64         // number of rows returned so far
+	buffered bool          // whether the section has been read into buffer
+	buffer   []logs.Record // rows sorted by timestamp ASC that have not been returned yet
+
 	state state
 }
 
@@ -41,6 +46,7 @@ type dataobjScanOptions struct {
 	StreamIDs   []int64                     // Stream IDs to match from logs sections.
 	Section     int                         // Logs section to fetch.
 	Predicates  []logs.RowPredicate         // Predicate to apply to the logs.
+	RowRanges   []logs.RowRange             // Rows of the section to read. A nil slice means all rows.
 	Projections []physical.ColumnExpression // Columns to include. An empty slice means all columns.
 
 	Direction physical.SortOrder // Order of timestamps to return (ASC=Forward, DESC=Backward)
@@ -54,12 +60,9 @@ var _ Pipeline = (*dataobjScan)(nil)
 // newDataobjScanPipeline creates a new Pipeline which emits a single
 // [arrow.Record] composed of all log sections in a data object. Rows in the
 // returned record are ordered by timestamp in the direction specified by
-// opts.Direction.
+// opts.Direction. If opts.Limit is set, the pipeline stops reading once the
+// limit of rows has been returned.
 func newDataobjScanPipeline(opts dataobjScanOptions) *dataobjScan {
-	if opts.Direction == physical.ASC {
-		// It's ok to panic here, because the validation of log query direction is performed in the logical planner.
-		panic("sorting by timestamp ASC is not supported by DataObjScan")
-	}
 	return &dataobjScan{opts: opts}
 }
 
@@ -118,6 +121,9 @@ func (s *dataobjScan) init(ctx context.Context) error {
 		// reader.
 		_ = lr.MatchStreams(slices.Values(s.opts.StreamIDs))
 		_ = lr.SetPredicates(s.opts.Predicates)
+		if s.opts.RowRanges != nil {
+			_ = lr.MatchRows(s.opts.RowRanges)
+		}
 
 		s.reader = lr
 		break
@@ -190,30 +196,16 @@ func (s *dataobjScan) initStreams(ctx context.Context) error {
 	return errors.Join(errs...)
 }
 
-// read reads the entire data object into memory and generates an arrow.Record
-// from the data. It returns an error upon encountering an error while reading
-// one of the sections.
+// read reads the next batch of rows from the logs section and generates an
+// arrow.Record from the data. It returns an error upon encountering an error
+// while reading the section.
 func (s *dataobjScan) read(ctx context.Context) (arrow.Record, error) {
-	var (
-		n   int   // number of rows yielded by the datobj reader
-		err error // error yielded by the dataobj reader
-	)
-
-	// Read from the dataobj until it yields at least one row, to avoid these function calls from the parent.
-	for n == 0 {
-		// Reset buffer
-		s.records = s.records[:s.opts.batchSize]
-
-		n, err = s.reader.Read(ctx, s.records)
-		if n == 0 && errors.Is(err, io.EOF) {
-			return nil, EOF
-		} else if err != nil && !errors.Is(err, io.EOF) {
-			return nil, err
-		}
+	records, err := s.next(ctx)
+	if err != nil {
+		return nil, err
 	}
-	s.records = s.records[:n]
 
-	projections, err := s.effectiveProjections(s.records)
+	projections, err := s.effectiveProjections(records)
 	if err != nil {
 		return nil, fmt.Errorf("getting effective projections: %w", err)
 	}
@@ -227,7 +219,7 @@ func (s *dataobjScan) read(ctx context.Context) (arrow.Record, error) {
 	rb := array.NewRecordBuilder(memory.NewGoAllocator(), schema)
 	defer rb.Release()
 
-	for _, record := range s.records {
+	for _, record := range records {
 		for i := 0; i < schema.NumFields(); i++ {
 			field, builder := rb.Schema().Field(i), rb.Field(i)
 			s.appendToBuilder(builder, &field, &record)
@@ -237,44 +229,117 @@ func (s *dataobjScan) read(ctx context.Context) (arrow.Record, error) {
 	return rb.NewRecord(), nil
 }
 
-// getLessFunc returns a "less comparison" function for records for the sort heap.
-// direction determines the search order:
-// BACKWARD is a backward search starting at the end of the time range.
-// FORWARD is a forward search starting at the beginning of the time range.
-// If two records have the same timestamp, the compareStreams function is used to determine the sort order.
-func (s *dataobjScan) getLessFunc(direction physical.SortOrder) func(a, b logs.Record) bool {
-	compareStreams := func(a, b logs.Record) bool {
-		aStream, ok := s.streams[a.StreamID]
-		if !ok {
-			return false
+// next returns the next non-empty batch of rows in the order of
+// s.opts.Direction. It returns [EOF] once the section is exhausted or the
+// limit of rows has been returned.
+func (s *dataobjScan) next(ctx context.Context) ([]logs.Record, error) {
+	remaining := int64(math.MaxInt64)
+	if s.opts.Limit > 0 {
+		remaining = int64(s.opts.Limit) - s.rows
+	}
+	if remaining <= 0 {
+		// Stop reading from the section once the limit is reached.
+		return nil, EOF
+	}
+
+	var (
+		records []logs.Record
+		err     error
+	)
+	switch s.opts.Direction {
+	case physical.ASC:
+		records, err = s.nextAscending(ctx)
+	default:
+		records, err = s.nextDescending(ctx, remaining)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.rows += int64(len(records))
+	return records, nil
+}
+
+// nextDescending returns the next batch of at most limit rows. Rows are
+// stored in logs sections sorted by timestamp DESC, so they are returned in
+// the order they are read.
+func (s *dataobjScan) nextDescending(ctx context.Context, limit int64) ([]logs.Record, error) {
+	var (
+		n   int   // number of rows yielded by the datobj reader
+		err error // error yielded by the dataobj reader
+	)
+
+	// Read from the dataobj until it yields at least one row, to avoid these function calls from the parent.
+	for n == 0 {
+		// Reset buffer
+		s.records = s.records[:min(s.opts.batchSize, limit)]
+
+		n, err = s.reader.Read(ctx, s.records)
+		if n == 0 && errors.Is(err, io.EOF) {
+			return nil, EOF
+		} else if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
 		}
+	}
+	return s.records[:n], nil
+}
 
-		bStream, ok := s.streams[b.StreamID]
-		if !ok {
-			return true
+// nextAscending returns the next batch of rows sorted by timestamp ASC.
+// Since rows are stored in logs sections sorted by timestamp DESC, the
+// section is read entirely on the first call and the rows are returned in
+// reverse order.
+func (s *dataobjScan) nextAscending(ctx context.Context) ([]logs.Record, error) {
+	if !s.buffered {
+		if err := s.readAll(ctx); err != nil {
+			return nil, err
 		}
+		s.buffered = true
+	}
 
-		return labels.Compare(aStream, bStream) < 0
+	if len(s.buffer) == 0 {
+		return nil, EOF
 	}
 
-	switch direction {
-	case physical.ASC:
-		return func(a, b logs.Record) bool {
-			if a.Timestamp.Equal(b.Timestamp) {
-				compareStreams(a, b)
-			}
-			return a.Timestamp.After(b.Timestamp)
+	n := min(int(s.opts.batchSize), len(s.buffer))
+	records := s.buffer[:n]
+	s.buffer = s.buffer[n:]
+	return records, nil
+}
+
+// readAll reads all rows of the section into s.buffer, sorted by timestamp
+// ASC. If s.opts.Limit is set, only the oldest rows up to the limit are
+// retained.
+func (s *dataobjScan) readAll(ctx context.Context) error {
+	limit := int(s.opts.Limit)
+
+	for {
+		s.records = s.records[:s.opts.batchSize]
+
+		n, err := s.reader.Read(ctx, s.records)
+		if n == 0 && errors.Is(err, io.EOF) {
+			break
+		} else if err != nil && !errors.Is(err, io.EOF) {
+			return err
 		}
-	case physical.DESC:
-		return func(a, b logs.Record) bool {
-			if a.Timestamp.Equal(b.Timestamp) {
-				compareStreams(a, b)
-			}
-			return a.Timestamp.Before(b.Timestamp)
+
+		s.buffer = append(s.buffer, s.records[:n]...)
+
+		// Zero out the records so the next call to s.reader.Read doesn't
+		// overwrite any memory we just moved to s.buffer.
+		clear(s.records[:n])
+
+		// The oldest rows are read last, so rows that were read before the
+		// last limit rows can be discarded early to bound memory usage.
+		if limit > 0 && len(s.buffer) >= 2*limit {
+			s.buffer = slices.Clone(s.buffer[len(s.buffer)-limit:])
 		}
-	default:
-		panic("invalid direction")
 	}
+
+	if limit > 0 && len(s.buffer) > limit {
+		s.buffer = s.buffer[len(s.buffer)-limit:]
+	}
+	slices.Reverse(s.buffer)
+	return nil
 }
 
 // effectiveProjections returns the effective projections to return for a
@@ -5,8 +5,11 @@ import (
 	"fmt"
 	"math"
 	"regexp"
+	"slices"
 	"time"
 
+	"github.com/prometheus/prometheus/model/labels"
+
 	"github.com/grafana/loki/v3/pkg/dataobj/sections/logs"
 	"github.com/grafana/loki/v3/pkg/engine/internal/datatype"
 	"github.com/grafana/loki/v3/pkg/engine/internal/types"
@@ -66,8 +69,9 @@ func mapPredicates(expr physical.Expression) (logs.RowPredicate, error) {
 	switch e := expr.(type) {
 	case *physical.BinaryExpr:
 
-		// Special case: both sides of the binary op are again binary expressions (where LHS is expected to be a column expression)
-		if e.Left.Type() == physical.ExprTypeBinary && e.Right.Type() == physical.ExprTypeBinary {
+		// Special case: AND and OR combine two predicates, each of which can be
+		// on a different column.
+		if e.Op == types.BinaryOpAnd || e.Op == types.BinaryOpOr {
 			left, err := mapPredicates(e.Left)
 			if err != nil {
 				return nil, err
@@ -76,20 +80,16 @@ func mapPredicates(expr physical.Expression) (logs.RowPredicate, error) {
 			if err != nil {
 				return nil, err
 			}
-			switch e.Op {
-			case types.BinaryOpAnd:
+			if e.Op == types.BinaryOpAnd {
 				return logs.AndRowPredicate{
 					Left:  left,
 					Right: right,
 				}, nil
-			case types.BinaryOpOr:
-				return logs.OrRowPredicate{
-					Left:  left,
-					Right: right,
-				}, nil
-			default:
-				return nil, fmt.Errorf("unsupported operator in predicate: %s", e.Op)
 			}
+			return logs.OrRowPredicate{
+				Left:  left,
+				Right: right,
+			}, nil
 		}
 
 		if e.Left.Type() != physical.ExprTypeColumn {
@@ -111,6 +111,15 @@ func mapPredicates(expr physical.Expression) (logs.RowPredicate, error) {
 		default:
 			return nil, fmt.Errorf("unsupported column ref type (%T) in predicate: %s", left.Ref, left.Ref.String())
 		}
+	case *physical.UnaryExpr:
+		if e.Op != types.UnaryOpNot {
+			return nil, fmt.Errorf("unsupported unary operator (%s) in predicate, expected NOT", e.Op)
+		}
+		inner, err := mapPredicates(e.Left)
+		if err != nil {
+			return nil, err
+		}
+		return logs.NotRowPredicate{Inner: inner}, nil
 	default:
 		return nil, fmt.Errorf("unsupported expression type (%T) in predicate: %s", expr, expr.String())
 	}
@@ -310,40 +319,14 @@ func (m *timestampPredicateMapper) updateUpperBound(val time.Time, includeVal bo
 }
 
 // mapMetadataPredicate converts a physical.Expression into a dataobj.Predicate for metadata filtering.
-// It supports MetadataMatcherPredicate for equality checks on metadata fields,
-// and can recursively handle AndPredicate, OrPredicate, and NotPredicate.
+// It supports equality, inequality and regular expression matches on metadata
+// fields, and can recursively handle AndPredicate, OrPredicate, and NotPredicate.
 func mapMetadataPredicate(expr physical.Expression) (logs.RowPredicate, error) {
 	switch e := expr.(type) {
 	case *physical.BinaryExpr:
 		switch e.Op {
-		case types.BinaryOpEq:
-			if e.Left.Type() != physical.ExprTypeColumn {
-				return nil, fmt.Errorf("unsupported LHS type (%v) for EQ metadata predicate, expected ColumnExpr", e.Left.Type())
-			}
-			leftColumn, ok := e.Left.(*physical.ColumnExpr)
-			if !ok { // Should not happen due to Type() check but defensive
-				return nil, fmt.Errorf("LHS of EQ metadata predicate failed to cast to ColumnExpr")
-			}
-			if leftColumn.Ref.Type != types.ColumnTypeMetadata {
-				return nil, fmt.Errorf("unsupported LHS column type (%v) for EQ metadata predicate, expected ColumnTypeMetadata", leftColumn.Ref.Type)
-			}
-
-			if e.Right.Type() != physical.ExprTypeLiteral {
-				return nil, fmt.Errorf("unsupported RHS type (%v) for EQ metadata predicate, expected LiteralExpr", e.Right.Type())
-			}
-			rightLiteral, ok := e.Right.(*physical.LiteralExpr)
-			if !ok { // Should not happen
-				return nil, fmt.Errorf("RHS of EQ metadata predicate failed to cast to LiteralExpr")
-			}
-			if rightLiteral.ValueType() != datatype.Loki.String {
-				return nil, fmt.Errorf("unsupported RHS literal type (%v) for EQ metadata predicate, expected ValueTypeStr", rightLiteral.ValueType())
-			}
-			val := rightLiteral.Literal.(datatype.StringLiteral).Value()
-
-			return logs.MetadataMatcherRowPredicate{
-				Key:   leftColumn.Ref.Column,
-				Value: val,
-			}, nil
+		case types.BinaryOpEq, types.BinaryOpNeq, types.BinaryOpMatchRe, types.BinaryOpNotMatchRe:
+			return mapMetadataComparison(e)
 		case types.BinaryOpAnd:
 			leftPredicate, err := mapMetadataPredicate(e.Left)
 			if err != nil {
@@ -371,7 +354,7 @@ func mapMetadataPredicate(expr physical.Expression) (logs.RowPredicate, error) {
 				Right: rightPredicate,
 			}, nil
 		default:
-			return nil, fmt.Errorf("unsupported binary operator (%s) for metadata predicate, expected EQ, AND, or OR", e.Op)
+			return nil, fmt.Errorf("unsupported binary operator (%s) for metadata predicate, expected EQ, NEQ, MATCH_RE, NOT_MATCH_RE, AND, or OR", e.Op)
 		}
 	case *physical.UnaryExpr:
 		if e.Op != types.UnaryOpNot {
@@ -389,6 +372,97 @@ func mapMetadataPredicate(expr physical.Expression) (logs.RowPredicate, error) {
 	}
 }
 
+// mapMetadataComparison converts a comparison between a metadata column and a
+// literal into a logs.RowPredicate.
+//
+// Equality checks and regular expressions that only match a set of literal
+// values are mapped to predicates which permit skipping pages using column
+// statistics. Other comparisons are evaluated on the values of the column.
+func mapMetadataComparison(e *physical.BinaryExpr) (logs.RowPredicate, error) {
+	op := e.Op.String()
+
+	if e.Left.Type() != physical.ExprTypeColumn {
+		return nil, fmt.Errorf("unsupported LHS type (%v) for %s metadata predicate, expected ColumnExpr", e.Left.Type(), op)
+	}
+	leftColumn, ok := e.Left.(*physical.ColumnExpr)
+	if !ok { // Should not happen due to Type() check but defensive
+		return nil, fmt.Errorf("LHS of %s metadata predicate failed to cast to ColumnExpr", op)
+	}
+	if leftColumn.Ref.Type != types.ColumnTypeMetadata {
+		return nil, fmt.Errorf("unsupported LHS column type (%v) for %s metadata predicate, expected ColumnTypeMetadata", leftColumn.Ref.Type, op)
+	}
+
+	if e.Right.Type() != physical.ExprTypeLiteral {
+		return nil, fmt.Errorf("unsupported RHS type (%v) for %s metadata predicate, expected LiteralExpr", e.Right.Type(), op)
+	}
+	rightLiteral, ok := e.Right.(*physical.LiteralExpr)
+	if !ok { // Should not happen
+		return nil, fmt.Errorf("RHS of %s metadata predicate failed to cast to LiteralExpr", op)
+	}
+
+	key := leftColumn.Ref.Column
+
+	if rightLiteral.ValueType() != datatype.Loki.String {
+		return nil, fmt.Errorf("unsupported RHS literal type (%v) for %s metadata predicate, expected ValueTypeStr", rightLiteral.ValueType(), op)
+	}
+	val := rightLiteral.Literal.(datatype.StringLiteral).Value()
+
+	switch e.Op {
+	case types.BinaryOpEq:
+		return logs.MetadataMatcherRowPredicate{Key: key, Value: val}, nil
+	case types.BinaryOpNeq:
+		return logs.NotRowPredicate{
+			Inner: logs.MetadataMatcherRowPredicate{Key: key, Value: val},
+		}, nil
+	case types.BinaryOpMatchRe:
+		return mapMetadataRegex(key, val)
+	case types.BinaryOpNotMatchRe:
+		inner, err := mapMetadataRegex(key, val)
+		if err != nil {
+			return nil, err
+		}
+		return logs.NotRowPredicate{Inner: inner}, nil
+	default:
+		return nil, fmt.Errorf("unsupported binary operator (%s) for metadata predicate", e.Op)
+	}
+}
+
+// mapMetadataRegex converts a regular expression on the metadata key into a
+// logs.RowPredicate. Like label matchers, the regular expression is fully
+// anchored.
+func mapMetadataRegex(key, pattern string) (logs.RowPredicate, error) {
+	m, err := labels.NewFastRegexMatcher(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	// Regular expressions like "error|warn" only match a set of literal values,
+	// which can be checked against the column statistics.
+	if values := m.SetMatches(); len(values) > 0 && !slices.Contains(values, "") {
+		if len(values) == 1 {
+			return logs.MetadataMatcherRowPredicate{Key: key, Value: values[0]}, nil
+		}
+		return logs.MetadataInRowPredicate{Key: key, Values: values}, nil
+	}
+
+	if m.MatchString("") {
+		// Predicates never match if the metadata column does not exist in a
+		// section, while a missing key has an empty value which does match the
+		// regular expression. Negating the inverted match keeps these rows.
+		return logs.NotRowPredicate{
+			Inner: logs.MetadataFilterRowPredicate{
+				Key:  key,
+				Keep: func(_, value string) bool { return !m.MatchString(value) },
+			},
+		}, nil
+	}
+
+	return logs.MetadataFilterRowPredicate{
+		Key:  key,
+		Keep: func(_, value string) bool { return m.MatchString(value) },
+	}, nil
+}
+
 func mapMessagePredicate(expr physical.Expression) (logs.RowPredicate, error) {
 	switch e := expr.(type) {
 	case *physical.BinaryExpr:
@@ -517,3 +591,40 @@ func rhsValue(e *physical.BinaryExpr) (string, error) {
 
 	return rightLiteral.Literal.(datatype.StringLiteral).Value(), nil
 }
+
+// requiredSubstrings returns the substrings which log lines must contain to
+// match all predicates. Only substring matches on the log message which are
+// combined with AND are considered; other predicates are ignored.
+func requiredSubstrings(predicates []physical.Expression) []string {
+	var substrings []string
+
+	var walk func(expr physical.Expression)
+	walk = func(expr physical.Expression) {
+		e, ok := expr.(*physical.BinaryExpr)
+		if !ok {
+			return
+		}
+
+		switch e.Op {
+		case types.BinaryOpAnd:
+			walk(e.Left)
+			walk(e.Right)
+
+		case types.BinaryOpMatchSubstr:
+			left, ok := e.Left.(*physical.ColumnExpr)
+			if !ok || left.Ref.Type != types.ColumnTypeBuiltin || left.Ref.Column != types.ColumnNameBuiltinMessage {
+				return
+			}
+			val, err := rhsValue(e)
+			if err != nil {
+				return
+			}
+			substrings = append(substrings, val)
+		}
+	}
+
+	for _, p := range predicates {
+		walk(p)
+	}
+	return substrings
+}
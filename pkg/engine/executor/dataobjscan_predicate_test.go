@@ -572,6 +572,66 @@ func TestMapMetadataPredicate(t *testing.T) {
 			},
 			expectedErr: false,
 		},
+		{
+			name: "not equal predicate",
+			expr: &physical.BinaryExpr{
+				Left:  &physical.ColumnExpr{Ref: types.ColumnRef{Column: "foo", Type: types.ColumnTypeMetadata}},
+				Right: physical.NewLiteral("bar"),
+				Op:    types.BinaryOpNeq,
+			},
+			expectedPred: logs.NotRowPredicate{
+				Inner: logs.MetadataMatcherRowPredicate{Key: "foo", Value: "bar"},
+			},
+		},
+		{
+			name: "regex with a single literal value",
+			expr: &physical.BinaryExpr{
+				Left:  &physical.ColumnExpr{Ref: types.ColumnRef{Column: "trace_id", Type: types.ColumnTypeMetadata}},
+				Right: physical.NewLiteral("abc123"),
+				Op:    types.BinaryOpMatchRe,
+			},
+			expectedPred: logs.MetadataMatcherRowPredicate{Key: "trace_id", Value: "abc123"},
+		},
+		{
+			name: "regex with a set of literal values",
+			expr: &physical.BinaryExpr{
+				Left:  &physical.ColumnExpr{Ref: types.ColumnRef{Column: "level", Type: types.ColumnTypeMetadata}},
+				Right: physical.NewLiteral("error|warn"),
+				Op:    types.BinaryOpMatchRe,
+			},
+			expectedPred: logs.MetadataInRowPredicate{Key: "level", Values: []string{"error", "warn"}},
+		},
+		{
+			name: "negated regex with a set of literal values",
+			expr: &physical.BinaryExpr{
+				Left:  &physical.ColumnExpr{Ref: types.ColumnRef{Column: "level", Type: types.ColumnTypeMetadata}},
+				Right: physical.NewLiteral("error|warn"),
+				Op:    types.BinaryOpNotMatchRe,
+			},
+			expectedPred: logs.NotRowPredicate{
+				Inner: logs.MetadataInRowPredicate{Key: "level", Values: []string{"error", "warn"}},
+			},
+		},
+		{
+			name: "error: invalid regex",
+			expr: &physical.BinaryExpr{
+				Left:  &physical.ColumnExpr{Ref: types.ColumnRef{Column: "level", Type: types.ColumnTypeMetadata}},
+				Right: physical.NewLiteral("(error"),
+				Op:    types.BinaryOpMatchRe,
+			},
+			expectedErr: true,
+		},
+		{
+			// Numeric comparisons are evaluated by the Filter, as rows with
+			// non-numeric values are kept with an error label.
+			name: "error: numeric comparison",
+			expr: &physical.BinaryExpr{
+				Left:  &physical.ColumnExpr{Ref: types.ColumnRef{Column: "duration", Type: types.ColumnTypeMetadata}},
+				Right: physical.NewLiteral(int64(10)),
+				Op:    types.BinaryOpLt,
+			},
+			expectedErr: true,
+		},
 		{
 			name: "error: unsupported binary operator",
 			expr: &physical.BinaryExpr{
@@ -656,6 +716,89 @@ func TestMapMetadataPredicate(t *testing.T) {
 	}
 }
 
+func TestMapMetadataPredicate_Filter(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		expr    physical.Expression
+		negated bool // Whether the filter is wrapped in a NotRowPredicate.
+		keep    map[string]bool
+	}{
+		{
+			name: "regex",
+			expr: &physical.BinaryExpr{
+				Left:  newColumnExpr("level", types.ColumnTypeMetadata),
+				Right: physical.NewLiteral("err.*"),
+				Op:    types.BinaryOpMatchRe,
+			},
+			keep: map[string]bool{"error": true, "err": true, "warn": false, "": false, "panic error": false},
+		},
+		{
+			name: "regex matching the empty value",
+			expr: &physical.BinaryExpr{
+				Left:  newColumnExpr("level", types.ColumnTypeMetadata),
+				Right: physical.NewLiteral("err.*|"),
+				Op:    types.BinaryOpMatchRe,
+			},
+			negated: true,
+			keep:    map[string]bool{"error": false, "": false, "warn": true},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			pred, err := mapMetadataPredicate(tc.expr)
+			require.NoError(t, err)
+
+			if tc.negated {
+				not, ok := pred.(logs.NotRowPredicate)
+				require.True(t, ok, "expected NotRowPredicate, got %T", pred)
+				pred = not.Inner
+			}
+			filter, ok := pred.(logs.MetadataFilterRowPredicate)
+			require.True(t, ok, "expected MetadataFilterRowPredicate, got %T", pred)
+
+			col := tc.expr.(*physical.BinaryExpr).Left.(*physical.ColumnExpr)
+			require.Equal(t, col.Ref.Column, filter.Key)
+			for value, want := range tc.keep {
+				require.Equal(t, want, filter.Keep(filter.Key, value), "value %q", value)
+			}
+		})
+	}
+}
+
+func TestBuildLogsPredicate(t *testing.T) {
+	time100 := time.Unix(0, 100).UTC()
+
+	expr := &physical.BinaryExpr{
+		Left: &physical.UnaryExpr{
+			Left: &physical.BinaryExpr{
+				Left:  newColumnExpr("level", types.ColumnTypeMetadata),
+				Right: physical.NewLiteral("debug"),
+				Op:    types.BinaryOpEq,
+			},
+			Op: types.UnaryOpNot,
+		},
+		Right: &physical.BinaryExpr{
+			Left:  tsColExpr(),
+			Right: physical.NewLiteral(ts(time100)),
+			Op:    types.BinaryOpGt,
+		},
+		Op: types.BinaryOpOr,
+	}
+
+	got, err := buildLogsPredicate(expr)
+	require.NoError(t, err)
+	require.Equal(t, logs.OrRowPredicate{
+		Left: logs.NotRowPredicate{
+			Inner: logs.MetadataMatcherRowPredicate{Key: "level", Value: "debug"},
+		},
+		Right: logs.TimeRangeRowPredicate{
+			StartTime:    time100,
+			EndTime:      testOpenEnd,
+			IncludeStart: false,
+			IncludeEnd:   true,
+		},
+	}, got)
+}
+
 func TestMapMessagePredicate(t *testing.T) {
 	for _, tc := range []struct {
 		name         string
@@ -776,3 +919,80 @@ func TestMapMessagePredicate(t *testing.T) {
 		})
 	}
 }
+
+func TestRequiredSubstrings(t *testing.T) {
+	messageMatch := func(op types.BinaryOp, value string) physical.Expression {
+		return &physical.BinaryExpr{
+			Left:  &physical.ColumnExpr{Ref: types.ColumnRef{Column: "message", Type: types.ColumnTypeBuiltin}},
+			Right: physical.NewLiteral(value),
+			Op:    op,
+		}
+	}
+
+	for _, tc := range []struct {
+		name       string
+		predicates []physical.Expression
+		expected   []string
+	}{
+		{
+			name:       "no predicates",
+			predicates: nil,
+			expected:   nil,
+		},
+		{
+			name: "string match filters",
+			predicates: []physical.Expression{
+				messageMatch(types.BinaryOpMatchSubstr, "foo"),
+				messageMatch(types.BinaryOpMatchSubstr, "bar"),
+			},
+			expected: []string{"foo", "bar"},
+		},
+		{
+			name: "and",
+			predicates: []physical.Expression{
+				&physical.BinaryExpr{
+					Left:  messageMatch(types.BinaryOpMatchSubstr, "foo"),
+					Right: messageMatch(types.BinaryOpMatchSubstr, "bar"),
+					Op:    types.BinaryOpAnd,
+				},
+			},
+			expected: []string{"foo", "bar"},
+		},
+		{
+			name: "or is ignored",
+			predicates: []physical.Expression{
+				&physical.BinaryExpr{
+					Left:  messageMatch(types.BinaryOpMatchSubstr, "foo"),
+					Right: messageMatch(types.BinaryOpMatchSubstr, "bar"),
+					Op:    types.BinaryOpOr,
+				},
+			},
+			expected: nil,
+		},
+		{
+			name: "negated and regex filters are ignored",
+			predicates: []physical.Expression{
+				messageMatch(types.BinaryOpNotMatchSubstr, "foo"),
+				messageMatch(types.BinaryOpMatchRe, "bar"),
+				&physical.UnaryExpr{Left: messageMatch(types.BinaryOpMatchSubstr, "baz"), Op: types.UnaryOpNot},
+			},
+			expected: nil,
+		},
+		{
+			name: "metadata filters are ignored",
+			predicates: []physical.Expression{
+				&physical.BinaryExpr{
+					Left:  &physical.ColumnExpr{Ref: types.ColumnRef{Column: "level", Type: types.ColumnTypeMetadata}},
+					Right: physical.NewLiteral("error"),
+					Op:    types.BinaryOpMatchSubstr,
+				},
+				messageMatch(types.BinaryOpMatchSubstr, "foo"),
+			},
+			expected: []string{"foo"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, requiredSubstrings(tc.predicates))
+		})
+	}
+}
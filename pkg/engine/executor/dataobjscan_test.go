@@ -121,6 +121,77 @@ prod,notloki,NULL,notloki-pod-1,1970-01-01 00:00:02,hello world`
 		AssertPipelinesEqual(t, pipeline, NewBufferedPipeline(expectRecord))
 	})
 
+	projections := []physical.ColumnExpression{
+		&physical.ColumnExpr{Ref: types.ColumnRef{Column: "timestamp", Type: types.ColumnTypeBuiltin}},
+		&physical.ColumnExpr{Ref: types.ColumnRef{Column: "service", Type: types.ColumnTypeLabel}},
+	}
+	projectionFields := []arrow.Field{
+		{Name: "timestamp", Type: arrow.FixedWidthTypes.Timestamp_ns, Metadata: datatype.ColumnMetadataBuiltinTimestamp, Nullable: true},
+		{Name: "service", Type: arrow.BinaryTypes.String, Metadata: labelMD, Nullable: true},
+	}
+
+	for _, tt := range []struct {
+		name      string
+		direction physical.SortOrder
+		limit     uint32
+		batchSize int64
+		expectCSV string
+	}{
+		{
+			name:      "Ascending",
+			direction: physical.ASC,
+			batchSize: 512,
+			expectCSV: `1970-01-01 00:00:02,notloki
+1970-01-01 00:00:03,notloki
+1970-01-01 00:00:05,loki
+1970-01-01 00:00:10,loki`,
+		},
+		{
+			name:      "Ascending with small batches",
+			direction: physical.ASC,
+			batchSize: 1,
+			expectCSV: `1970-01-01 00:00:02,notloki
+1970-01-01 00:00:03,notloki
+1970-01-01 00:00:05,loki
+1970-01-01 00:00:10,loki`,
+		},
+		{
+			name:      "Descending with limit",
+			direction: physical.DESC,
+			limit:     3,
+			batchSize: 2,
+			expectCSV: `1970-01-01 00:00:10,loki
+1970-01-01 00:00:05,loki
+1970-01-01 00:00:03,notloki`,
+		},
+		{
+			name:      "Ascending with limit",
+			direction: physical.ASC,
+			limit:     2,
+			batchSize: 1,
+			expectCSV: `1970-01-01 00:00:02,notloki
+1970-01-01 00:00:03,notloki`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			pipeline := newDataobjScanPipeline(dataobjScanOptions{
+				Object:      obj,
+				StreamIDs:   []int64{1, 2}, // All streams
+				Section:     0,             // First section.
+				Projections: projections,
+				Direction:   tt.direction,
+				Limit:       tt.limit,
+				batchSize:   tt.batchSize,
+			})
+
+			expectRecord, err := CSVToArrow(projectionFields, tt.expectCSV)
+			require.NoError(t, err)
+			defer expectRecord.Release()
+
+			AssertPipelinesEqual(t, pipeline, NewBufferedPipeline(expectRecord))
+		})
+	}
+
 	t.Run("Unknown column", func(t *testing.T) {
 		// Here, we'll check for a column which only exists once in the dataobj but is
 		// ambiguous from the perspective of the caller.
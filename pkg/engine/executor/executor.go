@@ -5,16 +5,41 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/apache/arrow-go/v18/arrow/memory"
 	"github.com/thanos-io/objstore"
 
 	"github.com/grafana/loki/v3/pkg/dataobj"
 	"github.com/grafana/loki/v3/pkg/dataobj/sections/logs"
+	"github.com/grafana/loki/v3/pkg/dataobj/sections/tokens"
+	"github.com/grafana/loki/v3/pkg/engine/internal/types"
 	"github.com/grafana/loki/v3/pkg/engine/planner/physical"
 )
 
 type Config struct {
 	BatchSize int64
 	Bucket    objstore.Bucket
+
+	// Fragments executes the fragments of the plan, for example on other
+	// queriers. If nil, the whole plan is executed locally.
+	Fragments FragmentRunner
+
+	// MemoryLimit is the maximum number of bytes the aggregations of a query
+	// may hold in memory. Zero or less disables the limit.
+	MemoryLimit int64
+
+	// SpillDir is the directory aggregations spill their state to once they
+	// exceed MemoryLimit. If empty, exceeding the limit fails the query.
+	SpillDir string
+
+	// Analysis collects runtime statistics of each node of the plan if
+	// non-nil.
+	Analysis *Analysis
+}
+
+// FragmentRunner executes a [physical.Fragment] of a plan and returns a
+// pipeline of its results.
+type FragmentRunner interface {
+	RunFragment(ctx context.Context, fragment physical.Fragment) Pipeline
 }
 
 func Run(ctx context.Context, cfg Config, plan *physical.Plan) Pipeline {
@@ -22,6 +47,13 @@ func Run(ctx context.Context, cfg Config, plan *physical.Plan) Pipeline {
 		plan:      plan,
 		batchSize: cfg.BatchSize,
 		bucket:    cfg.Bucket,
+		runner:    cfg.Fragments,
+		budget:    newMemoryBudget(cfg.MemoryLimit),
+		spillDir:  cfg.SpillDir,
+		analysis:  cfg.Analysis,
+	}
+	if c.budget != nil {
+		c.allocator = &budgetAllocator{Allocator: memory.DefaultAllocator, budget: c.budget}
 	}
 	if plan == nil {
 		return errorPipeline(errors.New("plan is nil"))
@@ -30,6 +62,17 @@ func Run(ctx context.Context, cfg Config, plan *physical.Plan) Pipeline {
 	if err != nil {
 		return errorPipeline(err)
 	}
+
+	if c.runner != nil {
+		fragments, err := physical.SplitFragments(plan)
+		if err != nil {
+			return errorPipeline(err)
+		}
+		c.fragments = make(map[physical.Node]physical.Fragment, len(fragments))
+		for _, fragment := range fragments {
+			c.fragments[fragment.Root] = fragment
+		}
+	}
 	return c.execute(ctx, node)
 }
 
@@ -39,9 +82,45 @@ type Context struct {
 	plan      *physical.Plan
 	evaluator expressionEvaluator
 	bucket    objstore.Bucket
+
+	runner    FragmentRunner
+	fragments map[physical.Node]physical.Fragment // fragments by their root node
+
+	budget    *memoryBudget    // memory budget of the query, nil if unlimited
+	allocator memory.Allocator // allocator charging the budget, nil if unlimited
+	spillDir  string
+
+	analysis *Analysis // collects runtime statistics of the nodes, nil if disabled
+}
+
+// memoryConfig returns the memory configuration of aggregation pipelines.
+func (c *Context) memoryConfig() memoryConfig {
+	return memoryConfig{
+		budget:    c.budget,
+		allocator: c.allocator,
+		spillDir:  c.spillDir,
+	}
 }
 
 func (c *Context) execute(ctx context.Context, node physical.Node) Pipeline {
+	pipeline := c.executeNode(ctx, node)
+	if c.analysis != nil {
+		return c.analysis.observe(node, pipeline)
+	}
+	return pipeline
+}
+
+func (c *Context) executeNode(ctx context.Context, node physical.Node) Pipeline {
+	if fragment, ok := c.fragments[node]; ok {
+		return c.runner.RunFragment(ctx, fragment)
+	}
+
+	// The operands of a binary operation are not interchangeable, so its
+	// inputs cannot be created from the unordered children of the node.
+	if n, ok := node.(*physical.BinaryOperation); ok {
+		return c.executeBinaryOperation(ctx, n)
+	}
+
 	children := c.plan.Children(node)
 	inputs := make([]Pipeline, 0, len(children))
 	for _, child := range children {
@@ -63,6 +142,14 @@ func (c *Context) execute(ctx context.Context, node physical.Node) Pipeline {
 		return c.executeRangeAggregation(ctx, n, inputs)
 	case *physical.VectorAggregation:
 		return c.executeVectorAggregation(ctx, n, inputs)
+	case *physical.Parse:
+		return c.executeParse(ctx, n, inputs)
+	case *physical.LineFormat:
+		return c.executeLineFormat(ctx, n, inputs)
+	case *physical.LabelFormat:
+		return c.executeLabelFormat(ctx, n, inputs)
+	case *physical.ProjectLabels:
+		return c.executeProjectLabels(ctx, n, inputs)
 	default:
 		return errorPipeline(fmt.Errorf("invalid node type: %T", node))
 	}
@@ -88,11 +175,17 @@ func (c *Context) executeDataObjScan(ctx context.Context, node *physical.DataObj
 		return errorPipeline(fmt.Errorf("creating data object: %w", err))
 	}
 
+	rowRanges, err := c.matchRowRanges(ctx, node)
+	if err != nil {
+		return errorPipeline(err)
+	}
+
 	return newDataobjScanPipeline(dataobjScanOptions{
 		Object:      obj,
 		StreamIDs:   node.StreamIDs,
 		Section:     node.Section,
 		Predicates:  predicates,
+		RowRanges:   rowRanges,
 		Projections: node.Projections,
 
 		Direction: node.Direction,
@@ -102,12 +195,39 @@ func (c *Context) executeDataObjScan(ctx context.Context, node *physical.DataObj
 	})
 }
 
+// matchRowRanges looks up the rows of the section scanned by node which may
+// match the line filters of node in the token index of the section. It
+// returns nil if the rows can't be narrowed down, in which case all rows of
+// the section must be read.
+func (c *Context) matchRowRanges(ctx context.Context, node *physical.DataObjScan) ([]logs.RowRange, error) {
+	if node.Index == "" {
+		return nil, nil
+	}
+	substrings := requiredSubstrings(node.Predicates)
+	if len(substrings) == 0 {
+		return nil, nil
+	}
+
+	idx, err := dataobj.FromBucket(ctx, c.bucket, string(node.Index))
+	if err != nil {
+		return nil, fmt.Errorf("creating index object: %w", err)
+	}
+
+	rowRanges, ok, err := tokens.MatchSubstrings(ctx, idx, string(node.Location), int64(node.Section), substrings)
+	if err != nil {
+		return nil, fmt.Errorf("matching token index: %w", err)
+	} else if !ok {
+		return nil, nil
+	}
+	return rowRanges, nil
+}
+
 func (c *Context) executeSortMerge(_ context.Context, sortmerge *physical.SortMerge, inputs []Pipeline) Pipeline {
 	if len(inputs) == 0 {
 		return emptyPipeline()
 	}
 
-	pipeline, err := NewSortMergePipeline(inputs, sortmerge.Order, sortmerge.Column, c.evaluator)
+	pipeline, err := NewSortMergePipeline(inputs, sortmerge.Order, sortmerge.Column, c.evaluator, c.budget)
 	if err != nil {
 		return errorPipeline(err)
 	}
@@ -161,16 +281,22 @@ func (c *Context) executeProjection(_ context.Context, proj *physical.Projection
 }
 
 func (c *Context) executeRangeAggregation(_ context.Context, plan *physical.RangeAggregation, inputs []Pipeline) Pipeline {
-	if len(inputs) == 0 {
+	// absent_over_time yields a value for every step if there are no inputs.
+	if len(inputs) == 0 && plan.Operation != types.RangeAggregationTypeAbsent {
 		return emptyPipeline()
 	}
 
 	pipeline, err := NewRangeAggregationPipeline(inputs, c.evaluator, rangeAggregationOptions{
 		partitionBy:   plan.PartitionBy,
+		operation:     plan.Operation,
+		unwrap:        plan.Unwrap,
+		parameter:     plan.Parameter,
 		startTs:       plan.Start,
 		endTs:         plan.End,
 		rangeInterval: plan.Range,
 		step:          plan.Step,
+		absentLabels:  plan.AbsentLabels,
+		memory:        c.memoryConfig(),
 	})
 	if err != nil {
 		return errorPipeline(err)
@@ -184,10 +310,92 @@ func (c *Context) executeVectorAggregation(_ context.Context, plan *physical.Vec
 		return emptyPipeline()
 	}
 
-	pipeline, err := NewVectorAggregationPipeline(inputs, plan.GroupBy, c.evaluator)
+	pipeline, err := NewVectorAggregationPipeline(inputs, c.evaluator, vectorAggregationOptions{
+		groupBy:   plan.GroupBy,
+		without:   plan.Without,
+		operation: plan.Operation,
+		parameter: plan.Parameter,
+		memory:    c.memoryConfig(),
+	})
+	if err != nil {
+		return errorPipeline(err)
+	}
+
+	return pipeline
+}
+
+func (c *Context) executeParse(_ context.Context, parse *physical.Parse, inputs []Pipeline) Pipeline {
+	if len(inputs) == 0 {
+		return emptyPipeline()
+	}
+
+	if len(inputs) > 1 {
+		return errorPipeline(fmt.Errorf("parse expects exactly one input, got %d", len(inputs)))
+	}
+
+	pipeline, err := NewParsePipeline(parse, inputs[0])
+	if err != nil {
+		return errorPipeline(err)
+	}
+	return pipeline
+}
+
+func (c *Context) executeLineFormat(_ context.Context, format *physical.LineFormat, inputs []Pipeline) Pipeline {
+	if len(inputs) == 0 {
+		return emptyPipeline()
+	}
+
+	if len(inputs) > 1 {
+		return errorPipeline(fmt.Errorf("line format expects exactly one input, got %d", len(inputs)))
+	}
+
+	pipeline, err := NewLineFormatPipeline(format, inputs[0])
+	if err != nil {
+		return errorPipeline(err)
+	}
+	return pipeline
+}
+
+func (c *Context) executeLabelFormat(_ context.Context, format *physical.LabelFormat, inputs []Pipeline) Pipeline {
+	if len(inputs) == 0 {
+		return emptyPipeline()
+	}
+
+	if len(inputs) > 1 {
+		return errorPipeline(fmt.Errorf("label format expects exactly one input, got %d", len(inputs)))
+	}
+
+	pipeline, err := NewLabelFormatPipeline(format, inputs[0])
 	if err != nil {
 		return errorPipeline(err)
 	}
+	return pipeline
+}
 
+func (c *Context) executeProjectLabels(_ context.Context, project *physical.ProjectLabels, inputs []Pipeline) Pipeline {
+	if len(inputs) == 0 {
+		return emptyPipeline()
+	}
+
+	if len(inputs) > 1 {
+		return errorPipeline(fmt.Errorf("project labels expects exactly one input, got %d", len(inputs)))
+	}
+
+	return NewProjectLabelsPipeline(project, inputs[0])
+}
+
+func (c *Context) executeBinaryOperation(ctx context.Context, binop *physical.BinaryOperation) Pipeline {
+	var left, right Pipeline
+	if binop.Left != nil {
+		left = c.execute(ctx, binop.Left)
+	}
+	if binop.Right != nil {
+		right = c.execute(ctx, binop.Right)
+	}
+
+	pipeline, err := NewBinaryOperationPipeline(binop, left, right, c.evaluator)
+	if err != nil {
+		return errorPipeline(err)
+	}
 	return pipeline
 }
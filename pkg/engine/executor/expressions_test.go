@@ -213,6 +213,57 @@ func TestEvaluateBinaryExpression(t *testing.T) {
 	})
 }
 
+func TestEvaluateUnaryExpression(t *testing.T) {
+	rec, err := CSVToArrow(fields, sampledata)
+	require.NoError(t, err)
+	defer rec.Release()
+
+	e := expressionEvaluator{}
+
+	t.Run("error if function for signature is not registered", func(t *testing.T) {
+		expr := &physical.UnaryExpr{
+			Left: &physical.ColumnExpr{
+				Ref: types.ColumnRef{Column: "name", Type: types.ColumnTypeBuiltin},
+			},
+			Op: types.UnaryOpNot,
+		}
+
+		_, err := e.eval(expr, rec)
+		require.ErrorContains(t, err, "failed to lookup unary function: not implemented")
+	})
+
+	t.Run("NOT(GT(float,float))", func(t *testing.T) {
+		expr := &physical.UnaryExpr{
+			Left: &physical.BinaryExpr{
+				Left: &physical.ColumnExpr{
+					Ref: types.ColumnRef{Column: "value", Type: types.ColumnTypeBuiltin},
+				},
+				Right: physical.NewLiteral(0.5),
+				Op:    types.BinaryOpGt,
+			},
+			Op: types.UnaryOpNot,
+		}
+
+		res, err := e.eval(expr, rec)
+		require.NoError(t, err)
+		result := collectBooleanColumnVector(res)
+		require.Equal(t, []bool{true, false, true, false, true, false, false, true, false, true}, result)
+	})
+
+	t.Run("NEG(float)", func(t *testing.T) {
+		expr := &physical.UnaryExpr{
+			Left: physical.NewLiteral(0.5),
+			Op:   types.UnaryOpNeg,
+		}
+
+		res, err := e.eval(expr, rec)
+		require.NoError(t, err)
+		require.Equal(t, datatype.Loki.Float, res.Type())
+		require.Equal(t, rec.NumRows(), res.Len())
+		require.Equal(t, -0.5, res.Value(0))
+	})
+}
+
 func collectBooleanColumnVector(vec ColumnVector) []bool {
 	res := make([]bool, 0, vec.Len())
 	arr := vec.ToArray().(*array.Boolean)
@@ -1,6 +1,8 @@
 package executor
 
 import (
+	"context"
+	"errors"
 	"testing"
 
 	"github.com/apache/arrow-go/v18/arrow"
@@ -271,4 +273,33 @@ Charlie,true
 		// Assert that the pipelines produce equal results
 		AssertPipelinesEqual(t, filterPipeline, expectedPipeline)
 	})
+
+	t.Run("filter with numeric comparison on non-numeric metadata", func(t *testing.T) {
+		// Label filters keep rows whose values cannot be parsed as a number
+		// and mark them with an error label. Until the Filter supports this,
+		// rows must not be dropped silently.
+		fields := []arrow.Field{
+			{Name: "status", Type: arrow.BinaryTypes.String, Metadata: datatype.ColumnMetadata(types.ColumnTypeMetadata, datatype.Loki.String)},
+		}
+		inputRecord, err := CSVToArrow(fields, "500\nabc")
+		require.NoError(t, err)
+		defer inputRecord.Release()
+
+		filter := &physical.Filter{
+			Predicates: []physical.Expression{
+				&physical.BinaryExpr{
+					Left:  &physical.ColumnExpr{Ref: types.ColumnRef{Column: "status", Type: types.ColumnTypeMetadata}},
+					Right: physical.NewLiteral(int64(499)),
+					Op:    types.BinaryOpGt,
+				},
+			},
+		}
+
+		filterPipeline := NewFilterPipeline(filter, NewBufferedPipeline(inputRecord), expressionEvaluator{})
+		defer filterPipeline.Close()
+
+		err = filterPipeline.Read(context.Background())
+		require.Error(t, err)
+		require.False(t, errors.Is(err, EOF))
+	})
 }
@@ -0,0 +1,38 @@
+package executor
+
+import (
+	"github.com/grafana/loki/v3/pkg/engine/planner/physical"
+	"github.com/grafana/loki/v3/pkg/logql/log"
+)
+
+// NewLineFormatPipeline returns a pipeline that rewrites the log line of each
+// row of its input using the template of the given node.
+func NewLineFormatPipeline(format *physical.LineFormat, input Pipeline) (*GenericPipeline, error) {
+	stage, err := log.NewFormatter(format.Template)
+	if err != nil {
+		return nil, err
+	}
+	return newLogStagePipeline(stage, input), nil
+}
+
+// NewLabelFormatPipeline returns a pipeline that renames or formats the labels
+// of each row of its input as defined by the given node.
+func NewLabelFormatPipeline(format *physical.LabelFormat, input Pipeline) (*GenericPipeline, error) {
+	stage, err := log.NewLabelsFormatter(format.Formats)
+	if err != nil {
+		return nil, err
+	}
+	return newLogStagePipeline(stage, input), nil
+}
+
+// NewProjectLabelsPipeline returns a pipeline that keeps or drops the labels
+// of each row of its input as defined by the given node.
+func NewProjectLabelsPipeline(project *physical.ProjectLabels, input Pipeline) *GenericPipeline {
+	var stage log.Stage
+	if project.Drop {
+		stage = log.NewDropLabels(project.Labels)
+	} else {
+		stage = log.NewKeepLabels(project.Labels)
+	}
+	return newLogStagePipeline(stage, input)
+}
@@ -0,0 +1,162 @@
+package executor
+
+import (
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/engine/internal/types"
+	"github.com/grafana/loki/v3/pkg/engine/planner/physical"
+	"github.com/grafana/loki/v3/pkg/logql/log"
+)
+
+// readOne reads a single record from the pipeline.
+func readOne(t *testing.T, pipeline Pipeline) arrow.Record {
+	t.Helper()
+	require.NoError(t, pipeline.Read(t.Context()))
+	record, err := pipeline.Value()
+	require.NoError(t, err)
+	return record
+}
+
+// columnValues returns the values of the column with the given name and
+// column type. Null values are represented as nil.
+func columnValues(t *testing.T, record arrow.Record, name string, ct types.ColumnType) []*string {
+	t.Helper()
+	for i, field := range record.Schema().Fields() {
+		fieldCt, _ := field.Metadata.GetValue(types.MetadataKeyColumnType)
+		if field.Name != name || fieldCt != ct.String() {
+			continue
+		}
+
+		col := record.Column(i).(*array.String)
+		values := make([]*string, col.Len())
+		for row := range col.Len() {
+			if col.IsValid(row) {
+				v := col.Value(row)
+				values[row] = &v
+			}
+		}
+		return values
+	}
+	t.Fatalf("column %s.%s not found", ct, name)
+	return nil
+}
+
+func messages(record arrow.Record) []string {
+	for i, field := range record.Schema().Fields() {
+		if field.Name == types.ColumnNameBuiltinMessage {
+			col := record.Column(i).(*array.String)
+			values := make([]string, col.Len())
+			for row := range col.Len() {
+				values[row] = col.Value(row)
+			}
+			return values
+		}
+	}
+	return nil
+}
+
+func TestLineFormatPipeline(t *testing.T) {
+	record := newLogRecord(t, []string{"info", "error"}, []string{`status=200 msg=hello`, `status=500`})
+
+	logfmt, err := NewParsePipeline(&physical.Parse{Kind: types.ParserTypeLogfmt}, NewBufferedPipeline(record))
+	require.NoError(t, err)
+
+	format, err := NewLineFormatPipeline(&physical.LineFormat{Template: `{{.level | ToUpper}} {{.status}} {{.msg | default "-"}} {{__line__}}`}, logfmt)
+	require.NoError(t, err)
+	defer format.Close()
+
+	result := readOne(t, format)
+	require.Equal(t, []string{
+		"INFO 200 hello status=200 msg=hello",
+		"ERROR 500 - status=500",
+	}, messages(result))
+
+	// labels are not modified
+	require.Equal(t, []*string{ptr("info"), ptr("error")}, columnValues(t, result, "level", types.ColumnTypeLabel))
+	require.Equal(t, []*string{ptr("200"), ptr("500")}, columnValues(t, result, "status", types.ColumnTypeParsed))
+}
+
+func TestLineFormatPipeline_InvalidTemplate(t *testing.T) {
+	_, err := NewLineFormatPipeline(&physical.LineFormat{Template: `{{.foo`}, emptyPipeline())
+	require.Error(t, err)
+}
+
+func TestLabelFormatPipeline(t *testing.T) {
+	record := newLogRecord(t, []string{"info", "error"}, []string{`status=200`, `status=500`})
+
+	logfmt, err := NewParsePipeline(&physical.Parse{Kind: types.ParserTypeLogfmt}, NewBufferedPipeline(record))
+	require.NoError(t, err)
+
+	format, err := NewLabelFormatPipeline(&physical.LabelFormat{Formats: []log.LabelFmt{
+		log.NewRenameLabelFmt("severity", "level"),
+		log.NewTemplateLabelFmt("status", `{{ if eq .status "500" }}failed{{ else }}ok{{ end }}`),
+	}}, logfmt)
+	require.NoError(t, err)
+	defer format.Close()
+
+	result := readOne(t, format)
+
+	// the renamed stream label is removed and added as parsed label
+	require.Equal(t, []*string{nil, nil}, columnValues(t, result, "level", types.ColumnTypeLabel))
+	require.Equal(t, []*string{ptr("info"), ptr("error")}, columnValues(t, result, "severity", types.ColumnTypeParsed))
+	require.Equal(t, []*string{ptr("ok"), ptr("failed")}, columnValues(t, result, "status", types.ColumnTypeParsed))
+	require.Equal(t, []string{`status=200`, `status=500`}, messages(result))
+}
+
+func TestProjectLabelsPipeline(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		project  *physical.ProjectLabels
+		level    []*string
+		status   []*string
+		duration []*string
+	}{
+		{
+			name: "keep",
+			project: &physical.ProjectLabels{Labels: []log.NamedLabelMatcher{
+				log.NewNamedLabelMatcher(nil, "status"),
+			}},
+			level:    []*string{nil, nil},
+			status:   []*string{ptr("200"), ptr("500")},
+			duration: []*string{nil, nil},
+		},
+		{
+			name: "drop",
+			project: &physical.ProjectLabels{Drop: true, Labels: []log.NamedLabelMatcher{
+				log.NewNamedLabelMatcher(nil, "status"),
+			}},
+			level:    []*string{ptr("info"), ptr("error")},
+			status:   []*string{nil, nil},
+			duration: []*string{ptr("10ms"), ptr("20ms")},
+		},
+		{
+			name: "drop with matcher",
+			project: &physical.ProjectLabels{Drop: true, Labels: []log.NamedLabelMatcher{
+				log.NewNamedLabelMatcher(labels.MustNewMatcher(labels.MatchEqual, "level", "error"), ""),
+			}},
+			level:    []*string{ptr("info"), nil},
+			status:   []*string{ptr("200"), ptr("500")},
+			duration: []*string{ptr("10ms"), ptr("20ms")},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			record := newLogRecord(t, []string{"info", "error"}, []string{`status=200 duration=10ms`, `status=500 duration=20ms`})
+
+			logfmt, err := NewParsePipeline(&physical.Parse{Kind: types.ParserTypeLogfmt}, NewBufferedPipeline(record))
+			require.NoError(t, err)
+
+			project := NewProjectLabelsPipeline(tt.project, logfmt)
+			defer project.Close()
+
+			result := readOne(t, project)
+			require.Equal(t, tt.level, columnValues(t, result, "level", types.ColumnTypeLabel))
+			require.Equal(t, tt.status, columnValues(t, result, "status", types.ColumnTypeParsed))
+			require.Equal(t, tt.duration, columnValues(t, result, "duration", types.ColumnTypeParsed))
+		})
+	}
+}
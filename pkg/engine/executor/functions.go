@@ -1,13 +1,17 @@
 package executor
 
 import (
+	"math"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/apache/arrow-go/v18/arrow"
 	"github.com/apache/arrow-go/v18/arrow/array"
 	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/dustin/go-humanize"
 
+	"github.com/grafana/loki/v3/pkg/engine/internal/datatype"
 	"github.com/grafana/loki/v3/pkg/engine/internal/errors"
 	"github.com/grafana/loki/v3/pkg/engine/internal/types"
 )
@@ -80,6 +84,38 @@ func init() {
 	}})
 }
 
+func init() {
+	// Functions for [types.UnaryOpNot]
+	unaryFunctions.register(types.UnaryOpNot, arrow.FixedWidthTypes.Boolean, &unaryFunction[*array.Boolean, bool, bool]{dt: datatype.Loki.Bool, eval: func(v bool) (bool, bool) { return !v, true }})
+	// Functions for [types.UnaryOpNeg]
+	unaryFunctions.register(types.UnaryOpNeg, arrow.PrimitiveTypes.Int64, &unaryFunction[*array.Int64, int64, int64]{dt: datatype.Loki.Integer, eval: func(v int64) (int64, bool) { return -v, true }})
+	unaryFunctions.register(types.UnaryOpNeg, arrow.PrimitiveTypes.Float64, &unaryFunction[*array.Float64, float64, float64]{dt: datatype.Loki.Float, eval: func(v float64) (float64, bool) { return -v, true }})
+	// Functions for [types.UnaryOpAbs]
+	unaryFunctions.register(types.UnaryOpAbs, arrow.PrimitiveTypes.Int64, &unaryFunction[*array.Int64, int64, int64]{dt: datatype.Loki.Integer, eval: func(v int64) (int64, bool) {
+		if v < 0 {
+			return -v, true
+		}
+		return v, true
+	}})
+	unaryFunctions.register(types.UnaryOpAbs, arrow.PrimitiveTypes.Float64, &unaryFunction[*array.Float64, float64, float64]{dt: datatype.Loki.Float, eval: func(v float64) (float64, bool) { return math.Abs(v), true }})
+	// Functions for [types.UnaryOpCastBytes]
+	unaryFunctions.register(types.UnaryOpCastBytes, arrow.BinaryTypes.String, &unaryFunction[*array.String, string, float64]{dt: datatype.Loki.Float, eval: func(v string) (float64, bool) {
+		b, err := humanize.ParseBytes(v)
+		if err != nil {
+			return 0, false
+		}
+		return float64(b), true
+	}})
+	// Functions for [types.UnaryOpCastDuration]
+	unaryFunctions.register(types.UnaryOpCastDuration, arrow.BinaryTypes.String, &unaryFunction[*array.String, string, float64]{dt: datatype.Loki.Float, eval: func(v string) (float64, bool) {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return 0, false
+		}
+		return d.Seconds(), true
+	}})
+}
+
 type UnaryFunctionRegistry interface {
 	register(types.UnaryOp, arrow.DataType, UnaryFunction)
 	GetForSignature(types.UnaryOp, arrow.DataType) (UnaryFunction, error)
@@ -106,8 +142,18 @@ func (u *unaryFuncReg) register(op types.UnaryOp, ltype arrow.DataType, f UnaryF
 }
 
 // GetForSignature implements UnaryFunctionRegistry.
-func (u *unaryFuncReg) GetForSignature(types.UnaryOp, arrow.DataType) (UnaryFunction, error) {
-	return nil, errors.ErrNotImplemented
+func (u *unaryFuncReg) GetForSignature(op types.UnaryOp, ltype arrow.DataType) (UnaryFunction, error) {
+	// Get registered functions for the specific operation
+	reg, ok := u.reg[op]
+	if !ok {
+		return nil, errors.ErrNotImplemented
+	}
+	// Get registered function for the specific data type
+	fn, ok := reg[ltype]
+	if !ok {
+		return nil, errors.ErrNotImplemented
+	}
+	return fn, nil
 }
 
 type BinaryFunctionRegistry interface {
@@ -200,7 +246,61 @@ func (f *genericFunction[E, T]) Evaluate(lhs ColumnVector, rhs ColumnVector) (Co
 		builder.Append(res)
 	}
 
-	return &Array{array: builder.NewArray()}, nil
+	return &Array{
+		array: builder.NewArray(),
+		dt:    datatype.Loki.Bool,
+		ct:    types.ColumnTypeGenerated,
+		rows:  lhs.Len(),
+	}, nil
+}
+
+// unaryFunction is a struct that implements the [UnaryFunction] interface and
+// can be used for any array type with comparable elements.
+// Null values of the input remain null. Values that cannot be evaluated, such
+// as strings that cannot be converted into a number, result in null values.
+type unaryFunction[E arrayType[T], T comparable, R bool | int64 | float64] struct {
+	dt   datatype.DataType // data type of the result
+	eval func(v T) (R, bool)
+}
+
+// Evaluate implements UnaryFunction.
+func (f *unaryFunction[E, T, R]) Evaluate(lhs ColumnVector) (ColumnVector, error) {
+	arr, ok := lhs.ToArray().(E)
+	if !ok {
+		return nil, arrow.ErrType
+	}
+
+	mem := memory.NewGoAllocator()
+	builder := array.NewBuilder(mem, f.dt.ArrowType())
+	defer builder.Release()
+
+	for i := 0; i < int(lhs.Len()); i++ {
+		if arr.IsNull(i) {
+			builder.AppendNull()
+			continue
+		}
+		res, ok := f.eval(arr.Value(i))
+		if !ok {
+			builder.AppendNull()
+			continue
+		}
+
+		switch builder := builder.(type) {
+		case *array.BooleanBuilder:
+			builder.Append(any(res).(bool))
+		case *array.Int64Builder:
+			builder.Append(any(res).(int64))
+		case *array.Float64Builder:
+			builder.Append(any(res).(float64))
+		}
+	}
+
+	return &Array{
+		array: builder.NewArray(),
+		dt:    f.dt,
+		ct:    types.ColumnTypeGenerated,
+		rows:  lhs.Len(),
+	}, nil
 }
 
 // Compiler optimized version of converting boolean b into an integer of value 0 or 1
@@ -837,3 +837,133 @@ func TestEmptyArrays(t *testing.T) {
 
 	assert.Equal(t, int64(0), result.Len())
 }
+
+func TestUnaryFunctionRegistry_GetForSignature(t *testing.T) {
+	tests := []struct {
+		name        string
+		op          types.UnaryOp
+		dataType    arrow.DataType
+		expectError bool
+	}{
+		{
+			name:     "valid not operation for boolean",
+			op:       types.UnaryOpNot,
+			dataType: arrow.FixedWidthTypes.Boolean,
+		},
+		{
+			name:     "valid negation for int64",
+			op:       types.UnaryOpNeg,
+			dataType: arrow.PrimitiveTypes.Int64,
+		},
+		{
+			name:     "valid negation for float64",
+			op:       types.UnaryOpNeg,
+			dataType: arrow.PrimitiveTypes.Float64,
+		},
+		{
+			name:     "valid bytes conversion for string",
+			op:       types.UnaryOpCastBytes,
+			dataType: arrow.BinaryTypes.String,
+		},
+		{
+			name:     "valid duration conversion for string",
+			op:       types.UnaryOpCastDuration,
+			dataType: arrow.BinaryTypes.String,
+		},
+		{
+			name:        "invalid data type for operation",
+			op:          types.UnaryOpNot,
+			dataType:    arrow.BinaryTypes.String, // Not registered
+			expectError: true,
+		},
+		{
+			name:        "invalid operation",
+			op:          types.UnaryOpInvalid, // Not registered
+			dataType:    arrow.FixedWidthTypes.Boolean,
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fn, err := unaryFunctions.GetForSignature(tt.op, tt.dataType)
+			if tt.expectError {
+				require.Error(t, err)
+				require.Nil(t, fn)
+			} else {
+				require.NoError(t, err)
+				require.NotNil(t, fn)
+			}
+		})
+	}
+}
+
+func TestUnaryFunctions(t *testing.T) {
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer mem.AssertSize(t, 0)
+
+	tests := []struct {
+		name     string
+		op       types.UnaryOp
+		input    func() *Array
+		expected []any // nil for null values
+	}{
+		{
+			name:     "not",
+			op:       types.UnaryOpNot,
+			input:    func() *Array { return createBoolArray(mem, []bool{true, false, true}, []bool{false, false, true}) },
+			expected: []any{false, true, nil},
+		},
+		{
+			name:     "negate int64",
+			op:       types.UnaryOpNeg,
+			input:    func() *Array { return createInt64Array(mem, []int64{1, -2, 0}, nil) },
+			expected: []any{int64(-1), int64(2), int64(0)},
+		},
+		{
+			name:     "negate float64",
+			op:       types.UnaryOpNeg,
+			input:    func() *Array { return createFloat64Array(mem, []float64{1.5, -2, 0}, []bool{false, false, true}) },
+			expected: []any{-1.5, 2.0, nil},
+		},
+		{
+			name:     "abs float64",
+			op:       types.UnaryOpAbs,
+			input:    func() *Array { return createFloat64Array(mem, []float64{1.5, -2}, nil) },
+			expected: []any{1.5, 2.0},
+		},
+		{
+			name: "bytes conversion",
+			op:   types.UnaryOpCastBytes,
+			input: func() *Array {
+				return createStringArray(mem, []string{"1KB", "2 MiB", "42", "foo", ""}, []bool{false, false, false, false, true})
+			},
+			expected: []any{1000.0, 2097152.0, 42.0, nil, nil},
+		},
+		{
+			name:     "duration conversion",
+			op:       types.UnaryOpCastDuration,
+			input:    func() *Array { return createStringArray(mem, []string{"250ms", "1m30s", "10", "foo"}, nil) },
+			expected: []any{0.25, 90.0, nil, nil},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := tt.input()
+			defer input.array.Release()
+
+			fn, err := unaryFunctions.GetForSignature(tt.op, input.Type().ArrowType())
+			require.NoError(t, err)
+
+			result, err := fn.Evaluate(input)
+			require.NoError(t, err)
+			defer result.ToArray().Release()
+
+			require.Equal(t, int64(len(tt.expected)), result.Len())
+			for i, expected := range tt.expected {
+				require.Equal(t, expected, result.Value(i), "row %d", i)
+			}
+		})
+	}
+}
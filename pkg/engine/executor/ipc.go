@@ -0,0 +1,162 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+
+	"github.com/grafana/loki/v3/pkg/engine/planner/physical"
+)
+
+// ExecuteFragment decodes a plan fragment that was encoded with
+// [physical.MarshalPlan], executes it and writes its results to w in the Arrow
+// IPC streaming format. The results can be read with [NewIPCPipeline].
+func ExecuteFragment(ctx context.Context, cfg Config, data []byte, w io.Writer) error {
+	plan, err := physical.UnmarshalPlan(data)
+	if err != nil {
+		return err
+	}
+
+	// Fragments are executed where they are received and must not be split
+	// any further.
+	cfg.Fragments = nil
+
+	pipeline := Run(ctx, cfg, plan)
+	defer pipeline.Close()
+	return WriteIPC(ctx, w, pipeline)
+}
+
+// WriteIPC reads all records from the pipeline and writes them to w in the
+// Arrow IPC streaming format.
+//
+// A stream can only contain records of a single schema, so a new stream is
+// started whenever the schema of the records changes.
+func WriteIPC(ctx context.Context, w io.Writer, p Pipeline) error {
+	var (
+		writer *ipc.Writer
+		schema *arrow.Schema
+	)
+	defer func() {
+		if writer != nil {
+			_ = writer.Close()
+		}
+	}()
+
+	for {
+		if err := p.Read(ctx); errors.Is(err, EOF) {
+			break
+		} else if err != nil {
+			return err
+		}
+		rec, err := p.Value()
+		if err != nil {
+			return err
+		}
+
+		if writer != nil && !schema.Equal(rec.Schema()) {
+			if err := writer.Close(); err != nil {
+				return fmt.Errorf("closing IPC stream: %w", err)
+			}
+			writer = nil
+		}
+		if writer == nil {
+			schema = rec.Schema()
+			writer = ipc.NewWriter(w, ipc.WithSchema(schema), ipc.WithAllocator(memory.DefaultAllocator))
+		}
+		if err := writer.Write(rec); err != nil {
+			return fmt.Errorf("writing record: %w", err)
+		}
+	}
+
+	if writer == nil {
+		return nil
+	}
+	err := writer.Close()
+	writer = nil
+	return err
+}
+
+// ipcPipeline is a [Pipeline] that reads records from one or more consecutive
+// Arrow IPC streams, as written by [WriteIPC].
+type ipcPipeline struct {
+	r      io.ReadCloser
+	reader *ipc.Reader
+	state  state
+}
+
+var _ Pipeline = (*ipcPipeline)(nil)
+
+// NewIPCPipeline returns a pipeline that reads the records from r, for
+// example the results of a fragment that was executed by another querier with
+// [ExecuteFragment]. The pipeline closes r when it is closed.
+func NewIPCPipeline(r io.ReadCloser) Pipeline {
+	return &ipcPipeline{r: r}
+}
+
+// Read implements [Pipeline].
+func (p *ipcPipeline) Read(ctx context.Context) error {
+	p.state = p.read(ctx)
+	return p.state.err
+}
+
+func (p *ipcPipeline) read(ctx context.Context) state {
+	for {
+		if err := ctx.Err(); err != nil {
+			return failureState(err)
+		}
+
+		if p.reader == nil {
+			reader, err := ipc.NewReader(p.r, ipc.WithAllocator(memory.DefaultAllocator))
+			if errors.Is(err, io.EOF) {
+				return Exhausted
+			} else if err != nil {
+				return failureState(fmt.Errorf("reading IPC stream: %w", err))
+			}
+			p.reader = reader
+		}
+
+		if p.reader.Next() {
+			// The record is only valid until the next call to Next, so it is
+			// retained for the consumer of the pipeline.
+			rec := p.reader.Record()
+			rec.Retain()
+			return successState(rec)
+		}
+		if err := p.reader.Err(); err != nil && !errors.Is(err, io.EOF) {
+			return failureState(fmt.Errorf("reading record: %w", err))
+		}
+
+		// The current stream is exhausted, continue with the next one.
+		p.reader.Release()
+		p.reader = nil
+	}
+}
+
+// Value implements [Pipeline].
+func (p *ipcPipeline) Value() (arrow.Record, error) {
+	return p.state.Value()
+}
+
+// Close implements [Pipeline].
+func (p *ipcPipeline) Close() {
+	if p.reader != nil {
+		p.reader.Release()
+		p.reader = nil
+	}
+	_ = p.r.Close()
+}
+
+// Inputs implements [Pipeline].
+func (p *ipcPipeline) Inputs() []Pipeline {
+	return nil
+}
+
+// Transport implements [Pipeline].
+func (p *ipcPipeline) Transport() Transport {
+	return Remote
+}
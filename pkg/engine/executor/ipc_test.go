@@ -0,0 +1,77 @@
+package executor
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/engine/internal/datatype"
+	"github.com/grafana/loki/v3/pkg/engine/internal/types"
+)
+
+func TestIPCPipeline(t *testing.T) {
+	fields := []arrow.Field{
+		{Name: "name", Type: datatype.Arrow.String, Metadata: datatype.ColumnMetadataBuiltinMessage, Nullable: true},
+		{Name: "age", Type: datatype.Arrow.Integer, Metadata: datatype.ColumnMetadata(types.ColumnTypeMetadata, datatype.Loki.Integer), Nullable: true},
+	}
+	otherFields := []arrow.Field{
+		{Name: "name", Type: datatype.Arrow.String, Metadata: datatype.ColumnMetadataBuiltinMessage, Nullable: true},
+	}
+
+	newRecords := func() []arrow.Record {
+		first, err := CSVToArrow(fields, "Alice,30\nBob,25")
+		require.NoError(t, err)
+		second, err := CSVToArrow(fields, "Charlie,35")
+		require.NoError(t, err)
+		third, err := CSVToArrow(otherFields, "Dave")
+		require.NoError(t, err)
+		return []arrow.Record{first, second, third}
+	}
+
+	var buf bytes.Buffer
+	err := WriteIPC(t.Context(), &buf, NewBufferedPipeline(newRecords()...))
+	require.NoError(t, err)
+
+	actual := NewIPCPipeline(io.NopCloser(&buf))
+	require.Equal(t, Remote, actual.Transport())
+
+	for _, rec := range newRecords() {
+		require.NoError(t, actual.Read(t.Context()))
+		batch, err := actual.Value()
+		require.NoError(t, err)
+		require.True(t, rec.Schema().Equal(batch.Schema()))
+		require.Equal(t, rec.NumRows(), batch.NumRows())
+	}
+	require.ErrorIs(t, actual.Read(t.Context()), EOF)
+	actual.Close()
+
+	t.Run("rows are preserved", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := WriteIPC(t.Context(), &buf, NewBufferedPipeline(newRecords()[:2]...))
+		require.NoError(t, err)
+
+		expected := NewBufferedPipeline(newRecords()[:2]...)
+		AssertPipelinesEqual(t, expected, NewIPCPipeline(io.NopCloser(&buf)))
+	})
+
+	t.Run("empty pipeline", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := WriteIPC(t.Context(), &buf, emptyPipeline())
+		require.NoError(t, err)
+		require.Zero(t, buf.Len())
+
+		actual := NewIPCPipeline(io.NopCloser(&buf))
+		defer actual.Close()
+		require.ErrorIs(t, actual.Read(t.Context()), EOF)
+	})
+}
+
+func TestExecuteFragment_InvalidPlan(t *testing.T) {
+	var buf bytes.Buffer
+	err := ExecuteFragment(t.Context(), Config{}, []byte("invalid"), &buf)
+	require.ErrorContains(t, err, "decoding plan")
+	require.Zero(t, buf.Len())
+}
@@ -0,0 +1,108 @@
+package executor
+
+import (
+	"sync/atomic"
+
+	"github.com/apache/arrow-go/v18/arrow/memory"
+
+	"github.com/grafana/loki/v3/pkg/logqlmodel"
+)
+
+// memoryBudget tracks the memory used by the pipelines of a single query
+// against a limit. A nil memoryBudget is unlimited.
+type memoryBudget struct {
+	limit int64
+	used  atomic.Int64
+}
+
+// newMemoryBudget creates a new memoryBudget with the given limit in bytes.
+// It returns nil if limit is not positive.
+func newMemoryBudget(limit int64) *memoryBudget {
+	if limit <= 0 {
+		return nil
+	}
+	return &memoryBudget{limit: limit}
+}
+
+// reserve reserves n bytes of the budget. It returns false without reserving
+// anything if the reservation would exceed the limit.
+func (b *memoryBudget) reserve(n int64) bool {
+	if b == nil {
+		return true
+	}
+	for {
+		used := b.used.Load()
+		if used+n > b.limit {
+			return false
+		}
+		if b.used.CompareAndSwap(used, used+n) {
+			return true
+		}
+	}
+}
+
+// charge unconditionally charges n bytes to the budget, even if that exceeds
+// the limit. A negative n returns bytes to the budget.
+func (b *memoryBudget) charge(n int64) {
+	if b == nil {
+		return
+	}
+	b.used.Add(n)
+}
+
+// release returns n bytes of a previous reservation to the budget.
+func (b *memoryBudget) release(n int64) {
+	b.charge(-n)
+}
+
+// limitError returns the error reported when a query exceeds its memory
+// budget and its state can't be spilled to disk.
+func (b *memoryBudget) limitError() error {
+	return logqlmodel.NewMemoryLimitError(b.limit)
+}
+
+// budgetAllocator is a [memory.Allocator] which charges the memory of Arrow
+// buffers to a [memoryBudget].
+//
+// Arrow allocations can't fail, so allocations exceeding the budget still
+// succeed. They leave less of the budget for the state of pipelines, which
+// causes them to spill sooner.
+type budgetAllocator struct {
+	memory.Allocator
+	budget *memoryBudget
+}
+
+var _ memory.Allocator = (*budgetAllocator)(nil)
+
+// Allocate implements [memory.Allocator].
+func (a *budgetAllocator) Allocate(size int) []byte {
+	a.budget.charge(int64(size))
+	return a.Allocator.Allocate(size)
+}
+
+// Reallocate implements [memory.Allocator].
+func (a *budgetAllocator) Reallocate(size int, b []byte) []byte {
+	a.budget.charge(int64(size - len(b)))
+	return a.Allocator.Reallocate(size, b)
+}
+
+// Free implements [memory.Allocator].
+func (a *budgetAllocator) Free(b []byte) {
+	a.budget.charge(-int64(len(b)))
+	a.Allocator.Free(b)
+}
+
+// memoryConfig configures how a pipeline accounts for the memory of its state.
+type memoryConfig struct {
+	budget    *memoryBudget    // Budget to reserve memory from; nil means unlimited.
+	allocator memory.Allocator // Allocator for the records built by the pipeline; nil means the default allocator.
+	spillDir  string           // Directory to spill state to; spilling is disabled if empty.
+}
+
+// alloc returns the allocator for the records built by the pipeline.
+func (c memoryConfig) alloc() memory.Allocator {
+	if c.allocator == nil {
+		return memory.DefaultAllocator
+	}
+	return c.allocator
+}
@@ -0,0 +1,57 @@
+package executor
+
+import (
+	"testing"
+
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/logqlmodel"
+)
+
+func TestMemoryBudget(t *testing.T) {
+	t.Run("unlimited", func(t *testing.T) {
+		budget := newMemoryBudget(0)
+		require.Nil(t, budget)
+		require.True(t, budget.reserve(1<<40))
+		budget.release(1 << 40) // must not panic
+	})
+
+	t.Run("limited", func(t *testing.T) {
+		budget := newMemoryBudget(100)
+		require.True(t, budget.reserve(60))
+		require.False(t, budget.reserve(50))
+		require.Equal(t, int64(60), budget.used.Load())
+
+		budget.release(20)
+		require.True(t, budget.reserve(50))
+		require.Equal(t, int64(90), budget.used.Load())
+
+		// charging can exceed the limit, which fails further reservations
+		budget.charge(20)
+		require.False(t, budget.reserve(1))
+	})
+
+	t.Run("limit error", func(t *testing.T) {
+		err := newMemoryBudget(100).limitError()
+		require.ErrorIs(t, err, logqlmodel.ErrLimit)
+		require.ErrorContains(t, err, "maximum memory (100 bytes)")
+	})
+}
+
+func TestBudgetAllocator(t *testing.T) {
+	budget := newMemoryBudget(1024)
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer mem.AssertSize(t, 0)
+
+	alloc := &budgetAllocator{Allocator: mem, budget: budget}
+
+	b := alloc.Allocate(100)
+	require.Equal(t, int64(100), budget.used.Load())
+
+	b = alloc.Reallocate(200, b)
+	require.Equal(t, int64(200), budget.used.Load())
+
+	alloc.Free(b)
+	require.Equal(t, int64(0), budget.used.Load())
+}
@@ -0,0 +1,45 @@
+package executor
+
+import (
+	"fmt"
+
+	"github.com/grafana/loki/v3/pkg/engine/internal/types"
+	"github.com/grafana/loki/v3/pkg/engine/planner/physical"
+	"github.com/grafana/loki/v3/pkg/logql/log"
+)
+
+// NewParsePipeline returns a pipeline that applies the parser of the given
+// node to the log line of each row of its input. The extracted labels are
+// added as parsed columns to the output records.
+func NewParsePipeline(parse *physical.Parse, input Pipeline) (*GenericPipeline, error) {
+	stage, err := newParserStage(parse)
+	if err != nil {
+		return nil, err
+	}
+	return newLogStagePipeline(stage, input), nil
+}
+
+// newParserStage creates the parser stage of the existing engine that
+// corresponds to the given node.
+func newParserStage(parse *physical.Parse) (log.Stage, error) {
+	switch parse.Kind {
+	case types.ParserTypeLogfmt:
+		if len(parse.Extractions) > 0 {
+			return log.NewLogfmtExpressionParser(parse.Extractions, parse.Strict)
+		}
+		return log.NewLogfmtParser(parse.Strict, parse.KeepEmpty), nil
+	case types.ParserTypeJSON:
+		if len(parse.Extractions) > 0 {
+			return log.NewJSONExpressionParser(parse.Extractions)
+		}
+		return log.NewJSONParser(false), nil
+	case types.ParserTypeRegexp:
+		return log.NewRegexpParser(parse.Expression)
+	case types.ParserTypePattern:
+		return log.NewPatternParser(parse.Expression)
+	case types.ParserTypeUnpack:
+		return log.NewUnpackParser(), nil
+	default:
+		return nil, fmt.Errorf("unsupported parser %s", parse.Kind)
+	}
+}
@@ -0,0 +1,217 @@
+package executor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/engine/internal/datatype"
+	"github.com/grafana/loki/v3/pkg/engine/internal/types"
+	"github.com/grafana/loki/v3/pkg/engine/planner/physical"
+	"github.com/grafana/loki/v3/pkg/logql/log"
+	"github.com/grafana/loki/v3/pkg/logqlmodel"
+)
+
+// newLogRecord creates a record with a timestamp, a level stream label and a
+// message column.
+func newLogRecord(t *testing.T, levels []string, lines []string) arrow.Record {
+	t.Helper()
+	require.Equal(t, len(levels), len(lines))
+
+	mem := memory.NewGoAllocator()
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: types.ColumnNameBuiltinTimestamp, Type: datatype.Arrow.Timestamp, Metadata: datatype.ColumnMetadataBuiltinTimestamp},
+		{Name: "level", Type: datatype.Arrow.String, Metadata: datatype.ColumnMetadata(types.ColumnTypeLabel, datatype.Loki.String)},
+		{Name: types.ColumnNameBuiltinMessage, Type: datatype.Arrow.String, Metadata: datatype.ColumnMetadataBuiltinMessage},
+	}, nil)
+
+	builder := array.NewRecordBuilder(mem, schema)
+	defer builder.Release()
+
+	now := time.Now()
+	for i := range lines {
+		builder.Field(0).(*array.TimestampBuilder).Append(arrow.Timestamp(now.Add(time.Duration(i) * time.Second).UnixNano()))
+		builder.Field(1).(*array.StringBuilder).Append(levels[i])
+		builder.Field(2).(*array.StringBuilder).Append(lines[i])
+	}
+	return builder.NewRecord()
+}
+
+// parsedColumns returns the values of all parsed columns of the record by
+// column name. Null values are represented as nil.
+func parsedColumns(t *testing.T, record arrow.Record) map[string][]*string {
+	t.Helper()
+
+	result := make(map[string][]*string)
+	for i, field := range record.Schema().Fields() {
+		ct, _ := field.Metadata.GetValue(types.MetadataKeyColumnType)
+		if ct != types.ColumnTypeParsed.String() {
+			continue
+		}
+
+		col := record.Column(i).(*array.String)
+		values := make([]*string, col.Len())
+		for row := range col.Len() {
+			if col.IsValid(row) {
+				v := col.Value(row)
+				values[row] = &v
+			}
+		}
+		result[field.Name] = values
+	}
+	return result
+}
+
+func ptr(s string) *string { return &s }
+
+func TestParsePipeline(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		parse    *physical.Parse
+		lines    []string
+		expected map[string][]*string
+	}{
+		{
+			name:  "logfmt",
+			parse: &physical.Parse{Kind: types.ParserTypeLogfmt},
+			lines: []string{
+				`msg="hello world" status=200`,
+				`status=500 duration=10ms`,
+			},
+			expected: map[string][]*string{
+				"msg":      {ptr("hello world"), nil},
+				"status":   {ptr("200"), ptr("500")},
+				"duration": {nil, ptr("10ms")},
+			},
+		},
+		{
+			name:  "logfmt with colliding stream label",
+			parse: &physical.Parse{Kind: types.ParserTypeLogfmt},
+			lines: []string{
+				`level=debug status=200`,
+			},
+			expected: map[string][]*string{
+				"level_extracted": {ptr("debug")},
+				"status":          {ptr("200")},
+			},
+		},
+		{
+			name: "logfmt with extractions",
+			parse: &physical.Parse{Kind: types.ParserTypeLogfmt, Extractions: []log.LabelExtractionExpr{
+				log.NewLabelExtractionExpr("code", "status"),
+			}},
+			lines: []string{
+				`status=200 duration=10ms`,
+			},
+			expected: map[string][]*string{
+				"code": {ptr("200")},
+			},
+		},
+		{
+			name:  "json",
+			parse: &physical.Parse{Kind: types.ParserTypeJSON},
+			lines: []string{
+				`{"status": 200, "request": {"method": "GET"}}`,
+				`not json`,
+			},
+			expected: map[string][]*string{
+				"status":                     {ptr("200"), nil},
+				"request_method":             {ptr("GET"), nil},
+				logqlmodel.ErrorLabel:        {nil, ptr("JSONParserErr")},
+				logqlmodel.ErrorDetailsLabel: {nil, ptr("Value looks like object, but can't find closing '}' symbol")},
+			},
+		},
+		{
+			name:  "regexp",
+			parse: &physical.Parse{Kind: types.ParserTypeRegexp, Expression: `status=(?P<status>\d+)`},
+			lines: []string{
+				`GET /index status=200`,
+				`GET /index`,
+			},
+			expected: map[string][]*string{
+				"status": {ptr("200"), nil},
+			},
+		},
+		{
+			name:  "pattern",
+			parse: &physical.Parse{Kind: types.ParserTypePattern, Expression: `<method> <path> <_>`},
+			lines: []string{
+				`GET /index 200`,
+			},
+			expected: map[string][]*string{
+				"method": {ptr("GET")},
+				"path":   {ptr("/index")},
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			levels := make([]string, len(tt.lines))
+			for i := range levels {
+				levels[i] = "info"
+			}
+
+			record := newLogRecord(t, levels, tt.lines)
+			pipeline, err := NewParsePipeline(tt.parse, NewBufferedPipeline(record))
+			require.NoError(t, err)
+			defer pipeline.Close()
+
+			require.NoError(t, pipeline.Read(t.Context()))
+			result, err := pipeline.Value()
+			require.NoError(t, err)
+
+			// input columns are retained
+			require.Equal(t, types.ColumnNameBuiltinTimestamp, result.ColumnName(0))
+			require.Equal(t, "level", result.ColumnName(1))
+			require.Equal(t, types.ColumnNameBuiltinMessage, result.ColumnName(2))
+			require.Equal(t, int64(len(tt.lines)), result.NumRows())
+
+			require.Equal(t, tt.expected, parsedColumns(t, result))
+		})
+	}
+}
+
+func TestParsePipeline_Unpack(t *testing.T) {
+	record := newLogRecord(t, []string{"info"}, []string{`{"_entry": "original line", "pod": "loki-0"}`})
+	pipeline, err := NewParsePipeline(&physical.Parse{Kind: types.ParserTypeUnpack}, NewBufferedPipeline(record))
+	require.NoError(t, err)
+	defer pipeline.Close()
+
+	require.NoError(t, pipeline.Read(t.Context()))
+	result, err := pipeline.Value()
+	require.NoError(t, err)
+
+	// The unpack parser replaces the log line.
+	require.Equal(t, "original line", result.Column(2).(*array.String).Value(0))
+	require.Equal(t, map[string][]*string{"pod": {ptr("loki-0")}}, parsedColumns(t, result))
+}
+
+func TestParsePipeline_MultipleParsers(t *testing.T) {
+	record := newLogRecord(t, []string{"info"}, []string{`status=200 msg="method=GET"`})
+
+	logfmt, err := NewParsePipeline(&physical.Parse{Kind: types.ParserTypeLogfmt}, NewBufferedPipeline(record))
+	require.NoError(t, err)
+
+	// The second parser stage keeps the parsed columns of the first one.
+	regexp, err := NewParsePipeline(&physical.Parse{Kind: types.ParserTypeRegexp, Expression: `method=(?P<method>\w+)`}, logfmt)
+	require.NoError(t, err)
+	defer regexp.Close()
+
+	require.NoError(t, regexp.Read(t.Context()))
+	result, err := regexp.Value()
+	require.NoError(t, err)
+
+	require.Equal(t, map[string][]*string{
+		"method": {ptr("GET")},
+		"msg":    {ptr("method=GET")},
+		"status": {ptr("200")},
+	}, parsedColumns(t, result))
+}
+
+func TestParsePipeline_InvalidExpression(t *testing.T) {
+	_, err := NewParsePipeline(&physical.Parse{Kind: types.ParserTypeRegexp, Expression: `(?P<foo`}, emptyPipeline())
+	require.Error(t, err)
+}
@@ -133,6 +133,8 @@ type prefetchWrapper struct {
 	ch    chan state // the results channel for pre-fetched items
 	state state      // internal state representing the last pre-fetched item
 
+	budget *memoryBudget // budget charged for the pre-fetched batches, nil if unlimited
+
 	cancel context.CancelCauseFunc // cancellation function for the context
 }
 
@@ -145,14 +147,16 @@ var _ Pipeline = (*prefetchWrapper)(nil)
 // The prefetching pipeline maintains a buffered channel with capacity 1 to store the next batch,
 // enabling pipeline parallelism and potentially improving throughput.
 //
-// The function accepts a [context.Context] `ctx` and a [Pipeline] `p`, which is the underlying pipeline to wrap
-// with pre-fetching capability.
+// The function accepts a [Pipeline] `p`, which is the underlying pipeline to wrap with pre-fetching capability,
+// and a [memoryBudget] `budget`, which is charged for the batches held by the wrapper until they are released.
+// Reading fails with the memory limit error if a batch exceeds the remaining budget.
 //
 // Returns a [prefetchWrapper] that implements the [Pipeline] interface.
-func newPrefetchingPipeline(p Pipeline) *prefetchWrapper {
+func newPrefetchingPipeline(p Pipeline, budget *memoryBudget) *prefetchWrapper {
 	return &prefetchWrapper{
 		Pipeline: p,
 		ch:       make(chan state),
+		budget:   budget,
 	}
 }
 
@@ -189,11 +193,17 @@ func (p prefetchWrapper) prefetch(ctx context.Context) error {
 				return s.err
 			}
 			s.batch, s.err = p.Pipeline.Value()
+			if !p.budget.reserve(recordSize(s.batch)) {
+				s = state{err: p.budget.limitError()}
+				p.ch <- s
+				return s.err
+			}
 			s.batch.Retain()
 			// Sending to channel will block until the batch is read by the parent pipeline.
 			// If the context is cancelled while waiting to send, we return.
 			select {
 			case <-ctx.Done():
+				p.releaseBatch(s.batch)
 				return ctx.Err()
 			case p.ch <- s:
 			}
@@ -203,9 +213,7 @@ func (p prefetchWrapper) prefetch(ctx context.Context) error {
 
 func (p *prefetchWrapper) read(_ context.Context) error {
 	// Release previously retained batch
-	if p.state.batch != nil {
-		p.state.batch.Release()
-	}
+	p.releaseBatch(p.state.batch)
 	p.state = <-p.ch
 	// Reading from a channel that is closed while waiting yields a zero-value.
 	// In that case, the pipeline should produce an error state.
@@ -225,7 +233,20 @@ func (p *prefetchWrapper) Close() {
 	// Cancel internal context so the goroutine can exit
 	p.cancel(errors.New("pipeline is closed"))
 	// Clear already pre-fetched, but unused items from channel
-	for range p.ch { // nolint:revive
+	for s := range p.ch {
+		p.releaseBatch(s.batch)
 	}
+	p.releaseBatch(p.state.batch)
+	p.state = Exhausted
 	p.Pipeline.Close()
 }
+
+// releaseBatch releases a batch retained by the pre-fetching goroutine and
+// returns its reservation to the budget.
+func (p *prefetchWrapper) releaseBatch(batch arrow.Record) {
+	if batch == nil {
+		return
+	}
+	p.budget.release(recordSize(batch))
+	batch.Release()
+}
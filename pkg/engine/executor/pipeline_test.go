@@ -179,7 +179,7 @@ func Test_prefetchWrapper_Read(t *testing.T) {
 	)
 
 	instrumentedPipeline := newInstrumentedPipeline(pipeline)
-	prefetchingPipeline := newPrefetchingPipeline(instrumentedPipeline)
+	prefetchingPipeline := newPrefetchingPipeline(instrumentedPipeline, nil)
 
 	require.Equal(t, 0, instrumentedPipeline.callCount["Read"])
 
@@ -4,27 +4,41 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
+	"unsafe"
 
 	"github.com/apache/arrow-go/v18/arrow"
 	"github.com/apache/arrow-go/v18/arrow/array"
-	"github.com/apache/arrow-go/v18/arrow/memory"
 	"github.com/cespare/xxhash/v2"
+	"github.com/prometheus/prometheus/model/labels"
 
 	"github.com/grafana/loki/v3/pkg/engine/internal/datatype"
 	"github.com/grafana/loki/v3/pkg/engine/internal/types"
 	"github.com/grafana/loki/v3/pkg/engine/planner/physical"
+	"github.com/grafana/loki/v3/pkg/logqlmodel"
 )
 
 type rangeAggregationOptions struct {
 	partitionBy []physical.ColumnExpression
 
+	operation types.RangeAggregationType // aggregation operation to apply on each window
+	unwrap    physical.Expression        // expression to extract sample values from, nil if the query does not unwrap
+	parameter float64                    // parameter of the operation, such as the quantile for quantile_over_time
+
+	absentLabels []labels.Label // labels of the series yielded by absent_over_time
+
 	// start and end timestamps are equal for instant queries.
 	startTs       time.Time     // start timestamp of the query
 	endTs         time.Time     // end timestamp of the query
 	rangeInterval time.Duration // range interval
 	step          time.Duration // step used for range queries
+
+	memory memoryConfig // accounting of the memory of the partitions
 }
 
 // RangeAggregationPipeline is a pipeline that performs aggregations over a time window.
@@ -32,24 +46,103 @@ type rangeAggregationOptions struct {
 // 1. It reads from the input pipelines
 // 2. Partitions the data by the specified columns
 // 3. Applies the aggregation function on each partition
+// 4. Emits one record per step, in ascending order of the step timestamp
 //
-// Current version only supports counting for instant queries.
+// If no partition columns are specified, the data is partitioned by all
+// label, metadata and parsed columns of the input records.
 type RangeAggregationPipeline struct {
 	state  state
 	inputs []Pipeline
 
-	aggregator *partitionAggregator
-	evaluator  expressionEvaluator // used to evaluate column expressions
-	opts       rangeAggregationOptions
+	evaluator expressionEvaluator // used to evaluate column expressions
+	opts      rangeAggregationOptions
+
+	tsEval      evalFunc // used to evaluate the timestamp column
+	messageEval evalFunc // used to evaluate the message column for bytes aggregations
+	unwrapEval  evalFunc // used to evaluate the unwrap expression
+
+	partitionBy []*physical.ColumnExpr // columns to partition by, grows with the input when partitioning implicitly
+
+	evalTs      []time.Time            // timestamps at which the aggregation is evaluated, one per step
+	aggregators []*partitionAggregator // aggregated partitions for each entry in evalTs
+	aggregated  bool                   // whether the inputs have been consumed
+	stepIdx     int                    // index of the next step to emit
+
+	reserved int64        // bytes of the memory budget reserved for the partitions in aggregators
+	spills   []*spillFile // partitions spilled to disk, each file holds one record per step in ascending order
 }
 
 func NewRangeAggregationPipeline(inputs []Pipeline, evaluator expressionEvaluator, opts rangeAggregationOptions) (*RangeAggregationPipeline, error) {
-	return &RangeAggregationPipeline{
-		inputs:     inputs,
-		evaluator:  evaluator,
-		aggregator: newPartitionAggregator(),
-		opts:       opts,
-	}, nil
+	if opts.step < 0 {
+		return nil, fmt.Errorf("invalid step %s: must not be negative", opts.step)
+	}
+
+	// Operations that aggregate sample values require an unwrap expression,
+	// except rate which counts log lines if no unwrap is specified.
+	switch opts.operation {
+	case types.RangeAggregationTypeSum, types.RangeAggregationTypeAvg,
+		types.RangeAggregationTypeMin, types.RangeAggregationTypeMax,
+		types.RangeAggregationTypeFirst, types.RangeAggregationTypeLast,
+		types.RangeAggregationTypeStddev, types.RangeAggregationTypeStdvar,
+		types.RangeAggregationTypeQuantile, types.RangeAggregationTypeChanges,
+		types.RangeAggregationTypeResets, types.RangeAggregationTypeDeriv,
+		types.RangeAggregationTypePredictLinear:
+		if opts.unwrap == nil {
+			return nil, fmt.Errorf("range aggregation %s requires an unwrap expression", opts.operation)
+		}
+	case types.RangeAggregationTypeCount, types.RangeAggregationTypeRate,
+		types.RangeAggregationTypeBytes, types.RangeAggregationTypeBytesRate,
+		types.RangeAggregationTypeAbsent:
+	default:
+		return nil, fmt.Errorf("unsupported range aggregation %s", opts.operation)
+	}
+
+	partitionBy := make([]*physical.ColumnExpr, 0, len(opts.partitionBy))
+	for _, column := range opts.partitionBy {
+		columnExpr, ok := column.(*physical.ColumnExpr)
+		if !ok {
+			return nil, fmt.Errorf("invalid column expression type %T", column)
+		}
+		partitionBy = append(partitionBy, columnExpr)
+	}
+
+	r := &RangeAggregationPipeline{
+		inputs:      inputs,
+		evaluator:   evaluator,
+		opts:        opts,
+		partitionBy: partitionBy,
+		evalTs:      evaluationTimestamps(opts.startTs, opts.endTs, opts.step),
+		tsEval: evaluator.newFunc(&physical.ColumnExpr{
+			Ref: types.ColumnRef{
+				Column: types.ColumnNameBuiltinTimestamp,
+				Type:   types.ColumnTypeBuiltin,
+			},
+		}),
+		messageEval: evaluator.newFunc(&physical.ColumnExpr{
+			Ref: types.ColumnRef{
+				Column: types.ColumnNameBuiltinMessage,
+				Type:   types.ColumnTypeBuiltin,
+			},
+		}),
+	}
+	if opts.unwrap != nil {
+		r.unwrapEval = evaluator.newFunc(opts.unwrap)
+	}
+	return r, nil
+}
+
+// evaluationTimestamps returns the timestamps at which the aggregation is evaluated.
+// Instant queries (step=0) are only evaluated at the end timestamp.
+func evaluationTimestamps(start, end time.Time, step time.Duration) []time.Time {
+	if step == 0 {
+		return []time.Time{end}
+	}
+
+	timestamps := make([]time.Time, 0, end.Sub(start)/step+1)
+	for ts := start; !ts.After(end); ts = ts.Add(step) {
+		timestamps = append(timestamps, ts)
+	}
+	return timestamps
 }
 
 // Read reads the next value into its state.
@@ -74,34 +167,67 @@ func (r *RangeAggregationPipeline) Read(ctx context.Context) error {
 	return nil
 }
 
-// TODOs:
-// - Support implicit partitioning by all labels when partitionBy is empty
-// - Use columnar access pattern. Current approach is row-based which does not benefit from the storage format.
-// - Add toggle to return partial results on Read() call instead of returning only after exhausing all inputs.
 func (r *RangeAggregationPipeline) read(ctx context.Context) (arrow.Record, error) {
-	var (
-		isTSInRange  func(t time.Time) bool
-		tsColumnExpr = &physical.ColumnExpr{
-			Ref: types.ColumnRef{
-				Column: types.ColumnNameBuiltinTimestamp,
-				Type:   types.ColumnTypeBuiltin,
-			},
-		} // timestamp column expression
+	if !r.aggregated {
+		if err := r.aggregate(ctx); err != nil {
+			return nil, err
+		}
+		// Once spilled, the remaining partitions are spilled as well, so only
+		// the partitions of a single step are held in memory while merging.
+		if len(r.spills) > 0 && r.reserved > 0 {
+			if err := r.spill(); err != nil {
+				return nil, err
+			}
+		}
+		for _, f := range r.spills {
+			if err := f.finish(); err != nil {
+				return nil, err
+			}
+		}
+		r.aggregated = true
+	}
 
-		// reused on each row read
-		labelValues = make([]string, len(r.opts.partitionBy))
-	)
+	for ; r.stepIdx < len(r.evalTs); r.stepIdx++ {
+		if err := r.mergeSpilled(r.stepIdx); err != nil {
+			return nil, err
+		}
+		aggregator := r.aggregators[r.stepIdx]
 
-	{
-		evalTs := r.opts.endTs
-		earliestTs := r.opts.endTs.Add(-r.opts.rangeInterval)
-		isTSInRange = func(t time.Time) bool {
-			// Aggregate entries that belong in [earliestTs, evalTs)
-			return t.Compare(earliestTs) >= 0 && t.Compare(evalTs) < 0
+		// absent_over_time only yields a value for steps without any entries.
+		if r.opts.operation == types.RangeAggregationTypeAbsent {
+			if aggregator != nil && aggregator.NumOfPartitions() > 0 {
+				r.releaseStep(r.stepIdx)
+				continue
+			}
+
+			record := r.buildAbsentRecord(r.evalTs[r.stepIdx])
+			r.stepIdx++
+			return record, nil
 		}
+
+		// emit the next step that has at least one partition
+		if aggregator == nil || aggregator.NumOfPartitions() == 0 {
+			continue
+		}
+
+		record := r.buildRecord(r.evalTs[r.stepIdx], aggregator)
+		r.releaseStep(r.stepIdx) // release partitions of emitted step
+		r.stepIdx++
+		return record, nil
 	}
 
-	r.aggregator.Reset() // reset before reading new inputs
+	return nil, EOF // no values to aggregate & reached EOF
+}
+
+// TODOs:
+// - Use columnar access pattern. Current approach is row-based which does not benefit from the storage format.
+// - Add toggle to return partial results on Read() call instead of returning only after exhausing all inputs.
+func (r *RangeAggregationPipeline) aggregate(ctx context.Context) error {
+	// reused on each row read
+	var labelValues []string
+
+	r.aggregators = make([]*partitionAggregator, len(r.evalTs))
+
 	inputsExhausted := false
 	for !inputsExhausted {
 		inputsExhausted = true
@@ -112,55 +238,466 @@ func (r *RangeAggregationPipeline) read(ctx context.Context) (arrow.Record, erro
 					continue
 				}
 
-				return nil, err
+				return err
 			}
 
 			inputsExhausted = false
 			record, _ := input.Value()
 
+			if len(r.opts.partitionBy) == 0 {
+				r.addImplicitPartitionColumns(record)
+			}
+
 			// extract all the columns that are used for partitioning
-			arrays := make([]*array.String, 0, len(r.opts.partitionBy))
-			for _, columnExpr := range r.opts.partitionBy {
+			arrays := make([]*array.String, 0, len(r.partitionBy))
+			for _, columnExpr := range r.partitionBy {
 				vec, err := r.evaluator.eval(columnExpr, record)
 				if err != nil {
-					return nil, err
+					return err
 				}
 
 				if vec.Type() != datatype.Loki.String {
-					return nil, fmt.Errorf("unsupported datatype for partitioning %s", vec.Type())
+					return fmt.Errorf("unsupported datatype for partitioning %s", vec.Type())
 				}
 
 				arrays = append(arrays, vec.ToArray().(*array.String))
 			}
 
-			// extract timestamp column to check if the entry is in range
-			vec, err := r.evaluator.eval(tsColumnExpr, record)
+			// extract timestamp column to find the steps the entry belongs to
+			vec, err := r.tsEval(record)
 			if err != nil {
-				return nil, err
+				return err
 			}
 			tsCol := vec.ToArray().(*array.Timestamp)
 
+			sampleValue, err := r.sampleValueFunc(record)
+			if err != nil {
+				return err
+			}
+
+			if cap(labelValues) < len(arrays) {
+				labelValues = make([]string, len(arrays))
+			}
+			labelValues = labelValues[:len(arrays)]
+
 			for row := range int(record.NumRows()) {
-				if !isTSInRange(tsCol.Value(row).ToTime(arrow.Nanosecond)) {
+				ts := tsCol.Value(row).ToTime(arrow.Nanosecond)
+				first, last, ok := r.stepsForTimestamp(ts)
+				if !ok {
+					continue
+				}
+
+				value, ok, err := sampleValue(row)
+				if err != nil {
+					return sampleExtractionError(r.partitionBy, arrays, row, err)
+				}
+				if !ok {
 					continue
 				}
 
-				// reset label values and hash for each row
+				// reset label values for each row
 				clear(labelValues)
 				for col, arr := range arrays {
 					labelValues[col] = arr.Value(row)
 				}
-				r.aggregator.Add(labelValues)
+
+				for i := first; i <= last; i++ {
+					if err := r.add(i, labelValues, ts.UnixNano(), value); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// add adds a sample to the partition identified by labelValues of the step
+// at index i. If the partitions exceed the memory budget, the partitions of
+// all steps are spilled to disk.
+func (r *RangeAggregationPipeline) add(i int, labelValues []string, ts int64, value float64) error {
+	if r.aggregators[i] == nil {
+		r.aggregators[i] = r.newPartitionAggregator()
+	}
+
+	grown := r.aggregators[i].Add(labelValues, ts, value)
+	if grown == 0 {
+		return nil
+	}
+	r.aggregators[i].size += grown
+
+	budget := r.opts.memory.budget
+	if budget.reserve(grown) {
+		r.reserved += grown
+		return nil
+	}
+
+	// Spilling only helps if there is reserved memory that can be released.
+	if r.reserved == 0 {
+		return budget.limitError()
+	}
+	// The spilled partitions include the sample which was just added, so its
+	// memory doesn't need to be reserved anymore.
+	if err := r.spill(); errors.Is(err, errSpillDisabled) {
+		return budget.limitError()
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+// newPartitionAggregator returns a [partitionAggregator] which retains the
+// samples required by the aggregation operation.
+func (r *RangeAggregationPipeline) newPartitionAggregator() *partitionAggregator {
+	a := newPartitionAggregator()
+	switch r.opts.operation {
+	case types.RangeAggregationTypeQuantile:
+		a.keepValues = true
+	case types.RangeAggregationTypeChanges, types.RangeAggregationTypeResets,
+		types.RangeAggregationTypeDeriv, types.RangeAggregationTypePredictLinear:
+		a.keepValues, a.keepTimestamps = true, true
+	}
+	return a
+}
+
+// releaseStep releases the partitions of the step at index i.
+func (r *RangeAggregationPipeline) releaseStep(i int) {
+	aggregator := r.aggregators[i]
+	if aggregator == nil {
+		return
+	}
+	r.opts.memory.budget.release(aggregator.size)
+	r.reserved -= aggregator.size
+	r.aggregators[i] = nil
+}
+
+// Columns of the records of partitions spilled to disk. The columns are
+// followed by one column for each partition column.
+const (
+	rangeSpillColumnStep = iota
+	rangeSpillColumnCount
+	rangeSpillColumnSum
+	rangeSpillColumnMean
+	rangeSpillColumnAux
+	rangeSpillColumnMin
+	rangeSpillColumnMax
+	rangeSpillColumnFirst
+	rangeSpillColumnFirstTs
+	rangeSpillColumnLast
+	rangeSpillColumnLastTs
+	rangeSpillColumnValues
+	rangeSpillColumnTimestamps
+	rangeSpillColumnLabels // first partition column
+)
+
+// spillSchema returns the schema of the records of spilled partitions.
+func (r *RangeAggregationPipeline) spillSchema() *arrow.Schema {
+	fields := []arrow.Field{
+		{Name: "step", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "count", Type: arrow.PrimitiveTypes.Float64},
+		{Name: "sum", Type: arrow.PrimitiveTypes.Float64},
+		{Name: "mean", Type: arrow.PrimitiveTypes.Float64},
+		{Name: "aux", Type: arrow.PrimitiveTypes.Float64},
+		{Name: "min", Type: arrow.PrimitiveTypes.Float64},
+		{Name: "max", Type: arrow.PrimitiveTypes.Float64},
+		{Name: "first", Type: arrow.PrimitiveTypes.Float64},
+		{Name: "first_ts", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "last", Type: arrow.PrimitiveTypes.Float64},
+		{Name: "last_ts", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "values", Type: arrow.ListOf(arrow.PrimitiveTypes.Float64)},
+		{Name: "timestamps", Type: arrow.ListOf(arrow.PrimitiveTypes.Int64)},
+	}
+	for i := range r.partitionBy {
+		fields = append(fields, arrow.Field{Name: fmt.Sprintf("label_%d", i), Type: arrow.BinaryTypes.String, Nullable: true})
+	}
+	return arrow.NewSchema(fields, nil)
+}
+
+// spill writes the partitions of all steps to a new spill file, one record
+// per step, and releases their memory.
+func (r *RangeAggregationPipeline) spill() error {
+	schema := r.spillSchema()
+	f, err := createSpillFile(r.opts.memory.spillDir, schema, r.opts.memory.alloc())
+	if err != nil {
+		return err
+	}
+	r.spills = append(r.spills, f)
+
+	for i, aggregator := range r.aggregators {
+		if aggregator == nil || aggregator.NumOfPartitions() == 0 {
+			continue
+		}
+
+		rec := r.buildSpillRecord(schema, i, aggregator)
+		err := f.write(rec)
+		rec.Release()
+		if err != nil {
+			return err
+		}
+		r.aggregators[i] = nil
+	}
+
+	r.opts.memory.budget.release(r.reserved)
+	r.reserved = 0
+	return nil
+}
+
+func (r *RangeAggregationPipeline) buildSpillRecord(schema *arrow.Schema, step int, aggregator *partitionAggregator) arrow.Record {
+	rb := array.NewRecordBuilder(r.opts.memory.alloc(), schema)
+	defer rb.Release()
+
+	values := rb.Field(rangeSpillColumnValues).(*array.ListBuilder)
+	timestamps := rb.Field(rangeSpillColumnTimestamps).(*array.ListBuilder)
+	for _, entry := range aggregator.entries {
+		rb.Field(rangeSpillColumnStep).(*array.Int64Builder).Append(int64(step))
+		rb.Field(rangeSpillColumnCount).(*array.Float64Builder).Append(entry.count)
+		rb.Field(rangeSpillColumnSum).(*array.Float64Builder).Append(entry.sum)
+		rb.Field(rangeSpillColumnMean).(*array.Float64Builder).Append(entry.mean)
+		rb.Field(rangeSpillColumnAux).(*array.Float64Builder).Append(entry.aux)
+		rb.Field(rangeSpillColumnMin).(*array.Float64Builder).Append(entry.min)
+		rb.Field(rangeSpillColumnMax).(*array.Float64Builder).Append(entry.max)
+		rb.Field(rangeSpillColumnFirst).(*array.Float64Builder).Append(entry.first)
+		rb.Field(rangeSpillColumnFirstTs).(*array.Int64Builder).Append(entry.firstTs)
+		rb.Field(rangeSpillColumnLast).(*array.Float64Builder).Append(entry.last)
+		rb.Field(rangeSpillColumnLastTs).(*array.Int64Builder).Append(entry.lastTs)
+
+		values.Append(true)
+		values.ValueBuilder().(*array.Float64Builder).AppendValues(entry.allValues, nil)
+		timestamps.Append(true)
+		timestamps.ValueBuilder().(*array.Int64Builder).AppendValues(entry.allTimestamps, nil)
+
+		for col := range r.partitionBy {
+			builder := rb.Field(rangeSpillColumnLabels + col).(*array.StringBuilder)
+			if col >= len(entry.labelValues) || entry.labelValues[col] == "" {
+				builder.AppendNull()
+			} else {
+				builder.Append(entry.labelValues[col])
+			}
+		}
+	}
+	return rb.NewRecord()
+}
+
+// mergeSpilled merges the partitions of the step at index i spilled to disk
+// into the partitions of the step. Partitions can't be spilled again at this
+// point, so exceeding the memory budget fails the query.
+func (r *RangeAggregationPipeline) mergeSpilled(i int) error {
+	var labelValues []string
+
+	for _, f := range r.spills {
+		rec := f.record()
+		if rec == nil || rec.NumRows() == 0 || rec.Column(rangeSpillColumnStep).(*array.Int64).Value(0) != int64(i) {
+			continue
+		}
+
+		if r.aggregators[i] == nil {
+			r.aggregators[i] = r.newPartitionAggregator()
+		}
+		aggregator := r.aggregators[i]
+
+		var (
+			count      = rec.Column(rangeSpillColumnCount).(*array.Float64)
+			sum        = rec.Column(rangeSpillColumnSum).(*array.Float64)
+			mean       = rec.Column(rangeSpillColumnMean).(*array.Float64)
+			aux        = rec.Column(rangeSpillColumnAux).(*array.Float64)
+			minimum    = rec.Column(rangeSpillColumnMin).(*array.Float64)
+			maximum    = rec.Column(rangeSpillColumnMax).(*array.Float64)
+			first      = rec.Column(rangeSpillColumnFirst).(*array.Float64)
+			firstTs    = rec.Column(rangeSpillColumnFirstTs).(*array.Int64)
+			last       = rec.Column(rangeSpillColumnLast).(*array.Float64)
+			lastTs     = rec.Column(rangeSpillColumnLastTs).(*array.Int64)
+			values     = rec.Column(rangeSpillColumnValues).(*array.List)
+			timestamps = rec.Column(rangeSpillColumnTimestamps).(*array.List)
+			labels     = make([]*array.String, 0, int(rec.NumCols())-rangeSpillColumnLabels)
+		)
+		for col := rangeSpillColumnLabels; col < int(rec.NumCols()); col++ {
+			labels = append(labels, rec.Column(col).(*array.String))
+		}
+		valueData := values.ListValues().(*array.Float64)
+		timestampData := timestamps.ListValues().(*array.Int64)
+
+		labelValues = resize(labelValues, len(labels))
+		for row := range int(rec.NumRows()) {
+			readStringValues(labelValues, labels, row)
+
+			start, end := values.ValueOffsets(row)
+			tsStart, tsEnd := timestamps.ValueOffsets(row)
+			entry := partitionEntry{
+				count:         count.Value(row),
+				sum:           sum.Value(row),
+				mean:          mean.Value(row),
+				aux:           aux.Value(row),
+				min:           minimum.Value(row),
+				max:           maximum.Value(row),
+				first:         first.Value(row),
+				firstTs:       firstTs.Value(row),
+				last:          last.Value(row),
+				lastTs:        lastTs.Value(row),
+				allValues:     valueData.Float64Values()[start:end],
+				allTimestamps: timestampData.Int64Values()[tsStart:tsEnd],
+			}
+
+			grown := aggregator.merge(labelValues, &entry)
+			if !r.opts.memory.budget.reserve(grown) {
+				return r.opts.memory.budget.limitError()
+			}
+			aggregator.size += grown
+			r.reserved += grown
+		}
+
+		if err := f.next(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addImplicitPartitionColumns adds all label, metadata and parsed columns of
+// the record to the partition columns that have not been seen before.
+func (r *RangeAggregationPipeline) addImplicitPartitionColumns(record arrow.Record) {
+	for _, field := range record.Schema().Fields() {
+		ct, ok := field.Metadata.GetValue(types.MetadataKeyColumnType)
+		if !ok {
+			continue
+		}
+
+		switch ct {
+		case types.ColumnTypeLabel.String(), types.ColumnTypeMetadata.String(), types.ColumnTypeParsed.String():
+		default:
+			continue
+		}
+
+		found := slices.ContainsFunc(r.partitionBy, func(expr *physical.ColumnExpr) bool {
+			return expr.Ref.Column == field.Name
+		})
+		if !found {
+			// Columns of different types can share the same name, so they are
+			// resolved by precedence using an ambiguous column reference.
+			r.partitionBy = append(r.partitionBy, &physical.ColumnExpr{
+				Ref: types.ColumnRef{Column: field.Name, Type: types.ColumnTypeAmbiguous},
+			})
+		}
+	}
+}
+
+// sampleValueFunc returns a function that yields the sample value of a row of
+// the given record. It returns false if the row does not have a sample value,
+// in which case it is excluded from the aggregation, and an error if the value
+// of the row cannot be converted into a sample value.
+func (r *RangeAggregationPipeline) sampleValueFunc(record arrow.Record) (func(row int) (float64, bool, error), error) {
+	switch {
+	case r.unwrapEval != nil:
+		vec, err := r.unwrapEval(record)
+		if err != nil {
+			return nil, err
+		}
+		return unwrapValueFunc(vec)
+
+	case r.opts.operation == types.RangeAggregationTypeBytes || r.opts.operation == types.RangeAggregationTypeBytesRate:
+		vec, err := r.messageEval(record)
+		if err != nil {
+			return nil, err
+		}
+		arr, ok := vec.ToArray().(*array.String)
+		if !ok {
+			return nil, fmt.Errorf("unsupported datatype for message column %s", vec.Type())
+		}
+		return func(row int) (float64, bool, error) {
+			return float64(arr.ValueLen(row)), true, nil
+		}, nil
+
+	default:
+		// Each log line counts as a sample of value 1.
+		return func(int) (float64, bool, error) { return 1, true, nil }, nil
+	}
+}
+
+// unwrapValueFunc returns a function that converts the values of the unwrapped
+// column vector into sample values. Like in the old engine, rows without a
+// value are skipped, while values which cannot be converted are an error.
+func unwrapValueFunc(vec ColumnVector) (func(row int) (float64, bool, error), error) {
+	switch arr := vec.ToArray().(type) {
+	case *array.Float64:
+		return func(row int) (float64, bool, error) {
+			return arr.Value(row), arr.IsValid(row), nil
+		}, nil
+	case *array.Int64:
+		return func(row int) (float64, bool, error) {
+			return float64(arr.Value(row)), arr.IsValid(row), nil
+		}, nil
+	case *array.String:
+		return func(row int) (float64, bool, error) {
+			if !arr.IsValid(row) || arr.Value(row) == "" {
+				return 0, false, nil
+			}
+			v, err := strconv.ParseFloat(arr.Value(row), 64)
+			if err != nil {
+				return 0, false, err
 			}
+			return v, true, nil
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported datatype for unwrap %s", vec.Type())
+	}
+}
+
+// errSampleExtraction is the value of the error label of samples whose value
+// cannot be extracted, as set by the old engine.
+const errSampleExtraction = "SampleExtractionErr"
+
+// sampleExtractionError returns the error of the old engine for a row whose
+// unwrapped value cannot be converted. The old engine marks the series of the
+// sample with an error label, which fails the query unless the error is
+// filtered out. Label filters after unwrap are not supported yet, so the
+// query always fails.
+func sampleExtractionError(partitionBy []*physical.ColumnExpr, arrays []*array.String, row int, err error) error {
+	builder := labels.NewScratchBuilder(len(arrays) + 2)
+	for col, arr := range arrays {
+		if v := arr.Value(row); v != "" {
+			builder.Add(partitionBy[col].Ref.Column, v)
 		}
 	}
+	builder.Add(logqlmodel.ErrorLabel, errSampleExtraction)
+	builder.Add(logqlmodel.ErrorDetailsLabel, err.Error())
+	builder.Sort()
+	return logqlmodel.NewPipelineErr(builder.Labels())
+}
+
+// stepsForTimestamp returns the indexes of the first and last step whose window contains ts.
+// The window of a step evaluated at t covers entries in [t-rangeInterval, t).
+// It returns false if ts does not belong to any window.
+func (r *RangeAggregationPipeline) stepsForTimestamp(ts time.Time) (int, int, bool) {
+	if r.opts.step == 0 {
+		evalTs := r.evalTs[0]
+		earliestTs := evalTs.Add(-r.opts.rangeInterval)
+		return 0, 0, ts.Compare(earliestTs) >= 0 && ts.Compare(evalTs) < 0
+	}
+
+	// A step at t = start + i*step includes ts if ts < t <= ts+rangeInterval.
+	offset := ts.Sub(r.opts.startTs)
+	first := floorDiv(offset, r.opts.step) + 1
+	last := floorDiv(offset+r.opts.rangeInterval, r.opts.step)
+
+	first = max(first, 0)
+	last = min(last, int64(len(r.evalTs)-1))
+	if first > last {
+		return 0, 0, false
+	}
+	return int(first), int(last), true
+}
 
-	if r.aggregator.NumOfPartitions() == 0 {
-		return nil, EOF // no values to aggregate & reached EOF
+// floorDiv returns the largest integer q such that q*d <= n.
+func floorDiv(n, d time.Duration) int64 {
+	q := n / d
+	if n%d != 0 && (n < 0) != (d < 0) {
+		q--
 	}
+	return int64(q)
+}
 
-	// TODO: schema is same for each read call when partitionBy is defined, we can create it once and reuse.
-	fields := make([]arrow.Field, 0, len(r.opts.partitionBy)+2)
+func (r *RangeAggregationPipeline) buildRecord(evalTs time.Time, aggregator *partitionAggregator) arrow.Record {
+	fields := make([]arrow.Field, 0, len(r.partitionBy)+2)
 	fields = append(fields,
 		arrow.Field{
 			Name:     types.ColumnNameBuiltinTimestamp,
@@ -170,18 +707,13 @@ func (r *RangeAggregationPipeline) read(ctx context.Context) (arrow.Record, erro
 		},
 		arrow.Field{
 			Name:     types.ColumnNameGeneratedValue,
-			Type:     datatype.Arrow.Integer,
+			Type:     datatype.Arrow.Float,
 			Nullable: false,
-			Metadata: datatype.ColumnMetadata(types.ColumnTypeGenerated, datatype.Loki.Integer),
+			Metadata: datatype.ColumnMetadata(types.ColumnTypeGenerated, datatype.Loki.Float),
 		},
 	)
 
-	for _, column := range r.opts.partitionBy {
-		columnExpr, ok := column.(*physical.ColumnExpr)
-		if !ok {
-			panic(fmt.Sprintf("invalid column expression type %T", column))
-		}
-
+	for _, columnExpr := range r.partitionBy {
 		fields = append(fields, arrow.Field{
 			Name:     columnExpr.Ref.Column,
 			Type:     datatype.Arrow.String,
@@ -191,25 +723,68 @@ func (r *RangeAggregationPipeline) read(ctx context.Context) (arrow.Record, erro
 	}
 
 	schema := arrow.NewSchema(fields, nil)
-	rb := array.NewRecordBuilder(memory.NewGoAllocator(), schema)
+	rb := array.NewRecordBuilder(r.opts.memory.alloc(), schema)
 	defer rb.Release()
 
-	ts, _ := arrow.TimestampFromTime(r.opts.endTs, arrow.Nanosecond)
-	for _, entry := range r.aggregator.entries {
+	ts, _ := arrow.TimestampFromTime(evalTs, arrow.Nanosecond)
+	for _, entry := range aggregator.entries {
 		rb.Field(0).(*array.TimestampBuilder).Append(ts)
-		rb.Field(1).(*array.Int64Builder).Append(entry.count)
+		rb.Field(1).(*array.Float64Builder).Append(entry.value(r.opts.operation, r.opts.rangeInterval, r.opts.parameter))
 
-		for col, val := range entry.labelValues {
+		for col := range r.partitionBy {
 			builder := rb.Field(col + 2) // offset by 2 as the first 2 fields are timestamp and value
-			if val == "" {
+
+			// Partitions created before a column was discovered have fewer label values.
+			if col >= len(entry.labelValues) || entry.labelValues[col] == "" {
 				builder.(*array.StringBuilder).AppendNull()
 			} else {
-				builder.(*array.StringBuilder).Append(val)
+				builder.(*array.StringBuilder).Append(entry.labelValues[col])
 			}
 		}
 	}
 
-	return rb.NewRecord(), nil
+	return rb.NewRecord()
+}
+
+// buildAbsentRecord returns the single row yielded by absent_over_time for a
+// step without any entries. The row carries one label column per absent label.
+func (r *RangeAggregationPipeline) buildAbsentRecord(evalTs time.Time) arrow.Record {
+	fields := make([]arrow.Field, 0, len(r.opts.absentLabels)+2)
+	fields = append(fields,
+		arrow.Field{
+			Name:     types.ColumnNameBuiltinTimestamp,
+			Type:     datatype.Arrow.Timestamp,
+			Nullable: false,
+			Metadata: datatype.ColumnMetadataBuiltinTimestamp,
+		},
+		arrow.Field{
+			Name:     types.ColumnNameGeneratedValue,
+			Type:     datatype.Arrow.Float,
+			Nullable: false,
+			Metadata: datatype.ColumnMetadata(types.ColumnTypeGenerated, datatype.Loki.Float),
+		},
+	)
+	for _, label := range r.opts.absentLabels {
+		fields = append(fields, arrow.Field{
+			Name:     label.Name,
+			Type:     datatype.Arrow.String,
+			Nullable: true,
+			Metadata: datatype.ColumnMetadata(types.ColumnTypeLabel, datatype.Loki.String),
+		})
+	}
+
+	schema := arrow.NewSchema(fields, nil)
+	rb := array.NewRecordBuilder(r.opts.memory.alloc(), schema)
+	defer rb.Release()
+
+	ts, _ := arrow.TimestampFromTime(evalTs, arrow.Nanosecond)
+	rb.Field(0).(*array.TimestampBuilder).Append(ts)
+	rb.Field(1).(*array.Float64Builder).Append(1)
+	for i, label := range r.opts.absentLabels {
+		rb.Field(i + 2).(*array.StringBuilder).Append(label.Value)
+	}
+
+	return rb.NewRecord()
 }
 
 // Value returns the current value in state.
@@ -225,6 +800,12 @@ func (r *RangeAggregationPipeline) Close() {
 		r.state.batch.Release()
 	}
 
+	r.opts.memory.budget.release(r.reserved)
+	r.reserved = 0
+	r.aggregators = nil
+	_ = closeSpillFiles(r.spills)
+	r.spills = nil
+
 	for _, input := range r.inputs {
 		input.Close()
 	}
@@ -241,8 +822,11 @@ func (r *RangeAggregationPipeline) Transport() Transport {
 }
 
 type partitionAggregator struct {
-	digest  *xxhash.Digest // used to compute key for each partition
-	entries map[uint64]*partitionEntry
+	digest         *xxhash.Digest // used to compute key for each partition
+	entries        map[uint64]*partitionEntry
+	keepValues     bool  // whether to retain all sample values of a partition
+	keepTimestamps bool  // whether to retain the timestamps of all sample values of a partition
+	size           int64 // estimated size of the entries in bytes
 }
 
 func newPartitionAggregator() *partitionAggregator {
@@ -253,27 +837,77 @@ func newPartitionAggregator() *partitionAggregator {
 	}
 }
 
+// partitionEntry holds the state of a single partition of a window.
+// Only the fields required by the aggregation operation are meaningful.
 type partitionEntry struct {
-	count       int64
 	labelValues []string
+
+	count         float64
+	sum           float64
+	mean          float64 // running mean for avg_over_time, stddev_over_time and stdvar_over_time
+	aux           float64 // running variance state for stddev and stdvar (Welford's algorithm)
+	min, max      float64
+	first         float64
+	firstTs       int64
+	last          float64
+	lastTs        int64
+	allValues     []float64 // only tracked for quantile_over_time and the operations tracking allTimestamps
+	allTimestamps []int64   // timestamps of allValues, only tracked for changes, resets, deriv and predict_linear
+}
+
+// partitionEntrySize is the estimated size of a [partitionEntry] in bytes,
+// excluding its label values and sample values.
+const partitionEntrySize = int64(unsafe.Sizeof(partitionEntry{})) + 16 // 16 bytes for the map entry
+
+// Add adds a sample with timestamp ts and value v to the partition identified by partitionLabelValues.
+// It returns the estimated number of bytes allocated for the sample.
+func (a *partitionAggregator) Add(partitionLabelValues []string, ts int64, v float64) int64 {
+	entry, grown := a.entry(partitionLabelValues)
+
+	entry.add(ts, v)
+	if a.keepValues {
+		entry.allValues = append(entry.allValues, v)
+		grown += 8
+	}
+	if a.keepTimestamps {
+		entry.allTimestamps = append(entry.allTimestamps, ts)
+		grown += 8
+	}
+	return grown
 }
 
-func (a *partitionAggregator) Add(partitionLabelValues []string) {
+// merge merges the partial state other of a partition, such as read back from
+// a spill file, into the partition identified by partitionLabelValues. It
+// returns the estimated number of bytes allocated for the state.
+func (a *partitionAggregator) merge(partitionLabelValues []string, other *partitionEntry) int64 {
+	entry, grown := a.entry(partitionLabelValues)
+
+	entry.merge(other)
+	return grown + 8*int64(len(other.allValues)+len(other.allTimestamps))
+}
+
+// entry returns the partition identified by partitionLabelValues, creating it
+// if it doesn't exist yet. It returns the estimated number of bytes allocated
+// for a new partition.
+func (a *partitionAggregator) entry(partitionLabelValues []string) (*partitionEntry, int64) {
 	a.digest.Reset()
 
+	// Empty label values are skipped and the remaining ones are keyed by their
+	// index, so that partitions created before an implicit partition column was
+	// discovered match the ones created afterwards.
 	for i, val := range partitionLabelValues {
-		if i > 0 {
-			_, _ = a.digest.Write([]byte{0}) // separator for label values
+		if val == "" {
+			continue
 		}
-
+		_, _ = a.digest.WriteString(strconv.Itoa(i))
+		_, _ = a.digest.Write([]byte{0}) // separator between index and value
 		_, _ = a.digest.WriteString(val)
+		_, _ = a.digest.Write([]byte{0}) // separator for label values
 	}
 
 	key := a.digest.Sum64()
-	if entry, ok := a.entries[key]; ok {
-		// TODO: handle hash collisions
-		entry.count++
-	} else {
+	entry, ok := a.entries[key]
+	if !ok {
 		// create a new slice since partitionLabelValues is reused by the calling code
 		labelValues := make([]string, len(partitionLabelValues))
 		for i, v := range partitionLabelValues {
@@ -283,12 +917,287 @@ func (a *partitionAggregator) Add(partitionLabelValues []string) {
 			labelValues[i] = strings.Clone(v)
 		}
 
+		// TODO: handle hash collisions
 		// TODO: add limits on number of partitions
-		a.entries[key] = &partitionEntry{
+		entry = &partitionEntry{
 			labelValues: labelValues,
-			count:       1,
+			min:         math.NaN(),
+			max:         math.NaN(),
+			firstTs:     math.MaxInt64,
+			lastTs:      math.MinInt64,
+		}
+		a.entries[key] = entry
+		return entry, partitionEntrySize + stringsSize(labelValues)
+	}
+	return entry, 0
+}
+
+// stringsSize returns the estimated size of values in bytes.
+func stringsSize(values []string) int64 {
+	size := 16 * int64(len(values)) // string headers
+	for _, v := range values {
+		size += int64(len(v))
+	}
+	return size
+}
+
+func (e *partitionEntry) add(ts int64, v float64) {
+	e.count++
+	e.sum += v
+
+	if v < e.min || math.IsNaN(e.min) {
+		e.min = v
+	}
+	if v > e.max || math.IsNaN(e.max) {
+		e.max = v
+	}
+
+	// Samples are not guaranteed to arrive in order of their timestamp.
+	if ts < e.firstTs {
+		e.first, e.firstTs = v, ts
+	}
+	if ts >= e.lastTs {
+		e.last, e.lastTs = v, ts
+	}
+
+	// Welford's online algorithm for the variance.
+	delta := v - e.mean
+	e.addMean(v)
+	e.aux += delta * (v - e.mean)
+}
+
+// merge merges the partial state of the same partition in o into e.
+func (e *partitionEntry) merge(o *partitionEntry) {
+	if o.count == 0 {
+		return
+	}
+	if e.count == 0 {
+		labelValues, allValues, allTimestamps := e.labelValues, e.allValues, e.allTimestamps
+		*e = *o
+		e.labelValues = labelValues
+		e.allValues = append(allValues, o.allValues...)
+		e.allTimestamps = append(allTimestamps, o.allTimestamps...)
+		return
+	}
+
+	count := e.count + o.count
+	e.sum += o.sum
+
+	if o.min < e.min || math.IsNaN(e.min) {
+		e.min = o.min
+	}
+	if o.max > e.max || math.IsNaN(e.max) {
+		e.max = o.max
+	}
+
+	// Spilled state always holds samples that were added earlier.
+	if o.firstTs <= e.firstTs {
+		e.first, e.firstTs = o.first, o.firstTs
+	}
+	if o.lastTs > e.lastTs {
+		e.last, e.lastTs = o.last, o.lastTs
+	}
+
+	// Chan et al.'s parallel algorithm to combine the variances.
+	delta := o.mean - e.mean
+	e.aux += o.aux + delta*delta*e.count*o.count/count
+
+	if math.IsInf(e.mean, 0) || math.IsInf(o.mean, 0) {
+		e.mean += o.mean
+	} else {
+		e.mean += (o.mean - e.mean) * o.count / count
+	}
+
+	e.count = count
+	e.allValues = append(e.allValues, o.allValues...)
+	e.allTimestamps = append(e.allTimestamps, o.allTimestamps...)
+}
+
+// addMean updates the running mean in the same way as avg_over_time of the old engine.
+func (e *partitionEntry) addMean(v float64) {
+	if math.IsInf(e.mean, 0) {
+		if math.IsInf(v, 0) && (e.mean > 0) == (v > 0) {
+			// The mean and v are Inf of the same sign. They can't be
+			// subtracted, but the value of the mean is correct already.
+			return
+		}
+		if !math.IsInf(v, 0) && !math.IsNaN(v) {
+			// The mean is infinite and adding a finite value would
+			// end up as NaN, so the mean is kept.
+			return
+		}
+	}
+	e.mean += v/e.count - e.mean/e.count
+}
+
+// value returns the aggregated value of the partition for the given operation.
+func (e *partitionEntry) value(op types.RangeAggregationType, rangeInterval time.Duration, parameter float64) float64 {
+	switch op {
+	case types.RangeAggregationTypeCount:
+		return e.count
+	case types.RangeAggregationTypeRate, types.RangeAggregationTypeBytesRate:
+		return e.sum / rangeInterval.Seconds()
+	case types.RangeAggregationTypeBytes, types.RangeAggregationTypeSum:
+		return e.sum
+	case types.RangeAggregationTypeAvg:
+		return e.mean
+	case types.RangeAggregationTypeMin:
+		return e.min
+	case types.RangeAggregationTypeMax:
+		return e.max
+	case types.RangeAggregationTypeFirst:
+		return e.first
+	case types.RangeAggregationTypeLast:
+		return e.last
+	case types.RangeAggregationTypeStddev:
+		return math.Sqrt(e.aux / e.count)
+	case types.RangeAggregationTypeStdvar:
+		return e.aux / e.count
+	case types.RangeAggregationTypeQuantile:
+		return quantile(parameter, e.allValues)
+	case types.RangeAggregationTypeAbsent:
+		return 1
+	case types.RangeAggregationTypeChanges:
+		e.sortSamples()
+		return changes(e.allValues)
+	case types.RangeAggregationTypeResets:
+		e.sortSamples()
+		return resets(e.allValues)
+	case types.RangeAggregationTypeDeriv:
+		e.sortSamples()
+		return deriv(e.allTimestamps, e.allValues)
+	case types.RangeAggregationTypePredictLinear:
+		e.sortSamples()
+		return predictLinear(parameter, e.allTimestamps, e.allValues)
+	default:
+		return math.NaN()
+	}
+}
+
+// sortSamples sorts allValues and allTimestamps by timestamp, since samples
+// are not guaranteed to arrive in order. Samples with equal timestamps keep
+// the order in which they were added.
+func (e *partitionEntry) sortSamples() {
+	sort.Stable((*samplesByTimestamp)(e))
+}
+
+// samplesByTimestamp implements [sort.Interface] for the samples of a
+// [partitionEntry].
+type samplesByTimestamp partitionEntry
+
+func (s *samplesByTimestamp) Len() int           { return len(s.allTimestamps) }
+func (s *samplesByTimestamp) Less(i, j int) bool { return s.allTimestamps[i] < s.allTimestamps[j] }
+func (s *samplesByTimestamp) Swap(i, j int) {
+	s.allTimestamps[i], s.allTimestamps[j] = s.allTimestamps[j], s.allTimestamps[i]
+	s.allValues[i], s.allValues[j] = s.allValues[j], s.allValues[i]
+}
+
+// changes returns the number of times the value changed between consecutive
+// values, in the same way as changes_over_time in the old engine.
+func changes(values []float64) float64 {
+	var n float64
+	for i := 1; i < len(values); i++ {
+		prev, cur := values[i-1], values[i]
+		if cur != prev && !(math.IsNaN(cur) && math.IsNaN(prev)) {
+			n++
 		}
 	}
+	return n
+}
+
+// resets returns the number of times the value decreased between consecutive
+// values, in the same way as resets_over_time in the old engine.
+func resets(values []float64) float64 {
+	var n float64
+	for i := 1; i < len(values); i++ {
+		if values[i] < values[i-1] {
+			n++
+		}
+	}
+	return n
+}
+
+// deriv returns the per-second derivative of the values, in the same way as
+// deriv in the old engine. It returns NaN for less than two values.
+func deriv(timestamps []int64, values []float64) float64 {
+	if len(values) < 2 {
+		return math.NaN()
+	}
+	slope, _ := linearRegression(timestamps, values, timestamps[len(timestamps)-1])
+	return slope
+}
+
+// predictLinear returns the value predicted in duration seconds after the
+// last value, in the same way as predict_linear in the old engine. It returns
+// NaN for less than two values.
+func predictLinear(duration float64, timestamps []int64, values []float64) float64 {
+	if len(values) < 2 {
+		return math.NaN()
+	}
+	slope, intercept := linearRegression(timestamps, values, timestamps[len(timestamps)-1])
+	return slope*duration + intercept
+}
+
+// linearRegression returns the slope per second and the intercept at
+// interceptTime of the least squares fit of the values. Timestamps are in
+// nanoseconds.
+func linearRegression(timestamps []int64, values []float64, interceptTime int64) (slope, intercept float64) {
+	var (
+		n            float64
+		sumX, sumY   float64
+		sumXY, sumX2 float64
+		constY       = true
+	)
+	for i, y := range values {
+		if constY && i > 0 && y != values[0] {
+			constY = false
+		}
+		n++
+		x := float64(timestamps[i]-interceptTime) / 1e9
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumX2 += x * x
+	}
+	if constY {
+		if math.IsInf(values[0], 0) {
+			return math.NaN(), math.NaN()
+		}
+		return 0, values[0]
+	}
+	covXY := sumXY - sumX*sumY/n
+	varX := sumX2 - sumX*sumX/n
+
+	slope = covXY / varX
+	intercept = sumY/n - slope*sumX/n
+	return slope, intercept
+}
+
+// quantile calculates the q-quantile of the given values, using the same
+// interpolation as quantile_over_time in the old engine.
+// The values are sorted in place.
+func quantile(q float64, values []float64) float64 {
+	if len(values) == 0 {
+		return math.NaN()
+	}
+	if q < 0 {
+		return math.Inf(-1)
+	}
+	if q > 1 {
+		return math.Inf(+1)
+	}
+	slices.Sort(values)
+
+	n := float64(len(values))
+	// When the quantile lies between two samples,
+	// we use a weighted average of the two samples.
+	rank := q * (n - 1)
+
+	lowerIndex := math.Max(0, math.Floor(rank))
+	upperIndex := math.Min(n-1, lowerIndex+1)
+
+	weight := rank - math.Floor(rank)
+	return values[int(lowerIndex)]*(1-weight) + values[int(upperIndex)]*weight
 }
 
 func (a *partitionAggregator) Reset() {
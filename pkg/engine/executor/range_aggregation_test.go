@@ -1,18 +1,22 @@
 package executor
 
 import (
+	"errors"
 	"fmt"
+	"math"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/apache/arrow-go/v18/arrow"
 	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/prometheus/prometheus/model/labels"
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/loki/v3/pkg/engine/internal/datatype"
 	"github.com/grafana/loki/v3/pkg/engine/internal/types"
 	"github.com/grafana/loki/v3/pkg/engine/planner/physical"
+	"github.com/grafana/loki/v3/pkg/logqlmodel"
 )
 
 const arrowTimestampFormat = "2006-01-02T15:04:05.000000000Z"
@@ -78,6 +82,7 @@ func TestRangeAggregationPipeline(t *testing.T) {
 				},
 			},
 		},
+		operation:     types.RangeAggregationTypeCount,
 		startTs:       now,
 		endTs:         now,
 		rangeInterval: 10 * time.Minute,
@@ -95,7 +100,7 @@ func TestRangeAggregationPipeline(t *testing.T) {
 	defer record.Release()
 
 	// Define expected results
-	expected := map[string]int64{
+	expected := map[string]float64{
 		"prod,app1": 2,
 		"prod,app2": 3,
 		"prod,app3": 2,
@@ -104,11 +109,11 @@ func TestRangeAggregationPipeline(t *testing.T) {
 
 	require.Equal(t, int64(len(expected)), record.NumRows(), "number of records should match")
 
-	actual := make(map[string]int64)
+	actual := make(map[string]float64)
 	for i := range int(record.NumRows()) {
 		require.Equal(t, record.Column(0).(*array.Timestamp).Value(i).ToTime(arrow.Nanosecond), now)
 
-		value := record.Column(1).(*array.Int64).Value(i)
+		value := record.Column(1).(*array.Float64).Value(i)
 		env := record.Column(2).(*array.String).Value(i)
 		service := record.Column(3).(*array.String).Value(i)
 		key := fmt.Sprintf("%s,%s", env, service)
@@ -117,3 +122,518 @@ func TestRangeAggregationPipeline(t *testing.T) {
 
 	require.EqualValues(t, expected, actual, "aggregation results should match")
 }
+
+func TestRangeAggregationPipeline_RangeQuery(t *testing.T) {
+	fields := []arrow.Field{
+		{Name: types.ColumnNameBuiltinTimestamp, Type: datatype.Arrow.Timestamp, Metadata: datatype.ColumnMetadataBuiltinTimestamp},
+		{Name: "env", Type: datatype.Arrow.String, Metadata: datatype.ColumnMetadata(types.ColumnTypeLabel, datatype.Loki.String)},
+	}
+
+	start := time.Unix(1000, 0).UTC()
+	inputCSV := strings.Join([]string{
+		fmt.Sprintf("%s,prod", start.Add(-1*time.Minute).Format(arrowTimestampFormat)), // steps at start, start+1m
+		fmt.Sprintf("%s,prod", start.Add(30*time.Second).Format(arrowTimestampFormat)), // steps at start+1m, start+2m
+		fmt.Sprintf("%s,dev", start.Add(90*time.Second).Format(arrowTimestampFormat)),  // steps at start+2m, start+3m
+		fmt.Sprintf("%s,dev", start.Add(3*time.Minute).Format(arrowTimestampFormat)),   // excluded, after end
+	}, "\n")
+
+	record, err := CSVToArrow(fields, inputCSV)
+	require.NoError(t, err)
+	defer record.Release()
+
+	opts := rangeAggregationOptions{
+		partitionBy: []physical.ColumnExpression{
+			&physical.ColumnExpr{Ref: types.ColumnRef{Column: "env", Type: types.ColumnTypeAmbiguous}},
+		},
+		operation:     types.RangeAggregationTypeCount,
+		startTs:       start,
+		endTs:         start.Add(3 * time.Minute),
+		rangeInterval: 2 * time.Minute,
+		step:          time.Minute,
+	}
+
+	pipeline, err := NewRangeAggregationPipeline([]Pipeline{NewBufferedPipeline(record)}, expressionEvaluator{}, opts)
+	require.NoError(t, err)
+	defer pipeline.Close()
+
+	// one record is emitted per step
+	actual := make(map[time.Time]map[string]float64)
+	var steps []time.Time
+	for {
+		err := pipeline.Read(t.Context())
+		if errors.Is(err, EOF) {
+			break
+		}
+		require.NoError(t, err)
+
+		rec, err := pipeline.Value()
+		require.NoError(t, err)
+
+		ts := rec.Column(0).(*array.Timestamp).Value(0).ToTime(arrow.Nanosecond)
+		steps = append(steps, ts)
+		actual[ts] = make(map[string]float64)
+		for i := range int(rec.NumRows()) {
+			require.Equal(t, ts, rec.Column(0).(*array.Timestamp).Value(i).ToTime(arrow.Nanosecond), "record must only contain a single step")
+			actual[ts][rec.Column(2).(*array.String).Value(i)] = rec.Column(1).(*array.Float64).Value(i)
+		}
+	}
+
+	expected := map[time.Time]map[string]float64{
+		start:                      {"prod": 1},
+		start.Add(1 * time.Minute): {"prod": 2},
+		start.Add(2 * time.Minute): {"prod": 1, "dev": 1},
+		start.Add(3 * time.Minute): {"dev": 1},
+	}
+	require.Equal(t, expected, actual)
+	require.IsIncreasing(t, steps, "steps must be emitted in ascending order")
+}
+
+// TestRangeAggregationPipeline_PreAggregation verifies that summing up range
+// aggregations of each input, as planned by the pre-aggregation of the
+// physical planner, yields the same result as aggregating all inputs at once.
+func TestRangeAggregationPipeline_PreAggregation(t *testing.T) {
+	fields := []arrow.Field{
+		{Name: types.ColumnNameBuiltinTimestamp, Type: datatype.Arrow.Timestamp, Metadata: datatype.ColumnMetadataBuiltinTimestamp},
+		{Name: "env", Type: datatype.Arrow.String, Metadata: datatype.ColumnMetadata(types.ColumnTypeLabel, datatype.Loki.String)},
+	}
+
+	start := time.Unix(1000, 0).UTC()
+	input1CSV := strings.Join([]string{
+		fmt.Sprintf("%s,prod", start.Add(-30*time.Second).Format(arrowTimestampFormat)),
+		fmt.Sprintf("%s,dev", start.Add(30*time.Second).Format(arrowTimestampFormat)),
+		fmt.Sprintf("%s,prod", start.Add(90*time.Second).Format(arrowTimestampFormat)),
+	}, "\n")
+	input2CSV := strings.Join([]string{
+		fmt.Sprintf("%s,prod", start.Add(-20*time.Second).Format(arrowTimestampFormat)),
+		fmt.Sprintf("%s,prod", start.Add(40*time.Second).Format(arrowTimestampFormat)),
+	}, "\n")
+
+	// Pipelines release the records they read, so each pipeline reads its
+	// own copy of the input.
+	input := func(csv string) Pipeline {
+		record, err := CSVToArrow(fields, csv)
+		require.NoError(t, err)
+		return NewBufferedPipeline(record)
+	}
+
+	partitionBy := []physical.ColumnExpression{
+		&physical.ColumnExpr{Ref: types.ColumnRef{Column: "env", Type: types.ColumnTypeAmbiguous}},
+	}
+
+	for _, operation := range []types.RangeAggregationType{types.RangeAggregationTypeCount, types.RangeAggregationTypeRate} {
+		t.Run(operation.String(), func(t *testing.T) {
+			opts := rangeAggregationOptions{
+				partitionBy:   partitionBy,
+				operation:     operation,
+				startTs:       start,
+				endTs:         start.Add(2 * time.Minute),
+				rangeInterval: time.Minute,
+				step:          time.Minute,
+			}
+			readAll := func(pipeline Pipeline) map[string]float64 {
+				defer pipeline.Close()
+				result := make(map[string]float64)
+				for {
+					err := pipeline.Read(t.Context())
+					if errors.Is(err, EOF) {
+						return result
+					}
+					require.NoError(t, err)
+					rec, _ := pipeline.Value()
+					for i := range int(rec.NumRows()) {
+						ts := rec.Column(0).(*array.Timestamp).Value(i).ToTime(arrow.Nanosecond)
+						result[fmt.Sprintf("%s,%s", ts.Format(time.RFC3339), rec.Column(2).(*array.String).Value(i))] = rec.Column(1).(*array.Float64).Value(i)
+					}
+				}
+			}
+
+			aggregated, err := NewRangeAggregationPipeline([]Pipeline{input(input1CSV), input(input2CSV)}, expressionEvaluator{}, opts)
+			require.NoError(t, err)
+			expected := readAll(aggregated)
+			require.NotEmpty(t, expected)
+
+			partial1, err := NewRangeAggregationPipeline([]Pipeline{input(input1CSV)}, expressionEvaluator{}, opts)
+			require.NoError(t, err)
+			partial2, err := NewRangeAggregationPipeline([]Pipeline{input(input2CSV)}, expressionEvaluator{}, opts)
+			require.NoError(t, err)
+			merge, err := NewSortMergePipeline([]Pipeline{partial1, partial2}, physical.ASC, &physical.ColumnExpr{
+				Ref: types.ColumnRef{Column: types.ColumnNameBuiltinTimestamp, Type: types.ColumnTypeBuiltin},
+			}, expressionEvaluator{}, nil)
+			require.NoError(t, err)
+			sum, err := NewVectorAggregationPipeline([]Pipeline{merge}, expressionEvaluator{}, vectorAggregationOptions{
+				groupBy:   partitionBy,
+				operation: types.VectorAggregationTypeSum,
+			})
+			require.NoError(t, err)
+
+			require.Equal(t, expected, readAll(sum))
+		})
+	}
+}
+
+func TestRangeAggregationPipeline_Operations(t *testing.T) {
+	fields := []arrow.Field{
+		{Name: types.ColumnNameBuiltinTimestamp, Type: datatype.Arrow.Timestamp, Metadata: datatype.ColumnMetadataBuiltinTimestamp},
+		{Name: types.ColumnNameBuiltinMessage, Type: datatype.Arrow.String, Metadata: datatype.ColumnMetadataBuiltinMessage},
+		{Name: "env", Type: datatype.Arrow.String, Metadata: datatype.ColumnMetadata(types.ColumnTypeLabel, datatype.Loki.String)},
+		{Name: "latency", Type: datatype.Arrow.String, Metadata: datatype.ColumnMetadata(types.ColumnTypeMetadata, datatype.Loki.String)},
+	}
+
+	now := time.Unix(1000, 0).UTC()
+	inputCSV := strings.Join([]string{
+		fmt.Sprintf("%s,hello,prod,4", now.Add(-1*time.Minute).Format(arrowTimestampFormat)),
+		fmt.Sprintf("%s,hi,prod,1", now.Add(-3*time.Minute).Format(arrowTimestampFormat)),
+		fmt.Sprintf("%s,hey,prod,2", now.Add(-2*time.Minute).Format(arrowTimestampFormat)),
+		fmt.Sprintf("%s,foo,prod,", now.Add(-2*time.Minute).Format(arrowTimestampFormat)), // skipped when unwrapping
+		fmt.Sprintf("%s,hello world,dev,8", now.Add(-1*time.Minute).Format(arrowTimestampFormat)),
+	}, "\n")
+
+	unwrap := &physical.ColumnExpr{Ref: types.ColumnRef{Column: "latency", Type: types.ColumnTypeAmbiguous}}
+
+	for _, tt := range []struct {
+		operation types.RangeAggregationType
+		unwrap    physical.Expression
+		parameter float64
+		expected  map[string]float64
+	}{
+		{operation: types.RangeAggregationTypeCount, expected: map[string]float64{"prod": 4, "dev": 1}},
+		{operation: types.RangeAggregationTypeRate, expected: map[string]float64{"prod": 4.0 / 300, "dev": 1.0 / 300}},
+		{operation: types.RangeAggregationTypeRate, unwrap: unwrap, expected: map[string]float64{"prod": 7.0 / 300, "dev": 8.0 / 300}},
+		{operation: types.RangeAggregationTypeBytes, expected: map[string]float64{"prod": 13, "dev": 11}},
+		{operation: types.RangeAggregationTypeBytesRate, expected: map[string]float64{"prod": 13.0 / 300, "dev": 11.0 / 300}},
+		{operation: types.RangeAggregationTypeSum, unwrap: unwrap, expected: map[string]float64{"prod": 7, "dev": 8}},
+		{operation: types.RangeAggregationTypeSum, unwrap: &physical.UnaryExpr{Left: unwrap, Op: types.UnaryOpCastBytes}, expected: map[string]float64{"prod": 7, "dev": 8}},
+		{operation: types.RangeAggregationTypeAvg, unwrap: unwrap, expected: map[string]float64{"prod": 7.0 / 3, "dev": 8}},
+		{operation: types.RangeAggregationTypeMin, unwrap: unwrap, expected: map[string]float64{"prod": 1, "dev": 8}},
+		{operation: types.RangeAggregationTypeMax, unwrap: unwrap, expected: map[string]float64{"prod": 4, "dev": 8}},
+		{operation: types.RangeAggregationTypeFirst, unwrap: unwrap, expected: map[string]float64{"prod": 1, "dev": 8}},
+		{operation: types.RangeAggregationTypeLast, unwrap: unwrap, expected: map[string]float64{"prod": 4, "dev": 8}},
+		{operation: types.RangeAggregationTypeStdvar, unwrap: unwrap, expected: map[string]float64{"prod": 14.0 / 9, "dev": 0}},
+		{operation: types.RangeAggregationTypeQuantile, unwrap: unwrap, parameter: 0.5, expected: map[string]float64{"prod": 2, "dev": 8}},
+		{operation: types.RangeAggregationTypeQuantile, unwrap: unwrap, parameter: 0.75, expected: map[string]float64{"prod": 3, "dev": 8}},
+		{operation: types.RangeAggregationTypeChanges, unwrap: unwrap, expected: map[string]float64{"prod": 2, "dev": 0}},
+		{operation: types.RangeAggregationTypeResets, unwrap: unwrap, expected: map[string]float64{"prod": 0, "dev": 0}},
+		{operation: types.RangeAggregationTypeDeriv, unwrap: unwrap, expected: map[string]float64{"prod": 0.025, "dev": math.NaN()}},
+		{operation: types.RangeAggregationTypePredictLinear, unwrap: unwrap, parameter: 60, expected: map[string]float64{"prod": 16.0 / 3, "dev": math.NaN()}},
+	} {
+		t.Run(tt.operation.String(), func(t *testing.T) {
+			record, err := CSVToArrow(fields, inputCSV)
+			require.NoError(t, err)
+			defer record.Release()
+
+			opts := rangeAggregationOptions{
+				partitionBy: []physical.ColumnExpression{
+					&physical.ColumnExpr{Ref: types.ColumnRef{Column: "env", Type: types.ColumnTypeAmbiguous}},
+				},
+				operation:     tt.operation,
+				unwrap:        tt.unwrap,
+				parameter:     tt.parameter,
+				startTs:       now,
+				endTs:         now,
+				rangeInterval: 5 * time.Minute,
+			}
+
+			pipeline, err := NewRangeAggregationPipeline([]Pipeline{NewBufferedPipeline(record)}, expressionEvaluator{}, opts)
+			require.NoError(t, err)
+			defer pipeline.Close()
+
+			require.NoError(t, pipeline.Read(t.Context()))
+			rec, err := pipeline.Value()
+			require.NoError(t, err)
+
+			actual := make(map[string]float64)
+			for i := range int(rec.NumRows()) {
+				actual[rec.Column(2).(*array.String).Value(i)] = rec.Column(1).(*array.Float64).Value(i)
+			}
+			require.Len(t, actual, len(tt.expected))
+			for k, v := range tt.expected {
+				if math.IsNaN(v) {
+					require.True(t, math.IsNaN(actual[k]), "partition %s", k)
+					continue
+				}
+				require.InDelta(t, v, actual[k], 1e-9, "partition %s", k)
+			}
+
+			require.ErrorIs(t, pipeline.Read(t.Context()), EOF)
+		})
+	}
+
+	t.Run("unwrap is required", func(t *testing.T) {
+		_, err := NewRangeAggregationPipeline(nil, expressionEvaluator{}, rangeAggregationOptions{
+			operation: types.RangeAggregationTypeSum,
+		})
+		require.ErrorContains(t, err, "requires an unwrap expression")
+	})
+
+	t.Run("unwrapped value cannot be converted", func(t *testing.T) {
+		record, err := CSVToArrow(fields, fmt.Sprintf("%s,foo,prod,invalid", now.Add(-2*time.Minute).Format(arrowTimestampFormat)))
+		require.NoError(t, err)
+		defer record.Release()
+
+		pipeline, err := NewRangeAggregationPipeline([]Pipeline{NewBufferedPipeline(record)}, expressionEvaluator{}, rangeAggregationOptions{
+			partitionBy: []physical.ColumnExpression{
+				&physical.ColumnExpr{Ref: types.ColumnRef{Column: "env", Type: types.ColumnTypeAmbiguous}},
+			},
+			operation:     types.RangeAggregationTypeSum,
+			unwrap:        unwrap,
+			startTs:       now,
+			endTs:         now,
+			rangeInterval: 5 * time.Minute,
+		})
+		require.NoError(t, err)
+		defer pipeline.Close()
+
+		// Like the old engine, the query fails with the error label of the
+		// series instead of skipping the sample.
+		err = pipeline.Read(t.Context())
+		require.ErrorIs(t, err, logqlmodel.ErrPipeline)
+		require.ErrorContains(t, err, `__error__="SampleExtractionErr"`)
+		require.ErrorContains(t, err, `env="prod"`)
+	})
+}
+
+func TestRangeAggregationPipeline_ImplicitPartitions(t *testing.T) {
+	now := time.Unix(1000, 0).UTC()
+
+	// records with different label columns
+	record1, err := CSVToArrow([]arrow.Field{
+		{Name: types.ColumnNameBuiltinTimestamp, Type: datatype.Arrow.Timestamp, Metadata: datatype.ColumnMetadataBuiltinTimestamp},
+		{Name: "env", Type: datatype.Arrow.String, Metadata: datatype.ColumnMetadata(types.ColumnTypeLabel, datatype.Loki.String)},
+	}, strings.Join([]string{
+		fmt.Sprintf("%s,prod", now.Add(-1*time.Minute).Format(arrowTimestampFormat)),
+		fmt.Sprintf("%s,dev", now.Add(-1*time.Minute).Format(arrowTimestampFormat)),
+	}, "\n"))
+	require.NoError(t, err)
+	defer record1.Release()
+
+	record2, err := CSVToArrow([]arrow.Field{
+		{Name: types.ColumnNameBuiltinTimestamp, Type: datatype.Arrow.Timestamp, Metadata: datatype.ColumnMetadataBuiltinTimestamp},
+		{Name: "env", Type: datatype.Arrow.String, Metadata: datatype.ColumnMetadata(types.ColumnTypeLabel, datatype.Loki.String)},
+		{Name: "service", Type: datatype.Arrow.String, Metadata: datatype.ColumnMetadata(types.ColumnTypeLabel, datatype.Loki.String)},
+	}, strings.Join([]string{
+		fmt.Sprintf("%s,prod,", now.Add(-2*time.Minute).Format(arrowTimestampFormat)),
+		fmt.Sprintf("%s,prod,app1", now.Add(-2*time.Minute).Format(arrowTimestampFormat)),
+	}, "\n"))
+	require.NoError(t, err)
+	defer record2.Release()
+
+	pipeline, err := NewRangeAggregationPipeline([]Pipeline{NewBufferedPipeline(record1, record2)}, expressionEvaluator{}, rangeAggregationOptions{
+		operation:     types.RangeAggregationTypeCount,
+		startTs:       now,
+		endTs:         now,
+		rangeInterval: 5 * time.Minute,
+	})
+	require.NoError(t, err)
+	defer pipeline.Close()
+
+	require.NoError(t, pipeline.Read(t.Context()))
+	rec, err := pipeline.Value()
+	require.NoError(t, err)
+	require.Equal(t, int64(4), rec.NumCols())
+
+	actual := make(map[string]float64)
+	for i := range int(rec.NumRows()) {
+		env := rec.Column(2).(*array.String).Value(i)
+		service := rec.Column(3).(*array.String).Value(i)
+		actual[env+","+service] = rec.Column(1).(*array.Float64).Value(i)
+	}
+
+	require.Equal(t, map[string]float64{
+		"prod,":     2,
+		"dev,":      1,
+		"prod,app1": 1,
+	}, actual)
+}
+
+func TestRangeAggregationPipeline_Absent(t *testing.T) {
+	fields := []arrow.Field{
+		{Name: types.ColumnNameBuiltinTimestamp, Type: datatype.Arrow.Timestamp, Metadata: datatype.ColumnMetadataBuiltinTimestamp},
+		{Name: "env", Type: datatype.Arrow.String, Metadata: datatype.ColumnMetadata(types.ColumnTypeLabel, datatype.Loki.String)},
+	}
+
+	start := time.Unix(1000, 0).UTC()
+	record, err := CSVToArrow(fields, fmt.Sprintf("%s,prod", start.Add(30*time.Second).Format(arrowTimestampFormat)))
+	require.NoError(t, err)
+	defer record.Release()
+
+	pipeline, err := NewRangeAggregationPipeline([]Pipeline{NewBufferedPipeline(record)}, expressionEvaluator{}, rangeAggregationOptions{
+		operation:     types.RangeAggregationTypeAbsent,
+		startTs:       start,
+		endTs:         start.Add(2 * time.Minute),
+		rangeInterval: time.Minute,
+		step:          time.Minute,
+		absentLabels:  []labels.Label{{Name: "env", Value: "prod"}},
+	})
+	require.NoError(t, err)
+	defer pipeline.Close()
+
+	// only steps without any entries yield a value
+	require.Equal(t, []time.Time{start, start.Add(2 * time.Minute)}, readAbsentSteps(t, pipeline, "prod"))
+}
+
+func TestRangeAggregationPipeline_AbsentWithoutInputs(t *testing.T) {
+	start := time.Unix(1000, 0).UTC()
+	pipeline, err := NewRangeAggregationPipeline(nil, expressionEvaluator{}, rangeAggregationOptions{
+		operation:     types.RangeAggregationTypeAbsent,
+		startTs:       start,
+		endTs:         start.Add(time.Minute),
+		rangeInterval: time.Minute,
+		step:          time.Minute,
+		absentLabels:  []labels.Label{{Name: "env", Value: "dev"}},
+	})
+	require.NoError(t, err)
+	defer pipeline.Close()
+
+	require.Equal(t, []time.Time{start, start.Add(time.Minute)}, readAbsentSteps(t, pipeline, "dev"))
+}
+
+// readAbsentSteps reads all records of an absent_over_time pipeline, checks
+// that each one holds a single series with the env label set to env and
+// returns their timestamps.
+func readAbsentSteps(t *testing.T, pipeline Pipeline, env string) []time.Time {
+	t.Helper()
+
+	var steps []time.Time
+	for {
+		err := pipeline.Read(t.Context())
+		if errors.Is(err, EOF) {
+			break
+		}
+		require.NoError(t, err)
+
+		rec, err := pipeline.Value()
+		require.NoError(t, err)
+		require.Equal(t, int64(1), rec.NumRows())
+		require.Equal(t, int64(3), rec.NumCols())
+		require.Equal(t, 1.0, rec.Column(1).(*array.Float64).Value(0))
+		require.Equal(t, "env", rec.ColumnName(2))
+		require.Equal(t, env, rec.Column(2).(*array.String).Value(0))
+
+		steps = append(steps, rec.Column(0).(*array.Timestamp).Value(0).ToTime(arrow.Nanosecond))
+	}
+	return steps
+}
+
+func TestRangeAggregationPipeline_Spill(t *testing.T) {
+	fields := []arrow.Field{
+		{Name: types.ColumnNameBuiltinTimestamp, Type: datatype.Arrow.Timestamp, Metadata: datatype.ColumnMetadataBuiltinTimestamp},
+		{Name: "env", Type: datatype.Arrow.String, Metadata: datatype.ColumnMetadata(types.ColumnTypeLabel, datatype.Loki.String)},
+		{Name: "latency", Type: datatype.Arrow.String, Metadata: datatype.ColumnMetadata(types.ColumnTypeMetadata, datatype.Loki.String)},
+	}
+
+	// 8 partitions with a sample every 15 seconds over 10 minutes
+	start := time.Unix(1000, 0).UTC()
+	var rows []string
+	for i := range 40 {
+		for env := range 8 {
+			ts := start.Add(time.Duration(i) * 15 * time.Second)
+			rows = append(rows, fmt.Sprintf("%s,env%d,%d", ts.Format(arrowTimestampFormat), env, (i*7+env*3)%11))
+		}
+	}
+	inputCSV := strings.Join(rows, "\n")
+
+	unwrap := &physical.ColumnExpr{Ref: types.ColumnRef{Column: "latency", Type: types.ColumnTypeAmbiguous}}
+
+	run := func(t *testing.T, opts rangeAggregationOptions) (map[time.Time]map[string]float64, error) {
+		record, err := CSVToArrow(fields, inputCSV)
+		require.NoError(t, err)
+		defer record.Release()
+
+		opts.partitionBy = []physical.ColumnExpression{
+			&physical.ColumnExpr{Ref: types.ColumnRef{Column: "env", Type: types.ColumnTypeAmbiguous}},
+		}
+		opts.startTs = start.Add(5 * time.Minute)
+		opts.endTs = start.Add(10 * time.Minute)
+		opts.rangeInterval = 5 * time.Minute
+		opts.step = 30 * time.Second
+
+		pipeline, err := NewRangeAggregationPipeline([]Pipeline{NewBufferedPipeline(record)}, expressionEvaluator{}, opts)
+		require.NoError(t, err)
+		defer func() {
+			pipeline.Close()
+			if opts.memory.budget != nil {
+				require.Zero(t, opts.memory.budget.used.Load(), "memory must be released on close")
+			}
+		}()
+
+		actual := make(map[time.Time]map[string]float64)
+		for {
+			err := pipeline.Read(t.Context())
+			if errors.Is(err, EOF) {
+				break
+			} else if err != nil {
+				return nil, err
+			}
+
+			rec, err := pipeline.Value()
+			require.NoError(t, err)
+			for i := range int(rec.NumRows()) {
+				ts := rec.Column(0).(*array.Timestamp).Value(i).ToTime(arrow.Nanosecond)
+				if actual[ts] == nil {
+					actual[ts] = make(map[string]float64)
+				}
+				actual[ts][rec.Column(2).(*array.String).Value(i)] = rec.Column(1).(*array.Float64).Value(i)
+			}
+		}
+
+		if opts.memory.budget != nil {
+			require.NotEmpty(t, pipeline.spills, "state must be spilled")
+		}
+		return actual, nil
+	}
+
+	for _, tt := range []struct {
+		operation types.RangeAggregationType
+		unwrap    physical.Expression
+		parameter float64
+	}{
+		{operation: types.RangeAggregationTypeCount},
+		{operation: types.RangeAggregationTypeSum, unwrap: unwrap},
+		{operation: types.RangeAggregationTypeAvg, unwrap: unwrap},
+		{operation: types.RangeAggregationTypeMin, unwrap: unwrap},
+		{operation: types.RangeAggregationTypeMax, unwrap: unwrap},
+		{operation: types.RangeAggregationTypeFirst, unwrap: unwrap},
+		{operation: types.RangeAggregationTypeLast, unwrap: unwrap},
+		{operation: types.RangeAggregationTypeStdvar, unwrap: unwrap},
+		{operation: types.RangeAggregationTypeQuantile, unwrap: unwrap, parameter: 0.9},
+		{operation: types.RangeAggregationTypeChanges, unwrap: unwrap},
+		{operation: types.RangeAggregationTypeResets, unwrap: unwrap},
+		{operation: types.RangeAggregationTypeDeriv, unwrap: unwrap},
+		{operation: types.RangeAggregationTypePredictLinear, unwrap: unwrap, parameter: 300},
+	} {
+		t.Run(tt.operation.String(), func(t *testing.T) {
+			opts := rangeAggregationOptions{
+				operation: tt.operation,
+				unwrap:    tt.unwrap,
+				parameter: tt.parameter,
+			}
+
+			expected, err := run(t, opts)
+			require.NoError(t, err)
+
+			// The budget fits the partitions of a few steps, but not all of them.
+			opts.memory = memoryConfig{
+				budget:   newMemoryBudget(4 * 8 * (partitionEntrySize + 8*20 + 8)),
+				spillDir: t.TempDir(),
+			}
+			actual, err := run(t, opts)
+			require.NoError(t, err)
+
+			require.Len(t, actual, len(expected))
+			for ts, partitions := range expected {
+				require.Len(t, actual[ts], len(partitions), "step %s", ts)
+				for k, v := range partitions {
+					require.InDelta(t, v, actual[ts][k], 1e-9, "partition %s at step %s", k, ts)
+				}
+			}
+		})
+	}
+
+	t.Run("limit exceeded without spill directory", func(t *testing.T) {
+		_, err := run(t, rangeAggregationOptions{
+			operation: types.RangeAggregationTypeCount,
+			memory:    memoryConfig{budget: newMemoryBudget(4 * 8 * partitionEntrySize)},
+		})
+		require.ErrorIs(t, err, logqlmodel.ErrLimit)
+	})
+}
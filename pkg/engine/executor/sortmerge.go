@@ -16,7 +16,9 @@ import (
 type compareFunc[T comparable] func(a, b T) bool
 
 // NewSortMergePipeline returns a new pipeline that merges already sorted inputs into a single output.
-func NewSortMergePipeline(inputs []Pipeline, order physical.SortOrder, column physical.ColumnExpression, evaluator expressionEvaluator) (*KWayMerge, error) {
+// The batches buffered for each input are charged to budget, so that merging
+// many inputs fails with the memory limit error rather than exhausting memory.
+func NewSortMergePipeline(inputs []Pipeline, order physical.SortOrder, column physical.ColumnExpression, evaluator expressionEvaluator, budget *memoryBudget) (*KWayMerge, error) {
 	var lessFunc func(a, b int64) bool
 	switch order {
 	case physical.ASC:
@@ -28,7 +30,7 @@ func NewSortMergePipeline(inputs []Pipeline, order physical.SortOrder, column ph
 	}
 
 	for i := range inputs {
-		inputs[i] = newPrefetchingPipeline(inputs[i])
+		inputs[i] = newPrefetchingPipeline(inputs[i], budget)
 	}
 
 	return &KWayMerge{
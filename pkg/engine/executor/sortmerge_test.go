@@ -1,6 +1,7 @@
 package executor
 
 import (
+	"errors"
 	"slices"
 	"testing"
 	"time"
@@ -11,6 +12,7 @@ import (
 
 	"github.com/grafana/loki/v3/pkg/engine/internal/types"
 	"github.com/grafana/loki/v3/pkg/engine/planner/physical"
+	"github.com/grafana/loki/v3/pkg/logqlmodel"
 )
 
 func TestSortMerge(t *testing.T) {
@@ -37,7 +39,7 @@ func TestSortMerge(t *testing.T) {
 			ascendingTimestampPipeline(now.Add(3*time.Nanosecond)).Pipeline(batchSize, 10),
 		}
 
-		pipeline, err := NewSortMergePipeline(inputs, merge.Order, merge.Column, expressionEvaluator{})
+		pipeline, err := NewSortMergePipeline(inputs, merge.Order, merge.Column, expressionEvaluator{}, nil)
 		require.NoError(t, err)
 
 		ctx := t.Context()
@@ -62,7 +64,7 @@ func TestSortMerge(t *testing.T) {
 			ascendingTimestampPipeline(now.Add(3*time.Second)).Pipeline(batchSize, 10),
 		}
 
-		pipeline, err := NewSortMergePipeline(inputs, merge.Order, merge.Column, expressionEvaluator{})
+		pipeline, err := NewSortMergePipeline(inputs, merge.Order, merge.Column, expressionEvaluator{}, nil)
 		require.NoError(t, err)
 
 		ctx := t.Context()
@@ -110,7 +112,7 @@ func TestSortMerge(t *testing.T) {
 			descendingTimestampPipeline(now.Add(3*time.Second)).Pipeline(batchSize, 10),
 		}
 
-		pipeline, err := NewSortMergePipeline(inputs, merge.Order, merge.Column, expressionEvaluator{})
+		pipeline, err := NewSortMergePipeline(inputs, merge.Order, merge.Column, expressionEvaluator{}, nil)
 		require.NoError(t, err)
 
 		ctx := t.Context()
@@ -140,4 +142,53 @@ func TestSortMerge(t *testing.T) {
 			slices.IsSortedFunc(timestamps, func(a, b arrow.Timestamp) int { return int(b - a) }),
 			"timestamps are not sorted in DESC order: %v", timestamps)
 	})
+
+	t.Run("memory limit", func(t *testing.T) {
+		column := &physical.ColumnExpr{
+			Ref: types.ColumnRef{
+				Column: types.ColumnNameBuiltinTimestamp,
+				Type:   types.ColumnTypeBuiltin,
+			},
+		}
+
+		newInputs := func() []Pipeline {
+			return []Pipeline{
+				ascendingTimestampPipeline(now.Add(1*time.Nanosecond)).Pipeline(batchSize, 10),
+				ascendingTimestampPipeline(now.Add(2*time.Nanosecond)).Pipeline(batchSize, 10),
+				ascendingTimestampPipeline(now.Add(3*time.Nanosecond)).Pipeline(batchSize, 10),
+			}
+		}
+
+		// the batches buffered for all inputs don't fit into the budget
+		budget := newMemoryBudget(64)
+		pipeline, err := NewSortMergePipeline(newInputs(), physical.ASC, column, expressionEvaluator{}, budget)
+		require.NoError(t, err)
+
+		err = pipeline.Read(t.Context())
+		require.ErrorIs(t, err, logqlmodel.ErrLimit)
+		pipeline.Close()
+		require.Equal(t, int64(0), budget.used.Load())
+
+		// the reservations of all batches are returned once the pipeline is closed
+		budget = newMemoryBudget(1 << 20)
+		pipeline, err = NewSortMergePipeline(newInputs(), physical.ASC, column, expressionEvaluator{}, budget)
+		require.NoError(t, err)
+
+		var rows int64
+		for {
+			err := pipeline.Read(t.Context())
+			if errors.Is(err, EOF) {
+				break
+			}
+			require.NoError(t, err)
+			require.Positive(t, budget.used.Load())
+
+			batch, _ := pipeline.Value()
+			rows += batch.NumRows()
+		}
+		require.Equal(t, int64(30), rows)
+
+		pipeline.Close()
+		require.Equal(t, int64(0), budget.used.Load())
+	})
 }
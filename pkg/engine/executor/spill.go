@@ -0,0 +1,124 @@
+package executor
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+)
+
+// errSpillDisabled is returned when state needs to be spilled but no spill
+// directory is configured.
+var errSpillDisabled = errors.New("spilling is disabled")
+
+// spillFile is a temporary file holding state of a pipeline that was spilled
+// to disk in the Arrow IPC streaming format. Records are written to the file
+// until [spillFile.finish] is called, after which they can be read back in
+// the order they were written. The file is removed when closed.
+type spillFile struct {
+	alloc memory.Allocator
+
+	f  *os.File
+	bw *bufio.Writer
+	w  *ipc.Writer
+
+	r   *ipc.Reader
+	rec arrow.Record // current record, only valid after finish
+}
+
+// createSpillFile creates a new spill file for records of the given schema
+// in dir. It returns errSpillDisabled if dir is empty.
+func createSpillFile(dir string, schema *arrow.Schema, alloc memory.Allocator) (*spillFile, error) {
+	if dir == "" {
+		return nil, errSpillDisabled
+	}
+
+	f, err := os.CreateTemp(dir, "loki-spill-*.arrow")
+	if err != nil {
+		return nil, fmt.Errorf("creating spill file: %w", err)
+	}
+
+	bw := bufio.NewWriter(f)
+	return &spillFile{
+		alloc: alloc,
+		f:     f,
+		bw:    bw,
+		w:     ipc.NewWriter(bw, ipc.WithSchema(schema), ipc.WithAllocator(alloc)),
+	}, nil
+}
+
+// write writes rec to the file.
+func (s *spillFile) write(rec arrow.Record) error {
+	if err := s.w.Write(rec); err != nil {
+		return fmt.Errorf("writing spill file: %w", err)
+	}
+	return nil
+}
+
+// finish finishes writing to the file and moves to its first record.
+func (s *spillFile) finish() error {
+	if err := s.w.Close(); err != nil {
+		return fmt.Errorf("closing spill file writer: %w", err)
+	}
+	if err := s.bw.Flush(); err != nil {
+		return fmt.Errorf("flushing spill file: %w", err)
+	}
+	if _, err := s.f.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("rewinding spill file: %w", err)
+	}
+
+	r, err := ipc.NewReader(bufio.NewReader(s.f), ipc.WithAllocator(s.alloc))
+	if err != nil {
+		return fmt.Errorf("reading spill file: %w", err)
+	}
+	s.r = r
+	return s.next()
+}
+
+// record returns the current record of the file, or nil if all records have
+// been read. The record is only valid until the next call to next.
+func (s *spillFile) record() arrow.Record {
+	return s.rec
+}
+
+// next moves to the next record of the file.
+func (s *spillFile) next() error {
+	if s.r.Next() {
+		s.rec = s.r.Record()
+		return nil
+	}
+	s.rec = nil
+	if err := s.r.Err(); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("reading spill file: %w", err)
+	}
+	return nil
+}
+
+// Close closes and removes the file.
+func (s *spillFile) Close() error {
+	if s.r != nil {
+		s.r.Release()
+		s.r = nil
+	}
+	s.rec = nil
+
+	closeErr := s.f.Close()
+	if err := os.Remove(s.f.Name()); err != nil {
+		return fmt.Errorf("removing spill file: %w", err)
+	}
+	return closeErr
+}
+
+// closeSpillFiles closes all files and returns the first error.
+func closeSpillFiles(files []*spillFile) error {
+	var errs []error
+	for _, f := range files {
+		errs = append(errs, f.Close())
+	}
+	return errors.Join(errs...)
+}
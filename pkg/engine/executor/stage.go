@@ -0,0 +1,321 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/loki/v3/pkg/engine/internal/datatype"
+	"github.com/grafana/loki/v3/pkg/engine/internal/types"
+	"github.com/grafana/loki/v3/pkg/logql/log"
+	"github.com/grafana/loki/v3/pkg/logqlmodel"
+)
+
+// newLogStagePipeline returns a pipeline that applies a stage of the existing
+// engine's log pipeline, such as a parser or formatter, to each row of its
+// input. Using the stages of the existing engine guarantees the same results,
+// including the handling of colliding label names and errors.
+//
+// The labels of each row are passed to the stage by their column type:
+// label columns as stream labels, metadata columns as structured metadata,
+// and parsed columns as parsed labels. The labels returned by the stage are
+// written back to the columns of the respective column type. Labels that
+// were removed by the stage are represented as null values, and new labels
+// are added as new columns.
+//
+// The stage must not filter out lines.
+func newLogStagePipeline(stage log.Stage, input Pipeline) *GenericPipeline {
+	p := &stageProcessor{
+		stage:   stage,
+		builder: log.NewBaseLabelsBuilder(),
+	}
+
+	return newGenericPipeline(Local, func(ctx context.Context, inputs []Pipeline) state {
+		// Pull the next item from the input pipeline
+		input := inputs[0]
+		err := input.Read(ctx)
+		if err != nil {
+			return failureState(err)
+		}
+
+		batch, err := input.Value()
+		if err != nil {
+			return failureState(err)
+		}
+
+		processed, err := p.process(batch)
+		if err != nil {
+			return failureState(err)
+		}
+		return successState(processed)
+	}, input)
+}
+
+// stageCategories maps the label categories of the log pipeline to the
+// column types of the engine.
+var stageCategories = []struct {
+	category log.LabelCategory
+	ct       types.ColumnType
+}{
+	{log.StreamLabel, types.ColumnTypeLabel},
+	{log.StructuredMetadataLabel, types.ColumnTypeMetadata},
+	{log.ParsedLabel, types.ColumnTypeParsed},
+}
+
+type stageProcessor struct {
+	stage   log.Stage
+	builder *log.BaseLabelsBuilder
+
+	// buffers that are reused across rows
+	lbs labels.ScratchBuilder
+	buf []labels.Label
+}
+
+// process applies the stage to each row of the record and returns a new
+// record with the rewritten log line and labels.
+func (p *stageProcessor) process(record arrow.Record) (arrow.Record, error) {
+	defer record.Release()
+
+	var (
+		schema       = record.Schema()
+		messageIdx   = -1
+		timestampIdx = -1
+
+		// column indexes of the label columns by category
+		labelIdxs = make([][]int, len(stageCategories))
+	)
+
+	for i, field := range schema.Fields() {
+		ct, ok := field.Metadata.GetValue(types.MetadataKeyColumnType)
+		if !ok {
+			continue
+		}
+
+		if ct == types.ColumnTypeBuiltin.String() {
+			switch field.Name {
+			case types.ColumnNameBuiltinMessage:
+				messageIdx = i
+			case types.ColumnNameBuiltinTimestamp:
+				timestampIdx = i
+			}
+			continue
+		}
+
+		if field.Type.ID() != arrow.STRING {
+			continue
+		}
+		for c, sc := range stageCategories {
+			if ct == sc.ct.String() {
+				labelIdxs[c] = append(labelIdxs[c], i)
+			}
+		}
+	}
+
+	if messageIdx < 0 {
+		return nil, fmt.Errorf("missing column %s", types.ColumnNameBuiltinMessage)
+	}
+	messageCol, ok := record.Column(messageIdx).(*array.String)
+	if !ok {
+		return nil, fmt.Errorf("column %s must be of type string, got %s", types.ColumnNameBuiltinMessage, schema.Field(messageIdx).Type)
+	}
+
+	var timestampCol *array.Timestamp
+	if timestampIdx >= 0 {
+		timestampCol, _ = record.Column(timestampIdx).(*array.Timestamp)
+	}
+
+	mem := memory.NewGoAllocator()
+	numRows := int(record.NumRows())
+
+	// Stages can replace the log line, so the message column is re-created
+	// from the lines returned by the stage.
+	messageBuilder := array.NewStringBuilder(mem)
+	defer messageBuilder.Release()
+
+	columns := make([]*stageColumns, len(stageCategories))
+	for c := range stageCategories {
+		columns[c] = newStageColumns(mem)
+		for _, idx := range labelIdxs[c] {
+			columns[c].add(schema.Field(idx).Name)
+		}
+	}
+	defer func() {
+		for _, cols := range columns {
+			cols.release()
+		}
+	}()
+
+	for row := range numRows {
+		lb := p.labelsBuilder(record, labelIdxs, row)
+
+		var ts int64
+		if timestampCol != nil {
+			ts = int64(timestampCol.Value(row))
+		}
+
+		if messageCol.IsNull(row) {
+			messageBuilder.AppendNull()
+		} else {
+			line, _ := p.stage.Process(ts, []byte(messageCol.Value(row)), lb)
+			messageBuilder.Append(string(line))
+		}
+
+		for c, sc := range stageCategories {
+			p.buf = lb.UnsortedLabels(p.buf, sc.category)
+			for _, l := range p.buf {
+				columns[c].append(l.Name, l.Value, row)
+			}
+			columns[c].fill(row)
+		}
+	}
+
+	fields := make([]arrow.Field, 0, len(schema.Fields()))
+	arrays := make([]arrow.Array, 0, len(schema.Fields()))
+	defer func() {
+		for _, arr := range arrays {
+			arr.Release()
+		}
+	}()
+
+	for i, field := range schema.Fields() {
+		var arr arrow.Array
+		switch {
+		case i == messageIdx:
+			arr = messageBuilder.NewArray()
+		default:
+			for c := range stageCategories {
+				if slices.Contains(labelIdxs[c], i) {
+					arr = columns[c].newArray(field.Name)
+				}
+			}
+		}
+		if arr == nil {
+			arr = record.Column(i)
+			arr.Retain()
+		}
+		fields = append(fields, field)
+		arrays = append(arrays, arr)
+	}
+
+	// Add the columns of labels that were not present in the input.
+	for c, sc := range stageCategories {
+		for _, name := range columns[c].newNames(len(labelIdxs[c])) {
+			fields = append(fields, arrow.Field{
+				Name:     name,
+				Type:     datatype.Arrow.String,
+				Nullable: true,
+				Metadata: datatype.ColumnMetadata(sc.ct, datatype.Loki.String),
+			})
+			arrays = append(arrays, columns[c].newArray(name))
+		}
+	}
+
+	return array.NewRecord(arrow.NewSchema(fields, nil), arrays, int64(numRows)), nil
+}
+
+// labelsBuilder returns a reset labels builder for the given row. The label
+// columns of the row are used as base labels, and the metadata and parsed
+// columns are added as structured metadata and parsed labels.
+func (p *stageProcessor) labelsBuilder(record arrow.Record, labelIdxs [][]int, row int) *log.LabelsBuilder {
+	p.lbs.Reset()
+	for _, idx := range labelIdxs[0] {
+		col := record.Column(idx).(*array.String)
+		if col.IsNull(row) || col.Value(row) == "" {
+			continue
+		}
+		p.lbs.Add(record.ColumnName(idx), col.Value(row))
+	}
+	p.lbs.Sort()
+
+	lbs := p.lbs.Labels()
+	lb := p.builder.ForLabels(lbs, lbs.Hash())
+	lb.Reset()
+
+	for c := 1; c < len(stageCategories); c++ {
+		for _, idx := range labelIdxs[c] {
+			col := record.Column(idx).(*array.String)
+			if col.IsNull(row) {
+				continue
+			}
+
+			switch name := record.ColumnName(idx); name {
+			case logqlmodel.ErrorLabel:
+				lb.SetErr(col.Value(row))
+			case logqlmodel.ErrorDetailsLabel:
+				lb.SetErrorDetails(col.Value(row))
+			default:
+				lb.Set(stageCategories[c].category, name, col.Value(row))
+			}
+		}
+	}
+	return lb
+}
+
+// stageColumns builds the string columns of a single label category.
+type stageColumns struct {
+	mem      memory.Allocator
+	names    []string // names in order of appearance
+	builders map[string]*array.StringBuilder
+}
+
+func newStageColumns(mem memory.Allocator) *stageColumns {
+	return &stageColumns{
+		mem:      mem,
+		builders: make(map[string]*array.StringBuilder),
+	}
+}
+
+// add adds a column with the given name if it does not exist yet.
+func (s *stageColumns) add(name string) *array.StringBuilder {
+	if b, ok := s.builders[name]; ok {
+		return b
+	}
+	b := array.NewStringBuilder(s.mem)
+	s.builders[name] = b
+	s.names = append(s.names, name)
+	return b
+}
+
+// append sets the value of the column with the given name for the given row.
+// Rows before the column was first seen do not have a value.
+func (s *stageColumns) append(name, value string, row int) {
+	b := s.add(name)
+	if n := row - b.Len(); n > 0 {
+		b.AppendNulls(n)
+	}
+	b.Append(value)
+}
+
+// fill appends null values to all columns that do not have a value for the
+// given row.
+func (s *stageColumns) fill(row int) {
+	for _, b := range s.builders {
+		if n := row + 1 - b.Len(); n > 0 {
+			b.AppendNulls(n)
+		}
+	}
+}
+
+// newArray returns the array of the column with the given name.
+func (s *stageColumns) newArray(name string) arrow.Array {
+	return s.builders[name].NewArray()
+}
+
+// newNames returns the sorted names of the columns that were added after the
+// first n columns.
+func (s *stageColumns) newNames(n int) []string {
+	names := slices.Clone(s.names[n:])
+	slices.Sort(names)
+	return names
+}
+
+func (s *stageColumns) release() {
+	for _, b := range s.builders {
+		b.Release()
+	}
+}
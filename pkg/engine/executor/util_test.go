@@ -109,7 +109,6 @@ func (p *recordGenerator) Pipeline(batchSize int64, rows int64) Pipeline {
 			pos += batch.NumRows()
 			return successState(batch)
 		},
-		nil,
 	)
 }
 
@@ -5,9 +5,12 @@ import (
 	"errors"
 	"fmt"
 	"maps"
+	"math"
 	"slices"
+	"strconv"
 	"strings"
 	"time"
+	"unsafe"
 
 	"github.com/apache/arrow-go/v18/arrow"
 	"github.com/apache/arrow-go/v18/arrow/array"
@@ -17,34 +20,78 @@ import (
 	"github.com/grafana/loki/v3/pkg/engine/internal/datatype"
 	"github.com/grafana/loki/v3/pkg/engine/internal/types"
 	"github.com/grafana/loki/v3/pkg/engine/planner/physical"
+	"github.com/grafana/loki/v3/pkg/util/topk"
 )
 
+type vectorAggregationOptions struct {
+	groupBy   []physical.ColumnExpression // columns to group by, or to exclude from grouping if without is set
+	without   bool                        // whether to group by all label columns except the ones in groupBy
+	operation types.VectorAggregationType // aggregation operation to apply on each group
+	parameter int                         // parameter of the operation, such as k for topk and bottomk
+
+	memory memoryConfig // accounting of the memory of the groups
+}
+
 // VectorAggregationPipeline is a pipeline that performs vector aggregations.
 //
 // It reads from the input pipeline, groups the data by specified columns,
 // and applies the aggregation function on each group.
+//
+// Operations that select samples, such as topk and sort, retain all label
+// columns of the selected samples instead of only the grouping columns.
 type VectorAggregationPipeline struct {
 	state  state
 	inputs []Pipeline
 
 	aggregator *vectorAggregator
 	evaluator  expressionEvaluator
-	groupBy    []physical.ColumnExpression
+	opts       vectorAggregationOptions
+
+	groupBy []*physical.ColumnExpr // columns to group by, grows with the input if the grouping is inverted
+	labels  []*physical.ColumnExpr // all label columns of the input, only tracked if required
 
 	tsEval    evalFunc // used to evaluate the timestamp column
 	valueEval evalFunc // used to evaluate the value column
+
+	aggregated bool                // whether the inputs have been consumed
+	reserved   int64               // bytes of the memory budget reserved for the groups in aggregator
+	pointSizes map[time.Time]int64 // bytes reserved for each point, only tracked with a memory budget
+	spills     []*spillFile        // groups spilled to disk, each file holds one record per timestamp in ascending order
 }
 
-func NewVectorAggregationPipeline(inputs []Pipeline, groupBy []physical.ColumnExpression, evaluator expressionEvaluator) (*VectorAggregationPipeline, error) {
+func NewVectorAggregationPipeline(inputs []Pipeline, evaluator expressionEvaluator, opts vectorAggregationOptions) (*VectorAggregationPipeline, error) {
 	if len(inputs) == 0 {
 		return nil, fmt.Errorf("vector aggregation expects at least one input")
 	}
 
-	return &VectorAggregationPipeline{
+	switch opts.operation {
+	case types.VectorAggregationTypeTopK, types.VectorAggregationTypeBottomK:
+		if opts.parameter < 1 {
+			return nil, fmt.Errorf("invalid parameter %d for %s: must be greater than 0", opts.parameter, opts.operation)
+		}
+	case types.VectorAggregationTypeSum, types.VectorAggregationTypeAvg,
+		types.VectorAggregationTypeMin, types.VectorAggregationTypeMax,
+		types.VectorAggregationTypeCount, types.VectorAggregationTypeStddev,
+		types.VectorAggregationTypeStdvar, types.VectorAggregationTypeSort,
+		types.VectorAggregationTypeSortDesc:
+	default:
+		return nil, fmt.Errorf("unsupported vector aggregation %s", opts.operation)
+	}
+
+	groupBy := make([]*physical.ColumnExpr, 0, len(opts.groupBy))
+	for _, column := range opts.groupBy {
+		columnExpr, ok := column.(*physical.ColumnExpr)
+		if !ok {
+			return nil, fmt.Errorf("invalid column expression type %T", column)
+		}
+		groupBy = append(groupBy, columnExpr)
+	}
+
+	v := &VectorAggregationPipeline{
 		inputs:     inputs,
 		evaluator:  evaluator,
-		groupBy:    groupBy,
-		aggregator: newVectorAggregator(groupBy),
+		opts:       opts,
+		aggregator: newVectorAggregator(opts.operation, opts.parameter),
 		tsEval: evaluator.newFunc(&physical.ColumnExpr{
 			Ref: types.ColumnRef{
 				Column: types.ColumnNameBuiltinTimestamp,
@@ -57,7 +104,11 @@ func NewVectorAggregationPipeline(inputs []Pipeline, groupBy []physical.ColumnEx
 				Type:   types.ColumnTypeGenerated,
 			},
 		}),
-	}, nil
+	}
+	if !opts.without {
+		v.groupBy = groupBy
+	}
+	return v, nil
 }
 
 // Read reads the next value into its state.
@@ -81,11 +132,67 @@ func (v *VectorAggregationPipeline) Read(ctx context.Context) error {
 }
 
 func (v *VectorAggregationPipeline) read(ctx context.Context) (arrow.Record, error) {
+	if !v.aggregated {
+		if err := v.aggregate(ctx); err != nil {
+			return nil, err
+		}
+		v.aggregated = true
+
+		if len(v.spills) == 0 {
+			if v.aggregator.NumOfPoints() == 0 {
+				return nil, EOF // no values to aggregate & reached EOF
+			}
+			record, err := v.aggregator.buildRecord(v.outputColumns(), v.opts.memory.alloc(), v.aggregator.GetSortedTimestamps())
+			v.release()
+			return record, err
+		}
+
+		// Once spilled, the remaining groups are spilled as well, so only the
+		// groups of a single timestamp are held in memory while merging.
+		if v.reserved > 0 {
+			if err := v.spill(); err != nil {
+				return nil, err
+			}
+		}
+		for _, f := range v.spills {
+			if err := f.finish(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if len(v.spills) == 0 {
+		return nil, EOF
+	}
+
+	// Once spilled, the points are emitted one record per timestamp.
+	ts, ok := v.nextTimestamp()
+	if !ok {
+		return nil, EOF
+	}
+	if err := v.mergeSpilled(ts); err != nil {
+		return nil, err
+	}
+	record, err := v.aggregator.buildRecord(v.outputColumns(), v.opts.memory.alloc(), []time.Time{ts})
+	v.releasePoint(ts)
+	return record, err
+}
+
+// outputColumns returns the label columns of the output records.
+func (v *VectorAggregationPipeline) outputColumns() []*physical.ColumnExpr {
+	if v.opts.operation.KeepsSamples() {
+		return v.labels
+	}
+	return v.groupBy
+}
+
+func (v *VectorAggregationPipeline) aggregate(ctx context.Context) error {
 	var (
-		labelValues = make([]string, len(v.groupBy))
+		// reused on each row read
+		groupValues  []string
+		sampleValues []string
 	)
 
-	v.aggregator.Reset() // reset before reading new inputs
 	inputsExhausted := false
 	for !inputsExhausted {
 		inputsExhausted = true
@@ -96,58 +203,385 @@ func (v *VectorAggregationPipeline) read(ctx context.Context) (arrow.Record, err
 					continue
 				}
 
-				return nil, err
+				return err
 			}
 
 			inputsExhausted = false
 			record, _ := input.Value()
 
+			if v.opts.without || v.opts.operation.KeepsSamples() {
+				v.addLabelColumns(record)
+			}
+
 			// extract timestamp column
 			tsVec, err := v.tsEval(record)
 			if err != nil {
-				return nil, err
+				return err
 			}
 			tsCol := tsVec.ToArray().(*array.Timestamp)
 
 			// extract value column
 			valueVec, err := v.valueEval(record)
 			if err != nil {
-				return nil, err
+				return err
+			}
+			valueArr, err := float64Values(valueVec)
+			if err != nil {
+				return err
 			}
-			valueArr := valueVec.ToArray().(*array.Int64)
 
 			// extract all the columns that are used for grouping
-			arrays := make([]*array.String, 0, len(v.groupBy))
-			for _, columnExpr := range v.groupBy {
-				vec, err := v.evaluator.eval(columnExpr, record)
-				if err != nil {
-					return nil, err
-				}
+			groupArrays, err := v.stringArrays(record, v.groupBy)
+			if err != nil {
+				return err
+			}
 
-				if vec.Type() != datatype.Loki.String {
-					return nil, fmt.Errorf("unsupported datatype for grouping %s", vec.Type())
+			// extract all label columns of the samples
+			var sampleArrays []*array.String
+			if v.opts.operation.KeepsSamples() {
+				sampleArrays, err = v.stringArrays(record, v.labels)
+				if err != nil {
+					return err
 				}
-
-				arrays = append(arrays, vec.ToArray().(*array.String))
 			}
 
+			groupValues = resize(groupValues, len(groupArrays))
+			sampleValues = resize(sampleValues, len(sampleArrays))
+
 			for row := range int(record.NumRows()) {
 				// reset for each row
-				clear(labelValues)
-				for col, arr := range arrays {
-					labelValues[col] = arr.Value(row)
+				readStringValues(groupValues, groupArrays, row)
+				readStringValues(sampleValues, sampleArrays, row)
+
+				if err := v.add(tsCol.Value(row).ToTime(arrow.Nanosecond), valueArr(row), groupValues, sampleValues); err != nil {
+					return err
 				}
+			}
+		}
+	}
+	return nil
+}
+
+// add adds a sample to the aggregator. If the groups exceed the memory
+// budget, the groups of all points are spilled to disk.
+func (v *VectorAggregationPipeline) add(ts time.Time, value float64, groupValues, sampleValues []string) error {
+	grown := v.aggregator.Add(ts, value, groupValues, sampleValues)
+
+	budget := v.opts.memory.budget
+	if budget == nil || grown == 0 {
+		return nil
+	}
+	if budget.reserve(grown) {
+		v.reserve(ts, grown)
+		return nil
+	}
+
+	// Spilling only helps if there is reserved memory that can be released.
+	if v.reserved == 0 {
+		return budget.limitError()
+	}
+	// The spilled groups include the sample which was just added, so its
+	// memory doesn't need to be reserved anymore.
+	if err := v.spill(); errors.Is(err, errSpillDisabled) {
+		return budget.limitError()
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+// reserve records that n bytes of the memory budget are reserved for the
+// point at ts.
+func (v *VectorAggregationPipeline) reserve(ts time.Time, n int64) {
+	if v.pointSizes == nil {
+		v.pointSizes = make(map[time.Time]int64)
+	}
+	v.pointSizes[ts] += n
+	v.reserved += n
+}
+
+// release releases the groups of all points.
+func (v *VectorAggregationPipeline) release() {
+	v.opts.memory.budget.release(v.reserved)
+	v.reserved = 0
+	clear(v.pointSizes)
+	v.aggregator.Reset()
+}
 
-				v.aggregator.Add(tsCol.Value(row).ToTime(arrow.Nanosecond), valueArr.Value(row), labelValues)
+// releasePoint releases the groups of the point at ts.
+func (v *VectorAggregationPipeline) releasePoint(ts time.Time) {
+	size := v.pointSizes[ts]
+	v.opts.memory.budget.release(size)
+	v.reserved -= size
+	delete(v.pointSizes, ts)
+	v.aggregator.delete(ts)
+}
+
+// nextTimestamp returns the earliest timestamp of the spilled points which
+// have not been emitted yet.
+func (v *VectorAggregationPipeline) nextTimestamp() (time.Time, bool) {
+	var (
+		next time.Time
+		ok   bool
+	)
+	for _, f := range v.spills {
+		rec := f.record()
+		if rec == nil || rec.NumRows() == 0 {
+			continue
+		}
+		ts := time.Unix(0, rec.Column(vectorSpillColumnTimestamp).(*array.Int64).Value(0)).UTC()
+		if !ok || ts.Before(next) {
+			next, ok = ts, true
+		}
+	}
+	return next, ok
+}
+
+// Columns of the records of groups spilled to disk. The columns are followed
+// by one column for each grouping column, and for operations that retain
+// samples, one column for each label column of the samples.
+const (
+	vectorSpillColumnTimestamp = iota
+	vectorSpillColumnValue
+	vectorSpillColumnMean
+	vectorSpillColumnCount
+	vectorSpillColumnSampleValue // null for groups without samples
+	vectorSpillColumnLabels      // first grouping column
+)
+
+const (
+	vectorSpillGroupPrefix  = "group_label_"
+	vectorSpillSamplePrefix = "sample_label_"
+)
+
+// spillSchema returns the schema of the records of spilled groups.
+func (v *VectorAggregationPipeline) spillSchema() *arrow.Schema {
+	fields := []arrow.Field{
+		{Name: "timestamp", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "value", Type: arrow.PrimitiveTypes.Float64},
+		{Name: "mean", Type: arrow.PrimitiveTypes.Float64},
+		{Name: "count", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "sample_value", Type: arrow.PrimitiveTypes.Float64, Nullable: true},
+	}
+	for i := range v.groupBy {
+		fields = append(fields, arrow.Field{Name: vectorSpillGroupPrefix + strconv.Itoa(i), Type: arrow.BinaryTypes.String, Nullable: true})
+	}
+	if v.opts.operation.KeepsSamples() {
+		for i := range v.labels {
+			fields = append(fields, arrow.Field{Name: vectorSpillSamplePrefix + strconv.Itoa(i), Type: arrow.BinaryTypes.String, Nullable: true})
+		}
+	}
+	return arrow.NewSchema(fields, nil)
+}
+
+// spill writes the groups of all points to a new spill file, one record per
+// timestamp, and releases their memory.
+func (v *VectorAggregationPipeline) spill() error {
+	schema := v.spillSchema()
+	f, err := createSpillFile(v.opts.memory.spillDir, schema, v.opts.memory.alloc())
+	if err != nil {
+		return err
+	}
+	v.spills = append(v.spills, f)
+
+	for _, ts := range v.aggregator.GetSortedTimestamps() {
+		rec := v.buildSpillRecord(schema, ts)
+		err := f.write(rec)
+		rec.Release()
+		if err != nil {
+			return err
+		}
+	}
+
+	v.release()
+	return nil
+}
+
+func (v *VectorAggregationPipeline) buildSpillRecord(schema *arrow.Schema, ts time.Time) arrow.Record {
+	rb := array.NewRecordBuilder(v.opts.memory.alloc(), schema)
+	defer rb.Release()
+
+	appendStrings := func(offset, n int, values []string) {
+		for col := range n {
+			builder := rb.Field(offset + col).(*array.StringBuilder)
+			if col >= len(values) || values[col] == "" {
+				builder.AppendNull()
+			} else {
+				builder.Append(values[col])
 			}
 		}
 	}
+	appendRow := func(state *groupState, sample *sampleState) {
+		rb.Field(vectorSpillColumnTimestamp).(*array.Int64Builder).Append(ts.UnixNano())
+		rb.Field(vectorSpillColumnValue).(*array.Float64Builder).Append(state.value)
+		rb.Field(vectorSpillColumnMean).(*array.Float64Builder).Append(state.mean)
+		rb.Field(vectorSpillColumnCount).(*array.Int64Builder).Append(state.count)
+
+		appendStrings(vectorSpillColumnLabels, len(v.groupBy), state.labelValues)
+		if sample == nil {
+			rb.Field(vectorSpillColumnSampleValue).(*array.Float64Builder).AppendNull()
+			return
+		}
+		rb.Field(vectorSpillColumnSampleValue).(*array.Float64Builder).Append(sample.value)
+		appendStrings(vectorSpillColumnLabels+len(v.groupBy), len(v.labels), sample.labelValues)
+	}
 
-	if v.aggregator.NumOfPoints() == 0 {
-		return nil, EOF // no values to aggregate & reached EOF
+	for _, state := range v.aggregator.GetEntriesForTimestamp(ts) {
+		if state.samples == nil {
+			appendRow(state, nil)
+			continue
+		}
+		for _, sample := range state.samples.PopAll() {
+			appendRow(state, sample)
+		}
+	}
+	return rb.NewRecord()
+}
+
+// mergeSpilled merges the groups of the point at ts spilled to disk into the
+// aggregator. Groups can't be spilled again at this point, so exceeding the
+// memory budget fails the query.
+func (v *VectorAggregationPipeline) mergeSpilled(ts time.Time) error {
+	var groupValues, sampleValues []string
+
+	for _, f := range v.spills {
+		rec := f.record()
+		if rec == nil || rec.NumRows() == 0 || rec.Column(vectorSpillColumnTimestamp).(*array.Int64).Value(0) != ts.UnixNano() {
+			continue
+		}
+
+		var (
+			value       = rec.Column(vectorSpillColumnValue).(*array.Float64)
+			mean        = rec.Column(vectorSpillColumnMean).(*array.Float64)
+			count       = rec.Column(vectorSpillColumnCount).(*array.Int64)
+			sampleValue = rec.Column(vectorSpillColumnSampleValue).(*array.Float64)
+
+			groupArrays, sampleArrays []*array.String
+		)
+		for i, field := range rec.Schema().Fields() {
+			switch {
+			case strings.HasPrefix(field.Name, vectorSpillGroupPrefix):
+				groupArrays = append(groupArrays, rec.Column(i).(*array.String))
+			case strings.HasPrefix(field.Name, vectorSpillSamplePrefix):
+				sampleArrays = append(sampleArrays, rec.Column(i).(*array.String))
+			}
+		}
+
+		groupValues = resize(groupValues, len(groupArrays))
+		sampleValues = resize(sampleValues, len(sampleArrays))
+		for row := range int(rec.NumRows()) {
+			readStringValues(groupValues, groupArrays, row)
+			readStringValues(sampleValues, sampleArrays, row)
+
+			var grown int64
+			if sampleValue.IsValid(row) {
+				grown = v.aggregator.Add(ts, sampleValue.Value(row), groupValues, sampleValues)
+			} else {
+				grown = v.aggregator.merge(ts, groupValues, &groupState{
+					value: value.Value(row),
+					mean:  mean.Value(row),
+					count: count.Value(row),
+				})
+			}
+
+			if v.opts.memory.budget == nil {
+				continue
+			}
+			if !v.opts.memory.budget.reserve(grown) {
+				return v.opts.memory.budget.limitError()
+			}
+			v.reserve(ts, grown)
+		}
+
+		if err := f.next(); err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
-	return v.aggregator.buildRecord()
+// addLabelColumns adds all label columns of the record to the tracked label
+// columns that have not been seen before. Label columns are all string
+// columns except the builtin and generated ones.
+// If the grouping is inverted, the new label columns are also added to the
+// grouping columns unless they are excluded from grouping.
+func (v *VectorAggregationPipeline) addLabelColumns(record arrow.Record) {
+	for _, field := range record.Schema().Fields() {
+		ct, _ := field.Metadata.GetValue(types.MetadataKeyColumnType)
+		dt, _ := field.Metadata.GetValue(types.MetadataKeyColumnDataType)
+		if dt != datatype.Loki.String.String() || ct == types.ColumnTypeBuiltin.String() || ct == types.ColumnTypeGenerated.String() {
+			continue
+		}
+
+		if !slices.ContainsFunc(v.labels, columnNamed(field.Name)) {
+			v.labels = append(v.labels, &physical.ColumnExpr{
+				Ref: types.ColumnRef{Column: field.Name, Type: types.ColumnTypeAmbiguous},
+			})
+		}
+
+		if v.opts.without &&
+			!slices.ContainsFunc(v.opts.groupBy, func(expr physical.ColumnExpression) bool {
+				columnExpr, ok := expr.(*physical.ColumnExpr)
+				return ok && columnExpr.Ref.Column == field.Name
+			}) &&
+			!slices.ContainsFunc(v.groupBy, columnNamed(field.Name)) {
+			v.groupBy = append(v.groupBy, &physical.ColumnExpr{
+				Ref: types.ColumnRef{Column: field.Name, Type: types.ColumnTypeAmbiguous},
+			})
+		}
+	}
+}
+
+func columnNamed(name string) func(*physical.ColumnExpr) bool {
+	return func(expr *physical.ColumnExpr) bool {
+		return expr.Ref.Column == name
+	}
+}
+
+// stringArrays evaluates the given columns against the record.
+func (v *VectorAggregationPipeline) stringArrays(record arrow.Record, columns []*physical.ColumnExpr) ([]*array.String, error) {
+	arrays := make([]*array.String, 0, len(columns))
+	for _, columnExpr := range columns {
+		vec, err := v.evaluator.eval(columnExpr, record)
+		if err != nil {
+			return nil, err
+		}
+
+		if vec.Type() != datatype.Loki.String {
+			return nil, fmt.Errorf("unsupported datatype for grouping %s", vec.Type())
+		}
+
+		arrays = append(arrays, vec.ToArray().(*array.String))
+	}
+	return arrays, nil
+}
+
+// resize returns a slice of length n, reusing the backing array of s if possible.
+func resize(s []string, n int) []string {
+	if cap(s) < n {
+		return make([]string, n)
+	}
+	return s[:n]
+}
+
+// readStringValues reads the values of row from arrays into dst.
+func readStringValues(dst []string, arrays []*array.String, row int) {
+	clear(dst)
+	for col, arr := range arrays {
+		dst[col] = arr.Value(row)
+	}
+}
+
+// float64Values returns a function to access the values of a numeric column vector as float64.
+func float64Values(vec ColumnVector) (func(row int) float64, error) {
+	switch arr := vec.ToArray().(type) {
+	case *array.Float64:
+		return arr.Value, nil
+	case *array.Int64:
+		return func(row int) float64 { return float64(arr.Value(row)) }, nil
+	default:
+		return nil, fmt.Errorf("unsupported datatype for value column %s", vec.Type())
+	}
 }
 
 // Value returns the current value in state.
@@ -161,6 +595,10 @@ func (v *VectorAggregationPipeline) Close() {
 		v.state.batch.Release()
 	}
 
+	v.release()
+	_ = closeSpillFiles(v.spills)
+	v.spills = nil
+
 	for _, input := range v.inputs {
 		input.Close()
 	}
@@ -177,64 +615,247 @@ func (v *VectorAggregationPipeline) Transport() Transport {
 }
 
 type groupState struct {
-	sum         int64
+	value       float64 // sum, min, max or the running variance for stddev and stdvar
+	mean        float64 // running mean for avg, stddev and stdvar
+	count       int64
+	labelValues []string
+
+	samples *topk.Heap[*sampleState] // selected samples for operations that retain samples
+}
+
+// sampleState is a sample selected by topk, bottomk, sort or sort_desc.
+type sampleState struct {
+	value       float64
 	labelValues []string
 }
 
 type vectorAggregator struct {
-	groupBy []physical.ColumnExpression          // columns to group by
-	digest  *xxhash.Digest                       // used to compute key for each group
-	points  map[time.Time]map[uint64]*groupState // holds the groupState for each point in time series
+	operation types.VectorAggregationType
+	parameter int
+
+	digest *xxhash.Digest                       // used to compute key for each group
+	points map[time.Time]map[uint64]*groupState // holds the groupState for each point in time series
 }
 
-func newVectorAggregator(groupBy []physical.ColumnExpression) *vectorAggregator {
+func newVectorAggregator(operation types.VectorAggregationType, parameter int) *vectorAggregator {
 	return &vectorAggregator{
-		groupBy: groupBy,
-		digest:  xxhash.New(),
-		points:  make(map[time.Time]map[uint64]*groupState),
+		operation: operation,
+		parameter: parameter,
+		digest:    xxhash.New(),
+		points:    make(map[time.Time]map[uint64]*groupState),
 	}
 }
 
-func (a *vectorAggregator) Add(ts time.Time, value int64, labelValues []string) {
+// Estimated sizes in bytes of the state of a vector aggregation, excluding
+// label values.
+const (
+	vectorPointSize = int64(unsafe.Sizeof(time.Time{})) + 64  // map entry and map of the point
+	groupStateSize  = int64(unsafe.Sizeof(groupState{})) + 16 // map entry
+	sampleStateSize = int64(unsafe.Sizeof(sampleState{})) + 8 // heap entry
+)
+
+// Add adds a sample with the given value to the group identified by groupValues
+// at timestamp ts. sampleValues holds the label values of the sample itself and
+// is only used by operations that retain samples. Add returns the estimated
+// number of bytes allocated for the sample.
+func (a *vectorAggregator) Add(ts time.Time, value float64, groupValues, sampleValues []string) int64 {
+	state, grown, created := a.group(ts, groupValues)
+	if created {
+		state.value = value
+		state.mean = value
+		state.count = 1
+
+		switch a.operation {
+		case types.VectorAggregationTypeStddev, types.VectorAggregationTypeStdvar:
+			state.value = 0
+		case types.VectorAggregationTypeTopK, types.VectorAggregationTypeBottomK,
+			types.VectorAggregationTypeSort, types.VectorAggregationTypeSortDesc:
+			state.samples.Push(&sampleState{value: value, labelValues: cloneStrings(sampleValues)})
+			grown += sampleStateSize + stringsSize(sampleValues)
+		}
+		return grown
+	}
+
+	// TODO: handle hash collisions
+	switch a.operation {
+	case types.VectorAggregationTypeSum:
+		state.value += value
+	case types.VectorAggregationTypeAvg:
+		state.count++
+		state.mean += (value - state.mean) / float64(state.count)
+	case types.VectorAggregationTypeMax:
+		if state.value < value || math.IsNaN(state.value) {
+			state.value = value
+		}
+	case types.VectorAggregationTypeMin:
+		if state.value > value || math.IsNaN(state.value) {
+			state.value = value
+		}
+	case types.VectorAggregationTypeCount:
+		state.count++
+	case types.VectorAggregationTypeStddev, types.VectorAggregationTypeStdvar:
+		// Welford's online algorithm for the variance.
+		state.count++
+		delta := value - state.mean
+		state.mean += delta / float64(state.count)
+		state.value += delta * (value - state.mean)
+	case types.VectorAggregationTypeTopK, types.VectorAggregationTypeBottomK,
+		types.VectorAggregationTypeSort, types.VectorAggregationTypeSortDesc:
+		sample := &sampleState{value: value}
+		res, _ := state.samples.Push(sample)
+		if res != topk.PushResultNone {
+			// only copy the label values if the sample was selected
+			sample.labelValues = cloneStrings(sampleValues)
+		}
+		if res == topk.PushResultPushed {
+			grown += sampleStateSize + stringsSize(sampleValues)
+		}
+	}
+	return grown
+}
+
+// merge merges the partial state other of a group, such as read back from a
+// spill file, into the group identified by groupValues at timestamp ts. The
+// samples of operations that retain samples are merged with [vectorAggregator.Add]
+// instead. merge returns the estimated number of bytes allocated for the state.
+func (a *vectorAggregator) merge(ts time.Time, groupValues []string, other *groupState) int64 {
+	state, grown, created := a.group(ts, groupValues)
+	if created {
+		state.value = other.value
+		state.mean = other.mean
+		state.count = other.count
+		return grown
+	}
+
+	switch a.operation {
+	case types.VectorAggregationTypeSum:
+		state.value += other.value
+	case types.VectorAggregationTypeAvg:
+		count := state.count + other.count
+		state.mean += (other.mean - state.mean) * float64(other.count) / float64(count)
+		state.count = count
+	case types.VectorAggregationTypeMax:
+		if state.value < other.value || math.IsNaN(state.value) {
+			state.value = other.value
+		}
+	case types.VectorAggregationTypeMin:
+		if state.value > other.value || math.IsNaN(state.value) {
+			state.value = other.value
+		}
+	case types.VectorAggregationTypeCount:
+		state.count += other.count
+	case types.VectorAggregationTypeStddev, types.VectorAggregationTypeStdvar:
+		// Chan et al.'s parallel algorithm to combine the variances.
+		count := state.count + other.count
+		delta := other.mean - state.mean
+		state.value += other.value + delta*delta*float64(state.count)*float64(other.count)/float64(count)
+		state.mean += delta * float64(other.count) / float64(count)
+		state.count = count
+	}
+	return grown
+}
+
+// group returns the group identified by groupValues at timestamp ts, creating
+// it if it doesn't exist yet. It returns the estimated number of bytes
+// allocated for a new group and whether the group was created.
+func (a *vectorAggregator) group(ts time.Time, groupValues []string) (*groupState, int64, bool) {
+	var grown int64
+
 	point, ok := a.points[ts]
 	if !ok {
 		point = make(map[uint64]*groupState)
 		a.points[ts] = point
+		grown += vectorPointSize
 	}
 
+	// Empty label values are skipped and the remaining ones are keyed by their
+	// index, so that rows from records with a different set of label columns
+	// end up in the same group.
 	a.digest.Reset()
-	for i, val := range labelValues {
-		if i > 0 {
-			_, _ = a.digest.Write([]byte{0}) // separator
+	for i, val := range groupValues {
+		if val == "" {
+			continue
 		}
-
+		_, _ = a.digest.WriteString(strconv.Itoa(i))
+		_, _ = a.digest.Write([]byte{0}) // separator between index and value
 		_, _ = a.digest.WriteString(val)
+		_, _ = a.digest.Write([]byte{0}) // separator for label values
 	}
 	key := a.digest.Sum64()
 
 	if state, ok := point[key]; ok {
-		// TODO: handle hash collisions
-		state.sum += value
-	} else {
-		// create a new slice since labelValues is reused by the calling code
-		labelValuesCopy := make([]string, len(labelValues))
-		for i, v := range labelValues {
-			// copy the value as this is backed by the arrow array data buffer.
-			// We could retain the record to avoid this copy, but that would hold
-			// all other columns in memory for as long as the query is evaluated.
-			labelValuesCopy[i] = strings.Clone(v)
-		}
+		return state, grown, false
+	}
+
+	// TODO: add limits on number of groups
+	state := &groupState{labelValues: cloneStrings(groupValues)}
+	if a.operation.KeepsSamples() {
+		state.samples = a.newSampleHeap()
+	}
+	point[key] = state
+	return state, grown + groupStateSize + stringsSize(groupValues), true
+}
 
-		// TODO: add limits on number of groups
-		point[key] = &groupState{
-			labelValues: labelValuesCopy,
-			sum:         value,
+// newSampleHeap returns a heap that retains the selected samples of a group.
+// The heap keeps the sample that is first to be evicted on top. For sort and
+// sort_desc the heap is unbounded and only used to order the samples.
+func (a *vectorAggregator) newSampleHeap() *topk.Heap[*sampleState] {
+	h := &topk.Heap[*sampleState]{
+		// NaN values are always treated as the smallest value, so that they
+		// are evicted first.
+		Less: func(left, right *sampleState) bool {
+			return left.value < right.value || (math.IsNaN(left.value) && !math.IsNaN(right.value))
+		},
+	}
+
+	switch a.operation {
+	case types.VectorAggregationTypeBottomK, types.VectorAggregationTypeSort:
+		h.Less = func(left, right *sampleState) bool {
+			return left.value > right.value || (math.IsNaN(left.value) && !math.IsNaN(right.value))
 		}
 	}
+
+	switch a.operation {
+	case types.VectorAggregationTypeTopK, types.VectorAggregationTypeBottomK:
+		h.Limit = a.parameter
+	}
+	return h
 }
 
-func (a *vectorAggregator) buildRecord() (arrow.Record, error) {
-	fields := make([]arrow.Field, 0, len(a.groupBy)+2)
+// cloneStrings copies values as they are backed by the arrow array data buffer.
+// We could retain the record to avoid this copy, but that would hold
+// all other columns in memory for as long as the query is evaluated.
+func cloneStrings(values []string) []string {
+	// create a new slice since values is reused by the calling code
+	res := make([]string, len(values))
+	for i, v := range values {
+		res[i] = strings.Clone(v)
+	}
+	return res
+}
+
+// value returns the aggregated value of the group.
+func (a *vectorAggregator) value(state *groupState) float64 {
+	switch a.operation {
+	case types.VectorAggregationTypeAvg:
+		return state.mean
+	case types.VectorAggregationTypeCount:
+		return float64(state.count)
+	case types.VectorAggregationTypeStddev:
+		return math.Sqrt(state.value / float64(state.count))
+	case types.VectorAggregationTypeStdvar:
+		return state.value / float64(state.count)
+	default:
+		return state.value
+	}
+}
+
+// buildRecord builds a record of the aggregated points at the given
+// timestamps, which must be sorted. columns are the label columns of the
+// output, which are either the grouping columns or the label columns of the
+// retained samples.
+func (a *vectorAggregator) buildRecord(columns []*physical.ColumnExpr, alloc memory.Allocator, timestamps []time.Time) (arrow.Record, error) {
+	fields := make([]arrow.Field, 0, len(columns)+2)
 	fields = append(fields,
 		arrow.Field{
 			Name:     types.ColumnNameBuiltinTimestamp,
@@ -244,18 +865,13 @@ func (a *vectorAggregator) buildRecord() (arrow.Record, error) {
 		},
 		arrow.Field{
 			Name:     types.ColumnNameGeneratedValue,
-			Type:     datatype.Arrow.Integer,
+			Type:     datatype.Arrow.Float,
 			Nullable: false,
-			Metadata: datatype.ColumnMetadata(types.ColumnTypeGenerated, datatype.Loki.Integer),
+			Metadata: datatype.ColumnMetadata(types.ColumnTypeGenerated, datatype.Loki.Float),
 		},
 	)
 
-	for _, column := range a.groupBy {
-		colExpr, ok := column.(*physical.ColumnExpr)
-		if !ok {
-			panic(fmt.Sprintf("invalid column expression type %T", column))
-		}
-
+	for _, colExpr := range columns {
 		fields = append(fields, arrow.Field{
 			Name:     colExpr.Ref.Column,
 			Type:     datatype.Arrow.String,
@@ -265,25 +881,42 @@ func (a *vectorAggregator) buildRecord() (arrow.Record, error) {
 	}
 
 	schema := arrow.NewSchema(fields, nil)
-	rb := array.NewRecordBuilder(memory.NewGoAllocator(), schema)
+	rb := array.NewRecordBuilder(alloc, schema)
 	defer rb.Release()
 
+	appendRow := func(ts arrow.Timestamp, value float64, labelValues []string) {
+		rb.Field(0).(*array.TimestampBuilder).Append(ts)
+		rb.Field(1).(*array.Float64Builder).Append(value)
+
+		for col := range columns {
+			builder := rb.Field(col + 2) // offset by 2 as the first 2 fields are timestamp and value
+
+			// Entries created before a column was discovered have fewer label values.
+			if col >= len(labelValues) || labelValues[col] == "" {
+				builder.(*array.StringBuilder).AppendNull()
+			} else {
+				builder.(*array.StringBuilder).Append(labelValues[col])
+			}
+		}
+	}
+
 	// emit aggregated results in sorted order of timestamp
-	for _, ts := range a.GetSortedTimestamps() {
+	for _, ts := range timestamps {
 		entries := a.GetEntriesForTimestamp(ts)
 		tsValue, _ := arrow.TimestampFromTime(ts, arrow.Nanosecond)
 
 		for _, entry := range entries {
-			rb.Field(0).(*array.TimestampBuilder).Append(tsValue)
-			rb.Field(1).(*array.Int64Builder).Append(entry.sum)
-
-			for col, val := range entry.labelValues {
-				builder := rb.Field(col + 2) // offset by 2 as the first 2 fields are timestamp and value
-				if val == "" {
-					builder.(*array.StringBuilder).AppendNull()
-				} else {
-					builder.(*array.StringBuilder).Append(val)
-				}
+			if entry.samples == nil {
+				appendRow(tsValue, a.value(entry), entry.labelValues)
+				continue
+			}
+
+			// The heap keeps the sample that is first to be evicted on top, so
+			// the order of the samples needs to be reversed.
+			samples := entry.samples.PopAll()
+			slices.Reverse(samples)
+			for _, sample := range samples {
+				appendRow(tsValue, sample.value, sample.labelValues)
 			}
 		}
 	}
@@ -295,6 +928,11 @@ func (a *vectorAggregator) Reset() {
 	clear(a.points)
 }
 
+// delete removes the point at ts.
+func (a *vectorAggregator) delete(ts time.Time) {
+	delete(a.points, ts)
+}
+
 func (a *vectorAggregator) NumOfPoints() int {
 	return len(a.points)
 }
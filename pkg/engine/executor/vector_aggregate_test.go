@@ -1,7 +1,9 @@
 package executor
 
 import (
+	"errors"
 	"fmt"
+	"math"
 	"strings"
 	"testing"
 	"time"
@@ -13,6 +15,7 @@ import (
 	"github.com/grafana/loki/v3/pkg/engine/internal/datatype"
 	"github.com/grafana/loki/v3/pkg/engine/internal/types"
 	"github.com/grafana/loki/v3/pkg/engine/planner/physical"
+	"github.com/grafana/loki/v3/pkg/logqlmodel"
 )
 
 func TestVectorAggregationPipeline(t *testing.T) {
@@ -84,7 +87,10 @@ func TestVectorAggregationPipeline(t *testing.T) {
 		},
 	}
 
-	pipeline, err := NewVectorAggregationPipeline([]Pipeline{input1, input2}, groupBy, expressionEvaluator{})
+	pipeline, err := NewVectorAggregationPipeline([]Pipeline{input1, input2}, expressionEvaluator{}, vectorAggregationOptions{
+		groupBy:   groupBy,
+		operation: types.VectorAggregationTypeSum,
+	})
 	require.NoError(t, err)
 	defer pipeline.Close()
 
@@ -121,7 +127,7 @@ func TestVectorAggregationPipeline(t *testing.T) {
 	actual := make(map[time.Time]map[string]int64)
 	for i := range int(record.NumRows()) {
 		ts := record.Column(0).(*array.Timestamp).Value(i).ToTime(arrow.Nanosecond)
-		value := record.Column(1).(*array.Int64).Value(i)
+		value := int64(record.Column(1).(*array.Float64).Value(i))
 		env := record.Column(2).(*array.String).Value(i)
 		service := record.Column(3).(*array.String).Value(i)
 		key := fmt.Sprintf("%s,%s", env, service)
@@ -142,3 +148,274 @@ func TestVectorAggregationPipeline(t *testing.T) {
 		}
 	}
 }
+
+func TestVectorAggregationPipeline_Operations(t *testing.T) {
+	fields := []arrow.Field{
+		{Name: types.ColumnNameBuiltinTimestamp, Type: datatype.Arrow.Timestamp, Metadata: datatype.ColumnMetadataBuiltinTimestamp},
+		{Name: types.ColumnNameGeneratedValue, Type: datatype.Arrow.Float, Metadata: datatype.ColumnMetadata(types.ColumnTypeGenerated, datatype.Loki.Float)},
+		{Name: "env", Type: datatype.Arrow.String, Metadata: datatype.ColumnMetadata(types.ColumnTypeLabel, datatype.Loki.String)},
+		{Name: "service", Type: datatype.Arrow.String, Metadata: datatype.ColumnMetadata(types.ColumnTypeLabel, datatype.Loki.String)},
+	}
+
+	ts := time.Unix(1000, 0).UTC()
+	inputCSV := strings.Join([]string{
+		fmt.Sprintf("%s,2,prod,app1", ts.Format(arrowTimestampFormat)),
+		fmt.Sprintf("%s,4,prod,app2", ts.Format(arrowTimestampFormat)),
+		fmt.Sprintf("%s,9,prod,app3", ts.Format(arrowTimestampFormat)),
+		fmt.Sprintf("%s,1,dev,app1", ts.Format(arrowTimestampFormat)),
+	}, "\n")
+
+	env := &physical.ColumnExpr{Ref: types.ColumnRef{Column: "env", Type: types.ColumnTypeAmbiguous}}
+	service := &physical.ColumnExpr{Ref: types.ColumnRef{Column: "service", Type: types.ColumnTypeAmbiguous}}
+
+	type sample struct {
+		labels string
+		value  float64
+	}
+
+	for _, tt := range []struct {
+		name     string
+		opts     vectorAggregationOptions
+		expected []sample // in order for sort and sort_desc
+	}{
+		{
+			name:     "sum",
+			opts:     vectorAggregationOptions{operation: types.VectorAggregationTypeSum},
+			expected: []sample{{"", 16}},
+		},
+		{
+			name:     "sum by env",
+			opts:     vectorAggregationOptions{operation: types.VectorAggregationTypeSum, groupBy: []physical.ColumnExpression{env}},
+			expected: []sample{{"dev", 1}, {"prod", 15}},
+		},
+		{
+			name:     "sum without service",
+			opts:     vectorAggregationOptions{operation: types.VectorAggregationTypeSum, groupBy: []physical.ColumnExpression{service}, without: true},
+			expected: []sample{{"dev", 1}, {"prod", 15}},
+		},
+		{
+			name:     "avg by env",
+			opts:     vectorAggregationOptions{operation: types.VectorAggregationTypeAvg, groupBy: []physical.ColumnExpression{env}},
+			expected: []sample{{"dev", 1}, {"prod", 5}},
+		},
+		{
+			name:     "min by env",
+			opts:     vectorAggregationOptions{operation: types.VectorAggregationTypeMin, groupBy: []physical.ColumnExpression{env}},
+			expected: []sample{{"dev", 1}, {"prod", 2}},
+		},
+		{
+			name:     "max by env",
+			opts:     vectorAggregationOptions{operation: types.VectorAggregationTypeMax, groupBy: []physical.ColumnExpression{env}},
+			expected: []sample{{"dev", 1}, {"prod", 9}},
+		},
+		{
+			name:     "count by env",
+			opts:     vectorAggregationOptions{operation: types.VectorAggregationTypeCount, groupBy: []physical.ColumnExpression{env}},
+			expected: []sample{{"dev", 1}, {"prod", 3}},
+		},
+		{
+			name:     "stdvar by env",
+			opts:     vectorAggregationOptions{operation: types.VectorAggregationTypeStdvar, groupBy: []physical.ColumnExpression{env}},
+			expected: []sample{{"dev", 0}, {"prod", 26.0 / 3}},
+		},
+		{
+			name:     "topk",
+			opts:     vectorAggregationOptions{operation: types.VectorAggregationTypeTopK, parameter: 2},
+			expected: []sample{{"prod,app3", 9}, {"prod,app2", 4}},
+		},
+		{
+			name:     "bottomk by env",
+			opts:     vectorAggregationOptions{operation: types.VectorAggregationTypeBottomK, parameter: 1, groupBy: []physical.ColumnExpression{env}},
+			expected: []sample{{"dev,app1", 1}, {"prod,app1", 2}},
+		},
+		{
+			name:     "sort",
+			opts:     vectorAggregationOptions{operation: types.VectorAggregationTypeSort},
+			expected: []sample{{"dev,app1", 1}, {"prod,app1", 2}, {"prod,app2", 4}, {"prod,app3", 9}},
+		},
+		{
+			name:     "sort_desc",
+			opts:     vectorAggregationOptions{operation: types.VectorAggregationTypeSortDesc},
+			expected: []sample{{"prod,app3", 9}, {"prod,app2", 4}, {"prod,app1", 2}, {"dev,app1", 1}},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			record, err := CSVToArrow(fields, inputCSV)
+			require.NoError(t, err)
+			defer record.Release()
+
+			pipeline, err := NewVectorAggregationPipeline([]Pipeline{NewBufferedPipeline(record)}, expressionEvaluator{}, tt.opts)
+			require.NoError(t, err)
+			defer pipeline.Close()
+
+			require.NoError(t, pipeline.Read(t.Context()))
+			rec, err := pipeline.Value()
+			require.NoError(t, err)
+
+			var actual []sample
+			for i := range int(rec.NumRows()) {
+				require.Equal(t, ts, rec.Column(0).(*array.Timestamp).Value(i).ToTime(arrow.Nanosecond))
+
+				labelValues := make([]string, 0, rec.NumCols()-2)
+				for col := 2; col < int(rec.NumCols()); col++ {
+					labelValues = append(labelValues, rec.Column(col).(*array.String).Value(i))
+				}
+				actual = append(actual, sample{strings.Join(labelValues, ","), rec.Column(1).(*array.Float64).Value(i)})
+			}
+
+			switch tt.opts.operation {
+			case types.VectorAggregationTypeSort, types.VectorAggregationTypeSortDesc, types.VectorAggregationTypeTopK:
+				require.Equal(t, tt.expected, actual)
+			default:
+				require.ElementsMatch(t, tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestVectorAggregationPipeline_Spill(t *testing.T) {
+	fields := []arrow.Field{
+		{Name: types.ColumnNameBuiltinTimestamp, Type: datatype.Arrow.Timestamp, Metadata: datatype.ColumnMetadataBuiltinTimestamp},
+		{Name: types.ColumnNameGeneratedValue, Type: datatype.Arrow.Float, Metadata: datatype.ColumnMetadata(types.ColumnTypeGenerated, datatype.Loki.Float)},
+		{Name: "env", Type: datatype.Arrow.String, Metadata: datatype.ColumnMetadata(types.ColumnTypeLabel, datatype.Loki.String)},
+		{Name: "service", Type: datatype.Arrow.String, Metadata: datatype.ColumnMetadata(types.ColumnTypeLabel, datatype.Loki.String)},
+	}
+
+	// 8 environments with 4 services each at 6 timestamps
+	start := time.Unix(1000, 0).UTC()
+	var rows []string
+	for i := range 6 {
+		for env := range 8 {
+			for service := range 4 {
+				ts := start.Add(time.Duration(i) * time.Minute)
+				rows = append(rows, fmt.Sprintf("%s,%d,env%d,app%d", ts.Format(arrowTimestampFormat), (i*7+env*5+service*3)%13, env, service))
+			}
+		}
+	}
+	inputCSV := strings.Join(rows, "\n")
+
+	newPipeline := func(t *testing.T, opts vectorAggregationOptions) *VectorAggregationPipeline {
+		record, err := CSVToArrow(fields, inputCSV)
+		require.NoError(t, err)
+		t.Cleanup(record.Release)
+
+		pipeline, err := NewVectorAggregationPipeline([]Pipeline{NewBufferedPipeline(record)}, expressionEvaluator{}, opts)
+		require.NoError(t, err)
+		return pipeline
+	}
+
+	// stateSize returns the memory reserved for the groups of all timestamps.
+	stateSize := func(t *testing.T, opts vectorAggregationOptions) int64 {
+		opts.memory = memoryConfig{budget: newMemoryBudget(math.MaxInt64)}
+		pipeline := newPipeline(t, opts)
+		defer pipeline.Close()
+
+		require.NoError(t, pipeline.aggregate(t.Context()))
+		return pipeline.reserved
+	}
+
+	type sample struct {
+		ts     time.Time
+		labels string
+		value  float64
+	}
+
+	run := func(t *testing.T, opts vectorAggregationOptions) ([]sample, error) {
+		pipeline := newPipeline(t, opts)
+		defer func() {
+			pipeline.Close()
+			if opts.memory.budget != nil {
+				require.Zero(t, opts.memory.budget.used.Load(), "memory must be released on close")
+			}
+		}()
+
+		var actual []sample
+		for {
+			err := pipeline.Read(t.Context())
+			if errors.Is(err, EOF) {
+				break
+			} else if err != nil {
+				return nil, err
+			}
+
+			rec, err := pipeline.Value()
+			require.NoError(t, err)
+			for i := range int(rec.NumRows()) {
+				labelValues := make([]string, 0, rec.NumCols()-2)
+				for col := 2; col < int(rec.NumCols()); col++ {
+					labelValues = append(labelValues, rec.Column(col).(*array.String).Value(i))
+				}
+				actual = append(actual, sample{
+					ts:     rec.Column(0).(*array.Timestamp).Value(i).ToTime(arrow.Nanosecond),
+					labels: strings.Join(labelValues, ","),
+					value:  rec.Column(1).(*array.Float64).Value(i),
+				})
+			}
+		}
+
+		if opts.memory.budget != nil {
+			require.NotEmpty(t, pipeline.spills, "state must be spilled")
+		}
+		return actual, nil
+	}
+
+	env := &physical.ColumnExpr{Ref: types.ColumnRef{Column: "env", Type: types.ColumnTypeAmbiguous}}
+
+	for _, tt := range []struct {
+		name string
+		opts vectorAggregationOptions
+	}{
+		{name: "sum by env", opts: vectorAggregationOptions{operation: types.VectorAggregationTypeSum, groupBy: []physical.ColumnExpression{env}}},
+		{name: "avg by env", opts: vectorAggregationOptions{operation: types.VectorAggregationTypeAvg, groupBy: []physical.ColumnExpression{env}}},
+		{name: "min by env", opts: vectorAggregationOptions{operation: types.VectorAggregationTypeMin, groupBy: []physical.ColumnExpression{env}}},
+		{name: "max by env", opts: vectorAggregationOptions{operation: types.VectorAggregationTypeMax, groupBy: []physical.ColumnExpression{env}}},
+		{name: "count by env", opts: vectorAggregationOptions{operation: types.VectorAggregationTypeCount, groupBy: []physical.ColumnExpression{env}}},
+		{name: "stdvar by env", opts: vectorAggregationOptions{operation: types.VectorAggregationTypeStdvar, groupBy: []physical.ColumnExpression{env}}},
+		{name: "topk by env", opts: vectorAggregationOptions{operation: types.VectorAggregationTypeTopK, parameter: 2, groupBy: []physical.ColumnExpression{env}}},
+		{name: "sort", opts: vectorAggregationOptions{operation: types.VectorAggregationTypeSort}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			expected, err := run(t, tt.opts)
+			require.NoError(t, err)
+
+			// The budget fits the groups of a single timestamp, but not all of them.
+			opts := tt.opts
+			opts.memory = memoryConfig{
+				budget:   newMemoryBudget(stateSize(t, tt.opts) / 3),
+				spillDir: t.TempDir(),
+			}
+			actual, err := run(t, opts)
+			require.NoError(t, err)
+
+			require.Len(t, actual, len(expected))
+			switch tt.opts.operation {
+			case types.VectorAggregationTypeSort:
+				// samples with equal values may be emitted in any order
+				for i := range expected {
+					require.Equal(t, expected[i].ts, actual[i].ts)
+					require.Equal(t, expected[i].value, actual[i].value)
+				}
+				require.ElementsMatch(t, expected, actual)
+			case types.VectorAggregationTypeTopK:
+				require.ElementsMatch(t, expected, actual)
+			default:
+				values := make(map[string]float64, len(actual))
+				for _, s := range actual {
+					values[fmt.Sprintf("%s,%s", s.ts, s.labels)] = s.value
+				}
+				for i, s := range expected {
+					require.Equal(t, s.ts, actual[i].ts, "timestamps must be emitted in ascending order")
+					require.InDelta(t, s.value, values[fmt.Sprintf("%s,%s", s.ts, s.labels)], 1e-9, "group %s at %s", s.labels, s.ts)
+				}
+			}
+		})
+	}
+
+	t.Run("limit exceeded without spill directory", func(t *testing.T) {
+		opts := vectorAggregationOptions{operation: types.VectorAggregationTypeSum, groupBy: []physical.ColumnExpression{env}}
+		opts.memory = memoryConfig{budget: newMemoryBudget(stateSize(t, opts) / 3)}
+
+		_, err := run(t, opts)
+		require.ErrorIs(t, err, logqlmodel.ErrLimit)
+	})
+}
@@ -6,32 +6,145 @@ type RangeAggregationType int
 const (
 	RangeAggregationTypeInvalid RangeAggregationType = iota
 
-	RangeAggregationTypeCount // Represents count_over_time range aggregation
+	RangeAggregationTypeCount         // Represents count_over_time range aggregation
+	RangeAggregationTypeRate          // Represents rate range aggregation
+	RangeAggregationTypeBytes         // Represents bytes_over_time range aggregation
+	RangeAggregationTypeBytesRate     // Represents bytes_rate range aggregation
+	RangeAggregationTypeSum           // Represents sum_over_time range aggregation
+	RangeAggregationTypeAvg           // Represents avg_over_time range aggregation
+	RangeAggregationTypeMin           // Represents min_over_time range aggregation
+	RangeAggregationTypeMax           // Represents max_over_time range aggregation
+	RangeAggregationTypeFirst         // Represents first_over_time range aggregation
+	RangeAggregationTypeLast          // Represents last_over_time range aggregation
+	RangeAggregationTypeStddev        // Represents stddev_over_time range aggregation
+	RangeAggregationTypeStdvar        // Represents stdvar_over_time range aggregation
+	RangeAggregationTypeQuantile      // Represents quantile_over_time range aggregation
+	RangeAggregationTypeAbsent        // Represents absent_over_time range aggregation
+	RangeAggregationTypeChanges       // Represents changes_over_time range aggregation
+	RangeAggregationTypeResets        // Represents resets_over_time range aggregation
+	RangeAggregationTypeDeriv         // Represents deriv range aggregation
+	RangeAggregationTypePredictLinear // Represents predict_linear range aggregation
 )
 
 func (op RangeAggregationType) String() string {
 	switch op {
 	case RangeAggregationTypeCount:
 		return "count"
+	case RangeAggregationTypeRate:
+		return "rate"
+	case RangeAggregationTypeBytes:
+		return "bytes"
+	case RangeAggregationTypeBytesRate:
+		return "bytes_rate"
+	case RangeAggregationTypeSum:
+		return "sum"
+	case RangeAggregationTypeAvg:
+		return "avg"
+	case RangeAggregationTypeMin:
+		return "min"
+	case RangeAggregationTypeMax:
+		return "max"
+	case RangeAggregationTypeFirst:
+		return "first"
+	case RangeAggregationTypeLast:
+		return "last"
+	case RangeAggregationTypeStddev:
+		return "stddev"
+	case RangeAggregationTypeStdvar:
+		return "stdvar"
+	case RangeAggregationTypeQuantile:
+		return "quantile"
+	case RangeAggregationTypeAbsent:
+		return "absent"
+	case RangeAggregationTypeChanges:
+		return "changes"
+	case RangeAggregationTypeResets:
+		return "resets"
+	case RangeAggregationTypeDeriv:
+		return "deriv"
+	case RangeAggregationTypePredictLinear:
+		return "predict_linear"
 	default:
 		return "invalid"
 	}
 }
 
+// HasParameter returns true if the range aggregation takes a parameter, such
+// as the quantile for quantile_over_time.
+func (op RangeAggregationType) HasParameter() bool {
+	return op == RangeAggregationTypeQuantile || op == RangeAggregationTypePredictLinear
+}
+
+// IsAdditive returns true if the result of the range aggregation over a set of streams
+// equals the sum of the results over each of the streams.
+func (op RangeAggregationType) IsAdditive() bool {
+	switch op {
+	case RangeAggregationTypeCount, RangeAggregationTypeRate,
+		RangeAggregationTypeBytes, RangeAggregationTypeBytesRate,
+		RangeAggregationTypeSum:
+		return true
+	default:
+		return false
+	}
+}
+
 // VectorAggregationType represents the type of vector aggregation operation
 type VectorAggregationType int
 
 const (
 	VectorAggregationTypeInvalid VectorAggregationType = iota
 
-	VectorAggregationTypeSum // Represents sum vector aggregation
+	VectorAggregationTypeSum      // Represents sum vector aggregation
+	VectorAggregationTypeAvg      // Represents avg vector aggregation
+	VectorAggregationTypeMin      // Represents min vector aggregation
+	VectorAggregationTypeMax      // Represents max vector aggregation
+	VectorAggregationTypeCount    // Represents count vector aggregation
+	VectorAggregationTypeStddev   // Represents stddev vector aggregation
+	VectorAggregationTypeStdvar   // Represents stdvar vector aggregation
+	VectorAggregationTypeTopK     // Represents topk vector aggregation
+	VectorAggregationTypeBottomK  // Represents bottomk vector aggregation
+	VectorAggregationTypeSort     // Represents sort vector aggregation
+	VectorAggregationTypeSortDesc // Represents sort_desc vector aggregation
 )
 
 func (op VectorAggregationType) String() string {
 	switch op {
 	case VectorAggregationTypeSum:
 		return "sum"
+	case VectorAggregationTypeAvg:
+		return "avg"
+	case VectorAggregationTypeMin:
+		return "min"
+	case VectorAggregationTypeMax:
+		return "max"
+	case VectorAggregationTypeCount:
+		return "count"
+	case VectorAggregationTypeStddev:
+		return "stddev"
+	case VectorAggregationTypeStdvar:
+		return "stdvar"
+	case VectorAggregationTypeTopK:
+		return "topk"
+	case VectorAggregationTypeBottomK:
+		return "bottomk"
+	case VectorAggregationTypeSort:
+		return "sort"
+	case VectorAggregationTypeSortDesc:
+		return "sort_desc"
 	default:
 		return "invalid"
 	}
 }
+
+// KeepsSamples returns true if the vector aggregation selects samples from its
+// input, retaining their labels, instead of aggregating them into a single
+// value per group.
+func (op VectorAggregationType) KeepsSamples() bool {
+	switch op {
+	case VectorAggregationTypeTopK, VectorAggregationTypeBottomK,
+		VectorAggregationTypeSort, VectorAggregationTypeSortDesc:
+		return true
+	default:
+		return false
+	}
+}
@@ -12,6 +12,10 @@ const (
 
 	UnaryOpNot // Logical NOT operation (!).
 	UnaryOpAbs // Mathematical absolute operation (abs).
+	UnaryOpNeg // Mathematical negation operation (-).
+
+	UnaryOpCastBytes    // Conversion of a humanized bytes string into a number of bytes (bytes).
+	UnaryOpCastDuration // Conversion of a duration string into a number of seconds (duration, duration_seconds).
 )
 
 // String returns the string representation of the UnaryOp.
@@ -21,6 +25,12 @@ func (t UnaryOp) String() string {
 		return "NOT"
 	case UnaryOpAbs:
 		return "ABS"
+	case UnaryOpNeg:
+		return "NEG"
+	case UnaryOpCastBytes:
+		return "CAST_BYTES"
+	case UnaryOpCastDuration:
+		return "CAST_DURATION"
 	default:
 		panic(fmt.Sprintf("unknown unary operator %d", t))
 	}
@@ -46,11 +56,14 @@ const (
 	BinaryOpXor // Logical XOR operation (^).
 	BinaryOpNot // Logical NOT operation (!).
 
+	BinaryOpUnless // Set difference operation (unless). Used for binary operations between vectors.
+
 	BinaryOpAdd // Addition operation (+).
 	BinaryOpSub // Subtraction operation (-).
 	BinaryOpMul // Multiplication operation (*).
 	BinaryOpDiv // Division operation (/).
 	BinaryOpMod // Modulo operation (%).
+	BinaryOpPow // Power operation (^).
 
 	BinaryOpMatchSubstr     // Substring matching operation (|=). Used for string match filter.
 	BinaryOpNotMatchSubstr  // Substring non-matching operation (!=). Used for string match filter.
@@ -83,6 +96,8 @@ func (t BinaryOp) String() string {
 		return "XOR"
 	case BinaryOpNot:
 		return "NOT"
+	case BinaryOpUnless:
+		return "UNLESS"
 	case BinaryOpAdd:
 		return "ADD"
 	case BinaryOpSub:
@@ -93,6 +108,8 @@ func (t BinaryOp) String() string {
 		return "DIV"
 	case BinaryOpMod:
 		return "MOD"
+	case BinaryOpPow:
+		return "POW"
 	case BinaryOpMatchSubstr:
 		return "MATCH_STR"
 	case BinaryOpNotMatchSubstr:
@@ -109,3 +126,52 @@ func (t BinaryOp) String() string {
 		panic(fmt.Sprintf("unknown binary operator %d", t))
 	}
 }
+
+// IsComparison returns true if the binary operation compares its operands.
+func (t BinaryOp) IsComparison() bool {
+	switch t {
+	case BinaryOpEq, BinaryOpNeq, BinaryOpGt, BinaryOpGte, BinaryOpLt, BinaryOpLte:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsSetOperation returns true if the binary operation is a set operation
+// between vectors (and, or, unless).
+func (t BinaryOp) IsSetOperation() bool {
+	switch t {
+	case BinaryOpAnd, BinaryOpOr, BinaryOpUnless:
+		return true
+	default:
+		return false
+	}
+}
+
+// VectorMatchCardinality describes the cardinality relationship of the
+// series of two vectors in a binary operation.
+type VectorMatchCardinality uint32
+
+// Recognized values of [VectorMatchCardinality].
+const (
+	VectorMatchOneToOne   VectorMatchCardinality = iota // Each series matches at most one series of the other side.
+	VectorMatchManyToOne                                // Multiple series of the left side can match a series of the right side (group_left).
+	VectorMatchOneToMany                                // Multiple series of the right side can match a series of the left side (group_right).
+	VectorMatchManyToMany                               // Any series can match any series of the other side. Used for set operations.
+)
+
+// String returns the string representation of the VectorMatchCardinality.
+func (c VectorMatchCardinality) String() string {
+	switch c {
+	case VectorMatchOneToOne:
+		return "one-to-one"
+	case VectorMatchManyToOne:
+		return "many-to-one"
+	case VectorMatchOneToMany:
+		return "one-to-many"
+	case VectorMatchManyToMany:
+		return "many-to-many"
+	default:
+		panic(fmt.Sprintf("unknown vector match cardinality %d", c))
+	}
+}
@@ -0,0 +1,32 @@
+package types
+
+// ParserType represents the type of a parser stage that extracts labels
+// from the log line.
+type ParserType int
+
+const (
+	ParserTypeInvalid ParserType = iota
+
+	ParserTypeLogfmt  // Represents the logfmt parser
+	ParserTypeJSON    // Represents the json parser
+	ParserTypeRegexp  // Represents the regexp parser
+	ParserTypePattern // Represents the pattern parser
+	ParserTypeUnpack  // Represents the unpack parser
+)
+
+func (p ParserType) String() string {
+	switch p {
+	case ParserTypeLogfmt:
+		return "logfmt"
+	case ParserTypeJSON:
+		return "json"
+	case ParserTypeRegexp:
+		return "regexp"
+	case ParserTypePattern:
+		return "pattern"
+	case ParserTypeUnpack:
+		return "unpack"
+	default:
+		return "invalid"
+	}
+}
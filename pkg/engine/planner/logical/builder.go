@@ -5,6 +5,7 @@ import (
 
 	"github.com/grafana/loki/v3/pkg/engine/internal/types"
 	"github.com/grafana/loki/v3/pkg/engine/planner/schema"
+	"github.com/grafana/loki/v3/pkg/logql/log"
 )
 
 // Builder provides an ergonomic interface for constructing a [Plan].
@@ -28,6 +29,71 @@ func (b *Builder) Select(predicate Value) *Builder {
 	}
 }
 
+// Parse applies a [Parse] operation to the Builder.
+// The expression is only used by the regexp and pattern parsers, strict and
+// keepEmpty only by the logfmt parser, and extractions only by the json and
+// logfmt parsers.
+func (b *Builder) Parse(
+	kind types.ParserType,
+	expression string,
+	strict, keepEmpty bool,
+	extractions []log.LabelExtractionExpr,
+) *Builder {
+	return &Builder{
+		val: &Parse{
+			Table:       b.val,
+			Kind:        kind,
+			Expression:  expression,
+			Strict:      strict,
+			KeepEmpty:   keepEmpty,
+			Extractions: extractions,
+		},
+	}
+}
+
+// LineFormat applies a [LineFormat] operation to the Builder.
+func (b *Builder) LineFormat(template string) *Builder {
+	return &Builder{
+		val: &LineFormat{
+			Table:    b.val,
+			Template: template,
+		},
+	}
+}
+
+// LabelFormat applies a [LabelFormat] operation to the Builder.
+func (b *Builder) LabelFormat(formats []log.LabelFmt) *Builder {
+	return &Builder{
+		val: &LabelFormat{
+			Table:   b.val,
+			Formats: formats,
+		},
+	}
+}
+
+// KeepLabels applies a [ProjectLabels] operation to the Builder that only
+// keeps the given labels.
+func (b *Builder) KeepLabels(labels []log.NamedLabelMatcher) *Builder {
+	return &Builder{
+		val: &ProjectLabels{
+			Table:  b.val,
+			Labels: labels,
+		},
+	}
+}
+
+// DropLabels applies a [ProjectLabels] operation to the Builder that drops
+// the given labels.
+func (b *Builder) DropLabels(labels []log.NamedLabelMatcher) *Builder {
+	return &Builder{
+		val: &ProjectLabels{
+			Table:  b.val,
+			Labels: labels,
+			Drop:   true,
+		},
+	}
+}
+
 // Limit applies a [Limit] operation to the Builder.
 func (b *Builder) Limit(skip uint32, fetch uint32) *Builder {
 	return &Builder{
@@ -59,12 +125,29 @@ func (b *Builder) RangeAggregation(
 	startTS, endTS time.Time,
 	step time.Duration,
 	rangeInterval time.Duration,
+) *Builder {
+	return b.UnwrapRangeAggregation(partitionBy, operation, nil, 0, startTS, endTS, step, rangeInterval)
+}
+
+// UnwrapRangeAggregation applies a [RangeAggregation] operation to the Builder
+// that aggregates the sample values extracted by the unwrap expression.
+// The parameter is only used by operations that require one, such as quantile_over_time.
+func (b *Builder) UnwrapRangeAggregation(
+	partitionBy []ColumnRef,
+	operation types.RangeAggregationType,
+	unwrap Value,
+	parameter float64,
+	startTS, endTS time.Time,
+	step time.Duration,
+	rangeInterval time.Duration,
 ) *Builder {
 	return &Builder{
 		val: &RangeAggregation{
 			Table: b.val,
 
 			Operation:     operation,
+			Unwrap:        unwrap,
+			Parameter:     parameter,
 			PartitionBy:   partitionBy,
 			Start:         startTS,
 			End:           endTS,
@@ -78,12 +161,46 @@ func (b *Builder) RangeAggregation(
 func (b *Builder) VectorAggregation(
 	groupBy []ColumnRef,
 	operation types.VectorAggregationType,
+) *Builder {
+	return b.GroupedVectorAggregation(groupBy, false, operation, 0)
+}
+
+// GroupedVectorAggregation applies a [VectorAggregation] operation to the Builder.
+// If without is true, the rows are grouped by all columns except the ones in groupBy.
+// The parameter is only used by operations that require one, such as topk.
+func (b *Builder) GroupedVectorAggregation(
+	groupBy []ColumnRef,
+	without bool,
+	operation types.VectorAggregationType,
+	parameter int,
 ) *Builder {
 	return &Builder{
 		val: &VectorAggregation{
 			Table:     b.val,
 			GroupBy:   groupBy,
+			Without:   without,
 			Operation: operation,
+			Parameter: parameter,
+		},
+	}
+}
+
+// BinOp applies a [BinOp] operation between the vector of the Builder and
+// rhs. Either side can be a [Literal] scalar instead of a vector. matching
+// describes how the series of two vectors are matched.
+func (b *Builder) BinOp(
+	op types.BinaryOp,
+	rhs Value,
+	returnBool bool,
+	matching *VectorMatching,
+) *Builder {
+	return &Builder{
+		val: &BinOp{
+			Left:           b.val,
+			Right:          rhs,
+			Op:             op,
+			ReturnBool:     returnBool,
+			VectorMatching: matching,
 		},
 	}
 }
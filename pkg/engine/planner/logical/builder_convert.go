@@ -37,6 +37,14 @@ func (b *ssaBuilder) process(value Value) (Value, error) {
 		return b.processMakeTablePlan(value)
 	case *Select:
 		return b.processSelectPlan(value)
+	case *Parse:
+		return b.processParsePlan(value)
+	case *LineFormat:
+		return b.processLineFormatPlan(value)
+	case *LabelFormat:
+		return b.processLabelFormatPlan(value)
+	case *ProjectLabels:
+		return b.processProjectLabelsPlan(value)
 	case *Limit:
 		return b.processLimitPlan(value)
 	case *Sort:
@@ -84,6 +92,46 @@ func (b *ssaBuilder) processSelectPlan(plan *Select) (Value, error) {
 	return plan, nil
 }
 
+func (b *ssaBuilder) processParsePlan(plan *Parse) (Value, error) {
+	if _, err := b.process(plan.Table); err != nil {
+		return nil, err
+	}
+
+	plan.id = fmt.Sprintf("%%%d", b.getID())
+	b.instructions = append(b.instructions, plan)
+	return plan, nil
+}
+
+func (b *ssaBuilder) processLineFormatPlan(plan *LineFormat) (Value, error) {
+	if _, err := b.process(plan.Table); err != nil {
+		return nil, err
+	}
+
+	plan.id = fmt.Sprintf("%%%d", b.getID())
+	b.instructions = append(b.instructions, plan)
+	return plan, nil
+}
+
+func (b *ssaBuilder) processLabelFormatPlan(plan *LabelFormat) (Value, error) {
+	if _, err := b.process(plan.Table); err != nil {
+		return nil, err
+	}
+
+	plan.id = fmt.Sprintf("%%%d", b.getID())
+	b.instructions = append(b.instructions, plan)
+	return plan, nil
+}
+
+func (b *ssaBuilder) processProjectLabelsPlan(plan *ProjectLabels) (Value, error) {
+	if _, err := b.process(plan.Table); err != nil {
+		return nil, err
+	}
+
+	plan.id = fmt.Sprintf("%%%d", b.getID())
+	b.instructions = append(b.instructions, plan)
+	return plan, nil
+}
+
 func (b *ssaBuilder) processLimitPlan(plan *Limit) (Value, error) {
 	if _, err := b.process(plan.Table); err != nil {
 		return nil, err
@@ -119,6 +167,11 @@ func (b *ssaBuilder) processRangeAggregate(plan *RangeAggregation) (Value, error
 	if _, err := b.process(plan.Table); err != nil {
 		return nil, err
 	}
+	if plan.Unwrap != nil {
+		if _, err := b.process(plan.Unwrap); err != nil {
+			return nil, err
+		}
+	}
 
 	plan.id = fmt.Sprintf("%%%d", b.getID())
 	b.instructions = append(b.instructions, plan)
@@ -3,7 +3,11 @@ package logical
 import (
 	"fmt"
 	"io"
+	"strconv"
 
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/loki/v3/pkg/engine/internal/types"
 	"github.com/grafana/loki/v3/pkg/engine/internal/util"
 	"github.com/grafana/loki/v3/pkg/engine/planner/internal/tree"
 )
@@ -25,6 +29,14 @@ func (t *treeFormatter) convert(value Value) *tree.Node {
 		return t.convertMakeTable(value)
 	case *Select:
 		return t.convertSelect(value)
+	case *Parse:
+		return t.convertParse(value)
+	case *LineFormat:
+		return t.convertLineFormat(value)
+	case *LabelFormat:
+		return t.convertLabelFormat(value)
+	case *ProjectLabels:
+		return t.convertProjectLabels(value)
 	case *Limit:
 		return t.convertLimit(value)
 	case *Sort:
@@ -66,6 +78,72 @@ func (t *treeFormatter) convertSelect(ast *Select) *tree.Node {
 	return node
 }
 
+func (t *treeFormatter) convertParse(ast *Parse) *tree.Node {
+	properties := []tree.Property{
+		tree.NewProperty("table", false, ast.Table.Name()),
+		tree.NewProperty("kind", false, ast.Kind),
+	}
+
+	switch ast.Kind {
+	case types.ParserTypeRegexp, types.ParserTypePattern:
+		properties = append(properties, tree.NewProperty("expression", false, strconv.Quote(ast.Expression)))
+	case types.ParserTypeLogfmt:
+		properties = append(properties,
+			tree.NewProperty("strict", false, ast.Strict),
+			tree.NewProperty("keep_empty", false, ast.KeepEmpty),
+		)
+	}
+
+	if len(ast.Extractions) > 0 {
+		extractions := make([]any, len(ast.Extractions))
+		for i, e := range ast.Extractions {
+			extractions[i] = e.Identifier + "=" + strconv.Quote(e.Expression)
+		}
+		properties = append(properties, tree.NewProperty("extractions", true, extractions...))
+	}
+
+	node := tree.NewNode("PARSE", ast.Name(), properties...)
+	node.Children = append(node.Children, t.convert(ast.Table))
+	return node
+}
+
+func (t *treeFormatter) convertLineFormat(ast *LineFormat) *tree.Node {
+	node := tree.NewNode("LINE_FORMAT", ast.Name(),
+		tree.NewProperty("table", false, ast.Table.Name()),
+		tree.NewProperty("template", false, strconv.Quote(ast.Template)),
+	)
+	node.Children = append(node.Children, t.convert(ast.Table))
+	return node
+}
+
+func (t *treeFormatter) convertLabelFormat(ast *LabelFormat) *tree.Node {
+	formats := make([]any, 0, len(ast.Formats))
+	for _, f := range labelFormatStrings(ast.Formats) {
+		formats = append(formats, f)
+	}
+
+	node := tree.NewNode("LABEL_FORMAT", ast.Name(),
+		tree.NewProperty("table", false, ast.Table.Name()),
+		tree.NewProperty("formats", true, formats...),
+	)
+	node.Children = append(node.Children, t.convert(ast.Table))
+	return node
+}
+
+func (t *treeFormatter) convertProjectLabels(ast *ProjectLabels) *tree.Node {
+	labels := make([]any, 0, len(ast.Labels))
+	for _, l := range namedLabelMatcherStrings(ast.Labels) {
+		labels = append(labels, l)
+	}
+
+	node := tree.NewNode(ast.opName(), ast.Name(),
+		tree.NewProperty("table", false, ast.Table.Name()),
+		tree.NewProperty("labels", true, labels...),
+	)
+	node.Children = append(node.Children, t.convert(ast.Table))
+	return node
+}
+
 func (t *treeFormatter) convertLimit(ast *Limit) *tree.Node {
 	node := tree.NewNode("LIMIT", ast.Name(),
 		tree.NewProperty("table", false, ast.Table.Name()),
@@ -113,6 +191,22 @@ func (t *treeFormatter) convertBinOp(expr *BinOp) *tree.Node {
 		tree.NewProperty("left", false, expr.Left.Name()),
 		tree.NewProperty("right", false, expr.Right.Name()),
 	)
+	if expr.ReturnBool {
+		node.Properties = append(node.Properties, tree.NewProperty("return_bool", false, expr.ReturnBool))
+	}
+	if m := expr.VectorMatching; m != nil {
+		if m.On {
+			node.Properties = append(node.Properties, tree.NewProperty("on", true, columnRefsAny(m.MatchingLabels)...))
+		} else if len(m.MatchingLabels) > 0 {
+			node.Properties = append(node.Properties, tree.NewProperty("ignoring", true, columnRefsAny(m.MatchingLabels)...))
+		}
+		switch m.Card {
+		case types.VectorMatchManyToOne:
+			node.Properties = append(node.Properties, tree.NewProperty("group_left", true, columnRefsAny(m.Include)...))
+		case types.VectorMatchOneToMany:
+			node.Properties = append(node.Properties, tree.NewProperty("group_right", true, columnRefsAny(m.Include)...))
+		}
+	}
 	node.Children = append(node.Children, t.convert(expr.Left))
 	node.Children = append(node.Children, t.convert(expr.Right))
 	return node
@@ -142,6 +236,16 @@ func (t *treeFormatter) convertRangeAggregation(r *RangeAggregation) *tree.Node
 		tree.NewProperty("range", false, r.RangeInterval),
 	}
 
+	if r.Operation.HasParameter() {
+		properties = append(properties, tree.NewProperty("parameter", false, r.Parameter))
+	}
+	if r.Unwrap != nil {
+		properties = append(properties, tree.NewProperty("unwrap", false, r.Unwrap.Name()))
+	}
+	if len(r.AbsentLabels) > 0 {
+		properties = append(properties, tree.NewProperty("absent_labels", false, labels.New(r.AbsentLabels...).String()))
+	}
+
 	if len(r.PartitionBy) > 0 {
 		partitionBy := make([]any, len(r.PartitionBy))
 		for i := range r.PartitionBy {
@@ -155,6 +259,9 @@ func (t *treeFormatter) convertRangeAggregation(r *RangeAggregation) *tree.Node
 	for _, columnRef := range r.PartitionBy {
 		node.Comments = append(node.Comments, t.convert(&columnRef))
 	}
+	if r.Unwrap != nil {
+		node.Comments = append(node.Comments, t.convert(r.Unwrap))
+	}
 	node.Children = append(node.Children, t.convert(r.Table))
 
 	return node
@@ -166,13 +273,22 @@ func (t *treeFormatter) convertVectorAggregation(v *VectorAggregation) *tree.Nod
 		tree.NewProperty("operation", false, v.Operation),
 	}
 
-	if len(v.GroupBy) > 0 {
+	switch v.Operation {
+	case types.VectorAggregationTypeTopK, types.VectorAggregationTypeBottomK:
+		properties = append(properties, tree.NewProperty("parameter", false, v.Parameter))
+	}
+
+	if len(v.GroupBy) > 0 || v.Without {
 		groupBy := make([]any, len(v.GroupBy))
 		for i := range v.GroupBy {
 			groupBy[i] = v.GroupBy[i].Name()
 		}
 
-		properties = append(properties, tree.NewProperty("group_by", true, groupBy...))
+		key := "group_by"
+		if v.Without {
+			key = "without"
+		}
+		properties = append(properties, tree.NewProperty(key, true, groupBy...))
 	}
 
 	node := tree.NewNode("VectorAggregation", v.Name(), properties...)
@@ -183,3 +299,13 @@ func (t *treeFormatter) convertVectorAggregation(v *VectorAggregation) *tree.Nod
 
 	return node
 }
+
+// columnRefsAny returns the names of the given column references for use as
+// values of a multi-value property.
+func columnRefsAny(refs []ColumnRef) []any {
+	names := make([]any, len(refs))
+	for i := range refs {
+		names[i] = refs[i].Name()
+	}
+	return names
+}
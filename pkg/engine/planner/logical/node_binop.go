@@ -2,6 +2,7 @@ package logical
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/grafana/loki/v3/pkg/engine/internal/types"
 	"github.com/grafana/loki/v3/pkg/engine/planner/schema"
@@ -9,11 +10,67 @@ import (
 
 // The BinOp instruction yields the result of binary operation Left Op Right.
 // BinOp implements both [Instruction] and [Value].
+//
+// A BinOp is either an expression on columns and literals, such as a
+// predicate, or a binary operation between vectors, in which case at least
+// one of Left and Right is a table relation of a metric query and the other
+// one may be a [Literal] scalar.
 type BinOp struct {
 	id string
 
 	Left, Right Value
 	Op          types.BinaryOp
+
+	// ReturnBool returns 0 or 1 for comparisons between vectors instead of
+	// filtering out the samples that do not satisfy the comparison.
+	ReturnBool bool
+
+	// VectorMatching describes how the series of two vectors are matched. It
+	// is nil for expressions that do not operate on two vectors.
+	VectorMatching *VectorMatching
+}
+
+// VectorMatching describes how the series of two vectors in a binary
+// operation are matched.
+type VectorMatching struct {
+	// On matches the series only on the MatchingLabels, instead of on all
+	// labels except the MatchingLabels.
+	On             bool
+	MatchingLabels []ColumnRef
+
+	// Card is the cardinality of the series matching between the vectors.
+	Card types.VectorMatchCardinality
+
+	// Include contains the labels of the series of the "one" side that are
+	// added to the result of a many-to-one or one-to-many matching.
+	Include []ColumnRef
+}
+
+// String returns the properties of the vector matching, omitting the
+// defaults.
+func (m *VectorMatching) String() string {
+	var props []string
+	if m.On {
+		props = append(props, fmt.Sprintf("on=(%s)", columnRefsString(m.MatchingLabels)))
+	} else if len(m.MatchingLabels) > 0 {
+		props = append(props, fmt.Sprintf("ignoring=(%s)", columnRefsString(m.MatchingLabels)))
+	}
+
+	switch m.Card {
+	case types.VectorMatchManyToOne:
+		props = append(props, fmt.Sprintf("group_left=(%s)", columnRefsString(m.Include)))
+	case types.VectorMatchOneToMany:
+		props = append(props, fmt.Sprintf("group_right=(%s)", columnRefsString(m.Include)))
+	}
+	return strings.Join(props, ", ")
+}
+
+func columnRefsString(refs []ColumnRef) string {
+	names := make([]string, len(refs))
+	for i, ref := range refs {
+		names[i] = ref.String()
+	}
+	return strings.Join(names, ", ")
 }
 
 var (
@@ -31,6 +88,19 @@ func (b *BinOp) Name() string {
 
 // String returns the disassembled SSA form of the BinOp instruction.
 func (b *BinOp) String() string {
+	var props []string
+	if b.ReturnBool {
+		props = append(props, "return_bool=true")
+	}
+	if b.VectorMatching != nil {
+		if matching := b.VectorMatching.String(); matching != "" {
+			props = append(props, matching)
+		}
+	}
+
+	if len(props) > 0 {
+		return fmt.Sprintf("%s %s %s [%s]", b.Op, b.Left.Name(), b.Right.Name(), strings.Join(props, ", "))
+	}
 	return fmt.Sprintf("%s %s %s", b.Op, b.Left.Name(), b.Right.Name())
 }
 
@@ -0,0 +1,103 @@
+package logical
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/loki/v3/pkg/engine/planner/schema"
+	"github.com/grafana/loki/v3/pkg/logql/log"
+)
+
+// The LineFormat instruction rewrites the log line of each row of a table
+// relation using a Go text template. LineFormat implements both [Instruction]
+// and [Value].
+type LineFormat struct {
+	id string
+
+	Table Value // The table relation to format.
+
+	// Template is the template that is executed for each row. It has access
+	// to all labels of the row as well as the log line and timestamp.
+	Template string
+}
+
+var (
+	_ Value       = (*LineFormat)(nil)
+	_ Instruction = (*LineFormat)(nil)
+)
+
+// Name returns an identifier for the LineFormat operation.
+func (f *LineFormat) Name() string {
+	if f.id != "" {
+		return f.id
+	}
+	return fmt.Sprintf("%p", f)
+}
+
+// String returns the disassembled SSA form of the LineFormat instruction.
+func (f *LineFormat) String() string {
+	return fmt.Sprintf("LINE_FORMAT %s [template=%s]", f.Table.Name(), strconv.Quote(f.Template))
+}
+
+// Schema returns the schema of the LineFormat plan.
+func (f *LineFormat) Schema() *schema.Schema {
+	return f.Table.Schema()
+}
+
+func (f *LineFormat) isInstruction() {}
+func (f *LineFormat) isValue()       {}
+
+// The LabelFormat instruction renames labels or sets labels to the result of
+// a Go text template for each row of a table relation. LabelFormat implements
+// both [Instruction] and [Value].
+type LabelFormat struct {
+	id string
+
+	Table Value // The table relation to format.
+
+	// Formats is the list of label renames and templates, applied in order.
+	Formats []log.LabelFmt
+}
+
+var (
+	_ Value       = (*LabelFormat)(nil)
+	_ Instruction = (*LabelFormat)(nil)
+)
+
+// Name returns an identifier for the LabelFormat operation.
+func (f *LabelFormat) Name() string {
+	if f.id != "" {
+		return f.id
+	}
+	return fmt.Sprintf("%p", f)
+}
+
+// String returns the disassembled SSA form of the LabelFormat instruction.
+func (f *LabelFormat) String() string {
+	return fmt.Sprintf("LABEL_FORMAT %s [formats=(%s)]", f.Table.Name(), strings.Join(labelFormatStrings(f.Formats), ", "))
+}
+
+// Schema returns the schema of the LabelFormat plan.
+func (f *LabelFormat) Schema() *schema.Schema {
+	// The resulting columns depend on the labels of each row and are
+	// therefore only known at execution time.
+	return f.Table.Schema()
+}
+
+func (f *LabelFormat) isInstruction() {}
+func (f *LabelFormat) isValue()       {}
+
+// labelFormatStrings returns the string representations of the given label
+// formats. Renames are represented as dst=src and templates as dst="tmpl".
+func labelFormatStrings(formats []log.LabelFmt) []string {
+	res := make([]string, len(formats))
+	for i, f := range formats {
+		if f.Rename {
+			res[i] = f.Name + "=" + f.Value
+		} else {
+			res[i] = f.Name + "=" + strconv.Quote(f.Value)
+		}
+	}
+	return res
+}
@@ -0,0 +1,79 @@
+package logical
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/loki/v3/pkg/engine/internal/types"
+	"github.com/grafana/loki/v3/pkg/engine/planner/schema"
+	"github.com/grafana/loki/v3/pkg/logql/log"
+)
+
+// The Parse instruction extracts labels from the log line of each row of a
+// table relation and adds them as parsed columns. Parse implements both
+// [Instruction] and [Value].
+type Parse struct {
+	id string
+
+	Table Value // The table relation to parse.
+
+	Kind types.ParserType // The parser to apply.
+
+	// Expression is the regular expression of the regexp parser or the
+	// pattern of the pattern parser.
+	Expression string
+
+	// Strict and KeepEmpty are flags of the logfmt parser.
+	Strict    bool
+	KeepEmpty bool
+
+	// Extractions limits the json and logfmt parsers to the given labels.
+	// If empty, all labels are extracted.
+	Extractions []log.LabelExtractionExpr
+}
+
+var (
+	_ Value       = (*Parse)(nil)
+	_ Instruction = (*Parse)(nil)
+)
+
+// Name returns an identifier for the Parse operation.
+func (p *Parse) Name() string {
+	if p.id != "" {
+		return p.id
+	}
+	return fmt.Sprintf("%p", p)
+}
+
+// String returns the disassembled SSA form of the Parse instruction.
+func (p *Parse) String() string {
+	props := fmt.Sprintf("kind=%s", p.Kind)
+
+	switch p.Kind {
+	case types.ParserTypeRegexp, types.ParserTypePattern:
+		props += fmt.Sprintf(", expression=%s", strconv.Quote(p.Expression))
+	case types.ParserTypeLogfmt:
+		props += fmt.Sprintf(", strict=%t, keep_empty=%t", p.Strict, p.KeepEmpty)
+	}
+
+	if len(p.Extractions) > 0 {
+		extractions := make([]string, len(p.Extractions))
+		for i, e := range p.Extractions {
+			extractions[i] = e.Identifier + "=" + strconv.Quote(e.Expression)
+		}
+		props += fmt.Sprintf(", extractions=(%s)", strings.Join(extractions, ", "))
+	}
+
+	return fmt.Sprintf("PARSE %s [%s]", p.Table.Name(), props)
+}
+
+// Schema returns the schema of the Parse plan.
+func (p *Parse) Schema() *schema.Schema {
+	// The parsed columns depend on the content of the log lines and are
+	// therefore only known at execution time.
+	return p.Table.Schema()
+}
+
+func (p *Parse) isInstruction() {}
+func (p *Parse) isValue()       {}
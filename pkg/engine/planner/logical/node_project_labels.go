@@ -0,0 +1,70 @@
+package logical
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/grafana/loki/v3/pkg/engine/planner/schema"
+	"github.com/grafana/loki/v3/pkg/logql/log"
+)
+
+// The ProjectLabels instruction keeps or drops labels of each row of a table
+// relation. ProjectLabels implements both [Instruction] and [Value].
+type ProjectLabels struct {
+	id string
+
+	Table Value // The table relation to project.
+
+	// Labels is the list of label names or label matchers. Labels that
+	// match an entry are dropped if Drop is true, otherwise all other labels
+	// are dropped.
+	Labels []log.NamedLabelMatcher
+	Drop   bool
+}
+
+var (
+	_ Value       = (*ProjectLabels)(nil)
+	_ Instruction = (*ProjectLabels)(nil)
+)
+
+// Name returns an identifier for the ProjectLabels operation.
+func (p *ProjectLabels) Name() string {
+	if p.id != "" {
+		return p.id
+	}
+	return fmt.Sprintf("%p", p)
+}
+
+// String returns the disassembled SSA form of the ProjectLabels instruction.
+func (p *ProjectLabels) String() string {
+	return fmt.Sprintf("%s %s [labels=(%s)]", p.opName(), p.Table.Name(), strings.Join(namedLabelMatcherStrings(p.Labels), ", "))
+}
+
+func (p *ProjectLabels) opName() string {
+	if p.Drop {
+		return "DROP"
+	}
+	return "KEEP"
+}
+
+// Schema returns the schema of the ProjectLabels plan.
+func (p *ProjectLabels) Schema() *schema.Schema {
+	return p.Table.Schema()
+}
+
+func (p *ProjectLabels) isInstruction() {}
+func (p *ProjectLabels) isValue()       {}
+
+// namedLabelMatcherStrings returns the string representations of the given
+// label names and matchers.
+func namedLabelMatcherStrings(matchers []log.NamedLabelMatcher) []string {
+	res := make([]string, len(matchers))
+	for i, m := range matchers {
+		if m.Matcher != nil {
+			res[i] = m.Matcher.String()
+		} else {
+			res[i] = m.Name
+		}
+	}
+	return res
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/prometheus/prometheus/model/labels"
+
 	"github.com/grafana/loki/v3/pkg/engine/internal/types"
 	"github.com/grafana/loki/v3/pkg/engine/internal/util"
 	"github.com/grafana/loki/v3/pkg/engine/planner/schema"
@@ -21,10 +23,14 @@ type RangeAggregation struct {
 	PartitionBy []ColumnRef // The columns to partition by.
 
 	Operation     types.RangeAggregationType // The type of aggregation operation to perform.
+	Unwrap        Value                      // The expression to extract sample values from, nil if the query does not unwrap.
+	Parameter     float64                    // The parameter of the operation, such as the quantile for quantile_over_time.
 	Start         time.Time
 	End           time.Time
 	Step          time.Duration
 	RangeInterval time.Duration
+
+	AbsentLabels []labels.Label // The labels of the series yielded by absent_over_time.
 }
 
 var (
@@ -43,6 +49,15 @@ func (r *RangeAggregation) Name() string {
 // String returns the disassembled SSA form of the RangeAggregation instruction.
 func (r *RangeAggregation) String() string {
 	props := fmt.Sprintf("operation=%s, start_ts=%s, end_ts=%s, step=%s, range=%s", r.Operation, util.FormatTimeRFC3339Nano(r.Start), util.FormatTimeRFC3339Nano(r.End), r.Step, r.RangeInterval)
+	if r.Operation.HasParameter() {
+		props += fmt.Sprintf(", parameter=%v", r.Parameter)
+	}
+	if r.Unwrap != nil {
+		props += fmt.Sprintf(", unwrap=%s", r.Unwrap.Name())
+	}
+	if len(r.AbsentLabels) > 0 {
+		props += fmt.Sprintf(", absent_labels=%s", labels.New(r.AbsentLabels...))
+	}
 
 	if len(r.PartitionBy) > 0 {
 		partitionBy := ""
@@ -77,11 +92,11 @@ func (r *RangeAggregation) Schema() *schema.Schema {
 			Name: types.ColumnNameBuiltinTimestamp,
 			Type: schema.ValueTypeTimestamp,
 		})
-		// Using int64 since only count_over_time is supported.
 		outputSchema.Columns = append(outputSchema.Columns, schema.ColumnSchema{
 			Name: types.ColumnNameGeneratedValue,
-			Type: schema.ValueTypeInt64,
+			Type: schema.ValueTypeFloat64,
 		})
+		return &outputSchema
 	}
 
 	// If partition by is empty, we aggregate by query-time series.
@@ -98,7 +113,7 @@ func (r *RangeAggregation) Schema() *schema.Schema {
 	}
 	outputSchema.Columns = append(outputSchema.Columns, schema.ColumnSchema{
 		Name: types.ColumnNameGeneratedValue,
-		Type: schema.ValueTypeInt64,
+		Type: schema.ValueTypeFloat64,
 	})
 	return &outputSchema
 }
@@ -18,8 +18,15 @@ type VectorAggregation struct {
 	// The columns to group by. If empty, all rows are aggregated into a single result.
 	GroupBy []ColumnRef
 
+	// Without inverts the grouping: rows are grouped by all columns except
+	// the ones listed in GroupBy.
+	Without bool
+
 	// The type of aggregation operation to perform (e.g., sum, min, max)
 	Operation types.VectorAggregationType
+
+	// Parameter of the operation, such as the number of samples for topk and bottomk.
+	Parameter int
 }
 
 var (
@@ -39,7 +46,12 @@ func (v *VectorAggregation) Name() string {
 func (v *VectorAggregation) String() string {
 	props := fmt.Sprintf("operation=%s", v.Operation)
 
-	if len(v.GroupBy) > 0 {
+	switch v.Operation {
+	case types.VectorAggregationTypeTopK, types.VectorAggregationTypeBottomK:
+		props += fmt.Sprintf(", parameter=%d", v.Parameter)
+	}
+
+	if len(v.GroupBy) > 0 || v.Without {
 		groupBy := ""
 		for i, columnRef := range v.GroupBy {
 			if i > 0 {
@@ -47,7 +59,11 @@ func (v *VectorAggregation) String() string {
 			}
 			groupBy += columnRef.String()
 		}
-		props += fmt.Sprintf(", group_by=(%s)", groupBy)
+		if v.Without {
+			props += fmt.Sprintf(", without=(%s)", groupBy)
+		} else {
+			props += fmt.Sprintf(", group_by=(%s)", groupBy)
+		}
 	}
 
 	return fmt.Sprintf("VECTOR_AGGREGATION %s [%s]", v.Table.Name(), props)
@@ -59,6 +75,9 @@ func (v *VectorAggregation) Schema() *schema.Schema {
 	// 1. Group by columns (if any)
 	// 2. Timestamp column (implicitly grouped by)
 	// 3. Aggregated value column
+	//
+	// The label columns are only known at execution time if the grouping is
+	// inverted or the operation retains the labels of the input samples.
 	outputSchema := schema.Schema{
 		Columns: make([]schema.ColumnSchema, 0, len(v.GroupBy)+2), // +2 for timestamp and value
 	}
@@ -70,10 +89,14 @@ func (v *VectorAggregation) Schema() *schema.Schema {
 		},
 		schema.ColumnSchema{
 			Name: types.ColumnNameGeneratedValue,
-			Type: schema.ValueTypeInt64,
+			Type: schema.ValueTypeFloat64,
 		},
 	)
 
+	if v.Without || v.Operation.KeepsSamples() {
+		return &outputSchema
+	}
+
 	// Add group by columns
 	for _, columnRef := range v.GroupBy {
 		outputSchema.Columns = append(outputSchema.Columns,
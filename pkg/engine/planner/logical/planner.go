@@ -3,6 +3,8 @@ package logical
 import (
 	"errors"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/prometheus/prometheus/model/labels"
@@ -46,9 +48,14 @@ func BuildPlan(params logql.Params) (*Plan, error) {
 // rangeInterval should be set to a non-zero value if the query contains [$range].
 func buildPlanForLogQuery(expr syntax.LogSelectorExpr, params logql.Params, isMetricQuery bool, rangeInterval time.Duration) (*Builder, error) {
 	var (
-		err        error
-		selector   Value
-		predicates []Value
+		err      error
+		selector Value
+
+		// stages holds the predicates, parsers and formatters of the pipeline
+		// in order of their appearance in the query. Parsers and formatters
+		// are represented by values without a table, which is filled in when
+		// building the plan.
+		stages []Value
 	)
 
 	// TODO(chaudum): Implement a Walk function that can return an error
@@ -61,7 +68,7 @@ func buildPlanForLogQuery(expr syntax.LogSelectorExpr, params logql.Params, isMe
 			selector = convertLabelMatchers(e.Matchers())
 			return true
 		case *syntax.LineFilterExpr:
-			predicates = append(predicates, convertLineFilterExpr(e))
+			stages = append(stages, convertLineFilterExpr(e))
 			// We do not want to traverse the AST further down, because line filter expressions can be nested,
 			// which would lead to multiple predicates of the same expression.
 			return false // do not traverse children
@@ -69,13 +76,27 @@ func buildPlanForLogQuery(expr syntax.LogSelectorExpr, params logql.Params, isMe
 			if val, innerErr := convertLabelFilter(e.LabelFilterer); innerErr != nil {
 				err = innerErr
 			} else {
-				predicates = append(predicates, val)
+				stages = append(stages, val)
 			}
 			return true
-		case *syntax.LineParserExpr, *syntax.LogfmtParserExpr, *syntax.LogfmtExpressionParserExpr, *syntax.JSONExpressionParserExpr,
-			*syntax.LineFmtExpr, *syntax.LabelFmtExpr,
-			*syntax.KeepLabelsExpr, *syntax.DropLabelsExpr:
-			err = errUnimplemented
+		case *syntax.LineParserExpr, *syntax.LogfmtParserExpr, *syntax.LogfmtExpressionParserExpr, *syntax.JSONExpressionParserExpr:
+			if parse, innerErr := convertParser(e); innerErr != nil {
+				err = innerErr
+			} else {
+				stages = append(stages, parse)
+			}
+			return false // do not traverse children
+		case *syntax.LineFmtExpr:
+			stages = append(stages, &LineFormat{Template: e.Value})
+			return false // do not traverse children
+		case *syntax.LabelFmtExpr:
+			stages = append(stages, &LabelFormat{Formats: e.Formats})
+			return false // do not traverse children
+		case *syntax.KeepLabelsExpr:
+			stages = append(stages, &ProjectLabels{Labels: e.KeepLabels()})
+			return false // do not traverse children
+		case *syntax.DropLabelsExpr:
+			stages = append(stages, &ProjectLabels{Labels: e.DropLabels(), Drop: true})
 			return false // do not traverse children
 		default:
 			err = errUnimplemented
@@ -91,6 +112,17 @@ func buildPlanForLogQuery(expr syntax.LogSelectorExpr, params logql.Params, isMe
 		return nil, fmt.Errorf("failed to parse shard: %w", err)
 	}
 
+	// Only predicates before the first parser or formatter can be used to
+	// resolve the data objects, since later predicates may refer to parsed
+	// columns or a modified log line.
+	var predicates []Value
+	for _, stage := range stages {
+		if !isPredicate(stage) {
+			break
+		}
+		predicates = append(predicates, stage)
+	}
+
 	// MAKETABLE -> DataObjScan
 	builder := NewBuilder(
 		&MakeTable{
@@ -100,15 +132,11 @@ func buildPlanForLogQuery(expr syntax.LogSelectorExpr, params logql.Params, isMe
 		},
 	)
 
-	direction := params.Direction()
-	if !isMetricQuery && direction == logproto.FORWARD {
-		return nil, fmt.Errorf("forward search log queries are not supported: %w", errUnimplemented)
-	}
-
 	// SORT -> SortMerge
-	// We always sort DESC. ASC timestamp sorting is not supported for logs queries,
-	// and metric queries do not care about the direction.
-	builder = builder.Sort(*timestampColumnRef(), false, false)
+	// Log queries are sorted in the direction of the query. Metric queries do
+	// not care about the direction, so they are always sorted DESC.
+	ascending := !isMetricQuery && params.Direction() == logproto.FORWARD
+	builder = builder.Sort(*timestampColumnRef(), ascending, false)
 
 	// SELECT -> Filter
 	start := params.Start()
@@ -118,8 +146,28 @@ func buildPlanForLogQuery(expr syntax.LogSelectorExpr, params logql.Params, isMe
 		builder = builder.Select(value)
 	}
 
-	for _, value := range predicates {
-		builder = builder.Select(value)
+	for _, stage := range stages {
+		switch stage := stage.(type) {
+		case *Parse:
+			// PARSE -> Parse
+			builder = builder.Parse(stage.Kind, stage.Expression, stage.Strict, stage.KeepEmpty, stage.Extractions)
+		case *LineFormat:
+			// LINE_FORMAT -> LineFormat
+			builder = builder.LineFormat(stage.Template)
+		case *LabelFormat:
+			// LABEL_FORMAT -> LabelFormat
+			builder = builder.LabelFormat(stage.Formats)
+		case *ProjectLabels:
+			// KEEP/DROP -> ProjectLabels
+			if stage.Drop {
+				builder = builder.DropLabels(stage.Labels)
+			} else {
+				builder = builder.KeepLabels(stage.Labels)
+			}
+		default:
+			// SELECT -> Filter
+			builder = builder.Select(stage)
+		}
 	}
 
 	// Metric queries do not apply a limit.
@@ -132,9 +180,18 @@ func buildPlanForLogQuery(expr syntax.LogSelectorExpr, params logql.Params, isMe
 	return builder, nil
 }
 
+// vectorAggregation holds the properties of a [syntax.VectorAggregationExpr]
+// required to build a [VectorAggregation] node.
+type vectorAggregation struct {
+	operation types.VectorAggregationType
+	groupBy   []ColumnRef
+	without   bool
+	parameter int
+}
+
 func buildPlanForSampleQuery(e syntax.SampleExpr, params logql.Params) (*Builder, error) {
-	if params.Step() > 0 {
-		return nil, fmt.Errorf("only instant metric queries are supported: %w", errUnimplemented)
+	if e, ok := e.(*syntax.BinOpExpr); ok {
+		return buildPlanForBinOp(e, params)
 	}
 
 	var (
@@ -142,39 +199,61 @@ func buildPlanForSampleQuery(e syntax.SampleExpr, params logql.Params) (*Builder
 
 		rangeAggType  types.RangeAggregationType
 		rangeInterval time.Duration
+		rangeParam    float64
+		unwrap        Value
 
-		vecAggType types.VectorAggregationType
-		groupBy    []ColumnRef
+		// vector aggregations in the order they are encountered in the AST,
+		// from the outermost to the innermost one.
+		vecAggs []vectorAggregation
 	)
 
 	e.Walk(func(e syntax.Expr) bool {
 		switch e := e.(type) {
 		case *syntax.RangeAggregationExpr:
-			// only count operation is supported for range aggregation.
-			// offsets are not yet supported.
-			if e.Operation != syntax.OpRangeTypeCount || e.Left.Offset != 0 {
+			// offsets, `@` modifiers and grouping of range aggregations are not yet supported.
+			if e.Left.Offset != 0 || e.Left.At != nil || e.Grouping != nil {
 				err = errUnimplemented
 				return false
 			}
 
-			rangeAggType = types.RangeAggregationTypeCount
+			rangeAggType = convertRangeAggregationType(e.Operation)
+			if rangeAggType == types.RangeAggregationTypeInvalid {
+				err = errUnimplemented
+				return false
+			}
+			if e.Params != nil {
+				rangeParam = *e.Params
+			}
+
+			if e.Left.Unwrap != nil {
+				unwrap, err = convertUnwrap(e.Left.Unwrap)
+				if err != nil {
+					return false
+				}
+			}
+
 			rangeInterval = e.Left.Interval
 			return false // do not traverse log range query
 
 		case *syntax.VectorAggregationExpr:
-			// only sum operation is supported for vector aggregation
-			// grouping by atleast one label is required.
-			if e.Operation != syntax.OpTypeSum ||
-				e.Grouping == nil || len(e.Grouping.Groups) == 0 || e.Grouping.Without {
+			vecAggType := convertVectorAggregationType(e.Operation)
+			if vecAggType == types.VectorAggregationTypeInvalid {
 				err = errUnimplemented
 				return false
 			}
 
-			vecAggType = types.VectorAggregationTypeSum
-			groupBy = make([]ColumnRef, 0, len(e.Grouping.Groups))
-			for _, group := range e.Grouping.Groups {
-				groupBy = append(groupBy, *NewColumnRef(group, types.ColumnTypeAmbiguous))
+			vecAgg := vectorAggregation{
+				operation: vecAggType,
+				parameter: e.Params,
 			}
+			if e.Grouping != nil {
+				vecAgg.without = e.Grouping.Without
+				vecAgg.groupBy = make([]ColumnRef, 0, len(e.Grouping.Groups))
+				for _, group := range e.Grouping.Groups {
+					vecAgg.groupBy = append(vecAgg.groupBy, *NewColumnRef(group, types.ColumnTypeAmbiguous))
+				}
+			}
+			vecAggs = append(vecAggs, vecAgg)
 
 			return true
 		default:
@@ -186,7 +265,7 @@ func buildPlanForSampleQuery(e syntax.SampleExpr, params logql.Params) (*Builder
 		return nil, err
 	}
 
-	if rangeAggType == types.RangeAggregationTypeInvalid || vecAggType == types.VectorAggregationTypeInvalid {
+	if rangeAggType == types.RangeAggregationTypeInvalid || len(vecAggs) == 0 {
 		return nil, errUnimplemented
 	}
 
@@ -200,13 +279,287 @@ func buildPlanForSampleQuery(e syntax.SampleExpr, params logql.Params) (*Builder
 		return nil, err
 	}
 
-	builder = builder.RangeAggregation(
-		nil, rangeAggType, params.Start(), params.End(), params.Step(), rangeInterval,
-	).VectorAggregation(groupBy, vecAggType)
+	builder = builder.UnwrapRangeAggregation(
+		nil, rangeAggType, unwrap, rangeParam, params.Start(), params.End(), params.Step(), rangeInterval,
+	)
+	if rangeAggType == types.RangeAggregationTypeAbsent {
+		builder.val.(*RangeAggregation).AbsentLabels = absentLabels(logSelectorExpr.Matchers())
+	}
+
+	// apply vector aggregations from the innermost to the outermost one
+	for i := len(vecAggs) - 1; i >= 0; i-- {
+		vecAgg := vecAggs[i]
+		builder = builder.GroupedVectorAggregation(vecAgg.groupBy, vecAgg.without, vecAgg.operation, vecAgg.parameter)
+	}
 
 	return builder, nil
 }
 
+// absentLabels returns the labels of the series yielded by absent_over_time
+// for the given stream matchers. Only labels matched by a single equality
+// matcher are kept, as the value of any other label is unknown.
+func absentLabels(matchers []*labels.Matcher) []labels.Label {
+	var (
+		result []labels.Label
+		drop   = map[string]bool{}
+	)
+	for _, m := range matchers {
+		if m.Name == labels.MetricName {
+			continue
+		}
+		if m.Type != labels.MatchEqual || slices.ContainsFunc(result, func(l labels.Label) bool { return l.Name == m.Name }) {
+			drop[m.Name] = true
+			continue
+		}
+		result = append(result, labels.Label{Name: m.Name, Value: m.Value})
+	}
+
+	result = slices.DeleteFunc(result, func(l labels.Label) bool { return drop[l.Name] })
+	slices.SortFunc(result, func(a, b labels.Label) int { return strings.Compare(a.Name, b.Name) })
+	return result
+}
+
+// buildPlanForBinOp builds the plan of a binary operation between two sample
+// expressions, of which one can be a literal.
+func buildPlanForBinOp(e *syntax.BinOpExpr, params logql.Params) (*Builder, error) {
+	op := convertBinaryOp(e.Op)
+	if op == types.BinaryOpInvalid {
+		return nil, fmt.Errorf("binary operation %s is not supported: %w", e.Op, errUnimplemented)
+	}
+
+	lhs, err := buildPlanForOperand(e.SampleExpr, params)
+	if err != nil {
+		return nil, err
+	}
+	rhs, err := buildPlanForOperand(e.RHS, params)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		returnBool bool
+		matching   *VectorMatching
+	)
+	if e.Opts != nil {
+		returnBool = e.Opts.ReturnBool
+
+		// Vector matching only applies to operations between two vectors.
+		_, lhsLiteral := lhs.Value().(*Literal)
+		_, rhsLiteral := rhs.Value().(*Literal)
+		if e.Opts.VectorMatching != nil && !lhsLiteral && !rhsLiteral {
+			matching = convertVectorMatching(e.Opts.VectorMatching, op)
+		}
+	}
+
+	return lhs.BinOp(op, rhs.Value(), returnBool, matching), nil
+}
+
+// buildPlanForOperand builds the plan of an operand of a binary operation.
+func buildPlanForOperand(e syntax.SampleExpr, params logql.Params) (*Builder, error) {
+	if lit, ok := e.(*syntax.LiteralExpr); ok {
+		val, err := lit.Value()
+		if err != nil {
+			return nil, err
+		}
+		return NewBuilder(NewLiteral(val)), nil
+	}
+	return buildPlanForSampleQuery(e, params)
+}
+
+func convertBinaryOp(op string) types.BinaryOp {
+	switch op {
+	case syntax.OpTypeAdd:
+		return types.BinaryOpAdd
+	case syntax.OpTypeSub:
+		return types.BinaryOpSub
+	case syntax.OpTypeMul:
+		return types.BinaryOpMul
+	case syntax.OpTypeDiv:
+		return types.BinaryOpDiv
+	case syntax.OpTypeMod:
+		return types.BinaryOpMod
+	case syntax.OpTypePow:
+		return types.BinaryOpPow
+	case syntax.OpTypeCmpEQ:
+		return types.BinaryOpEq
+	case syntax.OpTypeNEQ:
+		return types.BinaryOpNeq
+	case syntax.OpTypeGT:
+		return types.BinaryOpGt
+	case syntax.OpTypeGTE:
+		return types.BinaryOpGte
+	case syntax.OpTypeLT:
+		return types.BinaryOpLt
+	case syntax.OpTypeLTE:
+		return types.BinaryOpLte
+	case syntax.OpTypeAnd:
+		return types.BinaryOpAnd
+	case syntax.OpTypeOr:
+		return types.BinaryOpOr
+	case syntax.OpTypeUnless:
+		return types.BinaryOpUnless
+	default:
+		return types.BinaryOpInvalid
+	}
+}
+
+func convertVectorMatching(m *syntax.VectorMatching, op types.BinaryOp) *VectorMatching {
+	matching := &VectorMatching{
+		On:             m.On,
+		MatchingLabels: make([]ColumnRef, 0, len(m.MatchingLabels)),
+		Include:        make([]ColumnRef, 0, len(m.Include)),
+	}
+	for _, name := range m.MatchingLabels {
+		matching.MatchingLabels = append(matching.MatchingLabels, *NewColumnRef(name, types.ColumnTypeAmbiguous))
+	}
+	for _, name := range m.Include {
+		matching.Include = append(matching.Include, *NewColumnRef(name, types.ColumnTypeAmbiguous))
+	}
+
+	switch {
+	case op.IsSetOperation():
+		matching.Card = types.VectorMatchManyToMany
+	case m.Card == syntax.CardManyToOne:
+		matching.Card = types.VectorMatchManyToOne
+	case m.Card == syntax.CardOneToMany:
+		matching.Card = types.VectorMatchOneToMany
+	default:
+		matching.Card = types.VectorMatchOneToOne
+	}
+	return matching
+}
+
+func convertVectorAggregationType(op string) types.VectorAggregationType {
+	switch op {
+	case syntax.OpTypeSum:
+		return types.VectorAggregationTypeSum
+	case syntax.OpTypeAvg:
+		return types.VectorAggregationTypeAvg
+	case syntax.OpTypeMin:
+		return types.VectorAggregationTypeMin
+	case syntax.OpTypeMax:
+		return types.VectorAggregationTypeMax
+	case syntax.OpTypeCount:
+		return types.VectorAggregationTypeCount
+	case syntax.OpTypeStddev:
+		return types.VectorAggregationTypeStddev
+	case syntax.OpTypeStdvar:
+		return types.VectorAggregationTypeStdvar
+	case syntax.OpTypeTopK:
+		return types.VectorAggregationTypeTopK
+	case syntax.OpTypeBottomK:
+		return types.VectorAggregationTypeBottomK
+	case syntax.OpTypeSort:
+		return types.VectorAggregationTypeSort
+	case syntax.OpTypeSortDesc:
+		return types.VectorAggregationTypeSortDesc
+	default:
+		return types.VectorAggregationTypeInvalid
+	}
+}
+
+func convertRangeAggregationType(op string) types.RangeAggregationType {
+	switch op {
+	case syntax.OpRangeTypeCount:
+		return types.RangeAggregationTypeCount
+	case syntax.OpRangeTypeRate:
+		return types.RangeAggregationTypeRate
+	case syntax.OpRangeTypeBytes:
+		return types.RangeAggregationTypeBytes
+	case syntax.OpRangeTypeBytesRate:
+		return types.RangeAggregationTypeBytesRate
+	case syntax.OpRangeTypeSum:
+		return types.RangeAggregationTypeSum
+	case syntax.OpRangeTypeAvg:
+		return types.RangeAggregationTypeAvg
+	case syntax.OpRangeTypeMin:
+		return types.RangeAggregationTypeMin
+	case syntax.OpRangeTypeMax:
+		return types.RangeAggregationTypeMax
+	case syntax.OpRangeTypeFirst:
+		return types.RangeAggregationTypeFirst
+	case syntax.OpRangeTypeLast:
+		return types.RangeAggregationTypeLast
+	case syntax.OpRangeTypeStddev:
+		return types.RangeAggregationTypeStddev
+	case syntax.OpRangeTypeStdvar:
+		return types.RangeAggregationTypeStdvar
+	case syntax.OpRangeTypeQuantile:
+		return types.RangeAggregationTypeQuantile
+	case syntax.OpRangeTypeAbsent:
+		return types.RangeAggregationTypeAbsent
+	case syntax.OpRangeTypeChanges:
+		return types.RangeAggregationTypeChanges
+	case syntax.OpRangeTypeResets:
+		return types.RangeAggregationTypeResets
+	case syntax.OpRangeTypeDeriv:
+		return types.RangeAggregationTypeDeriv
+	case syntax.OpRangeTypePredictLinear:
+		return types.RangeAggregationTypePredictLinear
+	default:
+		return types.RangeAggregationTypeInvalid
+	}
+}
+
+// convertUnwrap converts an [syntax.UnwrapExpr] into the [Value] that yields the sample value.
+func convertUnwrap(expr *syntax.UnwrapExpr) (Value, error) {
+	// TODO: Support label filters after unwrap, such as `| unwrap latency | __error__=""`.
+	if len(expr.PostFilters) > 0 {
+		return nil, errUnimplemented
+	}
+
+	column := NewColumnRef(expr.Identifier, types.ColumnTypeAmbiguous)
+	switch expr.Operation {
+	case "":
+		return column, nil
+	case syntax.OpConvBytes:
+		return &UnaryOp{Op: types.UnaryOpCastBytes, Value: column}, nil
+	case syntax.OpConvDuration, syntax.OpConvDurationSeconds:
+		return &UnaryOp{Op: types.UnaryOpCastDuration, Value: column}, nil
+	default:
+		return nil, fmt.Errorf("unwrap conversion %s is not supported: %w", expr.Operation, errUnimplemented)
+	}
+}
+
+// isPredicate returns true if the given pipeline stage is a predicate, as
+// opposed to a parser or formatter.
+func isPredicate(stage Value) bool {
+	switch stage.(type) {
+	case *Parse, *LineFormat, *LabelFormat, *ProjectLabels:
+		return false
+	default:
+		return true
+	}
+}
+
+// convertParser converts a parser stage expression into a [Parse] value
+// without a table.
+func convertParser(expr syntax.Expr) (*Parse, error) {
+	switch e := expr.(type) {
+	case *syntax.LineParserExpr:
+		switch e.Op {
+		case syntax.OpParserTypeJSON:
+			return &Parse{Kind: types.ParserTypeJSON}, nil
+		case syntax.OpParserTypeRegexp:
+			return &Parse{Kind: types.ParserTypeRegexp, Expression: e.Param}, nil
+		case syntax.OpParserTypePattern:
+			return &Parse{Kind: types.ParserTypePattern, Expression: e.Param}, nil
+		case syntax.OpParserTypeUnpack:
+			return &Parse{Kind: types.ParserTypeUnpack}, nil
+		default:
+			return nil, fmt.Errorf("parser %s is not supported: %w", e.Op, errUnimplemented)
+		}
+	case *syntax.LogfmtParserExpr:
+		return &Parse{Kind: types.ParserTypeLogfmt, Strict: e.Strict, KeepEmpty: e.KeepEmpty}, nil
+	case *syntax.LogfmtExpressionParserExpr:
+		return &Parse{Kind: types.ParserTypeLogfmt, Strict: e.Strict, KeepEmpty: e.KeepEmpty, Extractions: e.Expressions}, nil
+	case *syntax.JSONExpressionParserExpr:
+		return &Parse{Kind: types.ParserTypeJSON, Extractions: e.Expressions}, nil
+	default:
+		return nil, fmt.Errorf("invalid parser expression %T: %w", expr, errUnimplemented)
+	}
+}
+
 func convertLabelMatchers(matchers []*labels.Matcher) Value {
 	var value *BinOp
 
@@ -83,7 +83,7 @@ func TestConvertAST_Success(t *testing.T) {
 		statement: `{cluster="prod", namespace=~"loki-.*"} | foo="bar" or bar="baz" |= "metric.go" |= "foo" or "bar" !~ "(a|b|c)" `,
 		start:     3600,
 		end:       7200,
-		direction: logproto.BACKWARD, // ASC is not supported
+		direction: logproto.BACKWARD,
 		limit:     1000,
 	}
 	logicalPlan, err := BuildPlan(q)
@@ -121,6 +121,98 @@ RETURN %20
 	t.Logf("\n%s\n", sb.String())
 }
 
+func TestConvertAST_Parse_Success(t *testing.T) {
+	q := &query{
+		statement: `{cluster="prod"} |= "metric.go" | logfmt | level="error" | regexp "status=(?P<status>\\d+)"`,
+		start:     3600,
+		end:       7200,
+		direction: logproto.BACKWARD,
+		limit:     1000,
+	}
+	logicalPlan, err := BuildPlan(q)
+	require.NoError(t, err)
+	t.Logf("\n%s\n", logicalPlan.String())
+
+	expected := `%1 = EQ label.cluster "prod"
+%2 = MAKETABLE [selector=%1, predicates=[%8], shard=0_of_1]
+%3 = SORT %2 [column=builtin.timestamp, asc=false, nulls_first=false]
+%4 = GTE builtin.timestamp 1970-01-01T01:00:00Z
+%5 = SELECT %3 [predicate=%4]
+%6 = LT builtin.timestamp 1970-01-01T02:00:00Z
+%7 = SELECT %5 [predicate=%6]
+%8 = MATCH_STR builtin.message "metric.go"
+%9 = SELECT %7 [predicate=%8]
+%10 = PARSE %9 [kind=logfmt, strict=false, keep_empty=false]
+%11 = EQ ambiguous.level "error"
+%12 = SELECT %10 [predicate=%11]
+%13 = PARSE %12 [kind=regexp, expression="status=(?P<status>\\d+)"]
+%14 = LIMIT %13 [skip=0, fetch=1000]
+RETURN %14
+`
+
+	require.Equal(t, expected, logicalPlan.String())
+}
+
+func TestConvertAST_Format_Success(t *testing.T) {
+	q := &query{
+		statement: `{cluster="prod"} | logfmt | label_format dst=src, level="{{ .level | ToUpper }}" | drop __error__ | line_format "{{.msg}}" |= "foo"`,
+		start:     3600,
+		end:       7200,
+		direction: logproto.BACKWARD,
+		limit:     1000,
+	}
+	logicalPlan, err := BuildPlan(q)
+	require.NoError(t, err)
+	t.Logf("\n%s\n", logicalPlan.String())
+
+	expected := `%1 = EQ label.cluster "prod"
+%2 = MAKETABLE [selector=%1, predicates=[], shard=0_of_1]
+%3 = SORT %2 [column=builtin.timestamp, asc=false, nulls_first=false]
+%4 = GTE builtin.timestamp 1970-01-01T01:00:00Z
+%5 = SELECT %3 [predicate=%4]
+%6 = LT builtin.timestamp 1970-01-01T02:00:00Z
+%7 = SELECT %5 [predicate=%6]
+%8 = PARSE %7 [kind=logfmt, strict=false, keep_empty=false]
+%9 = LABEL_FORMAT %8 [formats=(dst=src, level="{{ .level | ToUpper }}")]
+%10 = DROP %9 [labels=(__error__)]
+%11 = LINE_FORMAT %10 [template="{{.msg}}"]
+%12 = MATCH_STR builtin.message "foo"
+%13 = SELECT %11 [predicate=%12]
+%14 = LIMIT %13 [skip=0, fetch=1000]
+RETURN %14
+`
+
+	require.Equal(t, expected, logicalPlan.String())
+}
+
+func TestConvertAST_Forward_Success(t *testing.T) {
+	q := &query{
+		statement: `{cluster="prod"} |= "foo"`,
+		start:     3600,
+		end:       7200,
+		direction: logproto.FORWARD,
+		limit:     100,
+	}
+	logicalPlan, err := BuildPlan(q)
+	require.NoError(t, err)
+	t.Logf("\n%s\n", logicalPlan.String())
+
+	expected := `%1 = EQ label.cluster "prod"
+%2 = MAKETABLE [selector=%1, predicates=[%8], shard=0_of_1]
+%3 = SORT %2 [column=builtin.timestamp, asc=true, nulls_first=false]
+%4 = GTE builtin.timestamp 1970-01-01T01:00:00Z
+%5 = SELECT %3 [predicate=%4]
+%6 = LT builtin.timestamp 1970-01-01T02:00:00Z
+%7 = SELECT %5 [predicate=%6]
+%8 = MATCH_STR builtin.message "foo"
+%9 = SELECT %7 [predicate=%8]
+%10 = LIMIT %9 [skip=0, fetch=100]
+RETURN %10
+`
+
+	require.Equal(t, expected, logicalPlan.String())
+}
+
 func TestConvertAST_MetricQuery_Success(t *testing.T) {
 	q := &query{
 		statement: `sum by (level) (count_over_time({cluster="prod", namespace=~"loki-.*"} |= "metric.go"[5m]))`,
@@ -157,9 +249,141 @@ RETURN %13
 	t.Logf("\n%s\n", sb.String())
 }
 
+func TestConvertAST_NestedVectorAggregation_Success(t *testing.T) {
+	q := &query{
+		statement: `topk(5, sum without (pod) (rate({cluster="prod"}[5m])))`,
+		start:     3600,
+		end:       7200,
+		interval:  5 * time.Minute,
+	}
+
+	logicalPlan, err := BuildPlan(q)
+	require.NoError(t, err)
+	t.Logf("\n%s\n", logicalPlan.String())
+
+	expected := `%1 = EQ label.cluster "prod"
+%2 = MAKETABLE [selector=%1, predicates=[], shard=0_of_1]
+%3 = SORT %2 [column=builtin.timestamp, asc=false, nulls_first=false]
+%4 = GTE builtin.timestamp 1970-01-01T00:55:00Z
+%5 = SELECT %3 [predicate=%4]
+%6 = LT builtin.timestamp 1970-01-01T02:00:00Z
+%7 = SELECT %5 [predicate=%6]
+%8 = RANGE_AGGREGATION %7 [operation=rate, start_ts=1970-01-01T01:00:00Z, end_ts=1970-01-01T02:00:00Z, step=0s, range=5m0s]
+%9 = VECTOR_AGGREGATION %8 [operation=sum, without=(ambiguous.pod)]
+%10 = VECTOR_AGGREGATION %9 [operation=topk, parameter=5]
+RETURN %10
+`
+
+	require.Equal(t, expected, logicalPlan.String())
+}
+
+func TestConvertAST_BinOp_Success(t *testing.T) {
+	q := &query{
+		statement: `sum by (level) (rate({cluster="prod"} |= "error" [5m])) / on (level) group_left (pod) sum by (level, pod) (rate({cluster="prod"}[5m])) > bool 0.1`,
+		start:     3600,
+		end:       7200,
+		interval:  5 * time.Minute,
+	}
+
+	logicalPlan, err := BuildPlan(q)
+	require.NoError(t, err)
+	t.Logf("\n%s\n", logicalPlan.String())
+
+	expected := `%1 = EQ label.cluster "prod"
+%2 = MAKETABLE [selector=%1, predicates=[%8], shard=0_of_1]
+%3 = SORT %2 [column=builtin.timestamp, asc=false, nulls_first=false]
+%4 = GTE builtin.timestamp 1970-01-01T00:55:00Z
+%5 = SELECT %3 [predicate=%4]
+%6 = LT builtin.timestamp 1970-01-01T02:00:00Z
+%7 = SELECT %5 [predicate=%6]
+%8 = MATCH_STR builtin.message "error"
+%9 = SELECT %7 [predicate=%8]
+%10 = RANGE_AGGREGATION %9 [operation=rate, start_ts=1970-01-01T01:00:00Z, end_ts=1970-01-01T02:00:00Z, step=0s, range=5m0s]
+%11 = VECTOR_AGGREGATION %10 [operation=sum, group_by=(ambiguous.level)]
+%12 = EQ label.cluster "prod"
+%13 = MAKETABLE [selector=%12, predicates=[], shard=0_of_1]
+%14 = SORT %13 [column=builtin.timestamp, asc=false, nulls_first=false]
+%15 = GTE builtin.timestamp 1970-01-01T00:55:00Z
+%16 = SELECT %14 [predicate=%15]
+%17 = LT builtin.timestamp 1970-01-01T02:00:00Z
+%18 = SELECT %16 [predicate=%17]
+%19 = RANGE_AGGREGATION %18 [operation=rate, start_ts=1970-01-01T01:00:00Z, end_ts=1970-01-01T02:00:00Z, step=0s, range=5m0s]
+%20 = VECTOR_AGGREGATION %19 [operation=sum, group_by=(ambiguous.level, ambiguous.pod)]
+%21 = DIV %11 %20 [on=(ambiguous.level), group_left=(ambiguous.pod)]
+%22 = GT %21 0.1 [return_bool=true]
+RETURN %22
+`
+
+	require.Equal(t, expected, logicalPlan.String())
+}
+
+func TestConvertAST_UnwrapConversion_Success(t *testing.T) {
+	q := &query{
+		statement: `sum by (level) (sum_over_time({cluster="prod"} | logfmt | unwrap duration(latency) [5m]))`,
+		start:     3600,
+		end:       7200,
+		interval:  5 * time.Minute,
+	}
+
+	logicalPlan, err := BuildPlan(q)
+	require.NoError(t, err)
+	t.Logf("\n%s\n", logicalPlan.String())
+
+	expected := `%1 = EQ label.cluster "prod"
+%2 = MAKETABLE [selector=%1, predicates=[], shard=0_of_1]
+%3 = SORT %2 [column=builtin.timestamp, asc=false, nulls_first=false]
+%4 = GTE builtin.timestamp 1970-01-01T00:55:00Z
+%5 = SELECT %3 [predicate=%4]
+%6 = LT builtin.timestamp 1970-01-01T02:00:00Z
+%7 = SELECT %5 [predicate=%6]
+%8 = PARSE %7 [kind=logfmt, strict=false, keep_empty=false]
+%9 = CAST_DURATION ambiguous.latency
+%10 = RANGE_AGGREGATION %8 [operation=sum, start_ts=1970-01-01T01:00:00Z, end_ts=1970-01-01T02:00:00Z, step=0s, range=5m0s, unwrap=%9]
+%11 = VECTOR_AGGREGATION %10 [operation=sum, group_by=(ambiguous.level)]
+RETURN %11
+`
+
+	require.Equal(t, expected, logicalPlan.String())
+}
+
+func TestConvertAST_Absent_Success(t *testing.T) {
+	q := &query{
+		statement: `max by (cluster) (absent_over_time({cluster="prod", namespace=~"loki-.*", app="foo", app!="bar"}[5m]))`,
+		start:     3600,
+		end:       7200,
+		interval:  5 * time.Minute,
+	}
+
+	logicalPlan, err := BuildPlan(q)
+	require.NoError(t, err)
+	t.Logf("\n%s\n", logicalPlan.String())
+
+	// only labels matched by a single equality matcher are kept
+	expected := `%1 = EQ label.cluster "prod"
+%2 = MATCH_RE label.namespace "loki-.*"
+%3 = AND %1 %2
+%4 = EQ label.app "foo"
+%5 = AND %3 %4
+%6 = NEQ label.app "bar"
+%7 = AND %5 %6
+%8 = MAKETABLE [selector=%7, predicates=[], shard=0_of_1]
+%9 = SORT %8 [column=builtin.timestamp, asc=false, nulls_first=false]
+%10 = GTE builtin.timestamp 1970-01-01T00:55:00Z
+%11 = SELECT %9 [predicate=%10]
+%12 = LT builtin.timestamp 1970-01-01T02:00:00Z
+%13 = SELECT %11 [predicate=%12]
+%14 = RANGE_AGGREGATION %13 [operation=absent, start_ts=1970-01-01T01:00:00Z, end_ts=1970-01-01T02:00:00Z, step=0s, range=5m0s, absent_labels={cluster="prod"}]
+%15 = VECTOR_AGGREGATION %14 [operation=max, group_by=(ambiguous.cluster)]
+RETURN %15
+`
+
+	require.Equal(t, expected, logicalPlan.String())
+}
+
 func TestCanExecuteQuery(t *testing.T) {
 	for _, tt := range []struct {
 		statement string
+		step      time.Duration
 		expected  bool
 	}{
 		{
@@ -184,33 +408,51 @@ func TestCanExecuteQuery(t *testing.T) {
 		},
 		{
 			statement: `{env="prod"} | json`,
+			expected:  true,
 		},
 		{
 			statement: `{env="prod"} | json foo="bar"`,
+			expected:  true,
 		},
 		{
 			statement: `{env="prod"} | logfmt`,
+			expected:  true,
 		},
 		{
 			statement: `{env="prod"} | logfmt foo="bar"`,
+			expected:  true,
 		},
 		{
 			statement: `{env="prod"} | pattern "<_> foo=<foo> <_>"`,
+			expected:  true,
 		},
 		{
 			statement: `{env="prod"} | regexp ".* foo=(?P<foo>.+) .*"`,
+			expected:  true,
 		},
 		{
 			statement: `{env="prod"} | unpack`,
+			expected:  true,
 		},
 		{
 			statement: `{env="prod"} |= "metrics.go" | logfmt`,
+			expected:  true,
 		},
 		{
 			statement: `{env="prod"} | line_format "{.cluster}"`,
+			expected:  true,
 		},
 		{
 			statement: `{env="prod"} | label_format cluster="us"`,
+			expected:  true,
+		},
+		{
+			statement: `{env="prod"} | keep cluster, level="error"`,
+			expected:  true,
+		},
+		{
+			statement: `{env="prod"} | drop cluster, level="error"`,
+			expected:  true,
 		},
 		{
 			statement: `{env="prod"} |= "metric.go" | retry > 2`,
@@ -224,26 +466,105 @@ func TestCanExecuteQuery(t *testing.T) {
 			statement: `count_over_time({env="prod"}[1m])`,
 		},
 		{
-			// group by labels are required
 			statement: `sum(count_over_time({env="prod"}[1m]))`,
+			expected:  true,
+		},
+		{
+			statement: `sum without (level) (count_over_time({env="prod"}[1m]))`,
+			expected:  true,
+		},
+		{
+			statement: `topk(10, sum by (level) (rate({env="prod"}[1m])))`,
+			expected:  true,
+		},
+		{
+			statement: `sort_desc(count_over_time({env="prod"}[1m]))`,
+			expected:  true,
 		},
 		{
-			// rate is not supported
 			statement: `sum by (level) (rate({env="prod"}[1m]))`,
+			expected:  true,
+		},
+		{
+			statement: `sum by (level) (quantile_over_time(0.99, {env="prod"} | unwrap latency [1m]))`,
+			expected:  true,
+		},
+		{
+			statement: `sum by (level) (sum_over_time({env="prod"} | unwrap duration(latency) [1m]))`,
+			expected:  true,
+		},
+		{
+			statement: `max by (level) (deriv({env="prod"} | unwrap queue_depth [10m]))`,
+			expected:  true,
+		},
+		{
+			statement: `max by (level) (predict_linear(3600, {env="prod"} | unwrap queue_depth [1h])) > 1000`,
+			expected:  true,
+		},
+		{
+			statement: `sum by (level) (changes_over_time({env="prod"} | unwrap status [5m]))`,
+			expected:  true,
+		},
+		{
+			statement: `sum by (level) (resets_over_time({env="prod"} | unwrap requests_total [5m]))`,
+			expected:  true,
+		},
+		{
+			statement: `max(avg_over_time({env="prod"} | logfmt | unwrap bytes(size) [1m]))`,
+			expected:  true,
 		},
 		{
-			// max is not supported
 			statement: `max by (level) (count_over_time({env="prod"}[1m]))`,
+			expected:  true,
+		},
+		{
+			// approx_topk is not supported
+			statement: `approx_topk(10, count_over_time({env="prod"}[1m]))`,
 		},
 		{
 			statement: `sum by (level) (count_over_time({env="prod"}[1m] offset 5m))`,
 		},
+		{
+			// range queries are supported
+			statement: `sum by (level) (count_over_time({env="prod"}[1m]))`,
+			step:      15 * time.Second,
+			expected:  true,
+		},
+		{
+			statement: `sum(rate({env="prod"} |= "error" [1m])) / sum(rate({env="prod"}[1m]))`,
+			expected:  true,
+		},
+		{
+			statement: `sum by (level) (count_over_time({env="prod"}[1m])) > 10`,
+			expected:  true,
+		},
+		{
+			statement: `2 * sum by (level) (count_over_time({env="prod"}[1m]))`,
+			expected:  true,
+		},
+		{
+			statement: `sum by (level) (count_over_time({env="prod"}[1m])) > bool on (level) group_left (pod) sum by (level, pod) (count_over_time({env="dev"}[1m]))`,
+			expected:  true,
+		},
+		{
+			statement: `sum by (level) (count_over_time({env="prod"}[1m])) unless ignoring (pod) sum by (level) (count_over_time({env="dev"}[1m]))`,
+			expected:  true,
+		},
+		{
+			// both legs must be supported
+			statement: `sum by (level) (count_over_time({env="prod"}[1m])) or count_over_time({env="prod"}[1m])`,
+		},
+		{
+			// binary operations within vector aggregations are not supported
+			statement: `sum(count_over_time({env="prod"}[1m]) / 2)`,
+		},
 	} {
 		t.Run(tt.statement, func(t *testing.T) {
 			q := &query{
 				statement: tt.statement,
 				start:     1000,
 				end:       2000,
+				step:      tt.step,
 				direction: logproto.BACKWARD,
 				limit:     1000,
 			}
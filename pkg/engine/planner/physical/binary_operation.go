@@ -0,0 +1,71 @@
+package physical
+
+import (
+	"fmt"
+
+	"github.com/grafana/loki/v3/pkg/engine/internal/types"
+)
+
+// BinaryOperation represents a physical plan node that performs a binary
+// operation between two vectors, or between a vector and a scalar.
+// Samples of two vectors are joined by matching their series on labels at
+// each timestamp.
+type BinaryOperation struct {
+	id string
+
+	// Left and Right are the child nodes producing the operands. One of them
+	// is nil if the respective operand is the Scalar.
+	Left, Right Node
+
+	// Scalar is the scalar operand of an operation between a vector and a
+	// scalar. It is nil for operations between two vectors.
+	Scalar LiteralExpression
+
+	// Op defines the binary operation to perform.
+	Op types.BinaryOp
+
+	// ReturnBool returns 0 or 1 for comparisons instead of filtering out the
+	// samples that do not satisfy the comparison.
+	ReturnBool bool
+
+	// VectorMatching defines how the series of two vectors are matched. It is
+	// nil for operations between a vector and a scalar.
+	VectorMatching *VectorMatching
+}
+
+// VectorMatching describes how the series of two vectors in a
+// [BinaryOperation] are matched.
+type VectorMatching struct {
+	// On matches the series only on the MatchingLabels, instead of on all
+	// labels except the MatchingLabels.
+	On             bool
+	MatchingLabels []ColumnExpression
+
+	// Card is the cardinality of the series matching between the vectors.
+	Card types.VectorMatchCardinality
+
+	// Include contains the labels of the series of the "one" side that are
+	// added to the result of a many-to-one or one-to-many matching.
+	Include []ColumnExpression
+}
+
+// ID implements the [Node] interface.
+// Returns a string that uniquely identifies the node in the plan.
+func (b *BinaryOperation) ID() string {
+	if b.id == "" {
+		return fmt.Sprintf("%p", b)
+	}
+	return b.id
+}
+
+// Type implements the [Node] interface.
+// Returns the type of the node.
+func (*BinaryOperation) Type() NodeType {
+	return NodeTypeBinaryOperation
+}
+
+// Accept implements the [Node] interface.
+// Dispatches itself to the provided [Visitor] v
+func (b *BinaryOperation) Accept(v Visitor) error {
+	return v.VisitBinaryOperation(b)
+}
@@ -42,9 +42,12 @@ func (s ShardInfo) String() string {
 type Catalog interface {
 	// ResolveDataObj returns a list of data object paths,
 	// a list of stream IDs for each data data object,
-	// and a list of sections for each data object
-	ResolveDataObj(Expression, time.Time, time.Time) ([]DataObjLocation, [][]int64, [][]int, error)
-	ResolveDataObjWithShard(Expression, []Expression, ShardInfo, time.Time, time.Time) ([]DataObjLocation, [][]int64, [][]int, error)
+	// a list of sections for each data object,
+	// and the path of the index object for each data object.
+	// The list of index object paths is nil if index objects are not used,
+	// and an index object path is empty if the data object has no index object.
+	ResolveDataObj(Expression, time.Time, time.Time) ([]DataObjLocation, [][]int64, [][]int, []DataObjLocation, error)
+	ResolveDataObjWithShard(Expression, []Expression, ShardInfo, time.Time, time.Time) ([]DataObjLocation, [][]int64, [][]int, []DataObjLocation, error)
 }
 
 // MetastoreCatalog is the default implementation of [Catalog].
@@ -52,6 +55,10 @@ type MetastoreCatalog struct {
 	ctx           context.Context
 	metastore     metastore.Metastore
 	catalogueType CatalogueType
+
+	// statistics of the sections resolved from the metastore, keyed by the
+	// section.
+	statistics map[metastore.SectionKey]SectionStatistics
 }
 
 type CatalogueType int
@@ -73,13 +80,13 @@ func NewMetastoreCatalog(ctx context.Context, ms metastore.Metastore, catalogueT
 // ResolveDataObj resolves DataObj locations and streams IDs based on a given
 // [Expression]. The expression is required to be a (tree of) [BinaryExpression]
 // with a [ColumnExpression] on the left and a [LiteralExpression] on the right.
-func (c *MetastoreCatalog) ResolveDataObj(selector Expression, from, through time.Time) ([]DataObjLocation, [][]int64, [][]int, error) {
+func (c *MetastoreCatalog) ResolveDataObj(selector Expression, from, through time.Time) ([]DataObjLocation, [][]int64, [][]int, []DataObjLocation, error) {
 	return c.ResolveDataObjWithShard(selector, nil, noShard, from, through)
 }
 
-func (c *MetastoreCatalog) ResolveDataObjWithShard(selector Expression, predicates []Expression, shard ShardInfo, from, through time.Time) ([]DataObjLocation, [][]int64, [][]int, error) {
+func (c *MetastoreCatalog) ResolveDataObjWithShard(selector Expression, predicates []Expression, shard ShardInfo, from, through time.Time) ([]DataObjLocation, [][]int64, [][]int, []DataObjLocation, error) {
 	if c.metastore == nil {
-		return nil, nil, nil, errors.New("no metastore to resolve objects")
+		return nil, nil, nil, nil, errors.New("no metastore to resolve objects")
 	}
 
 	switch c.catalogueType {
@@ -88,25 +95,25 @@ func (c *MetastoreCatalog) ResolveDataObjWithShard(selector Expression, predicat
 	case CatalogueTypeIndex:
 		return c.resolveDataObjWithIndex(selector, predicates, shard, from, through)
 	default:
-		return nil, nil, nil, fmt.Errorf("invalid catalogue type: %d", c.catalogueType)
+		return nil, nil, nil, nil, fmt.Errorf("invalid catalogue type: %d", c.catalogueType)
 	}
 }
 
-func (c *MetastoreCatalog) resolveDataObj(selector Expression, shard ShardInfo, from, through time.Time) ([]DataObjLocation, [][]int64, [][]int, error) {
+func (c *MetastoreCatalog) resolveDataObj(selector Expression, shard ShardInfo, from, through time.Time) ([]DataObjLocation, [][]int64, [][]int, []DataObjLocation, error) {
 	matchers, err := expressionToMatchers(selector, false)
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("failed to convert selector expression into matchers: %w", err)
+		return nil, nil, nil, nil, fmt.Errorf("failed to convert selector expression into matchers: %w", err)
 	}
 
 	paths, streamIDs, numSections, err := c.metastore.StreamIDs(c.ctx, from, through, matchers...)
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("failed to resolve data object locations: %w", err)
+		return nil, nil, nil, nil, fmt.Errorf("failed to resolve data object locations: %w", err)
 	}
 
 	return filterForShard(shard, paths, streamIDs, numSections)
 }
 
-func filterForShard(shard ShardInfo, paths []string, streamIDs [][]int64, numSections []int) ([]DataObjLocation, [][]int64, [][]int, error) {
+func filterForShard(shard ShardInfo, paths []string, streamIDs [][]int64, numSections []int) ([]DataObjLocation, [][]int64, [][]int, []DataObjLocation, error) {
 	locations := make([]DataObjLocation, 0, len(paths))
 	streams := make([][]int64, 0, len(paths))
 	sections := make([][]int, 0, len(paths))
@@ -129,18 +136,19 @@ func filterForShard(shard ShardInfo, paths []string, streamIDs [][]int64, numSec
 		}
 	}
 
-	return locations, streams, sections, nil
+	// Data objects are resolved without index objects.
+	return locations, streams, sections, nil, nil
 }
 
 // resolveDataobjWithIndex expects the metastore to initially point to index objects, not the log objects directly.
-func (c *MetastoreCatalog) resolveDataObjWithIndex(selector Expression, predicates []Expression, shard ShardInfo, from, through time.Time) ([]DataObjLocation, [][]int64, [][]int, error) {
+func (c *MetastoreCatalog) resolveDataObjWithIndex(selector Expression, predicates []Expression, shard ShardInfo, from, through time.Time) ([]DataObjLocation, [][]int64, [][]int, []DataObjLocation, error) {
 	if c.metastore == nil {
-		return nil, nil, nil, errors.New("no metastore to resolve objects")
+		return nil, nil, nil, nil, errors.New("no metastore to resolve objects")
 	}
 
 	matchers, err := expressionToMatchers(selector, false)
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("failed to convert selector expression into matchers: %w", err)
+		return nil, nil, nil, nil, fmt.Errorf("failed to convert selector expression into matchers: %w", err)
 	}
 
 	predicateMatchers := make([]*labels.Matcher, 0, len(predicates))
@@ -155,21 +163,54 @@ func (c *MetastoreCatalog) resolveDataObjWithIndex(selector Expression, predicat
 
 	sectionDescriptors, err := c.metastore.Sections(c.ctx, from, through, matchers, predicateMatchers)
 	if err != nil {
-		return nil, nil, nil, fmt.Errorf("failed to resolve data object sections: %w", err)
+		return nil, nil, nil, nil, fmt.Errorf("failed to resolve data object sections: %w", err)
 	}
+	c.recordStatistics(sectionDescriptors)
 
 	return filterDescriptorsForShard(shard, sectionDescriptors)
 }
 
+// recordStatistics records the statistics of the resolved sections, which
+// are returned by [MetastoreCatalog.SectionStatistics].
+func (c *MetastoreCatalog) recordStatistics(sectionDescriptors []*metastore.DataobjSectionDescriptor) {
+	if c.statistics == nil {
+		c.statistics = make(map[metastore.SectionKey]SectionStatistics, len(sectionDescriptors))
+	}
+	for _, desc := range sectionDescriptors {
+		stats, ok := c.statistics[desc.SectionKey]
+		if !ok {
+			stats.Start, stats.End = desc.Start, desc.End
+		}
+		stats.Rows += int64(desc.RowCount)
+		stats.Bytes += desc.Size
+		stats.Streams += len(desc.StreamIDs)
+		if desc.Start.Before(stats.Start) {
+			stats.Start = desc.Start
+		}
+		if desc.End.After(stats.End) {
+			stats.End = desc.End
+		}
+		c.statistics[desc.SectionKey] = stats
+	}
+}
+
+// SectionStatistics implements [Statistics]. Statistics are only known for
+// sections resolved with the [CatalogueTypeIndex] catalogue.
+func (c *MetastoreCatalog) SectionStatistics(location DataObjLocation, section int) (SectionStatistics, bool) {
+	stats, ok := c.statistics[metastore.SectionKey{ObjectPath: string(location), SectionIdx: int64(section)}]
+	return stats, ok
+}
+
 // filterDescriptorsForShard filters the section descriptors for a given shard.
-// It returns the locations, streams, and sections for the shard.
+// It returns the locations, streams, sections, and index object locations for the shard.
 // TODO: Improve filtering: this method could be improved because it doesn't resolve the stream IDs to sections, even though this information is available. Instead, it resolves streamIDs to the whole object.
-func filterDescriptorsForShard(shard ShardInfo, sectionDescriptors []*metastore.DataobjSectionDescriptor) ([]DataObjLocation, [][]int64, [][]int, error) {
+func filterDescriptorsForShard(shard ShardInfo, sectionDescriptors []*metastore.DataobjSectionDescriptor) ([]DataObjLocation, [][]int64, [][]int, []DataObjLocation, error) {
 	index := make(map[DataObjLocation]int)
 
 	locations := make([]DataObjLocation, 0, len(sectionDescriptors))
 	streams := make([][]int64, 0, len(sectionDescriptors))
 	sections := make([][]int, 0, len(sectionDescriptors))
+	indexes := make([]DataObjLocation, 0, len(sectionDescriptors))
 
 	for _, desc := range sectionDescriptors {
 		location := DataObjLocation(desc.ObjectPath)
@@ -180,6 +221,7 @@ func filterDescriptorsForShard(shard ShardInfo, sectionDescriptors []*metastore.
 			locations = append(locations, location)
 			streams = append(streams, []int64{})
 			sections = append(sections, []int{})
+			indexes = append(indexes, DataObjLocation(desc.IndexPath))
 		}
 
 		if int(desc.SectionIdx)%int(shard.Of) == int(shard.Shard) {
@@ -195,7 +237,7 @@ func filterDescriptorsForShard(shard ShardInfo, sectionDescriptors []*metastore.
 		sections[i] = slices.Compact(sections[i])
 	}
 
-	return locations, streams, sections, nil
+	return locations, streams, sections, indexes, nil
 }
 
 // expressionToMatchers converts a selector expression to a list of matchers.
@@ -276,4 +318,7 @@ func convertBinaryOp(t types.BinaryOp) (labels.MatchType, error) {
 	return ty, nil
 }
 
-var _ Catalog = (*MetastoreCatalog)(nil)
+var (
+	_ Catalog    = (*MetastoreCatalog)(nil)
+	_ Statistics = (*MetastoreCatalog)(nil)
+)
@@ -7,6 +7,7 @@ import (
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/loki/v3/pkg/dataobj/metastore"
 	"github.com/grafana/loki/v3/pkg/engine/internal/datatype"
 	"github.com/grafana/loki/v3/pkg/engine/internal/types"
 )
@@ -166,3 +167,35 @@ func TestCatalog_ExpressionToMatchers(t *testing.T) {
 		})
 	}
 }
+
+func TestCatalog_SectionStatistics(t *testing.T) {
+	start := time.Unix(1000, 0)
+	section := func(path string, idx int64, streams []int64, rows int, start, end time.Time) *metastore.DataobjSectionDescriptor {
+		return &metastore.DataobjSectionDescriptor{
+			SectionKey: metastore.SectionKey{ObjectPath: path, SectionIdx: idx},
+			StreamIDs:  streams,
+			RowCount:   rows,
+			Size:       int64(rows) * 10,
+			Start:      start,
+			End:        end,
+		}
+	}
+
+	c := &MetastoreCatalog{}
+	c.recordStatistics([]*metastore.DataobjSectionDescriptor{
+		section("obj1", 0, []int64{1, 2}, 100, start, start.Add(time.Hour)),
+		section("obj1", 1, []int64{3}, 50, start.Add(time.Hour), start.Add(2*time.Hour)),
+		section("obj1", 0, []int64{4}, 10, start.Add(-time.Minute), start.Add(time.Minute)),
+	})
+
+	stats, ok := c.SectionStatistics("obj1", 0)
+	require.True(t, ok)
+	require.Equal(t, SectionStatistics{Rows: 110, Bytes: 1100, Start: start.Add(-time.Minute), End: start.Add(time.Hour), Streams: 3}, stats)
+
+	stats, ok = c.SectionStatistics("obj1", 1)
+	require.True(t, ok)
+	require.Equal(t, int64(50), stats.Rows)
+
+	_, ok = c.SectionStatistics("obj2", 0)
+	require.False(t, ok)
+}
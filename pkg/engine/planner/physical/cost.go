@@ -0,0 +1,363 @@
+package physical
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"github.com/grafana/loki/v3/pkg/engine/internal/types"
+)
+
+// SectionStatistics holds statistics about a section of a data object.
+type SectionStatistics struct {
+	Rows    int64     // Number of rows in the section.
+	Bytes   int64     // Uncompressed size of the section in bytes.
+	Start   time.Time // Timestamp of the oldest row in the section.
+	End     time.Time // Timestamp of the newest row in the section.
+	Streams int       // Number of streams in the section.
+}
+
+// Statistics provides statistics about the sections read by [DataObjScan]
+// nodes, which are used to estimate the cost of a plan. A [Catalog] which
+// implements Statistics enables the cost-based optimizations of the
+// [Planner].
+type Statistics interface {
+	// SectionStatistics returns the statistics of a section of the data
+	// object at location. It returns false if no statistics are known.
+	SectionStatistics(location DataObjLocation, section int) (SectionStatistics, bool)
+}
+
+// CostModel holds the parameters of the cost-based optimizations.
+type CostModel struct {
+	// BytesPerScan is the number of uncompressed bytes a single DataObjScan
+	// is expected to read. Scans of larger sections are split into multiple
+	// scans over disjoint sets of streams, which are read in parallel. Zero
+	// disables splitting scans.
+	BytesPerScan int64
+
+	// MaxScanParallelism is the maximum number of scans a single scan is split
+	// into.
+	MaxScanParallelism int
+
+	// PreAggregationRatio is the maximum ratio of the estimated number of rows
+	// produced by aggregating each scan on its own to the number of rows read
+	// by the scans, for which range aggregations are computed before their
+	// inputs are merged. Zero disables pre-aggregation.
+	PreAggregationRatio float64
+}
+
+// DefaultCostModel is the [CostModel] used by the [Planner].
+var DefaultCostModel = CostModel{
+	BytesPerScan:        512 << 20,
+	MaxScanParallelism:  8,
+	PreAggregationRatio: 0.1,
+}
+
+// costEstimator estimates the statistics of the rows read by scans.
+type costEstimator struct {
+	statistics Statistics
+	estimates  map[*DataObjScan]SectionStatistics // estimates of scans created by splitting a scan
+}
+
+func newCostEstimator(statistics Statistics) *costEstimator {
+	return &costEstimator{
+		statistics: statistics,
+		estimates:  make(map[*DataObjScan]SectionStatistics),
+	}
+}
+
+// scan returns the estimated statistics of the rows read by node. It returns
+// false if no statistics are known.
+func (e *costEstimator) scan(node *DataObjScan) (SectionStatistics, bool) {
+	if s, ok := e.estimates[node]; ok {
+		return s, true
+	}
+	return e.statistics.SectionStatistics(node.Location, node.Section)
+}
+
+// scans returns the estimated statistics of the children of node, which must
+// all be [DataObjScan] nodes. It returns false if any of the children isn't a
+// scan, is shared with other nodes, or has no statistics.
+func (e *costEstimator) scans(plan *Plan, node Node) ([]*DataObjScan, []SectionStatistics, bool) {
+	children := plan.Children(node)
+	scans := make([]*DataObjScan, 0, len(children))
+	stats := make([]SectionStatistics, 0, len(children))
+	for _, child := range children {
+		scan, ok := child.(*DataObjScan)
+		if !ok || len(plan.Parents(scan)) != 1 {
+			return nil, nil, false
+		}
+		s, ok := e.scan(scan)
+		if !ok {
+			return nil, nil, false
+		}
+		scans = append(scans, scan)
+		stats = append(stats, s)
+	}
+	return scans, stats, true
+}
+
+// scanParallelism is a rule that splits scans of large sections into
+// multiple scans which are read in parallel by their [SortMerge].
+type scanParallelism struct {
+	plan      *Plan
+	model     CostModel
+	estimator *costEstimator
+}
+
+// apply implements rule.
+func (r *scanParallelism) apply(node Node) bool {
+	merge, ok := node.(*SortMerge)
+	if !ok || r.model.BytesPerScan <= 0 {
+		return false
+	}
+
+	scans, stats, ok := r.estimator.scans(r.plan, merge)
+	if !ok {
+		return false
+	}
+
+	changed := false
+	for i, scan := range scans {
+		if _, split := r.estimator.estimates[scan]; split {
+			continue // scans are split at most once
+		}
+		n := r.parallelism(scan, stats[i])
+		if n <= 1 {
+			continue
+		}
+
+		// Scans are split by streams, so that each row is read by exactly one
+		// of them.
+		estimate := SectionStatistics{
+			Rows:    stats[i].Rows / int64(n),
+			Bytes:   stats[i].Bytes / int64(n),
+			Start:   stats[i].Start,
+			End:     stats[i].End,
+			Streams: (stats[i].Streams + n - 1) / n,
+		}
+		size := (len(scan.StreamIDs) + n - 1) / n
+		for streams := range slices.Chunk(scan.StreamIDs, size) {
+			split := &DataObjScan{
+				Location:    scan.Location,
+				Section:     scan.Section,
+				Index:       scan.Index,
+				StreamIDs:   slices.Clone(streams),
+				Projections: slices.Clone(scan.Projections),
+				Predicates:  slices.Clone(scan.Predicates),
+				Direction:   scan.Direction,
+				Limit:       scan.Limit,
+			}
+			r.plan.addNode(split)
+			_ = r.plan.addEdge(Edge{Parent: merge, Child: split})
+			r.estimator.estimates[split] = estimate
+		}
+		r.plan.eliminateNode(scan)
+		delete(r.estimator.estimates, scan)
+		changed = true
+	}
+	return changed
+}
+
+// parallelism returns the number of scans the scan of node is split into.
+func (r *scanParallelism) parallelism(node *DataObjScan, stats SectionStatistics) int {
+	n := int((stats.Bytes + r.model.BytesPerScan - 1) / r.model.BytesPerScan)
+	return min(n, max(r.model.MaxScanParallelism, 1), len(node.StreamIDs))
+}
+
+var _ rule = (*scanParallelism)(nil)
+
+// preAggregation is a rule that computes additive range aggregations on each
+// scan before the scans are merged, so that fewer rows need to be merged. The
+// partial results are summed up by a new [VectorAggregation] which replaces
+// the range aggregation.
+//
+// As the inputs of a [SortMerge] can be executed as fragments on other
+// queriers, the pre-aggregation is executed by the queriers reading the
+// scans.
+type preAggregation struct {
+	plan      *Plan
+	model     CostModel
+	estimator *costEstimator
+}
+
+// apply implements rule.
+func (r *preAggregation) apply(node Node) bool {
+	agg, ok := node.(*RangeAggregation)
+	if !ok || !r.canPreAggregate(agg) {
+		return false
+	}
+
+	merges := r.plan.Children(agg)
+	var rows, estimated int64
+	for _, merge := range merges {
+		_, stats, ok := r.estimator.scans(r.plan, merge)
+		if !ok {
+			return false
+		}
+		for _, s := range stats {
+			rows += s.Rows
+			estimated += min(s.Rows, int64(s.Streams)*int64(steps(agg)))
+		}
+	}
+	if rows == 0 || float64(estimated) > float64(rows)*r.model.PreAggregationRatio {
+		return false
+	}
+
+	return preAggregate(r.plan, agg)
+}
+
+// canPreAggregate returns true if the result of agg can be computed from
+// partial aggregations of the scans read by its inputs.
+func (r *preAggregation) canPreAggregate(agg *RangeAggregation) bool {
+	return r.model.PreAggregationRatio > 0 && canPreAggregate(r.plan, agg)
+}
+
+// canPreAggregate returns true if the result of agg can be computed from
+// partial aggregations of the inputs of its merges.
+func canPreAggregate(plan *Plan, agg *RangeAggregation) bool {
+	return isSummable(plan, agg) && areUnsharedMerges(plan, plan.Children(agg))
+}
+
+// isSummable returns true if the result of agg is equal to the sum of its
+// results over disjoint subsets of its input.
+func isSummable(plan *Plan, agg *RangeAggregation) bool {
+	// The partial results can only be summed up if the operation is additive
+	// and the partition labels are known up front. The range aggregation is
+	// replaced, so it must be consumed by another aggregation, which doesn't
+	// depend on the exact shape of its input.
+	if !agg.Operation.IsAdditive() || len(agg.PartitionBy) == 0 {
+		return false
+	}
+	parents := plan.Parents(agg)
+	if len(parents) != 1 {
+		return false
+	}
+	_, ok := parents[0].(*VectorAggregation)
+	return ok
+}
+
+// areUnsharedMerges returns true if all nodes are [SortMerge] nodes which are
+// not shared with other nodes.
+func areUnsharedMerges(plan *Plan, nodes []Node) bool {
+	for _, node := range nodes {
+		merge, ok := node.(*SortMerge)
+		if !ok || len(plan.Parents(merge)) != 1 {
+			return false
+		}
+	}
+	return true
+}
+
+// preAggregate replaces agg by partial range aggregations of each input of
+// its merges, whose results are summed up by a new [VectorAggregation].
+func preAggregate(plan *Plan, agg *RangeAggregation) bool {
+	merges := plan.Children(agg)
+
+	// The partial results are summed up by the partition labels, which is
+	// equal to the result of the range aggregation over all inputs.
+	sum := &VectorAggregation{
+		GroupBy:   slices.Clone(agg.PartitionBy),
+		Operation: types.VectorAggregationTypeSum,
+	}
+	for _, parent := range plan.Parents(agg) {
+		if err := plan.insertNode(parent, agg, sum); err != nil {
+			return false
+		}
+	}
+	for _, merge := range merges {
+		// The range aggregation consumes its whole input before it emits its
+		// results in ascending order of timestamps, so the partial results
+		// are merged in that order regardless of the order of the scans.
+		merge.(*SortMerge).Order = ASC
+		for _, input := range plan.Children(merge) {
+			partial := &RangeAggregation{
+				PartitionBy: slices.Clone(agg.PartitionBy),
+				Operation:   agg.Operation,
+				Unwrap:      agg.Unwrap,
+				Parameter:   agg.Parameter,
+				Start:       agg.Start,
+				End:         agg.End,
+				Step:        agg.Step,
+				Range:       agg.Range,
+			}
+			if err := plan.insertNode(merge, input, partial); err != nil {
+				return false
+			}
+		}
+	}
+	plan.eliminateNode(agg)
+	return true
+}
+
+// steps returns the number of steps at which agg is evaluated.
+func steps(agg *RangeAggregation) int {
+	if agg.Step <= 0 || !agg.End.After(agg.Start) {
+		return 1
+	}
+	return int(agg.End.Sub(agg.Start)/agg.Step) + 1
+}
+
+var _ rule = (*preAggregation)(nil)
+
+// scanOrder is a rule that orders the inputs of a [SortMerge] by the time
+// range of the sections they scan, so that the inputs holding the rows which
+// are merged first are started first.
+type scanOrder struct {
+	plan      *Plan
+	estimator *costEstimator
+}
+
+// apply implements rule.
+func (r *scanOrder) apply(node Node) bool {
+	merge, ok := node.(*SortMerge)
+	if !ok {
+		return false
+	}
+
+	children := r.plan.Children(merge)
+	if len(children) < 2 {
+		return false
+	}
+	stats := make(map[Node]SectionStatistics, len(children))
+	for _, child := range children {
+		scan := r.scanOf(child)
+		if scan == nil {
+			return false
+		}
+		s, ok := r.estimator.scan(scan)
+		if !ok {
+			return false
+		}
+		stats[child] = s
+	}
+
+	ordered := slices.Clone(children)
+	slices.SortStableFunc(ordered, func(a, b Node) int {
+		if merge.Order == DESC {
+			return cmp.Or(stats[b].End.Compare(stats[a].End), cmp.Compare(stats[b].Rows, stats[a].Rows))
+		}
+		return cmp.Or(stats[a].Start.Compare(stats[b].Start), cmp.Compare(stats[b].Rows, stats[a].Rows))
+	})
+	if slices.Equal(ordered, children) {
+		return false
+	}
+	return r.plan.orderChildren(merge, ordered) == nil
+}
+
+// scanOf returns the scan read by an input of a [SortMerge], which is either
+// the scan itself or a scan pre-aggregated by a [RangeAggregation]. It returns
+// nil for any other input.
+func (r *scanOrder) scanOf(node Node) *DataObjScan {
+	if agg, ok := node.(*RangeAggregation); ok {
+		children := r.plan.Children(agg)
+		if len(children) != 1 {
+			return nil
+		}
+		node = children[0]
+	}
+	scan, _ := node.(*DataObjScan)
+	return scan
+}
+
+var _ rule = (*scanOrder)(nil)
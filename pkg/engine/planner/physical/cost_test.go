@@ -0,0 +1,195 @@
+package physical
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/engine/internal/types"
+)
+
+// statisticsCatalog is a [Catalog] with synthetic statistics of sections.
+type statisticsCatalog struct {
+	catalog
+	sections map[DataObjLocation]SectionStatistics // statistics of section 0 of each object
+}
+
+// SectionStatistics implements Statistics.
+func (c *statisticsCatalog) SectionStatistics(location DataObjLocation, section int) (SectionStatistics, bool) {
+	if section != 0 {
+		return SectionStatistics{}, false
+	}
+	stats, ok := c.sections[location]
+	return stats, ok
+}
+
+var _ Statistics = (*statisticsCatalog)(nil)
+
+func TestCostBasedOptimizations(t *testing.T) {
+	start := time.Unix(1000, 0)
+
+	t.Run("without statistics", func(t *testing.T) {
+		plan := &Plan{}
+		merge := plan.addNode(&SortMerge{id: "merge"})
+		scan := plan.addNode(&DataObjScan{id: "scan", Location: "obj1", StreamIDs: []int64{1, 2, 3, 4}})
+		_ = plan.addEdge(Edge{Parent: merge, Child: scan})
+		original := PrintAsTree(plan)
+
+		planner := &Planner{catalog: &catalog{}, costModel: CostModel{BytesPerScan: 1, MaxScanParallelism: 4}}
+		_, err := planner.Optimize(plan)
+		require.NoError(t, err)
+		require.Equal(t, original, PrintAsTree(plan))
+	})
+
+	t.Run("scan parallelism", func(t *testing.T) {
+		plan := &Plan{}
+		limit := plan.addNode(&Limit{id: "limit", Fetch: 10})
+		merge := plan.addNode(&SortMerge{id: "merge"})
+		large := plan.addNode(&DataObjScan{id: "large", Location: "obj1", StreamIDs: []int64{1, 2, 3, 4, 5, 6}})
+		small := plan.addNode(&DataObjScan{id: "small", Location: "obj2", StreamIDs: []int64{7, 8}})
+		_ = plan.addEdge(Edge{Parent: limit, Child: merge})
+		_ = plan.addEdge(Edge{Parent: merge, Child: large})
+		_ = plan.addEdge(Edge{Parent: merge, Child: small})
+
+		planner := &Planner{
+			catalog: &statisticsCatalog{sections: map[DataObjLocation]SectionStatistics{
+				"obj1": {Rows: 1000, Bytes: 1000, Start: start, End: start, Streams: 6},
+				"obj2": {Rows: 100, Bytes: 100, Start: start, End: start, Streams: 2},
+			}},
+			costModel: CostModel{BytesPerScan: 250, MaxScanParallelism: 3},
+		}
+		_, err := planner.Optimize(plan)
+		require.NoError(t, err)
+
+		var streams [][]int64
+		for _, child := range plan.Children(merge) {
+			scan := child.(*DataObjScan)
+			require.Equal(t, uint32(10), scan.Limit)
+			streams = append(streams, scan.StreamIDs)
+		}
+		require.ElementsMatch(t, [][]int64{{1, 2}, {3, 4}, {5, 6}, {7, 8}}, streams)
+		require.Nil(t, plan.NodeByID("large"), "split scan is removed")
+	})
+
+	t.Run("pre-aggregation", func(t *testing.T) {
+		newPlan := func(op types.RangeAggregationType) (*Plan, *VectorAggregation) {
+			partitionBy := []ColumnExpression{newColumnExpr("app", types.ColumnTypeLabel)}
+
+			plan := &Plan{}
+			sum := plan.addNode(&VectorAggregation{id: "sum", GroupBy: partitionBy, Operation: types.VectorAggregationTypeSum})
+			agg := plan.addNode(&RangeAggregation{id: "agg", PartitionBy: partitionBy, Operation: op, Start: start, End: start.Add(4 * time.Minute), Step: time.Minute, Range: time.Minute})
+			merge := plan.addNode(&SortMerge{id: "merge", Order: ASC})
+			scan1 := plan.addNode(&DataObjScan{id: "scan1", Location: "obj1", StreamIDs: []int64{1, 2}})
+			scan2 := plan.addNode(&DataObjScan{id: "scan2", Location: "obj2", StreamIDs: []int64{3, 4}})
+			_ = plan.addEdge(Edge{Parent: sum, Child: agg})
+			_ = plan.addEdge(Edge{Parent: agg, Child: merge})
+			_ = plan.addEdge(Edge{Parent: merge, Child: scan1})
+			_ = plan.addEdge(Edge{Parent: merge, Child: scan2})
+			return plan, sum.(*VectorAggregation)
+		}
+		newPlanner := func(rows int64) *Planner {
+			// Each scan produces at most 2 streams * 5 steps rows once aggregated.
+			return &Planner{
+				catalog: &statisticsCatalog{sections: map[DataObjLocation]SectionStatistics{
+					"obj1": {Rows: rows, Bytes: rows, Start: start, End: start, Streams: 2},
+					"obj2": {Rows: rows, Bytes: rows, Start: start, End: start, Streams: 2},
+				}},
+				costModel: CostModel{PreAggregationRatio: 0.1},
+			}
+		}
+
+		t.Run("reduces rows", func(t *testing.T) {
+			plan, sum := newPlan(types.RangeAggregationTypeCount)
+			_, err := newPlanner(1000).Optimize(plan)
+			require.NoError(t, err)
+
+			require.Nil(t, plan.NodeByID("agg"), "range aggregation is replaced")
+
+			children := plan.Children(sum)
+			require.Len(t, children, 1)
+			merged, ok := children[0].(*VectorAggregation)
+			require.True(t, ok)
+			require.Equal(t, types.VectorAggregationTypeSum, merged.Operation)
+			require.Equal(t, "app", merged.GroupBy[0].(*ColumnExpr).Ref.Column)
+
+			merge := plan.NodeByID("merge")
+			require.Equal(t, []Node{merge}, plan.Children(merged))
+			for _, child := range plan.Children(merge) {
+				partial, ok := child.(*RangeAggregation)
+				require.True(t, ok)
+				require.Equal(t, types.RangeAggregationTypeCount, partial.Operation)
+				require.Len(t, plan.Children(partial), 1)
+			}
+
+			// Pre-aggregated scans are executed as fragments.
+			fragments, err := SplitFragments(plan)
+			require.NoError(t, err)
+			require.Len(t, fragments, 2)
+			for _, fragment := range fragments {
+				require.Equal(t, NodeTypeRangeAggreation, fragment.Root.Type())
+				_, err := MarshalPlan(fragment.Plan)
+				require.NoError(t, err)
+			}
+		})
+
+		t.Run("does not reduce rows", func(t *testing.T) {
+			plan, sum := newPlan(types.RangeAggregationTypeCount)
+			_, err := newPlanner(50).Optimize(plan)
+			require.NoError(t, err)
+
+			require.Equal(t, []Node{plan.NodeByID("agg")}, plan.Children(sum))
+			require.Equal(t, []Node{plan.NodeByID("scan1"), plan.NodeByID("scan2")}, plan.Children(plan.NodeByID("merge")))
+		})
+
+		t.Run("not additive", func(t *testing.T) {
+			plan, sum := newPlan(types.RangeAggregationTypeMax)
+			_, err := newPlanner(1000).Optimize(plan)
+			require.NoError(t, err)
+
+			require.Equal(t, []Node{plan.NodeByID("agg")}, plan.Children(sum))
+			require.Equal(t, []Node{plan.NodeByID("scan1"), plan.NodeByID("scan2")}, plan.Children(plan.NodeByID("merge")))
+		})
+	})
+
+	t.Run("scan order", func(t *testing.T) {
+		for _, tc := range []struct {
+			order    SortOrder
+			expected []string
+		}{
+			{order: ASC, expected: []string{"scan3", "scan1", "scan2"}},
+			{order: DESC, expected: []string{"scan2", "scan1", "scan3"}},
+		} {
+			t.Run(tc.order.String(), func(t *testing.T) {
+				plan := &Plan{}
+				merge := plan.addNode(&SortMerge{id: "merge", Order: tc.order})
+				for i, location := range []DataObjLocation{"obj1", "obj2", "obj3"} {
+					scan := plan.addNode(&DataObjScan{id: "scan" + string('1'+rune(i)), Location: location})
+					_ = plan.addEdge(Edge{Parent: merge, Child: scan})
+				}
+
+				planner := &Planner{catalog: &statisticsCatalog{sections: map[DataObjLocation]SectionStatistics{
+					"obj1": {Start: start.Add(time.Hour), End: start.Add(2 * time.Hour)},
+					"obj2": {Start: start.Add(2 * time.Hour), End: start.Add(3 * time.Hour)},
+					"obj3": {Start: start, End: start.Add(time.Hour)},
+				}}}
+				_, err := planner.Optimize(plan)
+				require.NoError(t, err)
+
+				var ids []string
+				for _, child := range plan.Children(merge) {
+					ids = append(ids, child.ID())
+				}
+				require.Equal(t, tc.expected, ids)
+
+				// The order is reset once the children change.
+				_ = plan.addEdge(Edge{Parent: merge, Child: plan.addNode(&DataObjScan{id: "scan0"})})
+				ids = ids[:0]
+				for _, child := range plan.Children(merge) {
+					ids = append(ids, child.ID())
+				}
+				require.Equal(t, []string{"scan0", "scan1", "scan2", "scan3"}, ids)
+			})
+		}
+	})
+}
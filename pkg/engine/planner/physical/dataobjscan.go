@@ -18,6 +18,9 @@ type DataObjScan struct {
 	Location DataObjLocation
 	// Section is the section index inside the data object to scan.
 	Section int
+	// Index is the location of the index object holding a token index of the
+	// section. It is empty if the section has no token index.
+	Index DataObjLocation
 	// StreamIDs is a set of stream IDs inside the data object. These IDs are
 	// only unique in the context of a single data object.
 	StreamIDs []int64
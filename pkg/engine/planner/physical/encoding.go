@@ -0,0 +1,128 @@
+package physical
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+
+	"github.com/grafana/loki/v3/pkg/engine/internal/datatype"
+)
+
+func init() {
+	// Nodes and expressions are encoded as interface values, so their
+	// concrete types need to be registered. Only the nodes that can be part
+	// of a [Fragment] are supported.
+	gob.Register(&DataObjScan{})
+	gob.Register(&SortMerge{})
+	gob.Register(&Filter{})
+	gob.Register(&Projection{})
+	gob.Register(&Limit{})
+	gob.Register(&Parse{})
+	gob.Register(&RangeAggregation{})
+
+	gob.Register(&BinaryExpr{})
+	gob.Register(&UnaryExpr{})
+	gob.Register(&ColumnExpr{})
+	gob.Register(&LiteralExpr{})
+
+	gob.Register(datatype.BoolLiteral(false))
+	gob.Register(datatype.StringLiteral(""))
+	gob.Register(datatype.IntegerLiteral(0))
+	gob.Register(datatype.FloatLiteral(0))
+	gob.Register(datatype.TimestampLiteral(0))
+	gob.Register(datatype.DurationLiteral(0))
+	gob.Register(datatype.BytesLiteral(0))
+}
+
+// encodedPlan is the serialized form of a [Plan]. Edges reference nodes by
+// their index in Nodes.
+type encodedPlan struct {
+	IDs   []string
+	Nodes []Node
+	Edges []encodedEdge
+}
+
+type encodedEdge struct {
+	Parent, Child int
+}
+
+// MarshalPlan encodes the plan into a binary form, so it can be sent to other
+// processes.
+func MarshalPlan(p *Plan) ([]byte, error) {
+	var (
+		enc     encodedPlan
+		indexes = make(map[Node]int, len(p.nodes))
+	)
+
+	nodes := p.nodes.sorted()
+	for i, node := range nodes {
+		switch node.Type() {
+		case NodeTypeDataObjScan, NodeTypeSortMerge, NodeTypeFilter, NodeTypeProjection, NodeTypeLimit, NodeTypeParse, NodeTypeRangeAggreation:
+		default:
+			return nil, fmt.Errorf("cannot encode node type %s", node.Type())
+		}
+		indexes[node] = i
+		enc.IDs = append(enc.IDs, node.ID())
+		enc.Nodes = append(enc.Nodes, node)
+	}
+	for _, node := range nodes {
+		for _, child := range p.Children(node) {
+			enc.Edges = append(enc.Edges, encodedEdge{Parent: indexes[node], Child: indexes[child]})
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(enc); err != nil {
+		return nil, fmt.Errorf("encoding plan: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalPlan decodes a plan that was encoded with [MarshalPlan].
+func UnmarshalPlan(data []byte) (*Plan, error) {
+	var enc encodedPlan
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&enc); err != nil {
+		return nil, fmt.Errorf("decoding plan: %w", err)
+	}
+
+	if len(enc.IDs) != len(enc.Nodes) {
+		return nil, fmt.Errorf("invalid plan: %d node IDs for %d nodes", len(enc.IDs), len(enc.Nodes))
+	}
+
+	p := &Plan{}
+	p.init()
+	for i, node := range enc.Nodes {
+		setNodeID(node, enc.IDs[i])
+		p.addNode(node)
+	}
+	for _, edge := range enc.Edges {
+		if edge.Parent < 0 || edge.Parent >= len(enc.Nodes) || edge.Child < 0 || edge.Child >= len(enc.Nodes) {
+			return nil, fmt.Errorf("invalid edge %d -> %d", edge.Parent, edge.Child)
+		}
+		if err := p.addEdge(Edge{Parent: enc.Nodes[edge.Parent], Child: enc.Nodes[edge.Child]}); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// setNodeID restores the ID of a decoded node, since unexported fields are not
+// encoded.
+func setNodeID(node Node, id string) {
+	switch n := node.(type) {
+	case *DataObjScan:
+		n.id = id
+	case *SortMerge:
+		n.id = id
+	case *Filter:
+		n.id = id
+	case *Projection:
+		n.id = id
+	case *Limit:
+		n.id = id
+	case *Parse:
+		n.id = id
+	case *RangeAggregation:
+		n.id = id
+	}
+}
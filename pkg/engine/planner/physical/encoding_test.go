@@ -0,0 +1,87 @@
+package physical
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/engine/internal/datatype"
+	"github.com/grafana/loki/v3/pkg/engine/internal/types"
+)
+
+func TestMarshalPlan(t *testing.T) {
+	p := &Plan{}
+	filter := p.addNode(&Filter{
+		id: "filter",
+		Predicates: []Expression{
+			&UnaryExpr{
+				Left: &BinaryExpr{
+					Left:  newColumnExpr("level", types.ColumnTypeMetadata),
+					Right: NewLiteral("debug"),
+					Op:    types.BinaryOpEq,
+				},
+				Op: types.UnaryOpNot,
+			},
+		},
+	})
+	scan := p.addNode(&DataObjScan{
+		id:        "scan",
+		Location:  "objects/00/0000",
+		Section:   1,
+		StreamIDs: []int64{1, 2, 3},
+		Projections: []ColumnExpression{
+			newColumnExpr("timestamp", types.ColumnTypeBuiltin),
+			newColumnExpr("message", types.ColumnTypeBuiltin),
+		},
+		Predicates: []Expression{
+			&BinaryExpr{
+				Left:  newColumnExpr("timestamp", types.ColumnTypeBuiltin),
+				Right: NewLiteral(datatype.Timestamp(3600000)),
+				Op:    types.BinaryOpGte,
+			},
+		},
+		Direction: DESC,
+		Limit:     1000,
+	})
+	merge := p.addNode(&SortMerge{
+		id:     "merge",
+		Column: newColumnExpr("timestamp", types.ColumnTypeBuiltin),
+		Order:  DESC,
+	})
+	_ = p.addEdge(Edge{Parent: filter, Child: merge})
+	_ = p.addEdge(Edge{Parent: merge, Child: scan})
+
+	data, err := MarshalPlan(p)
+	require.NoError(t, err)
+
+	actual, err := UnmarshalPlan(data)
+	require.NoError(t, err)
+	require.Equal(t, PrintAsTree(p), PrintAsTree(actual))
+
+	root, err := actual.Root()
+	require.NoError(t, err)
+	require.Equal(t, filter.(*Filter).Predicates, root.(*Filter).Predicates)
+
+	children := actual.Children(root)
+	require.Len(t, children, 1)
+	decodedMerge := children[0].(*SortMerge)
+	require.Equal(t, merge.(*SortMerge).Column, decodedMerge.Column)
+	require.Equal(t, DESC, decodedMerge.Order)
+
+	children = actual.Children(decodedMerge)
+	require.Len(t, children, 1)
+	decodedScan := children[0].(*DataObjScan)
+	expectedScan := scan.(*DataObjScan)
+	require.Equal(t, expectedScan.Location, decodedScan.Location)
+	require.Equal(t, expectedScan.StreamIDs, decodedScan.StreamIDs)
+	require.Equal(t, expectedScan.Projections, decodedScan.Projections)
+	require.Equal(t, expectedScan.Predicates, decodedScan.Predicates)
+}
+
+func TestMarshalPlan_UnsupportedNode(t *testing.T) {
+	p := &Plan{}
+	p.addNode(&VectorAggregation{id: "agg"})
+
+	_, err := MarshalPlan(p)
+	require.ErrorContains(t, err, "cannot encode node type VectorAggregation")
+}
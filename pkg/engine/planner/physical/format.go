@@ -0,0 +1,67 @@
+package physical
+
+import (
+	"fmt"
+
+	"github.com/grafana/loki/v3/pkg/logql/log"
+)
+
+// LineFormat represents a physical plan node that rewrites the log line of
+// each row using a Go text template.
+type LineFormat struct {
+	id string
+
+	// Template is the template that is executed for each row.
+	Template string
+}
+
+// ID implements the [Node] interface.
+// Returns a string that uniquely identifies the node in the plan.
+func (f *LineFormat) ID() string {
+	if f.id == "" {
+		return fmt.Sprintf("%p", f)
+	}
+	return f.id
+}
+
+// Type implements the [Node] interface.
+// Returns the type of the node.
+func (*LineFormat) Type() NodeType {
+	return NodeTypeLineFormat
+}
+
+// Accept implements the [Node] interface.
+// Dispatches itself to the provided [Visitor] v
+func (f *LineFormat) Accept(v Visitor) error {
+	return v.VisitLineFormat(f)
+}
+
+// LabelFormat represents a physical plan node that renames labels or sets
+// labels to the result of a Go text template for each row.
+type LabelFormat struct {
+	id string
+
+	// Formats is the list of label renames and templates, applied in order.
+	Formats []log.LabelFmt
+}
+
+// ID implements the [Node] interface.
+// Returns a string that uniquely identifies the node in the plan.
+func (f *LabelFormat) ID() string {
+	if f.id == "" {
+		return fmt.Sprintf("%p", f)
+	}
+	return f.id
+}
+
+// Type implements the [Node] interface.
+// Returns the type of the node.
+func (*LabelFormat) Type() NodeType {
+	return NodeTypeLabelFormat
+}
+
+// Accept implements the [Node] interface.
+// Dispatches itself to the provided [Visitor] v
+func (f *LabelFormat) Accept(v Visitor) error {
+	return v.VisitLabelFormat(f)
+}
@@ -0,0 +1,232 @@
+package physical
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Fragment is a subtree of a [Plan] which can be executed independently of
+// the rest of the plan, for example by a different querier. The results of a
+// fragment are merged by the parent node of its root.
+type Fragment struct {
+	// Parent is the node in the original plan that merges the results of the
+	// fragment.
+	Parent Node
+	// Root is the root node of the fragment in the original plan.
+	Root Node
+	// Plan contains Root and all its descendants.
+	Plan *Plan
+}
+
+// SplitFragments splits the plan into fragments that can be executed
+// independently of each other.
+//
+// Each input of a [SortMerge] node becomes a fragment if all nodes of its
+// subtree operate on the rows of a single data object, such as a
+// [DataObjScan] with an optional [Filter] or [Projection] on top, a
+// [SortMerge] of the sections of the data object, or a [RangeAggregation]
+// pre-aggregating them. Nodes that
+// are not part of any fragment need to be executed by the process which
+// merges the results of the fragments.
+//
+// Range aggregations are only part of fragments if the plan was optimized by
+// a [Planner] with fragments enabled, see [Planner.EnableFragments].
+func SplitFragments(p *Plan) ([]Fragment, error) {
+	root, err := p.Root()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		fragments []Fragment
+		visited   = make(nodeSet)
+	)
+
+	var walk func(node Node) error
+	walk = func(node Node) error {
+		if visited.contains(node) {
+			return nil
+		}
+		visited.add(node)
+
+		for _, child := range p.Children(node) {
+			if node.Type() == NodeTypeSortMerge && p.isFragmentRoot(child) {
+				sub, err := p.Subplan(child)
+				if err != nil {
+					return err
+				}
+				fragments = append(fragments, Fragment{Parent: node, Root: child, Plan: sub})
+				continue
+			}
+			if err := walk(child); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := walk(root); err != nil {
+		return nil, err
+	}
+	return fragments, nil
+}
+
+// isFragmentRoot returns true if the subtree of node can be executed on its
+// own. This is the case if the subtree reads from a single data object and
+// none of its nodes are shared with other parts of the plan.
+func (p *Plan) isFragmentRoot(node Node) bool {
+	_, ok := p.fragmentLocation(node)
+	return ok
+}
+
+// fragmentLocation returns the location of the single data object read by
+// the subtree of node. It returns false if the subtree reads from more than
+// one data object or can't be executed on its own.
+func (p *Plan) fragmentLocation(node Node) (DataObjLocation, bool) {
+	if len(p.Parents(node)) != 1 {
+		return "", false
+	}
+
+	children := p.Children(node)
+	switch node := node.(type) {
+	case *DataObjScan:
+		return node.Location, len(children) == 0
+	case *Filter, *Projection, *Limit, *Parse, *RangeAggregation:
+		if len(children) != 1 {
+			return "", false
+		}
+		return p.fragmentLocation(children[0])
+	case *SortMerge:
+		// Merges of the sections of a single data object are part of the
+		// fragment.
+		var location DataObjLocation
+		for i, child := range children {
+			childLocation, ok := p.fragmentLocation(child)
+			if !ok || (i > 0 && childLocation != location) {
+				return "", false
+			}
+			location = childLocation
+		}
+		return location, len(children) > 0
+	default:
+		return "", false
+	}
+}
+
+// Subplan returns a new plan that contains the given node and all its
+// descendants. The nodes are shared between both plans.
+func (p *Plan) Subplan(root Node) (*Plan, error) {
+	if !p.nodes.contains(root) {
+		return nil, fmt.Errorf("node %s does not exist in graph", root.ID())
+	}
+
+	sub := &Plan{}
+	sub.addNode(root)
+
+	var walk func(node Node) error
+	walk = func(node Node) error {
+		for _, child := range p.Children(node) {
+			sub.addNode(child)
+			if err := sub.addEdge(Edge{Parent: node, Child: child}); err != nil {
+				return err
+			}
+			if err := walk(child); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := walk(root); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// fragmentAggregation is a rule that computes additive range aggregations on
+// each input of their merges before the inputs are merged, like
+// [preAggregation] but regardless of the cost. It is applied to plans whose
+// fragments are executed on other queriers, so that the fragments return
+// partial aggregates instead of all rows they read.
+//
+// [Filter] and [Parse] nodes between the range aggregation and its merges
+// are copied into each input of the merges first, as they process each row
+// on its own.
+type fragmentAggregation struct {
+	plan *Plan
+}
+
+// apply implements rule.
+func (r *fragmentAggregation) apply(node Node) bool {
+	agg, ok := node.(*RangeAggregation)
+	if !ok || !isSummable(r.plan, agg) {
+		return false
+	}
+
+	// Collect the row operators between the range aggregation and the
+	// merges, from top to bottom.
+	var (
+		operators []Node
+		bottom    Node = agg
+	)
+	for {
+		children := r.plan.Children(bottom)
+		if len(children) != 1 || !isRowOperator(children[0]) || len(r.plan.Parents(children[0])) != 1 {
+			break
+		}
+		bottom = children[0]
+		operators = append(operators, bottom)
+	}
+	if !areUnsharedMerges(r.plan, r.plan.Children(bottom)) {
+		return false
+	}
+
+	for _, merge := range r.plan.Children(bottom) {
+		for _, input := range r.plan.Children(merge) {
+			parent := merge
+			for _, operator := range operators {
+				clone := cloneRowOperator(operator)
+				if err := r.plan.insertNode(parent, input, clone); err != nil {
+					return false
+				}
+				parent = clone
+			}
+		}
+	}
+	for _, operator := range operators {
+		r.plan.eliminateNode(operator)
+	}
+	return preAggregate(r.plan, agg)
+}
+
+// isRowOperator returns true if node processes each row independently of the
+// other rows, so that it can be applied before or after merging its input.
+func isRowOperator(node Node) bool {
+	switch node.Type() {
+	case NodeTypeFilter, NodeTypeParse:
+		return true
+	default:
+		return false
+	}
+}
+
+// cloneRowOperator returns a copy of a node for which [isRowOperator] returns
+// true.
+func cloneRowOperator(node Node) Node {
+	switch n := node.(type) {
+	case *Filter:
+		return &Filter{Predicates: slices.Clone(n.Predicates)}
+	case *Parse:
+		return &Parse{
+			Kind:        n.Kind,
+			Expression:  n.Expression,
+			Strict:      n.Strict,
+			KeepEmpty:   n.KeepEmpty,
+			Extractions: slices.Clone(n.Extractions),
+		}
+	default:
+		panic(fmt.Sprintf("cannot clone node type %s", node.Type()))
+	}
+}
+
+var _ rule = (*fragmentAggregation)(nil)
@@ -0,0 +1,143 @@
+package physical
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/engine/internal/types"
+)
+
+func TestSplitFragments(t *testing.T) {
+	p := &Plan{}
+	limit := p.addNode(&Limit{id: "limit", Fetch: 100})
+	merge := p.addNode(&SortMerge{id: "merge"})
+	filter := p.addNode(&Filter{id: "filter"})
+	scan1 := p.addNode(&DataObjScan{id: "scan1", Location: "obj1"})
+	scan2 := p.addNode(&DataObjScan{id: "scan2", Location: "obj2"})
+	_ = p.addEdge(Edge{Parent: limit, Child: merge})
+	_ = p.addEdge(Edge{Parent: merge, Child: filter})
+	_ = p.addEdge(Edge{Parent: merge, Child: scan2})
+	_ = p.addEdge(Edge{Parent: filter, Child: scan1})
+
+	fragments, err := SplitFragments(p)
+	require.NoError(t, err)
+	require.Len(t, fragments, 2)
+
+	require.Equal(t, merge, fragments[0].Parent)
+	require.Equal(t, filter, fragments[0].Root)
+	require.Equal(t, 2, fragments[0].Plan.Len())
+	require.Equal(t, []Node{scan1}, fragments[0].Plan.Children(filter))
+
+	require.Equal(t, merge, fragments[1].Parent)
+	require.Equal(t, scan2, fragments[1].Root)
+	require.Equal(t, 1, fragments[1].Plan.Len())
+
+	// The original plan is not modified.
+	require.Equal(t, 5, p.Len())
+}
+
+func TestSplitFragments_NotFragmentable(t *testing.T) {
+	t.Run("scans without merge node", func(t *testing.T) {
+		p := &Plan{}
+		limit := p.addNode(&Limit{id: "limit"})
+		scan := p.addNode(&DataObjScan{id: "scan"})
+		_ = p.addEdge(Edge{Parent: limit, Child: scan})
+
+		fragments, err := SplitFragments(p)
+		require.NoError(t, err)
+		require.Empty(t, fragments)
+	})
+
+	t.Run("merge of vector aggregations", func(t *testing.T) {
+		p := &Plan{}
+		merge := p.addNode(&SortMerge{id: "merge"})
+		agg := p.addNode(&VectorAggregation{id: "agg"})
+		scan := p.addNode(&DataObjScan{id: "scan"})
+		_ = p.addEdge(Edge{Parent: merge, Child: agg})
+		_ = p.addEdge(Edge{Parent: agg, Child: scan})
+
+		fragments, err := SplitFragments(p)
+		require.NoError(t, err)
+		require.Empty(t, fragments)
+	})
+}
+
+func TestFragmentAggregation(t *testing.T) {
+	start := time.Unix(1000, 0)
+
+	newPlan := func(op types.RangeAggregationType) *Plan {
+		partitionBy := []ColumnExpression{newColumnExpr("level", types.ColumnTypeAmbiguous)}
+
+		p := &Plan{}
+		sum := p.addNode(&VectorAggregation{id: "sum", GroupBy: partitionBy, Operation: types.VectorAggregationTypeSum})
+		agg := p.addNode(&RangeAggregation{id: "agg", PartitionBy: partitionBy, Operation: op, Start: start, End: start.Add(4 * time.Minute), Step: time.Minute, Range: time.Minute})
+		filter := p.addNode(&Filter{id: "filter", Predicates: []Expression{&BinaryExpr{
+			Left:  newColumnExpr("level", types.ColumnTypeParsed),
+			Right: NewLiteral("error"),
+			Op:    types.BinaryOpEq,
+		}}})
+		parse := p.addNode(&Parse{id: "parse", Kind: types.ParserTypeLogfmt})
+		merge1 := p.addNode(&SortMerge{id: "merge1", Order: ASC})
+		merge2 := p.addNode(&SortMerge{id: "merge2", Order: DESC})
+		scan1 := p.addNode(&DataObjScan{id: "scan1", Location: "obj1", Section: 0})
+		scan2 := p.addNode(&DataObjScan{id: "scan2", Location: "obj1", Section: 1})
+		scan3 := p.addNode(&DataObjScan{id: "scan3", Location: "obj2"})
+		_ = p.addEdge(Edge{Parent: sum, Child: agg})
+		_ = p.addEdge(Edge{Parent: agg, Child: filter})
+		_ = p.addEdge(Edge{Parent: filter, Child: parse})
+		_ = p.addEdge(Edge{Parent: parse, Child: merge1})
+		_ = p.addEdge(Edge{Parent: parse, Child: merge2})
+		_ = p.addEdge(Edge{Parent: merge1, Child: scan1})
+		_ = p.addEdge(Edge{Parent: merge1, Child: scan2})
+		_ = p.addEdge(Edge{Parent: merge2, Child: scan3})
+		return p
+	}
+
+	t.Run("splits additive aggregations", func(t *testing.T) {
+		p := newPlan(types.RangeAggregationTypeCount)
+		planner := &Planner{catalog: &catalog{}}
+		planner.EnableFragments()
+		_, err := planner.Optimize(p)
+		require.NoError(t, err)
+
+		require.Nil(t, p.NodeByID("agg"), "range aggregation is replaced")
+		require.Nil(t, p.NodeByID("filter"), "filter is copied into the inputs of the merges")
+		require.Nil(t, p.NodeByID("parse"), "parse is copied into the inputs of the merges")
+
+		merged, ok := p.Children(p.NodeByID("sum"))[0].(*VectorAggregation)
+		require.True(t, ok)
+		require.Equal(t, types.VectorAggregationTypeSum, merged.Operation)
+		require.ElementsMatch(t, []Node{p.NodeByID("merge1"), p.NodeByID("merge2")}, p.Children(merged))
+		require.Equal(t, ASC, p.NodeByID("merge2").(*SortMerge).Order, "partial aggregations are merged in ascending order")
+
+		fragments, err := SplitFragments(p)
+		require.NoError(t, err)
+		require.Len(t, fragments, 3)
+		for _, fragment := range fragments {
+			require.Equal(t, NodeTypeRangeAggreation, fragment.Root.Type())
+			require.Equal(t, 4, fragment.Plan.Len(), "fragment contains the partial aggregation, filter, parse and scan")
+			_, err := MarshalPlan(fragment.Plan)
+			require.NoError(t, err)
+		}
+	})
+
+	t.Run("keeps other aggregations", func(t *testing.T) {
+		p := newPlan(types.RangeAggregationTypeMax)
+		original := PrintAsTree(p)
+
+		planner := &Planner{catalog: &catalog{}}
+		planner.EnableFragments()
+		_, err := planner.Optimize(p)
+		require.NoError(t, err)
+		require.Equal(t, original, PrintAsTree(p))
+
+		fragments, err := SplitFragments(p)
+		require.NoError(t, err)
+		require.Len(t, fragments, 3)
+		for _, fragment := range fragments {
+			require.Equal(t, NodeTypeDataObjScan, fragment.Root.Type())
+		}
+	})
+}
@@ -63,6 +63,10 @@ func (r *predicatePushdown) applyPredicatePushdown(node Node, predicate Expressi
 			return true
 		}
 		return false
+	case *Parse, *LineFormat, *LabelFormat, *ProjectLabels:
+		// Parsers and formatters can modify the log line and labels, so
+		// predicates evaluated after them cannot be evaluated before them.
+		return false
 	}
 	for _, child := range r.plan.Children(node) {
 		if ok := r.applyPredicatePushdown(child, predicate); !ok {
@@ -75,7 +79,12 @@ func (r *predicatePushdown) applyPredicatePushdown(node Node, predicate Expressi
 func canApplyPredicate(predicate Expression) bool {
 	switch pred := predicate.(type) {
 	case *BinaryExpr:
+		if isNumericMetadataComparison(pred) {
+			return false
+		}
 		return canApplyPredicate(pred.Left) && canApplyPredicate(pred.Right)
+	case *UnaryExpr:
+		return pred.Op == types.UnaryOpNot && canApplyPredicate(pred.Left)
 	case *ColumnExpr:
 		return pred.Ref.Type == types.ColumnTypeBuiltin || pred.Ref.Type == types.ColumnTypeMetadata
 	case *LiteralExpr:
@@ -85,6 +94,21 @@ func canApplyPredicate(predicate Expression) bool {
 	}
 }
 
+// isNumericMetadataComparison returns true if the expression compares the
+// values of a metadata column numerically. Rows with values which cannot be
+// parsed as a number are kept and marked with an error label by label
+// filters, which cannot be done while scanning, so these comparisons must be
+// evaluated by the [Filter].
+func isNumericMetadataComparison(expr *BinaryExpr) bool {
+	switch expr.Op {
+	case types.BinaryOpGt, types.BinaryOpGte, types.BinaryOpLt, types.BinaryOpLte:
+	default:
+		return false
+	}
+	col, ok := expr.Left.(*ColumnExpr)
+	return ok && col.Ref.Type == types.ColumnTypeMetadata
+}
+
 var _ rule = (*predicatePushdown)(nil)
 
 // limitPushdown is a rule that moves down the limit to the scan nodes.
@@ -107,6 +131,10 @@ func (r *limitPushdown) applyLimitPushdown(node Node, limit uint32) bool {
 		// In case the scan node is reachable from multiple different limit nodes, we need to take the largest limit.
 		node.Limit = max(node.Limit, limit)
 		return true
+	case *Filter:
+		// The limit cannot be applied to the scan if rows are filtered out
+		// after the scan, otherwise fewer rows than requested are returned.
+		return false
 	}
 	for _, child := range r.plan.Children(node) {
 		if ok := r.applyLimitPushdown(child, limit); !ok {
@@ -127,11 +155,18 @@ type groupByPushdown struct {
 func (r *groupByPushdown) apply(node Node) bool {
 	switch node := node.(type) {
 	case *VectorAggregation:
-		if node.Operation != types.VectorAggregationTypeSum {
+		// The grouping labels can only be pushed down if they are known up front.
+		if node.Operation != types.VectorAggregationTypeSum || node.Without {
 			return false
 		}
 
-		return r.applyGroupByPushdown(node, node.GroupBy)
+		anyChanged := false
+		for _, child := range r.plan.Children(node) {
+			if changed := r.applyGroupByPushdown(child, node.GroupBy); changed {
+				anyChanged = true
+			}
+		}
+		return anyChanged
 	}
 
 	return false
@@ -140,7 +175,9 @@ func (r *groupByPushdown) apply(node Node) bool {
 func (r *groupByPushdown) applyGroupByPushdown(node Node, groupBy []ColumnExpression) bool {
 	switch node := node.(type) {
 	case *RangeAggregation:
-		if node.Operation != types.RangeAggregationTypeCount {
+		// Partitioning by the grouping labels only yields the same result if the
+		// operation can be summed up across streams.
+		if !node.Operation.IsAdditive() {
 			return false
 		}
 
@@ -166,6 +203,9 @@ func (r *groupByPushdown) applyGroupByPushdown(node Node, groupBy []ColumnExpres
 			}
 		}
 		return changed
+	case *VectorAggregation:
+		// Nested vector aggregations push down their own grouping labels.
+		return false
 	}
 
 	anyChanged := false
@@ -180,7 +220,8 @@ func (r *groupByPushdown) applyGroupByPushdown(node Node, groupBy []ColumnExpres
 var _ rule = (*groupByPushdown)(nil)
 
 // projectionPushdown is a rule that pushes down column projections.
-// Currently it only projects partition labels from range aggregations to scan nodes.
+// Currently it only projects partition labels and columns required to compute
+// the aggregated value from range aggregations to scan nodes.
 type projectionPushdown struct {
 	plan *Plan
 }
@@ -189,14 +230,22 @@ type projectionPushdown struct {
 func (r *projectionPushdown) apply(node Node) bool {
 	switch node := node.(type) {
 	case *RangeAggregation:
-		if len(node.PartitionBy) == 0 || node.Operation != types.RangeAggregationTypeCount {
+		if len(node.PartitionBy) == 0 {
 			return false
 		}
 
-		projections := make([]ColumnExpression, len(node.PartitionBy)+1)
+		projections := make([]ColumnExpression, len(node.PartitionBy), len(node.PartitionBy)+2)
 		copy(projections, node.PartitionBy)
 		// Always project timestamp column
-		projections[len(node.PartitionBy)] = &ColumnExpr{Ref: types.ColumnRef{Column: types.ColumnNameBuiltinTimestamp, Type: types.ColumnTypeBuiltin}}
+		projections = append(projections, &ColumnExpr{Ref: types.ColumnRef{Column: types.ColumnNameBuiltinTimestamp, Type: types.ColumnTypeBuiltin}})
+
+		switch node.Operation {
+		case types.RangeAggregationTypeBytes, types.RangeAggregationTypeBytesRate:
+			projections = append(projections, &ColumnExpr{Ref: types.ColumnRef{Column: types.ColumnNameBuiltinMessage, Type: types.ColumnTypeBuiltin}})
+		}
+		if node.Unwrap != nil {
+			projections = append(projections, columnsOf(node.Unwrap)...)
+		}
 
 		return r.applyProjectionPushdown(node, projections)
 	}
@@ -231,6 +280,10 @@ func (r *projectionPushdown) applyProjectionPushdown(node Node, projections []Co
 			}
 		}
 		return changed
+	case *Parse, *LineFormat, *LabelFormat, *ProjectLabels:
+		// Parsers and formatters require the log line and all labels, and the
+		// projected columns may be created by them.
+		return false
 	}
 
 	anyChanged := false
@@ -244,6 +297,20 @@ func (r *projectionPushdown) applyProjectionPushdown(node Node, projections []Co
 
 var _ rule = (*projectionPushdown)(nil)
 
+// columnsOf returns all column expressions referenced by the given expression.
+func columnsOf(expr Expression) []ColumnExpression {
+	switch expr := expr.(type) {
+	case *ColumnExpr:
+		return []ColumnExpression{expr}
+	case *UnaryExpr:
+		return columnsOf(expr.Left)
+	case *BinaryExpr:
+		return append(columnsOf(expr.Left), columnsOf(expr.Right)...)
+	default:
+		return nil
+	}
+}
+
 // optimization represents a single optimization pass and can hold multiple rules.
 type optimization struct {
 	plan  *Plan
@@ -58,6 +58,66 @@ func TestCanApplyPredicate(t *testing.T) {
 			},
 			want: false,
 		},
+		{
+			predicate: &BinaryExpr{
+				Left: &BinaryExpr{
+					Left:  newColumnExpr("trace_id", types.ColumnTypeMetadata),
+					Right: NewLiteral("abc"),
+					Op:    types.BinaryOpEq,
+				},
+				Right: &BinaryExpr{
+					Left:  newColumnExpr("level", types.ColumnTypeMetadata),
+					Right: NewLiteral("error"),
+					Op:    types.BinaryOpEq,
+				},
+				Op: types.BinaryOpOr,
+			},
+			want: true,
+		},
+		{
+			// Non-numeric values of numeric comparisons are marked with an
+			// error label instead of being dropped.
+			predicate: &BinaryExpr{
+				Left:  newColumnExpr("duration", types.ColumnTypeMetadata),
+				Right: NewLiteral(1.5),
+				Op:    types.BinaryOpGt,
+			},
+			want: false,
+		},
+		{
+			predicate: &BinaryExpr{
+				Left: &BinaryExpr{
+					Left:  newColumnExpr("trace_id", types.ColumnTypeMetadata),
+					Right: NewLiteral("abc"),
+					Op:    types.BinaryOpEq,
+				},
+				Right: &BinaryExpr{
+					Left:  newColumnExpr("duration", types.ColumnTypeMetadata),
+					Right: NewLiteral(int64(10)),
+					Op:    types.BinaryOpLte,
+				},
+				Op: types.BinaryOpOr,
+			},
+			want: false,
+		},
+		{
+			predicate: &UnaryExpr{
+				Left: &BinaryExpr{
+					Left:  newColumnExpr("level", types.ColumnTypeMetadata),
+					Right: NewLiteral("debug"),
+					Op:    types.BinaryOpEq,
+				},
+				Op: types.UnaryOpNot,
+			},
+			want: true,
+		},
+		{
+			predicate: &UnaryExpr{
+				Left: newColumnExpr("duration", types.ColumnTypeMetadata),
+				Op:   types.UnaryOpAbs,
+			},
+			want: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.predicate.String(), func(t *testing.T) {
@@ -165,6 +225,76 @@ func TestOptimizer(t *testing.T) {
 		require.Equal(t, expected, actual)
 	})
 
+	t.Run("filter predicate pushdown stops at line format", func(t *testing.T) {
+		predicate := &BinaryExpr{
+			Left:  newColumnExpr(types.ColumnNameBuiltinMessage, types.ColumnTypeBuiltin),
+			Right: NewLiteral("foo"),
+			Op:    types.BinaryOpMatchSubstr,
+		}
+
+		plan := &Plan{}
+		{
+			scan := plan.addNode(&DataObjScan{id: "scan1"})
+			format := plan.addNode(&LineFormat{id: "format1", Template: "{{.msg}}"})
+			filter := plan.addNode(&Filter{id: "filter1", Predicates: []Expression{predicate}})
+
+			_ = plan.addEdge(Edge{Parent: filter, Child: format})
+			_ = plan.addEdge(Edge{Parent: format, Child: scan})
+		}
+
+		original := PrintAsTree(plan)
+
+		optimizations := []*optimization{
+			newOptimization("predicate pushdown", plan).withRules(
+				&predicatePushdown{plan},
+			),
+		}
+		o := newOptimizer(plan, optimizations)
+		o.optimize(plan.Roots()[0])
+
+		actual := PrintAsTree(plan)
+		require.Equal(t, original, actual)
+	})
+
+	t.Run("filter predicate pushdown keeps numeric metadata comparisons", func(t *testing.T) {
+		equality := &BinaryExpr{
+			Left:  newColumnExpr("level", types.ColumnTypeMetadata),
+			Right: NewLiteral("error"),
+			Op:    types.BinaryOpEq,
+		}
+		numeric := &BinaryExpr{
+			Left:  newColumnExpr("duration", types.ColumnTypeMetadata),
+			Right: NewLiteral(1.5),
+			Op:    types.BinaryOpGt,
+		}
+
+		plan := &Plan{}
+		{
+			scan := plan.addNode(&DataObjScan{id: "scan1"})
+			filter := plan.addNode(&Filter{id: "filter1", Predicates: []Expression{equality, numeric}})
+			_ = plan.addEdge(Edge{Parent: filter, Child: scan})
+		}
+
+		optimizations := []*optimization{
+			newOptimization("predicate pushdown", plan).withRules(
+				&predicatePushdown{plan},
+			),
+		}
+		o := newOptimizer(plan, optimizations)
+		o.optimize(plan.Roots()[0])
+		actual := PrintAsTree(plan)
+
+		optimized := &Plan{}
+		{
+			scan := optimized.addNode(&DataObjScan{id: "scan1", Predicates: []Expression{equality}})
+			filter := optimized.addNode(&Filter{id: "filter1", Predicates: []Expression{numeric}})
+			_ = optimized.addEdge(Edge{Parent: filter, Child: scan})
+		}
+
+		expected := PrintAsTree(optimized)
+		require.Equal(t, expected, actual)
+	})
+
 	t.Run("filter remove", func(t *testing.T) {
 		plan := dummyPlan()
 		optimizations := []*optimization{
@@ -205,6 +335,75 @@ func TestOptimizer(t *testing.T) {
 		require.Equal(t, expected, actual)
 	})
 
+	t.Run("limit pushdown", func(t *testing.T) {
+		plan := &Plan{}
+		{
+			scan1 := plan.addNode(&DataObjScan{id: "scan1", Direction: ASC})
+			scan2 := plan.addNode(&DataObjScan{id: "scan2", Direction: ASC})
+			merge := plan.addNode(&SortMerge{id: "merge", Order: ASC})
+			limit := plan.addNode(&Limit{id: "limit", Fetch: 100})
+
+			_ = plan.addEdge(Edge{Parent: limit, Child: merge})
+			_ = plan.addEdge(Edge{Parent: merge, Child: scan1})
+			_ = plan.addEdge(Edge{Parent: merge, Child: scan2})
+		}
+
+		optimizations := []*optimization{
+			newOptimization("limit pushdown", plan).withRules(
+				&limitPushdown{plan},
+			),
+		}
+		o := newOptimizer(plan, optimizations)
+		o.optimize(plan.Roots()[0])
+		actual := PrintAsTree(plan)
+
+		optimized := &Plan{}
+		{
+			scan1 := optimized.addNode(&DataObjScan{id: "scan1", Direction: ASC, Limit: 100})
+			scan2 := optimized.addNode(&DataObjScan{id: "scan2", Direction: ASC, Limit: 100})
+			merge := optimized.addNode(&SortMerge{id: "merge", Order: ASC})
+			limit := optimized.addNode(&Limit{id: "limit", Fetch: 100})
+
+			_ = optimized.addEdge(Edge{Parent: limit, Child: merge})
+			_ = optimized.addEdge(Edge{Parent: merge, Child: scan1})
+			_ = optimized.addEdge(Edge{Parent: merge, Child: scan2})
+		}
+
+		expected := PrintAsTree(optimized)
+		require.Equal(t, expected, actual)
+	})
+
+	t.Run("limit pushdown stops at filter", func(t *testing.T) {
+		plan := &Plan{}
+		{
+			scan := plan.addNode(&DataObjScan{id: "scan"})
+			filter := plan.addNode(&Filter{id: "filter", Predicates: []Expression{
+				&BinaryExpr{
+					Left:  newColumnExpr("level", types.ColumnTypeAmbiguous),
+					Right: NewLiteral("error"),
+					Op:    types.BinaryOpEq,
+				},
+			}})
+			limit := plan.addNode(&Limit{id: "limit", Fetch: 100})
+
+			_ = plan.addEdge(Edge{Parent: limit, Child: filter})
+			_ = plan.addEdge(Edge{Parent: filter, Child: scan})
+		}
+
+		original := PrintAsTree(plan)
+
+		optimizations := []*optimization{
+			newOptimization("limit pushdown", plan).withRules(
+				&limitPushdown{plan},
+			),
+		}
+		o := newOptimizer(plan, optimizations)
+		o.optimize(plan.Roots()[0])
+
+		actual := PrintAsTree(plan)
+		require.Equal(t, original, actual)
+	})
+
 	t.Run("groupby pushdown", func(t *testing.T) {
 		groupBy := []ColumnExpression{
 			&ColumnExpr{Ref: types.ColumnRef{Column: "service", Type: types.ColumnTypeLabel}},
@@ -261,6 +460,52 @@ func TestOptimizer(t *testing.T) {
 		require.Equal(t, expected, actual)
 	})
 
+	t.Run("groupby pushdown stops at nested vector aggregation", func(t *testing.T) {
+		groupBy := []ColumnExpression{
+			&ColumnExpr{Ref: types.ColumnRef{Column: "service", Type: types.ColumnTypeLabel}},
+		}
+
+		// generate plan for sum by(service) (max without (service) (count_over_time{...}[]))
+		plan := &Plan{}
+		{
+			scan1 := plan.addNode(&DataObjScan{id: "scan1"})
+			rangeAgg := plan.addNode(&RangeAggregation{
+				id:        "count_over_time",
+				Operation: types.RangeAggregationTypeCount,
+			})
+			maxAgg := plan.addNode(&VectorAggregation{
+				id:        "max_of",
+				Operation: types.VectorAggregationTypeMax,
+				GroupBy:   groupBy,
+				Without:   true,
+			})
+			sumAgg := plan.addNode(&VectorAggregation{
+				id:        "sum_of",
+				Operation: types.VectorAggregationTypeSum,
+				GroupBy:   groupBy,
+			})
+
+			_ = plan.addEdge(Edge{Parent: sumAgg, Child: maxAgg})
+			_ = plan.addEdge(Edge{Parent: maxAgg, Child: rangeAgg})
+			_ = plan.addEdge(Edge{Parent: rangeAgg, Child: scan1})
+		}
+		expected := PrintAsTree(plan)
+
+		// apply optimisation
+		optimizations := []*optimization{
+			newOptimization("group by pushdown", plan).withRules(
+				&groupByPushdown{plan: plan},
+			),
+		}
+		o := newOptimizer(plan, optimizations)
+		o.optimize(plan.Roots()[0])
+
+		// the plan must not change, as the range aggregation needs to
+		// partition by all labels for the max aggregation.
+		actual := PrintAsTree(plan)
+		require.Equal(t, expected, actual)
+	})
+
 	t.Run("projection pushdown", func(t *testing.T) {
 		partitionBy := []ColumnExpression{
 			&ColumnExpr{Ref: types.ColumnRef{Column: "service", Type: types.ColumnTypeLabel}},
@@ -320,4 +565,96 @@ func TestOptimizer(t *testing.T) {
 		expected := PrintAsTree(expectedPlan)
 		require.Equal(t, expected, actual)
 	})
+
+	t.Run("projection pushdown with unwrap", func(t *testing.T) {
+		partitionBy := []ColumnExpression{
+			&ColumnExpr{Ref: types.ColumnRef{Column: "service", Type: types.ColumnTypeLabel}},
+		}
+		unwrap := &ColumnExpr{Ref: types.ColumnRef{Column: "latency", Type: types.ColumnTypeAmbiguous}}
+
+		plan := &Plan{}
+		{
+			scan := plan.addNode(&DataObjScan{
+				id: "scan1",
+			})
+			rangeAgg := plan.addNode(&RangeAggregation{
+				id:          "range1",
+				Operation:   types.RangeAggregationTypeSum,
+				PartitionBy: partitionBy,
+				Unwrap:      unwrap,
+			})
+
+			_ = plan.addEdge(Edge{Parent: rangeAgg, Child: scan})
+		}
+
+		optimizations := []*optimization{
+			newOptimization("projection pushdown", plan).withRules(
+				&projectionPushdown{plan: plan},
+			),
+		}
+		o := newOptimizer(plan, optimizations)
+		o.optimize(plan.Roots()[0])
+
+		expectedPlan := &Plan{}
+		{
+			scan := expectedPlan.addNode(&DataObjScan{
+				id: "scan1",
+				Projections: []ColumnExpression{
+					partitionBy[0],
+					&ColumnExpr{Ref: types.ColumnRef{Column: types.ColumnNameBuiltinTimestamp, Type: types.ColumnTypeBuiltin}},
+					unwrap,
+				},
+			})
+			rangeAgg := expectedPlan.addNode(&RangeAggregation{
+				id:          "range1",
+				Operation:   types.RangeAggregationTypeSum,
+				PartitionBy: partitionBy,
+				Unwrap:      unwrap,
+			})
+
+			_ = expectedPlan.addEdge(Edge{Parent: rangeAgg, Child: scan})
+		}
+
+		actual := PrintAsTree(plan)
+		expected := PrintAsTree(expectedPlan)
+		require.Equal(t, expected, actual)
+	})
+
+	t.Run("projection pushdown stops at parse", func(t *testing.T) {
+		partitionBy := []ColumnExpression{
+			&ColumnExpr{Ref: types.ColumnRef{Column: "level", Type: types.ColumnTypeAmbiguous}},
+		}
+
+		plan := &Plan{}
+		{
+			scan := plan.addNode(&DataObjScan{
+				id: "scan1",
+			})
+			parse := plan.addNode(&Parse{
+				id:   "parse1",
+				Kind: types.ParserTypeLogfmt,
+			})
+			rangeAgg := plan.addNode(&RangeAggregation{
+				id:          "range1",
+				Operation:   types.RangeAggregationTypeCount,
+				PartitionBy: partitionBy,
+			})
+
+			_ = plan.addEdge(Edge{Parent: rangeAgg, Child: parse})
+			_ = plan.addEdge(Edge{Parent: parse, Child: scan})
+		}
+
+		original := PrintAsTree(plan)
+
+		optimizations := []*optimization{
+			newOptimization("projection pushdown", plan).withRules(
+				&projectionPushdown{plan: plan},
+			),
+		}
+		o := newOptimizer(plan, optimizations)
+		o.optimize(plan.Roots()[0])
+
+		actual := PrintAsTree(plan)
+		require.Equal(t, original, actual)
+	})
 }
@@ -0,0 +1,50 @@
+package physical
+
+import (
+	"fmt"
+
+	"github.com/grafana/loki/v3/pkg/engine/internal/types"
+	"github.com/grafana/loki/v3/pkg/logql/log"
+)
+
+// Parse represents a physical plan node that extracts labels from the log
+// line of each row and adds them as parsed columns to the output.
+type Parse struct {
+	id string
+
+	// Kind defines the parser that is applied to the log lines.
+	Kind types.ParserType
+
+	// Expression is the regular expression of the regexp parser or the
+	// pattern of the pattern parser.
+	Expression string
+
+	// Strict and KeepEmpty are flags of the logfmt parser.
+	Strict    bool
+	KeepEmpty bool
+
+	// Extractions limits the json and logfmt parsers to the given labels.
+	// If empty, all labels are extracted.
+	Extractions []log.LabelExtractionExpr
+}
+
+// ID implements the [Node] interface.
+// Returns a string that uniquely identifies the node in the plan.
+func (p *Parse) ID() string {
+	if p.id == "" {
+		return fmt.Sprintf("%p", p)
+	}
+	return p.id
+}
+
+// Type implements the [Node] interface.
+// Returns the type of the node.
+func (*Parse) Type() NodeType {
+	return NodeTypeParse
+}
+
+// Accept implements the [Node] interface.
+// Dispatches itself to the provided [Visitor] v
+func (p *Parse) Accept(v Visitor) error {
+	return v.VisitParse(p)
+}
@@ -16,6 +16,11 @@ const (
 	NodeTypeLimit
 	NodeTypeRangeAggreation
 	NodeTypeVectorAggregation
+	NodeTypeParse
+	NodeTypeLineFormat
+	NodeTypeLabelFormat
+	NodeTypeProjectLabels
+	NodeTypeBinaryOperation
 )
 
 func (t NodeType) String() string {
@@ -34,6 +39,16 @@ func (t NodeType) String() string {
 		return "RangeAggregation"
 	case NodeTypeVectorAggregation:
 		return "VectorAggregation"
+	case NodeTypeParse:
+		return "Parse"
+	case NodeTypeLineFormat:
+		return "LineFormat"
+	case NodeTypeLabelFormat:
+		return "LabelFormat"
+	case NodeTypeProjectLabels:
+		return "ProjectLabels"
+	case NodeTypeBinaryOperation:
+		return "BinaryOperation"
 	default:
 		return "Undefined"
 	}
@@ -65,6 +80,12 @@ var _ Node = (*Projection)(nil)
 var _ Node = (*Limit)(nil)
 var _ Node = (*Filter)(nil)
 var _ Node = (*RangeAggregation)(nil)
+var _ Node = (*VectorAggregation)(nil)
+var _ Node = (*Parse)(nil)
+var _ Node = (*LineFormat)(nil)
+var _ Node = (*LabelFormat)(nil)
+var _ Node = (*ProjectLabels)(nil)
+var _ Node = (*BinaryOperation)(nil)
 
 func (*DataObjScan) isNode()       {}
 func (*SortMerge) isNode()         {}
@@ -73,6 +94,11 @@ func (*Limit) isNode()             {}
 func (*Filter) isNode()            {}
 func (*RangeAggregation) isNode()  {}
 func (*VectorAggregation) isNode() {}
+func (*Parse) isNode()             {}
+func (*LineFormat) isNode()        {}
+func (*LabelFormat) isNode()       {}
+func (*ProjectLabels) isNode()     {}
+func (*BinaryOperation) isNode()   {}
 
 // Edge is a directed connection (parent-child relation) between a two nodes.
 type Edge struct {
@@ -146,6 +172,10 @@ type Plan struct {
 	parents map[Node]nodeSet
 	// children maps each node to a set of its child nodes in the execution graph
 	children map[Node]nodeSet
+	// childOrder maps a node to the order of its children set with
+	// [Plan.orderChildren]. Children of nodes without an order are sorted by
+	// their ID.
+	childOrder map[Node][]Node
 }
 
 func (p *Plan) init() {
@@ -204,6 +234,53 @@ func (p *Plan) addEdge(e Edge) error {
 
 	p.children[e.Parent].add(e.Child)
 	p.parents[e.Child].add(e.Parent)
+	delete(p.childOrder, e.Parent)
+	return nil
+}
+
+// removeEdge removes the directed edge between two nodes from the plan. Both
+// nodes remain part of the plan.
+func (p *Plan) removeEdge(e Edge) {
+	if children, ok := p.children[e.Parent]; ok {
+		children.remove(e.Child)
+	}
+	if parents, ok := p.parents[e.Child]; ok {
+		parents.remove(e.Parent)
+	}
+	delete(p.childOrder, e.Parent)
+}
+
+// insertNode inserts node between parent and its child, replacing the edge
+// between them. node is added to the plan if it doesn't exist yet.
+func (p *Plan) insertNode(parent, child, node Node) error {
+	if !p.children[parent].contains(child) {
+		return fmt.Errorf("node %s is not a child of %s", child.ID(), parent.ID())
+	}
+	p.addNode(node)
+	p.removeEdge(Edge{Parent: parent, Child: child})
+	if err := p.addEdge(Edge{Parent: parent, Child: node}); err != nil {
+		return err
+	}
+	return p.addEdge(Edge{Parent: node, Child: child})
+}
+
+// orderChildren sets the order in which [Plan.Children] returns the children
+// of parent. children must contain exactly the children of parent. The order
+// is reset once the children of parent change.
+func (p *Plan) orderChildren(parent Node, children []Node) error {
+	if len(children) != len(p.children[parent]) {
+		return fmt.Errorf("node %s has %d children, got %d", parent.ID(), len(p.children[parent]), len(children))
+	}
+	for _, child := range children {
+		if !p.children[parent].contains(child) {
+			return fmt.Errorf("node %s is not a child of %s", child.ID(), parent.ID())
+		}
+	}
+
+	if p.childOrder == nil {
+		p.childOrder = make(map[Node][]Node)
+	}
+	p.childOrder[parent] = slices.Clone(children)
 	return nil
 }
 
@@ -221,6 +298,7 @@ func (p *Plan) eliminateNode(node Node) {
 	for _, parent := range p.Parents(node) {
 		p.children[parent].remove(node)
 		p.parents[node].remove(parent)
+		delete(p.childOrder, parent)
 	}
 
 	for _, child := range p.Children(node) {
@@ -228,6 +306,8 @@ func (p *Plan) eliminateNode(node Node) {
 		p.children[node].remove(child)
 	}
 
+	delete(p.childOrder, node)
+
 	p.nodes.remove(node)
 	delete(p.nodesByID, node.ID())
 }
@@ -250,11 +330,15 @@ func (p *Plan) Parents(n Node) []Node {
 	return p.parents[n].sorted()
 }
 
-// Children returns all child nodes of the given node
+// Children returns all child nodes of the given node. Children are sorted by
+// their ID unless the optimizer picked a different order.
 func (p *Plan) Children(n Node) []Node {
 	if _, ok := p.children[n]; !ok {
 		return nil
 	}
+	if order, ok := p.childOrder[n]; ok {
+		return slices.Clone(order)
+	}
 	return p.children[n].sorted()
 }
 
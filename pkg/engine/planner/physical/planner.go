@@ -70,20 +70,39 @@ func (pc *Context) GetResolveTimeRange() (from, through time.Time) {
 //  2. Pushdown
 //     a) Push down the limit of the Limit node to the DataObjScan nodes.
 //     b) Push down the predicate from the Filter node to the DataObjScan nodes.
+//  3. Cost-based optimizations
+//     If the catalog implements [Statistics], the statistics of the scanned
+//     sections are used to pick the degree of parallelism of scans, whether
+//     to pre-aggregate range aggregations, and the order of scans.
+//
+// If fragments are enabled, range aggregations are additionally split for
+// executing the fragments of the plan on other queriers, see
+// [Planner.EnableFragments].
 type Planner struct {
-	context *Context
-	catalog Catalog
-	plan    *Plan
+	context   *Context
+	catalog   Catalog
+	costModel CostModel
+	plan      *Plan
+	fragments bool
 }
 
 // NewPlanner creates a new planner instance with the given context.
 func NewPlanner(ctx *Context, catalog Catalog) *Planner {
 	return &Planner{
-		context: ctx,
-		catalog: catalog,
+		context:   ctx,
+		catalog:   catalog,
+		costModel: DefaultCostModel,
 	}
 }
 
+// EnableFragments prepares the plans optimized by the planner for executing
+// their fragments on other queriers: additive range aggregations are computed
+// on each input of their merges, so that the fragments returned by
+// [SplitFragments] include the partial aggregations.
+func (p *Planner) EnableFragments() {
+	p.fragments = true
+}
+
 // Build converts a given logical plan into a physical plan and returns an error if the conversion fails.
 // The resulting plan can be accessed using [Planner.Plan].
 func (p *Planner) Build(lp *logical.Plan) (*Plan, error) {
@@ -147,6 +166,16 @@ func (p *Planner) process(inst logical.Value, ctx *Context) ([]Node, error) {
 		return p.processRangeAggregation(inst, ctx)
 	case *logical.VectorAggregation:
 		return p.processVectorAggregation(inst, ctx)
+	case *logical.Parse:
+		return p.processParse(inst, ctx)
+	case *logical.LineFormat:
+		return p.processLineFormat(inst, ctx)
+	case *logical.LabelFormat:
+		return p.processLabelFormat(inst, ctx)
+	case *logical.ProjectLabels:
+		return p.processProjectLabels(inst, ctx)
+	case *logical.BinOp:
+		return p.processBinOp(inst, ctx)
 	}
 	return nil, nil
 }
@@ -165,13 +194,17 @@ func (p *Planner) processMakeTable(lp *logical.MakeTable, ctx *Context) ([]Node,
 
 	from, through := ctx.GetResolveTimeRange()
 
-	objects, streams, sections, err := p.catalog.ResolveDataObjWithShard(p.convertPredicate(lp.Selector), predicates, ShardInfo(*shard), from, through)
+	objects, streams, sections, indexes, err := p.catalog.ResolveDataObjWithShard(p.convertPredicate(lp.Selector), predicates, ShardInfo(*shard), from, through)
 	if err != nil {
 		return nil, err
 	}
 
 	nodes := make([]Node, 0, len(objects))
 	for i := range objects {
+		var index DataObjLocation
+		if indexes != nil {
+			index = indexes[i]
+		}
 
 		node := &SortMerge{
 			Column: newColumnExpr(types.ColumnNameBuiltinTimestamp, types.ColumnTypeBuiltin),
@@ -184,6 +217,7 @@ func (p *Planner) processMakeTable(lp *logical.MakeTable, ctx *Context) ([]Node,
 				Location:  objects[i],
 				StreamIDs: streams[i],
 				Section:   section,
+				Index:     index,
 				Direction: ctx.direction, // apply direction from previously visited Sort node
 			}
 			p.plan.addNode(scan)
@@ -266,13 +300,22 @@ func (p *Planner) processRangeAggregation(r *logical.RangeAggregation, ctx *Cont
 		partitionBy[i] = &ColumnExpr{Ref: col.Ref}
 	}
 
+	var unwrap Expression
+	if r.Unwrap != nil {
+		unwrap = p.convertPredicate(r.Unwrap)
+	}
+
 	node := &RangeAggregation{
 		PartitionBy: partitionBy,
 		Operation:   r.Operation,
+		Unwrap:      unwrap,
+		Parameter:   r.Parameter,
 		Start:       r.Start,
 		End:         r.End,
 		Range:       r.RangeInterval,
 		Step:        r.Step,
+
+		AbsentLabels: r.AbsentLabels,
 	}
 	p.plan.addNode(node)
 
@@ -298,7 +341,144 @@ func (p *Planner) processVectorAggregation(lp *logical.VectorAggregation, ctx *C
 
 	node := &VectorAggregation{
 		GroupBy:   groupBy,
+		Without:   lp.Without,
 		Operation: lp.Operation,
+		Parameter: lp.Parameter,
+	}
+	p.plan.addNode(node)
+	children, err := p.process(lp.Table, ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range children {
+		if err := p.plan.addEdge(Edge{Parent: node, Child: children[i]}); err != nil {
+			return nil, err
+		}
+	}
+	return []Node{node}, nil
+}
+
+// Convert [logical.BinOp] into one [BinaryOperation] node.
+// Each side of the operation is planned independently. A [logical.Literal]
+// side becomes the scalar of the node instead of a child node.
+func (p *Planner) processBinOp(lp *logical.BinOp, ctx *Context) ([]Node, error) {
+	node := &BinaryOperation{
+		Op:         lp.Op,
+		ReturnBool: lp.ReturnBool,
+	}
+	if m := lp.VectorMatching; m != nil {
+		node.VectorMatching = &VectorMatching{
+			On:             m.On,
+			MatchingLabels: convertColumnRefs(m.MatchingLabels),
+			Card:           m.Card,
+			Include:        convertColumnRefs(m.Include),
+		}
+	}
+	p.plan.addNode(node)
+
+	processOperand := func(operand logical.Value) (Node, error) {
+		if literal, ok := operand.(*logical.Literal); ok {
+			node.Scalar = NewLiteral(literal.Value())
+			return nil, nil
+		}
+
+		children, err := p.process(operand, ctx.Clone())
+		if err != nil {
+			return nil, err
+		}
+		if len(children) != 1 {
+			return nil, fmt.Errorf("operand of binary operation %s must produce exactly one node, got %d", lp.Op, len(children))
+		}
+		if err := p.plan.addEdge(Edge{Parent: node, Child: children[0]}); err != nil {
+			return nil, err
+		}
+		return children[0], nil
+	}
+
+	var err error
+	if node.Left, err = processOperand(lp.Left); err != nil {
+		return nil, err
+	}
+	if node.Right, err = processOperand(lp.Right); err != nil {
+		return nil, err
+	}
+	if node.Left == nil && node.Right == nil {
+		return nil, fmt.Errorf("binary operation %s requires at least one vector operand", lp.Op)
+	}
+	return []Node{node}, nil
+}
+
+func convertColumnRefs(refs []logical.ColumnRef) []ColumnExpression {
+	columns := make([]ColumnExpression, len(refs))
+	for i, ref := range refs {
+		columns[i] = &ColumnExpr{Ref: ref.Ref}
+	}
+	return columns
+}
+
+// Convert [logical.Parse] into one [Parse] node.
+func (p *Planner) processParse(lp *logical.Parse, ctx *Context) ([]Node, error) {
+	node := &Parse{
+		Kind:        lp.Kind,
+		Expression:  lp.Expression,
+		Strict:      lp.Strict,
+		KeepEmpty:   lp.KeepEmpty,
+		Extractions: lp.Extractions,
+	}
+	p.plan.addNode(node)
+	children, err := p.process(lp.Table, ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range children {
+		if err := p.plan.addEdge(Edge{Parent: node, Child: children[i]}); err != nil {
+			return nil, err
+		}
+	}
+	return []Node{node}, nil
+}
+
+// Convert [logical.LineFormat] into one [LineFormat] node.
+func (p *Planner) processLineFormat(lp *logical.LineFormat, ctx *Context) ([]Node, error) {
+	node := &LineFormat{
+		Template: lp.Template,
+	}
+	p.plan.addNode(node)
+	children, err := p.process(lp.Table, ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range children {
+		if err := p.plan.addEdge(Edge{Parent: node, Child: children[i]}); err != nil {
+			return nil, err
+		}
+	}
+	return []Node{node}, nil
+}
+
+// Convert [logical.LabelFormat] into one [LabelFormat] node.
+func (p *Planner) processLabelFormat(lp *logical.LabelFormat, ctx *Context) ([]Node, error) {
+	node := &LabelFormat{
+		Formats: lp.Formats,
+	}
+	p.plan.addNode(node)
+	children, err := p.process(lp.Table, ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range children {
+		if err := p.plan.addEdge(Edge{Parent: node, Child: children[i]}); err != nil {
+			return nil, err
+		}
+	}
+	return []Node{node}, nil
+}
+
+// Convert [logical.ProjectLabels] into one [ProjectLabels] node.
+func (p *Planner) processProjectLabels(lp *logical.ProjectLabels, ctx *Context) ([]Node, error) {
+	node := &ProjectLabels{
+		Labels: lp.Labels,
+		Drop:   lp.Drop,
 	}
 	p.plan.addNode(node)
 	children, err := p.process(lp.Table, ctx)
@@ -314,7 +494,8 @@ func (p *Planner) processVectorAggregation(lp *logical.VectorAggregation, ctx *C
 }
 
 // Optimize tries to optimize the plan by pushing down filter predicates and limits
-// to the scan nodes.
+// to the scan nodes, and by applying cost-based optimizations if statistics
+// are available.
 func (p *Planner) Optimize(plan *Plan) (*Plan, error) {
 	for i, root := range plan.Roots() {
 
@@ -334,6 +515,37 @@ func (p *Planner) Optimize(plan *Plan) (*Plan, error) {
 				&projectionPushdown{plan: plan},
 			),
 		}
+		statistics, hasStatistics := p.catalog.(Statistics)
+		estimator := newCostEstimator(statistics)
+		if hasStatistics {
+			// Cost-based optimizations copy the pushed down predicates and
+			// projections of scans, so they are applied last.
+			optimizations = append(optimizations,
+				newOptimization("ScanParallelism", plan).withRules(
+					&scanParallelism{plan: plan, model: p.costModel, estimator: estimator},
+				),
+				newOptimization("PreAggregation", plan).withRules(
+					&preAggregation{plan: plan, model: p.costModel, estimator: estimator},
+				),
+			)
+		}
+		if p.fragments {
+			// Range aggregations which are not pre-aggregated based on their
+			// cost are split for their fragments. This resets the order of
+			// the inputs of merges, so it must be applied before ScanOrder.
+			optimizations = append(optimizations,
+				newOptimization("FragmentAggregation", plan).withRules(
+					&fragmentAggregation{plan: plan},
+				),
+			)
+		}
+		if hasStatistics {
+			optimizations = append(optimizations,
+				newOptimization("ScanOrder", plan).withRules(
+					&scanOrder{plan: plan, estimator: estimator},
+				),
+			)
+		}
 		optimizer := newOptimizer(plan, optimizations)
 		optimizer.optimize(root)
 		if i == 1 {
@@ -22,12 +22,12 @@ type catalog struct {
 }
 
 // ResolveDataObj implements Catalog.
-func (c *catalog) ResolveDataObj(e Expression, from, through time.Time) ([]DataObjLocation, [][]int64, [][]int, error) {
+func (c *catalog) ResolveDataObj(e Expression, from, through time.Time) ([]DataObjLocation, [][]int64, [][]int, []DataObjLocation, error) {
 	return c.ResolveDataObjWithShard(e, nil, noShard, from, through)
 }
 
 // ResolveDataObjForShard implements Catalog.
-func (c *catalog) ResolveDataObjWithShard(_ Expression, _ []Expression, shard ShardInfo, _, _ time.Time) ([]DataObjLocation, [][]int64, [][]int, error) {
+func (c *catalog) ResolveDataObjWithShard(_ Expression, _ []Expression, shard ShardInfo, _, _ time.Time) ([]DataObjLocation, [][]int64, [][]int, []DataObjLocation, error) {
 	paths := make([]string, 0, len(c.streamsByObject))
 	streams := make([][]int64, 0, len(c.streamsByObject))
 	sections := make([]int, 0, len(c.streamsByObject))
@@ -100,7 +100,7 @@ func TestMockCatalog(t *testing.T) {
 		},
 	} {
 		t.Run("shard "+tt.shard.String(), func(t *testing.T) {
-			paths, streams, sections, _ := catalog.ResolveDataObjWithShard(nil, nil, tt.shard, time.Now(), time.Now())
+			paths, streams, sections, _, _ := catalog.ResolveDataObjWithShard(nil, nil, tt.shard, time.Now(), time.Now())
 			require.Equal(t, tt.expPaths, paths)
 			require.Equal(t, tt.expStreams, streams)
 			require.Equal(t, tt.expSections, sections)
@@ -314,3 +314,183 @@ func TestPlanner_Convert_RangeAggregations(t *testing.T) {
 	require.NoError(t, err)
 	t.Logf("Optimized plan\n%s\n", PrintAsTree(physicalPlan))
 }
+
+func TestPlanner_Convert_Parse(t *testing.T) {
+	// logical plan for { app="users" } | logfmt | level="error"
+	b := logical.NewBuilder(
+		&logical.MakeTable{
+			Selector: &logical.BinOp{
+				Left:  logical.NewColumnRef("app", types.ColumnTypeLabel),
+				Right: logical.NewLiteral("users"),
+				Op:    types.BinaryOpEq,
+			},
+			Shard: logical.NewShard(0, 1), // no sharding
+		},
+	).Parse(
+		types.ParserTypeLogfmt, "", false, false, nil,
+	).Select(
+		&logical.BinOp{
+			Left:  logical.NewColumnRef("level", types.ColumnTypeAmbiguous),
+			Right: logical.NewLiteral("error"),
+			Op:    types.BinaryOpEq,
+		},
+	)
+
+	logicalPlan, err := b.ToPlan()
+	require.NoError(t, err)
+
+	catalog := &catalog{
+		streamsByObject: map[string]objectMeta{
+			"obj1": {streamIDs: []int64{1, 2}, sections: 1},
+		},
+	}
+	planner := NewPlanner(NewContext(time.Now(), time.Now()), catalog)
+
+	physicalPlan, err := planner.Build(logicalPlan)
+	require.NoError(t, err)
+
+	physicalPlan, err = planner.Optimize(physicalPlan)
+	require.NoError(t, err)
+	t.Logf("Optimized plan\n%s\n", PrintAsTree(physicalPlan))
+
+	// The filter on the parsed label must not be pushed down below the parser.
+	root, err := physicalPlan.Root()
+	require.NoError(t, err)
+	require.IsType(t, &Filter{}, root)
+	require.Len(t, root.(*Filter).Predicates, 1)
+
+	children := physicalPlan.Children(root)
+	require.Len(t, children, 1)
+	require.IsType(t, &Parse{}, children[0])
+	require.Equal(t, types.ParserTypeLogfmt, children[0].(*Parse).Kind)
+}
+
+func TestPlanner_Convert_Unpack(t *testing.T) {
+	// logical plan for { app="users" } | unpack |= "foo"
+	b := logical.NewBuilder(
+		&logical.MakeTable{
+			Selector: &logical.BinOp{
+				Left:  logical.NewColumnRef("app", types.ColumnTypeLabel),
+				Right: logical.NewLiteral("users"),
+				Op:    types.BinaryOpEq,
+			},
+			Shard: logical.NewShard(0, 1), // no sharding
+		},
+	).Parse(
+		types.ParserTypeUnpack, "", false, false, nil,
+	).Select(
+		&logical.BinOp{
+			Left:  logical.NewColumnRef(types.ColumnNameBuiltinMessage, types.ColumnTypeBuiltin),
+			Right: logical.NewLiteral("foo"),
+			Op:    types.BinaryOpMatchSubstr,
+		},
+	)
+
+	logicalPlan, err := b.ToPlan()
+	require.NoError(t, err)
+
+	catalog := &catalog{
+		streamsByObject: map[string]objectMeta{
+			"obj1": {streamIDs: []int64{1, 2}, sections: 1},
+		},
+	}
+	planner := NewPlanner(NewContext(time.Now(), time.Now()), catalog)
+
+	physicalPlan, err := planner.Build(logicalPlan)
+	require.NoError(t, err)
+
+	physicalPlan, err = planner.Optimize(physicalPlan)
+	require.NoError(t, err)
+	t.Logf("Optimized plan\n%s\n", PrintAsTree(physicalPlan))
+
+	// unpack replaces the log line, so the line filter must be evaluated
+	// after the parser instead of on the packed line read by the scans.
+	root, err := physicalPlan.Root()
+	require.NoError(t, err)
+	require.IsType(t, &Filter{}, root)
+	require.Len(t, root.(*Filter).Predicates, 1)
+
+	children := physicalPlan.Children(root)
+	require.Len(t, children, 1)
+	require.IsType(t, &Parse{}, children[0])
+	require.Equal(t, types.ParserTypeUnpack, children[0].(*Parse).Kind)
+
+	for _, node := range physicalPlan.Leaves() {
+		require.IsType(t, &DataObjScan{}, node)
+		require.Empty(t, node.(*DataObjScan).Predicates, "the line filter is not pushed down")
+	}
+}
+
+func TestPlanner_Convert_BinOp(t *testing.T) {
+	// logical plan for sum by (level) (count_over_time({app="users"}[5m])) / on (level) sum by (level) (count_over_time({app="orders"}[5m])) > 2
+	operand := func(app string) *logical.Builder {
+		return logical.NewBuilder(
+			&logical.MakeTable{
+				Selector: &logical.BinOp{
+					Left:  logical.NewColumnRef("app", types.ColumnTypeLabel),
+					Right: logical.NewLiteral(app),
+					Op:    types.BinaryOpEq,
+				},
+				Shard: logical.NewShard(0, 1), // no sharding
+			},
+		).RangeAggregation(
+			nil,
+			types.RangeAggregationTypeCount,
+			time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC), // Start Time
+			time.Date(2023, 10, 1, 1, 0, 0, 0, time.UTC), // End Time
+			0,             // Step
+			time.Minute*5, // Range
+		).VectorAggregation(
+			[]logical.ColumnRef{*logical.NewColumnRef("level", types.ColumnTypeAmbiguous)},
+			types.VectorAggregationTypeSum,
+		)
+	}
+
+	left, right := operand("users"), operand("orders")
+	b := left.BinOp(types.BinaryOpDiv, right.Value(), false, &logical.VectorMatching{
+		On:             true,
+		MatchingLabels: []logical.ColumnRef{*logical.NewColumnRef("level", types.ColumnTypeAmbiguous)},
+		Card:           types.VectorMatchOneToOne,
+	}).BinOp(types.BinaryOpGt, logical.NewLiteral(float64(2)), false, nil)
+
+	logicalPlan, err := b.ToPlan()
+	require.NoError(t, err)
+
+	catalog := &catalog{
+		streamsByObject: map[string]objectMeta{
+			"obj1": {streamIDs: []int64{1, 2}, sections: 1},
+		},
+	}
+	planner := NewPlanner(NewContext(time.Now(), time.Now()), catalog)
+
+	physicalPlan, err := planner.Build(logicalPlan)
+	require.NoError(t, err)
+
+	physicalPlan, err = planner.Optimize(physicalPlan)
+	require.NoError(t, err)
+	t.Logf("Optimized plan\n%s\n", PrintAsTree(physicalPlan))
+
+	root, err := physicalPlan.Root()
+	require.NoError(t, err)
+	require.IsType(t, &BinaryOperation{}, root)
+
+	filter := root.(*BinaryOperation)
+	require.Equal(t, types.BinaryOpGt, filter.Op)
+	require.Nil(t, filter.Right)
+	require.Equal(t, NewLiteral(float64(2)), filter.Scalar)
+	require.Equal(t, []Node{filter.Left}, physicalPlan.Children(filter))
+
+	require.IsType(t, &BinaryOperation{}, filter.Left)
+	div := filter.Left.(*BinaryOperation)
+	require.Equal(t, types.BinaryOpDiv, div.Op)
+	require.Nil(t, div.Scalar)
+	require.True(t, div.VectorMatching.On)
+	require.Len(t, physicalPlan.Children(div), 2)
+
+	// Both sides of the operation are planned independently.
+	for _, side := range []Node{div.Left, div.Right} {
+		require.IsType(t, &VectorAggregation{}, side)
+		require.Contains(t, physicalPlan.Children(div), side)
+	}
+	require.NotSame(t, div.Left, div.Right)
+}
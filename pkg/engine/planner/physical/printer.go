@@ -6,19 +6,31 @@ import (
 	"strings"
 	"time"
 
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/loki/v3/pkg/engine/internal/types"
 	"github.com/grafana/loki/v3/pkg/engine/planner/internal/tree"
 )
 
 // BuildTree converts a physical plan node and its children into a tree structure
 // that can be used for visualization and debugging purposes.
 func BuildTree(p *Plan, n Node) *tree.Node {
-	return toTree(p, n)
+	return toTree(p, n, nil)
 }
 
-func toTree(p *Plan, n Node) *tree.Node {
+func toTree(p *Plan, n Node, stats func(Node) []Stat) *tree.Node {
 	root := toTreeNode(n)
+	if stats != nil {
+		if s := stats(n); len(s) > 0 {
+			properties := make([]tree.Property, len(s))
+			for i := range s {
+				properties[i] = tree.NewProperty(s[i].Name, false, s[i].Value)
+			}
+			root.AddComment("Stats", "", properties)
+		}
+	}
 	for _, child := range p.Children(n) {
-		if ch := toTree(p, child); ch != nil {
+		if ch := toTree(p, child, stats); ch != nil {
 			root.Children = append(root.Children, ch)
 		}
 	}
@@ -37,6 +49,9 @@ func toTreeNode(n Node) *tree.Node {
 			tree.NewProperty("direction", false, node.Direction),
 			tree.NewProperty("limit", false, node.Limit),
 		}
+		if node.Index != "" {
+			treeNode.Properties = append(treeNode.Properties, tree.NewProperty("index", false, node.Index))
+		}
 		for i := range node.Predicates {
 			treeNode.Properties = append(treeNode.Properties, tree.NewProperty(fmt.Sprintf("predicate[%d]", i), false, node.Predicates[i].String()))
 		}
@@ -67,10 +82,125 @@ func toTreeNode(n Node) *tree.Node {
 			tree.NewProperty("range", false, node.Range),
 		}
 
+		if node.Operation.HasParameter() {
+			properties = append(properties, tree.NewProperty("parameter", false, node.Parameter))
+		}
+		if node.Unwrap != nil {
+			properties = append(properties, tree.NewProperty("unwrap", false, node.Unwrap.String()))
+		}
+		if len(node.AbsentLabels) > 0 {
+			properties = append(properties, tree.NewProperty("absent_labels", false, labels.New(node.AbsentLabels...).String()))
+		}
+
 		if len(node.PartitionBy) > 0 {
 			properties = append(properties, tree.NewProperty("partition_by", true, toAnySlice(node.PartitionBy)...))
 		}
 
+		treeNode.Properties = properties
+	case *VectorAggregation:
+		properties := []tree.Property{
+			tree.NewProperty("operation", false, node.Operation),
+		}
+
+		switch node.Operation {
+		case types.VectorAggregationTypeTopK, types.VectorAggregationTypeBottomK:
+			properties = append(properties, tree.NewProperty("parameter", false, node.Parameter))
+		}
+
+		if node.Without {
+			properties = append(properties, tree.NewProperty("without", true, toAnySlice(node.GroupBy)...))
+		} else if len(node.GroupBy) > 0 {
+			properties = append(properties, tree.NewProperty("group_by", true, toAnySlice(node.GroupBy)...))
+		}
+
+		treeNode.Properties = properties
+	case *Parse:
+		properties := []tree.Property{
+			tree.NewProperty("kind", false, node.Kind),
+		}
+
+		switch node.Kind {
+		case types.ParserTypeRegexp, types.ParserTypePattern:
+			properties = append(properties, tree.NewProperty("expression", false, node.Expression))
+		case types.ParserTypeLogfmt:
+			properties = append(properties,
+				tree.NewProperty("strict", false, node.Strict),
+				tree.NewProperty("keep_empty", false, node.KeepEmpty),
+			)
+		}
+
+		if len(node.Extractions) > 0 {
+			extractions := make([]any, len(node.Extractions))
+			for i, e := range node.Extractions {
+				extractions[i] = fmt.Sprintf("%s=%q", e.Identifier, e.Expression)
+			}
+			properties = append(properties, tree.NewProperty("extractions", true, extractions...))
+		}
+
+		treeNode.Properties = properties
+	case *LineFormat:
+		treeNode.Properties = []tree.Property{
+			tree.NewProperty("template", false, node.Template),
+		}
+	case *LabelFormat:
+		formats := make([]any, len(node.Formats))
+		for i, f := range node.Formats {
+			if f.Rename {
+				formats[i] = f.Name + "=" + f.Value
+			} else {
+				formats[i] = fmt.Sprintf("%s=%q", f.Name, f.Value)
+			}
+		}
+		treeNode.Properties = []tree.Property{
+			tree.NewProperty("formats", true, formats...),
+		}
+	case *ProjectLabels:
+		labels := make([]any, len(node.Labels))
+		for i, l := range node.Labels {
+			if l.Matcher != nil {
+				labels[i] = l.Matcher.String()
+			} else {
+				labels[i] = l.Name
+			}
+		}
+		key := "keep"
+		if node.Drop {
+			key = "drop"
+		}
+		treeNode.Properties = []tree.Property{
+			tree.NewProperty(key, true, labels...),
+		}
+	case *BinaryOperation:
+		properties := []tree.Property{
+			tree.NewProperty("operation", false, node.Op),
+		}
+
+		if node.Scalar != nil {
+			side := "right"
+			if node.Left == nil {
+				side = "left"
+			}
+			properties = append(properties, tree.NewProperty("scalar_"+side, false, node.Scalar.String()))
+		}
+		if node.ReturnBool {
+			properties = append(properties, tree.NewProperty("return_bool", false, node.ReturnBool))
+		}
+
+		if m := node.VectorMatching; m != nil {
+			if m.On {
+				properties = append(properties, tree.NewProperty("on", true, toAnySlice(m.MatchingLabels)...))
+			} else if len(m.MatchingLabels) > 0 {
+				properties = append(properties, tree.NewProperty("ignoring", true, toAnySlice(m.MatchingLabels)...))
+			}
+
+			switch m.Card {
+			case types.VectorMatchManyToOne:
+				properties = append(properties, tree.NewProperty("group_left", true, toAnySlice(m.Include)...))
+			case types.VectorMatchOneToMany:
+				properties = append(properties, tree.NewProperty("group_right", true, toAnySlice(m.Include)...))
+			}
+		}
+
 		treeNode.Properties = properties
 	}
 	return treeNode
@@ -101,6 +231,29 @@ func PrintAsTree(p *Plan) string {
 	return strings.Join(results, "\n")
 }
 
+// Stat is a runtime statistic of a node of an executed plan.
+type Stat struct {
+	Name  string
+	Value any
+}
+
+// PrintAsTreeWithStats converts an executed physical [Plan] into a
+// human-readable tree representation like [PrintAsTree]. The runtime
+// statistics returned by stats for each node are printed below the node.
+func PrintAsTreeWithStats(p *Plan, stats func(Node) []Stat) string {
+	results := make([]string, 0, len(p.Roots()))
+
+	for _, root := range p.Roots() {
+		sb := &strings.Builder{}
+		printer := tree.NewPrinter(sb)
+		node := toTree(p, root, stats)
+		printer.Print(node)
+		results = append(results, sb.String())
+	}
+
+	return strings.Join(results, "\n")
+}
+
 func WriteMermaidFormat(w io.Writer, p *Plan) {
 	for _, root := range p.Roots() {
 		node := BuildTree(p, root)
@@ -1,6 +1,10 @@
 package physical
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
 
 func TestPrinter(t *testing.T) {
 	t.Run("simple tree", func(t *testing.T) {
@@ -50,3 +54,23 @@ func TestPrinter(t *testing.T) {
 		t.Log("\n" + repr)
 	})
 }
+
+func TestPrintAsTreeWithStats(t *testing.T) {
+	p := &Plan{}
+	limit := p.addNode(&Limit{id: "limit", Fetch: 10})
+	scan := p.addNode(&DataObjScan{id: "scan", Location: "obj"})
+	_ = p.addEdge(Edge{Parent: limit, Child: scan})
+
+	repr := PrintAsTreeWithStats(p, func(n Node) []Stat {
+		if n.ID() != "scan" {
+			return nil // nodes without statistics are printed as is
+		}
+		return []Stat{{Name: "rows", Value: 10}, {Name: "pages_scanned", Value: 2}}
+	})
+
+	expected := `Limit <limit> offset=0 limit=10
+└── DataObjScan <scan> location=obj streams=0 section_id=0 projections=() direction=ASC limit=0
+        └── Stats rows=10 pages_scanned=2
+`
+	require.Equal(t, expected, repr)
+}
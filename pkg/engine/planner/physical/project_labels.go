@@ -0,0 +1,41 @@
+package physical
+
+import (
+	"fmt"
+
+	"github.com/grafana/loki/v3/pkg/logql/log"
+)
+
+// ProjectLabels represents a physical plan node that keeps or drops labels of
+// each row. Unlike [Projection], it operates on the labels of each row rather
+// than on the columns of the input.
+type ProjectLabels struct {
+	id string
+
+	// Labels is the list of label names or label matchers. Labels that
+	// match an entry are dropped if Drop is true, otherwise all other labels
+	// are dropped.
+	Labels []log.NamedLabelMatcher
+	Drop   bool
+}
+
+// ID implements the [Node] interface.
+// Returns a string that uniquely identifies the node in the plan.
+func (p *ProjectLabels) ID() string {
+	if p.id == "" {
+		return fmt.Sprintf("%p", p)
+	}
+	return p.id
+}
+
+// Type implements the [Node] interface.
+// Returns the type of the node.
+func (*ProjectLabels) Type() NodeType {
+	return NodeTypeProjectLabels
+}
+
+// Accept implements the [Node] interface.
+// Dispatches itself to the provided [Visitor] v
+func (p *ProjectLabels) Accept(v Visitor) error {
+	return v.VisitProjectLabels(p)
+}
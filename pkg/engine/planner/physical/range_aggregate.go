@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/prometheus/prometheus/model/labels"
+
 	"github.com/grafana/loki/v3/pkg/engine/internal/types"
 )
 
@@ -14,10 +16,14 @@ type RangeAggregation struct {
 	PartitionBy []ColumnExpression // Columns to partition the data by.
 
 	Operation types.RangeAggregationType
+	Unwrap    Expression // Expression to extract the sample value from, nil if the query does not unwrap.
+	Parameter float64    // Parameter of the operation, such as the quantile for quantile_over_time.
 	Start     time.Time
 	End       time.Time
 	Step      time.Duration // optional for instant queries
 	Range     time.Duration
+
+	AbsentLabels []labels.Label // Labels of the series yielded by absent_over_time.
 }
 
 func (r *RangeAggregation) ID() string {
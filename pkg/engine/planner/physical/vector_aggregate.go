@@ -15,8 +15,16 @@ type VectorAggregation struct {
 	// GroupBy defines the columns to group by. If empty, all rows are aggregated into a single result.
 	GroupBy []ColumnExpression
 
+	// Without inverts the grouping. If true, rows are grouped by all label
+	// columns of the input except the ones defined in GroupBy.
+	Without bool
+
 	// Operation defines the type of aggregation operation to perform (e.g., sum, min, max)
 	Operation types.VectorAggregationType
+
+	// Parameter defines the parameter of the operation, such as the number
+	// of samples to select for topk and bottomk.
+	Parameter int
 }
 
 // ID implements the [Node] interface.
@@ -12,4 +12,9 @@ type Visitor interface {
 	VisitFilter(*Filter) error
 	VisitLimit(*Limit) error
 	VisitVectorAggregation(*VectorAggregation) error
+	VisitParse(*Parse) error
+	VisitLineFormat(*LineFormat) error
+	VisitLabelFormat(*LabelFormat) error
+	VisitProjectLabels(*ProjectLabels) error
+	VisitBinaryOperation(*BinaryOperation) error
 }
@@ -16,6 +16,11 @@ type nodeCollectVisitor struct {
 	onVisitProjection        func(*Projection) error
 	onVisitRangeAggregation  func(*RangeAggregation) error
 	onVisitVectorAggregation func(*VectorAggregation) error
+	onVisitParse             func(*Parse) error
+	onVisitLineFormat        func(*LineFormat) error
+	onVisitLabelFormat       func(*LabelFormat) error
+	onVisitProjectLabels     func(*ProjectLabels) error
+	onVisitBinaryOperation   func(*BinaryOperation) error
 }
 
 func (v *nodeCollectVisitor) VisitDataObjScan(n *DataObjScan) error {
@@ -74,3 +79,43 @@ func (v *nodeCollectVisitor) VisitVectorAggregation(n *VectorAggregation) error
 	v.visited = append(v.visited, fmt.Sprintf("%s.%s", n.Type().String(), n.ID()))
 	return nil
 }
+
+func (v *nodeCollectVisitor) VisitParse(n *Parse) error {
+	if v.onVisitParse != nil {
+		return v.onVisitParse(n)
+	}
+	v.visited = append(v.visited, fmt.Sprintf("%s.%s", n.Type().String(), n.ID()))
+	return nil
+}
+
+func (v *nodeCollectVisitor) VisitLineFormat(n *LineFormat) error {
+	if v.onVisitLineFormat != nil {
+		return v.onVisitLineFormat(n)
+	}
+	v.visited = append(v.visited, fmt.Sprintf("%s.%s", n.Type().String(), n.ID()))
+	return nil
+}
+
+func (v *nodeCollectVisitor) VisitLabelFormat(n *LabelFormat) error {
+	if v.onVisitLabelFormat != nil {
+		return v.onVisitLabelFormat(n)
+	}
+	v.visited = append(v.visited, fmt.Sprintf("%s.%s", n.Type().String(), n.ID()))
+	return nil
+}
+
+func (v *nodeCollectVisitor) VisitProjectLabels(n *ProjectLabels) error {
+	if v.onVisitProjectLabels != nil {
+		return v.onVisitProjectLabels(n)
+	}
+	v.visited = append(v.visited, fmt.Sprintf("%s.%s", n.Type().String(), n.ID()))
+	return nil
+}
+
+func (v *nodeCollectVisitor) VisitBinaryOperation(n *BinaryOperation) error {
+	if v.onVisitBinaryOperation != nil {
+		return v.onVisitBinaryOperation(n)
+	}
+	v.visited = append(v.visited, fmt.Sprintf("%s.%s", n.Type().String(), n.ID()))
+	return nil
+}
@@ -44,8 +44,13 @@ const (
 	// HTTP header keys
 	HTTPScopeOrgID          = "X-Scope-OrgID"
 	HTTPQueryTags           = "X-Query-Tags"
+	HTTPExplain             = "X-Loki-Explain"
 	HTTPCacheControl        = "Cache-Control"
 	HTTPCacheControlNoCache = "no-cache"
+
+	// ExplainAnalyze is the value of the HTTPExplain header to execute queries
+	// with EXPLAIN ANALYZE.
+	ExplainAnalyze = "analyze"
 )
 
 var userAgent = fmt.Sprintf("loki-logcli/%s", build.Version)
@@ -84,6 +89,7 @@ type DefaultClient struct {
 	BearerTokenFile  string
 	Retries          int
 	QueryTags        string
+	Explain          string
 	NoCache          bool
 	AuthHeader       string
 	ProxyURL         string
@@ -414,6 +420,10 @@ func (c *DefaultClient) getHTTPRequestHeader() (http.Header, error) {
 		h.Set(HTTPQueryTags, c.QueryTags)
 	}
 
+	if c.Explain != "" {
+		h.Set(HTTPExplain, c.Explain)
+	}
+
 	if (c.Username != "" || c.Password != "") && (len(c.BearerToken) > 0 || len(c.BearerTokenFile) > 0) {
 		return nil, fmt.Errorf("at most one of HTTP basic auth (username/password), bearer-token & bearer-token-file is allowed to be configured")
 	}
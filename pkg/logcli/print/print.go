@@ -217,6 +217,17 @@ func (r *QueryResultPrinter) PrintStats(stats stats.Result) {
 	stats.Log(kvLogger{Writer: writer})
 }
 
+// PrintExplain prints the analyzed query plans found in the statistics of a
+// query response to stderr.
+func (r *QueryResultPrinter) PrintExplain(stats stats.Result) {
+	for _, plan := range stats.Plans {
+		fmt.Fprint(os.Stderr, plan)
+	}
+	if len(stats.Plans) == 0 {
+		fmt.Fprintln(os.Stderr, "No query plan returned: the query was not executed by the new query engine.")
+	}
+}
+
 func matchLabels(on bool, l loghttp.LabelSet, names []string) loghttp.LabelSet {
 	return util.MatchLabels(on, l, names)
 }
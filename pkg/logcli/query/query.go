@@ -57,6 +57,10 @@ type Query struct {
 	FetchSchemaFromStorage bool
 	SchemaStore            string
 
+	// If true, the query is executed with EXPLAIN ANALYZE and the analyzed
+	// plan returned by Loki is printed to stderr.
+	Explain bool
+
 	// Parallelization parameters.
 
 	// The duration of each part/job.
@@ -96,6 +100,12 @@ func (q *Query) DoQuery(c client.Client, out output.LogOutput, statistics bool)
 		return
 	}
 
+	if q.Explain {
+		if dc, ok := c.(*client.DefaultClient); ok {
+			dc.Explain = client.ExplainAnalyze
+		}
+	}
+
 	d := q.resultsDirection()
 
 	var resp *loghttp.QueryResponse
@@ -129,6 +139,9 @@ func (q *Query) DoQuery(c client.Client, out output.LogOutput, statistics bool)
 		if statistics {
 			result.PrintStats(resp.Data.Statistics)
 		}
+		if q.Explain {
+			result.PrintExplain(resp.Data.Statistics)
+		}
 		_, _ = result.PrintResult(resp.Data.Result, out, nil)
 	} else {
 		unlimited := q.Limit == 0
@@ -160,6 +173,9 @@ func (q *Query) DoQuery(c client.Client, out output.LogOutput, statistics bool)
 			if statistics {
 				result.PrintStats(resp.Data.Statistics)
 			}
+			if q.Explain {
+				result.PrintExplain(resp.Data.Statistics)
+			}
 
 			resultLength, lastEntry = result.PrintResult(resp.Data.Result, out, lastEntry)
 			// Was not a log stream query, or no results, no more batching
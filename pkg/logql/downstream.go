@@ -707,6 +707,22 @@ func (ev *DownstreamEvaluator) NewStepEvaluator(
 			return nil, fmt.Errorf("unexpected matrix type: got (%T), want (CountMinSketchVector)", results[0].Data)
 		}
 		return NewCountMinSketchVectorStepEvaluator(vector), nil
+	case *syntax.HistogramQuantileExpr:
+		concat, ok := e.Left.(*ConcatSampleExpr)
+		if !ok {
+			return ev.defaultEvaluator.NewStepEvaluator(ctx, nextEvFactory, e, params)
+		}
+		// The same bucket may be returned by multiple shards, each holding a
+		// partial count. Buckets with equal labels are summed up before the
+		// quantile is calculated:
+		// histogram_quantile(0.99, _ ++ _) -> histogram_quantile(0.99, sum without() (_ ++ _))
+		merged := *e
+		merged.Left = &syntax.VectorAggregationExpr{
+			Left:      concat,
+			Grouping:  &syntax.Grouping{Without: true},
+			Operation: syntax.OpTypeSum,
+		}
+		return ev.defaultEvaluator.NewStepEvaluator(ctx, nextEvFactory, &merged, params)
 	default:
 		return ev.defaultEvaluator.NewStepEvaluator(ctx, nextEvFactory, e, params)
 	}
@@ -55,6 +55,16 @@ func TestMappingEquivalence(t *testing.T) {
 		{`avg(rate({a=~".+"}[1s])) by (a)`, true, nil},
 		{`1 + sum by (cluster) (rate({a=~".+"}[1s]))`, false, nil},
 		{`sum(max(rate({a=~".+"}[1s])))`, false, nil},
+		{`max_over_time(sum by (a) (rate({a=~".+"}[1s]))[5s:2s])`, false, nil},
+		{`avg_over_time(sum(count_over_time({a=~".+"}[2s]))[4s:] offset 1s)`, false, nil},
+		{`minute(sum by (a) (rate({a=~".+"}[1s])) * 1e6)`, false, nil},
+		{`sum by (a) (count_over_time({a=~".+"}[1s])) and on() day_of_month() > 0`, false, nil},
+		{`sum by (a) (count_over_time({a=~".+"}[1s])) * year()`, false, nil},
+		{`histogram_quantile(0.9, sum by (le) (count_over_time({a=~".+"} | label_format le="{{ if eq .a \"0\" }}+Inf{{ else }}{{ .a }}{{ end }}" [1s])))`, false, nil},
+		{`histogram_quantile(0.5, sum by (le) (sum_over_time({a=~".+"} | logfmt | label_format le="{{ if eq .b \"0\" }}+Inf{{ else }}{{ .b }}{{ end }}" | unwrap value [1s])))`, true, nil},
+		{`histogram_quantile(0.5, sum by (a, le) (count_over_time({a=~".+"} | label_format le="{{ if eq .b \"0\" }}+Inf{{ else }}{{ .b }}{{ end }}" [1s])))`, false, nil},
+		{`sum by (a) (rate({a=~".+"}[2s] @ 5))`, false, nil},
+		{`sum(count_over_time({a=~".+"}[3s] @ end())) / sum(count_over_time({a=~".+"}[3s] @ start() offset 1s))`, false, nil},
 		{`max(count(rate({a=~".+"}[1s])))`, false, nil},
 		{`max(sum by (cluster) (rate({a=~".+"}[1s]))) / count(rate({a=~".+"}[1s]))`, false, nil},
 		{`sum(rate({a=~".+"} |= "foo" != "foo"[1s]) or vector(1))`, false, nil},
@@ -689,6 +699,18 @@ func TestRangeMappingEquivalence(t *testing.T) {
 		// label_replace
 		{`label_replace(sum by (a) (count_over_time({a=~".+"}[3s])), "", "", "", "")`, time.Second},
 		{`label_replace(sum by (a) (count_over_time({a=~".+"}[3s])), "foo", "$1", "a", "(.*)")`, time.Second},
+
+		// time functions
+		{`timestamp(sum by (a) (count_over_time({a=~".+"}[3s])))`, time.Second},
+
+		// histogram_quantile
+		{`histogram_quantile(0.9, sum by (le) (count_over_time({a=~".+"} | label_format le="{{ if eq .a \"0\" }}+Inf{{ else }}{{ .a }}{{ end }}" [3s])))`, time.Second},
+
+		// subqueries
+		{`max_over_time(sum by (a) (count_over_time({a=~".+"}[3s]))[5s:2s])`, time.Second},
+		{`avg_over_time(sum(rate({a=~".+"}[3s]))[4s:] offset 1s)`, time.Second},
+		{`sum by (a) (count_over_time({a=~".+"}[3s] @ 5))`, time.Second},
+		{`sum(rate({a=~".+"}[3s] @ end() offset 1s))`, time.Second},
 	} {
 		q := NewMockQuerier(
 			shards,
@@ -26,6 +26,7 @@ import (
 	"github.com/prometheus/prometheus/promql"
 	promql_parser "github.com/prometheus/prometheus/promql/parser"
 
+	"github.com/grafana/dskit/flagext"
 	"github.com/grafana/dskit/tenant"
 
 	"github.com/grafana/loki/v3/pkg/iter"
@@ -167,6 +168,12 @@ type EngineOpts struct {
 	// Batch size of the v2 execution engine.
 	BatchSize int `yaml:"batch_size" category:"experimental"`
 
+	// Maximum memory the aggregations of a single query may hold in the v2 execution engine.
+	MemoryLimit flagext.Bytes `yaml:"memory_limit" category:"experimental"`
+
+	// Directory the v2 execution engine spills aggregations to once they exceed MemoryLimit.
+	SpillDirectory string `yaml:"spill_directory" category:"experimental"`
+
 	// CataloguePath is the path to the catalogue in the object store.
 	CataloguePath string `yaml:"-" doc:"hidden" category:"experimental"`
 }
@@ -176,6 +183,8 @@ func (opts *EngineOpts) RegisterFlagsWithPrefix(prefix string, f *flag.FlagSet)
 	f.IntVar(&opts.MaxCountMinSketchHeapSize, prefix+"max-count-min-sketch-heap-size", 10_000, "The maximum number of labels the heap of a topk query using a count min sketch can track.")
 	f.BoolVar(&opts.EnableV2Engine, prefix+"enable-v2-engine", false, "Experimental: Enable next generation query engine for supported queries.")
 	f.IntVar(&opts.BatchSize, prefix+"batch-size", 100, "Experimental: Batch size of the next generation query engine.")
+	f.Var(&opts.MemoryLimit, prefix+"memory-limit", "Experimental: Maximum memory the aggregations of a single query may hold in the next generation query engine. Aggregations exceeding the limit are spilled to the spill directory if configured, otherwise the query fails. 0 to disable.")
+	f.StringVar(&opts.SpillDirectory, prefix+"spill-directory", "", "Experimental: Directory the next generation query engine spills aggregations to once they exceed the memory limit. Spilling is disabled if empty.")
 	f.StringVar(&opts.CataloguePath, prefix+"catalogue-path", "", "The path to the catalogue in the object store.")
 	// Log executing query by default
 	opts.LogExecutingQuery = true
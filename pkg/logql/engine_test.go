@@ -2312,6 +2312,278 @@ func TestEngine_RangeQuery(t *testing.T) {
 	}
 }
 
+func TestEngine_Subquery(t *testing.T) {
+	t.Parallel()
+
+	// count_over_time({app="foo"}[1m]) is 10 at 60s, 30 at 120s and 20 at 180s.
+	var samples []logproto.Sample
+	for i, n := range []int64{10, 30, 20} {
+		for j := int64(0); j < n; j++ {
+			ts := time.Unix(int64(i)*60+j+1, 0)
+			samples = append(samples, logproto.Sample{Timestamp: ts.UnixNano(), Hash: uint64(ts.Unix()), Value: 1})
+		}
+	}
+	data := [][]logproto.Series{{{Labels: `{app="foo"}`, Samples: samples}}}
+	params := []SelectSampleParams{
+		{&logproto.SampleQueryRequest{Selector: `count_over_time({app="foo"}[1m])`}},
+	}
+	metric := labels.FromStrings("app", "foo")
+
+	for _, test := range []struct {
+		qs         string
+		start, end time.Time
+		step       time.Duration
+
+		expected promql_parser.Value
+	}{
+		{
+			qs:    `max_over_time(count_over_time({app="foo"}[1m])[2m:1m])`,
+			start: time.Unix(120, 0), end: time.Unix(180, 0), step: time.Minute,
+			expected: promql.Matrix{
+				{Metric: metric, Floats: []promql.FPoint{{T: 120000, F: 30}, {T: 180000, F: 30}}},
+			},
+		},
+		{
+			qs:    `sum_over_time(count_over_time({app="foo"}[1m])[2m:1m])`,
+			start: time.Unix(120, 0), end: time.Unix(180, 0), step: time.Minute,
+			expected: promql.Matrix{
+				{Metric: metric, Floats: []promql.FPoint{{T: 120000, F: 40}, {T: 180000, F: 50}}},
+			},
+		},
+		{
+			// The resolution defaults to the step of the query.
+			qs:    `min_over_time(count_over_time({app="foo"}[1m])[3m:])`,
+			start: time.Unix(180, 0), end: time.Unix(180, 0), step: time.Minute,
+			expected: promql.Matrix{
+				{Metric: metric, Floats: []promql.FPoint{{T: 180000, F: 10}}},
+			},
+		},
+		{
+			qs:    `sum_over_time(count_over_time({app="foo"}[1m])[2m:])`,
+			start: time.Unix(180, 0), end: time.Unix(180, 0),
+			expected: promql.Vector{
+				{Metric: metric, T: 180000, F: 50},
+			},
+		},
+		{
+			qs:    `last_over_time(count_over_time({app="foo"}[1m])[1m:1m] offset 1m)`,
+			start: time.Unix(180, 0), end: time.Unix(180, 0),
+			expected: promql.Vector{
+				{Metric: metric, T: 180000, F: 30},
+			},
+		},
+	} {
+		t.Run(test.qs, func(t *testing.T) {
+			t.Parallel()
+
+			eng := NewEngine(EngineOpts{}, newQuerierRecorder(t, data, params), NoLimits, log.NewNopLogger())
+
+			params, err := NewLiteralParams(test.qs, test.start, test.end, test.step, 0, logproto.FORWARD, 0, nil, nil)
+			require.NoError(t, err)
+			res, err := eng.Query(params).Exec(user.InjectOrgID(context.Background(), "fake"))
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, res.Data)
+		})
+	}
+}
+
+func TestEngine_AtModifier(t *testing.T) {
+	t.Parallel()
+
+	// count_over_time({app="foo"}[1m]) is 10 at 60s, 30 at 120s and 20 at 180s.
+	var samples []logproto.Sample
+	for i, n := range []int64{10, 30, 20} {
+		for j := int64(0); j < n; j++ {
+			ts := time.Unix(int64(i)*60+j+1, 0)
+			samples = append(samples, logproto.Sample{Timestamp: ts.UnixNano(), Hash: uint64(ts.Unix()), Value: 1})
+		}
+	}
+	data := [][]logproto.Series{{{Labels: `{app="foo"}`, Samples: samples}}}
+	metric := labels.FromStrings("app", "foo")
+
+	for _, test := range []struct {
+		qs         string
+		selector   string // range aggregation selecting the samples
+		start, end time.Time
+		step       time.Duration
+
+		expected promql_parser.Value
+	}{
+		{
+			qs:       `count_over_time({app="foo"}[1m] @ 120)`,
+			selector: `count_over_time({app="foo"}[1m] @ 120)`,
+			start:    time.Unix(60, 0), end: time.Unix(180, 0), step: time.Minute,
+			expected: promql.Matrix{
+				{Metric: metric, Floats: []promql.FPoint{{T: 60000, F: 30}, {T: 120000, F: 30}, {T: 180000, F: 30}}},
+			},
+		},
+		{
+			qs:       `count_over_time({app="foo"}[1m] @ start())`,
+			selector: `count_over_time({app="foo"}[1m] @ start())`,
+			start:    time.Unix(120, 0), end: time.Unix(180, 0), step: time.Minute,
+			expected: promql.Matrix{
+				{Metric: metric, Floats: []promql.FPoint{{T: 120000, F: 30}, {T: 180000, F: 30}}},
+			},
+		},
+		{
+			qs:       `count_over_time({app="foo"}[1m] @ end())`,
+			selector: `count_over_time({app="foo"}[1m] @ end())`,
+			start:    time.Unix(60, 0), end: time.Unix(180, 0), step: time.Minute,
+			expected: promql.Matrix{
+				{Metric: metric, Floats: []promql.FPoint{{T: 60000, F: 20}, {T: 120000, F: 20}, {T: 180000, F: 20}}},
+			},
+		},
+		{
+			// The range is pinned before the offset is applied.
+			qs:       `count_over_time({app="foo"}[1m] @ 180 offset 1m)`,
+			selector: `count_over_time({app="foo"}[1m] @ 180 offset 1m)`,
+			start:    time.Unix(60, 0), end: time.Unix(60, 0),
+			expected: promql.Vector{
+				{Metric: metric, T: 60000, F: 30},
+			},
+		},
+		{
+			qs:       `sum(count_over_time({app="foo"}[1m] @ 60)) / sum(count_over_time({app="foo"}[1m]))`,
+			selector: `count_over_time({app="foo"}[1m] @ 60)`,
+			start:    time.Unix(120, 0), end: time.Unix(180, 0), step: time.Minute,
+			expected: promql.Matrix{
+				{Metric: labels.EmptyLabels(), Floats: []promql.FPoint{{T: 120000, F: 10.0 / 30}, {T: 180000, F: 10.0 / 20}}},
+			},
+		},
+	} {
+		t.Run(test.qs, func(t *testing.T) {
+			t.Parallel()
+
+			params := []SelectSampleParams{
+				{&logproto.SampleQueryRequest{Selector: test.selector}},
+				{&logproto.SampleQueryRequest{Selector: `sum(count_over_time({app="foo"}[1m]))`}},
+			}
+			eng := NewEngine(EngineOpts{}, newQuerierRecorder(t, append(data, data...), params), NoLimits, log.NewNopLogger())
+
+			q, err := NewLiteralParams(test.qs, test.start, test.end, test.step, 0, logproto.FORWARD, 0, nil, nil)
+			require.NoError(t, err)
+			res, err := eng.Query(q).Exec(user.InjectOrgID(context.Background(), "fake"))
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, res.Data)
+		})
+	}
+}
+
+func TestEngine_HistogramQuantile(t *testing.T) {
+	t.Parallel()
+
+	// The buckets of {app="foo"} hold 10, 90 and 100 observations.
+	ts := time.Unix(30, 0).UnixNano()
+	series := []logproto.Series{
+		{Labels: `{app="foo", le="0.1"}`, Samples: []logproto.Sample{{Timestamp: ts, Hash: 1, Value: 10}}},
+		{Labels: `{app="foo", le="0.5"}`, Samples: []logproto.Sample{{Timestamp: ts, Hash: 2, Value: 90}}},
+		{Labels: `{app="foo", le="+Inf"}`, Samples: []logproto.Sample{{Timestamp: ts, Hash: 3, Value: 100}}},
+		{Labels: `{app="foo"}`, Samples: []logproto.Sample{{Timestamp: ts, Hash: 4, Value: 1000}}},
+	}
+	data := [][]logproto.Series{series, series}
+	params := []SelectSampleParams{
+		{&logproto.SampleQueryRequest{Selector: `sum by (le) (sum_over_time({app="foo"} | unwrap count[1m]))`}},
+		{&logproto.SampleQueryRequest{Selector: `sum by (app, le) (sum_over_time({app="foo"} | unwrap count[1m]))`}},
+	}
+
+	for _, test := range []struct {
+		qs       string
+		expected promql_parser.Value
+	}{
+		{
+			qs:       `histogram_quantile(0.1, sum by (le) (sum_over_time({app="foo"} | unwrap count [1m])))`,
+			expected: promql.Vector{{Metric: labels.EmptyLabels(), T: 60000, F: 0.1}},
+		},
+		{
+			qs:       `histogram_quantile(0.9, sum by (le) (sum_over_time({app="foo"} | unwrap count [1m])))`,
+			expected: promql.Vector{{Metric: labels.EmptyLabels(), T: 60000, F: 0.5}},
+		},
+		{
+			// Quantiles in the +Inf bucket are the upper bound of the second highest bucket.
+			qs:       `histogram_quantile(0.95, sum by (app, le) (sum_over_time({app="foo"} | unwrap count [1m])))`,
+			expected: promql.Vector{{Metric: labels.FromStrings("app", "foo"), T: 60000, F: 0.5}},
+		},
+	} {
+		t.Run(test.qs, func(t *testing.T) {
+			t.Parallel()
+
+			eng := NewEngine(EngineOpts{}, newQuerierRecorder(t, data, params), NoLimits, log.NewNopLogger())
+
+			params, err := NewLiteralParams(test.qs, time.Unix(60, 0), time.Unix(60, 0), 0, 0, logproto.FORWARD, 0, nil, nil)
+			require.NoError(t, err)
+			res, err := eng.Query(params).Exec(user.InjectOrgID(context.Background(), "fake"))
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, res.Data)
+		})
+	}
+}
+
+func TestEngine_TimeFunctions(t *testing.T) {
+	t.Parallel()
+
+	// Thursday, 29 February 2024 13:45:00 UTC
+	now := time.Unix(1709214300, 0)
+	ts := now.Add(-30 * time.Second).UnixNano()
+	data := [][]logproto.Series{{
+		{Labels: `{app="foo"}`, Samples: []logproto.Sample{{Timestamp: ts, Hash: 1, Value: 1}}},
+	}}
+	params := []SelectSampleParams{
+		{&logproto.SampleQueryRequest{Selector: `count_over_time({app="foo"}[1m])`}},
+	}
+	metric := labels.FromStrings("app", "foo")
+
+	for _, test := range []struct {
+		qs         string
+		start, end time.Time
+		step       time.Duration
+
+		expected promql_parser.Value
+	}{
+		{qs: `hour()`, start: now, end: now, expected: promql.Vector{{Metric: labels.EmptyLabels(), T: now.UnixMilli(), F: 13}}},
+		{qs: `minute()`, start: now, end: now, expected: promql.Vector{{Metric: labels.EmptyLabels(), T: now.UnixMilli(), F: 45}}},
+		{qs: `day_of_week()`, start: now, end: now, expected: promql.Vector{{Metric: labels.EmptyLabels(), T: now.UnixMilli(), F: 4}}},
+		{qs: `day_of_month()`, start: now, end: now, expected: promql.Vector{{Metric: labels.EmptyLabels(), T: now.UnixMilli(), F: 29}}},
+		{qs: `days_in_month()`, start: now, end: now, expected: promql.Vector{{Metric: labels.EmptyLabels(), T: now.UnixMilli(), F: 29}}},
+		{qs: `month()`, start: now, end: now, expected: promql.Vector{{Metric: labels.EmptyLabels(), T: now.UnixMilli(), F: 2}}},
+		{qs: `year()`, start: now, end: now, expected: promql.Vector{{Metric: labels.EmptyLabels(), T: now.UnixMilli(), F: 2024}}},
+		{
+			// The values of the argument are interpreted as Unix timestamps.
+			qs: `hour(vector(1709181000))`, start: now, end: now,
+			expected: promql.Vector{{Metric: labels.EmptyLabels(), T: now.UnixMilli(), F: 4}},
+		},
+		{
+			qs: `timestamp(count_over_time({app="foo"}[1m]))`, start: now, end: now,
+			expected: promql.Vector{{Metric: metric, T: now.UnixMilli(), F: 1709214300}},
+		},
+		{
+			qs: `count_over_time({app="foo"}[1m]) and on() hour() >= 9`, start: now, end: now,
+			expected: promql.Vector{{Metric: metric, T: now.UnixMilli(), F: 1}},
+		},
+		{
+			qs: `hour()`, start: now.Add(-time.Hour), end: now, step: 30 * time.Minute,
+			expected: promql.Matrix{
+				{Metric: labels.EmptyLabels(), Floats: []promql.FPoint{
+					{T: now.Add(-time.Hour).UnixMilli(), F: 12},
+					{T: now.Add(-30 * time.Minute).UnixMilli(), F: 13},
+					{T: now.UnixMilli(), F: 13},
+				}},
+			},
+		},
+	} {
+		t.Run(test.qs, func(t *testing.T) {
+			t.Parallel()
+
+			eng := NewEngine(EngineOpts{}, newQuerierRecorder(t, data, params), NoLimits, log.NewNopLogger())
+
+			params, err := NewLiteralParams(test.qs, test.start, test.end, test.step, 0, logproto.FORWARD, 0, nil, nil)
+			require.NoError(t, err)
+			res, err := eng.Query(params).Exec(user.InjectOrgID(context.Background(), "fake"))
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, res.Data)
+		})
+	}
+}
+
 func TestEngine_Variants_InstantQuery(t *testing.T) {
 	t.Parallel()
 
@@ -333,7 +333,7 @@ func (ev *DefaultEvaluator) NewStepEvaluator(
 ) (StepEvaluator, error) {
 	switch e := expr.(type) {
 	case *syntax.VectorAggregationExpr:
-		if rangExpr, ok := e.Left.(*syntax.RangeAggregationExpr); ok && e.Operation == syntax.OpTypeSum {
+		if rangExpr, ok := e.Left.(*syntax.RangeAggregationExpr); ok && e.Operation == syntax.OpTypeSum && rangExpr.Left.At == nil {
 			// if range expression is wrapped with a vector expression
 			// we should send the vector expression for allowing reducing labels at the source.
 			nextEvFactory = SampleEvaluatorFunc(func(ctx context.Context, _ SampleEvaluatorFactory, _ syntax.SampleExpr, _ Params) (StepEvaluator, error) {
@@ -362,6 +362,11 @@ func (ev *DefaultEvaluator) NewStepEvaluator(
 	case *CountMinSketchEvalExpr:
 		return NewCountMinSketchEvalStepEvaluator(ctx, nextEvFactory, e, q)
 	case *syntax.RangeAggregationExpr:
+		if at := e.Left.At; at != nil {
+			if ts := at.Time(q.Start(), q.End()); !ts.Equal(q.Start()) || !ts.Equal(q.End()) {
+				return newAtModifierEvaluator(ctx, nextEvFactory, e, q, ts)
+			}
+		}
 		it, err := ev.querier.SelectSamples(ctx, SelectSampleParams{
 			&logproto.SampleQueryRequest{
 				// extend startTs backwards by step
@@ -385,6 +390,12 @@ func (ev *DefaultEvaluator) NewStepEvaluator(
 		return newBinOpStepEvaluator(ctx, nextEvFactory, e, q)
 	case *syntax.LabelReplaceExpr:
 		return newLabelReplaceEvaluator(ctx, nextEvFactory, e, q)
+	case *syntax.HistogramQuantileExpr:
+		return newHistogramQuantileEvaluator(ctx, nextEvFactory, e, q)
+	case *syntax.TimeFunctionExpr:
+		return newTimeFunctionEvaluator(ctx, nextEvFactory, e, q)
+	case *syntax.SubqueryExpr:
+		return newSubqueryEvaluator(ctx, nextEvFactory, e, q)
 	case *syntax.VectorExpr:
 		val, err := e.Value()
 		if err != nil {
@@ -1314,6 +1325,397 @@ func (e *LabelReplaceEvaluator) Error() error {
 	return e.nextEvaluator.Error()
 }
 
+// newHistogramQuantileEvaluator evaluates the quantile of the histograms in the
+// results of the inner expression of expr.
+func newHistogramQuantileEvaluator(
+	ctx context.Context,
+	evFactory SampleEvaluatorFactory,
+	expr *syntax.HistogramQuantileExpr,
+	q Params,
+) (*HistogramQuantileEvaluator, error) {
+	nextEvaluator, err := evFactory.NewStepEvaluator(ctx, evFactory, expr.Left, q)
+	if err != nil {
+		return nil, err
+	}
+
+	return &HistogramQuantileEvaluator{
+		nextEvaluator: nextEvaluator,
+		expr:          expr,
+		histograms:    map[uint64]*bucketSeries{},
+		buf:           make([]byte, 0, 1024),
+	}, nil
+}
+
+// HistogramQuantileEvaluator calculates the quantile of the histograms formed
+// by the buckets in the results of the next evaluator at each step. Samples
+// without a valid `le` label are ignored.
+type HistogramQuantileEvaluator struct {
+	nextEvaluator StepEvaluator
+	expr          *syntax.HistogramQuantileExpr
+	histograms    map[uint64]*bucketSeries // reused at each step
+	order         []uint64                 // hashes of histograms in the order they were found
+	buf           []byte
+}
+
+// bucketSeries holds the buckets of a histogram at a single step.
+type bucketSeries struct {
+	metric  labels.Labels
+	buckets promql.Buckets
+}
+
+func (e *HistogramQuantileEvaluator) Next() (bool, int64, StepResult) {
+	next, ts, r := e.nextEvaluator.Next()
+	if !next {
+		return false, 0, SampleVector{}
+	}
+
+	for _, h := range e.histograms {
+		h.buckets = h.buckets[:0]
+	}
+	e.order = e.order[:0]
+
+	var hash uint64
+	for _, s := range r.SampleVector() {
+		upperBound, err := strconv.ParseFloat(s.Metric.Get(labels.BucketLabel), 64)
+		if err != nil {
+			continue
+		}
+
+		hash, e.buf = s.Metric.HashWithoutLabels(e.buf, labels.BucketLabel)
+		h, ok := e.histograms[hash]
+		if !ok {
+			h = &bucketSeries{metric: labels.NewBuilder(s.Metric).Del(labels.BucketLabel).Labels()}
+			e.histograms[hash] = h
+		}
+		if len(h.buckets) == 0 {
+			e.order = append(e.order, hash)
+		}
+		h.buckets = append(h.buckets, promql.Bucket{UpperBound: upperBound, Count: s.F})
+	}
+
+	vec := make(promql.Vector, 0, len(e.order))
+	for _, hash := range e.order {
+		h := e.histograms[hash]
+		q, _, _ := promql.BucketQuantile(e.expr.Quantile, h.buckets)
+		vec = append(vec, promql.Sample{T: ts, F: q, Metric: h.metric})
+	}
+	return next, ts, SampleVector(vec)
+}
+
+func (e *HistogramQuantileEvaluator) Close() error {
+	return e.nextEvaluator.Close()
+}
+
+func (e *HistogramQuantileEvaluator) Error() error {
+	return e.nextEvaluator.Error()
+}
+
+// timeFunctions implement the time functions of LogQL apart from timestamp().
+// Each returns a component of a time in UTC.
+var timeFunctions = map[string]func(t time.Time) float64{
+	syntax.OpHour:       func(t time.Time) float64 { return float64(t.Hour()) },
+	syntax.OpMinute:     func(t time.Time) float64 { return float64(t.Minute()) },
+	syntax.OpDayOfWeek:  func(t time.Time) float64 { return float64(t.Weekday()) },
+	syntax.OpDayOfMonth: func(t time.Time) float64 { return float64(t.Day()) },
+	syntax.OpDaysInMonth: func(t time.Time) float64 {
+		return float64(32 - time.Date(t.Year(), t.Month(), 32, 0, 0, 0, 0, time.UTC).Day())
+	},
+	syntax.OpMonth: func(t time.Time) float64 { return float64(t.Month()) },
+	syntax.OpYear:  func(t time.Time) float64 { return float64(t.Year()) },
+}
+
+// newTimeFunctionEvaluator evaluates the time function of expr. Without an
+// argument, the function is applied to the time of each step.
+func newTimeFunctionEvaluator(
+	ctx context.Context,
+	evFactory SampleEvaluatorFactory,
+	expr *syntax.TimeFunctionExpr,
+	q Params,
+) (*TimeFunctionEvaluator, error) {
+	fn, ok := timeFunctions[expr.Operation]
+	if !ok && expr.Operation != syntax.OpTimestamp {
+		return nil, fmt.Errorf("unsupported time function %s", expr.Operation)
+	}
+
+	var nextEvaluator StepEvaluator
+	if expr.Left == nil {
+		nextEvaluator = newVectorIterator(0, q.Step().Milliseconds(), q.Start().UnixMilli(), q.End().UnixMilli())
+	} else {
+		var err error
+		nextEvaluator, err = evFactory.NewStepEvaluator(ctx, evFactory, expr.Left, q)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &TimeFunctionEvaluator{
+		nextEvaluator: nextEvaluator,
+		expr:          expr,
+		fn:            fn,
+	}, nil
+}
+
+// TimeFunctionEvaluator applies a time function to the results of the next
+// evaluator.
+type TimeFunctionEvaluator struct {
+	nextEvaluator StepEvaluator
+	expr          *syntax.TimeFunctionExpr
+	fn            func(t time.Time) float64 // nil for timestamp()
+}
+
+func (e *TimeFunctionEvaluator) Next() (bool, int64, StepResult) {
+	next, ts, r := e.nextEvaluator.Next()
+	if !next {
+		return false, 0, SampleVector{}
+	}
+
+	vec := r.SampleVector()
+	for i, s := range vec {
+		switch {
+		case e.fn == nil:
+			// timestamp() returns the timestamp of the sample in seconds.
+			vec[i].F = float64(s.T) / 1000
+		case e.expr.Left == nil:
+			vec[i].F = e.fn(time.UnixMilli(ts).UTC())
+		default:
+			vec[i].F = e.fn(time.Unix(int64(s.F), 0).UTC())
+		}
+	}
+	return next, ts, SampleVector(vec)
+}
+
+func (e *TimeFunctionEvaluator) Close() error {
+	return e.nextEvaluator.Close()
+}
+
+func (e *TimeFunctionEvaluator) Error() error {
+	return e.nextEvaluator.Error()
+}
+
+// defaultSubqueryStep is the resolution of subqueries without a resolution in
+// instant queries, which have no step of their own.
+const defaultSubqueryStep = time.Minute
+
+// subqueryParams overrides the time range and the step of the params of a
+// query with the ones at which the inner query of a subquery, or a range
+// pinned by the `@` modifier, is evaluated.
+type subqueryParams struct {
+	Params
+	start, end time.Time
+	step       time.Duration
+}
+
+func (p subqueryParams) Start() time.Time    { return p.start }
+func (p subqueryParams) End() time.Time      { return p.end }
+func (p subqueryParams) Step() time.Duration { return p.step }
+
+// newSubqueryEvaluator evaluates the inner query of expr at the resolution of
+// the subquery and aggregates its results over the range of the subquery at
+// each step of the query.
+func newSubqueryEvaluator(
+	ctx context.Context,
+	evFactory SampleEvaluatorFactory,
+	expr *syntax.SubqueryExpr,
+	q Params,
+) (StepEvaluator, error) {
+	step := expr.Step
+	if step == 0 {
+		step = q.Step()
+	}
+	if step <= 0 {
+		step = defaultSubqueryStep
+	}
+
+	// The inner query is evaluated at multiples of the step of the subquery,
+	// so that its results don't depend on the start of the query. The lower
+	// bound of the range is exclusive.
+	start := q.Start().Add(-expr.Offset).Add(-expr.Range).UnixNano()
+	end := q.End().Add(-expr.Offset)
+	first := start - start%step.Nanoseconds() + step.Nanoseconds()
+
+	agg, err := subqueryAggregator(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	// The inner query isn't evaluated at all if none of its steps are within
+	// the range of the query.
+	var samples iter.SampleIterator = iter.NoopSampleIterator
+	if first <= end.UnixNano() {
+		inner, err := evFactory.NewStepEvaluator(ctx, evFactory, expr.Left, subqueryParams{
+			Params: q,
+			start:  time.Unix(0, first),
+			end:    end,
+			step:   step,
+		})
+		if err != nil {
+			return nil, err
+		}
+		samples = &subquerySampleIterator{evaluator: inner}
+	}
+
+	outerStep := q.Step().Nanoseconds()
+	if outerStep == 0 {
+		outerStep = 1
+	}
+	it := newBatchRangeVectorIterator(
+		iter.NewPeekingSampleIterator(samples),
+		agg,
+		expr.Range.Nanoseconds(),
+		outerStep,
+		q.Start().Add(-expr.Offset).UnixNano(), end.UnixNano(), expr.Offset.Nanoseconds(),
+	)
+
+	if expr.Operation == syntax.OpRangeTypeAbsent {
+		absentLabels, err := absentLabels(expr)
+		if err != nil {
+			_ = it.Close()
+			return nil, err
+		}
+		return &AbsentRangeVectorEvaluator{
+			iter: it,
+			lbs:  absentLabels,
+		}, nil
+	}
+	return &RangeVectorEvaluator{
+		iter: it,
+	}, nil
+}
+
+// subqueryAggregator returns the aggregation of the samples of the inner query
+// of a subquery within its range.
+func subqueryAggregator(expr *syntax.SubqueryExpr) (BatchRangeVectorAggregator, error) {
+	switch expr.Operation {
+	case syntax.OpRangeTypeCount:
+		return countOverTime, nil
+	case syntax.OpRangeTypeSum:
+		return sumOverTime, nil
+	case syntax.OpRangeTypeAvg:
+		return avgOverTime, nil
+	case syntax.OpRangeTypeMax:
+		return maxOverTime, nil
+	case syntax.OpRangeTypeMin:
+		return minOverTime, nil
+	case syntax.OpRangeTypeStddev:
+		return stddevOverTime, nil
+	case syntax.OpRangeTypeStdvar:
+		return stdvarOverTime, nil
+	case syntax.OpRangeTypeQuantile:
+		return quantileOverTime(*expr.Params), nil
+	case syntax.OpRangeTypeFirst:
+		return first, nil
+	case syntax.OpRangeTypeLast:
+		return last, nil
+	case syntax.OpRangeTypeAbsent:
+		return one, nil
+	default:
+		return nil, fmt.Errorf(syntax.UnsupportedErr, expr.Operation)
+	}
+}
+
+// subquerySampleIterator iterates over the samples of the results of the inner
+// query of a subquery in the order of their timestamps.
+type subquerySampleIterator struct {
+	evaluator StepEvaluator
+
+	samples []promql.Sample
+	cur     promql.Sample
+}
+
+func (it *subquerySampleIterator) Next() bool {
+	for len(it.samples) == 0 {
+		next, _, r := it.evaluator.Next()
+		if !next {
+			return false
+		}
+		it.samples = r.SampleVector()
+	}
+	it.cur, it.samples = it.samples[0], it.samples[1:]
+	return true
+}
+
+func (it *subquerySampleIterator) At() logproto.Sample {
+	return logproto.Sample{
+		Timestamp: it.cur.T * int64(time.Millisecond),
+		Value:     it.cur.F,
+		Hash:      it.cur.Metric.Hash(),
+	}
+}
+
+func (it *subquerySampleIterator) Labels() string     { return it.cur.Metric.String() }
+func (it *subquerySampleIterator) StreamHash() uint64 { return it.cur.Metric.Hash() }
+func (it *subquerySampleIterator) Err() error         { return it.evaluator.Error() }
+func (it *subquerySampleIterator) Close() error       { return it.evaluator.Close() }
+
+// newAtModifierEvaluator evaluates expr once at the time ts pinned by the `@`
+// modifier of its range and returns the result at each step of the query.
+func newAtModifierEvaluator(
+	ctx context.Context,
+	evFactory SampleEvaluatorFactory,
+	expr *syntax.RangeAggregationExpr,
+	q Params,
+	ts time.Time,
+) (StepEvaluator, error) {
+	pinned, err := evFactory.NewStepEvaluator(ctx, evFactory, expr, subqueryParams{
+		Params: q,
+		start:  ts,
+		end:    ts,
+		step:   q.Step(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	stepMs := q.Step().Milliseconds()
+	if stepMs == 0 {
+		stepMs = 1
+	}
+	return &AtModifierEvaluator{
+		pinned:    pinned,
+		atMs:      ts.UnixMilli(),
+		stepMs:    stepMs,
+		endMs:     q.End().UnixMilli(),
+		currentMs: q.Start().UnixMilli() - stepMs,
+	}, nil
+}
+
+// AtModifierEvaluator repeats the single step of a range aggregation pinned by
+// the `@` modifier at each step of the query.
+type AtModifierEvaluator struct {
+	pinned    StepEvaluator
+	evaluated bool
+	vec       promql.Vector
+
+	atMs, stepMs, endMs, currentMs int64
+}
+
+func (e *AtModifierEvaluator) Next() (bool, int64, StepResult) {
+	if !e.evaluated {
+		e.evaluated = true
+		if next, _, r := e.pinned.Next(); next {
+			e.vec = r.SampleVector()
+		}
+		if e.pinned.Error() != nil {
+			return false, 0, SampleVector{}
+		}
+	}
+
+	e.currentMs += e.stepMs
+	if e.currentMs > e.endMs {
+		return false, 0, SampleVector{}
+	}
+	vec := make(promql.Vector, 0, len(e.vec))
+	for _, s := range e.vec {
+		s.T = e.currentMs
+		vec = append(vec, s)
+	}
+	return true, e.currentMs, SampleVector(vec)
+}
+
+func (e *AtModifierEvaluator) Close() error { return e.pinned.Close() }
+
+func (e *AtModifierEvaluator) Error() error { return e.pinned.Error() }
+
 // This is to replace missing timeseries during absent_over_time aggregation.
 func absentLabels(expr syntax.SampleExpr) (labels.Labels, error) {
 	m := labels.Labels{}
@@ -14,6 +14,16 @@ func (e *LabelReplaceEvaluator) Explain(parent Node) {
 	e.nextEvaluator.Explain(b)
 }
 
+func (e *TimeFunctionEvaluator) Explain(parent Node) {
+	b := parent.Childf("%s TimeFunction", e.expr.Operation)
+	e.nextEvaluator.Explain(b)
+}
+
+func (e *HistogramQuantileEvaluator) Explain(parent Node) {
+	b := parent.Childf("%v HistogramQuantile", e.expr.Quantile)
+	e.nextEvaluator.Explain(b)
+}
+
 func (e *VectorAggEvaluator) Explain(parent Node) {
 	b := parent.Childf("[%s, %s] VectorAgg", e.expr.Operation, e.expr.Grouping)
 	e.nextEvaluator.Explain(b)
@@ -48,6 +58,11 @@ func (e *AbsentRangeVectorEvaluator) Explain(parent Node) {
 	parent.Child("Absent RangeVectorAgg")
 }
 
+func (e *AtModifierEvaluator) Explain(parent Node) {
+	b := parent.Childf("%d AtModifier", e.atMs)
+	e.pinned.Explain(b)
+}
+
 func (e *BinOpStepEvaluator) Explain(parent Node) {
 	b := parent.Childf("%s BinOp", e.expr.Op)
 	e.lse.Explain(b)
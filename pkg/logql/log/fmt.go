@@ -68,6 +68,7 @@ var (
 		"unixEpochNanos":   unixEpochNanos,
 		"toDateInZone":     toDateInZone,
 		"unixToTime":       unixToTime,
+		"hour":             hour,
 		"alignLeft":        alignLeft,
 		"alignRight":       alignRight,
 	}
@@ -170,6 +171,11 @@ func unixEpochNanos(date time.Time) string {
 	return strconv.FormatInt(date.UnixNano(), 10)
 }
 
+// hour returns the hour of date in UTC, like the hour() function of LogQL.
+func hour(date time.Time) int {
+	return date.UTC().Hour()
+}
+
 func toDateInZone(fmt, zone, str string) time.Time {
 	loc, err := time.LoadLocation(zone)
 	if err != nil {
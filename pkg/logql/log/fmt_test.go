@@ -633,6 +633,15 @@ func Test_labelsFormatter_Format(t *testing.T) {
 				"ts", "1661518453",
 			),
 		},
+		{
+			"timestamp_hour",
+			mustNewLabelsFormatter([]LabelFmt{NewTemplateLabelFmt("hour", "{{ __timestamp__ | hour }}")}),
+			labels.FromStrings("foo", "blip", "bar", "blop"),
+			labels.FromStrings("foo", "blip",
+				"bar", "blop",
+				"hour", "12",
+			),
+		},
 		{
 			"count",
 			mustNewLabelsFormatter([]LabelFmt{NewTemplateLabelFmt("count", `{{ __line__ | count "test" }}`)}),
@@ -75,6 +75,16 @@ func newRangeVectorIterator(
 	if err != nil {
 		return nil, err
 	}
+	return newBatchRangeVectorIterator(it, vectorAggregator, selRange, step, start, end, offset), nil
+}
+
+// newBatchRangeVectorIterator returns an iterator which aggregates all samples
+// within the range of each step with agg. The start and end must already be
+// shifted by the offset.
+func newBatchRangeVectorIterator(
+	it iter.PeekingSampleIterator,
+	agg BatchRangeVectorAggregator,
+	selRange, step, start, end, offset int64) *batchRangeVectorIterator {
 	return &batchRangeVectorIterator{
 		iter:     it,
 		step:     step,
@@ -82,14 +92,12 @@ func newRangeVectorIterator(
 		selRange: selRange,
 		metrics:  map[string]labels.Labels{},
 		window:   map[string]*promql.Series{},
-		agg:      vectorAggregator,
+		agg:      agg,
 		current:  start - step, // first loop iteration will set it to start
 		offset:   offset,
-	}, nil
+	}
 }
 
-//batch
-
 type batchRangeVectorIterator struct {
 	iter                                 iter.PeekingSampleIterator
 	selRange, step, end, current, offset int64
@@ -250,6 +258,14 @@ func aggregator(r *syntax.RangeAggregationExpr) (BatchRangeVectorAggregator, err
 		return last, nil
 	case syntax.OpRangeTypeAbsent:
 		return one, nil
+	case syntax.OpRangeTypeChanges:
+		return changesOverTime, nil
+	case syntax.OpRangeTypeResets:
+		return resetsOverTime, nil
+	case syntax.OpRangeTypeDeriv:
+		return deriv, nil
+	case syntax.OpRangeTypePredictLinear:
+		return predictLinear(*r.Params), nil
 	default:
 		return nil, fmt.Errorf(syntax.UnsupportedErr, r.Operation)
 	}
@@ -504,6 +520,94 @@ func one(_ []promql.FPoint) float64 {
 	return 1.0
 }
 
+// changesOverTime returns the number of times the value of the samples
+// changed within the range.
+func changesOverTime(samples []promql.FPoint) float64 {
+	var changes float64
+	for i := 1; i < len(samples); i++ {
+		prev, cur := samples[i-1].F, samples[i].F
+		if cur != prev && !(math.IsNaN(cur) && math.IsNaN(prev)) {
+			changes++
+		}
+	}
+	return changes
+}
+
+// resetsOverTime returns the number of times the value of the samples
+// decreased within the range, which are counter resets if the samples are
+// counters.
+func resetsOverTime(samples []promql.FPoint) float64 {
+	var resets float64
+	for i := 1; i < len(samples); i++ {
+		if samples[i].F < samples[i-1].F {
+			resets++
+		}
+	}
+	return resets
+}
+
+// deriv returns the per-second derivative of the samples using a simple
+// linear regression. It returns NaN for less than two samples.
+func deriv(samples []promql.FPoint) float64 {
+	if len(samples) < 2 {
+		return math.NaN()
+	}
+	slope, _ := linearRegression(samples, samples[len(samples)-1].T)
+	return slope
+}
+
+// predictLinear returns the value the samples are predicted to have in
+// duration seconds, based on a simple linear regression. The prediction
+// starts at the timestamp of the last sample within the range, since log
+// lines are not guaranteed to be logged at the end of every range. It
+// returns NaN for less than two samples.
+func predictLinear(duration float64) func(samples []promql.FPoint) float64 {
+	return func(samples []promql.FPoint) float64 {
+		if len(samples) < 2 {
+			return math.NaN()
+		}
+		slope, intercept := linearRegression(samples, samples[len(samples)-1].T)
+		return slope*duration + intercept
+	}
+}
+
+// linearRegression is adapted from prometheus code promql/functions.go.
+// It returns the slope per second and the intercept at interceptTime of the
+// least squares fit of the samples. The timestamps are in nanoseconds.
+func linearRegression(samples []promql.FPoint, interceptTime int64) (slope, intercept float64) {
+	var (
+		n            float64
+		sumX, sumY   float64
+		sumXY, sumX2 float64
+		initY        = samples[0].F
+		constY       = true
+	)
+	for i, sample := range samples {
+		// Set constY to false if any new y values are encountered.
+		if constY && i > 0 && sample.F != initY {
+			constY = false
+		}
+		n++
+		x := float64(sample.T-interceptTime) / 1e9
+		sumX += x
+		sumY += sample.F
+		sumXY += x * sample.F
+		sumX2 += x * x
+	}
+	if constY {
+		if math.IsInf(initY, 0) {
+			return math.NaN(), math.NaN()
+		}
+		return 0, initY
+	}
+	covXY := sumXY - sumX*sumY/n
+	varX := sumX2 - sumX*sumX/n
+
+	slope = covXY / varX
+	intercept = sumY/n - slope*sumX/n
+	return slope, intercept
+}
+
 // streaming range agg
 type streamRangeVectorIterator struct {
 	iter                                 iter.PeekingSampleIterator
@@ -627,6 +731,14 @@ func streamingAggregator(r *syntax.RangeAggregationExpr) (RangeStreamingAgg, err
 		return &LastOverTime{}, nil
 	case syntax.OpRangeTypeAbsent:
 		return &OneOverTime{}, nil
+	case syntax.OpRangeTypeChanges:
+		return &ChangesOverTime{}, nil
+	case syntax.OpRangeTypeResets:
+		return &ResetsOverTime{}, nil
+	case syntax.OpRangeTypeDeriv:
+		return &DerivOverTime{samples: make([]promql.FPoint, 0)}, nil
+	case syntax.OpRangeTypePredictLinear:
+		return &PredictLinearOverTime{duration: *r.Params, samples: make([]promql.FPoint, 0)}, nil
 	default:
 		return nil, fmt.Errorf(syntax.UnsupportedErr, r.Operation)
 	}
@@ -851,3 +963,68 @@ func (a *OneOverTime) agg(_ promql.FPoint) {
 func (a *OneOverTime) at() float64 {
 	return 1.0
 }
+
+// ChangesOverTime counts the number of times the value of the samples changed.
+type ChangesOverTime struct {
+	prev    float64
+	changes float64
+	hasData bool
+}
+
+func (a *ChangesOverTime) agg(sample promql.FPoint) {
+	if a.hasData && sample.F != a.prev && !(math.IsNaN(sample.F) && math.IsNaN(a.prev)) {
+		a.changes++
+	}
+	a.prev = sample.F
+	a.hasData = true
+}
+
+func (a *ChangesOverTime) at() float64 {
+	return a.changes
+}
+
+// ResetsOverTime counts the number of times the value of the samples decreased.
+type ResetsOverTime struct {
+	prev    float64
+	resets  float64
+	hasData bool
+}
+
+func (a *ResetsOverTime) agg(sample promql.FPoint) {
+	if a.hasData && sample.F < a.prev {
+		a.resets++
+	}
+	a.prev = sample.F
+	a.hasData = true
+}
+
+func (a *ResetsOverTime) at() float64 {
+	return a.resets
+}
+
+// DerivOverTime calculates the per-second derivative of the samples.
+type DerivOverTime struct {
+	samples []promql.FPoint
+}
+
+func (a *DerivOverTime) agg(sample promql.FPoint) {
+	a.samples = append(a.samples, sample)
+}
+
+func (a *DerivOverTime) at() float64 {
+	return deriv(a.samples)
+}
+
+// PredictLinearOverTime predicts the value of the samples in duration seconds.
+type PredictLinearOverTime struct {
+	duration float64
+	samples  []promql.FPoint
+}
+
+func (a *PredictLinearOverTime) agg(sample promql.FPoint) {
+	a.samples = append(a.samples, sample)
+}
+
+func (a *PredictLinearOverTime) at() float64 {
+	return predictLinear(a.duration)(a.samples)
+}
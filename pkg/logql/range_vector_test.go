@@ -3,6 +3,7 @@ package logql
 import (
 	"context"
 	"fmt"
+	"math"
 	"math/rand"
 	"sort"
 	"testing"
@@ -535,6 +536,11 @@ func Test_InstantQueryRangeVectorAggregations(t *testing.T) {
 		{"first", 1., syntax.OpRangeTypeFirst, false},
 		{"last", 3., syntax.OpRangeTypeLast, false},
 		{"absent", 1., syntax.OpRangeTypeAbsent, false},
+		{"changes", 2., syntax.OpRangeTypeChanges, false},
+		{"resets", 0., syntax.OpRangeTypeResets, false},
+		{"resets negative", 2., syntax.OpRangeTypeResets, true},
+		{"deriv", 1.0000000000000006e+09, syntax.OpRangeTypeDeriv, false},
+		{"predict linear", 9.900000030000006e+08, syntax.OpRangeTypePredictLinear, false},
 	}
 
 	var start, end int64 = 4, 4 // Instant query
@@ -554,6 +560,55 @@ func Test_InstantQueryRangeVectorAggregations(t *testing.T) {
 	}
 }
 
+func Test_TrendRangeVectorAggregations(t *testing.T) {
+	// A gauge sampled every 10 seconds with a drop at 30s.
+	points := []promql.FPoint{
+		{T: time.Unix(0, 0).UnixNano(), F: 10},
+		{T: time.Unix(10, 0).UnixNano(), F: 20},
+		{T: time.Unix(20, 0).UnixNano(), F: 20},
+		{T: time.Unix(30, 0).UnixNano(), F: 5},
+		{T: time.Unix(40, 0).UnixNano(), F: 15},
+	}
+
+	for _, tc := range []struct {
+		op       string
+		params   *float64
+		points   []promql.FPoint
+		expected float64
+	}{
+		{op: syntax.OpRangeTypeChanges, points: points, expected: 3},
+		{op: syntax.OpRangeTypeChanges, points: points[:1], expected: 0},
+		{op: syntax.OpRangeTypeResets, points: points, expected: 1},
+		{op: syntax.OpRangeTypeResets, points: points[:3], expected: 0},
+		{op: syntax.OpRangeTypeDeriv, points: points, expected: -0.05},
+		{op: syntax.OpRangeTypeDeriv, points: points[1:3], expected: 0},
+		{op: syntax.OpRangeTypeDeriv, points: points[:1], expected: math.NaN()},
+		// The prediction starts at the last sample: 13 + 60 * -0.05.
+		{op: syntax.OpRangeTypePredictLinear, params: proto.Float64(60), points: points, expected: 10},
+		{op: syntax.OpRangeTypePredictLinear, params: proto.Float64(60), points: points[:1], expected: math.NaN()},
+	} {
+		t.Run(fmt.Sprintf("%s of %d samples", tc.op, len(tc.points)), func(t *testing.T) {
+			expr := &syntax.RangeAggregationExpr{Left: &syntax.LogRangeExpr{Interval: time.Minute}, Params: tc.params, Operation: tc.op}
+
+			batch, err := aggregator(expr)
+			require.NoError(t, err)
+			streaming, err := streamingAggregator(expr)
+			require.NoError(t, err)
+			for _, p := range tc.points {
+				streaming.agg(p)
+			}
+
+			for _, v := range []float64{batch(tc.points), streaming.at()} {
+				if math.IsNaN(tc.expected) {
+					require.True(t, math.IsNaN(v))
+					continue
+				}
+				require.InDelta(t, tc.expected, v, 1e-9)
+			}
+		})
+	}
+}
+
 func sampleIter(negative bool) iter.PeekingSampleIterator {
 	return iter.NewPeekingSampleIterator(
 		iter.NewSortSampleIterator([]iter.SampleIterator{
@@ -175,6 +175,35 @@ func (m RangeMapper) Map(expr syntax.SampleExpr, vectorAggrPushdown *syntax.Vect
 		}
 		e.Left = lhsMapped
 		return e, nil
+	case *syntax.SubqueryExpr:
+		// The inner query is split like any other query, but a vector
+		// aggregation can't be pushed down through the aggregation over time of
+		// the subquery.
+		lhsMapped, err := m.Map(e.Left, nil, recorder)
+		if err != nil {
+			return nil, err
+		}
+		e.Left = lhsMapped
+		return e, nil
+	case *syntax.TimeFunctionExpr:
+		if e.Left == nil {
+			return e, nil
+		}
+		lhsMapped, err := m.Map(e.Left, nil, recorder)
+		if err != nil {
+			return nil, err
+		}
+		e.Left = lhsMapped
+		return e, nil
+	case *syntax.HistogramQuantileExpr:
+		// The quantile isn't additive, so vector aggregations can't be pushed
+		// down through it.
+		lhsMapped, err := m.Map(e.Left, nil, recorder)
+		if err != nil {
+			return nil, err
+		}
+		e.Left = lhsMapped
+		return e, nil
 	case *syntax.LiteralExpr:
 		return e, nil
 	case *syntax.VectorExpr:
@@ -563,6 +592,7 @@ func (m RangeMapper) mapRangeAggregationExpr(expr *syntax.RangeAggregationExpr,
 // supported.
 // A binary expression is splittable, if both the left and the right-hand side
 // are splittable.
+// A subquery is splittable, if its inner expression is splittable.
 func isSplittableByRange(expr syntax.SampleExpr) bool {
 	switch e := expr.(type) {
 	case *syntax.VectorAggregationExpr:
@@ -579,6 +609,12 @@ func isSplittableByRange(expr syntax.SampleExpr) bool {
 		return isSplittableByRange(e.SampleExpr) || literalLHS && isSplittableByRange(e.RHS) || literalRHS
 	case *syntax.LabelReplaceExpr:
 		return isSplittableByRange(e.Left)
+	case *syntax.SubqueryExpr:
+		return isSplittableByRange(e.Left)
+	case *syntax.HistogramQuantileExpr:
+		return isSplittableByRange(e.Left)
+	case *syntax.TimeFunctionExpr:
+		return e.Left != nil && isSplittableByRange(e.Left)
 	case *syntax.VectorExpr:
 		return false
 	default:
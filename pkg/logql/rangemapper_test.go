@@ -1809,6 +1809,64 @@ func Test_SplitRangeVectorMapping(t *testing.T) {
 			)`,
 			3,
 		},
+
+		// @ modifier
+		{
+			`sum by (baz) (count_over_time({app="foo"}[3m] @ end()))`,
+			`sum by (baz) (
+				sum without () (
+					downstream<sum by (baz) (count_over_time({app="foo"} [1m] @ end() offset 2m0s)), shard=<nil>>
+					++ downstream<sum by (baz) (count_over_time({app="foo"} [1m] @ end() offset 1m0s)), shard=<nil>>
+					++ downstream<sum by (baz) (count_over_time({app="foo"} [1m] @ end())), shard=<nil>>
+				)
+			)`,
+			3,
+		},
+
+		// histogram_quantile
+		{
+			`histogram_quantile(0.99, sum by (le) (count_over_time({app="foo"}[3m])))`,
+			`histogram_quantile(0.99,
+				sum by (le) (
+					sum without () (
+						downstream<sum by (le) (count_over_time({app="foo"} [1m] offset 2m0s)), shard=<nil>>
+						++ downstream<sum by (le) (count_over_time({app="foo"} [1m] offset 1m0s)), shard=<nil>>
+						++ downstream<sum by (le) (count_over_time({app="foo"} [1m])), shard=<nil>>
+					)
+				)
+			)`,
+			3,
+		},
+
+		// time functions
+		{
+			`timestamp(sum by (baz) (count_over_time({app="foo"}[3m])))`,
+			`timestamp(
+				sum by (baz) (
+					sum without () (
+						downstream<sum by (baz) (count_over_time({app="foo"} [1m] offset 2m0s)), shard=<nil>>
+						++ downstream<sum by (baz) (count_over_time({app="foo"} [1m] offset 1m0s)), shard=<nil>>
+						++ downstream<sum by (baz) (count_over_time({app="foo"} [1m])), shard=<nil>>
+					)
+				)
+			)`,
+			3,
+		},
+
+		// subqueries
+		{
+			`max_over_time(sum by (baz) (count_over_time({app="foo"}[3m]))[1h:1m])`,
+			`max_over_time(
+				sum by (baz) (
+					sum without () (
+						downstream<sum by (baz) (count_over_time({app="foo"} [1m] offset 2m0s)), shard=<nil>>
+						++ downstream<sum by (baz) (count_over_time({app="foo"} [1m] offset 1m0s)), shard=<nil>>
+						++ downstream<sum by (baz) (count_over_time({app="foo"} [1m])), shard=<nil>>
+					)
+				)[1h:1m]
+			)`,
+			3,
+		},
 	} {
 		t.Run(tc.expr, func(t *testing.T) {
 			t.Parallel()
@@ -1994,6 +2052,16 @@ func Test_SplitRangeVectorMapping_Noop(t *testing.T) {
 			`5 * 5`,
 			`25`,
 		},
+		// should be noop if the inner expression of a subquery is not splittable
+		{
+			`max_over_time(quantile_over_time(0.95, {app="foo"} | unwrap bar[3m])[1h:1m])`,
+			`max_over_time(quantile_over_time(0.95, {app="foo"} | unwrap bar[3m])[1h:1m])`,
+		},
+		// should be noop if time function without argument
+		{
+			`hour()`,
+			`hour()`,
+		},
 		// should be noop if VectorExpr
 		{
 			`vector(0)`,
@@ -101,6 +101,12 @@ func (m ShardMapper) Map(expr syntax.Expr, r *downstreamRecorder, topLevel bool)
 		return m.mapLabelReplaceExpr(e, r, topLevel)
 	case *syntax.RangeAggregationExpr:
 		return m.mapRangeAggregationExpr(e, r, topLevel)
+	case *syntax.SubqueryExpr:
+		return m.mapSubqueryExpr(e, r)
+	case *syntax.HistogramQuantileExpr:
+		return m.mapHistogramQuantileExpr(e, r)
+	case *syntax.TimeFunctionExpr:
+		return m.mapTimeFunctionExpr(e, r, topLevel)
 	case *syntax.BinOpExpr:
 		return m.mapBinOpExpr(e, r, topLevel)
 	default:
@@ -415,6 +421,50 @@ func (m ShardMapper) mapLabelReplaceExpr(expr *syntax.LabelReplaceExpr, r *downs
 	return &cpy, bytesPerShard, nil
 }
 
+// mapSubqueryExpr shards the inner query of a subquery. The subquery itself is
+// evaluated on the merged results of the inner query at each step of the
+// subquery, which are the same whether the inner query is sharded or not.
+// The inner query is never mapped as a top level expression, since its results
+// must be exact rather than approximated from sketches.
+func (m ShardMapper) mapSubqueryExpr(expr *syntax.SubqueryExpr, r *downstreamRecorder) (syntax.SampleExpr, uint64, error) {
+	subMapped, bytesPerShard, err := m.Map(expr.Left, r, false)
+	if err != nil {
+		return nil, 0, err
+	}
+	cpy := *expr
+	cpy.Left = subMapped.(syntax.SampleExpr)
+	return &cpy, bytesPerShard, nil
+}
+
+// mapHistogramQuantileExpr shards the inner query of histogram_quantile. The
+// quantile itself is calculated on the frontend once the buckets of all shards
+// have been merged, see DownstreamEvaluator.
+func (m ShardMapper) mapHistogramQuantileExpr(expr *syntax.HistogramQuantileExpr, r *downstreamRecorder) (syntax.SampleExpr, uint64, error) {
+	leftMapped, bytesPerShard, err := m.Map(expr.Left, r, false)
+	if err != nil {
+		return nil, 0, err
+	}
+	cpy := *expr
+	cpy.Left = leftMapped.(syntax.SampleExpr)
+	return &cpy, bytesPerShard, nil
+}
+
+// mapTimeFunctionExpr shards the argument of a time function, which is applied
+// to each sample on its own. Without an argument, the function is evaluated
+// on the frontend.
+func (m ShardMapper) mapTimeFunctionExpr(expr *syntax.TimeFunctionExpr, r *downstreamRecorder, topLevel bool) (syntax.SampleExpr, uint64, error) {
+	if expr.Left == nil {
+		return expr, 0, nil
+	}
+	leftMapped, bytesPerShard, err := m.Map(expr.Left, r, topLevel)
+	if err != nil {
+		return nil, 0, err
+	}
+	cpy := *expr
+	cpy.Left = leftMapped.(syntax.SampleExpr)
+	return &cpy, bytesPerShard, nil
+}
+
 // These functions require a different merge strategy than the default
 // concatenation.
 // This is because the same label sets may exist on multiple shards when label-reducing parsing is applied or when
@@ -534,7 +584,9 @@ func (m ShardMapper) mapRangeAggregationExpr(expr *syntax.RangeAggregationExpr,
 		}, bytesPerShard, nil
 
 	case syntax.OpRangeTypeQuantile:
-		if !m.quantileOverTimeSharding {
+		// The sketches of the shards are evaluated at each step, so ranges
+		// pinned by the `@` modifier aren't sharded.
+		if !m.quantileOverTimeSharding || expr.Left.At != nil {
 			return noOp(expr, m.shards.Resolver())
 		}
 
@@ -581,7 +633,9 @@ func (m ShardMapper) mapRangeAggregationExpr(expr *syntax.RangeAggregationExpr,
 		}, bytesPerShard, nil
 
 	case syntax.OpRangeTypeFirst:
-		if !m.firstOverTimeSharding {
+		// Samples are merged by their timestamps, which are replaced by the
+		// steps of the query for ranges pinned by the `@` modifier.
+		if !m.firstOverTimeSharding || expr.Left.At != nil {
 			return noOp(expr, m.shards.Resolver())
 		}
 
@@ -613,7 +667,7 @@ func (m ShardMapper) mapRangeAggregationExpr(expr *syntax.RangeAggregationExpr,
 			offset:      expr.Left.Offset,
 		}, bytesPerShard, nil
 	case syntax.OpRangeTypeLast:
-		if !m.lastOverTimeSharding {
+		if !m.lastOverTimeSharding || expr.Left.At != nil {
 			return noOp(expr, m.shards.Resolver())
 		}
 
@@ -663,9 +717,11 @@ func isNoOp(left syntax.Expr, right syntax.Expr) bool {
 }
 
 func isLiteralOrVector(e syntax.Expr) bool {
-	switch e.(type) {
+	switch e := e.(type) {
 	case *syntax.VectorExpr, *syntax.LiteralExpr:
 		return true
+	case *syntax.TimeFunctionExpr:
+		return e.Left == nil
 	default:
 		return false
 	}
@@ -320,6 +320,76 @@ func TestMappingStrings(t *testing.T) {
 					)
 				)`,
 		},
+		{
+			in: `max_over_time(sum by (cluster) (rate({foo="bar"}[5m]))[1h:1m])`,
+			out: `max_over_time(
+					sum by (cluster) (
+						downstream<sum by (cluster) (rate({foo="bar"}[5m])), shard=0_of_2>
+						++ downstream<sum by (cluster) (rate({foo="bar"}[5m])), shard=1_of_2>
+					)[1h:1m]
+				)`,
+		},
+		{
+			in: `sum by (cluster) (rate({foo="bar"}[5m] @ end()))`,
+			out: `sum by (cluster) (
+					downstream<sum by (cluster) (rate({foo="bar"}[5m] @ end())), shard=0_of_2>
+					++ downstream<sum by (cluster) (rate({foo="bar"}[5m] @ end())), shard=1_of_2>
+				)`,
+		},
+		{
+			// quantiles of ranges pinned by the @ modifier are not approximated
+			in:  `quantile_over_time(0.99, {foo="bar"} | unwrap latency [5m] @ 1609746000) by (cluster)`,
+			out: `quantile_over_time(0.99, {foo="bar"} | unwrap latency [5m] @ 1609746000) by (cluster)`,
+		},
+		{
+			in: `histogram_quantile(0.99, sum by (le) (sum_over_time({foo="bar"} | unwrap count [5m])))`,
+			out: `histogram_quantile(0.99,
+					sum by (le) (
+						downstream<sum by (le) (sum_over_time({foo="bar"} | unwrap count [5m])), shard=0_of_2>
+						++ downstream<sum by (le) (sum_over_time({foo="bar"} | unwrap count [5m])), shard=1_of_2>
+					)
+				)`,
+		},
+		{
+			in: `timestamp(sum by (cluster) (rate({foo="bar"}[5m])))`,
+			out: `timestamp(
+					sum by (cluster) (
+						downstream<sum by (cluster) (rate({foo="bar"}[5m])), shard=0_of_2>
+						++ downstream<sum by (cluster) (rate({foo="bar"}[5m])), shard=1_of_2>
+					)
+				)`,
+		},
+		{
+			in: `sum(rate({foo="bar"}[5m])) and on() hour() >= 9`,
+			out: `(
+					sum(
+						downstream<sum(rate({foo="bar"}[5m])), shard=0_of_2>
+						++ downstream<sum(rate({foo="bar"}[5m])), shard=1_of_2>
+					) and on() downstream<(hour() >= 9), shard=<nil>>
+				)`,
+		},
+		{
+			// time functions without an argument are evaluated on the frontend
+			in: `sum(rate({foo="bar"}[5m])) * hour()`,
+			out: `(
+					sum(
+						downstream<sum(rate({foo="bar"}[5m])), shard=0_of_2>
+						++ downstream<sum(rate({foo="bar"}[5m])), shard=1_of_2>
+					) * hour()
+				)`,
+		},
+		{
+			in: `histogram_quantile(0.99, count_over_time({foo="bar"}[5m]))`,
+			out: `histogram_quantile(0.99,
+					downstream<count_over_time({foo="bar"}[5m]), shard=0_of_2>
+					++ downstream<count_over_time({foo="bar"}[5m]), shard=1_of_2>
+				)`,
+		},
+		{
+			// quantiles are not approximated within subqueries
+			in:  `max_over_time(quantile_over_time(0.99, {foo="bar"} | unwrap latency [5m])[1h:1m])`,
+			out: `max_over_time(quantile_over_time(0.99, {foo="bar"} | unwrap latency [5m])[1h:1m])`,
+		},
 		{
 			in: `sum(count_over_time({foo="bar"} | logfmt | label_format bar=baz | bar="buz" [5m])) by (bar)`,
 			out: `sum by (bar) (
@@ -48,10 +48,13 @@ type Expr interface {
 func (MatchersExpr) isExpr()               {}
 func (PipelineExpr) isExpr()               {}
 func (RangeAggregationExpr) isExpr()       {}
+func (SubqueryExpr) isExpr()               {}
 func (VectorAggregationExpr) isExpr()      {}
 func (LiteralExpr) isExpr()                {}
 func (VectorExpr) isExpr()                 {}
 func (LabelReplaceExpr) isExpr()           {}
+func (HistogramQuantileExpr) isExpr()      {}
+func (TimeFunctionExpr) isExpr()           {}
 func (LineParserExpr) isExpr()             {}
 func (LogfmtParserExpr) isExpr()           {}
 func (LineFilterExpr) isExpr()             {}
@@ -97,10 +100,13 @@ type SampleExpr interface {
 }
 
 func (RangeAggregationExpr) isSampleExpr()  {}
+func (SubqueryExpr) isSampleExpr()          {}
 func (VectorAggregationExpr) isSampleExpr() {}
 func (LiteralExpr) isSampleExpr()           {}
 func (VectorExpr) isSampleExpr()            {}
 func (LabelReplaceExpr) isSampleExpr()      {}
+func (HistogramQuantileExpr) isSampleExpr() {}
+func (TimeFunctionExpr) isSampleExpr()      {}
 func (MultiVariantExpr) isSampleExpr()      {}
 
 // StageExpr is an expression defining a single step into a log pipeline
@@ -850,6 +856,9 @@ func newDropLabelsExpr(dropLabels []log.NamedLabelMatcher) *DropLabelsExpr {
 	return &DropLabelsExpr{dropLabels: dropLabels}
 }
 
+// DropLabels returns the label names and matchers of the labels to drop.
+func (e *DropLabelsExpr) DropLabels() []log.NamedLabelMatcher { return e.dropLabels }
+
 func (e *DropLabelsExpr) Shardable(_ bool) bool { return true }
 
 func (e *DropLabelsExpr) Stage() (log.Stage, error) {
@@ -890,6 +899,9 @@ func newKeepLabelsExpr(keepLabels []log.NamedLabelMatcher) *KeepLabelsExpr {
 	return &KeepLabelsExpr{keepLabels: keepLabels}
 }
 
+// KeepLabels returns the label names and matchers of the labels to keep.
+func (e *KeepLabelsExpr) KeepLabels() []log.NamedLabelMatcher { return e.keepLabels }
+
 func (e *KeepLabelsExpr) Shardable(_ bool) bool { return true }
 
 func (e *KeepLabelsExpr) Stage() (log.Stage, error) {
@@ -1143,6 +1155,7 @@ type LogRangeExpr struct {
 	Left     LogSelectorExpr
 	Interval time.Duration
 	Offset   time.Duration
+	At       *AtModifier // Time at which the range is evaluated, if pinned by the `@` modifier.
 	Unwrap   *UnwrapExpr
 }
 
@@ -1154,8 +1167,8 @@ func (r LogRangeExpr) String() string {
 		sb.WriteString(r.Unwrap.String())
 	}
 	sb.WriteString(fmt.Sprintf("[%v]", model.Duration(r.Interval)))
-	if r.Offset != 0 {
-		offsetExpr := OffsetExpr{Offset: r.Offset}
+	if r.Offset != 0 || r.At != nil {
+		offsetExpr := OffsetExpr{Offset: r.Offset, At: r.At}
 		sb.WriteString(offsetExpr.String())
 	}
 	return sb.String()
@@ -1186,29 +1199,42 @@ func (r *LogRangeExpr) WithoutUnwrap() (*LogRangeExpr, error) {
 		Left:     left,
 		Interval: r.Interval,
 		Offset:   r.Offset,
+		At:       r.At,
 	}, nil
 }
 
 func newLogRange(left LogSelectorExpr, interval time.Duration, u *UnwrapExpr, o *OffsetExpr) *LogRangeExpr {
-	var offset time.Duration
+	var (
+		offset time.Duration
+		at     *AtModifier
+	)
 	if o != nil {
 		offset = o.Offset
+		at = o.At
 	}
 	return &LogRangeExpr{
 		Left:     left,
 		Interval: interval,
 		Unwrap:   u,
 		Offset:   offset,
+		At:       at,
 	}
 }
 
+// OffsetExpr holds the modifiers of a range: its offset and its `@` modifier.
 type OffsetExpr struct {
 	Offset time.Duration
+	At     *AtModifier
 }
 
 func (o *OffsetExpr) String() string {
 	var sb strings.Builder
-	sb.WriteString(fmt.Sprintf(" %s %s", OpOffset, o.Offset.String()))
+	if o.At != nil {
+		sb.WriteString(o.At.String())
+	}
+	if o.Offset != 0 || o.At == nil {
+		sb.WriteString(fmt.Sprintf(" %s %s", OpOffset, o.Offset.String()))
+	}
 	return sb.String()
 }
 
@@ -1218,6 +1244,82 @@ func newOffsetExpr(offset time.Duration) *OffsetExpr {
 	}
 }
 
+// withAt returns the modifiers with the `@` modifier at.
+func (o *OffsetExpr) withAt(at *AtModifier) *OffsetExpr {
+	o.At = at
+	return o
+}
+
+// AtModifier pins the evaluation of a range to a fixed time instead of the
+// time of each step of the query, e.g. `@ 1609746000`, `@ start()` or
+// `@ end()`.
+type AtModifier struct {
+	Timestamp time.Time // Fixed time of the range, unless Start or End is set.
+	Start     bool      // Whether the range is evaluated at the start of the query.
+	End       bool      // Whether the range is evaluated at the end of the query.
+}
+
+func (a *AtModifier) String() string {
+	switch {
+	case a.Start:
+		return fmt.Sprintf(" %s %s()", OpAt, OpStart)
+	case a.End:
+		return fmt.Sprintf(" %s %s()", OpAt, OpEnd)
+	default:
+		return fmt.Sprintf(" %s %s", OpAt, strconv.FormatFloat(float64(a.Timestamp.UnixMilli())/1e3, 'f', -1, 64))
+	}
+}
+
+// Time returns the time at which the range is evaluated for a query from
+// start to end.
+func (a *AtModifier) Time(start, end time.Time) time.Time {
+	switch {
+	case a.Start:
+		return start
+	case a.End:
+		return end
+	default:
+		return a.Timestamp
+	}
+}
+
+// newAtModifier returns an `@` modifier at a unix timestamp in seconds, which
+// is rounded to milliseconds.
+func newAtModifier(seconds float64) *AtModifier {
+	return &AtModifier{Timestamp: time.UnixMilli(int64(math.Round(seconds * 1e3)))}
+}
+
+// ResolveAtModifiers returns a copy of expr in which the `@ start()` and
+// `@ end()` modifiers are replaced by the start and end of the query, so that
+// they keep referring to the same time when the query is split into queries
+// over shorter time ranges.
+func ResolveAtModifiers(expr Expr, start, end time.Time) (Expr, error) {
+	resolved, err := Clone(expr)
+	if err != nil {
+		return nil, err
+	}
+	resolved.Walk(func(e Expr) bool {
+		if r, ok := e.(*LogRangeExpr); ok && r.At != nil {
+			r.At = &AtModifier{Timestamp: r.At.Time(start, end)}
+		}
+		return true
+	})
+	return resolved, nil
+}
+
+// HasStartOrEndAtModifier returns true if a range of expr is evaluated at the
+// start or end of the query by the `@ start()` or `@ end()` modifiers.
+func HasStartOrEndAtModifier(expr Expr) bool {
+	var found bool
+	expr.Walk(func(e Expr) bool {
+		if r, ok := e.(*LogRangeExpr); ok && r.At != nil && (r.At.Start || r.At.End) {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
 const (
 	// vector ops
 	OpTypeSum      = "sum"
@@ -1233,21 +1335,25 @@ const (
 	OpTypeSortDesc = "sort_desc"
 
 	// range vector ops
-	OpRangeTypeCount       = "count_over_time"
-	OpRangeTypeRate        = "rate"
-	OpRangeTypeRateCounter = "rate_counter"
-	OpRangeTypeBytes       = "bytes_over_time"
-	OpRangeTypeBytesRate   = "bytes_rate"
-	OpRangeTypeAvg         = "avg_over_time"
-	OpRangeTypeSum         = "sum_over_time"
-	OpRangeTypeMin         = "min_over_time"
-	OpRangeTypeMax         = "max_over_time"
-	OpRangeTypeStdvar      = "stdvar_over_time"
-	OpRangeTypeStddev      = "stddev_over_time"
-	OpRangeTypeQuantile    = "quantile_over_time"
-	OpRangeTypeFirst       = "first_over_time"
-	OpRangeTypeLast        = "last_over_time"
-	OpRangeTypeAbsent      = "absent_over_time"
+	OpRangeTypeCount         = "count_over_time"
+	OpRangeTypeRate          = "rate"
+	OpRangeTypeRateCounter   = "rate_counter"
+	OpRangeTypeBytes         = "bytes_over_time"
+	OpRangeTypeBytesRate     = "bytes_rate"
+	OpRangeTypeAvg           = "avg_over_time"
+	OpRangeTypeSum           = "sum_over_time"
+	OpRangeTypeMin           = "min_over_time"
+	OpRangeTypeMax           = "max_over_time"
+	OpRangeTypeStdvar        = "stdvar_over_time"
+	OpRangeTypeStddev        = "stddev_over_time"
+	OpRangeTypeQuantile      = "quantile_over_time"
+	OpRangeTypeFirst         = "first_over_time"
+	OpRangeTypeLast          = "last_over_time"
+	OpRangeTypeAbsent        = "absent_over_time"
+	OpRangeTypeChanges       = "changes_over_time"
+	OpRangeTypeResets        = "resets_over_time"
+	OpRangeTypeDeriv         = "deriv"
+	OpRangeTypePredictLinear = "predict_linear"
 
 	// vector
 	OpTypeVector = "vector"
@@ -1287,6 +1393,9 @@ const (
 	OpPipe   = "|"
 	OpUnwrap = "unwrap"
 	OpOffset = "offset"
+	OpAt     = "@"
+	OpStart  = "start"
+	OpEnd    = "end"
 
 	OpOn       = "on"
 	OpIgnoring = "ignoring"
@@ -1301,6 +1410,18 @@ const (
 
 	OpLabelReplace = "label_replace"
 
+	OpHistogramQuantile = "histogram_quantile"
+
+	// time functions
+	OpHour        = "hour"
+	OpMinute      = "minute"
+	OpDayOfWeek   = "day_of_week"
+	OpDayOfMonth  = "day_of_month"
+	OpDaysInMonth = "days_in_month"
+	OpMonth       = "month"
+	OpYear        = "year"
+	OpTimestamp   = "timestamp"
+
 	// function filters
 	OpFilterIP = "ip"
 
@@ -1364,7 +1485,7 @@ type RangeAggregationExpr struct {
 func newRangeAggregationExpr(left *LogRangeExpr, operation string, gr *Grouping, stringParams *string) SampleExpr {
 	var params *float64
 	if stringParams != nil {
-		if operation != OpRangeTypeQuantile && operation != OpRangeTypeQuantileSketch {
+		if operation != OpRangeTypeQuantile && operation != OpRangeTypeQuantileSketch && operation != OpRangeTypePredictLinear {
 			return &RangeAggregationExpr{err: logqlmodel.NewParseError(fmt.Sprintf("parameter %s not supported for operation %s", *stringParams, operation), 0, 0)}
 		}
 		var err error
@@ -1375,7 +1496,7 @@ func newRangeAggregationExpr(left *LogRangeExpr, operation string, gr *Grouping,
 		}
 
 	} else {
-		if operation == OpRangeTypeQuantile {
+		if operation == OpRangeTypeQuantile || operation == OpRangeTypePredictLinear {
 			return &RangeAggregationExpr{err: logqlmodel.NewParseError(fmt.Sprintf("parameter required for operation %s", operation), 0, 0)}
 		}
 	}
@@ -1430,7 +1551,8 @@ func (e RangeAggregationExpr) validate() error {
 		case OpRangeTypeAvg, OpRangeTypeSum, OpRangeTypeMax, OpRangeTypeMin, OpRangeTypeStddev,
 			OpRangeTypeStdvar, OpRangeTypeQuantile, OpRangeTypeRate, OpRangeTypeRateCounter,
 			OpRangeTypeAbsent, OpRangeTypeFirst, OpRangeTypeLast, OpRangeTypeQuantileSketch,
-			OpRangeTypeFirstWithTimestamp, OpRangeTypeLastWithTimestamp:
+			OpRangeTypeFirstWithTimestamp, OpRangeTypeLastWithTimestamp, OpRangeTypeChanges,
+			OpRangeTypeResets, OpRangeTypeDeriv, OpRangeTypePredictLinear:
 			return nil
 		default:
 			return fmt.Errorf("invalid aggregation %s with unwrap", e.Operation)
@@ -1488,6 +1610,162 @@ func (e *RangeAggregationExpr) Walk(f WalkFn) {
 
 func (e *RangeAggregationExpr) Accept(v RootVisitor) { v.VisitRangeAggregation(e) }
 
+// subqueryRange holds the range and the optional resolution of a subquery,
+// e.g. `[1h:1m]`.
+type subqueryRange struct {
+	Range time.Duration
+	Step  time.Duration
+}
+
+// SubqueryExpr is a range aggregation over the results of a metric query, which
+// is evaluated at the resolution of the subquery instead of the resolution of
+// the query, e.g. `max_over_time(sum(rate({app="foo"}[1m]))[1h:1m])`.
+type SubqueryExpr struct {
+	Left      SampleExpr
+	Operation string
+	Params    *float64
+
+	Range  time.Duration
+	Step   time.Duration // Resolution of the subquery, zero for the step of the query.
+	Offset time.Duration
+	err    error
+}
+
+func newSubqueryExpr(left SampleExpr, operation string, r subqueryRange, o *OffsetExpr, stringParams *string) SampleExpr {
+	var params *float64
+	if stringParams != nil {
+		if operation != OpRangeTypeQuantile {
+			return &SubqueryExpr{err: logqlmodel.NewParseError(fmt.Sprintf("parameter %s not supported for operation %s", *stringParams, operation), 0, 0)}
+		}
+		v, err := strconv.ParseFloat(*stringParams, 64)
+		if err != nil {
+			return &SubqueryExpr{err: logqlmodel.NewParseError(fmt.Sprintf("invalid parameter for operation %s: %s", operation, err), 0, 0)}
+		}
+		params = &v
+	} else if operation == OpRangeTypeQuantile {
+		return &SubqueryExpr{err: logqlmodel.NewParseError(fmt.Sprintf("parameter required for operation %s", operation), 0, 0)}
+	}
+
+	e := &SubqueryExpr{
+		Left:      left,
+		Operation: operation,
+		Params:    params,
+		Range:     r.Range,
+		Step:      r.Step,
+	}
+	if o != nil {
+		if o.At != nil {
+			return &SubqueryExpr{err: logqlmodel.NewParseError(fmt.Sprintf("%s modifier is not supported for subqueries", OpAt), 0, 0)}
+		}
+		e.Offset = o.Offset
+	}
+	if err := e.validate(); err != nil {
+		return &SubqueryExpr{err: logqlmodel.NewParseError(err.Error(), 0, 0)}
+	}
+	return e
+}
+
+func (e SubqueryExpr) validate() error {
+	switch e.Operation {
+	case OpRangeTypeCount, OpRangeTypeSum, OpRangeTypeAvg, OpRangeTypeMin, OpRangeTypeMax,
+		OpRangeTypeStdvar, OpRangeTypeStddev, OpRangeTypeQuantile, OpRangeTypeFirst,
+		OpRangeTypeLast, OpRangeTypeAbsent:
+	default:
+		// Rates are computed from log lines and are meaningless for samples
+		// of a metric query.
+		return fmt.Errorf("invalid aggregation %s of subquery", e.Operation)
+	}
+	if e.Range <= 0 {
+		return fmt.Errorf("invalid subquery range %s", model.Duration(e.Range))
+	}
+	if e.Step < 0 {
+		return fmt.Errorf("invalid subquery resolution %s", model.Duration(e.Step))
+	}
+	return nil
+}
+
+func (e *SubqueryExpr) Selector() (LogSelectorExpr, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	return e.Left.Selector()
+}
+
+// MatcherGroups returns the matcher groups of the inner query, which are
+// queried over the range of the subquery in addition to their own range.
+func (e *SubqueryExpr) MatcherGroups() ([]MatcherRange, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	groups, err := e.Left.MatcherGroups()
+	if err != nil {
+		return nil, err
+	}
+	for i := range groups {
+		groups[i].Interval += e.Range
+		groups[i].Offset += e.Offset
+	}
+	return groups, nil
+}
+
+func (e *SubqueryExpr) Extractors() ([]SampleExtractor, error) {
+	if e.err != nil {
+		return []SampleExtractor{}, e.err
+	}
+	return e.Left.Extractors()
+}
+
+// Shardable returns whether the inner query is shardable. The subquery itself
+// is always evaluated on the results of the inner query, which are identical
+// whether the inner query is sharded or not.
+func (e *SubqueryExpr) Shardable(_ bool) bool {
+	return e.Left.Shardable(false)
+}
+
+func (e *SubqueryExpr) Walk(f WalkFn) {
+	if !f(e) {
+		return
+	}
+	if e.Left != nil {
+		e.Left.Walk(f)
+	}
+}
+
+func (e *SubqueryExpr) Accept(v RootVisitor) { v.VisitSubquery(e) }
+
+// impls Stringer
+func (e *SubqueryExpr) String() string {
+	var sb strings.Builder
+	sb.WriteString(e.Operation)
+	sb.WriteString("(")
+	if e.Params != nil {
+		sb.WriteString(strconv.FormatFloat(*e.Params, 'f', -1, 64))
+		sb.WriteString(",")
+	}
+	sb.WriteString(e.Left.String())
+	sb.WriteString(e.rangeString())
+	sb.WriteString(")")
+	return sb.String()
+}
+
+// rangeString returns the range, resolution and offset of the subquery, e.g.
+// `[1h:1m] offset 5m`.
+func (e *SubqueryExpr) rangeString() string {
+	var sb strings.Builder
+	sb.WriteString("[")
+	sb.WriteString(model.Duration(e.Range).String())
+	sb.WriteString(":")
+	if e.Step != 0 {
+		sb.WriteString(model.Duration(e.Step).String())
+	}
+	sb.WriteString("]")
+	if e.Offset != 0 {
+		offsetExpr := OffsetExpr{Offset: e.Offset}
+		sb.WriteString(offsetExpr.String())
+	}
+	return sb.String()
+}
+
 // Grouping struct represents the grouping by/without label(s) for vector aggregators and range vector aggregators.
 // The representation is as follows:
 //   - No Grouping (labels dismissed): <operation> (<expr>) => Grouping{Without: false, Groups: nil}
@@ -2270,6 +2548,157 @@ func (e *LabelReplaceExpr) String() string {
 	return sb.String()
 }
 
+// HistogramQuantileExpr calculates the φ-quantile from the buckets of classic
+// histograms, e.g. `histogram_quantile(0.99, sum by (le) (...))`. The samples
+// of Left with the same labels apart from the `le` label are the buckets of a
+// histogram, where `le` is the upper bound of each bucket.
+type HistogramQuantileExpr struct {
+	Left     SampleExpr
+	Quantile float64
+	err      error
+}
+
+func newHistogramQuantileExpr(left SampleExpr, quantile string) *HistogramQuantileExpr {
+	q, err := strconv.ParseFloat(quantile, 64)
+	if err != nil {
+		return &HistogramQuantileExpr{
+			err: logqlmodel.NewParseError(fmt.Sprintf("invalid parameter for %s: %s", OpHistogramQuantile, err), 0, 0),
+		}
+	}
+	return &HistogramQuantileExpr{
+		Left:     left,
+		Quantile: q,
+	}
+}
+
+func (e *HistogramQuantileExpr) Selector() (LogSelectorExpr, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	return e.Left.Selector()
+}
+
+func (e *HistogramQuantileExpr) MatcherGroups() ([]MatcherRange, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	return e.Left.MatcherGroups()
+}
+
+func (e *HistogramQuantileExpr) Extractors() ([]SampleExtractor, error) {
+	if e.err != nil {
+		return []SampleExtractor{}, e.err
+	}
+	return e.Left.Extractors()
+}
+
+// Shardable returns false, as the buckets of a histogram must be merged before
+// the quantile is calculated. The inner expression may still be sharded.
+func (e *HistogramQuantileExpr) Shardable(_ bool) bool {
+	return false
+}
+
+func (e *HistogramQuantileExpr) Walk(f WalkFn) {
+	if !f(e) {
+		return
+	}
+	if e.Left != nil {
+		e.Left.Walk(f)
+	}
+}
+
+func (e *HistogramQuantileExpr) Accept(v RootVisitor) { v.VisitHistogramQuantile(e) }
+
+func (e *HistogramQuantileExpr) String() string {
+	var sb strings.Builder
+	sb.WriteString(OpHistogramQuantile)
+	sb.WriteString("(")
+	sb.WriteString(strconv.FormatFloat(e.Quantile, 'f', -1, 64))
+	sb.WriteString(",")
+	sb.WriteString(e.Left.String())
+	sb.WriteString(")")
+	return sb.String()
+}
+
+// TimeFunctionExpr applies a time function to each sample of Left, e.g.
+// `hour(...)`. Except for `timestamp()`, which returns the timestamps of the
+// samples, the values of the samples are interpreted as Unix timestamps in
+// seconds and the function returns the corresponding component of the time in
+// UTC. Without an argument, the function is applied to the time at each step.
+type TimeFunctionExpr struct {
+	Left      SampleExpr // nil if the function has no argument
+	Operation string
+	err       error
+}
+
+func newTimeFunctionExpr(left SampleExpr, operation string) *TimeFunctionExpr {
+	if left == nil && operation == OpTimestamp {
+		return &TimeFunctionExpr{
+			Operation: operation,
+			err:       logqlmodel.NewParseError(fmt.Sprintf("argument required for %s", operation), 0, 0),
+		}
+	}
+	return &TimeFunctionExpr{
+		Left:      left,
+		Operation: operation,
+	}
+}
+
+// Selector returns the selector of Left. Without an argument, the function
+// doesn't select any logs, just like `vector()`.
+func (e *TimeFunctionExpr) Selector() (LogSelectorExpr, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	if e.Left == nil {
+		return &VectorExpr{}, nil
+	}
+	return e.Left.Selector()
+}
+
+func (e *TimeFunctionExpr) MatcherGroups() ([]MatcherRange, error) {
+	if e.err != nil || e.Left == nil {
+		return nil, e.err
+	}
+	return e.Left.MatcherGroups()
+}
+
+func (e *TimeFunctionExpr) Extractors() ([]SampleExtractor, error) {
+	if e.err != nil || e.Left == nil {
+		return []SampleExtractor{}, e.err
+	}
+	return e.Left.Extractors()
+}
+
+func (e *TimeFunctionExpr) Shardable(topLevel bool) bool {
+	if e.Left == nil {
+		return false
+	}
+	return e.Left.Shardable(topLevel)
+}
+
+func (e *TimeFunctionExpr) Walk(f WalkFn) {
+	if !f(e) {
+		return
+	}
+	if e.Left != nil {
+		e.Left.Walk(f)
+	}
+}
+
+func (e *TimeFunctionExpr) Accept(v RootVisitor) { v.VisitTimeFunction(e) }
+
+func (e *TimeFunctionExpr) String() string {
+	var sb strings.Builder
+	sb.WriteString(e.Operation)
+	sb.WriteString("(")
+	if e.Left != nil {
+		sb.WriteString(e.Left.String())
+	}
+	sb.WriteString(")")
+	return sb.String()
+}
+
 // shardableOps lists the operations which may be sharded, but are not
 // guaranteed to be. See the `Shardable()` implementations
 // on the respective expr types for more details.
@@ -163,6 +163,14 @@ func Test_SampleExpr_String(t *testing.T) {
 			"(.*):.*"
 		)
 		`,
+		`max_over_time(sum by (job) (rate({namespace="tns"} |= "level=error" [1m]))[1h:1m])`,
+		`quantile_over_time(0.99, count_over_time({namespace="tns"}[1m])[1h:] offset 1h)`,
+		`histogram_quantile(0.9, sum by (le) (sum_over_time({namespace="tns"} | logfmt | unwrap count [5m])))`,
+		`sum(count_over_time({namespace="tns"}[5m])) and on() (hour() >= 9 and hour() < 17)`,
+		`timestamp(sum(count_over_time({namespace="tns"}[5m])))`,
+		`count_over_time({namespace="tns"}[5m] @ 1609746000)`,
+		`sum by (job) (rate({namespace="tns"} |= "level=error" [5m] @ start() offset 1d))`,
+		`rate({namespace="tns"}[5m] @ end())`,
 		`10 / (5/2)`,
 		`(count_over_time({job="postgres"}[5m])/2) or vector(2)`,
 		`10 / (count_over_time({job="postgres"}[5m])/2)`,
@@ -1100,6 +1108,19 @@ func TestLogSelectorExprHasFilter(t *testing.T) {
 	}
 }
 
+func TestResolveAtModifiers(t *testing.T) {
+	query := `sum(rate({app="foo"}[5m] @ end())) / sum(rate({app="foo"}[5m] @ start() offset 1h)) > count_over_time({app="foo"}[5m] @ 1500)`
+	expr, err := ParseExpr(query)
+	require.NoError(t, err)
+	require.True(t, HasStartOrEndAtModifier(expr))
+
+	resolved, err := ResolveAtModifiers(expr, time.Unix(1000, 0), time.Unix(2000, 0))
+	require.NoError(t, err)
+	require.Equal(t, `((sum(rate({app="foo"}[5m] @ 2000)) / sum(rate({app="foo"}[5m] @ 1000 offset 1h0m0s))) > count_over_time({app="foo"}[5m] @ 1500))`, resolved.String())
+	require.False(t, HasStartOrEndAtModifier(resolved))
+	require.Equal(t, MustParseExpr(query).String(), expr.String(), "the original expression is unchanged")
+}
+
 func TestGroupingString(t *testing.T) {
 	g := Grouping{
 		Groups:  []string{"a", "b"},
@@ -87,6 +87,40 @@ func (v *cloneVisitor) VisitLabelReplace(e *LabelReplaceExpr) {
 	v.cloned = mustNewLabelReplaceExpr(left, e.Dst, e.Replacement, e.Src, e.Regex)
 }
 
+func (v *cloneVisitor) VisitSubquery(e *SubqueryExpr) {
+	copied := &SubqueryExpr{
+		Left:      MustClone[SampleExpr](e.Left),
+		Operation: e.Operation,
+		Range:     e.Range,
+		Step:      e.Step,
+		Offset:    e.Offset,
+	}
+
+	if e.Params != nil {
+		tmp := *e.Params
+		copied.Params = &tmp
+	}
+
+	v.cloned = copied
+}
+
+func (v *cloneVisitor) VisitHistogramQuantile(e *HistogramQuantileExpr) {
+	v.cloned = &HistogramQuantileExpr{
+		Left:     MustClone[SampleExpr](e.Left),
+		Quantile: e.Quantile,
+	}
+}
+
+func (v *cloneVisitor) VisitTimeFunction(e *TimeFunctionExpr) {
+	copied := &TimeFunctionExpr{
+		Operation: e.Operation,
+	}
+	if e.Left != nil {
+		copied.Left = MustClone[SampleExpr](e.Left)
+	}
+	v.cloned = copied
+}
+
 func (v *cloneVisitor) VisitLiteral(e *LiteralExpr) {
 	v.cloned = &LiteralExpr{Val: e.Val}
 }
@@ -101,6 +135,10 @@ func (v *cloneVisitor) VisitLogRange(e *LogRangeExpr) {
 		Interval: e.Interval,
 		Offset:   e.Offset,
 	}
+	if e.At != nil {
+		at := *e.At
+		copied.At = &at
+	}
 	if e.Unwrap != nil {
 		copied.Unwrap = &UnwrapExpr{
 			Identifier: e.Unwrap.Identifier,
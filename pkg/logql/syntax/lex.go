@@ -38,6 +38,7 @@ var tokens = map[string]int{
 	"]":            CLOSE_BRACKET,
 	OpLabelReplace: LABEL_REPLACE,
 	OpOffset:       OFFSET,
+	OpAt:           AT,
 	OpOn:           ON,
 	OpIgnoring:     IGNORING,
 	OpGroupLeft:    GROUP_LEFT,
@@ -94,22 +95,35 @@ var parserFlags = map[string]struct{}{
 // functionTokens are tokens that needs to be suffixes with parenthesis
 var functionTokens = map[string]int{
 	// range vec ops
-	OpRangeTypeRate:        RATE,
-	OpRangeTypeRateCounter: RATE_COUNTER,
-	OpRangeTypeCount:       COUNT_OVER_TIME,
-	OpRangeTypeBytesRate:   BYTES_RATE,
-	OpRangeTypeBytes:       BYTES_OVER_TIME,
-	OpRangeTypeAvg:         AVG_OVER_TIME,
-	OpRangeTypeSum:         SUM_OVER_TIME,
-	OpRangeTypeMin:         MIN_OVER_TIME,
-	OpRangeTypeMax:         MAX_OVER_TIME,
-	OpRangeTypeStdvar:      STDVAR_OVER_TIME,
-	OpRangeTypeStddev:      STDDEV_OVER_TIME,
-	OpRangeTypeQuantile:    QUANTILE_OVER_TIME,
-	OpRangeTypeFirst:       FIRST_OVER_TIME,
-	OpRangeTypeLast:        LAST_OVER_TIME,
-	OpRangeTypeAbsent:      ABSENT_OVER_TIME,
-	OpTypeVector:           VECTOR,
+	OpRangeTypeRate:          RATE,
+	OpRangeTypeRateCounter:   RATE_COUNTER,
+	OpRangeTypeCount:         COUNT_OVER_TIME,
+	OpRangeTypeBytesRate:     BYTES_RATE,
+	OpRangeTypeBytes:         BYTES_OVER_TIME,
+	OpRangeTypeAvg:           AVG_OVER_TIME,
+	OpRangeTypeSum:           SUM_OVER_TIME,
+	OpRangeTypeMin:           MIN_OVER_TIME,
+	OpRangeTypeMax:           MAX_OVER_TIME,
+	OpRangeTypeStdvar:        STDVAR_OVER_TIME,
+	OpRangeTypeStddev:        STDDEV_OVER_TIME,
+	OpRangeTypeQuantile:      QUANTILE_OVER_TIME,
+	OpRangeTypeFirst:         FIRST_OVER_TIME,
+	OpRangeTypeLast:          LAST_OVER_TIME,
+	OpRangeTypeAbsent:        ABSENT_OVER_TIME,
+	OpRangeTypeChanges:       CHANGES_OVER_TIME,
+	OpRangeTypeResets:        RESETS_OVER_TIME,
+	OpRangeTypeDeriv:         DERIV,
+	OpRangeTypePredictLinear: PREDICT_LINEAR,
+	OpTypeVector:             VECTOR,
+	OpHistogramQuantile:      HISTOGRAM_QUANTILE,
+	OpHour:                   HOUR,
+	OpMinute:                 MINUTE,
+	OpDayOfWeek:              DAY_OF_WEEK,
+	OpDayOfMonth:             DAY_OF_MONTH,
+	OpDaysInMonth:            DAYS_IN_MONTH,
+	OpMonth:                  MONTH,
+	OpYear:                   YEAR,
+	OpTimestamp:              TIMESTAMP,
 
 	// vec ops
 	OpTypeSum:      SUM,
@@ -134,6 +148,10 @@ var functionTokens = map[string]int{
 
 	// filterOp
 	OpFilterIP: IP,
+
+	// at modifier
+	OpStart: START,
+	OpEnd:   END,
 }
 
 type lexer struct {
@@ -208,6 +226,15 @@ func (l *lexer) Lex(lval *syntaxSymType) int {
 		l.builder.Reset()
 		for r := l.Next(); r != scanner.EOF; r = l.Next() {
 			if r == ']' {
+				if rng, step, ok := strings.Cut(l.builder.String(), ":"); ok {
+					subquery, err := parseSubqueryRange(rng, step)
+					if err != nil {
+						l.Error(err.Error())
+						return 0
+					}
+					lval.subquery = subquery
+					return SUBQUERY
+				}
 				i, err := model.ParseDuration(l.builder.String())
 				if err != nil {
 					l.Error(err.Error())
@@ -290,6 +317,24 @@ func tryScanFlag(l *Scanner) (string, bool) {
 	return flag, true
 }
 
+// parseSubqueryRange parses the range and the optional resolution of a
+// subquery, e.g. `1h:1m` or `1h:`.
+func parseSubqueryRange(rng, step string) (subqueryRange, error) {
+	r, err := model.ParseDuration(rng)
+	if err != nil {
+		return subqueryRange{}, err
+	}
+	subquery := subqueryRange{Range: time.Duration(r)}
+	if step != "" {
+		s, err := model.ParseDuration(step)
+		if err != nil {
+			return subqueryRange{}, err
+		}
+		subquery.Step = time.Duration(s)
+	}
+	return subquery, nil
+}
+
 func tryScanDuration(number string, l *Scanner) (time.Duration, bool) {
 	var sb strings.Builder
 	sb.WriteString(number)
@@ -227,6 +227,24 @@ func validateSampleExpr(expr SampleExpr) error {
 			return e.err
 		}
 		return validateSampleExpr(e.Left)
+	case *SubqueryExpr:
+		if e.err != nil {
+			return e.err
+		}
+		return validateSampleExpr(e.Left)
+	case *HistogramQuantileExpr:
+		if e.err != nil {
+			return e.err
+		}
+		return validateSampleExpr(e.Left)
+	case *TimeFunctionExpr:
+		if e.err != nil {
+			return e.err
+		}
+		if e.Left == nil {
+			return nil
+		}
+		return validateSampleExpr(e.Left)
 	default:
 		selector, err := e.Selector()
 		if err != nil {
@@ -2917,7 +2917,213 @@ var ParseTestCases = []struct {
 	},
 	{
 		in:  `quantile_over_time(foo,{namespace="tns"} |= "level=error" | json |foo>=5,bar<25ms| unwrap latency [5m])`,
-		err: logqlmodel.NewParseError("syntax error: unexpected IDENTIFIER, expecting NUMBER or { or (", 1, 20),
+		err: logqlmodel.NewParseError("syntax error: unexpected IDENTIFIER", 1, 20),
+	},
+	{
+		in: `max_over_time(sum(rate({app="foo"}[1m]))[1h:1m])`,
+		exp: &SubqueryExpr{
+			Left: mustNewVectorAggregationExpr(newRangeAggregationExpr(
+				&LogRangeExpr{
+					Left:     newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "app", "foo")}),
+					Interval: time.Minute,
+				},
+				OpRangeTypeRate, nil, nil,
+			), OpTypeSum, nil, nil),
+			Operation: OpRangeTypeMax,
+			Range:     time.Hour,
+			Step:      time.Minute,
+		},
+	},
+	{
+		in: `quantile_over_time(0.99, count_over_time({app="foo"}[1m])[1h:] offset 5m)`,
+		exp: newSubqueryExpr(
+			newRangeAggregationExpr(
+				&LogRangeExpr{
+					Left:     newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "app", "foo")}),
+					Interval: time.Minute,
+				},
+				OpRangeTypeCount, nil, nil,
+			),
+			OpRangeTypeQuantile, subqueryRange{Range: time.Hour}, newOffsetExpr(5*time.Minute), NewStringLabelFilter("0.99"),
+		),
+	},
+	{
+		in:  `rate(sum(rate({app="foo"}[1m]))[1h:1m])`,
+		err: logqlmodel.NewParseError("invalid aggregation rate of subquery", 0, 0),
+	},
+	{
+		in:  `quantile_over_time(sum(rate({app="foo"}[1m]))[1h:1m])`,
+		err: logqlmodel.NewParseError("parameter required for operation quantile_over_time", 0, 0),
+	},
+	{
+		in: `histogram_quantile(0.99, sum by (le) (sum_over_time({app="foo"} | logfmt | unwrap count [5m])))`,
+		exp: &HistogramQuantileExpr{
+			Left: mustNewVectorAggregationExpr(newRangeAggregationExpr(
+				&LogRangeExpr{
+					Left: newPipelineExpr(
+						newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "app", "foo")}),
+						MultiStageExpr{newLogfmtParserExpr(nil)},
+					),
+					Interval: 5 * time.Minute,
+					Unwrap:   &UnwrapExpr{Identifier: "count"},
+				},
+				OpRangeTypeSum, nil, nil,
+			), OpTypeSum, &Grouping{Groups: []string{"le"}}, nil),
+			Quantile: 0.99,
+		},
+	},
+	{
+		in:  `hour()`,
+		exp: &TimeFunctionExpr{Operation: OpHour},
+	},
+	{
+		in: `day_of_week(sum(count_over_time({app="foo"}[1m])))`,
+		exp: &TimeFunctionExpr{
+			Left: mustNewVectorAggregationExpr(newRangeAggregationExpr(
+				&LogRangeExpr{
+					Left:     newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "app", "foo")}),
+					Interval: time.Minute,
+				},
+				OpRangeTypeCount, nil, nil,
+			), OpTypeSum, nil, nil),
+			Operation: OpDayOfWeek,
+		},
+	},
+	{
+		in: `sum by (hour) (count_over_time({app="foo"} | label_format hour="{{ __timestamp__ | hour }}" [1m]))`,
+		exp: mustNewVectorAggregationExpr(newRangeAggregationExpr(
+			&LogRangeExpr{
+				Left: newPipelineExpr(
+					newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "app", "foo")}),
+					MultiStageExpr{newLabelFmtExpr([]log.LabelFmt{log.NewTemplateLabelFmt("hour", "{{ __timestamp__ | hour }}")})},
+				),
+				Interval: time.Minute,
+			},
+			OpRangeTypeCount, nil, nil,
+		), OpTypeSum, &Grouping{Groups: []string{"hour"}}, nil),
+	},
+	{
+		in:  `timestamp()`,
+		err: logqlmodel.NewParseError("argument required for timestamp", 0, 0),
+	},
+	{
+		in:  `histogram_quantile(1e400, sum by (le) (sum_over_time({app="foo"} | logfmt | unwrap count [5m])))`,
+		err: logqlmodel.NewParseError("invalid parameter for histogram_quantile: strconv.ParseFloat: parsing \"1e400\": value out of range", 0, 0),
+	},
+	{
+		in:  `histogram_quantile(sum by (le) (sum_over_time({app="foo"} | logfmt | unwrap count [5m])))`,
+		err: logqlmodel.NewParseError("syntax error: unexpected SUM, expecting NUMBER", 1, 20),
+	},
+	{
+		in: `count_over_time({app="foo"}[5m] @ 1609746000)`,
+		exp: newRangeAggregationExpr(
+			&LogRangeExpr{
+				Left:     newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "app", "foo")}),
+				Interval: 5 * time.Minute,
+				At:       &AtModifier{Timestamp: time.Unix(1609746000, 0)},
+			},
+			OpRangeTypeCount, nil, nil,
+		),
+	},
+	{
+		in: `sum_over_time({app="foo"} | unwrap bytes [5m] @ 1609746000.5 offset 1h)`,
+		exp: newRangeAggregationExpr(
+			&LogRangeExpr{
+				Left:     newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "app", "foo")}),
+				Interval: 5 * time.Minute,
+				Offset:   time.Hour,
+				At:       &AtModifier{Timestamp: time.UnixMilli(1609746000500)},
+				Unwrap:   newUnwrapExpr("bytes", ""),
+			},
+			OpRangeTypeSum, nil, nil,
+		),
+	},
+	{
+		in: `rate({app="foo"} |= "error" [5m] offset 1h @ start())`,
+		exp: newRangeAggregationExpr(
+			&LogRangeExpr{
+				Left: newPipelineExpr(
+					newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "app", "foo")}),
+					MultiStageExpr{newLineFilterExpr(log.LineMatchEqual, "", "error")},
+				),
+				Interval: 5 * time.Minute,
+				Offset:   time.Hour,
+				At:       &AtModifier{Start: true},
+			},
+			OpRangeTypeRate, nil, nil,
+		),
+	},
+	{
+		in: `sum by (start) (rate({app="foo"}[5m] @ end()))`,
+		exp: mustNewVectorAggregationExpr(newRangeAggregationExpr(
+			&LogRangeExpr{
+				Left:     newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "app", "foo")}),
+				Interval: 5 * time.Minute,
+				At:       &AtModifier{End: true},
+			},
+			OpRangeTypeRate, nil, nil,
+		), OpTypeSum, &Grouping{Groups: []string{"start"}}, nil),
+	},
+	{
+		in:  `rate({app="foo"}[5m] @ now())`,
+		err: logqlmodel.NewParseError("syntax error: unexpected IDENTIFIER, expecting NUMBER or START or END", 1, 24),
+	},
+	{
+		in:  `max_over_time(sum(rate({app="foo"}[1m]))[1h:1m] @ end())`,
+		err: logqlmodel.NewParseError("@ modifier is not supported for subqueries", 0, 0),
+	},
+	{
+		in: `changes_over_time({app="foo"} | unwrap queue_depth [5m])`,
+		exp: newRangeAggregationExpr(
+			newLogRange(newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "app", "foo")}),
+				5*time.Minute, newUnwrapExpr("queue_depth", ""), nil),
+			OpRangeTypeChanges, nil, nil,
+		),
+	},
+	{
+		in: `sum by (app) (resets_over_time({app="foo"} | logfmt | unwrap requests_total [1h]))`,
+		exp: mustNewVectorAggregationExpr(newRangeAggregationExpr(
+			newLogRange(&PipelineExpr{
+				Left:        newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "app", "foo")}),
+				MultiStages: MultiStageExpr{newLogfmtParserExpr(nil)},
+			}, time.Hour, newUnwrapExpr("requests_total", ""), nil),
+			OpRangeTypeResets, nil, nil,
+		), OpTypeSum, &Grouping{Groups: []string{"app"}}, nil),
+	},
+	{
+		in: `deriv({app="foo"} | unwrap queue_depth [10m] offset 5m)`,
+		exp: newRangeAggregationExpr(
+			newLogRange(newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "app", "foo")}),
+				10*time.Minute, newUnwrapExpr("queue_depth", ""), newOffsetExpr(5*time.Minute)),
+			OpRangeTypeDeriv, nil, nil,
+		),
+	},
+	{
+		in: `predict_linear(3600, {app="foo"} | unwrap queue_depth [1h]) > 1000`,
+		exp: mustNewBinOpExpr(OpTypeGT, &BinOpOptions{VectorMatching: &VectorMatching{Card: CardOneToOne}},
+			newRangeAggregationExpr(
+				newLogRange(newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "app", "foo")}),
+					time.Hour, newUnwrapExpr("queue_depth", ""), nil),
+				OpRangeTypePredictLinear, nil, NewStringLabelFilter("3600"),
+			),
+			&LiteralExpr{Val: 1000},
+		),
+	},
+	{
+		in:  `predict_linear({app="foo"} | unwrap queue_depth [1h])`,
+		err: logqlmodel.NewParseError("parameter required for operation predict_linear", 0, 0),
+	},
+	{
+		in:  `deriv(60, {app="foo"} | unwrap queue_depth [1h])`,
+		err: logqlmodel.NewParseError("parameter 60 not supported for operation deriv", 0, 0),
+	},
+	{
+		in:  `changes_over_time({app="foo"}[5m])`,
+		err: logqlmodel.NewParseError("invalid aggregation changes_over_time without unwrap", 0, 0),
+	},
+	{
+		in:  `deriv({app="foo"} | unwrap queue_depth [5m]) by (app)`,
+		err: logqlmodel.NewParseError("grouping not allowed for deriv aggregation", 0, 0),
 	},
 	{
 		in:  `vector(abc)`,
@@ -180,8 +180,8 @@ func (e *LogRangeExpr) Pretty(level int) string {
 	// TODO: this will put [1m] on the same line, not in new line as people used to now.
 	s = fmt.Sprintf("%s [%s]", s, model.Duration(e.Interval))
 
-	if e.Offset != 0 {
-		oe := OffsetExpr{Offset: e.Offset}
+	if e.Offset != 0 || e.At != nil {
+		oe := OffsetExpr{Offset: e.Offset, At: e.At}
 		s += oe.Pretty(level)
 	}
 
@@ -195,7 +195,14 @@ func (e *OffsetExpr) Pretty(_ int) string {
 	// using `model.Duration` as it can format ignoring zero units.
 	// e.g: time.Duration(2 * Hour) -> "2h0m0s"
 	// but model.Duration(2 * Hour) -> "2h"
-	return fmt.Sprintf(" %s %s", OpOffset, model.Duration(e.Offset))
+	var s string
+	if e.At != nil {
+		s = e.At.String()
+	}
+	if e.Offset != 0 || e.At == nil {
+		s += fmt.Sprintf(" %s %s", OpOffset, model.Duration(e.Offset))
+	}
+	return s
 }
 
 // e.g: count_over_time({foo="bar"}[5m])
@@ -226,6 +233,30 @@ func (e *RangeAggregationExpr) Pretty(level int) string {
 	return s
 }
 
+// e.g: max_over_time(sum(rate({foo="bar"}[1m]))[1h:1m])
+func (e *SubqueryExpr) Pretty(level int) string {
+	s := Indent(level)
+	if !NeedSplit(e) {
+		return s + e.String()
+	}
+
+	s += e.Operation
+
+	s += "(\n"
+
+	if e.Params != nil {
+		s = fmt.Sprintf("%s%s%s,", s, Indent(level+1), fmt.Sprint(*e.Params))
+		s += "\n"
+	}
+
+	s += e.Left.Pretty(level + 1)
+	s += "\n" + Indent(level+1) + e.rangeString()
+
+	s += "\n" + Indent(level) + ")"
+
+	return s
+}
+
 // e.g:
 // sum(count_over_time({foo="bar"}[5m])) by (container)
 // topk(10, count_over_time({foo="bar"}[5m])) by (container)
@@ -338,6 +369,42 @@ func (e *LabelReplaceExpr) Pretty(level int) string {
 	return s
 }
 
+// e.g: hour(sum(count_over_time({app="foo"}[5m])))
+func (e *TimeFunctionExpr) Pretty(level int) string {
+	s := Indent(level)
+
+	if e.Left == nil || !NeedSplit(e) {
+		return s + e.String()
+	}
+
+	s += e.Operation
+	s += "(\n"
+	s += e.Left.Pretty(level + 1)
+	s += "\n" + Indent(level) + ")"
+
+	return s
+}
+
+// e.g: histogram_quantile(0.99, sum by (le) (sum_over_time({app="gateway"} | logfmt | unwrap count [5m])))
+func (e *HistogramQuantileExpr) Pretty(level int) string {
+	s := Indent(level)
+
+	if !NeedSplit(e) {
+		return s + e.String()
+	}
+
+	s += OpHistogramQuantile
+
+	s += "(\n"
+
+	s += Indent(level+1) + strconv.FormatFloat(e.Quantile, 'f', -1, 64) + ",\n"
+	s += e.Left.Pretty(level + 1)
+
+	s += "\n" + Indent(level) + ")"
+
+	return s
+}
+
 // e.g: vector(5)
 func (e *VectorExpr) Pretty(level int) string {
 	return commonPrefixIndent(level, e)
@@ -166,6 +166,55 @@ func TestFormat_LabelReplace(t *testing.T) {
   "$1",
   "service",
   "(.*):.*"
+)`,
+		},
+		{
+			name: "subquery",
+			in:   `max_over_time(sum(rate({job="api-server",service="a:c"}|= "err" [5m]))[1h:1m] offset 5m)`,
+			exp: `max_over_time(
+  sum(
+    rate(
+      {job="api-server", service="a:c"}
+        |= "err" [5m]
+    )
+  )
+  [1h:1m] offset 5m0s
+)`,
+		},
+		{
+			name: "time function",
+			in:   `hour(sum(rate({job="api-server",service="a:c"}|= "err" [5m])))`,
+			exp: `hour(
+  sum(
+    rate(
+      {job="api-server", service="a:c"}
+        |= "err" [5m]
+    )
+  )
+)`,
+		},
+		{
+			name: "histogram quantile",
+			in:   `histogram_quantile(0.99, sum by (le) (sum_over_time({job="api-server"} | logfmt | unwrap count [5m])))`,
+			exp: `histogram_quantile(
+  0.99,
+  sum by (le)(
+    sum_over_time(
+      {job="api-server"}
+        | logfmt
+        | unwrap count [5m]
+    )
+  )
+)`,
+		},
+		{
+			name: "at modifier",
+			in:   `sum(rate({job="api-server",service="a:c"}|= "err" [5m] @ start() offset 1h))`,
+			exp: `sum(
+  rate(
+    {job="api-server", service="a:c"}
+      |= "err" [5m] @ start() offset 1h
+  )
 )`,
 		},
 		{
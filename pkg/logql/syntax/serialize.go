@@ -35,10 +35,13 @@ const (
 	Binary              = "binary"
 	Bytes               = "bytes"
 	And                 = "and"
+	At                  = "at"
 	Card                = "cardinality"
 	Dst                 = "dst"
+	End                 = "end"
 	Duration            = "duration"
 	Groups              = "groups"
+	HistogramQuantile   = "histogram_quantile"
 	GroupingField       = "grouping"
 	Include             = "include"
 	Identifier          = "identifier"
@@ -68,7 +71,12 @@ const (
 	ReturnBool          = "return_bool"
 	RHS                 = "rhs"
 	Src                 = "src"
+	Start               = "start"
+	StepNanos           = "step_nanos"
 	StringField         = "string"
+	TimestampNanos      = "timestamp_nanos"
+	TimeFunction        = "time_function"
+	Subquery            = "subquery"
 	NoopField           = "noop"
 	Type                = "type"
 	Unwrap              = "unwrap"
@@ -98,6 +106,12 @@ func DecodeJSON(raw string) (Expr, error) {
 		return decodeVector(iter)
 	case LabelReplace:
 		return decodeLabelReplace(iter)
+	case Subquery:
+		return decodeSubquery(iter)
+	case HistogramQuantile:
+		return decodeHistogramQuantile(iter)
+	case TimeFunction:
+		return decodeTimeFunction(iter)
 	case LogSelector:
 		return decodeLogSelector(iter)
 	case Variants:
@@ -208,6 +222,41 @@ func (v *JSONSerializer) VisitRangeAggregation(e *RangeAggregationExpr) {
 	v.Flush()
 }
 
+func (v *JSONSerializer) VisitSubquery(e *SubqueryExpr) {
+	v.WriteObjectStart()
+
+	v.WriteObjectField(Subquery)
+	v.WriteObjectStart()
+
+	v.WriteObjectField(Op)
+	v.WriteString(e.Operation)
+
+	if e.Params != nil {
+		v.WriteMore()
+		v.WriteObjectField(Params)
+		v.WriteFloat64(*e.Params)
+	}
+
+	v.WriteMore()
+	v.WriteObjectField(IntervalNanos)
+	v.WriteInt64(int64(e.Range))
+	v.WriteMore()
+	v.WriteObjectField(StepNanos)
+	v.WriteInt64(int64(e.Step))
+	v.WriteMore()
+	v.WriteObjectField(OffsetNanos)
+	v.WriteInt64(int64(e.Offset))
+
+	v.WriteMore()
+	v.WriteObjectField(Inner)
+	e.Left.Accept(v)
+
+	v.WriteObjectEnd()
+
+	v.WriteObjectEnd()
+	v.Flush()
+}
+
 func (v *JSONSerializer) VisitLogRange(e *LogRangeExpr) {
 	v.WriteObjectStart()
 
@@ -217,6 +266,12 @@ func (v *JSONSerializer) VisitLogRange(e *LogRangeExpr) {
 	v.WriteObjectField(OffsetNanos)
 	v.WriteInt64(int64(e.Offset))
 
+	if e.At != nil {
+		v.WriteMore()
+		v.WriteObjectField(At)
+		encodeAtModifier(v.Stream, e.At)
+	}
+
 	// Serialize log selector pipeline as string.
 	v.WriteMore()
 	v.WriteObjectField(LogSelector)
@@ -262,6 +317,44 @@ func (v *JSONSerializer) VisitLabelReplace(e *LabelReplaceExpr) {
 	v.Flush()
 }
 
+func (v *JSONSerializer) VisitTimeFunction(e *TimeFunctionExpr) {
+	v.WriteObjectStart()
+
+	v.WriteObjectField(TimeFunction)
+	v.WriteObjectStart()
+
+	v.WriteObjectField(Op)
+	v.WriteString(e.Operation)
+
+	if e.Left != nil {
+		v.WriteMore()
+		v.WriteObjectField(Inner)
+		e.Left.Accept(v)
+	}
+
+	v.WriteObjectEnd()
+	v.WriteObjectEnd()
+	v.Flush()
+}
+
+func (v *JSONSerializer) VisitHistogramQuantile(e *HistogramQuantileExpr) {
+	v.WriteObjectStart()
+
+	v.WriteObjectField(HistogramQuantile)
+	v.WriteObjectStart()
+
+	v.WriteObjectField(Params)
+	v.WriteFloat64(e.Quantile)
+
+	v.WriteMore()
+	v.WriteObjectField(Inner)
+	e.Left.Accept(v)
+
+	v.WriteObjectEnd()
+	v.WriteObjectEnd()
+	v.Flush()
+}
+
 func (v *JSONSerializer) VisitLiteral(e *LiteralExpr) {
 	v.WriteObjectStart()
 
@@ -748,6 +841,12 @@ func decodeSample(iter *jsoniter.Iterator) (SampleExpr, error) {
 			expr, err = decodeVector(iter)
 		case LabelReplace:
 			expr, err = decodeLabelReplace(iter)
+		case Subquery:
+			expr, err = decodeSubquery(iter)
+		case HistogramQuantile:
+			expr, err = decodeHistogramQuantile(iter)
+		case TimeFunction:
+			expr, err = decodeTimeFunction(iter)
 		default:
 			return nil, fmt.Errorf("unknown sample expression type: %s", key)
 		}
@@ -889,6 +988,31 @@ func decodeRangeAgg(iter *jsoniter.Iterator) (*RangeAggregationExpr, error) {
 	return expr, err
 }
 
+func decodeSubquery(iter *jsoniter.Iterator) (*SubqueryExpr, error) {
+	expr := &SubqueryExpr{}
+	var err error
+
+	for f := iter.ReadObject(); f != ""; f = iter.ReadObject() {
+		switch f {
+		case Op:
+			expr.Operation = iter.ReadString()
+		case Params:
+			tmp := iter.ReadFloat64()
+			expr.Params = &tmp
+		case IntervalNanos:
+			expr.Range = time.Duration(iter.ReadInt64())
+		case StepNanos:
+			expr.Step = time.Duration(iter.ReadInt64())
+		case OffsetNanos:
+			expr.Offset = time.Duration(iter.ReadInt64())
+		case Inner:
+			expr.Left, err = decodeSample(iter)
+		}
+	}
+
+	return expr, err
+}
+
 func decodeLogRange(iter *jsoniter.Iterator) (*LogRangeExpr, error) {
 	expr := &LogRangeExpr{}
 	var err error
@@ -901,6 +1025,8 @@ func decodeLogRange(iter *jsoniter.Iterator) (*LogRangeExpr, error) {
 			expr.Interval = time.Duration(iter.ReadInt64())
 		case OffsetNanos:
 			expr.Offset = time.Duration(iter.ReadInt64())
+		case At:
+			expr.At = decodeAtModifier(iter)
 		case Unwrap:
 			expr.Unwrap = decodeUnwrap(iter)
 		}
@@ -909,6 +1035,34 @@ func decodeLogRange(iter *jsoniter.Iterator) (*LogRangeExpr, error) {
 	return expr, err
 }
 
+func encodeAtModifier(s *jsoniter.Stream, at *AtModifier) {
+	s.WriteObjectStart()
+	s.WriteObjectField(TimestampNanos)
+	s.WriteInt64(at.Timestamp.UnixNano())
+	s.WriteMore()
+	s.WriteObjectField(Start)
+	s.WriteBool(at.Start)
+	s.WriteMore()
+	s.WriteObjectField(End)
+	s.WriteBool(at.End)
+	s.WriteObjectEnd()
+}
+
+func decodeAtModifier(iter *jsoniter.Iterator) *AtModifier {
+	at := &AtModifier{}
+	for f := iter.ReadObject(); f != ""; f = iter.ReadObject() {
+		switch f {
+		case TimestampNanos:
+			at.Timestamp = time.Unix(0, iter.ReadInt64())
+		case Start:
+			at.Start = iter.ReadBool()
+		case End:
+			at.End = iter.ReadBool()
+		}
+	}
+	return at
+}
+
 func decodeLabelReplace(iter *jsoniter.Iterator) (*LabelReplaceExpr, error) {
 	var err error
 	var left SampleExpr
@@ -935,6 +1089,38 @@ func decodeLabelReplace(iter *jsoniter.Iterator) (*LabelReplaceExpr, error) {
 	return mustNewLabelReplaceExpr(left, dst, replacement, src, regex), nil
 }
 
+func decodeTimeFunction(iter *jsoniter.Iterator) (*TimeFunctionExpr, error) {
+	expr := &TimeFunctionExpr{}
+	var err error
+
+	for f := iter.ReadObject(); f != ""; f = iter.ReadObject() {
+		switch f {
+		case Op:
+			expr.Operation = iter.ReadString()
+		case Inner:
+			expr.Left, err = decodeSample(iter)
+		}
+	}
+
+	return expr, err
+}
+
+func decodeHistogramQuantile(iter *jsoniter.Iterator) (*HistogramQuantileExpr, error) {
+	expr := &HistogramQuantileExpr{}
+	var err error
+
+	for f := iter.ReadObject(); f != ""; f = iter.ReadObject() {
+		switch f {
+		case Params:
+			expr.Quantile = iter.ReadFloat64()
+		case Inner:
+			expr.Left, err = decodeSample(iter)
+		}
+	}
+
+	return expr, err
+}
+
 func decodeLiteral(iter *jsoniter.Iterator) (*LiteralExpr, error) {
 	expr := &LiteralExpr{}
 
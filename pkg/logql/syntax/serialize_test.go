@@ -42,6 +42,21 @@ func TestJSONSerializationRoundTrip(t *testing.T) {
 		"label replace": {
 			query: `label_replace(vector(0.000000),"foo","bar","","")`,
 		},
+		"subquery": {
+			query: `quantile_over_time(0.99,sum by (app) (rate({app="foo"}[1m]))[1h:1m] offset 5m)`,
+		},
+		"time function": {
+			query: `hour(sum by (app) (rate({app="foo"}[1m])))`,
+		},
+		"time function without argument": {
+			query: `day_of_week()`,
+		},
+		"histogram quantile": {
+			query: `histogram_quantile(0.99,sum by (le) (sum_over_time({app="foo"} | logfmt | unwrap count [5m])))`,
+		},
+		"at modifier": {
+			query: `sum(rate({app="foo"}[1m] @ 1609746000.5 offset 5m)) / sum(rate({app="foo"}[1m] @ end()))`,
+		},
 		"filters with bytes": {
 			query: `{app="foo"} |= "bar" | json | ( status_code <500 or ( status_code>200 , size>=2.5KiB ) )`,
 		},
@@ -43,18 +43,20 @@ import (
   labelExtractionExpressionList []log.LabelExtractionExpr
   unwrapExpr *UnwrapExpr
   offsetExpr *OffsetExpr
+  atModifier *AtModifier
+  subquery subqueryRange
 }
 
 %start root
 
 %type <expr> expr
 %type <logExpr> logExpr
-%type <metricExpr> metricExpr rangeAggregationExpr vectorAggregationExpr binOpExpr labelReplaceExpr vectorExpr
+%type <metricExpr> metricExpr rangeAggregationExpr subqueryExpr vectorAggregationExpr binOpExpr labelReplaceExpr histogramQuantileExpr timeFunctionExpr vectorExpr
 %type <variantsExpr> variantsExpr
 %type <stage> pipelineStage logfmtParser labelParser jsonExpressionParser logfmtExpressionParser lineFormatExpr decolorizeExpr labelFormatExpr dropLabelsExpr keepLabelsExpr
 %type <stages> pipelineExpr
 %type <lineFilterExpr> lineFilter lineFilters orFilter
-%type <op> rangeOp convOp vectorOp filterOp
+%type <op> rangeOp convOp vectorOp filterOp timeFunction
 %type <filterer> bytesFilter numberFilter durationFilter labelFilter unitFilter ipLabelFilter
 %type <filter> filter
 %type <matcher> matcher
@@ -73,18 +75,21 @@ import (
 %type <labelExtractionExpressionList> labelExtractionExpressionList
 %type <unwrapExpr> unwrapExpr
 %type <offsetExpr> offsetExpr
+%type <atModifier> atModifier
 %type <metricExprs> metricExprs
 
 %token <bytes> BYTES
 %token <str> IDENTIFIER STRING NUMBER FUNCTION_FLAG
 %token <dur> DURATION RANGE
+%token <subquery> SUBQUERY
 %token <val> MATCHERS LABELS EQ RE NRE NPA OPEN_BRACE CLOSE_BRACE OPEN_BRACKET CLOSE_BRACKET COMMA DOT PIPE_MATCH PIPE_EXACT PIPE_PATTERN
              OPEN_PARENTHESIS CLOSE_PARENTHESIS BY WITHOUT COUNT_OVER_TIME RATE RATE_COUNTER SUM SORT SORT_DESC AVG
              MAX MIN COUNT STDDEV STDVAR BOTTOMK TOPK APPROX_TOPK
              BYTES_OVER_TIME BYTES_RATE BOOL JSON REGEXP LOGFMT PIPE LINE_FMT LABEL_FMT UNWRAP AVG_OVER_TIME SUM_OVER_TIME MIN_OVER_TIME
              MAX_OVER_TIME STDVAR_OVER_TIME STDDEV_OVER_TIME QUANTILE_OVER_TIME BYTES_CONV DURATION_CONV DURATION_SECONDS_CONV
              FIRST_OVER_TIME LAST_OVER_TIME ABSENT_OVER_TIME VECTOR LABEL_REPLACE UNPACK OFFSET PATTERN IP ON IGNORING GROUP_LEFT GROUP_RIGHT
-             DECOLORIZE DROP KEEP VARIANTS OF
+             DECOLORIZE DROP KEEP VARIANTS OF AT START END CHANGES_OVER_TIME RESETS_OVER_TIME DERIV PREDICT_LINEAR
+             HISTOGRAM_QUANTILE HOUR MINUTE DAY_OF_WEEK DAY_OF_MONTH DAYS_IN_MONTH MONTH YEAR TIMESTAMP
 
 // Operators are listed with increasing precedence.
 %left <binOp> OR
@@ -113,10 +118,13 @@ logExpr:
 
 metricExpr:
       rangeAggregationExpr                          { $$ = $1 }
+    | subqueryExpr                                  { $$ = $1 }
     | vectorAggregationExpr                         { $$ = $1 }
     | binOpExpr                                     { $$ = $1 }
     | literalExpr                                   { $$ = $1 }
     | labelReplaceExpr                              { $$ = $1 }
+    | histogramQuantileExpr                         { $$ = $1 }
+    | timeFunctionExpr                              { $$ = $1 }
     | vectorExpr                                    { $$ = $1 }
     | OPEN_PARENTHESIS metricExpr CLOSE_PARENTHESIS { $$ = $2 }
     ;
@@ -173,6 +181,13 @@ rangeAggregationExpr:
     | rangeOp OPEN_PARENTHESIS NUMBER COMMA logRangeExpr CLOSE_PARENTHESIS grouping  { $$ = newRangeAggregationExpr($5, $1, $7, &$3) }
     ;
 
+subqueryExpr:
+      rangeOp OPEN_PARENTHESIS metricExpr SUBQUERY CLOSE_PARENTHESIS                                   { $$ = newSubqueryExpr($3, $1, $4, nil, nil) }
+    | rangeOp OPEN_PARENTHESIS metricExpr SUBQUERY offsetExpr CLOSE_PARENTHESIS                        { $$ = newSubqueryExpr($3, $1, $4, $5, nil) }
+    | rangeOp OPEN_PARENTHESIS NUMBER COMMA metricExpr SUBQUERY CLOSE_PARENTHESIS                      { $$ = newSubqueryExpr($5, $1, $6, nil, &$3) }
+    | rangeOp OPEN_PARENTHESIS NUMBER COMMA metricExpr SUBQUERY offsetExpr CLOSE_PARENTHESIS           { $$ = newSubqueryExpr($5, $1, $6, $7, &$3) }
+    ;
+
 vectorAggregationExpr:
     // Aggregations with 1 argument.
       vectorOp OPEN_PARENTHESIS metricExpr CLOSE_PARENTHESIS                               { $$ = mustNewVectorAggregationExpr($3, $1, nil, nil) }
@@ -189,6 +204,27 @@ labelReplaceExpr:
       { $$ = mustNewLabelReplaceExpr($3, $5, $7, $9, $11)}
     ;
 
+histogramQuantileExpr:
+    HISTOGRAM_QUANTILE OPEN_PARENTHESIS NUMBER COMMA metricExpr CLOSE_PARENTHESIS
+      { $$ = newHistogramQuantileExpr($5, $3) }
+    ;
+
+timeFunctionExpr:
+      timeFunction OPEN_PARENTHESIS CLOSE_PARENTHESIS              { $$ = newTimeFunctionExpr(nil, $1) }
+    | timeFunction OPEN_PARENTHESIS metricExpr CLOSE_PARENTHESIS   { $$ = newTimeFunctionExpr($3, $1) }
+    ;
+
+timeFunction:
+      HOUR          { $$ = OpHour }
+    | MINUTE        { $$ = OpMinute }
+    | DAY_OF_WEEK   { $$ = OpDayOfWeek }
+    | DAY_OF_MONTH  { $$ = OpDayOfMonth }
+    | DAYS_IN_MONTH { $$ = OpDaysInMonth }
+    | MONTH         { $$ = OpMonth }
+    | YEAR          { $$ = OpYear }
+    | TIMESTAMP     { $$ = OpTimestamp }
+    ;
+
 selector:
       OPEN_BRACE matchers CLOSE_BRACE  { $$ = $2 }
     | OPEN_BRACE matchers error        { $$ = $2 }
@@ -505,10 +541,24 @@ rangeOp:
     | FIRST_OVER_TIME    { $$ = OpRangeTypeFirst }
     | LAST_OVER_TIME     { $$ = OpRangeTypeLast }
     | ABSENT_OVER_TIME   { $$ = OpRangeTypeAbsent }
+    | CHANGES_OVER_TIME  { $$ = OpRangeTypeChanges }
+    | RESETS_OVER_TIME   { $$ = OpRangeTypeResets }
+    | DERIV              { $$ = OpRangeTypeDeriv }
+    | PREDICT_LINEAR     { $$ = OpRangeTypePredictLinear }
     ;
 
 offsetExpr:
-    OFFSET DURATION { $$ = newOffsetExpr( $2 ) }
+      OFFSET DURATION               { $$ = newOffsetExpr( $2 ) }
+    | atModifier                    { $$ = &OffsetExpr{ At: $1 } }
+    | OFFSET DURATION atModifier    { $$ = newOffsetExpr( $2 ).withAt( $3 ) }
+    | atModifier OFFSET DURATION    { $$ = newOffsetExpr( $3 ).withAt( $1 ) }
+    ;
+
+atModifier:
+      AT NUMBER                                     { $$ = newAtModifier(mustNewFloat($2)) }
+    | AT START OPEN_PARENTHESIS CLOSE_PARENTHESIS   { $$ = &AtModifier{ Start: true } }
+    | AT END OPEN_PARENTHESIS CLOSE_PARENTHESIS     { $$ = &AtModifier{ End: true } }
+    ;
 
 labels:
       IDENTIFIER                 { $$ = []string{ $1 } }
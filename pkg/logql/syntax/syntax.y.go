@@ -45,6 +45,8 @@ type syntaxSymType struct {
 	labelExtractionExpressionList []log.LabelExtractionExpr
 	unwrapExpr                    *UnwrapExpr
 	offsetExpr                    *OffsetExpr
+	atModifier                    *AtModifier
+	subquery                      subqueryRange
 }
 
 const BYTES = 57346
@@ -54,93 +56,110 @@ const NUMBER = 57349
 const FUNCTION_FLAG = 57350
 const DURATION = 57351
 const RANGE = 57352
-const MATCHERS = 57353
-const LABELS = 57354
-const EQ = 57355
-const RE = 57356
-const NRE = 57357
-const NPA = 57358
-const OPEN_BRACE = 57359
-const CLOSE_BRACE = 57360
-const OPEN_BRACKET = 57361
-const CLOSE_BRACKET = 57362
-const COMMA = 57363
-const DOT = 57364
-const PIPE_MATCH = 57365
-const PIPE_EXACT = 57366
-const PIPE_PATTERN = 57367
-const OPEN_PARENTHESIS = 57368
-const CLOSE_PARENTHESIS = 57369
-const BY = 57370
-const WITHOUT = 57371
-const COUNT_OVER_TIME = 57372
-const RATE = 57373
-const RATE_COUNTER = 57374
-const SUM = 57375
-const SORT = 57376
-const SORT_DESC = 57377
-const AVG = 57378
-const MAX = 57379
-const MIN = 57380
-const COUNT = 57381
-const STDDEV = 57382
-const STDVAR = 57383
-const BOTTOMK = 57384
-const TOPK = 57385
-const APPROX_TOPK = 57386
-const BYTES_OVER_TIME = 57387
-const BYTES_RATE = 57388
-const BOOL = 57389
-const JSON = 57390
-const REGEXP = 57391
-const LOGFMT = 57392
-const PIPE = 57393
-const LINE_FMT = 57394
-const LABEL_FMT = 57395
-const UNWRAP = 57396
-const AVG_OVER_TIME = 57397
-const SUM_OVER_TIME = 57398
-const MIN_OVER_TIME = 57399
-const MAX_OVER_TIME = 57400
-const STDVAR_OVER_TIME = 57401
-const STDDEV_OVER_TIME = 57402
-const QUANTILE_OVER_TIME = 57403
-const BYTES_CONV = 57404
-const DURATION_CONV = 57405
-const DURATION_SECONDS_CONV = 57406
-const FIRST_OVER_TIME = 57407
-const LAST_OVER_TIME = 57408
-const ABSENT_OVER_TIME = 57409
-const VECTOR = 57410
-const LABEL_REPLACE = 57411
-const UNPACK = 57412
-const OFFSET = 57413
-const PATTERN = 57414
-const IP = 57415
-const ON = 57416
-const IGNORING = 57417
-const GROUP_LEFT = 57418
-const GROUP_RIGHT = 57419
-const DECOLORIZE = 57420
-const DROP = 57421
-const KEEP = 57422
-const VARIANTS = 57423
-const OF = 57424
-const OR = 57425
-const AND = 57426
-const UNLESS = 57427
-const CMP_EQ = 57428
-const NEQ = 57429
-const LT = 57430
-const LTE = 57431
-const GT = 57432
-const GTE = 57433
-const ADD = 57434
-const SUB = 57435
-const MUL = 57436
-const DIV = 57437
-const MOD = 57438
-const POW = 57439
+const SUBQUERY = 57353
+const MATCHERS = 57354
+const LABELS = 57355
+const EQ = 57356
+const RE = 57357
+const NRE = 57358
+const NPA = 57359
+const OPEN_BRACE = 57360
+const CLOSE_BRACE = 57361
+const OPEN_BRACKET = 57362
+const CLOSE_BRACKET = 57363
+const COMMA = 57364
+const DOT = 57365
+const PIPE_MATCH = 57366
+const PIPE_EXACT = 57367
+const PIPE_PATTERN = 57368
+const OPEN_PARENTHESIS = 57369
+const CLOSE_PARENTHESIS = 57370
+const BY = 57371
+const WITHOUT = 57372
+const COUNT_OVER_TIME = 57373
+const RATE = 57374
+const RATE_COUNTER = 57375
+const SUM = 57376
+const SORT = 57377
+const SORT_DESC = 57378
+const AVG = 57379
+const MAX = 57380
+const MIN = 57381
+const COUNT = 57382
+const STDDEV = 57383
+const STDVAR = 57384
+const BOTTOMK = 57385
+const TOPK = 57386
+const APPROX_TOPK = 57387
+const BYTES_OVER_TIME = 57388
+const BYTES_RATE = 57389
+const BOOL = 57390
+const JSON = 57391
+const REGEXP = 57392
+const LOGFMT = 57393
+const PIPE = 57394
+const LINE_FMT = 57395
+const LABEL_FMT = 57396
+const UNWRAP = 57397
+const AVG_OVER_TIME = 57398
+const SUM_OVER_TIME = 57399
+const MIN_OVER_TIME = 57400
+const MAX_OVER_TIME = 57401
+const STDVAR_OVER_TIME = 57402
+const STDDEV_OVER_TIME = 57403
+const QUANTILE_OVER_TIME = 57404
+const BYTES_CONV = 57405
+const DURATION_CONV = 57406
+const DURATION_SECONDS_CONV = 57407
+const FIRST_OVER_TIME = 57408
+const LAST_OVER_TIME = 57409
+const ABSENT_OVER_TIME = 57410
+const VECTOR = 57411
+const LABEL_REPLACE = 57412
+const UNPACK = 57413
+const OFFSET = 57414
+const PATTERN = 57415
+const IP = 57416
+const ON = 57417
+const IGNORING = 57418
+const GROUP_LEFT = 57419
+const GROUP_RIGHT = 57420
+const DECOLORIZE = 57421
+const DROP = 57422
+const KEEP = 57423
+const VARIANTS = 57424
+const OF = 57425
+const AT = 57426
+const START = 57427
+const END = 57428
+const CHANGES_OVER_TIME = 57429
+const RESETS_OVER_TIME = 57430
+const DERIV = 57431
+const PREDICT_LINEAR = 57432
+const HISTOGRAM_QUANTILE = 57433
+const HOUR = 57434
+const MINUTE = 57435
+const DAY_OF_WEEK = 57436
+const DAY_OF_MONTH = 57437
+const DAYS_IN_MONTH = 57438
+const MONTH = 57439
+const YEAR = 57440
+const TIMESTAMP = 57441
+const OR = 57442
+const AND = 57443
+const UNLESS = 57444
+const CMP_EQ = 57445
+const NEQ = 57446
+const LT = 57447
+const LTE = 57448
+const GT = 57449
+const GTE = 57450
+const ADD = 57451
+const SUB = 57452
+const MUL = 57453
+const DIV = 57454
+const MOD = 57455
+const POW = 57456
 
 var syntaxToknames = [...]string{
 	"$end",
@@ -153,6 +172,7 @@ var syntaxToknames = [...]string{
 	"FUNCTION_FLAG",
 	"DURATION",
 	"RANGE",
+	"SUBQUERY",
 	"MATCHERS",
 	"LABELS",
 	"EQ",
@@ -225,6 +245,22 @@ var syntaxToknames = [...]string{
 	"KEEP",
 	"VARIANTS",
 	"OF",
+	"AT",
+	"START",
+	"END",
+	"CHANGES_OVER_TIME",
+	"RESETS_OVER_TIME",
+	"DERIV",
+	"PREDICT_LINEAR",
+	"HISTOGRAM_QUANTILE",
+	"HOUR",
+	"MINUTE",
+	"DAY_OF_WEEK",
+	"DAY_OF_MONTH",
+	"DAYS_IN_MONTH",
+	"MONTH",
+	"YEAR",
+	"TIMESTAMP",
 	"OR",
 	"AND",
 	"UNLESS",
@@ -241,294 +277,362 @@ var syntaxToknames = [...]string{
 	"MOD",
 	"POW",
 }
+
 var syntaxStatenames = [...]string{}
 
 const syntaxEofCode = 1
 const syntaxErrCode = 2
 const syntaxInitialStackSize = 16
 
-var syntaxExca = [...]int{
+var syntaxExca = [...]int16{
 	-1, 1,
 	1, -1,
 	-2, 0,
-	-1, 150,
-	21, 227,
-	27, 227,
+	-1, 169,
+	22, 255,
+	28, 255,
 	-2, 3,
-	-1, 290,
-	21, 228,
-	27, 228,
+	-1, 316,
+	22, 256,
+	28, 256,
 	-2, 3,
 }
 
 const syntaxPrivate = 57344
 
-const syntaxLast = 642
-
-var syntaxAct = [...]int{
-
-	293, 232, 88, 4, 217, 67, 130, 6, 188, 206,
-	158, 79, 203, 195, 66, 241, 205, 193, 80, 2,
-	59, 286, 84, 51, 52, 53, 60, 61, 64, 65,
-	62, 63, 54, 55, 56, 57, 58, 59, 143, 218,
-	11, 52, 53, 60, 61, 64, 65, 62, 63, 54,
-	55, 56, 57, 58, 59, 60, 61, 64, 65, 62,
-	63, 54, 55, 56, 57, 58, 59, 56, 57, 58,
-	59, 289, 113, 144, 210, 156, 157, 296, 119, 54,
-	55, 56, 57, 58, 59, 150, 301, 154, 156, 157,
-	269, 162, 225, 18, 160, 268, 265, 167, 224, 18,
-	284, 264, 219, 18, 281, 283, 146, 18, 278, 280,
-	98, 18, 70, 277, 169, 172, 173, 298, 174, 175,
-	176, 177, 178, 179, 180, 181, 182, 183, 184, 185,
-	186, 187, 275, 170, 171, 18, 272, 274, 371, 18,
-	146, 271, 371, 200, 197, 208, 208, 216, 211, 214,
-	215, 212, 213, 343, 297, 140, 209, 392, 296, 267,
-	223, 155, 228, 343, 387, 263, 297, 310, 239, 235,
-	140, 190, 236, 360, 145, 233, 134, 228, 19, 20,
-	374, 114, 244, 350, 19, 20, 190, 380, 19, 20,
-	379, 134, 19, 20, 298, 298, 19, 20, 252, 253,
-	254, 344, 335, 299, 298, 75, 77, 298, 75, 77,
-	256, 89, 90, 72, 73, 74, 72, 73, 74, 378,
-	19, 20, 228, 243, 19, 20, 290, 87, 368, 89,
-	90, 376, 291, 294, 189, 300, 228, 303, 160, 113,
-	306, 292, 307, 119, 234, 320, 295, 305, 191, 189,
-	304, 266, 270, 273, 276, 279, 282, 285, 346, 347,
-	348, 229, 310, 363, 314, 316, 319, 321, 359, 208,
-	299, 322, 328, 324, 353, 75, 77, 76, 140, 333,
-	76, 243, 308, 72, 73, 74, 310, 351, 140, 247,
-	310, 331, 358, 243, 190, 336, 357, 338, 340, 134,
-	342, 113, 243, 318, 140, 243, 352, 341, 337, 134,
-	113, 234, 310, 354, 237, 317, 310, 222, 312, 243,
-	190, 148, 311, 221, 315, 134, 259, 245, 147, 334,
-	159, 126, 127, 125, 330, 135, 137, 301, 365, 366,
-	15, 242, 160, 113, 367, 364, 140, 76, 15, 161,
-	369, 370, 329, 128, 287, 129, 375, 161, 251, 250,
-	249, 136, 138, 139, 231, 18, 248, 134, 220, 75,
-	77, 382, 166, 383, 384, 15, 165, 72, 73, 74,
-	261, 302, 191, 189, 7, 164, 388, 94, 23, 24,
-	25, 38, 47, 48, 39, 41, 42, 40, 43, 44,
-	45, 46, 49, 26, 27, 234, 93, 86, 81, 390,
-	386, 356, 152, 28, 29, 30, 31, 32, 33, 34,
-	257, 309, 262, 35, 36, 37, 50, 21, 151, 231,
-	240, 153, 260, 246, 75, 77, 238, 75, 77, 14,
-	15, 76, 72, 73, 74, 72, 73, 74, 230, 7,
-	19, 20, 258, 23, 24, 25, 38, 47, 48, 39,
-	41, 42, 40, 43, 44, 45, 46, 49, 26, 27,
-	234, 385, 85, 234, 373, 372, 349, 339, 28, 29,
-	30, 31, 32, 33, 34, 83, 326, 327, 35, 36,
-	37, 50, 21, 296, 196, 163, 196, 255, 391, 194,
-	3, 168, 75, 77, 14, 15, 76, 92, 78, 76,
-	72, 73, 74, 91, 7, 19, 20, 389, 23, 24,
-	25, 38, 47, 48, 39, 41, 42, 40, 43, 44,
-	45, 46, 49, 26, 27, 377, 362, 361, 234, 332,
-	323, 149, 313, 28, 29, 30, 31, 32, 33, 34,
-	75, 77, 140, 35, 36, 37, 50, 21, 72, 73,
-	74, 325, 288, 227, 204, 202, 226, 225, 224, 14,
-	201, 199, 198, 134, 76, 95, 381, 355, 207, 196,
-	19, 20, 85, 204, 97, 96, 69, 192, 22, 82,
-	71, 131, 132, 141, 133, 126, 127, 125, 142, 135,
-	137, 17, 345, 16, 68, 124, 123, 122, 121, 120,
-	118, 117, 116, 115, 5, 13, 12, 128, 10, 129,
-	9, 8, 76, 1, 0, 136, 138, 139, 99, 100,
-	101, 102, 103, 104, 105, 106, 107, 108, 109, 110,
-	111, 112,
+const syntaxLast = 1104
+
+var syntaxAct = [...]int16{
+	257, 6, 84, 323, 240, 83, 149, 211, 105, 229,
+	260, 321, 226, 4, 265, 3, 218, 216, 228, 97,
+	2, 96, 76, 95, 312, 101, 68, 69, 70, 77,
+	78, 81, 82, 79, 80, 71, 72, 73, 74, 75,
+	76, 162, 242, 12, 69, 70, 77, 78, 81, 82,
+	79, 80, 71, 72, 73, 74, 75, 76, 77, 78,
+	81, 82, 79, 80, 71, 72, 73, 74, 75, 76,
+	71, 72, 73, 74, 75, 76, 73, 74, 75, 76,
+	87, 372, 295, 322, 248, 21, 132, 294, 291, 324,
+	247, 21, 315, 290, 138, 324, 420, 310, 195, 196,
+	21, 307, 309, 371, 21, 180, 306, 193, 194, 332,
+	304, 407, 169, 21, 177, 303, 322, 179, 182, 233,
+	175, 176, 301, 331, 187, 21, 190, 300, 324, 320,
+	298, 164, 376, 21, 192, 297, 241, 163, 197, 198,
+	199, 200, 201, 202, 203, 204, 205, 206, 207, 208,
+	209, 210, 293, 445, 159, 322, 420, 330, 289, 373,
+	374, 223, 117, 220, 231, 231, 133, 324, 106, 107,
+	251, 213, 341, 322, 331, 325, 153, 232, 399, 246,
+	440, 92, 94, 259, 432, 324, 255, 22, 23, 89,
+	90, 91, 431, 22, 23, 96, 427, 95, 263, 331,
+	376, 268, 22, 23, 165, 165, 22, 23, 239, 234,
+	237, 238, 235, 236, 430, 22, 23, 258, 417, 426,
+	278, 279, 280, 104, 415, 106, 107, 22, 23, 425,
+	330, 325, 423, 282, 402, 22, 23, 92, 94, 173,
+	175, 176, 331, 92, 94, 89, 90, 91, 390, 387,
+	212, 89, 90, 91, 18, 180, 326, 328, 132, 316,
+	335, 317, 329, 405, 318, 333, 138, 319, 327, 93,
+	337, 159, 331, 258, 395, 391, 338, 292, 296, 299,
+	302, 305, 308, 311, 369, 345, 251, 365, 213, 346,
+	348, 351, 353, 153, 285, 231, 341, 341, 360, 356,
+	354, 341, 398, 397, 256, 92, 94, 396, 339, 251,
+	92, 94, 367, 89, 90, 91, 159, 363, 89, 90,
+	91, 267, 334, 159, 382, 93, 273, 251, 377, 174,
+	379, 93, 132, 378, 388, 336, 132, 375, 153, 381,
+	213, 258, 380, 256, 352, 153, 258, 267, 267, 92,
+	94, 272, 267, 252, 392, 92, 94, 89, 90, 91,
+	261, 322, 167, 89, 90, 91, 214, 212, 404, 166,
+	350, 349, 159, 324, 409, 347, 406, 403, 412, 414,
+	408, 132, 384, 385, 386, 258, 341, 411, 413, 213,
+	419, 258, 343, 93, 153, 267, 341, 267, 93, 245,
+	422, 418, 342, 92, 94, 244, 366, 429, 362, 361,
+	428, 89, 90, 91, 435, 313, 277, 287, 269, 436,
+	266, 276, 275, 274, 243, 21, 186, 185, 434, 326,
+	335, 132, 184, 437, 113, 439, 18, 93, 112, 86,
+	388, 111, 132, 93, 110, 7, 189, 441, 103, 28,
+	29, 30, 47, 56, 57, 48, 50, 51, 49, 52,
+	53, 54, 55, 58, 31, 32, 98, 214, 212, 443,
+	438, 394, 171, 283, 33, 34, 35, 36, 37, 38,
+	39, 340, 288, 286, 40, 41, 42, 67, 24, 170,
+	271, 93, 172, 270, 262, 253, 284, 368, 254, 421,
+	17, 416, 389, 410, 370, 43, 44, 45, 46, 25,
+	59, 60, 61, 62, 63, 64, 65, 66, 21, 102,
+	219, 219, 433, 281, 217, 358, 359, 22, 23, 18,
+	191, 188, 109, 100, 108, 444, 442, 424, 7, 401,
+	400, 364, 28, 29, 30, 47, 56, 57, 48, 50,
+	51, 49, 52, 53, 54, 55, 58, 31, 32, 357,
+	355, 344, 227, 168, 314, 250, 249, 33, 34, 35,
+	36, 37, 38, 39, 248, 247, 224, 40, 41, 42,
+	67, 24, 222, 221, 393, 230, 219, 102, 227, 225,
+	116, 115, 215, 17, 27, 99, 88, 150, 43, 44,
+	45, 46, 25, 59, 60, 61, 62, 63, 64, 65,
+	66, 21, 151, 160, 152, 161, 26, 20, 383, 19,
+	22, 23, 18, 85, 143, 142, 141, 140, 139, 137,
+	136, 181, 135, 134, 5, 28, 29, 30, 47, 56,
+	57, 48, 50, 51, 49, 52, 53, 54, 55, 58,
+	31, 32, 16, 15, 14, 13, 11, 10, 9, 8,
+	33, 34, 35, 36, 37, 38, 39, 1, 0, 0,
+	40, 41, 42, 67, 24, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 17, 0, 0, 0,
+	0, 43, 44, 45, 46, 25, 59, 60, 61, 62,
+	63, 64, 65, 66, 264, 0, 0, 0, 0, 0,
+	0, 0, 0, 22, 23, 18, 0, 0, 0, 0,
+	0, 0, 0, 0, 7, 0, 0, 0, 28, 29,
+	30, 47, 56, 57, 48, 50, 51, 49, 52, 53,
+	54, 55, 58, 31, 32, 0, 0, 0, 0, 0,
+	0, 0, 0, 33, 34, 35, 36, 37, 38, 39,
+	0, 0, 0, 40, 41, 42, 67, 24, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 17,
+	0, 0, 0, 0, 43, 44, 45, 46, 25, 59,
+	60, 61, 62, 63, 64, 65, 66, 183, 0, 0,
+	0, 0, 0, 0, 0, 0, 22, 23, 18, 0,
+	0, 0, 0, 0, 0, 0, 0, 7, 0, 0,
+	0, 28, 29, 30, 47, 56, 57, 48, 50, 51,
+	49, 52, 53, 54, 55, 58, 31, 32, 0, 0,
+	0, 0, 0, 0, 0, 0, 33, 34, 35, 36,
+	37, 38, 39, 0, 0, 0, 40, 41, 42, 67,
+	24, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 17, 0, 0, 0, 0, 43, 44, 45,
+	46, 25, 59, 60, 61, 62, 63, 64, 65, 66,
+	178, 0, 0, 0, 0, 0, 0, 0, 0, 22,
+	23, 18, 0, 0, 0, 0, 0, 0, 0, 0,
+	181, 0, 0, 0, 28, 29, 30, 47, 56, 57,
+	48, 50, 51, 49, 52, 53, 54, 55, 58, 31,
+	32, 114, 0, 0, 0, 0, 0, 0, 0, 33,
+	34, 35, 36, 37, 38, 39, 0, 0, 0, 40,
+	41, 42, 67, 24, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 0, 0, 17, 0, 0, 0, 0,
+	43, 44, 45, 46, 25, 59, 60, 61, 62, 63,
+	64, 65, 66, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 22, 23, 159, 0, 0, 0, 0, 0,
+	0, 118, 119, 120, 121, 122, 123, 124, 125, 126,
+	127, 128, 129, 130, 131, 0, 153, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 159, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 145, 146,
+	144, 0, 154, 156, 332, 0, 0, 0, 0, 153,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	147, 0, 148, 0, 0, 0, 0, 0, 155, 157,
+	158, 145, 146, 144, 0, 154, 156, 0, 0, 0,
+	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 0, 147, 0, 148, 0, 0, 0, 0,
+	0, 155, 157, 158,
 }
-var syntaxPact = [...]int{
-
-	358, -1000, -60, -1000, -1000, -1000, 535, 358, -1000, -1000,
-	-1000, -1000, -1000, -1000, 382, 467, 381, 201, -1000, 506,
-	500, 380, 361, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
-	-1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
-	-1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
-	-1000, 63, 63, 63, 63, 63, 63, 63, 63, 63,
-	63, 63, 63, 63, 63, 63, 535, -1000, 190, 547,
-	-45, 67, -1000, -1000, -1000, -1000, -1000, -1000, 301, 294,
-	-60, 358, 410, -1000, -1000, 74, 323, 488, 359, 350,
-	346, -1000, -1000, 358, 494, 358, 59, 39, -1000, 358,
-	358, 358, 358, 358, 358, 358, 358, 358, 358, 358,
-	358, 358, 358, -1000, -45, -1000, -1000, -1000, -1000, 165,
-	-1000, -1000, -1000, -1000, -1000, 491, 574, 566, -1000, 565,
-	-1000, -1000, -1000, -1000, 341, 564, -1000, 578, 573, 573,
-	61, -1000, -1000, 33, -1000, 342, -1000, -1000, -1000, 296,
-	-1000, -1000, -1000, 577, 562, 561, 560, 557, 234, 427,
-	419, 331, 287, 415, 423, 314, 300, 412, 262, -43,
-	340, 334, 333, 332, -31, -31, -27, -27, -77, -77,
-	-77, -77, -13, -13, -13, -13, -13, -13, 165, 341,
-	341, 341, 489, 399, -1000, -1000, 439, 399, -1000, -1000,
-	299, -1000, 411, -1000, 367, 401, -1000, 74, -1000, 401,
-	92, 86, 132, 128, 104, 100, 96, -1000, -62, 328,
-	556, -11, 358, -1000, -1000, -1000, -1000, -1000, -1000, 183,
-	331, 422, 144, 193, 283, 354, 220, 183, 358, 255,
-	400, 295, -1000, -1000, 291, -1000, 536, -1000, 297, 288,
-	276, 218, 273, 165, 150, -1000, 399, 574, 534, -1000,
-	559, 481, 573, 326, -1000, -1000, -1000, 308, -1000, -1000,
-	-1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
-	-1000, -1000, -1000, -1000, -1000, -1000, 33, 533, 252, 303,
-	-1000, -1000, 175, 487, 66, 487, 468, 6, 341, 6,
-	143, 196, 466, 156, 260, -1000, -1000, 247, -1000, 358,
-	572, -1000, -1000, 390, 269, -1000, 265, -1000, -1000, 241,
-	-1000, 146, -1000, -1000, -1000, -1000, -1000, -1000, -1000, 531,
-	530, -1000, 236, -1000, 331, 183, 66, 487, 66, -1000,
-	-1000, 165, -1000, 6, -1000, 202, -1000, -1000, -1000, 87,
-	465, 464, 153, 183, 204, -1000, 529, -1000, -1000, -1000,
-	-1000, 192, 163, -1000, 160, -1000, 66, -1000, 571, 91,
-	66, 32, 6, 6, 461, -1000, -1000, 389, -1000, -1000,
-	-1000, 137, 66, -1000, -1000, 6, 511, -1000, -1000, 388,
-	492, 130, -1000,
+
+var syntaxPact = [...]int16{
+	511, -32768, -74, -32768, -32768, -32768, 387, 511, -32768, -32768,
+	-32768, -32768, -32768, -32768, -32768, -32768, -32768, 439, 514, 421,
+	196, -32768, 527, 525, 417, 414, 411, 407, -32768, -32768,
+	-32768, -32768, -32768, -32768, -32768, -32768, -32768, -32768, -32768, -32768,
+	-32768, -32768, -32768, -32768, -32768, -32768, -32768, -32768, -32768, -32768,
+	-32768, -32768, -32768, -32768, -32768, -32768, -32768, -32768, -32768, -32768,
+	-32768, -32768, -32768, -32768, -32768, -32768, -32768, -32768, 114, 114,
+	114, 114, 114, 114, 114, 114, 114, 114, 114, 114,
+	114, 114, 114, 387, -32768, 227, 1022, -59, 131, -32768,
+	-32768, -32768, -32768, -32768, -32768, 341, 334, -74, 511, 470,
+	-32768, -32768, 225, 883, 790, 405, 400, 399, -32768, -32768,
+	511, 524, 418, 523, 511, 32, 21, -32768, 511, 511,
+	511, 511, 511, 511, 511, 511, 511, 511, 511, 511,
+	511, 511, -32768, -59, -32768, -32768, -32768, -32768, 367, -32768,
+	-32768, -32768, -32768, -32768, 516, 581, 577, -32768, 576, -32768,
+	-32768, -32768, -32768, 311, 570, -32768, 583, 580, 580, 105,
+	-32768, -32768, 130, -32768, 397, -32768, -32768, -32768, 377, -32768,
+	-32768, -32768, 582, 569, 568, 560, 559, 325, 473, 487,
+	333, 604, 332, 472, 697, 392, 390, 471, 468, -32768,
+	323, 298, -57, 396, 395, 394, 389, -45, -45, -35,
+	-35, -92, -92, -92, -92, -39, -39, -39, -39, -39,
+	-39, 367, 311, 311, 311, 515, 451, -32768, -32768, 482,
+	451, -32768, -32768, 266, -32768, 461, -32768, 403, 460, -32768,
+	225, -32768, 460, 84, 78, 126, 118, 106, 97, 93,
+	-32768, -76, 388, 558, 9, 511, -32768, -32768, -32768, -32768,
+	-32768, -32768, 139, 604, 101, 165, 289, 147, 989, 294,
+	307, 139, 511, 280, 459, 374, -32768, -32768, 364, -32768,
+	555, 511, -32768, -32768, 347, 343, 342, 316, 318, 367,
+	149, -32768, 451, 581, 554, -32768, 557, 520, 580, 382,
+	-32768, -32768, -32768, 381, -32768, -32768, -32768, -32768, -32768, -32768,
+	-32768, -32768, -32768, -32768, -32768, -32768, -32768, -32768, -32768, -32768,
+	-32768, -32768, 130, 535, 259, 379, -32768, -32768, 284, 486,
+	-32768, 256, 495, 31, 74, 11, 122, 339, 71, 339,
+	11, 311, 319, 221, 492, 220, -32768, -32768, 247, -32768,
+	511, 579, -32768, -32768, 449, 246, 279, -32768, 275, -32768,
+	-32768, 274, -32768, 150, -32768, -32768, -32768, -32768, -32768, -32768,
+	-32768, 534, 533, -32768, 206, -32768, 236, 139, 83, -32768,
+	5, 494, -32768, 360, 351, -32768, 11, 71, 339, 71,
+	-32768, 367, -32768, 197, -32768, -32768, -32768, 491, 190, 44,
+	489, 139, 204, -32768, 531, -32768, -32768, -32768, -32768, -32768,
+	201, 191, -32768, 168, 333, 236, -32768, -32768, 186, -32768,
+	-32768, 164, 156, -32768, 71, 517, 11, 404, 104, 71,
+	54, 11, -32768, -32768, 448, -32768, -32768, -32768, 165, 294,
+	-32768, -32768, -32768, 152, -32768, 11, 71, -32768, 530, 221,
+	-32768, -32768, 447, 529, 125, -32768,
 }
-var syntaxPgo = [...]int{
-
-	0, 623, 18, 500, 3, 621, 620, 618, 616, 615,
-	614, 5, 613, 612, 611, 610, 609, 608, 607, 606,
-	605, 14, 112, 604, 4, 603, 602, 601, 102, 598,
-	594, 593, 8, 592, 591, 590, 6, 589, 7, 588,
-	15, 587, 575, 585, 584, 9, 16, 12, 565, 2,
-	10, 40, 13, 17, 1, 0, 541,
+
+var syntaxPgo = [...]int16{
+	0, 667, 19, 15, 13, 659, 658, 657, 656, 655,
+	654, 653, 652, 634, 2, 633, 632, 630, 629, 628,
+	627, 626, 625, 624, 5, 80, 623, 4, 619, 618,
+	617, 42, 616, 615, 614, 613, 7, 612, 597, 596,
+	6, 595, 1, 594, 14, 592, 931, 591, 590, 9,
+	18, 12, 589, 8, 10, 43, 16, 17, 0, 11,
+	3, 563,
 }
-var syntaxR1 = [...]int{
 
+var syntaxR1 = [...]int8{
 	0, 1, 2, 2, 2, 3, 3, 3, 4, 4,
-	4, 4, 4, 4, 4, 10, 50, 50, 50, 50,
-	50, 50, 50, 50, 50, 50, 50, 50, 50, 50,
-	50, 50, 50, 50, 50, 50, 50, 50, 50, 50,
-	50, 50, 54, 54, 54, 26, 26, 26, 5, 5,
-	5, 5, 6, 6, 6, 6, 6, 6, 8, 38,
-	38, 38, 37, 37, 36, 36, 36, 36, 21, 21,
-	11, 11, 11, 11, 11, 11, 11, 11, 11, 11,
-	11, 35, 35, 35, 35, 35, 35, 28, 24, 24,
-	24, 22, 22, 22, 23, 23, 41, 41, 12, 12,
-	13, 13, 13, 13, 14, 15, 15, 16, 17, 47,
-	47, 48, 48, 48, 18, 32, 32, 32, 32, 32,
-	32, 32, 32, 32, 52, 52, 53, 53, 34, 34,
-	33, 33, 31, 31, 31, 31, 31, 31, 31, 29,
-	29, 29, 29, 29, 29, 29, 30, 30, 30, 30,
-	30, 30, 30, 45, 45, 46, 46, 19, 20, 7,
-	7, 7, 7, 7, 7, 7, 7, 7, 7, 7,
-	7, 7, 7, 7, 43, 43, 44, 44, 44, 44,
-	42, 42, 42, 42, 42, 42, 42, 42, 51, 51,
-	51, 9, 39, 27, 27, 27, 27, 27, 27, 27,
-	27, 27, 27, 27, 27, 25, 25, 25, 25, 25,
-	25, 25, 25, 25, 25, 25, 25, 25, 25, 25,
-	55, 40, 40, 49, 49, 49, 49, 56, 56,
+	4, 4, 4, 4, 4, 4, 4, 4, 13, 54,
+	54, 54, 54, 54, 54, 54, 54, 54, 54, 54,
+	54, 54, 54, 54, 54, 54, 54, 54, 54, 54,
+	54, 54, 54, 54, 54, 58, 58, 58, 29, 29,
+	29, 5, 5, 5, 5, 6, 6, 6, 6, 7,
+	7, 7, 7, 7, 7, 9, 10, 11, 11, 32,
+	32, 32, 32, 32, 32, 32, 32, 42, 42, 42,
+	41, 41, 40, 40, 40, 40, 24, 24, 14, 14,
+	14, 14, 14, 14, 14, 14, 14, 14, 14, 39,
+	39, 39, 39, 39, 39, 31, 27, 27, 27, 25,
+	25, 25, 26, 26, 45, 45, 15, 15, 16, 16,
+	16, 16, 17, 18, 18, 19, 20, 51, 51, 52,
+	52, 52, 21, 36, 36, 36, 36, 36, 36, 36,
+	36, 36, 56, 56, 57, 57, 38, 38, 37, 37,
+	35, 35, 35, 35, 35, 35, 35, 33, 33, 33,
+	33, 33, 33, 33, 34, 34, 34, 34, 34, 34,
+	34, 49, 49, 50, 50, 22, 23, 8, 8, 8,
+	8, 8, 8, 8, 8, 8, 8, 8, 8, 8,
+	8, 8, 47, 47, 48, 48, 48, 48, 46, 46,
+	46, 46, 46, 46, 46, 46, 55, 55, 55, 12,
+	43, 30, 30, 30, 30, 30, 30, 30, 30, 30,
+	30, 30, 30, 28, 28, 28, 28, 28, 28, 28,
+	28, 28, 28, 28, 28, 28, 28, 28, 28, 28,
+	28, 28, 59, 59, 59, 59, 60, 60, 60, 44,
+	44, 53, 53, 53, 53, 61, 61,
 }
-var syntaxR2 = [...]int{
 
+var syntaxR2 = [...]int8{
 	0, 1, 1, 1, 1, 1, 2, 3, 1, 1,
-	1, 1, 1, 1, 3, 8, 2, 3, 4, 5,
-	3, 4, 5, 6, 3, 4, 5, 6, 3, 4,
-	5, 6, 4, 5, 6, 7, 3, 4, 4, 5,
-	3, 2, 3, 6, 3, 1, 1, 1, 4, 6,
-	5, 7, 4, 5, 5, 6, 7, 7, 12, 3,
-	3, 2, 1, 3, 3, 3, 3, 3, 1, 2,
-	1, 2, 2, 2, 2, 2, 2, 2, 2, 2,
-	2, 1, 1, 1, 1, 1, 1, 1, 1, 3,
-	4, 2, 5, 3, 1, 2, 1, 2, 1, 2,
-	1, 2, 1, 2, 2, 3, 2, 2, 1, 3,
-	3, 1, 3, 3, 2, 1, 1, 1, 1, 3,
-	2, 3, 3, 3, 3, 1, 1, 3, 6, 6,
-	1, 1, 3, 3, 3, 3, 3, 3, 3, 3,
+	1, 1, 1, 1, 1, 1, 1, 3, 8, 2,
+	3, 4, 5, 3, 4, 5, 6, 3, 4, 5,
+	6, 3, 4, 5, 6, 4, 5, 6, 7, 3,
+	4, 4, 5, 3, 2, 3, 6, 3, 1, 1,
+	1, 4, 6, 5, 7, 5, 6, 7, 8, 4,
+	5, 5, 6, 7, 7, 12, 6, 3, 4, 1,
+	1, 1, 1, 1, 1, 1, 1, 3, 3, 2,
+	1, 3, 3, 3, 3, 3, 1, 2, 1, 2,
+	2, 2, 2, 2, 2, 2, 2, 2, 2, 1,
+	1, 1, 1, 1, 1, 1, 1, 3, 4, 2,
+	5, 3, 1, 2, 1, 2, 1, 2, 1, 2,
+	1, 2, 2, 3, 2, 2, 1, 3, 3, 1,
+	3, 3, 2, 1, 1, 1, 1, 3, 2, 3,
+	3, 3, 3, 1, 1, 3, 6, 6, 1, 1,
+	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
-	3, 3, 3, 1, 1, 1, 3, 2, 2, 4,
+	3, 1, 1, 1, 3, 2, 2, 4, 4, 4,
 	4, 4, 4, 4, 4, 4, 4, 4, 4, 4,
-	4, 4, 4, 4, 0, 1, 5, 4, 5, 4,
-	1, 1, 2, 4, 5, 2, 4, 5, 1, 2,
-	2, 4, 1, 1, 1, 1, 1, 1, 1, 1,
+	4, 4, 0, 1, 5, 4, 5, 4, 1, 1,
+	2, 4, 5, 2, 4, 5, 1, 2, 2, 4,
 	1, 1, 1, 1, 1, 1, 1, 1, 1, 1,
 	1, 1, 1, 1, 1, 1, 1, 1, 1, 1,
-	2, 1, 3, 4, 4, 3, 3, 1, 3,
+	1, 1, 1, 1, 1, 1, 1, 1, 1, 1,
+	1, 1, 2, 1, 3, 3, 2, 4, 4, 1,
+	3, 4, 4, 3, 3, 1, 3,
 }
-var syntaxChk = [...]int{
-
-	-1000, -1, -2, -3, -4, -10, -38, 26, -5, -6,
-	-7, -51, -8, -9, 81, 17, -25, -27, 7, 92,
-	93, 69, -39, 30, 31, 32, 45, 46, 55, 56,
-	57, 58, 59, 60, 61, 65, 66, 67, 33, 36,
-	39, 37, 38, 40, 41, 42, 43, 34, 35, 44,
-	68, 83, 84, 85, 92, 93, 94, 95, 96, 97,
-	86, 87, 90, 91, 88, 89, -21, -11, -23, 51,
-	-22, -35, 23, 24, 25, 15, 87, 16, -3, -4,
-	-2, 26, -37, 18, -36, 5, 26, 26, -49, 28,
-	29, 7, 7, 26, 26, -42, -43, -44, 47, -42,
-	-42, -42, -42, -42, -42, -42, -42, -42, -42, -42,
-	-42, -42, -42, -11, -22, -12, -13, -14, -15, -32,
-	-16, -17, -18, -19, -20, 50, 48, 49, 70, 72,
-	-36, -34, -33, -30, 26, 52, 78, 53, 79, 80,
-	5, -31, -29, 83, 6, -28, 73, 27, 27, -56,
-	-4, 18, 2, 21, 13, 87, 14, 15, -50, 7,
-	-38, 26, -4, 7, 26, 26, 26, -4, 7, -2,
-	74, 75, 76, 77, -2, -2, -2, -2, -2, -2,
-	-2, -2, -2, -2, -2, -2, -2, -2, -32, 84,
-	21, 83, -41, -53, 8, -52, 5, -53, 6, 6,
-	-32, 6, -48, -47, 5, -46, -45, 5, -36, -46,
-	13, 87, 90, 91, 88, 89, 86, -24, 6, -28,
-	26, 27, 21, -36, 6, 6, 6, 6, 2, 27,
-	21, 10, -54, -21, 51, -38, -50, 27, 21, -4,
-	7, -40, 27, 5, -40, 27, 21, 27, 26, 26,
-	26, 26, -32, -32, -32, 8, -53, 21, 13, 27,
-	21, 13, 21, 73, 9, 4, -51, 73, 9, 4,
-	-51, 9, 4, -51, 9, 4, -51, 9, 4, -51,
-	9, 4, -51, 9, 4, -51, 83, 26, 6, 82,
-	-4, -49, -50, -55, -54, -21, 71, 10, 51, 10,
-	-54, 54, 27, -54, -21, 27, -49, -4, 27, 21,
-	21, 27, 27, 6, -40, 27, -40, 27, 27, -40,
-	27, -40, -52, 6, -47, 2, 5, 6, -45, 26,
-	26, -24, 6, 27, 26, 27, -54, -21, -54, 9,
-	-55, -32, -55, 10, 5, -26, 62, 63, 64, 10,
-	27, 27, -54, 27, -4, 5, 21, 27, 27, 27,
-	27, 6, 6, 27, -50, -49, -54, -55, 26, -55,
-	-54, 51, 10, 10, 27, -49, 27, 6, 27, 27,
-	27, 5, -54, -55, -55, 10, 21, 27, -55, 6,
-	21, 6, 27,
+
+var syntaxChk = [...]int16{
+	-32768, -1, -2, -3, -4, -13, -42, 27, -5, -6,
+	-7, -8, -55, -9, -10, -11, -12, 82, 18, -28,
+	-30, 7, 109, 110, 70, 91, -32, -43, 31, 32,
+	33, 46, 47, 56, 57, 58, 59, 60, 61, 62,
+	66, 67, 68, 87, 88, 89, 90, 34, 37, 40,
+	38, 39, 41, 42, 43, 44, 35, 36, 45, 92,
+	93, 94, 95, 96, 97, 98, 99, 69, 100, 101,
+	102, 109, 110, 111, 112, 113, 114, 103, 104, 107,
+	108, 105, 106, -24, -14, -26, 52, -25, -39, 24,
+	25, 26, 16, 104, 17, -3, -4, -2, 27, -41,
+	19, -40, 5, 27, 27, -53, 29, 30, 7, 7,
+	27, 27, 27, 27, -46, -47, -48, 48, -46, -46,
+	-46, -46, -46, -46, -46, -46, -46, -46, -46, -46,
+	-46, -46, -14, -25, -15, -16, -17, -18, -36, -19,
+	-20, -21, -22, -23, 51, 49, 50, 71, 73, -40,
+	-38, -37, -34, 27, 53, 79, 54, 80, 81, 5,
+	-35, -33, 100, 6, -31, 74, 28, 28, -61, -4,
+	19, 2, 22, 14, 104, 15, 16, -54, 7, -4,
+	-42, 27, -4, 7, 27, 27, 27, -4, 7, 28,
+	-4, 7, -2, 75, 76, 77, 78, -2, -2, -2,
+	-2, -2, -2, -2, -2, -2, -2, -2, -2, -2,
+	-2, -36, 101, 22, 100, -45, -57, 8, -56, 5,
+	-57, 6, 6, -36, 6, -52, -51, 5, -50, -49,
+	5, -40, -50, 14, 104, 107, 108, 105, 106, 103,
+	-27, 6, -31, 27, 28, 22, -40, 6, 6, 6,
+	6, 2, 28, 22, 11, -24, 10, -58, 52, -42,
+	-54, 28, 22, -4, 7, -44, 28, 5, -44, 28,
+	22, 22, 28, 28, 27, 27, 27, 27, -36, -36,
+	-36, 8, -57, 22, 14, 28, 22, 14, 22, 74,
+	9, 4, -55, 74, 9, 4, -55, 9, 4, -55,
+	9, 4, -55, 9, 4, -55, 9, 4, -55, 9,
+	4, -55, 100, 27, 6, 83, -4, -53, -54, -4,
+	28, -59, 72, -60, 84, 10, -58, -59, -58, -24,
+	10, 52, 55, -24, 28, -58, 28, -53, -4, 28,
+	22, 22, 28, 28, 6, -4, -44, 28, -44, 28,
+	28, -44, 28, -44, -56, 6, -51, 2, 5, 6,
+	-49, 27, 27, -27, 6, 28, 27, 28, 11, 28,
+	9, 72, 7, 85, 86, -59, 10, -58, -24, -58,
+	-59, -36, 5, -29, 63, 64, 65, 28, -58, 10,
+	28, 28, -4, 5, 22, 28, 28, 28, 28, 28,
+	6, 6, 28, -54, -42, 27, -53, 28, -59, -60,
+	9, 27, 27, -59, -58, 27, 10, 28, -59, -58,
+	52, 10, -53, 28, 6, 28, 28, 28, -24, -42,
+	28, 28, 28, 5, -59, 10, -58, -59, 22, -24,
+	28, -59, 6, 22, 6, 28,
 }
-var syntaxDef = [...]int{
 
+var syntaxDef = [...]int16{
 	0, -2, 1, 2, 3, 4, 5, 0, 8, 9,
-	10, 11, 12, 13, 0, 0, 0, 0, 188, 0,
-	0, 0, 0, 205, 206, 207, 208, 209, 210, 211,
-	212, 213, 214, 215, 216, 217, 218, 219, 193, 194,
-	195, 196, 197, 198, 199, 200, 201, 202, 203, 204,
-	192, 174, 174, 174, 174, 174, 174, 174, 174, 174,
-	174, 174, 174, 174, 174, 174, 6, 68, 70, 0,
-	94, 0, 81, 82, 83, 84, 85, 86, 2, 3,
-	0, 0, 0, 61, 62, 0, 0, 0, 0, 0,
-	0, 189, 190, 0, 0, 0, 180, 181, 175, 0,
+	10, 11, 12, 13, 14, 15, 16, 0, 0, 0,
+	0, 206, 0, 0, 0, 0, 0, 0, 223, 224,
+	225, 226, 227, 228, 229, 230, 231, 232, 233, 234,
+	235, 236, 237, 238, 239, 240, 241, 211, 212, 213,
+	214, 215, 216, 217, 218, 219, 220, 221, 222, 69,
+	70, 71, 72, 73, 74, 75, 76, 210, 192, 192,
+	192, 192, 192, 192, 192, 192, 192, 192, 192, 192,
+	192, 192, 192, 6, 86, 88, 0, 112, 0, 99,
+	100, 101, 102, 103, 104, 2, 3, 0, 0, 0,
+	79, 80, 0, 0, 0, 0, 0, 0, 207, 208,
+	0, 0, 0, 0, 0, 198, 199, 193, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0, 0, 0,
-	0, 0, 0, 69, 95, 71, 72, 73, 74, 75,
-	76, 77, 78, 79, 80, 98, 100, 0, 102, 0,
-	115, 116, 117, 118, 0, 0, 108, 0, 0, 0,
-	0, 130, 131, 0, 91, 0, 87, 7, 14, 0,
-	-2, 59, 60, 0, 0, 0, 0, 0, 0, 0,
-	0, 0, 3, 188, 0, 0, 0, 3, 0, 159,
-	0, 0, 182, 185, 160, 161, 162, 163, 164, 165,
-	166, 167, 168, 169, 170, 171, 172, 173, 120, 0,
-	0, 0, 99, 106, 96, 126, 125, 104, 101, 103,
-	0, 107, 114, 111, 0, 157, 155, 153, 154, 158,
-	0, 0, 0, 0, 0, 0, 0, 93, 88, 0,
-	0, 0, 0, 63, 64, 65, 66, 67, 41, 48,
-	0, 16, 0, 0, 0, 0, 0, 52, 0, 3,
-	188, 0, 225, 221, 0, 226, 0, 191, 0, 0,
-	0, 0, 121, 122, 123, 97, 105, 0, 0, 119,
-	0, 0, 0, 0, 137, 144, 151, 0, 136, 143,
-	150, 132, 139, 146, 133, 140, 147, 134, 141, 148,
-	135, 142, 149, 138, 145, 152, 0, 0, 0, 0,
-	-2, 50, 0, 17, 20, 36, 0, 24, 0, 28,
-	0, 0, 0, 0, 0, 40, 54, 3, 53, 0,
-	0, 223, 224, 0, 0, 177, 0, 179, 183, 0,
-	186, 0, 127, 124, 112, 113, 109, 110, 156, 0,
-	0, 89, 0, 92, 0, 49, 21, 37, 38, 220,
-	25, 44, 29, 32, 42, 0, 45, 46, 47, 18,
-	0, 0, 0, 55, 3, 222, 0, 176, 178, 184,
-	187, 0, 0, 90, 0, 51, 39, 33, 0, 19,
-	22, 0, 26, 30, 0, 56, 57, 0, 128, 129,
-	15, 0, 23, 27, 31, 34, 0, 43, 35, 0,
-	0, 0, 58,
+	0, 0, 87, 113, 89, 90, 91, 92, 93, 94,
+	95, 96, 97, 98, 116, 118, 0, 120, 0, 133,
+	134, 135, 136, 0, 0, 126, 0, 0, 0, 0,
+	148, 149, 0, 109, 0, 105, 7, 17, 0, -2,
+	77, 78, 0, 0, 0, 0, 0, 0, 206, 3,
+	5, 0, 3, 206, 0, 0, 0, 3, 0, 67,
+	3, 0, 177, 0, 0, 200, 203, 178, 179, 180,
+	181, 182, 183, 184, 185, 186, 187, 188, 189, 190,
+	191, 138, 0, 0, 0, 117, 124, 114, 144, 143,
+	122, 119, 121, 0, 125, 132, 129, 0, 175, 173,
+	171, 172, 176, 0, 0, 0, 0, 0, 0, 0,
+	111, 106, 0, 0, 0, 0, 81, 82, 83, 84,
+	85, 44, 51, 0, 0, 6, 19, 0, 0, 5,
+	0, 59, 0, 3, 206, 0, 253, 249, 0, 254,
+	0, 0, 68, 209, 0, 0, 0, 0, 139, 140,
+	141, 115, 123, 0, 0, 137, 0, 0, 0, 0,
+	155, 162, 169, 0, 154, 161, 168, 150, 157, 164,
+	151, 158, 165, 152, 159, 166, 153, 160, 167, 156,
+	163, 170, 0, 0, 0, 0, -2, 53, 0, 3,
+	55, 0, 0, 243, 0, 31, 0, 20, 23, 39,
+	27, 0, 0, 6, 0, 0, 43, 61, 3, 60,
+	0, 0, 251, 252, 0, 3, 0, 195, 0, 197,
+	201, 0, 204, 0, 145, 142, 130, 131, 127, 128,
+	174, 0, 0, 107, 0, 110, 0, 52, 0, 56,
+	242, 0, 246, 0, 0, 32, 35, 24, 40, 41,
+	28, 47, 45, 0, 48, 49, 50, 0, 0, 21,
+	0, 62, 3, 250, 0, 66, 194, 196, 202, 205,
+	0, 0, 108, 0, 0, 0, 54, 57, 0, 244,
+	245, 0, 0, 36, 42, 0, 33, 0, 22, 25,
+	0, 29, 63, 64, 0, 146, 147, 18, 0, 0,
+	58, 247, 248, 0, 34, 37, 26, 30, 0, 0,
+	46, 38, 0, 0, 0, 65,
 }
-var syntaxTok1 = [...]int{
 
+var syntaxTok1 = [...]int8{
 	1,
 }
-var syntaxTok2 = [...]int{
 
+var syntaxTok2 = [...]int8{
 	2, 3, 4, 5, 6, 7, 8, 9, 10, 11,
 	12, 13, 14, 15, 16, 17, 18, 19, 20, 21,
 	22, 23, 24, 25, 26, 27, 28, 29, 30, 31,
@@ -538,9 +642,12 @@ var syntaxTok2 = [...]int{
 	62, 63, 64, 65, 66, 67, 68, 69, 70, 71,
 	72, 73, 74, 75, 76, 77, 78, 79, 80, 81,
 	82, 83, 84, 85, 86, 87, 88, 89, 90, 91,
-	92, 93, 94, 95, 96, 97,
+	92, 93, 94, 95, 96, 97, 98, 99, 100, 101,
+	102, 103, 104, 105, 106, 107, 108, 109, 110, 111,
+	112, 113, 114,
 }
-var syntaxTok3 = [...]int{
+
+var syntaxTok3 = [...]int8{
 	0,
 }
 
@@ -581,7 +688,7 @@ func syntaxNewParser() syntaxParser {
 	return &syntaxParserImpl{}
 }
 
-const syntaxFlag = -1000
+const syntaxFlag = -32768
 
 func syntaxTokname(c int) string {
 	if c >= 1 && c-1 < len(syntaxToknames) {
@@ -620,9 +727,9 @@ func syntaxErrorMessage(state, lookAhead int) string {
 	expected := make([]int, 0, 4)
 
 	// Look for shiftable tokens.
-	base := syntaxPact[state]
+	base := int(syntaxPact[state])
 	for tok := TOKSTART; tok-1 < len(syntaxToknames); tok++ {
-		if n := base + tok; n >= 0 && n < syntaxLast && syntaxChk[syntaxAct[n]] == tok {
+		if n := base + tok; n >= 0 && n < syntaxLast && int(syntaxChk[int(syntaxAct[n])]) == tok {
 			if len(expected) == cap(expected) {
 				return res
 			}
@@ -632,13 +739,13 @@ func syntaxErrorMessage(state, lookAhead int) string {
 
 	if syntaxDef[state] == -2 {
 		i := 0
-		for syntaxExca[i] != -1 || syntaxExca[i+1] != state {
+		for syntaxExca[i] != -1 || int(syntaxExca[i+1]) != state {
 			i += 2
 		}
 
 		// Look for tokens that we accept or reduce.
 		for i += 2; syntaxExca[i] >= 0; i += 2 {
-			tok := syntaxExca[i]
+			tok := int(syntaxExca[i])
 			if tok < TOKSTART || syntaxExca[i+1] == 0 {
 				continue
 			}
@@ -669,30 +776,30 @@ func syntaxlex1(lex syntaxLexer, lval *syntaxSymType) (char, token int) {
 	token = 0
 	char = lex.Lex(lval)
 	if char <= 0 {
-		token = syntaxTok1[0]
+		token = int(syntaxTok1[0])
 		goto out
 	}
 	if char < len(syntaxTok1) {
-		token = syntaxTok1[char]
+		token = int(syntaxTok1[char])
 		goto out
 	}
 	if char >= syntaxPrivate {
 		if char < syntaxPrivate+len(syntaxTok2) {
-			token = syntaxTok2[char-syntaxPrivate]
+			token = int(syntaxTok2[char-syntaxPrivate])
 			goto out
 		}
 	}
 	for i := 0; i < len(syntaxTok3); i += 2 {
-		token = syntaxTok3[i+0]
+		token = int(syntaxTok3[i+0])
 		if token == char {
-			token = syntaxTok3[i+1]
+			token = int(syntaxTok3[i+1])
 			goto out
 		}
 	}
 
 out:
 	if token == 0 {
-		token = syntaxTok2[1] /* unknown char */
+		token = int(syntaxTok2[1]) /* unknown char */
 	}
 	if syntaxDebug >= 3 {
 		__yyfmt__.Printf("lex %s(%d)\n", syntaxTokname(token), uint(char))
@@ -747,7 +854,7 @@ syntaxstack:
 	syntaxS[syntaxp].yys = syntaxstate
 
 syntaxnewstate:
-	syntaxn = syntaxPact[syntaxstate]
+	syntaxn = int(syntaxPact[syntaxstate])
 	if syntaxn <= syntaxFlag {
 		goto syntaxdefault /* simple state */
 	}
@@ -758,8 +865,8 @@ syntaxnewstate:
 	if syntaxn < 0 || syntaxn >= syntaxLast {
 		goto syntaxdefault
 	}
-	syntaxn = syntaxAct[syntaxn]
-	if syntaxChk[syntaxn] == syntaxtoken { /* valid shift */
+	syntaxn = int(syntaxAct[syntaxn])
+	if int(syntaxChk[syntaxn]) == syntaxtoken { /* valid shift */
 		syntaxrcvr.char = -1
 		syntaxtoken = -1
 		syntaxVAL = syntaxrcvr.lval
@@ -772,7 +879,7 @@ syntaxnewstate:
 
 syntaxdefault:
 	/* default state action */
-	syntaxn = syntaxDef[syntaxstate]
+	syntaxn = int(syntaxDef[syntaxstate])
 	if syntaxn == -2 {
 		if syntaxrcvr.char < 0 {
 			syntaxrcvr.char, syntaxtoken = syntaxlex1(syntaxlex, &syntaxrcvr.lval)
@@ -781,18 +888,18 @@ syntaxdefault:
 		/* look through exception table */
 		xi := 0
 		for {
-			if syntaxExca[xi+0] == -1 && syntaxExca[xi+1] == syntaxstate {
+			if syntaxExca[xi+0] == -1 && int(syntaxExca[xi+1]) == syntaxstate {
 				break
 			}
 			xi += 2
 		}
 		for xi += 2; ; xi += 2 {
-			syntaxn = syntaxExca[xi+0]
+			syntaxn = int(syntaxExca[xi+0])
 			if syntaxn < 0 || syntaxn == syntaxtoken {
 				break
 			}
 		}
-		syntaxn = syntaxExca[xi+1]
+		syntaxn = int(syntaxExca[xi+1])
 		if syntaxn < 0 {
 			goto ret0
 		}
@@ -814,10 +921,10 @@ syntaxdefault:
 
 			/* find a state where "error" is a legal shift action */
 			for syntaxp >= 0 {
-				syntaxn = syntaxPact[syntaxS[syntaxp].yys] + syntaxErrCode
+				syntaxn = int(syntaxPact[syntaxS[syntaxp].yys]) + syntaxErrCode
 				if syntaxn >= 0 && syntaxn < syntaxLast {
-					syntaxstate = syntaxAct[syntaxn] /* simulate a shift of "error" */
-					if syntaxChk[syntaxstate] == syntaxErrCode {
+					syntaxstate = int(syntaxAct[syntaxn]) /* simulate a shift of "error" */
+					if int(syntaxChk[syntaxstate]) == syntaxErrCode {
 						goto syntaxstack
 					}
 				}
@@ -853,7 +960,7 @@ syntaxdefault:
 	syntaxpt := syntaxp
 	_ = syntaxpt // guard against "declared and not used"
 
-	syntaxp -= syntaxR2[syntaxn]
+	syntaxp -= int(syntaxR2[syntaxn])
 	// syntaxp is now the index of $0. Perform the default action. Iff the
 	// reduced production is ε, $1 is possibly out of range.
 	if syntaxp+1 >= len(syntaxS) {
@@ -864,16 +971,16 @@ syntaxdefault:
 	syntaxVAL = syntaxS[syntaxp+1]
 
 	/* consult goto table to find next state */
-	syntaxn = syntaxR1[syntaxn]
-	syntaxg := syntaxPgo[syntaxn]
+	syntaxn = int(syntaxR1[syntaxn])
+	syntaxg := int(syntaxPgo[syntaxn])
 	syntaxj := syntaxg + syntaxS[syntaxp].yys + 1
 
 	if syntaxj >= syntaxLast {
-		syntaxstate = syntaxAct[syntaxg]
+		syntaxstate = int(syntaxAct[syntaxg])
 	} else {
-		syntaxstate = syntaxAct[syntaxj]
-		if syntaxChk[syntaxstate] != -syntaxn {
-			syntaxstate = syntaxAct[syntaxg]
+		syntaxstate = int(syntaxAct[syntaxj])
+		if int(syntaxChk[syntaxstate]) != -syntaxn {
+			syntaxstate = int(syntaxAct[syntaxg])
 		}
 	}
 	// dummy call; replaced with literal code
@@ -932,12 +1039,12 @@ syntaxdefault:
 	case 11:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
-			syntaxVAL.metricExpr = syntaxDollar[1].literalExpr
+			syntaxVAL.metricExpr = syntaxDollar[1].metricExpr
 		}
 	case 12:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
-			syntaxVAL.metricExpr = syntaxDollar[1].metricExpr
+			syntaxVAL.metricExpr = syntaxDollar[1].literalExpr
 		}
 	case 13:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
@@ -945,1077 +1052,1217 @@ syntaxdefault:
 			syntaxVAL.metricExpr = syntaxDollar[1].metricExpr
 		}
 	case 14:
+		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
+		{
+			syntaxVAL.metricExpr = syntaxDollar[1].metricExpr
+		}
+	case 15:
+		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
+		{
+			syntaxVAL.metricExpr = syntaxDollar[1].metricExpr
+		}
+	case 16:
+		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
+		{
+			syntaxVAL.metricExpr = syntaxDollar[1].metricExpr
+		}
+	case 17:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.metricExpr = syntaxDollar[2].metricExpr
 		}
-	case 15:
+	case 18:
 		syntaxDollar = syntaxS[syntaxpt-8 : syntaxpt+1]
 		{
 			syntaxVAL.variantsExpr = newVariantsExpr(syntaxDollar[3].metricExprs, syntaxDollar[7].logRangeExpr)
 		}
-	case 16:
+	case 19:
 		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
 		{
 			syntaxVAL.logRangeExpr = newLogRange(newMatcherExpr(syntaxDollar[1].matchers), syntaxDollar[2].dur, nil, nil)
 		}
-	case 17:
+	case 20:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.logRangeExpr = newLogRange(newMatcherExpr(syntaxDollar[1].matchers), syntaxDollar[2].dur, nil, syntaxDollar[3].offsetExpr)
 		}
-	case 18:
+	case 21:
 		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
 		{
 			syntaxVAL.logRangeExpr = newLogRange(newMatcherExpr(syntaxDollar[2].matchers), syntaxDollar[4].dur, nil, nil)
 		}
-	case 19:
+	case 22:
 		syntaxDollar = syntaxS[syntaxpt-5 : syntaxpt+1]
 		{
 			syntaxVAL.logRangeExpr = newLogRange(newMatcherExpr(syntaxDollar[2].matchers), syntaxDollar[4].dur, nil, syntaxDollar[5].offsetExpr)
 		}
-	case 20:
+	case 23:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.logRangeExpr = newLogRange(newMatcherExpr(syntaxDollar[1].matchers), syntaxDollar[2].dur, syntaxDollar[3].unwrapExpr, nil)
 		}
-	case 21:
+	case 24:
 		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
 		{
 			syntaxVAL.logRangeExpr = newLogRange(newMatcherExpr(syntaxDollar[1].matchers), syntaxDollar[2].dur, syntaxDollar[4].unwrapExpr, syntaxDollar[3].offsetExpr)
 		}
-	case 22:
+	case 25:
 		syntaxDollar = syntaxS[syntaxpt-5 : syntaxpt+1]
 		{
 			syntaxVAL.logRangeExpr = newLogRange(newMatcherExpr(syntaxDollar[2].matchers), syntaxDollar[4].dur, syntaxDollar[5].unwrapExpr, nil)
 		}
-	case 23:
+	case 26:
 		syntaxDollar = syntaxS[syntaxpt-6 : syntaxpt+1]
 		{
 			syntaxVAL.logRangeExpr = newLogRange(newMatcherExpr(syntaxDollar[2].matchers), syntaxDollar[4].dur, syntaxDollar[6].unwrapExpr, syntaxDollar[5].offsetExpr)
 		}
-	case 24:
+	case 27:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.logRangeExpr = newLogRange(newMatcherExpr(syntaxDollar[1].matchers), syntaxDollar[3].dur, syntaxDollar[2].unwrapExpr, nil)
 		}
-	case 25:
+	case 28:
 		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
 		{
 			syntaxVAL.logRangeExpr = newLogRange(newMatcherExpr(syntaxDollar[1].matchers), syntaxDollar[3].dur, syntaxDollar[2].unwrapExpr, syntaxDollar[4].offsetExpr)
 		}
-	case 26:
+	case 29:
 		syntaxDollar = syntaxS[syntaxpt-5 : syntaxpt+1]
 		{
 			syntaxVAL.logRangeExpr = newLogRange(newMatcherExpr(syntaxDollar[2].matchers), syntaxDollar[5].dur, syntaxDollar[3].unwrapExpr, nil)
 		}
-	case 27:
+	case 30:
 		syntaxDollar = syntaxS[syntaxpt-6 : syntaxpt+1]
 		{
 			syntaxVAL.logRangeExpr = newLogRange(newMatcherExpr(syntaxDollar[2].matchers), syntaxDollar[5].dur, syntaxDollar[3].unwrapExpr, syntaxDollar[6].offsetExpr)
 		}
-	case 28:
+	case 31:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.logRangeExpr = newLogRange(newPipelineExpr(newMatcherExpr(syntaxDollar[1].matchers), syntaxDollar[2].stages), syntaxDollar[3].dur, nil, nil)
 		}
-	case 29:
+	case 32:
 		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
 		{
 			syntaxVAL.logRangeExpr = newLogRange(newPipelineExpr(newMatcherExpr(syntaxDollar[1].matchers), syntaxDollar[2].stages), syntaxDollar[3].dur, nil, syntaxDollar[4].offsetExpr)
 		}
-	case 30:
+	case 33:
 		syntaxDollar = syntaxS[syntaxpt-5 : syntaxpt+1]
 		{
 			syntaxVAL.logRangeExpr = newLogRange(newPipelineExpr(newMatcherExpr(syntaxDollar[2].matchers), syntaxDollar[3].stages), syntaxDollar[5].dur, nil, nil)
 		}
-	case 31:
+	case 34:
 		syntaxDollar = syntaxS[syntaxpt-6 : syntaxpt+1]
 		{
 			syntaxVAL.logRangeExpr = newLogRange(newPipelineExpr(newMatcherExpr(syntaxDollar[2].matchers), syntaxDollar[3].stages), syntaxDollar[5].dur, nil, syntaxDollar[6].offsetExpr)
 		}
-	case 32:
+	case 35:
 		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
 		{
 			syntaxVAL.logRangeExpr = newLogRange(newPipelineExpr(newMatcherExpr(syntaxDollar[1].matchers), syntaxDollar[2].stages), syntaxDollar[4].dur, syntaxDollar[3].unwrapExpr, nil)
 		}
-	case 33:
+	case 36:
 		syntaxDollar = syntaxS[syntaxpt-5 : syntaxpt+1]
 		{
 			syntaxVAL.logRangeExpr = newLogRange(newPipelineExpr(newMatcherExpr(syntaxDollar[1].matchers), syntaxDollar[2].stages), syntaxDollar[4].dur, syntaxDollar[3].unwrapExpr, syntaxDollar[5].offsetExpr)
 		}
-	case 34:
+	case 37:
 		syntaxDollar = syntaxS[syntaxpt-6 : syntaxpt+1]
 		{
 			syntaxVAL.logRangeExpr = newLogRange(newPipelineExpr(newMatcherExpr(syntaxDollar[2].matchers), syntaxDollar[3].stages), syntaxDollar[6].dur, syntaxDollar[4].unwrapExpr, nil)
 		}
-	case 35:
+	case 38:
 		syntaxDollar = syntaxS[syntaxpt-7 : syntaxpt+1]
 		{
 			syntaxVAL.logRangeExpr = newLogRange(newPipelineExpr(newMatcherExpr(syntaxDollar[2].matchers), syntaxDollar[3].stages), syntaxDollar[6].dur, syntaxDollar[4].unwrapExpr, syntaxDollar[7].offsetExpr)
 		}
-	case 36:
+	case 39:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.logRangeExpr = newLogRange(newPipelineExpr(newMatcherExpr(syntaxDollar[1].matchers), syntaxDollar[3].stages), syntaxDollar[2].dur, nil, nil)
 		}
-	case 37:
+	case 40:
 		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
 		{
 			syntaxVAL.logRangeExpr = newLogRange(newPipelineExpr(newMatcherExpr(syntaxDollar[1].matchers), syntaxDollar[4].stages), syntaxDollar[2].dur, nil, syntaxDollar[3].offsetExpr)
 		}
-	case 38:
+	case 41:
 		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
 		{
 			syntaxVAL.logRangeExpr = newLogRange(newPipelineExpr(newMatcherExpr(syntaxDollar[1].matchers), syntaxDollar[3].stages), syntaxDollar[2].dur, syntaxDollar[4].unwrapExpr, nil)
 		}
-	case 39:
+	case 42:
 		syntaxDollar = syntaxS[syntaxpt-5 : syntaxpt+1]
 		{
 			syntaxVAL.logRangeExpr = newLogRange(newPipelineExpr(newMatcherExpr(syntaxDollar[1].matchers), syntaxDollar[4].stages), syntaxDollar[2].dur, syntaxDollar[5].unwrapExpr, syntaxDollar[3].offsetExpr)
 		}
-	case 40:
+	case 43:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.logRangeExpr = syntaxDollar[2].logRangeExpr
 		}
-	case 42:
+	case 45:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.unwrapExpr = newUnwrapExpr(syntaxDollar[3].str, "")
 		}
-	case 43:
+	case 46:
 		syntaxDollar = syntaxS[syntaxpt-6 : syntaxpt+1]
 		{
 			syntaxVAL.unwrapExpr = newUnwrapExpr(syntaxDollar[5].str, syntaxDollar[3].op)
 		}
-	case 44:
+	case 47:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.unwrapExpr = syntaxDollar[1].unwrapExpr.addPostFilter(syntaxDollar[3].filterer)
 		}
-	case 45:
+	case 48:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.op = OpConvBytes
 		}
-	case 46:
+	case 49:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.op = OpConvDuration
 		}
-	case 47:
+	case 50:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.op = OpConvDurationSeconds
 		}
-	case 48:
+	case 51:
 		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
 		{
 			syntaxVAL.metricExpr = newRangeAggregationExpr(syntaxDollar[3].logRangeExpr, syntaxDollar[1].op, nil, nil)
 		}
-	case 49:
+	case 52:
 		syntaxDollar = syntaxS[syntaxpt-6 : syntaxpt+1]
 		{
 			syntaxVAL.metricExpr = newRangeAggregationExpr(syntaxDollar[5].logRangeExpr, syntaxDollar[1].op, nil, &syntaxDollar[3].str)
 		}
-	case 50:
+	case 53:
 		syntaxDollar = syntaxS[syntaxpt-5 : syntaxpt+1]
 		{
 			syntaxVAL.metricExpr = newRangeAggregationExpr(syntaxDollar[3].logRangeExpr, syntaxDollar[1].op, syntaxDollar[5].grouping, nil)
 		}
-	case 51:
+	case 54:
 		syntaxDollar = syntaxS[syntaxpt-7 : syntaxpt+1]
 		{
 			syntaxVAL.metricExpr = newRangeAggregationExpr(syntaxDollar[5].logRangeExpr, syntaxDollar[1].op, syntaxDollar[7].grouping, &syntaxDollar[3].str)
 		}
-	case 52:
+	case 55:
+		syntaxDollar = syntaxS[syntaxpt-5 : syntaxpt+1]
+		{
+			syntaxVAL.metricExpr = newSubqueryExpr(syntaxDollar[3].metricExpr, syntaxDollar[1].op, syntaxDollar[4].subquery, nil, nil)
+		}
+	case 56:
+		syntaxDollar = syntaxS[syntaxpt-6 : syntaxpt+1]
+		{
+			syntaxVAL.metricExpr = newSubqueryExpr(syntaxDollar[3].metricExpr, syntaxDollar[1].op, syntaxDollar[4].subquery, syntaxDollar[5].offsetExpr, nil)
+		}
+	case 57:
+		syntaxDollar = syntaxS[syntaxpt-7 : syntaxpt+1]
+		{
+			syntaxVAL.metricExpr = newSubqueryExpr(syntaxDollar[5].metricExpr, syntaxDollar[1].op, syntaxDollar[6].subquery, nil, &syntaxDollar[3].str)
+		}
+	case 58:
+		syntaxDollar = syntaxS[syntaxpt-8 : syntaxpt+1]
+		{
+			syntaxVAL.metricExpr = newSubqueryExpr(syntaxDollar[5].metricExpr, syntaxDollar[1].op, syntaxDollar[6].subquery, syntaxDollar[7].offsetExpr, &syntaxDollar[3].str)
+		}
+	case 59:
 		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
 		{
 			syntaxVAL.metricExpr = mustNewVectorAggregationExpr(syntaxDollar[3].metricExpr, syntaxDollar[1].op, nil, nil)
 		}
-	case 53:
+	case 60:
 		syntaxDollar = syntaxS[syntaxpt-5 : syntaxpt+1]
 		{
 			syntaxVAL.metricExpr = mustNewVectorAggregationExpr(syntaxDollar[4].metricExpr, syntaxDollar[1].op, syntaxDollar[2].grouping, nil)
 		}
-	case 54:
+	case 61:
 		syntaxDollar = syntaxS[syntaxpt-5 : syntaxpt+1]
 		{
 			syntaxVAL.metricExpr = mustNewVectorAggregationExpr(syntaxDollar[3].metricExpr, syntaxDollar[1].op, syntaxDollar[5].grouping, nil)
 		}
-	case 55:
+	case 62:
 		syntaxDollar = syntaxS[syntaxpt-6 : syntaxpt+1]
 		{
 			syntaxVAL.metricExpr = mustNewVectorAggregationExpr(syntaxDollar[5].metricExpr, syntaxDollar[1].op, nil, &syntaxDollar[3].str)
 		}
-	case 56:
+	case 63:
 		syntaxDollar = syntaxS[syntaxpt-7 : syntaxpt+1]
 		{
 			syntaxVAL.metricExpr = mustNewVectorAggregationExpr(syntaxDollar[5].metricExpr, syntaxDollar[1].op, syntaxDollar[7].grouping, &syntaxDollar[3].str)
 		}
-	case 57:
+	case 64:
 		syntaxDollar = syntaxS[syntaxpt-7 : syntaxpt+1]
 		{
 			syntaxVAL.metricExpr = mustNewVectorAggregationExpr(syntaxDollar[6].metricExpr, syntaxDollar[1].op, syntaxDollar[2].grouping, &syntaxDollar[4].str)
 		}
-	case 58:
+	case 65:
 		syntaxDollar = syntaxS[syntaxpt-12 : syntaxpt+1]
 		{
 			syntaxVAL.metricExpr = mustNewLabelReplaceExpr(syntaxDollar[3].metricExpr, syntaxDollar[5].str, syntaxDollar[7].str, syntaxDollar[9].str, syntaxDollar[11].str)
 		}
-	case 59:
+	case 66:
+		syntaxDollar = syntaxS[syntaxpt-6 : syntaxpt+1]
+		{
+			syntaxVAL.metricExpr = newHistogramQuantileExpr(syntaxDollar[5].metricExpr, syntaxDollar[3].str)
+		}
+	case 67:
+		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
+		{
+			syntaxVAL.metricExpr = newTimeFunctionExpr(nil, syntaxDollar[1].op)
+		}
+	case 68:
+		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
+		{
+			syntaxVAL.metricExpr = newTimeFunctionExpr(syntaxDollar[3].metricExpr, syntaxDollar[1].op)
+		}
+	case 69:
+		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
+		{
+			syntaxVAL.op = OpHour
+		}
+	case 70:
+		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
+		{
+			syntaxVAL.op = OpMinute
+		}
+	case 71:
+		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
+		{
+			syntaxVAL.op = OpDayOfWeek
+		}
+	case 72:
+		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
+		{
+			syntaxVAL.op = OpDayOfMonth
+		}
+	case 73:
+		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
+		{
+			syntaxVAL.op = OpDaysInMonth
+		}
+	case 74:
+		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
+		{
+			syntaxVAL.op = OpMonth
+		}
+	case 75:
+		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
+		{
+			syntaxVAL.op = OpYear
+		}
+	case 76:
+		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
+		{
+			syntaxVAL.op = OpTimestamp
+		}
+	case 77:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.matchers = syntaxDollar[2].matchers
 		}
-	case 60:
+	case 78:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.matchers = syntaxDollar[2].matchers
 		}
-	case 61:
+	case 79:
 		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
 		{
 		}
-	case 62:
+	case 80:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.matchers = []*labels.Matcher{syntaxDollar[1].matcher}
 		}
-	case 63:
+	case 81:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.matchers = append(syntaxDollar[1].matchers, syntaxDollar[3].matcher)
 		}
-	case 64:
+	case 82:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.matcher = mustNewMatcher(labels.MatchEqual, syntaxDollar[1].str, syntaxDollar[3].str)
 		}
-	case 65:
+	case 83:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.matcher = mustNewMatcher(labels.MatchNotEqual, syntaxDollar[1].str, syntaxDollar[3].str)
 		}
-	case 66:
+	case 84:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.matcher = mustNewMatcher(labels.MatchRegexp, syntaxDollar[1].str, syntaxDollar[3].str)
 		}
-	case 67:
+	case 85:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.matcher = mustNewMatcher(labels.MatchNotRegexp, syntaxDollar[1].str, syntaxDollar[3].str)
 		}
-	case 68:
+	case 86:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.stages = MultiStageExpr{syntaxDollar[1].stage}
 		}
-	case 69:
+	case 87:
 		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
 		{
 			syntaxVAL.stages = append(syntaxDollar[1].stages, syntaxDollar[2].stage)
 		}
-	case 70:
+	case 88:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.stage = syntaxDollar[1].lineFilterExpr
 		}
-	case 71:
+	case 89:
 		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
 		{
 			syntaxVAL.stage = syntaxDollar[2].stage
 		}
-	case 72:
+	case 90:
 		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
 		{
 			syntaxVAL.stage = syntaxDollar[2].stage
 		}
-	case 73:
+	case 91:
 		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
 		{
 			syntaxVAL.stage = syntaxDollar[2].stage
 		}
-	case 74:
+	case 92:
 		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
 		{
 			syntaxVAL.stage = syntaxDollar[2].stage
 		}
-	case 75:
+	case 93:
 		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
 		{
 			syntaxVAL.stage = &LabelFilterExpr{LabelFilterer: syntaxDollar[2].filterer}
 		}
-	case 76:
+	case 94:
 		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
 		{
 			syntaxVAL.stage = syntaxDollar[2].stage
 		}
-	case 77:
+	case 95:
 		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
 		{
 			syntaxVAL.stage = syntaxDollar[2].stage
 		}
-	case 78:
+	case 96:
 		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
 		{
 			syntaxVAL.stage = syntaxDollar[2].stage
 		}
-	case 79:
+	case 97:
 		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
 		{
 			syntaxVAL.stage = syntaxDollar[2].stage
 		}
-	case 80:
+	case 98:
 		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
 		{
 			syntaxVAL.stage = syntaxDollar[2].stage
 		}
-	case 81:
+	case 99:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.filter = log.LineMatchRegexp
 		}
-	case 82:
+	case 100:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.filter = log.LineMatchEqual
 		}
-	case 83:
+	case 101:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.filter = log.LineMatchPattern
 		}
-	case 84:
+	case 102:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.filter = log.LineMatchNotRegexp
 		}
-	case 85:
+	case 103:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.filter = log.LineMatchNotEqual
 		}
-	case 86:
+	case 104:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.filter = log.LineMatchNotPattern
 		}
-	case 87:
+	case 105:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.op = OpFilterIP
 		}
-	case 88:
+	case 106:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.lineFilterExpr = newLineFilterExpr(log.LineMatchEqual, "", syntaxDollar[1].str)
 		}
-	case 89:
+	case 107:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.lineFilterExpr = newOrLineFilterExpr(newLineFilterExpr(log.LineMatchEqual, "", syntaxDollar[1].str), syntaxDollar[3].lineFilterExpr)
 		}
-	case 90:
+	case 108:
 		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
 		{
 			syntaxVAL.lineFilterExpr = newLineFilterExpr(log.LineMatchEqual, syntaxDollar[1].op, syntaxDollar[3].str)
 		}
-	case 91:
+	case 109:
 		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
 		{
 			syntaxVAL.lineFilterExpr = newLineFilterExpr(syntaxDollar[1].filter, "", syntaxDollar[2].str)
 		}
-	case 92:
+	case 110:
 		syntaxDollar = syntaxS[syntaxpt-5 : syntaxpt+1]
 		{
 			syntaxVAL.lineFilterExpr = newLineFilterExpr(syntaxDollar[1].filter, syntaxDollar[2].op, syntaxDollar[4].str)
 		}
-	case 93:
+	case 111:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.lineFilterExpr = newOrLineFilterExpr(syntaxDollar[1].lineFilterExpr, syntaxDollar[3].lineFilterExpr)
 		}
-	case 94:
+	case 112:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.lineFilterExpr = syntaxDollar[1].lineFilterExpr
 		}
-	case 95:
+	case 113:
 		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
 		{
 			syntaxVAL.lineFilterExpr = newNestedLineFilterExpr(syntaxDollar[1].lineFilterExpr, syntaxDollar[2].lineFilterExpr)
 		}
-	case 96:
+	case 114:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.strs = []string{syntaxDollar[1].str}
 		}
-	case 97:
+	case 115:
 		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
 		{
 			syntaxVAL.strs = append(syntaxDollar[1].strs, syntaxDollar[2].str)
 		}
-	case 98:
+	case 116:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.stage = newLogfmtParserExpr(nil)
 		}
-	case 99:
+	case 117:
 		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
 		{
 			syntaxVAL.stage = newLogfmtParserExpr(syntaxDollar[2].strs)
 		}
-	case 100:
+	case 118:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.stage = newLabelParserExpr(OpParserTypeJSON, "")
 		}
-	case 101:
+	case 119:
 		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
 		{
 			syntaxVAL.stage = newLabelParserExpr(OpParserTypeRegexp, syntaxDollar[2].str)
 		}
-	case 102:
+	case 120:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.stage = newLabelParserExpr(OpParserTypeUnpack, "")
 		}
-	case 103:
+	case 121:
 		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
 		{
 			syntaxVAL.stage = newLabelParserExpr(OpParserTypePattern, syntaxDollar[2].str)
 		}
-	case 104:
+	case 122:
 		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
 		{
 			syntaxVAL.stage = newJSONExpressionParser(syntaxDollar[2].labelExtractionExpressionList)
 		}
-	case 105:
+	case 123:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.stage = newLogfmtExpressionParser(syntaxDollar[3].labelExtractionExpressionList, syntaxDollar[2].strs)
 		}
-	case 106:
+	case 124:
 		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
 		{
 			syntaxVAL.stage = newLogfmtExpressionParser(syntaxDollar[2].labelExtractionExpressionList, nil)
 		}
-	case 107:
+	case 125:
 		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
 		{
 			syntaxVAL.stage = newLineFmtExpr(syntaxDollar[2].str)
 		}
-	case 108:
+	case 126:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.stage = newDecolorizeExpr()
 		}
-	case 109:
+	case 127:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.labelFormat = log.NewRenameLabelFmt(syntaxDollar[1].str, syntaxDollar[3].str)
 		}
-	case 110:
+	case 128:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.labelFormat = log.NewTemplateLabelFmt(syntaxDollar[1].str, syntaxDollar[3].str)
 		}
-	case 111:
+	case 129:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.labelsFormat = []log.LabelFmt{syntaxDollar[1].labelFormat}
 		}
-	case 112:
+	case 130:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.labelsFormat = append(syntaxDollar[1].labelsFormat, syntaxDollar[3].labelFormat)
 		}
-	case 114:
+	case 132:
 		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
 		{
 			syntaxVAL.stage = newLabelFmtExpr(syntaxDollar[2].labelsFormat)
 		}
-	case 115:
+	case 133:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.filterer = log.NewStringLabelFilter(syntaxDollar[1].matcher)
 		}
-	case 116:
+	case 134:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.filterer = syntaxDollar[1].filterer
 		}
-	case 117:
+	case 135:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.filterer = syntaxDollar[1].filterer
 		}
-	case 118:
+	case 136:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.filterer = syntaxDollar[1].filterer
 		}
-	case 119:
+	case 137:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.filterer = syntaxDollar[2].filterer
 		}
-	case 120:
+	case 138:
 		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
 		{
 			syntaxVAL.filterer = log.NewAndLabelFilter(syntaxDollar[1].filterer, syntaxDollar[2].filterer)
 		}
-	case 121:
+	case 139:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.filterer = log.NewAndLabelFilter(syntaxDollar[1].filterer, syntaxDollar[3].filterer)
 		}
-	case 122:
+	case 140:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.filterer = log.NewAndLabelFilter(syntaxDollar[1].filterer, syntaxDollar[3].filterer)
 		}
-	case 123:
+	case 141:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.filterer = log.NewOrLabelFilter(syntaxDollar[1].filterer, syntaxDollar[3].filterer)
 		}
-	case 124:
+	case 142:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.labelExtractionExpression = log.NewLabelExtractionExpr(syntaxDollar[1].str, syntaxDollar[3].str)
 		}
-	case 125:
+	case 143:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.labelExtractionExpression = log.NewLabelExtractionExpr(syntaxDollar[1].str, syntaxDollar[1].str)
 		}
-	case 126:
+	case 144:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.labelExtractionExpressionList = []log.LabelExtractionExpr{syntaxDollar[1].labelExtractionExpression}
 		}
-	case 127:
+	case 145:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.labelExtractionExpressionList = append(syntaxDollar[1].labelExtractionExpressionList, syntaxDollar[3].labelExtractionExpression)
 		}
-	case 128:
+	case 146:
 		syntaxDollar = syntaxS[syntaxpt-6 : syntaxpt+1]
 		{
 			syntaxVAL.filterer = log.NewIPLabelFilter(syntaxDollar[5].str, syntaxDollar[1].str, log.LabelFilterEqual)
 		}
-	case 129:
+	case 147:
 		syntaxDollar = syntaxS[syntaxpt-6 : syntaxpt+1]
 		{
 			syntaxVAL.filterer = log.NewIPLabelFilter(syntaxDollar[5].str, syntaxDollar[1].str, log.LabelFilterNotEqual)
 		}
-	case 130:
+	case 148:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.filterer = syntaxDollar[1].filterer
 		}
-	case 131:
+	case 149:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.filterer = syntaxDollar[1].filterer
 		}
-	case 132:
+	case 150:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.filterer = log.NewDurationLabelFilter(log.LabelFilterGreaterThan, syntaxDollar[1].str, syntaxDollar[3].dur)
 		}
-	case 133:
+	case 151:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.filterer = log.NewDurationLabelFilter(log.LabelFilterGreaterThanOrEqual, syntaxDollar[1].str, syntaxDollar[3].dur)
 		}
-	case 134:
+	case 152:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.filterer = log.NewDurationLabelFilter(log.LabelFilterLesserThan, syntaxDollar[1].str, syntaxDollar[3].dur)
 		}
-	case 135:
+	case 153:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.filterer = log.NewDurationLabelFilter(log.LabelFilterLesserThanOrEqual, syntaxDollar[1].str, syntaxDollar[3].dur)
 		}
-	case 136:
+	case 154:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.filterer = log.NewDurationLabelFilter(log.LabelFilterNotEqual, syntaxDollar[1].str, syntaxDollar[3].dur)
 		}
-	case 137:
+	case 155:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.filterer = log.NewDurationLabelFilter(log.LabelFilterEqual, syntaxDollar[1].str, syntaxDollar[3].dur)
 		}
-	case 138:
+	case 156:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.filterer = log.NewDurationLabelFilter(log.LabelFilterEqual, syntaxDollar[1].str, syntaxDollar[3].dur)
 		}
-	case 139:
+	case 157:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.filterer = log.NewBytesLabelFilter(log.LabelFilterGreaterThan, syntaxDollar[1].str, syntaxDollar[3].bytes)
 		}
-	case 140:
+	case 158:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.filterer = log.NewBytesLabelFilter(log.LabelFilterGreaterThanOrEqual, syntaxDollar[1].str, syntaxDollar[3].bytes)
 		}
-	case 141:
+	case 159:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.filterer = log.NewBytesLabelFilter(log.LabelFilterLesserThan, syntaxDollar[1].str, syntaxDollar[3].bytes)
 		}
-	case 142:
+	case 160:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.filterer = log.NewBytesLabelFilter(log.LabelFilterLesserThanOrEqual, syntaxDollar[1].str, syntaxDollar[3].bytes)
 		}
-	case 143:
+	case 161:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.filterer = log.NewBytesLabelFilter(log.LabelFilterNotEqual, syntaxDollar[1].str, syntaxDollar[3].bytes)
 		}
-	case 144:
+	case 162:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.filterer = log.NewBytesLabelFilter(log.LabelFilterEqual, syntaxDollar[1].str, syntaxDollar[3].bytes)
 		}
-	case 145:
+	case 163:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.filterer = log.NewBytesLabelFilter(log.LabelFilterEqual, syntaxDollar[1].str, syntaxDollar[3].bytes)
 		}
-	case 146:
+	case 164:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.filterer = log.NewNumericLabelFilter(log.LabelFilterGreaterThan, syntaxDollar[1].str, syntaxDollar[3].literalExpr.Val)
 		}
-	case 147:
+	case 165:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.filterer = log.NewNumericLabelFilter(log.LabelFilterGreaterThanOrEqual, syntaxDollar[1].str, syntaxDollar[3].literalExpr.Val)
 		}
-	case 148:
+	case 166:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.filterer = log.NewNumericLabelFilter(log.LabelFilterLesserThan, syntaxDollar[1].str, syntaxDollar[3].literalExpr.Val)
 		}
-	case 149:
+	case 167:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.filterer = log.NewNumericLabelFilter(log.LabelFilterLesserThanOrEqual, syntaxDollar[1].str, syntaxDollar[3].literalExpr.Val)
 		}
-	case 150:
+	case 168:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.filterer = log.NewNumericLabelFilter(log.LabelFilterNotEqual, syntaxDollar[1].str, syntaxDollar[3].literalExpr.Val)
 		}
-	case 151:
+	case 169:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.filterer = log.NewNumericLabelFilter(log.LabelFilterEqual, syntaxDollar[1].str, syntaxDollar[3].literalExpr.Val)
 		}
-	case 152:
+	case 170:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.filterer = log.NewNumericLabelFilter(log.LabelFilterEqual, syntaxDollar[1].str, syntaxDollar[3].literalExpr.Val)
 		}
-	case 153:
+	case 171:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.namedMatcher = log.NewNamedLabelMatcher(nil, syntaxDollar[1].str)
 		}
-	case 154:
+	case 172:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.namedMatcher = log.NewNamedLabelMatcher(syntaxDollar[1].matcher, "")
 		}
-	case 155:
+	case 173:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.namedMatchers = []log.NamedLabelMatcher{syntaxDollar[1].namedMatcher}
 		}
-	case 156:
+	case 174:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.namedMatchers = append(syntaxDollar[1].namedMatchers, syntaxDollar[3].namedMatcher)
 		}
-	case 157:
+	case 175:
 		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
 		{
 			syntaxVAL.stage = newDropLabelsExpr(syntaxDollar[2].namedMatchers)
 		}
-	case 158:
+	case 176:
 		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
 		{
 			syntaxVAL.stage = newKeepLabelsExpr(syntaxDollar[2].namedMatchers)
 		}
-	case 159:
+	case 177:
 		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
 		{
 			syntaxVAL.metricExpr = mustNewBinOpExpr("or", syntaxDollar[3].binOpts, syntaxDollar[1].expr, syntaxDollar[4].expr)
 		}
-	case 160:
+	case 178:
 		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
 		{
 			syntaxVAL.metricExpr = mustNewBinOpExpr("and", syntaxDollar[3].binOpts, syntaxDollar[1].expr, syntaxDollar[4].expr)
 		}
-	case 161:
+	case 179:
 		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
 		{
 			syntaxVAL.metricExpr = mustNewBinOpExpr("unless", syntaxDollar[3].binOpts, syntaxDollar[1].expr, syntaxDollar[4].expr)
 		}
-	case 162:
+	case 180:
 		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
 		{
 			syntaxVAL.metricExpr = mustNewBinOpExpr("+", syntaxDollar[3].binOpts, syntaxDollar[1].expr, syntaxDollar[4].expr)
 		}
-	case 163:
+	case 181:
 		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
 		{
 			syntaxVAL.metricExpr = mustNewBinOpExpr("-", syntaxDollar[3].binOpts, syntaxDollar[1].expr, syntaxDollar[4].expr)
 		}
-	case 164:
+	case 182:
 		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
 		{
 			syntaxVAL.metricExpr = mustNewBinOpExpr("*", syntaxDollar[3].binOpts, syntaxDollar[1].expr, syntaxDollar[4].expr)
 		}
-	case 165:
+	case 183:
 		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
 		{
 			syntaxVAL.metricExpr = mustNewBinOpExpr("/", syntaxDollar[3].binOpts, syntaxDollar[1].expr, syntaxDollar[4].expr)
 		}
-	case 166:
+	case 184:
 		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
 		{
 			syntaxVAL.metricExpr = mustNewBinOpExpr("%", syntaxDollar[3].binOpts, syntaxDollar[1].expr, syntaxDollar[4].expr)
 		}
-	case 167:
+	case 185:
 		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
 		{
 			syntaxVAL.metricExpr = mustNewBinOpExpr("^", syntaxDollar[3].binOpts, syntaxDollar[1].expr, syntaxDollar[4].expr)
 		}
-	case 168:
+	case 186:
 		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
 		{
 			syntaxVAL.metricExpr = mustNewBinOpExpr("==", syntaxDollar[3].binOpts, syntaxDollar[1].expr, syntaxDollar[4].expr)
 		}
-	case 169:
+	case 187:
 		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
 		{
 			syntaxVAL.metricExpr = mustNewBinOpExpr("!=", syntaxDollar[3].binOpts, syntaxDollar[1].expr, syntaxDollar[4].expr)
 		}
-	case 170:
+	case 188:
 		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
 		{
 			syntaxVAL.metricExpr = mustNewBinOpExpr(">", syntaxDollar[3].binOpts, syntaxDollar[1].expr, syntaxDollar[4].expr)
 		}
-	case 171:
+	case 189:
 		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
 		{
 			syntaxVAL.metricExpr = mustNewBinOpExpr(">=", syntaxDollar[3].binOpts, syntaxDollar[1].expr, syntaxDollar[4].expr)
 		}
-	case 172:
+	case 190:
 		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
 		{
 			syntaxVAL.metricExpr = mustNewBinOpExpr("<", syntaxDollar[3].binOpts, syntaxDollar[1].expr, syntaxDollar[4].expr)
 		}
-	case 173:
+	case 191:
 		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
 		{
 			syntaxVAL.metricExpr = mustNewBinOpExpr("<=", syntaxDollar[3].binOpts, syntaxDollar[1].expr, syntaxDollar[4].expr)
 		}
-	case 174:
+	case 192:
 		syntaxDollar = syntaxS[syntaxpt-0 : syntaxpt+1]
 		{
 			syntaxVAL.binOpts = &BinOpOptions{VectorMatching: &VectorMatching{Card: CardOneToOne}}
 		}
-	case 175:
+	case 193:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.binOpts = &BinOpOptions{VectorMatching: &VectorMatching{Card: CardOneToOne}, ReturnBool: true}
 		}
-	case 176:
+	case 194:
 		syntaxDollar = syntaxS[syntaxpt-5 : syntaxpt+1]
 		{
 			syntaxVAL.binOpts = syntaxDollar[1].binOpts
 			syntaxVAL.binOpts.VectorMatching.On = true
 			syntaxVAL.binOpts.VectorMatching.MatchingLabels = syntaxDollar[4].strs
 		}
-	case 177:
+	case 195:
 		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
 		{
 			syntaxVAL.binOpts = syntaxDollar[1].binOpts
 			syntaxVAL.binOpts.VectorMatching.On = true
 		}
-	case 178:
+	case 196:
 		syntaxDollar = syntaxS[syntaxpt-5 : syntaxpt+1]
 		{
 			syntaxVAL.binOpts = syntaxDollar[1].binOpts
 			syntaxVAL.binOpts.VectorMatching.MatchingLabels = syntaxDollar[4].strs
 		}
-	case 179:
+	case 197:
 		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
 		{
 			syntaxVAL.binOpts = syntaxDollar[1].binOpts
 		}
-	case 180:
+	case 198:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.binOpts = syntaxDollar[1].binOpts
 		}
-	case 181:
+	case 199:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.binOpts = syntaxDollar[1].binOpts
 		}
-	case 182:
+	case 200:
 		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
 		{
 			syntaxVAL.binOpts = syntaxDollar[1].binOpts
 			syntaxVAL.binOpts.VectorMatching.Card = CardManyToOne
 		}
-	case 183:
+	case 201:
 		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
 		{
 			syntaxVAL.binOpts = syntaxDollar[1].binOpts
 			syntaxVAL.binOpts.VectorMatching.Card = CardManyToOne
 		}
-	case 184:
+	case 202:
 		syntaxDollar = syntaxS[syntaxpt-5 : syntaxpt+1]
 		{
 			syntaxVAL.binOpts = syntaxDollar[1].binOpts
 			syntaxVAL.binOpts.VectorMatching.Card = CardManyToOne
 			syntaxVAL.binOpts.VectorMatching.Include = syntaxDollar[4].strs
 		}
-	case 185:
+	case 203:
 		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
 		{
 			syntaxVAL.binOpts = syntaxDollar[1].binOpts
 			syntaxVAL.binOpts.VectorMatching.Card = CardOneToMany
 		}
-	case 186:
+	case 204:
 		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
 		{
 			syntaxVAL.binOpts = syntaxDollar[1].binOpts
 			syntaxVAL.binOpts.VectorMatching.Card = CardOneToMany
 		}
-	case 187:
+	case 205:
 		syntaxDollar = syntaxS[syntaxpt-5 : syntaxpt+1]
 		{
 			syntaxVAL.binOpts = syntaxDollar[1].binOpts
 			syntaxVAL.binOpts.VectorMatching.Card = CardOneToMany
 			syntaxVAL.binOpts.VectorMatching.Include = syntaxDollar[4].strs
 		}
-	case 188:
+	case 206:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.literalExpr = mustNewLiteralExpr(syntaxDollar[1].str, false)
 		}
-	case 189:
+	case 207:
 		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
 		{
 			syntaxVAL.literalExpr = mustNewLiteralExpr(syntaxDollar[2].str, false)
 		}
-	case 190:
+	case 208:
 		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
 		{
 			syntaxVAL.literalExpr = mustNewLiteralExpr(syntaxDollar[2].str, true)
 		}
-	case 191:
+	case 209:
 		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
 		{
 			syntaxVAL.metricExpr = NewVectorExpr(syntaxDollar[3].str)
 		}
-	case 192:
+	case 210:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.str = OpTypeVector
 		}
-	case 193:
+	case 211:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.op = OpTypeSum
 		}
-	case 194:
+	case 212:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.op = OpTypeAvg
 		}
-	case 195:
+	case 213:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.op = OpTypeCount
 		}
-	case 196:
+	case 214:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.op = OpTypeMax
 		}
-	case 197:
+	case 215:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.op = OpTypeMin
 		}
-	case 198:
+	case 216:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.op = OpTypeStddev
 		}
-	case 199:
+	case 217:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.op = OpTypeStdvar
 		}
-	case 200:
+	case 218:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.op = OpTypeBottomK
 		}
-	case 201:
+	case 219:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.op = OpTypeTopK
 		}
-	case 202:
+	case 220:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.op = OpTypeSort
 		}
-	case 203:
+	case 221:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.op = OpTypeSortDesc
 		}
-	case 204:
+	case 222:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.op = OpTypeApproxTopK
 		}
-	case 205:
+	case 223:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.op = OpRangeTypeCount
 		}
-	case 206:
+	case 224:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.op = OpRangeTypeRate
 		}
-	case 207:
+	case 225:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.op = OpRangeTypeRateCounter
 		}
-	case 208:
+	case 226:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.op = OpRangeTypeBytes
 		}
-	case 209:
+	case 227:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.op = OpRangeTypeBytesRate
 		}
-	case 210:
+	case 228:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.op = OpRangeTypeAvg
 		}
-	case 211:
+	case 229:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.op = OpRangeTypeSum
 		}
-	case 212:
+	case 230:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.op = OpRangeTypeMin
 		}
-	case 213:
+	case 231:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.op = OpRangeTypeMax
 		}
-	case 214:
+	case 232:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.op = OpRangeTypeStdvar
 		}
-	case 215:
+	case 233:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.op = OpRangeTypeStddev
 		}
-	case 216:
+	case 234:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.op = OpRangeTypeQuantile
 		}
-	case 217:
+	case 235:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.op = OpRangeTypeFirst
 		}
-	case 218:
+	case 236:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.op = OpRangeTypeLast
 		}
-	case 219:
+	case 237:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.op = OpRangeTypeAbsent
 		}
-	case 220:
+	case 238:
+		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
+		{
+			syntaxVAL.op = OpRangeTypeChanges
+		}
+	case 239:
+		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
+		{
+			syntaxVAL.op = OpRangeTypeResets
+		}
+	case 240:
+		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
+		{
+			syntaxVAL.op = OpRangeTypeDeriv
+		}
+	case 241:
+		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
+		{
+			syntaxVAL.op = OpRangeTypePredictLinear
+		}
+	case 242:
 		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
 		{
 			syntaxVAL.offsetExpr = newOffsetExpr(syntaxDollar[2].dur)
 		}
-	case 221:
+	case 243:
+		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
+		{
+			syntaxVAL.offsetExpr = &OffsetExpr{At: syntaxDollar[1].atModifier}
+		}
+	case 244:
+		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
+		{
+			syntaxVAL.offsetExpr = newOffsetExpr(syntaxDollar[2].dur).withAt(syntaxDollar[3].atModifier)
+		}
+	case 245:
+		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
+		{
+			syntaxVAL.offsetExpr = newOffsetExpr(syntaxDollar[3].dur).withAt(syntaxDollar[1].atModifier)
+		}
+	case 246:
+		syntaxDollar = syntaxS[syntaxpt-2 : syntaxpt+1]
+		{
+			syntaxVAL.atModifier = newAtModifier(mustNewFloat(syntaxDollar[2].str))
+		}
+	case 247:
+		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
+		{
+			syntaxVAL.atModifier = &AtModifier{Start: true}
+		}
+	case 248:
+		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
+		{
+			syntaxVAL.atModifier = &AtModifier{End: true}
+		}
+	case 249:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.strs = []string{syntaxDollar[1].str}
 		}
-	case 222:
+	case 250:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.strs = append(syntaxDollar[1].strs, syntaxDollar[3].str)
 		}
-	case 223:
+	case 251:
 		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
 		{
 			syntaxVAL.grouping = &Grouping{Without: false, Groups: syntaxDollar[3].strs}
 		}
-	case 224:
+	case 252:
 		syntaxDollar = syntaxS[syntaxpt-4 : syntaxpt+1]
 		{
 			syntaxVAL.grouping = &Grouping{Without: true, Groups: syntaxDollar[3].strs}
 		}
-	case 225:
+	case 253:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.grouping = &Grouping{Without: false, Groups: nil}
 		}
-	case 226:
+	case 254:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.grouping = &Grouping{Without: true, Groups: nil}
 		}
-	case 227:
+	case 255:
 		syntaxDollar = syntaxS[syntaxpt-1 : syntaxpt+1]
 		{
 			syntaxVAL.metricExprs = []SampleExpr{syntaxDollar[1].metricExpr}
 		}
-	case 228:
+	case 256:
 		syntaxDollar = syntaxS[syntaxpt-3 : syntaxpt+1]
 		{
 			syntaxVAL.metricExprs = append(syntaxDollar[1].metricExprs, syntaxDollar[3].metricExpr)
@@ -18,6 +18,9 @@ type SampleExprVisitor interface {
 	VisitVectorAggregation(*VectorAggregationExpr)
 	VisitRangeAggregation(*RangeAggregationExpr)
 	VisitLabelReplace(*LabelReplaceExpr)
+	VisitSubquery(*SubqueryExpr)
+	VisitHistogramQuantile(*HistogramQuantileExpr)
+	VisitTimeFunction(*TimeFunctionExpr)
 	VisitLiteral(*LiteralExpr)
 	VisitVector(*VectorExpr)
 }
@@ -68,6 +71,9 @@ type DepthFirstTraversal struct {
 	VisitMatchersFn               func(v RootVisitor, e *MatchersExpr)
 	VisitPipelineFn               func(v RootVisitor, e *PipelineExpr)
 	VisitRangeAggregationFn       func(v RootVisitor, e *RangeAggregationExpr)
+	VisitSubqueryFn               func(v RootVisitor, e *SubqueryExpr)
+	VisitHistogramQuantileFn      func(v RootVisitor, e *HistogramQuantileExpr)
+	VisitTimeFunctionFn           func(v RootVisitor, e *TimeFunctionExpr)
 	VisitVectorFn                 func(v RootVisitor, e *VectorExpr)
 	VisitVectorAggregationFn      func(v RootVisitor, e *VectorAggregationExpr)
 	VisitVariantsFn               func(v RootVisitor, e *MultiVariantExpr)
@@ -272,6 +278,42 @@ func (v *DepthFirstTraversal) VisitRangeAggregation(e *RangeAggregationExpr) {
 	}
 }
 
+// VisitSubquery implements RootVisitor.
+func (v *DepthFirstTraversal) VisitSubquery(e *SubqueryExpr) {
+	if e == nil {
+		return
+	}
+	if v.VisitSubqueryFn != nil {
+		v.VisitSubqueryFn(v, e)
+	} else {
+		e.Left.Accept(v)
+	}
+}
+
+// VisitHistogramQuantile implements RootVisitor.
+func (v *DepthFirstTraversal) VisitHistogramQuantile(e *HistogramQuantileExpr) {
+	if e == nil {
+		return
+	}
+	if v.VisitHistogramQuantileFn != nil {
+		v.VisitHistogramQuantileFn(v, e)
+	} else {
+		e.Left.Accept(v)
+	}
+}
+
+// VisitTimeFunction implements RootVisitor.
+func (v *DepthFirstTraversal) VisitTimeFunction(e *TimeFunctionExpr) {
+	if e == nil {
+		return
+	}
+	if v.VisitTimeFunctionFn != nil {
+		v.VisitTimeFunctionFn(v, e)
+	} else if e.Left != nil {
+		e.Left.Accept(v)
+	}
+}
+
 // VisitVector implements RootVisitor.
 func (v *DepthFirstTraversal) VisitVector(e *VectorExpr) {
 	if e == nil {
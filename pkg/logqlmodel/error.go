@@ -97,6 +97,12 @@ func NewSeriesLimitError(limit int) *LimitError {
 	}
 }
 
+func NewMemoryLimitError(limit int64) *LimitError {
+	return &LimitError{
+		error: fmt.Errorf("maximum memory (%d bytes) reached for a single query; consider reducing query cardinality by adding more specific stream selectors, reducing the time range, or aggregating results by fewer labels", limit),
+	}
+}
+
 // Is allows to use errors.Is(err,ErrLimit) on this error.
 func (e LimitError) Is(target error) bool {
 	return target == ErrLimit
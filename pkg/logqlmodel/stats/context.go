@@ -21,6 +21,7 @@ package stats
 
 import (
 	"context"
+	"slices"
 	"sync"
 	"sync/atomic" //lint:ignore faillint we can't use go.uber.org/atomic with a protobuf struct without wrapping it.
 	"time"
@@ -174,6 +175,15 @@ func JoinResults(ctx context.Context, res Result) {
 	stats.result.Merge(res)
 }
 
+// AddPlan adds the plan of an executed query, annotated with runtime
+// statistics, to the statistics in a concurrency-safe manner.
+func (c *Context) AddPlan(plan string) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	c.result.Plans = append(c.result.Plans, plan)
+}
+
 // JoinIngesters joins the ingester result statistics in a concurrency-safe manner.
 func JoinIngesters(ctx context.Context, inc Ingester) {
 	stats := FromContext(ctx)
@@ -315,6 +325,9 @@ func (r *Result) Merge(m Result) {
 	r.Caches.Merge(m.Caches)
 	r.Summary.Merge(m.Summary)
 	r.Index.Merge(m.Index)
+	if len(m.Plans) > 0 {
+		r.Plans = append(slices.Clip(r.Plans), m.Plans...)
+	}
 	r.ComputeSummary(ConvertSecondsToNanoseconds(r.Summary.ExecTime+m.Summary.ExecTime),
 		ConvertSecondsToNanoseconds(r.Summary.QueueTime+m.Summary.QueueTime), int(r.Summary.TotalEntriesReturned))
 }
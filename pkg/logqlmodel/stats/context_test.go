@@ -296,6 +296,31 @@ func TestResult_Merge(t *testing.T) {
 	}, res)
 }
 
+func TestResult_MergePlans(t *testing.T) {
+	statsCtx, ctx := NewContext(context.Background())
+	statsCtx.AddPlan("plan 1")
+
+	// plans of subqueries are appended in the order they are merged
+	JoinResults(ctx, Result{Plans: []string{"plan 2"}})
+	res := statsCtx.Result(0, 0, 0)
+	require.Equal(t, []string{"plan 1", "plan 2"}, res.Plans)
+
+	var merged Result
+	merged.MergeSplit(res)
+	merged.MergeSplit(Result{Plans: []string{"plan 3"}})
+	require.Equal(t, []string{"plan 1", "plan 2", "plan 3"}, merged.Plans)
+
+	// merging doesn't modify the plans of the context
+	require.Equal(t, []string{"plan 1", "plan 2"}, statsCtx.Result(0, 0, 0).Plans)
+
+	// plans are sent from queriers to the frontend
+	buf, err := merged.Marshal()
+	require.NoError(t, err)
+	var decoded Result
+	require.NoError(t, decoded.Unmarshal(buf))
+	require.Equal(t, merged, decoded)
+}
+
 func TestReset(t *testing.T) {
 	statsCtx, ctx := NewContext(context.Background())
 	fakeIngesterQuery(ctx)
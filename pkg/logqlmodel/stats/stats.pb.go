@@ -33,6 +33,7 @@ type Result struct {
 	Ingester Ingester `protobuf:"bytes,3,opt,name=ingester,proto3" json:"ingester"`
 	Caches   Caches   `protobuf:"bytes,4,opt,name=caches,proto3" json:"cache"`
 	Index    Index    `protobuf:"bytes,5,opt,name=index,proto3" json:"index"`
+	Plans    []string `protobuf:"bytes,6,rep,name=plans,proto3" json:"plans,omitempty"`
 }
 
 func (m *Result) Reset()      { *m = Result{} }
@@ -102,6 +103,13 @@ func (m *Result) GetIndex() Index {
 	return Index{}
 }
 
+func (m *Result) GetPlans() []string {
+	if m != nil {
+		return m.Plans
+	}
+	return nil
+}
+
 type Caches struct {
 	Chunk               Cache `protobuf:"bytes,1,opt,name=chunk,proto3" json:"chunk"`
 	Index               Cache `protobuf:"bytes,2,opt,name=index,proto3" json:"index"`
@@ -1034,109 +1042,111 @@ func init() {
 func init() { proto.RegisterFile("pkg/logqlmodel/stats/stats.proto", fileDescriptor_6cdfe5d2aea33ebb) }
 
 var fileDescriptor_6cdfe5d2aea33ebb = []byte{
-	// 1632 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x58, 0xcd, 0x6f, 0xdc, 0x44,
-	0x14, 0xcf, 0x66, 0xeb, 0x4d, 0x3a, 0xf9, 0x6a, 0x27, 0x29, 0xdd, 0xd2, 0xb2, 0x4e, 0x97, 0x56,
-	0x14, 0x21, 0x65, 0x55, 0x8a, 0x84, 0x40, 0x54, 0x02, 0x27, 0x44, 0xaa, 0x94, 0x8a, 0xf0, 0x02,
-	0x02, 0xc1, 0xc9, 0xb1, 0x27, 0x1b, 0xb7, 0x5e, 0x7b, 0x63, 0x8f, 0xd3, 0x46, 0x42, 0x82, 0x3f,
-	0x81, 0x3b, 0xe2, 0xc0, 0x05, 0x71, 0xe1, 0xc4, 0x01, 0x71, 0xe6, 0xd2, 0x63, 0x8f, 0x3d, 0x59,
-	0x34, 0xbd, 0x20, 0x9f, 0x7a, 0xe6, 0x84, 0xe6, 0xcd, 0xac, 0xbf, 0x77, 0xb3, 0xbd, 0x64, 0xfd,
-	0x7e, 0xef, 0xf7, 0xde, 0x8c, 0x9f, 0xdf, 0x7b, 0xf3, 0x26, 0x64, 0x7d, 0xf8, 0xb0, 0xdf, 0x73,
-	0xfd, 0xfe, 0x91, 0x3b, 0xf0, 0x6d, 0xe6, 0xf6, 0x42, 0x6e, 0xf2, 0x50, 0xfe, 0xdd, 0x18, 0x06,
-	0x3e, 0xf7, 0xa9, 0x86, 0xc2, 0xeb, 0x6b, 0x7d, 0xbf, 0xef, 0x23, 0xd2, 0x13, 0x4f, 0x52, 0xd9,
-	0xfd, 0x75, 0x96, 0xb4, 0x80, 0x85, 0x91, 0xcb, 0xe9, 0x07, 0x64, 0x2e, 0x8c, 0x06, 0x03, 0x33,
-	0x38, 0x69, 0x37, 0xd6, 0x1b, 0xb7, 0x16, 0xde, 0x5d, 0xde, 0x90, 0x6e, 0xf6, 0x24, 0x6a, 0xac,
-	0x3c, 0x89, 0xf5, 0x99, 0x24, 0xd6, 0x47, 0x34, 0x18, 0x3d, 0x08, 0xd3, 0xa3, 0x88, 0x05, 0x0e,
-	0x0b, 0xda, 0xb3, 0x05, 0xd3, 0xcf, 0x25, 0x9a, 0x99, 0x2a, 0x1a, 0x8c, 0x1e, 0xe8, 0x5d, 0x32,
-	0xef, 0x78, 0x7d, 0x16, 0x72, 0x16, 0xb4, 0x9b, 0x68, 0xbb, 0xa2, 0x6c, 0xef, 0x29, 0xd8, 0xb8,
-	0xa0, 0x8c, 0x53, 0x22, 0xa4, 0x4f, 0xf4, 0x3d, 0xd2, 0xb2, 0x4c, 0xeb, 0x90, 0x85, 0xed, 0x73,
-	0x68, 0xbc, 0xa4, 0x8c, 0x37, 0x11, 0x34, 0x96, 0x94, 0xa9, 0x86, 0x24, 0x50, 0x5c, 0x7a, 0x9b,
-	0x68, 0x8e, 0x67, 0xb3, 0xc7, 0x6d, 0x0d, 0x8d, 0x16, 0xd3, 0x15, 0x6d, 0xf6, 0x38, 0xb3, 0x41,
-	0x0a, 0xc8, 0x9f, 0xee, 0x4f, 0xe7, 0x48, 0x6b, 0x33, 0xb5, 0xb6, 0x0e, 0x23, 0xef, 0xa1, 0x0a,
-	0xd3, 0x62, 0x7e, 0xc9, 0xdc, 0x8a, 0x82, 0x02, 0xf2, 0x27, 0x5b, 0x70, 0x76, 0x92, 0x49, 0x7e,
-	0x41, 0xf1, 0x66, 0x01, 0x7e, 0x18, 0x15, 0x96, 0xa2, 0xcd, 0xb2, 0xb2, 0x51, 0x1c, 0x50, 0xbf,
-	0x74, 0x93, 0x2c, 0x20, 0x4d, 0x7e, 0x53, 0x15, 0x94, 0xa2, 0xe9, 0xaa, 0x32, 0xcd, 0x13, 0x21,
-	0x2f, 0xd0, 0x6d, 0xb2, 0x78, 0xec, 0xbb, 0xd1, 0x80, 0x29, 0x2f, 0x5a, 0x8d, 0x97, 0x35, 0xe5,
-	0xa5, 0xc0, 0x84, 0x82, 0x24, 0xfc, 0x84, 0xe2, 0x2b, 0x8f, 0x76, 0xd3, 0x9a, 0xe4, 0x27, 0xcf,
-	0x84, 0x82, 0x24, 0x5e, 0xca, 0x35, 0xf7, 0x99, 0xab, 0xdc, 0xcc, 0x4d, 0x7a, 0xa9, 0x1c, 0x11,
-	0xf2, 0x02, 0xfd, 0x96, 0xac, 0x3a, 0x5e, 0xc8, 0x4d, 0x8f, 0xdf, 0x67, 0x3c, 0x70, 0x2c, 0xe5,
-	0x6c, 0xbe, 0xc6, 0xd9, 0x55, 0xe5, 0xac, 0xce, 0x00, 0xea, 0xc0, 0xee, 0x5f, 0x2d, 0x32, 0xa7,
-	0xca, 0x84, 0x7e, 0x49, 0x2e, 0xef, 0x9f, 0x70, 0x16, 0xee, 0x06, 0xbe, 0xc5, 0xc2, 0x90, 0xd9,
-	0xbb, 0x2c, 0xd8, 0x63, 0x96, 0xef, 0xd9, 0x98, 0x30, 0x4d, 0xe3, 0x6a, 0x12, 0xeb, 0xe3, 0x28,
-	0x30, 0x4e, 0x21, 0xdc, 0xba, 0x8e, 0x57, 0xeb, 0x76, 0x36, 0x73, 0x3b, 0x86, 0x02, 0xe3, 0x14,
-	0xf4, 0x1e, 0x59, 0xe5, 0x3e, 0x37, 0x5d, 0xa3, 0xb0, 0x2c, 0xe6, 0x5c, 0xd3, 0xb8, 0x2c, 0x82,
-	0x50, 0xa3, 0x86, 0x3a, 0x30, 0x75, 0xb5, 0x53, 0x58, 0x0a, 0x73, 0x30, 0xef, 0xaa, 0xa8, 0x86,
-	0x3a, 0x90, 0xde, 0x22, 0xf3, 0xec, 0x31, 0xb3, 0xbe, 0x70, 0x06, 0x0c, 0xb3, 0xaf, 0x61, 0x2c,
-	0x8a, 0x06, 0x30, 0xc2, 0x20, 0x7d, 0xa2, 0xef, 0x90, 0xf3, 0x47, 0x11, 0x8b, 0x18, 0x52, 0x5b,
-	0x48, 0x5d, 0x4a, 0x62, 0x3d, 0x03, 0x21, 0x7b, 0xa4, 0x1b, 0x84, 0x84, 0xd1, 0xbe, 0x6c, 0x3d,
-	0x21, 0xe6, 0x51, 0xd3, 0x58, 0x4e, 0x62, 0x3d, 0x87, 0x42, 0xee, 0x99, 0xee, 0x90, 0x35, 0xdc,
-	0xdd, 0xa7, 0x1e, 0x97, 0xe9, 0xc8, 0xa3, 0xc0, 0x63, 0x36, 0x26, 0x4d, 0xd3, 0x68, 0x27, 0xb1,
-	0x5e, 0xab, 0x87, 0x5a, 0x94, 0x76, 0x49, 0x2b, 0x1c, 0xba, 0x0e, 0x0f, 0xdb, 0xe7, 0xd1, 0x9e,
-	0x88, 0xfa, 0x95, 0x08, 0xa8, 0x5f, 0xe4, 0x1c, 0x9a, 0x81, 0x1d, 0xb6, 0x49, 0x8e, 0x83, 0x08,
-	0xa8, 0xdf, 0x74, 0x57, 0xbb, 0x7e, 0xc8, 0xb7, 0x1d, 0x97, 0xb3, 0x00, 0xa3, 0xd7, 0x5e, 0x28,
-	0xed, 0xaa, 0xa4, 0x87, 0x5a, 0x94, 0x7e, 0x4f, 0x6e, 0x22, 0xbe, 0xc7, 0x83, 0xc8, 0xe2, 0x51,
-	0xc0, 0xec, 0xfb, 0x8c, 0x9b, 0xb6, 0xc9, 0xcd, 0x52, 0x4a, 0x2c, 0xa2, 0xfb, 0xb7, 0x93, 0x58,
-	0x9f, 0xce, 0x00, 0xa6, 0xa3, 0x75, 0xff, 0x6b, 0x10, 0x0d, 0x3b, 0x2f, 0xbd, 0x4d, 0x16, 0xd0,
-	0x64, 0x53, 0xf4, 0xcc, 0x50, 0x55, 0xcb, 0x8a, 0xa8, 0xea, 0x1c, 0x0c, 0x79, 0x81, 0x7e, 0x4c,
-	0x2e, 0x0c, 0xd3, 0x17, 0x52, 0x76, 0xb2, 0x1c, 0xd6, 0x92, 0x58, 0xaf, 0xe8, 0xa0, 0x82, 0xd0,
-	0x0f, 0xc9, 0xb2, 0x8c, 0xeb, 0x56, 0x14, 0x98, 0xdc, 0xf1, 0x3d, 0x95, 0xfb, 0x34, 0x89, 0xf5,
-	0x92, 0x06, 0x4a, 0xb2, 0x58, 0x3d, 0x0a, 0x99, 0x6d, 0xb8, 0xbe, 0x3f, 0x90, 0x4e, 0xe5, 0x39,
-	0x34, 0x2f, 0x57, 0x2f, 0xeb, 0xa0, 0x82, 0x74, 0x3f, 0x22, 0x73, 0xea, 0x8c, 0x14, 0x67, 0x44,
-	0xc8, 0xfd, 0x80, 0x95, 0x8e, 0x95, 0x3d, 0x81, 0x65, 0x67, 0x04, 0x52, 0x40, 0xfe, 0x74, 0x7f,
-	0x9f, 0x25, 0xf3, 0xf7, 0xb2, 0xa3, 0x70, 0x11, 0x23, 0x03, 0x4c, 0x34, 0x31, 0xd9, 0x6c, 0x34,
-	0xe3, 0x82, 0xe8, 0xad, 0x79, 0x1c, 0x0a, 0x12, 0xdd, 0x26, 0x34, 0x17, 0xcf, 0xfb, 0x26, 0x47,
-	0x5b, 0x19, 0xc2, 0xd7, 0x92, 0x58, 0xaf, 0xd1, 0x42, 0x0d, 0x96, 0xae, 0x6e, 0xa0, 0x1c, 0xaa,
-	0x20, 0x66, 0xab, 0x2b, 0x1c, 0x0a, 0x92, 0x08, 0x7e, 0x56, 0xfe, 0x7b, 0xcc, 0xe3, 0xaa, 0x5b,
-	0x60, 0xf0, 0x8b, 0x1a, 0x28, 0xc9, 0x59, 0xbc, 0xb4, 0xa9, 0xe3, 0xf5, 0xa7, 0x46, 0x34, 0xd4,
-	0xa7, 0x0b, 0xab, 0xb4, 0x60, 0x07, 0x2a, 0xdb, 0xb2, 0x85, 0x53, 0x0d, 0x94, 0x64, 0xfa, 0x19,
-	0xb9, 0x94, 0x43, 0xb6, 0xfc, 0x47, 0x9e, 0xeb, 0x9b, 0x76, 0x1a, 0xb5, 0x2b, 0x49, 0xac, 0xd7,
-	0x13, 0xa0, 0x1e, 0x16, 0xdf, 0xc0, 0x2a, 0x60, 0xd8, 0xcc, 0x9a, 0xd9, 0x37, 0xa8, 0x6a, 0xa1,
-	0x06, 0xa3, 0x16, 0xb9, 0x22, 0x3a, 0xd7, 0x09, 0xb0, 0x03, 0x16, 0x30, 0xcf, 0x62, 0x76, 0x56,
-	0x7c, 0xed, 0x25, 0xcc, 0xcb, 0x9b, 0x49, 0xac, 0x5f, 0x1f, 0x4b, 0x1a, 0x55, 0x28, 0x8c, 0xf7,
-	0x93, 0x4d, 0x3f, 0xa5, 0xd9, 0x42, 0x60, 0x63, 0xa6, 0x9f, 0xd1, 0xfb, 0x01, 0x3b, 0x08, 0xb7,
-	0x19, 0xb7, 0x0e, 0xd3, 0xbe, 0x9e, 0x7f, 0xbf, 0x82, 0x16, 0x6a, 0x30, 0xfa, 0x35, 0x69, 0x5b,
-	0x3e, 0xa6, 0xbb, 0xe3, 0x7b, 0x9b, 0xbe, 0xc7, 0x03, 0xdf, 0xdd, 0x31, 0x39, 0xf3, 0xac, 0x13,
-	0x6c, 0xfd, 0x4d, 0xe3, 0x5a, 0x12, 0xeb, 0x63, 0x39, 0x30, 0x56, 0x43, 0x6d, 0x72, 0x6d, 0xe8,
-	0x0c, 0x99, 0x38, 0x24, 0xbf, 0x0a, 0xcc, 0xe1, 0x90, 0x05, 0xb2, 0x40, 0x99, 0x2d, 0x5b, 0xab,
-	0x3c, 0x2a, 0xd6, 0x93, 0x58, 0x9f, 0xc8, 0x83, 0x89, 0x5a, 0x31, 0x26, 0x8b, 0xe8, 0xfa, 0xfb,
-	0x0f, 0xd4, 0xd8, 0x31, 0x1a, 0x93, 0xb7, 0x24, 0x9a, 0x8d, 0xc9, 0x8a, 0x06, 0xa3, 0x87, 0xee,
-	0x2f, 0xf3, 0x64, 0x4e, 0xb1, 0x70, 0xb3, 0x01, 0xdb, 0x0d, 0x98, 0xed, 0x58, 0x26, 0x67, 0x5b,
-	0xcc, 0xf2, 0x07, 0xc3, 0x40, 0xf6, 0x5c, 0xff, 0xd1, 0xa8, 0x6f, 0xca, 0xcd, 0x4e, 0xe0, 0xc1,
-	0x44, 0x2d, 0xed, 0x93, 0x37, 0xc6, 0xe9, 0xb1, 0x81, 0xab, 0x6c, 0xbf, 0x9e, 0xc4, 0xfa, 0x64,
-	0x22, 0x4c, 0x56, 0xd3, 0x9f, 0x1b, 0xa4, 0x37, 0x8e, 0x31, 0xe6, 0xf0, 0x50, 0xb5, 0x71, 0x27,
-	0x89, 0xf5, 0x57, 0x35, 0x85, 0x57, 0x35, 0xa0, 0x9b, 0xe4, 0xa2, 0x38, 0x34, 0x52, 0x1b, 0x8c,
-	0xb1, 0x6c, 0x53, 0x97, 0x92, 0x58, 0xaf, 0x2a, 0xa1, 0x0a, 0xd1, 0x07, 0xa4, 0x53, 0x00, 0xab,
-	0xe1, 0x94, 0xe5, 0xd0, 0x4d, 0x62, 0xfd, 0x0c, 0x26, 0x9c, 0xa1, 0xa7, 0xdf, 0x91, 0x1b, 0x05,
-	0xc6, 0xb8, 0x20, 0xca, 0x92, 0xb9, 0x95, 0xc4, 0xfa, 0x54, 0x7c, 0x98, 0x8a, 0x25, 0x3a, 0x6b,
-	0x76, 0xc6, 0x62, 0xac, 0xe6, 0xb2, 0xce, 0x5a, 0xd4, 0x40, 0x49, 0x16, 0x87, 0xc8, 0xd0, 0xec,
-	0xb3, 0x70, 0xcf, 0x32, 0xbd, 0x6c, 0xce, 0xc2, 0x43, 0x24, 0x8f, 0x43, 0x41, 0xa2, 0x77, 0xc9,
-	0x0a, 0xca, 0xb9, 0x4e, 0x2c, 0x07, 0xac, 0xd5, 0x24, 0xd6, 0xcb, 0x2a, 0x28, 0x03, 0x62, 0x9c,
-	0x2a, 0x41, 0x32, 0x3c, 0x24, 0x1b, 0xa7, 0xea, 0xf4, 0x50, 0x8b, 0x8a, 0x19, 0x46, 0xe0, 0xa3,
-	0x63, 0x70, 0x21, 0x9b, 0x61, 0x72, 0x30, 0xe4, 0x85, 0xf4, 0x08, 0x16, 0x21, 0xf8, 0xe4, 0xd8,
-	0x74, 0x5c, 0x73, 0xdf, 0x65, 0x6a, 0xdc, 0xca, 0x8e, 0xe0, 0x82, 0x16, 0x6a, 0xb0, 0xee, 0x1f,
-	0x1a, 0xd1, 0xb0, 0x0d, 0x8b, 0x6f, 0x70, 0xc8, 0x4c, 0x5b, 0xf6, 0x64, 0x7c, 0x99, 0xdc, 0xb1,
-	0x5a, 0xd4, 0x40, 0x49, 0x2e, 0xd8, 0xca, 0xe6, 0xa7, 0xd5, 0xd8, 0xca, 0x76, 0x57, 0x92, 0x45,
-	0xa9, 0xd8, 0x95, 0xc4, 0x6e, 0x65, 0xa5, 0x52, 0x51, 0x42, 0x15, 0x2a, 0x3b, 0xc9, 0x37, 0xe0,
-	0x8a, 0x13, 0xb9, 0x8d, 0x2a, 0x24, 0x72, 0xa2, 0xbc, 0x8f, 0xf9, 0x2c, 0x27, 0xca, 0xbb, 0x28,
-	0x03, 0xc2, 0x1c, 0x03, 0xbc, 0x15, 0x0d, 0x5d, 0xcc, 0xf6, 0x30, 0x9f, 0x52, 0x25, 0x15, 0x94,
-	0x01, 0xcc, 0xc8, 0xd2, 0x70, 0x4e, 0x72, 0x19, 0x59, 0x9a, 0xcb, 0xcb, 0x00, 0x1d, 0x92, 0xf5,
-	0x34, 0xb0, 0xe3, 0x8a, 0x57, 0x26, 0xd6, 0x8d, 0x24, 0xd6, 0xcf, 0xe4, 0xc2, 0x99, 0x0c, 0x7a,
-	0x42, 0xde, 0xb4, 0xa7, 0x68, 0xbb, 0x32, 0x27, 0xdf, 0x4a, 0x62, 0x7d, 0x1a, 0x3a, 0x4c, 0x43,
-	0xea, 0xfe, 0xdd, 0x24, 0x1a, 0x5e, 0xbb, 0x45, 0xf5, 0x33, 0x79, 0x65, 0xda, 0xf6, 0x23, 0xaf,
-	0x30, 0xc0, 0xe6, 0x71, 0x28, 0x48, 0x62, 0x06, 0x67, 0xa3, 0x8b, 0xd6, 0x51, 0x24, 0x46, 0x61,
-	0x39, 0x88, 0x69, 0x72, 0x06, 0x2f, 0xeb, 0xa0, 0x82, 0xd0, 0xf7, 0xc9, 0x92, 0xc2, 0x70, 0x36,
-	0x94, 0x97, 0x5f, 0xcd, 0xb8, 0x98, 0xc4, 0x7a, 0x51, 0x01, 0x45, 0x51, 0x18, 0xe2, 0x6d, 0x1d,
-	0x98, 0xc5, 0x9c, 0xe3, 0xf4, 0xaa, 0x8b, 0x86, 0x05, 0x05, 0x14, 0x45, 0x71, 0x69, 0x45, 0x00,
-	0x27, 0x5e, 0x59, 0x5e, 0x78, 0x69, 0x4d, 0x41, 0xc8, 0x1e, 0xc5, 0x5d, 0x38, 0x90, 0x7b, 0x95,
-	0xb5, 0xa4, 0xc9, 0xbb, 0xf0, 0x08, 0x83, 0xf4, 0x49, 0x04, 0xd0, 0xce, 0x4f, 0x90, 0x73, 0x59,
-	0xfb, 0xcc, 0xe3, 0x50, 0x90, 0x44, 0xbd, 0xe1, 0xb4, 0xb7, 0xc3, 0xbc, 0x3e, 0x3f, 0xdc, 0x63,
-	0xc1, 0x71, 0xda, 0x79, 0xb1, 0xde, 0x2a, 0x4a, 0xa8, 0x42, 0x06, 0x7b, 0xfa, 0xbc, 0x33, 0xf3,
-	0xec, 0x79, 0x67, 0xe6, 0xe5, 0xf3, 0x4e, 0xe3, 0x87, 0xd3, 0x4e, 0xe3, 0xb7, 0xd3, 0x4e, 0xe3,
-	0xc9, 0x69, 0xa7, 0xf1, 0xf4, 0xb4, 0xd3, 0xf8, 0xe7, 0xb4, 0xd3, 0xf8, 0xf7, 0xb4, 0x33, 0xf3,
-	0xf2, 0xb4, 0xd3, 0xf8, 0xf1, 0x45, 0x67, 0xe6, 0xe9, 0x8b, 0xce, 0xcc, 0xb3, 0x17, 0x9d, 0x99,
-	0x6f, 0x7a, 0x7d, 0x87, 0x1f, 0x46, 0xfb, 0x1b, 0x96, 0x3f, 0xe8, 0xf5, 0x03, 0xf3, 0xc0, 0xf4,
-	0xcc, 0x9e, 0xeb, 0x3f, 0x74, 0x7a, 0xc7, 0x77, 0x7a, 0x75, 0xff, 0xd7, 0xdc, 0x6f, 0xe1, 0x7f,
-	0x2d, 0xef, 0xfc, 0x1f, 0x00, 0x00, 0xff, 0xff, 0x3b, 0x4c, 0x10, 0xe6, 0xf6, 0x14, 0x00, 0x00,
+	// 1659 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x58, 0xbd, 0x6f, 0xdc, 0xc6,
+	0x12, 0xd7, 0xe9, 0xc4, 0xd3, 0x79, 0xf5, 0x65, 0xaf, 0xe4, 0x67, 0xfa, 0xd9, 0xef, 0x28, 0xdf,
+	0xb3, 0xf1, 0x64, 0xbc, 0x40, 0x07, 0xc7, 0x06, 0x82, 0x04, 0x31, 0x90, 0x50, 0x8a, 0x00, 0x03,
+	0x32, 0xa2, 0x8c, 0x12, 0x24, 0x48, 0x2a, 0x8a, 0x5c, 0x9d, 0x68, 0xf3, 0xc8, 0x13, 0xb9, 0x94,
+	0x2d, 0x20, 0x40, 0xf2, 0x27, 0xa4, 0x0f, 0x52, 0xa4, 0x4b, 0x93, 0x2a, 0x45, 0x90, 0x3a, 0x8d,
+	0x4b, 0xa7, 0x73, 0x45, 0xc4, 0x72, 0x13, 0xb0, 0x72, 0x9d, 0x2a, 0xd8, 0x8f, 0x23, 0xb9, 0x24,
+	0xef, 0x74, 0x6e, 0x44, 0xce, 0x6f, 0x7e, 0x33, 0xbb, 0x9c, 0x9b, 0x99, 0x9d, 0x15, 0x5a, 0x1f,
+	0x3e, 0xee, 0xf7, 0xbc, 0xa0, 0x7f, 0xec, 0x0d, 0x02, 0x87, 0x78, 0xbd, 0x88, 0x5a, 0x34, 0x12,
+	0x7f, 0x37, 0x87, 0x61, 0x40, 0x03, 0xac, 0x71, 0xe1, 0xdf, 0x6b, 0xfd, 0xa0, 0x1f, 0x70, 0xa4,
+	0xc7, 0xde, 0x84, 0xb2, 0xfb, 0xc7, 0x2c, 0x6a, 0x01, 0x89, 0x62, 0x8f, 0xe2, 0x77, 0xd1, 0x7c,
+	0x14, 0x0f, 0x06, 0x56, 0x78, 0xaa, 0x37, 0xd6, 0x1b, 0x1b, 0x0b, 0x6f, 0x2f, 0x6f, 0x0a, 0x37,
+	0xfb, 0x02, 0x35, 0x57, 0x9e, 0x25, 0xc6, 0x4c, 0x9a, 0x18, 0x23, 0x1a, 0x8c, 0x5e, 0x98, 0xe9,
+	0x71, 0x4c, 0x42, 0x97, 0x84, 0xfa, 0xac, 0x62, 0xfa, 0x89, 0x40, 0x73, 0x53, 0x49, 0x83, 0xd1,
+	0x0b, 0xbe, 0x8f, 0xda, 0xae, 0xdf, 0x27, 0x11, 0x25, 0xa1, 0xde, 0xe4, 0xb6, 0x2b, 0xd2, 0xf6,
+	0x81, 0x84, 0xcd, 0x8b, 0xd2, 0x38, 0x23, 0x42, 0xf6, 0x86, 0xef, 0xa1, 0x96, 0x6d, 0xd9, 0x47,
+	0x24, 0xd2, 0xe7, 0xb8, 0xf1, 0x92, 0x34, 0xde, 0xe2, 0xa0, 0xb9, 0x24, 0x4d, 0x35, 0x4e, 0x02,
+	0xc9, 0xc5, 0x77, 0x90, 0xe6, 0xfa, 0x0e, 0x79, 0xaa, 0x6b, 0xdc, 0x68, 0x31, 0x5b, 0xd1, 0x21,
+	0x4f, 0x73, 0x1b, 0x4e, 0x01, 0xf1, 0xc0, 0xb7, 0x91, 0x36, 0xf4, 0x2c, 0x3f, 0xd2, 0x5b, 0xeb,
+	0xcd, 0x8d, 0x0b, 0xe6, 0x6a, 0x9a, 0x18, 0x2b, 0x1c, 0x78, 0x2b, 0x18, 0xb8, 0x94, 0x0c, 0x86,
+	0xf4, 0x14, 0x04, 0xa3, 0xfb, 0xfd, 0x1c, 0x6a, 0x6d, 0x65, 0x0b, 0xd9, 0x47, 0xb1, 0xff, 0x58,
+	0x6f, 0x28, 0x0b, 0x71, 0x6d, 0x61, 0x73, 0x8c, 0x02, 0xe2, 0x91, 0xef, 0x6d, 0x76, 0x92, 0x89,
+	0xb2, 0xb7, 0x7b, 0xa8, 0x15, 0xf2, 0xdf, 0x50, 0x6f, 0xd6, 0xd8, 0x2c, 0x4b, 0x1b, 0xc9, 0x01,
+	0xf9, 0xc4, 0x5b, 0x68, 0x81, 0xd3, 0xc4, 0xcf, 0xaf, 0xcf, 0xd5, 0x98, 0xae, 0x4a, 0xd3, 0x22,
+	0x11, 0x8a, 0x02, 0xde, 0x41, 0x8b, 0x27, 0x81, 0x17, 0x0f, 0x88, 0xf4, 0xa2, 0xd5, 0x78, 0x59,
+	0x93, 0x5e, 0x14, 0x26, 0x28, 0x12, 0xf3, 0x13, 0xb1, 0x84, 0x18, 0xed, 0xa6, 0x35, 0xc9, 0x4f,
+	0x91, 0x09, 0x8a, 0xc4, 0x3e, 0xca, 0xb3, 0x0e, 0x88, 0x27, 0xdd, 0xcc, 0x4f, 0xfa, 0xa8, 0x02,
+	0x11, 0x8a, 0x02, 0xfe, 0x0a, 0xad, 0xba, 0x7e, 0x44, 0x2d, 0x9f, 0x3e, 0x24, 0x34, 0x74, 0x6d,
+	0xe9, 0xac, 0x5d, 0xe3, 0xec, 0x9a, 0x74, 0x56, 0x67, 0x00, 0x75, 0x60, 0xf7, 0xb7, 0x16, 0x9a,
+	0x97, 0x15, 0x85, 0x3f, 0x43, 0x57, 0x0e, 0x4e, 0x29, 0x89, 0xf6, 0xc2, 0xc0, 0x26, 0x51, 0x44,
+	0x9c, 0x3d, 0x12, 0xee, 0x13, 0x3b, 0xf0, 0x1d, 0x9e, 0x30, 0x4d, 0xf3, 0x5a, 0x9a, 0x18, 0xe3,
+	0x28, 0x30, 0x4e, 0xc1, 0xdc, 0x7a, 0xae, 0x5f, 0xeb, 0x76, 0x36, 0x77, 0x3b, 0x86, 0x02, 0xe3,
+	0x14, 0xf8, 0x01, 0x5a, 0xa5, 0x01, 0xb5, 0x3c, 0x53, 0x59, 0x96, 0xe7, 0x5c, 0xd3, 0xbc, 0xc2,
+	0x82, 0x50, 0xa3, 0x86, 0x3a, 0x30, 0x73, 0xb5, 0xab, 0x2c, 0xa5, 0xcf, 0x95, 0x5c, 0xa9, 0x6a,
+	0xa8, 0x03, 0xf1, 0x06, 0x6a, 0x93, 0xa7, 0xc4, 0xfe, 0xd4, 0x1d, 0x10, 0x9e, 0x7d, 0x0d, 0x73,
+	0x91, 0xf5, 0x8a, 0x11, 0x06, 0xd9, 0x1b, 0xfe, 0x3f, 0xba, 0x70, 0x1c, 0x93, 0x98, 0x70, 0x6a,
+	0x8b, 0x53, 0x97, 0xd2, 0xc4, 0xc8, 0x41, 0xc8, 0x5f, 0xf1, 0x26, 0x42, 0x51, 0x7c, 0x20, 0xba,
+	0x54, 0xc4, 0xf3, 0xa8, 0x69, 0x2e, 0xa7, 0x89, 0x51, 0x40, 0xa1, 0xf0, 0x8e, 0x77, 0xd1, 0x1a,
+	0xdf, 0xdd, 0x47, 0x3e, 0xe5, 0x3a, 0x42, 0xe3, 0xd0, 0x27, 0x0e, 0x4f, 0x9a, 0xa6, 0xa9, 0xa7,
+	0x89, 0x51, 0xab, 0x87, 0x5a, 0x14, 0x77, 0x51, 0x2b, 0x1a, 0x7a, 0x2e, 0x8d, 0xf4, 0x0b, 0xdc,
+	0x1e, 0xb1, 0xfa, 0x15, 0x08, 0xc8, 0x27, 0xe7, 0x1c, 0x59, 0xa1, 0x13, 0xe9, 0xa8, 0xc0, 0xe1,
+	0x08, 0xc8, 0x67, 0xb6, 0xab, 0xbd, 0x20, 0xa2, 0x3b, 0xae, 0x47, 0x49, 0xc8, 0xa3, 0xa7, 0x2f,
+	0x94, 0x76, 0x55, 0xd2, 0x43, 0x2d, 0x8a, 0xbf, 0x41, 0xb7, 0x38, 0xbe, 0x4f, 0xc3, 0xd8, 0xa6,
+	0x71, 0x48, 0x9c, 0x87, 0x84, 0x5a, 0x8e, 0x45, 0xad, 0x52, 0x4a, 0x2c, 0x72, 0xf7, 0xb7, 0xd3,
+	0xc4, 0x98, 0xce, 0x00, 0xa6, 0xa3, 0x75, 0xff, 0x6e, 0x20, 0x8d, 0x37, 0x69, 0x7c, 0x07, 0x2d,
+	0x70, 0x93, 0x2d, 0xd6, 0x33, 0x23, 0x59, 0x2d, 0x2b, 0xac, 0xaa, 0x0b, 0x30, 0x14, 0x05, 0xfc,
+	0x01, 0xba, 0x38, 0xcc, 0x3e, 0x48, 0xda, 0x89, 0x72, 0x58, 0x4b, 0x13, 0xa3, 0xa2, 0x83, 0x0a,
+	0x82, 0xdf, 0x43, 0xcb, 0x22, 0xae, 0xdb, 0x71, 0x68, 0x51, 0x37, 0xf0, 0x65, 0xee, 0xe3, 0x34,
+	0x31, 0x4a, 0x1a, 0x28, 0xc9, 0x6c, 0xf5, 0x38, 0x22, 0x8e, 0xe9, 0x05, 0xc1, 0x40, 0x38, 0x15,
+	0x47, 0x56, 0x5b, 0xac, 0x5e, 0xd6, 0x41, 0x05, 0xe9, 0xbe, 0x8f, 0xe6, 0xe5, 0x71, 0xca, 0xce,
+	0x88, 0x88, 0x06, 0x21, 0x29, 0x1d, 0x2b, 0xfb, 0x0c, 0xcb, 0xcf, 0x08, 0x4e, 0x01, 0xf1, 0xe8,
+	0xfe, 0x3c, 0x8b, 0xda, 0x0f, 0xf2, 0x53, 0x73, 0x91, 0x47, 0x06, 0x08, 0x6b, 0x62, 0xa2, 0xd9,
+	0x68, 0xe6, 0x45, 0xd6, 0x5b, 0x8b, 0x38, 0x28, 0x12, 0xde, 0x41, 0xb8, 0x10, 0xcf, 0x87, 0x16,
+	0xe5, 0xb6, 0x22, 0x84, 0xff, 0x4a, 0x13, 0xa3, 0x46, 0x0b, 0x35, 0x58, 0xb6, 0xba, 0xc9, 0xe5,
+	0x48, 0x06, 0x31, 0x5f, 0x5d, 0xe2, 0xa0, 0x48, 0x2c, 0xf8, 0x79, 0xf9, 0xef, 0x13, 0x9f, 0xea,
+	0x73, 0x79, 0xf0, 0x55, 0x0d, 0x94, 0xe4, 0x3c, 0x5e, 0xda, 0xd4, 0xf1, 0xfa, 0x55, 0x43, 0x1a,
+	0xd7, 0x67, 0x0b, 0xcb, 0xb4, 0x20, 0x87, 0x7a, 0xa3, 0xb4, 0x70, 0xa6, 0x81, 0x92, 0x8c, 0x3f,
+	0x46, 0x97, 0x0b, 0xc8, 0x76, 0xf0, 0xc4, 0xf7, 0x02, 0xcb, 0xc9, 0xa2, 0x76, 0x35, 0x4d, 0x8c,
+	0x7a, 0x02, 0xd4, 0xc3, 0xec, 0x37, 0xb0, 0x15, 0x8c, 0x37, 0xb3, 0x66, 0xfe, 0x1b, 0x54, 0xb5,
+	0x50, 0x83, 0x61, 0x1b, 0x5d, 0x65, 0x9d, 0xeb, 0x14, 0xc8, 0x21, 0x09, 0x89, 0x6f, 0x13, 0x27,
+	0x2f, 0x3e, 0x7d, 0x89, 0xe7, 0xe5, 0xad, 0x34, 0x31, 0x6e, 0x8c, 0x25, 0x8d, 0x2a, 0x14, 0xc6,
+	0xfb, 0xc9, 0xa7, 0x9f, 0xd2, 0x6c, 0xc1, 0xb0, 0x31, 0xd3, 0xcf, 0xe8, 0xfb, 0x80, 0x1c, 0x46,
+	0x3b, 0x84, 0xda, 0x47, 0x59, 0x5f, 0x2f, 0x7e, 0x9f, 0xa2, 0x85, 0x1a, 0x0c, 0x7f, 0x81, 0x74,
+	0x3b, 0xe0, 0xe9, 0xee, 0x06, 0xfe, 0x56, 0xe0, 0xd3, 0x30, 0xf0, 0x76, 0x2d, 0x4a, 0x7c, 0xfb,
+	0x94, 0xb7, 0xfe, 0xa6, 0x79, 0x3d, 0x4d, 0x8c, 0xb1, 0x1c, 0x18, 0xab, 0xc1, 0x0e, 0xba, 0x3e,
+	0x74, 0x87, 0x84, 0x1d, 0x92, 0x9f, 0x87, 0xd6, 0x70, 0x48, 0x42, 0x51, 0xa0, 0xc4, 0x11, 0xad,
+	0x55, 0x1c, 0x15, 0xeb, 0x69, 0x62, 0x4c, 0xe4, 0xc1, 0x44, 0x2d, 0x9b, 0xa8, 0x59, 0x74, 0x83,
+	0x83, 0x47, 0x7a, 0x5b, 0x99, 0xa8, 0xb7, 0x05, 0x9a, 0x4f, 0xd4, 0x92, 0x06, 0xa3, 0x97, 0xee,
+	0x8f, 0x6d, 0x34, 0x2f, 0x59, 0x7c, 0xb3, 0x21, 0xd9, 0x0b, 0x89, 0xe3, 0xda, 0x16, 0x25, 0xdb,
+	0xc4, 0x0e, 0x06, 0xc3, 0x50, 0xf4, 0xdc, 0xe0, 0xc9, 0xa8, 0x6f, 0x8a, 0xcd, 0x4e, 0xe0, 0xc1,
+	0x44, 0x2d, 0xee, 0xa3, 0xff, 0x8c, 0xd3, 0xf3, 0x06, 0x2e, 0xb3, 0xfd, 0x46, 0x9a, 0x18, 0x93,
+	0x89, 0x30, 0x59, 0x8d, 0x7f, 0x68, 0xa0, 0xde, 0x38, 0xc6, 0x98, 0xc3, 0x43, 0xd6, 0xc6, 0xdd,
+	0x34, 0x31, 0xde, 0xd4, 0x14, 0xde, 0xd4, 0x00, 0x6f, 0xa1, 0x4b, 0xec, 0xd0, 0xc8, 0x6c, 0x78,
+	0x8c, 0x45, 0x9b, 0xba, 0x9c, 0x26, 0x46, 0x55, 0x09, 0x55, 0x08, 0x3f, 0x42, 0x1d, 0x05, 0xac,
+	0x86, 0x53, 0x94, 0x43, 0x37, 0x4d, 0x8c, 0x73, 0x98, 0x70, 0x8e, 0x1e, 0x7f, 0x8d, 0x6e, 0x2a,
+	0x8c, 0x71, 0x41, 0x14, 0x25, 0xb3, 0x91, 0x26, 0xc6, 0x54, 0x7c, 0x98, 0x8a, 0xc5, 0x3a, 0x6b,
+	0x7e, 0xc6, 0xf2, 0x58, 0xcd, 0xe7, 0x9d, 0x55, 0xd5, 0x40, 0x49, 0x66, 0x87, 0xc8, 0xd0, 0xea,
+	0x93, 0x68, 0xdf, 0xb6, 0xfc, 0x7c, 0xce, 0xe2, 0x87, 0x48, 0x11, 0x07, 0x45, 0xc2, 0xf7, 0xd1,
+	0x0a, 0x97, 0x0b, 0x9d, 0x58, 0x0c, 0x58, 0xe2, 0x3e, 0xa7, 0xaa, 0xa0, 0x0c, 0xb0, 0x71, 0xaa,
+	0x04, 0x89, 0xf0, 0xa0, 0x7c, 0x9c, 0xaa, 0xd3, 0x43, 0x2d, 0xca, 0x66, 0x18, 0x86, 0x8f, 0x8e,
+	0xc1, 0x85, 0x7c, 0x86, 0x29, 0xc0, 0x50, 0x14, 0xb2, 0x23, 0x98, 0x85, 0xe0, 0xc3, 0x13, 0xcb,
+	0xf5, 0xac, 0x03, 0x8f, 0xe8, 0x8b, 0x79, 0x7b, 0xac, 0x6a, 0xa1, 0x06, 0xeb, 0xfe, 0xa2, 0x21,
+	0x8d, 0xb7, 0x61, 0xf6, 0x1b, 0x1c, 0x11, 0xcb, 0xe1, 0x82, 0xf8, 0x98, 0xc2, 0xb1, 0xaa, 0x6a,
+	0xa0, 0x24, 0x2b, 0xb6, 0xa2, 0xf9, 0x69, 0x35, 0xb6, 0x5c, 0x03, 0x25, 0x99, 0x95, 0x8a, 0x53,
+	0x49, 0xec, 0x56, 0x5e, 0x2a, 0x15, 0x25, 0x54, 0xa1, 0xb2, 0x93, 0x62, 0x03, 0xae, 0x38, 0x11,
+	0xdb, 0xa8, 0x42, 0x2c, 0x27, 0xca, 0xfb, 0x68, 0xe7, 0x39, 0x51, 0xde, 0x45, 0x19, 0x60, 0xe6,
+	0x3c, 0xc0, 0xdb, 0xf1, 0xd0, 0xe3, 0xd9, 0x1e, 0x15, 0x53, 0xaa, 0xa4, 0x82, 0x32, 0xc0, 0x33,
+	0xb2, 0x34, 0x9c, 0xa3, 0x42, 0x46, 0xaa, 0x2a, 0x28, 0x03, 0x78, 0x88, 0xd6, 0xb3, 0xc0, 0x8e,
+	0x2b, 0x5e, 0x91, 0x58, 0x37, 0xd3, 0xc4, 0x38, 0x97, 0x0b, 0xe7, 0x32, 0xf0, 0x29, 0xfa, 0xaf,
+	0x33, 0x45, 0xdb, 0x15, 0x39, 0xf9, 0xbf, 0x34, 0x31, 0xa6, 0xa1, 0xc3, 0x34, 0xa4, 0xee, 0xef,
+	0x4d, 0xa4, 0xf1, 0x6b, 0x37, 0xab, 0x7e, 0x22, 0xae, 0x4c, 0x3b, 0x41, 0xec, 0x2b, 0x03, 0x6c,
+	0x11, 0x07, 0x45, 0x62, 0x33, 0x38, 0x19, 0x5d, 0xb4, 0x8e, 0x63, 0x12, 0x51, 0x39, 0x88, 0x69,
+	0x62, 0x06, 0x2f, 0xeb, 0xa0, 0x82, 0xe0, 0x77, 0xd0, 0x92, 0xc4, 0xf8, 0x6c, 0x28, 0x2e, 0xbf,
+	0x9a, 0x79, 0x29, 0x4d, 0x0c, 0x55, 0x01, 0xaa, 0xc8, 0x0c, 0xf9, 0x6d, 0x1d, 0x88, 0x4d, 0xdc,
+	0x93, 0xec, 0xaa, 0xcb, 0x0d, 0x15, 0x05, 0xa8, 0x22, 0xbb, 0xb4, 0x72, 0x80, 0x4f, 0xbc, 0xa2,
+	0xbc, 0xf8, 0xa5, 0x35, 0x03, 0x21, 0x7f, 0x65, 0x77, 0xe1, 0x50, 0xec, 0x55, 0xd4, 0x92, 0x26,
+	0xee, 0xc2, 0x23, 0x0c, 0xb2, 0x37, 0x16, 0x40, 0xa7, 0x38, 0x41, 0xce, 0xe7, 0xed, 0xb3, 0x88,
+	0x83, 0x22, 0xb1, 0x7a, 0xe3, 0xd3, 0xde, 0x2e, 0xf1, 0xfb, 0xf4, 0x68, 0x9f, 0x84, 0x27, 0x59,
+	0xe7, 0xe5, 0xf5, 0x56, 0x51, 0x42, 0x15, 0x32, 0xc9, 0xf3, 0x97, 0x9d, 0x99, 0x17, 0x2f, 0x3b,
+	0x33, 0xaf, 0x5f, 0x76, 0x1a, 0xdf, 0x9e, 0x75, 0x1a, 0x3f, 0x9d, 0x75, 0x1a, 0xcf, 0xce, 0x3a,
+	0x8d, 0xe7, 0x67, 0x9d, 0xc6, 0x9f, 0x67, 0x9d, 0xc6, 0x5f, 0x67, 0x9d, 0x99, 0xd7, 0x67, 0x9d,
+	0xc6, 0x77, 0xaf, 0x3a, 0x33, 0xcf, 0x5f, 0x75, 0x66, 0x5e, 0xbc, 0xea, 0xcc, 0x7c, 0xd9, 0xeb,
+	0xbb, 0xf4, 0x28, 0x3e, 0xd8, 0xb4, 0x83, 0x41, 0xaf, 0x1f, 0x5a, 0x87, 0x96, 0x6f, 0xf5, 0xbc,
+	0xe0, 0xb1, 0xdb, 0x3b, 0xb9, 0xdb, 0xab, 0xfb, 0x17, 0xe8, 0x41, 0x8b, 0xff, 0x83, 0xf3, 0xee,
+	0x3f, 0x03, 0x00, 0x63, 0xc4, 0x5d, 0x0f, 0x21, 0x15, 0x00, 0x00,
 }
 
 func (this *Result) Equal(that interface{}) bool {
@@ -1173,6 +1183,14 @@ func (this *Result) Equal(that interface{}) bool {
 	if !this.Index.Equal(&that1.Index) {
 		return false
 	}
+	if len(this.Plans) != len(that1.Plans) {
+		return false
+	}
+	for i := range this.Plans {
+		if this.Plans[i] != that1.Plans[i] {
+			return false
+		}
+	}
 	return true
 }
 func (this *Caches) Equal(that interface{}) bool {
@@ -1572,13 +1590,14 @@ func (this *Result) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 9)
+	s := make([]string, 0, 10)
 	s = append(s, "&stats.Result{")
 	s = append(s, "Summary: "+strings.Replace(this.Summary.GoString(), `&`, ``, 1)+",\n")
 	s = append(s, "Querier: "+strings.Replace(this.Querier.GoString(), `&`, ``, 1)+",\n")
 	s = append(s, "Ingester: "+strings.Replace(this.Ingester.GoString(), `&`, ``, 1)+",\n")
 	s = append(s, "Caches: "+strings.Replace(this.Caches.GoString(), `&`, ``, 1)+",\n")
 	s = append(s, "Index: "+strings.Replace(this.Index.GoString(), `&`, ``, 1)+",\n")
+	s = append(s, "Plans: "+fmt.Sprintf("%#v", this.Plans)+",\n")
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -1759,6 +1778,15 @@ func (m *Result) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if len(m.Plans) > 0 {
+		for iNdEx := len(m.Plans) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.Plans[iNdEx])
+			copy(dAtA[i:], m.Plans[iNdEx])
+			i = encodeVarintStats(dAtA, i, uint64(len(m.Plans[iNdEx])))
+			i--
+			dAtA[i] = 0x32
+		}
+	}
 	{
 		size, err := m.Index.MarshalToSizedBuffer(dAtA[:i])
 		if err != nil {
@@ -2458,6 +2486,12 @@ func (m *Result) Size() (n int) {
 	n += 1 + l + sovStats(uint64(l))
 	l = m.Index.Size()
 	n += 1 + l + sovStats(uint64(l))
+	if len(m.Plans) > 0 {
+		for _, s := range m.Plans {
+			l = len(s)
+			n += 1 + l + sovStats(uint64(l))
+		}
+	}
 	return n
 }
 
@@ -2750,6 +2784,7 @@ func (this *Result) String() string {
 		`Ingester:` + strings.Replace(strings.Replace(this.Ingester.String(), "Ingester", "Ingester", 1), `&`, ``, 1) + `,`,
 		`Caches:` + strings.Replace(strings.Replace(this.Caches.String(), "Caches", "Caches", 1), `&`, ``, 1) + `,`,
 		`Index:` + strings.Replace(strings.Replace(this.Index.String(), "Index", "Index", 1), `&`, ``, 1) + `,`,
+		`Plans:` + fmt.Sprintf("%v", this.Plans) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -3105,6 +3140,38 @@ func (m *Result) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Plans", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowStats
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthStats
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthStats
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Plans = append(m.Plans, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipStats(dAtA[iNdEx:])
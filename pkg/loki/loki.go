@@ -37,6 +37,7 @@ import (
 	"github.com/grafana/loki/v3/pkg/compactor"
 	compactorclient "github.com/grafana/loki/v3/pkg/compactor/client"
 	"github.com/grafana/loki/v3/pkg/compactor/deletion"
+	dataobjcompactor "github.com/grafana/loki/v3/pkg/dataobj/compactor"
 	dataobjconfig "github.com/grafana/loki/v3/pkg/dataobj/config"
 	"github.com/grafana/loki/v3/pkg/dataobj/consumer"
 	dataobjindex "github.com/grafana/loki/v3/pkg/dataobj/index"
@@ -365,6 +366,12 @@ func (c *Config) isTarget(m string) bool {
 	return util.StringsContain(c.Target, m)
 }
 
+// dataObjStorageEnabled returns true if logs are queried from data objects,
+// so that delete requests and retention need to be applied to them.
+func (c *Config) dataObjStorageEnabled() bool {
+	return c.DataObj.Querier.Enabled || c.Querier.Engine.EnableV2Engine
+}
+
 type Frontend interface {
 	services.Service
 	CheckReady(_ context.Context) error
@@ -429,6 +436,7 @@ type Loki struct {
 	blockScheduler            *blockscheduler.BlockScheduler
 	dataObjConsumer           *consumer.Service
 	dataObjIndexBuilder       *dataobjindex.Builder
+	dataObjCompactor          *dataobjcompactor.Service
 
 	ClientMetrics       storage.ClientMetrics
 	deleteClientMetrics *deletion.DeleteRequestClientMetrics
@@ -772,6 +780,7 @@ func (t *Loki) setupModuleManager() error {
 	mm.RegisterModule(UI, t.initUI)
 	mm.RegisterModule(DataObjConsumer, t.initDataObjConsumer)
 	mm.RegisterModule(DataObjIndexBuilder, t.initDataObjIndexBuilder)
+	mm.RegisterModule(DataObjCompactor, t.initDataObjCompactor)
 
 	mm.RegisterModule(All, nil)
 	mm.RegisterModule(Read, nil)
@@ -817,8 +826,9 @@ func (t *Loki) setupModuleManager() error {
 		BlockBuilder:             {PartitionRing, Store, Server, UI},
 		BlockScheduler:           {Server, UI},
 		DataObjExplorer:          {Server, UI},
-		DataObjConsumer:          {PartitionRing, Server, UI},
+		DataObjConsumer:          {PartitionRing, Server, UI, Overrides},
 		DataObjIndexBuilder:      {Server, UI},
+		DataObjCompactor:         {Server, UI, Overrides},
 
 		Read:    {QueryFrontend, Querier},
 		Write:   {Ingester, Distributor, PatternIngester},
@@ -877,6 +887,13 @@ func (t *Loki) setupModuleManager() error {
 		deps[Server] = append(deps[Server], IngesterGRPCInterceptors)
 	}
 
+	// Delete requests need to be applied to data objects before the compactor
+	// marks them as processed, so the compactor requires the dataobj compactor
+	// whenever logs are stored in data objects.
+	if t.Cfg.isTarget(DataObjCompactor) || t.Cfg.dataObjStorageEnabled() {
+		deps[Compactor] = append(deps[Compactor], DataObjCompactor)
+	}
+
 	if t.Cfg.LegacyReadTarget {
 		deps[Read] = append(deps[Read], deps[Backend]...)
 	}
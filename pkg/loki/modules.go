@@ -52,12 +52,17 @@ import (
 	"github.com/grafana/loki/v3/pkg/compactor/client/grpc"
 	"github.com/grafana/loki/v3/pkg/compactor/deletion"
 	"github.com/grafana/loki/v3/pkg/compactor/generationnumber"
+	dataobjcompactor "github.com/grafana/loki/v3/pkg/dataobj/compactor"
 	"github.com/grafana/loki/v3/pkg/dataobj/consumer"
 	"github.com/grafana/loki/v3/pkg/dataobj/explorer"
 	dataobjindex "github.com/grafana/loki/v3/pkg/dataobj/index"
 	"github.com/grafana/loki/v3/pkg/dataobj/metastore"
 	dataobjquerier "github.com/grafana/loki/v3/pkg/dataobj/querier"
 	"github.com/grafana/loki/v3/pkg/distributor"
+	"github.com/grafana/loki/v3/pkg/engine"
+	"github.com/grafana/loki/v3/pkg/engine/distributed"
+	"github.com/grafana/loki/v3/pkg/engine/distributed/distributedpb"
+	"github.com/grafana/loki/v3/pkg/engine/executor"
 	"github.com/grafana/loki/v3/pkg/indexgateway"
 	"github.com/grafana/loki/v3/pkg/ingester"
 	"github.com/grafana/loki/v3/pkg/kafka/partition"
@@ -160,6 +165,7 @@ const (
 	DataObjExplorer          = "dataobj-explorer"
 	DataObjConsumer          = "dataobj-consumer"
 	DataObjIndexBuilder      = "dataobj-index-builder"
+	DataObjCompactor         = "dataobj-compactor"
 	UI                       = "ui"
 	All                      = "all"
 	Read                     = "read"
@@ -612,6 +618,7 @@ func (t *Loki) initQuerier() (services.Service, error) {
 	toMerge := []middleware.Interface{
 		httpreq.ExtractQueryMetricsMiddleware(),
 		httpreq.ExtractQueryTagsMiddleware(),
+		httpreq.ExtractExplainMiddleware(),
 		httpreq.PropagateHeadersMiddleware(httpreq.LokiEncodingFlagsHeader, httpreq.LokiDisablePipelineWrappersHeader),
 		serverutil.RecoveryHTTPMiddleware,
 		t.HTTPAuthMiddleware,
@@ -619,15 +626,33 @@ func (t *Loki) initQuerier() (services.Service, error) {
 		serverutil.ResponseJSONMiddleware(),
 	}
 
-	var store objstore.Bucket
+	var (
+		store     objstore.Bucket
+		fragments engine.FragmentScheduler
+	)
 	if t.Cfg.Querier.Engine.EnableV2Engine {
 		store, err = t.createDataObjBucket("dataobj-querier")
 		if err != nil {
 			return nil, err
 		}
+
+		// Execute the plan fragments sent by other queriers.
+		distributedpb.RegisterFragmentExecutorServer(t.Server.GRPC, distributed.NewHandler(executor.Config{
+			BatchSize:   int64(t.Cfg.Querier.Engine.BatchSize),
+			Bucket:      store,
+			MemoryLimit: int64(t.Cfg.Querier.Engine.MemoryLimit),
+			SpillDir:    t.Cfg.Querier.Engine.SpillDirectory,
+		}, logger))
+
+		if t.Cfg.Querier.DistributedExecution.Address != "" {
+			fragments, err = distributed.NewScheduler(t.Cfg.Querier.DistributedExecution, prometheus.DefaultRegisterer)
+			if err != nil {
+				return nil, err
+			}
+		}
 	}
 
-	t.querierAPI = querier.NewQuerierAPI(t.Cfg.Querier, t.Querier, t.Overrides, store, prometheus.DefaultRegisterer, logger)
+	t.querierAPI = querier.NewQuerierAPI(t.Cfg.Querier, t.Querier, t.Overrides, store, fragments, prometheus.DefaultRegisterer, logger)
 
 	indexStatsHTTPMiddleware := querier.WrapQuerySpanAndTimeout("query.IndexStats", t.Overrides)
 	indexShardsHTTPMiddleware := querier.WrapQuerySpanAndTimeout("query.IndexShards", t.Overrides)
@@ -1323,6 +1348,7 @@ func (t *Loki) initQueryFrontend() (_ services.Service, err error) {
 	// TODO: add SerializeHTTPHandler
 	toMerge := []middleware.Interface{
 		httpreq.ExtractQueryTagsMiddleware(),
+		httpreq.ExtractExplainMiddleware(),
 		httpreq.PropagateHeadersMiddleware(httpreq.LokiActorPathHeader, httpreq.LokiEncodingFlagsHeader, httpreq.LokiDisablePipelineWrappersHeader),
 		serverutil.RecoveryHTTPMiddleware,
 		t.HTTPAuthMiddleware,
@@ -1756,6 +1782,21 @@ func (t *Loki) initCompactor() (services.Service, error) {
 		return nil, err
 	}
 
+	if t.Cfg.dataObjStorageEnabled() && t.dataObjCompactor == nil {
+		// Delete requests would be marked as processed without being applied
+		// to the data objects.
+		return nil, errors.New("the dataobj compactor must run with the compactor when logs are stored in data objects")
+	}
+	if t.dataObjCompactor != nil {
+		t.compactor.SetObjectDeleter(func(ctx context.Context, userID string, requests []*deletion.DeleteRequest) error {
+			reqs := make([]dataobjcompactor.DeleteRequest, 0, len(requests))
+			for _, req := range requests {
+				reqs = append(reqs, req)
+			}
+			return t.dataObjCompactor.ApplyDeletes(ctx, userID, reqs)
+		})
+	}
+
 	t.compactor.RegisterIndexCompactor(types.BoltDBShipperType, boltdbcompactor.NewIndexCompactor())
 	t.compactor.RegisterIndexCompactor(types.TSDBType, tsdb.NewIndexCompactor())
 	prefix, compactorHandler := t.compactor.Handler()
@@ -2203,6 +2244,7 @@ func (t *Loki) initDataObjConsumer() (services.Service, error) {
 		t.Cfg.DataObj.Metastore,
 		t.Cfg.Distributor.TenantTopic.TopicPrefix,
 		store,
+		t.Overrides,
 		t.Cfg.Ingester.LifecyclerConfig.ID,
 		t.partitionRing,
 		prometheus.DefaultRegisterer,
@@ -2235,6 +2277,25 @@ func (t *Loki) initDataObjIndexBuilder() (services.Service, error) {
 	return t.dataObjIndexBuilder, err
 }
 
+func (t *Loki) initDataObjCompactor() (services.Service, error) {
+	store, err := t.createDataObjBucket("dataobj-compactor")
+	if err != nil {
+		return nil, err
+	}
+
+	level.Info(util_log.Logger).Log("msg", "initializing dataobj compactor")
+	t.dataObjCompactor, err = dataobjcompactor.NewService(
+		t.Cfg.DataObj.Compactor,
+		t.Cfg.DataObj.Metastore,
+		store,
+		t.Overrides,
+		util_log.Logger,
+		prometheus.DefaultRegisterer,
+	)
+
+	return t.dataObjCompactor, err
+}
+
 func (t *Loki) createDataObjBucket(clientName string) (objstore.Bucket, error) {
 	schema, err := t.Cfg.SchemaConfig.SchemaForTime(model.Now())
 	if err != nil {
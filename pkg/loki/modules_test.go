@@ -393,6 +393,64 @@ func TestUIServiceInitialization(t *testing.T) {
 	})
 }
 
+func TestCompactor_DataObjCompactorDependency(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		target  string
+		dataobj bool
+		want    bool
+	}{
+		{name: "all without data objects", target: All, want: false},
+		{name: "all with data objects", target: All, dataobj: true, want: true},
+		{name: "backend with data objects", target: Backend, dataobj: true, want: true},
+		{name: "compactor with data objects", target: Compactor, dataobj: true, want: true},
+		{name: "querier with data objects", target: Querier, dataobj: true, want: false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			l := &Loki{Cfg: Config{Target: flagext.StringSliceCSV{tc.target}}}
+			l.Cfg.DataObj.Querier.Enabled = tc.dataobj
+			require.NoError(t, l.setupModuleManager())
+			require.Equal(t, tc.want, l.isModuleActive(DataObjCompactor))
+		})
+	}
+
+	t.Run("compactor applies deletes to data objects", func(t *testing.T) {
+		cfg := minimalWorkingConfig(t, t.TempDir(), Compactor, func(cfg *Config) {
+			cfg.Querier.Engine.EnableV2Engine = true
+		})
+		c, err := New(cfg)
+		require.NoError(t, err)
+
+		services, err := c.ModuleManager.InitModuleServices(Compactor)
+		defer func() {
+			for _, service := range services {
+				service.StopAsync()
+			}
+		}()
+
+		require.NoError(t, err)
+		require.NotNil(t, c.compactor)
+		require.NotNil(t, c.dataObjCompactor, "the dataobj compactor applies delete requests to data objects")
+	})
+
+	t.Run("compactor fails without dataobj compactor", func(t *testing.T) {
+		cfg := minimalWorkingConfig(t, t.TempDir(), Compactor)
+		c, err := New(cfg)
+		require.NoError(t, err)
+
+		// Enable data objects after the module dependencies were set up.
+		c.Cfg.Querier.Engine.EnableV2Engine = true
+		services, err := c.ModuleManager.InitModuleServices(Compactor)
+		defer func() {
+			for _, service := range services {
+				service.StopAsync()
+			}
+		}()
+
+		require.ErrorContains(t, err, "the dataobj compactor must run with the compactor")
+	})
+}
+
 func minimalWorkingConfig(t *testing.T, dir, target string, cfgTransformers ...func(*Config)) Config {
 	prepareGlobalMetricsRegistry(t)
 
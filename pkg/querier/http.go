@@ -60,14 +60,15 @@ type QuerierAPI struct {
 	logger   log.Logger
 }
 
-// NewQuerierAPI returns an instance of the QuerierAPI.
-func NewQuerierAPI(cfg Config, querier Querier, limits querier_limits.Limits, store objstore.Bucket, reg prometheus.Registerer, logger log.Logger) *QuerierAPI {
+// NewQuerierAPI returns an instance of the QuerierAPI. If fragments is non-nil,
+// the next generation query engine executes the fragments of its plans with it.
+func NewQuerierAPI(cfg Config, querier Querier, limits querier_limits.Limits, store objstore.Bucket, fragments engine.FragmentScheduler, reg prometheus.Registerer, logger log.Logger) *QuerierAPI {
 	return &QuerierAPI{
 		cfg:      cfg,
 		limits:   limits,
 		querier:  querier,
 		engineV1: logql.NewEngine(cfg.Engine, querier, limits, logger),
-		engineV2: engine.New(cfg.Engine, store, limits, reg, logger),
+		engineV2: engine.New(cfg.Engine, store, fragments, limits, reg, logger),
 		logger:   logger,
 	}
 }
@@ -336,12 +336,23 @@ func (Codec) DecodeRequest(_ context.Context, r *http.Request, _ []string) (quer
 
 	disableCacheReq := strings.ToLower(strings.TrimSpace(r.Header.Get(cacheControlHeader))) == noCacheVal
 
+	// The plan of a query is returned with its results, so they must not be
+	// served from or stored in the results cache.
+	explain := r.Form.Get("explain") != "" || r.Header.Get(httpreq.LokiExplainHeader) != ""
+
 	switch op := getOperation(r.URL.Path); op {
 	case QueryRangeOp:
 		req, err := parseRangeQuery(r)
 		if err != nil {
 			return nil, httpgrpc.Errorf(http.StatusBadRequest, "%s", err.Error())
 		}
+
+		if explain {
+			req.CachingOptions = queryrangebase.CachingOptions{
+				Disabled: true,
+			}
+		}
+
 		return req, nil
 	case InstantQueryOp:
 		req, err := parseInstantQuery(r)
@@ -350,7 +361,7 @@ func (Codec) DecodeRequest(_ context.Context, r *http.Request, _ []string) (quer
 		}
 
 		req.CachingOptions = queryrangebase.CachingOptions{
-			Disabled: disableCacheReq,
+			Disabled: disableCacheReq || explain,
 		}
 
 		return req, nil
@@ -507,6 +518,11 @@ func (Codec) DecodeHTTPGrpcRequest(ctx context.Context, r *httpgrpc.HTTPRequest)
 		httpreq.InjectHeader(ctx, httpreq.LokiDisablePipelineWrappersHeader, disableWrappers)
 	}
 
+	// Add explain mode
+	if explain := httpReq.Header.Get(httpreq.LokiExplainHeader); explain != "" {
+		ctx = httpreq.InjectHeader(ctx, httpreq.LokiExplainHeader, explain)
+	}
+
 	// Add query metrics
 	if queueTimeHeader := httpReq.Header.Get(string(httpreq.QueryQueueTimeHTTPHeader)); queueTimeHeader != "" {
 		queueTime, err := time.ParseDuration(queueTimeHeader)
@@ -716,6 +732,11 @@ func (c Codec) EncodeRequest(ctx context.Context, r queryrangebase.Request) (*ht
 		header.Set(httpreq.LokiDisablePipelineWrappersHeader, disableWrappers)
 	}
 
+	// Add explain mode
+	if explain := httpreq.ExtractHeader(ctx, httpreq.LokiExplainHeader); explain != "" {
+		header.Set(httpreq.LokiExplainHeader, explain)
+	}
+
 	// Add limits
 	if limits := querylimits.ExtractQueryLimitsContext(ctx); limits != nil {
 		err := querylimits.InjectQueryLimitsHeader(&header, limits)
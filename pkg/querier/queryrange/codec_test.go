@@ -1345,6 +1345,41 @@ func Test_codec_MergeResponse(t *testing.T) {
 			},
 			"",
 		},
+		{
+			"prom with plans",
+			[]queryrangebase.Response{
+				&LokiPromResponse{
+					Statistics: stats.Result{Plans: []string{"plan 1"}},
+					Response: &queryrangebase.PrometheusResponse{
+						Status: loghttp.QueryStatusSuccess,
+						Data: queryrangebase.PrometheusData{
+							ResultType: loghttp.ResultTypeMatrix,
+							Result:     sampleStreams,
+						},
+					},
+				},
+				&LokiPromResponse{
+					Statistics: stats.Result{Plans: []string{"plan 2"}},
+					Response: &queryrangebase.PrometheusResponse{
+						Status: loghttp.QueryStatusSuccess,
+						Data: queryrangebase.PrometheusData{
+							ResultType: loghttp.ResultTypeMatrix,
+						},
+					},
+				},
+			},
+			&LokiPromResponse{
+				Statistics: stats.Result{Summary: stats.Summary{Splits: 2}, Plans: []string{"plan 1", "plan 2"}},
+				Response: &queryrangebase.PrometheusResponse{
+					Status: loghttp.QueryStatusSuccess,
+					Data: queryrangebase.PrometheusData{
+						ResultType: loghttp.ResultTypeMatrix,
+						Result:     sampleStreams,
+					},
+				},
+			},
+			"",
+		},
 		{
 			"loki backward",
 			[]queryrangebase.Response{
@@ -336,6 +336,11 @@ func (Codec) QueryRequestUnwrap(ctx context.Context, req *QueryRequest) (queryra
 		ctx = httpreq.InjectHeader(ctx, httpreq.LokiDisablePipelineWrappersHeader, disableWrappers)
 	}
 
+	// Add explain mode
+	if explain, ok := req.Metadata[httpreq.LokiExplainHeader]; ok {
+		ctx = httpreq.InjectHeader(ctx, httpreq.LokiExplainHeader, explain)
+	}
+
 	// Add limits
 	if encodedLimits, ok := req.Metadata[querylimits.HTTPHeaderQueryLimitsKey]; ok {
 		limits, err := querylimits.UnmarshalQueryLimits([]byte(encodedLimits))
@@ -453,6 +458,12 @@ func (Codec) QueryRequestWrap(ctx context.Context, r queryrangebase.Request) (*Q
 		result.Metadata[httpreq.LokiDisablePipelineWrappersHeader] = disableWrappers
 	}
 
+	// Keep explain mode
+	explain := httpreq.ExtractHeader(ctx, httpreq.LokiExplainHeader)
+	if explain != "" {
+		result.Metadata[httpreq.LokiExplainHeader] = explain
+	}
+
 	// Add limits
 	limits := querylimits.ExtractQueryLimitsContext(ctx)
 	if limits != nil {
@@ -16,6 +16,7 @@ import (
 
 	"github.com/grafana/loki/v3/pkg/logproto"
 	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	"github.com/grafana/loki/v3/pkg/querier/plan"
 	"github.com/grafana/loki/v3/pkg/querier/queryrange/queryrangebase"
 	"github.com/grafana/loki/v3/pkg/storage/config"
 	"github.com/grafana/loki/v3/pkg/util/constants"
@@ -194,6 +195,12 @@ func (h *splitByInterval) Do(ctx context.Context, r queryrangebase.Request) (que
 		return h.next.Do(ctx, r)
 	}
 
+	if req, ok := r.(*LokiRequest); ok {
+		if r, err = resolveAtModifiers(req); err != nil {
+			return nil, httpgrpc.Errorf(http.StatusBadRequest, "%s", err.Error())
+		}
+	}
+
 	intervals := h.splitter.split(time.Now().UTC(), tenantIDs, r, interval)
 
 	h.metrics.splits.Observe(float64(len(intervals)))
@@ -248,6 +255,22 @@ func (h *splitByInterval) Do(ctx context.Context, r queryrangebase.Request) (que
 	return h.merger.MergeResponse(resps...)
 }
 
+// resolveAtModifiers replaces the `@ start()` and `@ end()` modifiers of the
+// query of r by the start and end of r, so that the queries r is split into
+// keep evaluating the pinned ranges at the start and end of the whole query.
+func resolveAtModifiers(r *LokiRequest) (*LokiRequest, error) {
+	if r.Plan == nil || !syntax.HasStartOrEndAtModifier(r.Plan.AST) {
+		return r, nil
+	}
+	expr, err := syntax.ResolveAtModifiers(r.Plan.AST, r.StartTs, r.EndTs)
+	if err != nil {
+		return nil, err
+	}
+	resolved := r.WithQuery(expr.String()).(*LokiRequest)
+	resolved.Plan = &plan.QueryPlan{AST: expr}
+	return resolved, nil
+}
+
 // maxRangeVectorAndOffsetDurationFromQueryString
 func maxRangeVectorAndOffsetDurationFromQueryString(q string) (time.Duration, time.Duration, error) {
 	parsed, err := syntax.ParseExpr(q)
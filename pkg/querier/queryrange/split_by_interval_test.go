@@ -1369,6 +1369,39 @@ func Test_splitMetricQuery(t *testing.T) {
 	}
 }
 
+func Test_resolveAtModifiers(t *testing.T) {
+	query := `sum(rate({app="foo"}[5m] @ end())) / sum(rate({app="foo"}[5m] @ start()))`
+	req := &LokiRequest{
+		Query:   query,
+		StartTs: time.Unix(1000, 0),
+		EndTs:   time.Unix(2000, 0),
+		Step:    1000,
+		Plan:    &plan.QueryPlan{AST: syntax.MustParseExpr(query)},
+	}
+
+	resolved, err := resolveAtModifiers(req)
+	require.NoError(t, err)
+	expected := `(sum(rate({app="foo"}[5m] @ 2000)) / sum(rate({app="foo"}[5m] @ 1000)))`
+	require.Equal(t, expected, resolved.Query)
+	require.Equal(t, expected, resolved.Plan.AST.String())
+	require.Equal(t, query, req.Query, "the original request is unchanged")
+
+	// The queries the request is split into evaluate the pinned ranges at
+	// the start and end of the whole query.
+	splits := newMetricQuerySplitter(fakeLimits{}, nil).split(time.Now(), []string{"1"}, resolved, 100*time.Second)
+	require.Greater(t, len(splits), 1)
+	for _, split := range splits {
+		require.Equal(t, expected, split.GetQuery())
+	}
+
+	// Queries without these modifiers are left as they are.
+	query = `sum(rate({app="foo"}[5m] @ 1500))`
+	req = &LokiRequest{Query: query, Plan: &plan.QueryPlan{AST: syntax.MustParseExpr(query)}}
+	resolved, err = resolveAtModifiers(req)
+	require.NoError(t, err)
+	require.Same(t, req, resolved)
+}
+
 func Test_splitByInterval_Do(t *testing.T) {
 	ctx := user.InjectOrgID(context.Background(), "1")
 	next := queryrangebase.HandlerFunc(func(_ context.Context, r queryrangebase.Request) (queryrangebase.Response, error) {
@@ -15,6 +15,14 @@ var (
 	LokiActorPathHeader               = "X-Loki-Actor-Path"
 	LokiDisablePipelineWrappersHeader = "X-Loki-Disable-Pipeline-Wrappers"
 
+	// LokiExplainHeader is the name of the header to request the query plan
+	// along with the results of a query. It can also be set with the explain
+	// query parameter.
+	LokiExplainHeader = "X-Loki-Explain"
+	// ExplainAnalyze executes the query and annotates each node of its plan
+	// with runtime statistics.
+	ExplainAnalyze = "analyze"
+
 	// LokiActorPathDelimiter is the delimiter used to serialise the hierarchy of the actor.
 	LokiActorPathDelimiter = "|"
 )
@@ -55,3 +63,27 @@ func InjectActorPath(ctx context.Context, value string) context.Context {
 func InjectHeader(ctx context.Context, key, value string) context.Context {
 	return context.WithValue(ctx, headerContextKey(key), value)
 }
+
+// ExtractExplainMiddleware injects the explain mode of a query, given by the
+// explain query parameter or the LokiExplainHeader header, into the request
+// context.
+func ExtractExplainMiddleware() middleware.Interface {
+	return middleware.Func(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			explain := req.URL.Query().Get("explain")
+			if explain == "" {
+				explain = req.Header.Get(LokiExplainHeader)
+			}
+			if explain != "" {
+				req = req.WithContext(InjectHeader(req.Context(), LokiExplainHeader, explain))
+			}
+			next.ServeHTTP(w, req)
+		})
+	})
+}
+
+// IsExplainAnalyze returns true if the query of ctx requested its plan
+// annotated with runtime statistics.
+func IsExplainAnalyze(ctx context.Context) bool {
+	return strings.EqualFold(ExtractHeader(ctx, LokiExplainHeader), ExplainAnalyze)
+}
@@ -0,0 +1,55 @@
+package httpreq
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractExplainMiddleware(t *testing.T) {
+	for _, tc := range []struct {
+		desc    string
+		url     string
+		header  string
+		analyze bool
+	}{
+		{
+			desc: "not set",
+			url:  "http://testing.com",
+		},
+		{
+			desc:    "query parameter",
+			url:     "http://testing.com?explain=analyze",
+			analyze: true,
+		},
+		{
+			desc:    "header",
+			url:     "http://testing.com",
+			header:  "ANALYZE",
+			analyze: true,
+		},
+		{
+			desc:   "query parameter takes precedence",
+			url:    "http://testing.com?explain=plan",
+			header: "analyze",
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tc.url, nil)
+			if tc.header != "" {
+				req.Header.Set(LokiExplainHeader, tc.header)
+			}
+
+			checked := false
+			mware := ExtractExplainMiddleware().Wrap(http.HandlerFunc(func(_ http.ResponseWriter, req *http.Request) {
+				require.Equal(t, tc.analyze, IsExplainAnalyze(req.Context()))
+				checked = true
+			}))
+			mware.ServeHTTP(httptest.NewRecorder(), req)
+
+			require.True(t, checked)
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	bloomplanner "github.com/grafana/loki/v3/pkg/bloombuild/planner"
 	"github.com/grafana/loki/v3/pkg/bloomgateway"
 	"github.com/grafana/loki/v3/pkg/compactor"
+	dataobj_consumer "github.com/grafana/loki/v3/pkg/dataobj/consumer"
 	"github.com/grafana/loki/v3/pkg/distributor"
 	"github.com/grafana/loki/v3/pkg/indexgateway"
 	"github.com/grafana/loki/v3/pkg/ingester"
@@ -31,5 +32,6 @@ type CombinedLimits interface {
 	bloomplanner.Limits
 	bloombuilder.Limits
 	pattern.Limits
+	dataobj_consumer.Limits
 	bucket.SSEConfigProvider
 }
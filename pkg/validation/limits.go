@@ -21,6 +21,7 @@ import (
 
 	"github.com/grafana/loki/v3/pkg/compactor/deletionmode"
 	"github.com/grafana/loki/v3/pkg/compression"
+	"github.com/grafana/loki/v3/pkg/dataobj/consumer/logsobj"
 	"github.com/grafana/loki/v3/pkg/distributor/shardstreams"
 	"github.com/grafana/loki/v3/pkg/loghttp/push"
 	"github.com/grafana/loki/v3/pkg/logql"
@@ -250,6 +251,8 @@ type Limits struct {
 	MetricAggregationEnabled                    bool                         `yaml:"metric_aggregation_enabled"                       json:"metric_aggregation_enabled"`
 	PatternPersistenceEnabled                   bool                         `yaml:"pattern_persistence_enabled"                      json:"pattern_persistence_enabled"`
 
+	DataObjCompression logsobj.CompressionOverrides `yaml:"dataobj_compression" json:"dataobj_compression" category:"experimental" doc:"description=Per-tenant overrides of the compression of each column type of logs sections written by the dataobj consumer. Columns without a codec use the compression configured for the dataobj consumer."`
+
 	// This config doesn't have a CLI flag registered here because they're registered in
 	// their own original config struct.
 	S3SSEType                 string `yaml:"s3_sse_type" json:"s3_sse_type" doc:"nocli|description=S3 server-side encryption type. Required to enable server-side encryption overrides for a specific tenant. If not set, the default S3 client settings are used."`
@@ -545,6 +548,10 @@ func (l *Limits) Validate() error {
 		}
 	}
 
+	if err := l.DataObjCompression.Validate(); err != nil {
+		return errors.Wrap(err, "invalid dataobj_compression")
+	}
+
 	if _, err := deletionmode.ParseMode(l.DeletionMode); err != nil {
 		return err
 	}
@@ -1252,6 +1259,10 @@ func (o *Overrides) S3SSEKMSEncryptionContext(user string) string {
 	return o.getOverridesForUser(user).S3SSEKMSEncryptionContext
 }
 
+func (o *Overrides) DataObjCompression(userID string) logsobj.CompressionOverrides {
+	return o.getOverridesForUser(userID).DataObjCompression
+}
+
 func (o *Overrides) getOverridesForUser(userID string) *Limits {
 	if o.tenantLimits != nil {
 		l := o.tenantLimits.TenantLimits(userID)